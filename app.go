@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	gosync "sync"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/health"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/scheduler"
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/openwebui-content-sync/internal/sync"
+	"github.com/openwebui-content-sync/internal/utils"
+	"github.com/openwebui-content-sync/internal/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// shutdownTimeout bounds how long the health server gets to finish in-flight requests
+const shutdownTimeout = 5 * time.Second
+
+// Options selects how the App runs besides the configuration
+type Options struct {
+	DryRun      bool   // Report planned changes without modifying OpenWebUI
+	PurgeSource string // Source being purged; knowledge names are not resolved in purge mode
+	RepairIndex bool   // Repairing the file index; knowledge names are not resolved either
+}
+
+// App wires the adapters, sync manager, scheduler and health server of one process
+type App struct {
+	cfg     *config.Config
+	manager *sync.Manager
+
+	adapters         []adapter.Adapter
+	adapterSchedules map[string]config.ScheduleConfig // adapter name -> optional own schedule
+	localAdapter     *adapter.LocalFolderAdapter      // nil unless local folders are enabled
+
+	background gosync.WaitGroup // initial sync, webhook receiver and local folder watcher of Run
+}
+
+// New creates the sync manager and the enabled adapters for cfg
+func New(cfg *config.Config, opts Options) (*App, error) {
+	syncManager, err := sync.NewManager(cfg.OpenWebUI, cfg.Storage, cfg.Sync, cfg.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync manager: %w", err)
+	}
+	syncManager.DryRun = opts.DryRun
+
+	// Turn knowledge_name mappings into knowledge IDs before the adapters copy their mappings
+	if opts.PurgeSource == "" && !opts.RepairIndex {
+		if err := syncManager.ResolveKnowledgeNames(context.Background(), cfg); err != nil {
+			return nil, fmt.Errorf("failed to resolve knowledge bases: %w", err)
+		}
+	}
+
+	app := &App{
+		cfg:              cfg,
+		manager:          syncManager,
+		adapterSchedules: make(map[string]config.ScheduleConfig),
+	}
+
+	// A dry run must not record page versions, or the next real sync would skip those pages
+	for _, factory := range adapterFactories(cfg, !opts.DryRun) {
+		if !factory.enabled {
+			continue
+		}
+		adpt, err := factory.create()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s adapter: %w", factory.label, err)
+		}
+		if setter, ok := adpt.(adapter.LoggerSetter); ok {
+			setter.SetLogger(logging.Default().WithField("adapter", adpt.Name()))
+		}
+		if localAdapter, ok := adpt.(*adapter.LocalFolderAdapter); ok {
+			app.localAdapter = localAdapter
+		}
+		app.addAdapter(adpt, factory.schedule)
+	}
+
+	// Continue incremental syncs from where the previous process left off
+	syncManager.RestoreLastSync(app.adapters)
+
+	return app, nil
+}
+
+// adapterFactory creates one of the supported adapters
+type adapterFactory struct {
+	label    string // adapter name in error messages
+	enabled  bool
+	schedule config.ScheduleConfig
+	create   func() (adapter.Adapter, error)
+}
+
+// adapterFactories returns the factories of every supported adapter, in sync order. Confluence
+// page versions are only persisted in the storage when recordVersions is set.
+func adapterFactories(cfg *config.Config, recordVersions bool) []adapterFactory {
+	return []adapterFactory{
+		{
+			label:    "GitHub",
+			enabled:  cfg.GitHub.Enabled,
+			schedule: cfg.GitHub.Schedule,
+			create: func() (adapter.Adapter, error) {
+				return adapter.NewGitHubAdapter(cfg.GitHub)
+			},
+		},
+		{
+			label:    "Confluence",
+			enabled:  cfg.Confluence.Enabled,
+			schedule: cfg.Confluence.Schedule,
+			create: func() (adapter.Adapter, error) {
+				var store storage.Storage
+				if recordVersions {
+					var err error
+					if store, err = storage.New(cfg.Storage); err != nil {
+						return nil, err
+					}
+				}
+				confluenceAdapter, err := adapter.NewConfluenceAdapter(cfg.Confluence, store)
+				if err != nil {
+					return nil, err
+				}
+				confluenceAdapter.SetHeaders(cfg.HTTP.UserAgent, cfg.HTTP.Headers)
+				return confluenceAdapter, nil
+			},
+		},
+		{
+			label:    "Local Folders",
+			enabled:  cfg.LocalFolders.Enabled,
+			schedule: cfg.LocalFolders.Schedule,
+			create: func() (adapter.Adapter, error) {
+				return adapter.NewLocalFolderAdapter(cfg.LocalFolders)
+			},
+		},
+		{
+			label:    "Slack",
+			enabled:  cfg.Slack.Enabled,
+			schedule: cfg.Slack.Schedule,
+			create: func() (adapter.Adapter, error) {
+				store, err := storage.New(cfg.Storage)
+				if err != nil {
+					return nil, err
+				}
+				return adapter.NewSlackAdapter(cfg.Slack, store)
+			},
+		},
+		{
+			label:    "Jira",
+			enabled:  cfg.Jira.Enabled,
+			schedule: cfg.Jira.Schedule,
+			create: func() (adapter.Adapter, error) {
+				jiraAdapter, err := adapter.NewJiraAdapter(cfg.Jira)
+				if err != nil {
+					return nil, err
+				}
+				jiraAdapter.SetHeaders(cfg.HTTP.UserAgent, cfg.HTTP.Headers)
+				return jiraAdapter, nil
+			},
+		},
+	}
+}
+
+// addAdapter registers an adapter with its optional own schedule
+func (a *App) addAdapter(adpt adapter.Adapter, schedule config.ScheduleConfig) {
+	a.adapters = append(a.adapters, adpt)
+	a.adapterSchedules[adpt.Name()] = schedule
+}
+
+// Manager returns the sync manager, e.g. for the dry-run summary
+func (a *App) Manager() *sync.Manager {
+	return a.manager
+}
+
+// Purge removes every file synced by source from OpenWebUI and returns how many were removed
+func (a *App) Purge(ctx context.Context, source string) (int, error) {
+	return a.manager.PurgeSource(ctx, source)
+}
+
+// RepairIndex drops the file index entries of files missing from OpenWebUI and of duplicates
+func (a *App) RepairIndex(ctx context.Context) (sync.RepairReport, error) {
+	return a.manager.RepairIndex(ctx)
+}
+
+// RunOnce initializes the file index and runs a single sync of every adapter
+func (a *App) RunOnce(ctx context.Context) error {
+	logrus.Info("Initializing file index from OpenWebUI...")
+	if err := a.manager.InitializeFileIndex(ctx, a.adapters); err != nil {
+		// Continue even if initialization fails
+		logrus.Errorf("Failed to initialize file index: %v", err)
+	}
+
+	return a.manager.SyncFiles(ctx, a.adapters)
+}
+
+// Run starts the health server and scheduler, runs the initial sync and keeps syncing on
+// schedule until ctx is cancelled. It returns once everything has shut down.
+func (a *App) Run(ctx context.Context) error {
+	// Note: With the mapping system, individual files will have their own knowledge IDs
+	logrus.Infof("Using mapping-based knowledge ID assignment - files will use their individual knowledge IDs from mappings")
+
+	sched, err := scheduler.NewFromConfig(a.cfg.Schedule, a.adapters, a.manager)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduler: %w", err)
+	}
+	sched.SetSyncTimeout(a.cfg.Sync.Timeout)
+	for name, schedule := range a.adapterSchedules {
+		if err := sched.SetAdapterSchedule(name, schedule); err != nil {
+			return fmt.Errorf("failed to configure schedule for adapter %s: %w", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	receiver := a.newWebhookReceiver(sched)
+	healthServer := a.startHealthServer(ctx, sched, receiver)
+	webhookServer := a.startWebhookServer(receiver)
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		sched.Start(ctx)
+		close(schedulerDone)
+	}()
+
+	// Run the initial sync in the background, so a shutdown during it gets the same grace
+	// period as a scheduled sync
+	a.background.Add(1)
+	go func() {
+		defer a.background.Done()
+
+		// Initialize file index from OpenWebUI
+		logrus.Info("Initializing file index from OpenWebUI...")
+		if err := a.manager.InitializeFileIndex(ctx, a.adapters); err != nil {
+			logrus.Errorf("Failed to initialize file index: %v", err)
+			// Continue even if initialization fails
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Events queued until now are synced once the index knows the files in OpenWebUI
+		if receiver != nil {
+			a.background.Add(1)
+			go func() {
+				defer a.background.Done()
+				receiver.Run(ctx)
+			}()
+		}
+
+		// Run initial sync
+		logrus.Info("Running initial sync...")
+		if err := sched.RunSyncWithContext(ctx); err != nil && !errors.Is(err, scheduler.ErrSyncRunning) {
+			logrus.Errorf("Initial sync failed: %v", err)
+		}
+
+		// Sync local folder changes as they happen; the scheduled sync keeps running either way
+		if a.localAdapter != nil && a.cfg.LocalFolders.Watch && ctx.Err() == nil {
+			watching, err := a.localAdapter.Watch(ctx, func(ctx context.Context, file *adapter.File) error {
+				return a.manager.SyncChangedFile(ctx, file, a.localAdapter.Name())
+			})
+			if err != nil {
+				logrus.Warnf("Failed to watch local folders, falling back to interval polling: %v", err)
+			} else {
+				<-watching
+			}
+		}
+	}()
+
+	<-ctx.Done()
+	logrus.Info("Shutting down gracefully...")
+
+	if healthServer != nil {
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer stopCancel()
+		if err := healthServer.Stop(stopCtx); err != nil {
+			logrus.Warnf("Failed to stop health server: %v", err)
+		}
+	}
+	if webhookServer != nil {
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer stopCancel()
+		if err := webhookServer.Shutdown(stopCtx); err != nil {
+			logrus.Warnf("Failed to stop webhook server: %v", err)
+		}
+	}
+	<-schedulerDone
+
+	if err := a.waitForSyncs(sched); err != nil {
+		return err
+	}
+
+	logrus.Info("Graceful shutdown completed")
+	return nil
+}
+
+// waitForSyncs gives running syncs, including the initial sync, webhook syncs and watched
+// changes, up to sync.shutdown_timeout to stop after cancellation
+func (a *App) waitForSyncs(sched *scheduler.Scheduler) error {
+	timeout := a.cfg.Sync.ShutdownTimeout
+	if timeout <= 0 {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		a.background.Wait()
+		sched.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("running sync did not stop within %v, forcing exit", timeout)
+	}
+}
+
+// newWebhookReceiver returns a receiver syncing the items named by webhook events through the
+// scheduler, or nil when webhooks are disabled
+func (a *App) newWebhookReceiver(sched *scheduler.Scheduler) *webhook.Receiver {
+	if !a.cfg.Webhooks.Enabled {
+		return nil
+	}
+	return webhook.NewReceiver(a.cfg.Webhooks, func(ctx context.Context, item webhook.Item) error {
+		for _, adpt := range a.adapters {
+			if adpt.Name() == item.Source {
+				return sched.RunItemSyncWithContext(ctx, adpt, item.ID)
+			}
+		}
+		return fmt.Errorf("adapter %s is not enabled", item.Source)
+	})
+}
+
+// startWebhookServer serves the webhook receiver on webhooks.port and returns the server, or
+// nil when there is no receiver or it shares the health server
+func (a *App) startWebhookServer(receiver *webhook.Receiver) *http.Server {
+	if receiver == nil || a.cfg.Webhooks.Port == 0 {
+		return nil
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", a.cfg.Webhooks.Port),
+		Handler:           receiver.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logrus.Infof("Receiving webhooks on port %d", a.cfg.Webhooks.Port)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("Webhook server error: %v", err)
+		}
+	}()
+	return server
+}
+
+// startHealthServer serves /health, /ready, /status and /sync when enabled, plus the webhook
+// receiver unless it has its own port, and returns the server, or nil when the health server
+// is disabled
+func (a *App) startHealthServer(ctx context.Context, sched *scheduler.Scheduler, receiver *webhook.Receiver) *health.Server {
+	if !a.cfg.HealthEnabled {
+		logrus.Info("Health server disabled")
+		return nil
+	}
+
+	healthServer := health.NewServer(a.cfg.HealthPort)
+	healthServer.SetSyncTrigger(func() (<-chan error, error) {
+		return sched.StartSyncWithContext(ctx)
+	})
+	healthServer.SetStatusProvider(func() any {
+		status := a.manager.Status()
+		if next := sched.NextSync(); !next.IsZero() {
+			status.NextSync = &next
+		}
+		return status
+	})
+	readyClient, err := newProbeClient(a.cfg, a.cfg.OpenWebUI.BaseURL, a.cfg.OpenWebUI.APIKey)
+	if err != nil {
+		logrus.Warnf("Failed to configure readiness client: %v", err)
+	}
+	healthServer.SetReadinessCheck(health.OpenWebUICheck(readyClient))
+	if receiver != nil && a.cfg.Webhooks.Port == 0 {
+		healthServer.Handle("/webhooks/", receiver.Handler())
+	}
+	go func() {
+		if err := healthServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("Health server error: %v", err)
+		}
+	}()
+	return healthServer
+}
+
+// newProbeClient creates an OpenWebUI client for readiness and connection checks. Probes should
+// fail fast, so the client doesn't retry. A client is returned even if the TLS settings fail to
+// load.
+func newProbeClient(cfg *config.Config, baseURL, apiKey string) (*openwebui.Client, error) {
+	client := openwebui.NewClient(baseURL, apiKey)
+	client.SetRetryConfig(utils.RetryConfig{})
+	client.SetHeaders(cfg.HTTP.UserAgent, cfg.HTTP.Headers)
+	return client, client.SetHTTPConfig(0, cfg.OpenWebUI.InsecureSkipVerify, cfg.OpenWebUI.CACertPath)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// newTestApp creates an App with every adapter disabled, talking to a fake OpenWebUI
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]interface{}{})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		Schedule:  config.ScheduleConfig{Interval: time.Hour},
+		Storage:   config.StorageConfig{Path: t.TempDir()},
+		OpenWebUI: config.OpenWebUIConfig{BaseURL: server.URL, APIKey: "test-api-key"},
+	}
+
+	app, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	if len(app.adapters) != 0 {
+		t.Fatalf("Expected no adapters, got %d", len(app.adapters))
+	}
+	return app
+}
+
+func TestApp_RunOnce_DisabledAdapters(t *testing.T) {
+	app := newTestApp(t)
+
+	if err := app.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := app.Manager().Report()
+	if report.Succeeded != 0 || report.Failed != 0 || report.Skipped != 0 {
+		t.Errorf("Expected an empty report, got %+v", report)
+	}
+}
+
+func TestApp_Run_StopsOnCancel(t *testing.T) {
+	app := newTestApp(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
+
+func TestApp_Run_WaitsForBackgroundTasks(t *testing.T) {
+	app := newTestApp(t)
+	app.cfg.Sync.ShutdownTimeout = 5 * time.Second
+	app.cfg.LocalFolders = config.LocalFolderConfig{
+		Enabled:  true,
+		Watch:    true,
+		Mappings: []config.LocalFolderMapping{{FolderPath: t.TempDir(), KnowledgeID: "knowledge-id"}},
+	}
+	localAdapter, err := adapter.NewLocalFolderAdapter(app.cfg.LocalFolders)
+	if err != nil {
+		t.Fatalf("Failed to create local folder adapter: %v", err)
+	}
+	app.localAdapter = localAdapter
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+
+	// The initial sync and the watcher stopped before Run returned
+	stopped := make(chan struct{})
+	go func() {
+		app.background.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected the background tasks of Run to have stopped")
+	}
+}
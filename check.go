@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// connectionCheckTimeout bounds each connection check of --validate-config
+const connectionCheckTimeout = 30 * time.Second
+
+// connectionCheck is the outcome of checking the URL and credentials of OpenWebUI or a source
+type connectionCheck struct {
+	Name string
+	Err  error
+}
+
+// checkConnections sends a cheap authenticated request to OpenWebUI, every additional OpenWebUI
+// target and every enabled adapter. Adapters that fail to be created are reported as failed.
+func checkConnections(ctx context.Context, cfg *config.Config) []connectionCheck {
+	var checks []connectionCheck
+	check := func(name string, fn func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(ctx, connectionCheckTimeout)
+		defer cancel()
+		checks = append(checks, connectionCheck{Name: name, Err: fn(ctx)})
+	}
+
+	check("OpenWebUI", openwebuiCheck(cfg, cfg.OpenWebUI.BaseURL, cfg.OpenWebUI.APIKey))
+	for _, target := range cfg.OpenWebUI.Targets {
+		check("OpenWebUI target "+target.Name, openwebuiCheck(cfg, target.BaseURL, target.APIKey))
+	}
+
+	// Nothing is synced, so Confluence page versions aren't recorded
+	for _, factory := range adapterFactories(cfg, false) {
+		if !factory.enabled {
+			continue
+		}
+		check(factory.label, func(ctx context.Context) error {
+			adpt, err := factory.create()
+			if err != nil {
+				return fmt.Errorf("failed to create adapter: %w", err)
+			}
+			if checker, ok := adpt.(adapter.ConnectionChecker); ok {
+				return checker.CheckConnection(ctx)
+			}
+			return nil
+		})
+	}
+	return checks
+}
+
+// openwebuiCheck returns a check that lists the knowledge bases of an OpenWebUI instance
+func openwebuiCheck(cfg *config.Config, baseURL, apiKey string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		client, err := newProbeClient(cfg, baseURL, apiKey)
+		if err != nil {
+			return fmt.Errorf("failed to configure client: %w", err)
+		}
+		if _, err := client.ListKnowledge(ctx); err != nil {
+			return fmt.Errorf("failed to list knowledge bases: %w", err)
+		}
+		return nil
+	}
+}
+
+// printConnectionChecks writes a pass/fail table of the checks to w and reports whether every
+// check passed
+func printConnectionChecks(w io.Writer, checks []connectionCheck) bool {
+	passed := true
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tRESULT\tERROR")
+	for _, check := range checks {
+		if check.Err != nil {
+			passed = false
+			fmt.Fprintf(tw, "%s\tFAIL\t%v\n", check.Name, check.Err)
+		} else {
+			fmt.Fprintf(tw, "%s\tPASS\t\n", check.Name)
+		}
+	}
+	tw.Flush()
+	return passed
+}
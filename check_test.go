@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestCheckConnections(t *testing.T) {
+	// Accepts the key "valid-key" only
+	openwebui := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer valid-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer openwebui.Close()
+
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/myself" {
+			t.Errorf("Unexpected Jira request for %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"accountId": "123"}`))
+	}))
+	defer jira.Close()
+
+	cfg := &config.Config{
+		Storage: config.StorageConfig{Path: t.TempDir()},
+		OpenWebUI: config.OpenWebUIConfig{
+			BaseURL: openwebui.URL,
+			APIKey:  "valid-key",
+			Targets: []config.OpenWebUITarget{{Name: "staging", BaseURL: openwebui.URL, APIKey: "revoked-key"}},
+		},
+		Jira: config.JiraConfig{
+			Enabled:         true,
+			BaseURL:         jira.URL,
+			Username:        "test@example.com",
+			APIKey:          "test-key",
+			ProjectMappings: []config.JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "knowledge-id"}},
+		},
+		// Fails to be created without mappings
+		LocalFolders: config.LocalFolderConfig{Enabled: true},
+	}
+
+	checks := checkConnections(context.Background(), cfg)
+
+	expected := map[string]bool{ // name -> passed
+		"OpenWebUI":                true,
+		"OpenWebUI target staging": false,
+		"Local Folders":            false,
+		"Jira":                     true,
+	}
+	if len(checks) != len(expected) {
+		t.Fatalf("Expected %d checks, got %+v", len(expected), checks)
+	}
+	for _, check := range checks {
+		passed, ok := expected[check.Name]
+		if !ok {
+			t.Errorf("Unexpected check %s", check.Name)
+			continue
+		}
+		if passed != (check.Err == nil) {
+			t.Errorf("Expected check %s passed = %v, got error %v", check.Name, passed, check.Err)
+		}
+	}
+}
+
+func TestPrintConnectionChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []connectionCheck
+		passed   bool
+		expected []string // lines of the table
+	}{
+		{
+			name:     "all passed",
+			checks:   []connectionCheck{{Name: "OpenWebUI"}, {Name: "GitHub"}},
+			passed:   true,
+			expected: []string{"SOURCE     RESULT  ERROR", "OpenWebUI  PASS", "GitHub     PASS"},
+		},
+		{
+			name:     "one failed",
+			checks:   []connectionCheck{{Name: "OpenWebUI"}, {Name: "Slack", Err: errors.New("invalid_auth")}},
+			passed:   false,
+			expected: []string{"SOURCE     RESULT  ERROR", "OpenWebUI  PASS", "Slack      FAIL    invalid_auth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if passed := printConnectionChecks(&buf, tt.checks); passed != tt.passed {
+				t.Errorf("Expected passed = %v, got %v", tt.passed, passed)
+			}
+
+			lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("Expected %d lines, got %q", len(tt.expected), buf.String())
+			}
+			for i, line := range lines {
+				if strings.TrimRight(line, " ") != tt.expected[i] {
+					t.Errorf("Line %d: expected %q, got %q", i, tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package adapter
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/openwebui-content-sync/internal/logging"
 )
 
 // File represents a file from an external source
@@ -14,6 +17,9 @@ type File struct {
 	Size        int64     `json:"size"`
 	Source      string    `json:"source"`
 	KnowledgeID string    `json:"knowledge_id,omitempty"` // Optional: specific knowledge base ID for this file
+	ContentType string    `json:"content_type,omitempty"` // Optional: MIME type sent on upload (detected from the extension if empty)
+	ID          string    `json:"id,omitempty"`           // Optional: stable identity within the source (e.g. a Slack channel ID), so a renamed file replaces its previous version
+	Unchanged   bool      `json:"unchanged,omitempty"`    // Optional: the adapter skipped reading the file as it didn't change since the last fetch; Content is empty and Hash is the previous one
 }
 
 // Adapter defines the interface for data source adapters
@@ -30,3 +36,84 @@ type Adapter interface {
 	// SetLastSync updates the last sync timestamp
 	SetLastSync(t time.Time)
 }
+
+// KnowledgeIDProvider is implemented by adapters that can report the knowledge bases they
+// sync to without fetching content. Adapters with incremental sync state implement it so that
+// initializing the file index does not consume a fetch that was never uploaded.
+type KnowledgeIDProvider interface {
+	// KnowledgeIDs returns the knowledge base IDs the adapter's files are assigned to
+	KnowledgeIDs() []string
+}
+
+// CompleteFetcher is implemented by adapters whose FetchFiles returns every file of their
+// source rather than only the changed ones. The sync manager only removes indexed files that
+// are missing from a fetch for adapters that report the fetch as complete, so adapters that
+// skip unchanged content, or that skipped files after an error, never lose their files.
+type CompleteFetcher interface {
+	// FetchComplete reports whether the last FetchFiles call returned every file of the source
+	FetchComplete() bool
+}
+
+// CoverageReporter is implemented by adapters that can report how much of their source the last
+// fetch covered, such as the Slack channels skipped after errors. The sync manager includes the
+// report in the adapter's status.
+type CoverageReporter interface {
+	// Coverage returns a JSON-serializable summary of the last FetchFiles call
+	Coverage() any
+}
+
+// ContentLoader is implemented by adapters that return files marked Unchanged without their
+// content. The sync manager loads the content of such files when it has no record of the
+// version, e.g. because the previous upload failed or the file index was lost.
+type ContentLoader interface {
+	// LoadContent reads the content of an unchanged file and clears its Unchanged mark
+	LoadContent(ctx context.Context, file *File) error
+}
+
+// SyncRecorder is implemented by adapters that skip content they synced before. The sync
+// manager reports each fetched file once it is in OpenWebUI and commits the reports after
+// the adapter's files were synced, so content that failed to sync is fetched again.
+type SyncRecorder interface {
+	// FileSynced records that a file returned by the last FetchFiles or FetchItem call synced
+	FileSynced(file *File)
+
+	// CommitSynced persists the files recorded since the last commit
+	CommitSynced() error
+}
+
+// FullFetcher is implemented by adapters whose FetchFiles skips content they returned before.
+// The sync manager requests a full fetch when files that failed to sync are due for their
+// retry, or when an additional OpenWebUI target lacks content the next incremental fetch
+// wouldn't return, such as a newly added target.
+type FullFetcher interface {
+	// RequestFullFetch makes the next FetchFiles call return every file of the source
+	RequestFullFetch()
+}
+
+// ConnectionChecker is implemented by adapters that can verify their credentials with a single
+// cheap request, used by --validate-config before a deployment
+type ConnectionChecker interface {
+	// CheckConnection returns an error when the source can't be reached or rejects the credentials
+	CheckConnection(ctx context.Context) error
+}
+
+// LoggerSetter is implemented by adapters that log through a logging.Logger rather than the
+// global logrus logger, so the app can add the adapter's name to each message and tests can
+// capture the logs
+type LoggerSetter interface {
+	// SetLogger sets the logger the adapter writes to
+	SetLogger(logger logging.Logger)
+}
+
+// ItemFetcher is implemented by adapters that can fetch a single item of their source, such as
+// a Slack channel, Confluence page or Jira issue named by a webhook event, without fetching the
+// rest of the source
+type ItemFetcher interface {
+	// FetchItem returns the files of the item with the given ID. It returns no files for items
+	// the adapter doesn't sync, and ErrUnknownItem if it can't tell whether it syncs the item.
+	FetchItem(ctx context.Context, id string) ([]*File, error)
+}
+
+// ErrUnknownItem is returned by FetchItem for items the adapter can only place by fetching the
+// whole source, such as a page that may match a CQL mapping
+var ErrUnknownItem = errors.New("item can only be synced with the whole source")
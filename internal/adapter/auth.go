@@ -0,0 +1,24 @@
+package adapter
+
+import "net/http"
+
+// setAuth authenticates a request to Confluence or Jira: with authType bearer, the API key is
+// sent as a bearer token, such as a personal access token of a Data Center instance; otherwise
+// the username and API key are sent with basic auth, as Atlassian Cloud expects
+func setAuth(req *http.Request, authType, username, apiKey string) {
+	if authType == "bearer" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		return
+	}
+	req.SetBasicAuth(username, apiKey)
+}
+
+// authenticate sets the configured credentials on a request to Confluence
+func (c *ConfluenceAdapter) authenticate(req *http.Request) {
+	setAuth(req, c.config.AuthType, c.config.Username, c.config.APIKey)
+}
+
+// authenticate sets the configured credentials on a request to Jira
+func (j *JiraAdapter) authenticate(req *http.Request) {
+	setAuth(req, j.config.AuthType, j.config.Username, j.config.APIKey)
+}
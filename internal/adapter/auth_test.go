@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestAdapters_AuthorizationHeader(t *testing.T) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("jdoe:secret-token"))
+
+	tests := []struct {
+		name     string
+		authType string
+		username string
+		expected string
+	}{
+		{name: "default", username: "jdoe", expected: basic},
+		{name: "basic", authType: "basic", username: "jdoe", expected: basic},
+		{name: "bearer", authType: "bearer", expected: "Bearer secret-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			headers := make(map[string]string) // request path -> Authorization header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				headers[r.URL.Path] = r.Header.Get("Authorization")
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "100", "key": "PROJ-1", "title": "Runbook", "fields": {"summary": "Broken build"}, "results": []}`))
+			}))
+			defer server.Close()
+
+			confluence, err := NewConfluenceAdapter(config.ConfluenceConfig{
+				BaseURL:            server.URL,
+				Username:           tt.username,
+				APIKey:             "secret-token",
+				AuthType:           tt.authType,
+				ParentPageMappings: []config.ParentPageMapping{{ParentPageID: "100", KnowledgeID: "docs"}},
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+			jira, err := NewJiraAdapter(config.JiraConfig{
+				BaseURL:         server.URL,
+				Username:        tt.username,
+				APIKey:          "secret-token",
+				AuthType:        tt.authType,
+				ProjectMappings: []config.JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "issues"}},
+			})
+			if err != nil {
+				t.Fatalf("NewJiraAdapter() error = %v", err)
+			}
+
+			ctx := context.Background()
+			if _, err := confluence.fetchPageByID(ctx, "100"); err != nil {
+				t.Fatalf("fetchPageByID() error = %v", err)
+			}
+			if err := confluence.CheckConnection(ctx); err != nil {
+				t.Fatalf("Confluence CheckConnection() error = %v", err)
+			}
+			if _, err := jira.fetchIssue(ctx, "PROJ-1"); err != nil {
+				t.Fatalf("fetchIssue() error = %v", err)
+			}
+			if err := jira.CheckConnection(ctx); err != nil {
+				t.Fatalf("Jira CheckConnection() error = %v", err)
+			}
+
+			if len(headers) != 4 {
+				t.Fatalf("Expected 4 requests, got %v", headers)
+			}
+			for path, value := range headers {
+				if value != tt.expected {
+					t.Errorf("Expected Authorization %q for %s, got %q", tt.expected, path, value)
+				}
+			}
+		})
+	}
+}
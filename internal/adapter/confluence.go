@@ -5,12 +5,14 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
@@ -18,6 +20,9 @@ import (
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/openwebui-content-sync/internal/utils"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
 )
@@ -25,12 +30,25 @@ import (
 // ConfluenceAdapter implements the Adapter interface for Confluence spaces
 type ConfluenceAdapter struct {
 	client             *http.Client
+	retryConfig        utils.RetryConfig // retries of network errors, 429 and 5xx responses
 	config             config.ConfluenceConfig
 	lastSync           time.Time
 	spaces             []string
 	parentPageIDs      []string
 	spaceMappings      map[string]string // space_key -> knowledge_id mapping
 	parentPageMappings map[string]string // parent_page_id -> knowledge_id mapping
+	cqlMappings        []config.CQLMapping
+	store              storage.Storage            // persists the synced versions, nil to keep them in memory only
+	versions           map[string]syncedContent   // page/attachment ID -> version and file at the last sync
+	pending            map[*File]contentVersion   // versions of the fetched files, recorded once they synced
+	loaders            map[*File]unchangedContent // unchanged files of the last fetch, loaded on demand
+	versionsMu         sync.Mutex                 // guards versions, pending and loaders while files are synced
+	fullFetch          bool                       // set by RequestFullFetch to ignore the synced versions for one fetch
+	incomplete         bool                       // set when the last fetch skipped content after an error
+	pageTitles         map[string]string          // page ID -> title, used to name ancestors
+	filenameOwners     map[string]string          // knowledge ID + filename -> ID of the content synced under it this run
+	spaceIDs           map[string]string          // space key -> space ID, resolved when a single page is fetched
+	logger             logging.Logger             // nil to log to the global logrus logger
 }
 
 // ConfluenceSpace represents a space from Confluence API
@@ -119,17 +137,18 @@ type ConfluenceChildPageList struct {
 
 // ConfluenceAttachment represents an attachment
 type ConfluenceAttachment struct {
-	ID        string                 `json:"id"`
-	Title     string                 `json:"title"`
-	MediaType string                 `json:"mediaType"`
-	FileSize  int                    `json:"fileSize"`
-	Comment   string                 `json:"comment"`
-	PageID    string                 `json:"pageId"`
-	SpaceID   string                 `json:"spaceId"`
-	Version   ConfluenceVersion      `json:"version"`
-	CreatedAt string                 `json:"createdAt"`
-	AuthorID  string                 `json:"authorId"`
-	Links     map[string]interface{} `json:"_links"`
+	ID           string                 `json:"id"`
+	Title        string                 `json:"title"`
+	MediaType    string                 `json:"mediaType"`
+	FileSize     int                    `json:"fileSize"`
+	Comment      string                 `json:"comment"`
+	PageID       string                 `json:"pageId"`
+	SpaceID      string                 `json:"spaceId"`
+	Version      ConfluenceVersion      `json:"version"`
+	CreatedAt    string                 `json:"createdAt"`
+	AuthorID     string                 `json:"authorId"`
+	DownloadLink string                 `json:"downloadLink"`
+	Links        map[string]interface{} `json:"_links"`
 }
 
 // ConfluenceAttachmentList represents the response from listing attachments
@@ -197,12 +216,13 @@ type ConfluenceGroups struct {
 	Size  int           `json:"size"`
 }
 
-// NewConfluenceAdapter creates a new Confluence adapter
-func NewConfluenceAdapter(cfg config.ConfluenceConfig) (*ConfluenceAdapter, error) {
+// NewConfluenceAdapter creates a new Confluence adapter. Synced page versions are persisted in
+// store so unchanged pages are not re-fetched; pass a nil store to disable persistence.
+func NewConfluenceAdapter(cfg config.ConfluenceConfig, store storage.Storage) (*ConfluenceAdapter, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("confluence base URL is required")
 	}
-	if cfg.Username == "" {
+	if cfg.Username == "" && cfg.AuthType != "bearer" {
 		return nil, fmt.Errorf("confluence username is required")
 	}
 	if cfg.APIKey == "" {
@@ -231,24 +251,56 @@ func NewConfluenceAdapter(cfg config.ConfluenceConfig) (*ConfluenceAdapter, erro
 		}
 	}
 
+	// Process CQL mappings
+	var cqlMappings []config.CQLMapping
+	for _, mapping := range cfg.CQLMappings {
+		if strings.TrimSpace(mapping.CQL) != "" && mapping.KnowledgeID != "" {
+			cqlMappings = append(cqlMappings, mapping)
+		}
+	}
+
 	// If no mappings are configured, return error
-	if len(spaces) == 0 && len(parentPageIDs) == 0 {
-		return nil, fmt.Errorf("at least one confluence space or parent page mapping must be configured")
+	if len(spaces) == 0 && len(parentPageIDs) == 0 && len(cqlMappings) == 0 {
+		return nil, fmt.Errorf("at least one confluence space, parent page or CQL mapping must be configured")
 	}
 
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
-	return &ConfluenceAdapter{
+	adapter := &ConfluenceAdapter{
 		client:             client,
+		retryConfig:        newRetryConfig(cfg.Retry, utils.DefaultRetryConfig()),
 		config:             cfg,
 		spaces:             spaces,
 		parentPageIDs:      parentPageIDs,
 		spaceMappings:      spaceMappings,
 		parentPageMappings: parentPageMappings,
+		cqlMappings:        cqlMappings,
 		lastSync:           time.Now(),
-	}, nil
+		versions:           make(map[string]syncedContent),
+		pending:            make(map[*File]contentVersion),
+		loaders:            make(map[*File]unchangedContent),
+	}
+
+	if store != nil {
+		adapter.store = store
+		if err := adapter.loadVersions(); err != nil {
+			logrus.Warnf("Failed to load Confluence page versions, doing a full sync: %v", err)
+		}
+	}
+
+	return adapter, nil
+}
+
+// SetHeaders sets the User-Agent and extra headers sent with every request to Confluence
+func (c *ConfluenceAdapter) SetHeaders(userAgent string, headers map[string]string) {
+	utils.WithHeaders(c.client, userAgent, headers)
+}
+
+// do sends a request to Confluence, retrying network errors, 429 and 5xx responses
+func (c *ConfluenceAdapter) do(req *http.Request) (*http.Response, error) {
+	return utils.DoWithRetry(c.client, req, c.retryConfig)
 }
 
 // Name returns the adapter name
@@ -256,103 +308,111 @@ func (c *ConfluenceAdapter) Name() string {
 	return "confluence"
 }
 
-// FetchFiles fetches files from all configured Confluence spaces and parent pages
+// SetLogger sets the logger the adapter writes to
+func (c *ConfluenceAdapter) SetLogger(logger logging.Logger) {
+	c.logger = logger
+}
+
+// log returns the adapter's logger
+func (c *ConfluenceAdapter) log() logging.Logger {
+	if c.logger == nil {
+		return logging.Default()
+	}
+	return c.logger
+}
+
+// FetchFiles fetches files from all configured Confluence spaces, parent pages and CQL queries
 func (c *ConfluenceAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	var allFiles []*File
+	c.resetFilenameClaims()
+	c.resetPendingVersions()
+	c.incomplete = false
 
-	logrus.Debugf("Confluence adapter config - ParentPageIDs: %v, Spaces: %v, BaseURL: %s, Username: %s",
+	c.log().Debugf("Confluence adapter config - ParentPageIDs: %v, Spaces: %v, BaseURL: %s, Username: %s",
 		c.parentPageIDs, c.spaces, c.config.BaseURL, c.config.Username)
 
 	// Process parent pages if configured
 	if len(c.parentPageIDs) > 0 {
-		logrus.Debugf("Using PARENT PAGE mode - Processing %d parent pages", len(c.parentPageIDs))
+		c.log().Debugf("Using PARENT PAGE mode - Processing %d parent pages", len(c.parentPageIDs))
 		for _, parentPageID := range c.parentPageIDs {
-			logrus.Debugf("Fetching files from Confluence parent page: %s", parentPageID)
+			c.log().Debugf("Fetching files from Confluence parent page: %s", parentPageID)
 
 			// Step 1: Get the parent page details
 			parentPage, err := c.fetchPageByID(ctx, parentPageID)
 			if err != nil {
-				logrus.Errorf("Failed to fetch parent page %s: %v", parentPageID, err)
+				c.log().Errorf("Failed to fetch parent page %s: %v", parentPageID, err)
+				c.incomplete = true
 				continue
 			}
 
-			logrus.Debugf("Parent page: %s (Space: %s)", parentPage.Title, parentPage.SpaceID)
+			c.log().Debugf("Parent page: %s (Space: %s)", parentPage.Title, parentPage.SpaceID)
 
 			// Step 2: Fetch all sub-pages under this parent
 			pages, err := c.fetchSubPages(ctx, parentPageID)
 			if err != nil {
-				logrus.Errorf("Failed to fetch sub-pages for parent %s: %v", parentPageID, err)
+				c.log().Errorf("Failed to fetch sub-pages for parent %s: %v", parentPageID, err)
+				c.incomplete = true
 				continue
 			}
 
 			// Include the parent page itself in the results
 			pages = append([]ConfluencePage{parentPage}, pages...)
 
-			logrus.Debugf("Found %d pages under parent page %s", len(pages), parentPage.Title)
+			c.log().Debugf("Found %d pages under parent page %s", len(pages), parentPage.Title)
 
 			// Step 3: Process each page
 			knowledgeID := c.parentPageMappings[parentPageID]
-			for _, page := range pages {
-				file, err := c.processPage(ctx, page, knowledgeID)
-				if err != nil {
-					logrus.Errorf("Failed to process page %s: %v", page.Title, err)
-					continue
-				}
-				allFiles = append(allFiles, file)
-			}
+			allFiles = append(allFiles, c.processPages(ctx, pages, knowledgeID)...)
 		}
 	}
 
 	// Process spaces if configured
 	if len(c.spaces) > 0 {
-		logrus.Debugf("Using SPACE mode - Processing %d spaces", len(c.spaces))
+		c.log().Debugf("Using SPACE mode - Processing %d spaces", len(c.spaces))
 		for _, spaceKey := range c.spaces {
-			logrus.Debugf("Fetching files from Confluence space: %s", spaceKey)
+			c.log().Debugf("Fetching files from Confluence space: %s", spaceKey)
 
 			// Step 1: Get space ID from space key
 			spaceID, err := c.getSpaceID(ctx, spaceKey)
 			if err != nil {
-				logrus.Errorf("Failed to get space ID for %s: %v", spaceKey, err)
+				c.log().Errorf("Failed to get space ID for %s: %v", spaceKey, err)
+				c.incomplete = true
 				continue
 			}
 
-			logrus.Debugf("Space %s has ID: %s", spaceKey, spaceID)
+			c.log().Debugf("Space %s has ID: %s", spaceKey, spaceID)
 
 			// Step 2: Fetch pages from the space
 			pages, err := c.fetchSpacePages(ctx, spaceID)
 			if err != nil {
-				logrus.Errorf("Failed to fetch pages from space %s: %v", spaceKey, err)
+				c.log().Errorf("Failed to fetch pages from space %s: %v", spaceKey, err)
+				c.incomplete = true
 				continue
 			}
 
-			logrus.Debugf("Found %d pages in space %s", len(pages), spaceKey)
+			c.log().Debugf("Found %d pages in space %s", len(pages), spaceKey)
 
 			// Step 3: Process each page
 			knowledgeID := c.spaceMappings[spaceKey]
-			for _, page := range pages {
-				file, err := c.processPage(ctx, page, knowledgeID)
-				if err != nil {
-					logrus.Errorf("Failed to process page %s: %v", page.Title, err)
-					continue
-				}
-				allFiles = append(allFiles, file)
-			}
+			allFiles = append(allFiles, c.processPages(ctx, pages, knowledgeID)...)
 
 			// Step 4: Fetch blog posts from the space
 			if c.config.IncludeBlogPosts {
 				blogposts, err := c.fetchSpaceBlogposts(ctx, spaceID)
 				if err != nil {
-					logrus.Errorf("Failed to fetch blog posts from space %s: %v", spaceKey, err)
+					c.log().Errorf("Failed to fetch blog posts from space %s: %v", spaceKey, err)
+					c.incomplete = true
 					continue
 				}
 
-				logrus.Debugf("Found %d blog posts in space %s", len(blogposts), spaceKey)
+				c.log().Debugf("Found %d blog posts in space %s", len(blogposts), spaceKey)
 
 				// Step 5: Process each blog post
 				for _, blogpost := range blogposts {
 					file, err := c.processBlogpost(ctx, blogpost, knowledgeID)
 					if err != nil {
-						logrus.Errorf("Failed to process blog post %s: %v", blogpost.Title, err)
+						c.log().Errorf("Failed to process blog post %s: %v", blogpost.Title, err)
+						c.incomplete = true
 						continue
 					}
 					allFiles = append(allFiles, file)
@@ -361,12 +421,263 @@ func (c *ConfluenceAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 		}
 	}
 
+	// Process CQL queries if configured
+	for _, mapping := range c.cqlMappings {
+		c.log().Debugf("Fetching files from Confluence CQL query: %s", mapping.CQL)
+
+		pages, err := c.searchPages(ctx, mapping.CQL)
+		if err != nil {
+			c.log().Errorf("Failed to search pages with CQL %q: %v", mapping.CQL, err)
+			c.incomplete = true
+			continue
+		}
+
+		c.log().Debugf("Found %d pages for CQL query %s", len(pages), mapping.CQL)
+		allFiles = append(allFiles, c.processPages(ctx, pages, mapping.KnowledgeID)...)
+	}
+
+	c.fullFetch = false
 	c.lastSync = time.Now()
 	return allFiles, nil
 }
 
+// processPages processes pages and, if enabled, their attachments. Pages filtered out by
+// label are dropped, and pages whose version is unchanged since the last sync are returned
+// marked Unchanged without fetching their body.
+func (c *ConfluenceAdapter) processPages(ctx context.Context, pages []ConfluencePage, knowledgeID string) []*File {
+	pages = c.filterPagesByLabels(ctx, pages)
+	if c.usesAncestors() {
+		c.rememberPageTitles(pages)
+	}
+
+	// Unchanged pages keep the filename they synced under, including their ancestors' titles.
+	// They claim it before any changed page is named, so a changed page listed first can't
+	// take it over.
+	unchanged := make(map[string]syncedContent)
+	for _, page := range pages {
+		if synced, ok := c.unchangedVersion(page.ID, page.Version.Number); ok {
+			unchanged[page.ID] = synced
+			c.claimFilename(knowledgeID, page.ID, synced.Path)
+		}
+	}
+
+	var files []*File
+	skipped := 0
+	for _, page := range pages {
+		if synced, ok := unchanged[page.ID]; ok {
+			skipped++
+			version := contentVersion{id: page.ID, number: page.Version.Number}
+			files = append(files, c.unchangedFile(synced, knowledgeID, c.pageContentType(), version, func(ctx context.Context) ([]byte, error) {
+				file, err := c.processPage(ctx, page, knowledgeID)
+				if err != nil {
+					return nil, err
+				}
+				return file.Content, nil
+			}))
+		} else {
+			file, err := c.processPage(ctx, page, knowledgeID)
+			if err != nil {
+				c.log().Errorf("Failed to process page %s: %v", page.Title, err)
+				c.incomplete = true
+				continue
+			}
+			files = append(files, file)
+			c.pending[file] = contentVersion{id: page.ID, number: page.Version.Number}
+		}
+
+		// Attachments carry their own versions, since adding one doesn't always bump the page version
+		if c.config.IncludeAttachments {
+			files = append(files, c.processPageAttachments(ctx, page, knowledgeID)...)
+		}
+	}
+
+	if skipped > 0 {
+		c.log().Debugf("Skipped %d unchanged Confluence pages", skipped)
+	}
+	return files
+}
+
+// contentVersion is the version of a fetched page or attachment
+type contentVersion struct {
+	id     string
+	number int
+}
+
+// syncedContent is the version of a page or attachment at its last sync and the file it
+// synced as, so later fetches can report it unchanged without fetching it
+type syncedContent struct {
+	Number int    `json:"number"`
+	Path   string `json:"path,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+}
+
+// UnmarshalJSON also accepts the bare version numbers written by older versions, which lack
+// the file and are fetched once more
+func (s *syncedContent) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		return json.Unmarshal(data, &s.Number)
+	}
+	type plain syncedContent
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// unchangedContent loads the content of a file a fetch returned marked Unchanged
+type unchangedContent struct {
+	version contentVersion
+	load    func(ctx context.Context) ([]byte, error)
+}
+
+// resetPendingVersions forgets the versions of files fetched before that never synced
+func (c *ConfluenceAdapter) resetPendingVersions() {
+	c.versionsMu.Lock()
+	defer c.versionsMu.Unlock()
+	c.pending = make(map[*File]contentVersion)
+	c.loaders = make(map[*File]unchangedContent)
+}
+
+// FileSynced records the version of a fetched page or attachment once it synced, so it is
+// skipped while unchanged
+func (c *ConfluenceAdapter) FileSynced(file *File) {
+	c.versionsMu.Lock()
+	defer c.versionsMu.Unlock()
+
+	if version, ok := c.pending[file]; ok {
+		c.versions[version.id] = syncedContent{Number: version.number, Path: file.Path, Hash: file.Hash}
+		delete(c.pending, file)
+	}
+}
+
+// unchangedFile returns the file of unchanged content, marked Unchanged without its content,
+// and remembers how LoadContent fetches it
+func (c *ConfluenceAdapter) unchangedFile(synced syncedContent, knowledgeID, contentType string, version contentVersion, load func(ctx context.Context) ([]byte, error)) *File {
+	file := &File{
+		Path:        synced.Path,
+		Hash:        synced.Hash,
+		Modified:    c.lastSync,
+		Source:      "confluence",
+		KnowledgeID: knowledgeID,
+		ContentType: contentType,
+		Unchanged:   true,
+	}
+	c.versionsMu.Lock()
+	c.loaders[file] = unchangedContent{version: version, load: load}
+	c.versionsMu.Unlock()
+	return file
+}
+
+// LoadContent fetches a page or attachment that a fetch reported as unchanged, e.g. because
+// the file index lost it. The file keeps the name it synced under.
+func (c *ConfluenceAdapter) LoadContent(ctx context.Context, file *File) error {
+	c.versionsMu.Lock()
+	loader, ok := c.loaders[file]
+	c.versionsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("file %s was not fetched as unchanged", file.Path)
+	}
+
+	content, err := loader.load(ctx)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(content)
+	file.Content = content
+	file.Hash = base64.StdEncoding.EncodeToString(hash[:])
+	file.Size = int64(len(content))
+	file.Unchanged = false
+
+	c.versionsMu.Lock()
+	c.pending[file] = loader.version
+	c.versionsMu.Unlock()
+	return nil
+}
+
+// FetchComplete reports whether the last fetch returned every page, blog post and attachment
+// of the configured mappings, changed or not, so content removed from Confluence or filtered
+// out by label can be removed from OpenWebUI
+func (c *ConfluenceAdapter) FetchComplete() bool {
+	return !c.incomplete
+}
+
+// RequestFullFetch makes the next fetch return unchanged pages and attachments too, like
+// force_full_sync does for every fetch
+func (c *ConfluenceAdapter) RequestFullFetch() {
+	c.fullFetch = true
+}
+
+// CommitSynced writes the versions of the synced pages and attachments to disk
+func (c *ConfluenceAdapter) CommitSynced() error {
+	c.versionsMu.Lock()
+	defer c.versionsMu.Unlock()
+	return c.saveVersions()
+}
+
+// unchangedVersion returns the synced file of content with the given ID if it was already
+// synced at this version
+func (c *ConfluenceAdapter) unchangedVersion(id string, version int) (syncedContent, bool) {
+	if c.config.ForceFullSync || c.fullFetch || version == 0 {
+		return syncedContent{}, false
+	}
+	synced, ok := c.versions[id]
+	return synced, ok && synced.Number == version && synced.Path != ""
+}
+
+// confluenceVersionsKey is the storage key of the synced page versions
+const confluenceVersionsKey = "confluence/page_versions.json"
+
+// loadVersions loads the synced versions from the storage
+func (c *ConfluenceAdapter) loadVersions() error {
+	data, err := c.store.Read(confluenceVersionsKey)
+	if errors.Is(err, storage.ErrNotExist) {
+		return nil // Nothing synced yet
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read versions file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.versions); err != nil {
+		return fmt.Errorf("failed to unmarshal versions: %w", err)
+	}
+	return nil
+}
+
+// saveVersions writes the synced versions to the storage
+func (c *ConfluenceAdapter) saveVersions() error {
+	if c.store == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c.versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal versions: %w", err)
+	}
+
+	if err := c.store.Write(confluenceVersionsKey, data); err != nil {
+		return fmt.Errorf("failed to write versions file: %w", err)
+	}
+	return nil
+}
+
+// KnowledgeIDs returns the knowledge base IDs of the configured space, parent page and CQL mappings
+func (c *ConfluenceAdapter) KnowledgeIDs() []string {
+	knowledgeIDs := make([]string, 0, len(c.spaces)+len(c.parentPageIDs)+len(c.cqlMappings))
+	for _, spaceKey := range c.spaces {
+		knowledgeIDs = append(knowledgeIDs, c.spaceMappings[spaceKey])
+	}
+	for _, parentPageID := range c.parentPageIDs {
+		knowledgeIDs = append(knowledgeIDs, c.parentPageMappings[parentPageID])
+	}
+	for _, mapping := range c.cqlMappings {
+		knowledgeIDs = append(knowledgeIDs, mapping.KnowledgeID)
+	}
+	return knowledgeIDs
+}
+
 // getSpaceID retrieves the space ID from the space key
 func (c *ConfluenceAdapter) getSpaceID(ctx context.Context, spaceKey string) (string, error) {
+	if c.isServer() {
+		return c.getServerSpaceKey(ctx, spaceKey)
+	}
+
 	// URL encode the space key
 	encodedSpaceKey := url.QueryEscape(spaceKey)
 	url := fmt.Sprintf("%s/wiki/api/v2/spaces?keys=%s", c.config.BaseURL, encodedSpaceKey)
@@ -377,16 +688,15 @@ func (c *ConfluenceAdapter) getSpaceID(ctx context.Context, spaceKey string) (st
 	}
 
 	// Set authentication
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "OpenWebUI-Content-Sync/1.0")
 
-	logrus.Debugf("Confluence space API URL: %s", url)
-	logrus.Debugf("Confluence space key - Original: %s, Encoded: %s", spaceKey, encodedSpaceKey)
-	logrus.Debugf("Confluence auth - Username: %s, APIKey length: %d", c.config.Username, len(c.config.APIKey))
-	logrus.Debugf("Request headers: %+v", req.Header)
+	c.log().Debugf("Confluence space API URL: %s", url)
+	c.log().Debugf("Confluence space key - Original: %s, Encoded: %s", spaceKey, encodedSpaceKey)
+	c.log().Debugf("Confluence auth - Username: %s, APIKey length: %d", c.config.Username, len(c.config.APIKey))
+	c.log().Debugf("Request headers: %+v", req.Header)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to make request: %w", err)
 	}
@@ -394,7 +704,7 @@ func (c *ConfluenceAdapter) getSpaceID(ctx context.Context, spaceKey string) (st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Consume body for proper connection reuse
-		logrus.Errorf("Confluence space API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
+		c.log().Errorf("Confluence space API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
 		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -412,6 +722,10 @@ func (c *ConfluenceAdapter) getSpaceID(ctx context.Context, spaceKey string) (st
 
 // fetchSpacePages fetches all pages from a space using space ID
 func (c *ConfluenceAdapter) fetchSpacePages(ctx context.Context, spaceID string) ([]ConfluencePage, error) {
+	if c.isServer() {
+		return c.fetchServerSpaceContents(ctx, spaceID, "page")
+	}
+
 	var allPages []ConfluencePage
 	limit := c.config.PageLimit
 	if limit <= 0 {
@@ -427,12 +741,12 @@ func (c *ConfluenceAdapter) fetchSpacePages(ctx context.Context, spaceID string)
 		}
 
 		// Set authentication
-		req.SetBasicAuth(c.config.Username, c.config.APIKey)
+		c.authenticate(req)
 		req.Header.Set("Accept", "application/json")
 
-		logrus.Debugf("Confluence pages API URL: %s", url)
+		c.log().Debugf("Confluence pages API URL: %s", url)
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make request: %w", err)
 		}
@@ -490,7 +804,7 @@ func (c *ConfluenceAdapter) fetchSpacePages(ctx context.Context, spaceID string)
 		// Fetch users by IDs
 		users, err := c.fetchUsersByIds(ctx, accountIDs)
 		if err != nil {
-			logrus.Errorf("Failed to fetch users for pages: %v", err)
+			c.log().Errorf("Failed to fetch users for pages: %v", err)
 			// Continue without user information if fetch fails
 		} else {
 			// Update pages with user display names
@@ -507,6 +821,10 @@ func (c *ConfluenceAdapter) fetchSpacePages(ctx context.Context, spaceID string)
 
 // fetchPageByID fetches a specific page by its ID
 func (c *ConfluenceAdapter) fetchPageByID(ctx context.Context, pageID string) (ConfluencePage, error) {
+	if c.isServer() {
+		return c.fetchServerPage(ctx, pageID)
+	}
+
 	url := fmt.Sprintf("%s/wiki/api/v2/pages/%s", c.config.BaseURL, pageID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -515,11 +833,11 @@ func (c *ConfluenceAdapter) fetchPageByID(ctx context.Context, pageID string) (C
 	}
 
 	// Set authentication
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Confluence page API URL: %s", url)
-	resp, err := c.client.Do(req)
+	c.log().Debugf("Confluence page API URL: %s", url)
+	resp, err := c.do(req)
 	if err != nil {
 		return ConfluencePage{}, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -527,7 +845,7 @@ func (c *ConfluenceAdapter) fetchPageByID(ctx context.Context, pageID string) (C
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Consume body for proper connection reuse
-		logrus.Errorf("Confluence page API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
+		c.log().Errorf("Confluence page API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
 		return ConfluencePage{}, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -541,6 +859,10 @@ func (c *ConfluenceAdapter) fetchPageByID(ctx context.Context, pageID string) (C
 
 // fetchSubPages fetches all sub-pages under a specific parent page
 func (c *ConfluenceAdapter) fetchSubPages(ctx context.Context, parentPageID string) ([]ConfluencePage, error) {
+	if c.isServer() {
+		return c.fetchServerSubPages(ctx, parentPageID)
+	}
+
 	var allPages []ConfluencePage
 	limit := c.config.PageLimit
 	if limit <= 0 {
@@ -556,12 +878,12 @@ func (c *ConfluenceAdapter) fetchSubPages(ctx context.Context, parentPageID stri
 		}
 
 		// Set authentication
-		req.SetBasicAuth(c.config.Username, c.config.APIKey)
+		c.authenticate(req)
 		req.Header.Set("Accept", "application/json")
 
-		logrus.Debugf("Confluence sub-pages API URL: %s", url)
+		c.log().Debugf("Confluence sub-pages API URL: %s", url)
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make request: %w", err)
 		}
@@ -582,7 +904,8 @@ func (c *ConfluenceAdapter) fetchSubPages(ctx context.Context, parentPageID stri
 		for _, childPage := range childPageList.Results {
 			fullPage, err := c.fetchPageByID(ctx, childPage.ID)
 			if err != nil {
-				logrus.Errorf("Failed to fetch full page details for %s: %v", childPage.ID, err)
+				c.log().Errorf("Failed to fetch full page details for %s: %v", childPage.ID, err)
+				c.incomplete = true
 				continue
 			}
 			allPages = append(allPages, fullPage)
@@ -616,23 +939,33 @@ func (c *ConfluenceAdapter) processPage(ctx context.Context, page ConfluencePage
 		return nil, fmt.Errorf("failed to fetch page body: %w", err)
 	}
 
-	// Create filename from title
+	// trail holds the titles from the top-level page down to this page. Without its ancestors
+	// the page would be renamed or lose its breadcrumb, so it keeps its synced file until they
+	// can be fetched.
+	trail := []string{page.Title}
+	if c.usesAncestors() {
+		ancestors, err := c.pageAncestorTitles(ctx, page.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch ancestors: %w", err)
+		}
+		trail = append(ancestors, page.Title)
+	}
+
+	// Create filename from title, prefixed with the ancestors' titles if enabled
 	filename := c.SanitizeFilename(page.Title)
-	if c.config.UseMarkdownParser {
-		filename += ".md"
-	} else {
-		filename += ".txt"
+	if c.config.AncestorFilenames && len(trail) > 1 {
+		filename = c.ancestorFilename(trail)
 	}
+	filename = c.claimFilename(knowledgeID, page.ID, filename+c.pageExtension())
+	contentType := c.pageContentType()
 
-	// Format content as webui link + body content
-	webuiLink := ""
-	if webui, exists := page.Links["webui"]; exists {
-		if webuiStr, ok := webui.(string); ok {
-			webuiLink = webuiStr
-		}
+	// Format content as metadata header + source link + body content
+	breadcrumb := ""
+	if c.config.Breadcrumbs {
+		breadcrumb = fmt.Sprintf("Breadcrumb: %s\n", strings.Join(trail, " > "))
 	}
-	metaData := fmt.Sprintf("---\nAuthor: %s\nCreatedAt: %s\nLinkToPage: %s\nTitle: %s\n---", page.AuthorDisplayName, page.CreatedAt, c.config.BaseURL+"/wiki"+webuiLink, page.Title)
-	content := fmt.Sprintf("%s\n\n%s", metaData, pageBody)
+	metaData := fmt.Sprintf("---\nAuthor: %s\nCreatedAt: %s\n%sTitle: %s\n%s---", page.AuthorDisplayName, page.CreatedAt, c.linkToPage(page.Links), page.Title, breadcrumb)
+	content := fmt.Sprintf("%s\n\n%s%s", metaData, c.sourceLink(page.Links), pageBody)
 
 	// Create file content
 	fileContent := []byte(content)
@@ -649,11 +982,38 @@ func (c *ConfluenceAdapter) processPage(ctx context.Context, page ConfluencePage
 		Size:        int64(len(fileContent)),
 		Source:      "confluence",
 		KnowledgeID: knowledgeID,
+		ContentType: contentType,
 	}, nil
 }
 
+// linkToPage returns the "LinkToPage:" header line with the content's webui link, or "" when
+// include_source_link replaces it with a markdown link
+func (c *ConfluenceAdapter) linkToPage(links map[string]interface{}) string {
+	if c.config.IncludeSourceLink {
+		return ""
+	}
+	webui, _ := links["webui"].(string)
+	return fmt.Sprintf("LinkToPage: %s\n", c.wikiURL(webui))
+}
+
+// sourceLink returns a markdown link to the content's webui link, followed by a blank line, or
+// "" when source links are disabled or the content has no webui link. The link is relative to
+// the wiki context path unless it is absolute.
+func (c *ConfluenceAdapter) sourceLink(links map[string]interface{}) string {
+	webui, _ := links["webui"].(string)
+	if !c.config.IncludeSourceLink || webui == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("[View in Confluence](%s)\n\n", c.wikiURL(webui))
+}
+
 // fetchPageBody fetches the body content of a specific page
 func (c *ConfluenceAdapter) fetchPageBody(ctx context.Context, pageID string) (string, error) {
+	if c.isServer() {
+		return c.fetchServerBody(ctx, pageID)
+	}
+
 	url := fmt.Sprintf("%s/wiki/api/v2/pages/%s?body-format=export_view", c.config.BaseURL, pageID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -662,12 +1022,12 @@ func (c *ConfluenceAdapter) fetchPageBody(ctx context.Context, pageID string) (s
 	}
 
 	// Set authentication
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Confluence page body API URL: %s", url)
+	c.log().Debugf("Confluence page body API URL: %s", url)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to make request: %w", err)
 	}
@@ -693,8 +1053,191 @@ func (c *ConfluenceAdapter) fetchPageBody(ctx context.Context, pageID string) (s
 	return "", fmt.Errorf("no content found in page body")
 }
 
+// processPageAttachments fetches and downloads the attachments of a page, returning them as Files.
+// Failures are logged and skipped so a broken attachment does not prevent the page from syncing.
+func (c *ConfluenceAdapter) processPageAttachments(ctx context.Context, page ConfluencePage, knowledgeID string) []*File {
+	attachments, err := c.fetchPageAttachments(ctx, page.ID)
+	if err != nil {
+		c.log().Errorf("Failed to fetch attachments for page %s: %v", page.Title, err)
+		c.incomplete = true
+		return nil
+	}
+
+	var files []*File
+	for _, attachment := range attachments {
+		if !c.config.IncludeBinaryAttachments && !isTextMediaType(attachment.MediaType) {
+			c.log().Debugf("Skipping binary attachment %s (%s) on page %s", attachment.Title, attachment.MediaType, page.Title)
+			continue
+		}
+		// Prefix with the page title so attachments with the same name on different pages don't collide.
+		// The name is claimed before the version check, so unchanged attachments keep theirs.
+		filename := c.claimFilename(knowledgeID, attachment.ID, c.SanitizeFilename(page.Title)+"_"+c.SanitizeFilename(attachment.Title))
+		version := contentVersion{id: attachment.ID, number: attachment.Version.Number}
+		if synced, ok := c.unchangedVersion(attachment.ID, attachment.Version.Number); ok {
+			files = append(files, c.unchangedFile(synced, knowledgeID, attachment.MediaType, version, func(ctx context.Context) ([]byte, error) {
+				return c.downloadAttachment(ctx, attachment)
+			}))
+			continue
+		}
+
+		content, err := c.downloadAttachment(ctx, attachment)
+		if err != nil {
+			c.log().Errorf("Failed to download attachment %s on page %s: %v", attachment.Title, page.Title, err)
+			c.incomplete = true
+			continue
+		}
+
+		hash := sha256.Sum256(content)
+		files = append(files, &File{
+			Path:        filename,
+			Content:     content,
+			Hash:        base64.StdEncoding.EncodeToString(hash[:]),
+			Modified:    c.lastSync,
+			Size:        int64(len(content)),
+			Source:      "confluence",
+			KnowledgeID: knowledgeID,
+			ContentType: attachment.MediaType,
+		})
+		c.pending[files[len(files)-1]] = version
+	}
+
+	return files
+}
+
+// fetchPageAttachments fetches all attachments of a specific page
+func (c *ConfluenceAdapter) fetchPageAttachments(ctx context.Context, pageID string) ([]ConfluenceAttachment, error) {
+	if c.isServer() {
+		return c.fetchServerAttachments(ctx, pageID)
+	}
+
+	var allAttachments []ConfluenceAttachment
+	limit := c.config.PageLimit
+	if limit <= 0 {
+		limit = 100 // Default limit
+	}
+
+	url := fmt.Sprintf("%s/wiki/api/v2/pages/%s/attachments?limit=%d", c.config.BaseURL, pageID, limit)
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set authentication
+		c.authenticate(req)
+		req.Header.Set("Accept", "application/json")
+
+		c.log().Debugf("Confluence attachments API URL: %s", url)
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("API request failed with status %d: response body omitted", resp.StatusCode)
+		}
+
+		var attachmentList ConfluenceAttachmentList
+		if err := json.NewDecoder(resp.Body).Decode(&attachmentList); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		resp.Body.Close()
+
+		allAttachments = append(allAttachments, attachmentList.Results...)
+
+		// Check for next page
+		nextLink, hasNext := attachmentList.Links["next"]
+		if !hasNext {
+			break
+		}
+
+		nextURL, ok := nextLink.(string)
+		if !ok || nextURL == "" {
+			break
+		}
+		if !strings.HasPrefix(nextURL, "https") {
+			// Prepend the base URL
+			nextURL = c.config.BaseURL + nextURL
+		}
+		url = nextURL
+	}
+
+	return allAttachments, nil
+}
+
+// downloadAttachment downloads the content of an attachment via its download link
+func (c *ConfluenceAdapter) downloadAttachment(ctx context.Context, attachment ConfluenceAttachment) ([]byte, error) {
+	downloadLink := attachment.DownloadLink
+	if downloadLink == "" {
+		if link, ok := attachment.Links["download"].(string); ok {
+			downloadLink = link
+		}
+	}
+	if downloadLink == "" {
+		return nil, fmt.Errorf("attachment %s has no download link", attachment.ID)
+	}
+
+	// Download links are relative to the wiki context path
+	url := c.wikiURL(downloadLink)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set authentication
+	c.authenticate(req)
+
+	c.log().Debugf("Downloading attachment: %s", attachment.Title)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d: response body omitted", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return content, nil
+}
+
+// isTextMediaType checks if an attachment media type is text-based
+func isTextMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/javascript", "application/x-sh", "application/sql", "application/csv":
+		return true
+	}
+
+	// Structured syntax suffixes such as application/ld+json or image/svg+xml
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
 // fetchSpaceBlogposts fetches all blog posts from a space using space ID
 func (c *ConfluenceAdapter) fetchSpaceBlogposts(ctx context.Context, spaceID string) ([]ConfluenceBlogPost, error) {
+	if c.isServer() {
+		return c.fetchServerSpaceBlogposts(ctx, spaceID)
+	}
+
 	var allBlogposts []ConfluenceBlogPost
 	limit := c.config.PageLimit
 	if limit <= 0 {
@@ -710,12 +1253,12 @@ func (c *ConfluenceAdapter) fetchSpaceBlogposts(ctx context.Context, spaceID str
 		}
 
 		// Set authentication
-		req.SetBasicAuth(c.config.Username, c.config.APIKey)
+		c.authenticate(req)
 		req.Header.Set("Accept", "application/json")
 
-		logrus.Debugf("Confluence blogposts API URL: %s", url)
+		c.log().Debugf("Confluence blogposts API URL: %s", url)
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make request: %w", err)
 		}
@@ -772,7 +1315,7 @@ func (c *ConfluenceAdapter) fetchSpaceBlogposts(ctx context.Context, spaceID str
 		// Fetch users by IDs
 		users, err := c.fetchUsersByIds(ctx, accountIDs)
 		if err != nil {
-			logrus.Errorf("Failed to fetch users for blogposts: %v", err)
+			c.log().Errorf("Failed to fetch users for blogposts: %v", err)
 			// Continue without user information if fetch fails
 		} else {
 			// Update blogposts with user display names
@@ -797,11 +1340,11 @@ func (c *ConfluenceAdapter) fetchBlogpostByID(ctx context.Context, blogpostID st
 	}
 
 	// Set authentication
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Confluence blogpost API URL: %s", url)
-	resp, err := c.client.Do(req)
+	c.log().Debugf("Confluence blogpost API URL: %s", url)
+	resp, err := c.do(req)
 	if err != nil {
 		return ConfluenceBlogPost{}, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -809,7 +1352,7 @@ func (c *ConfluenceAdapter) fetchBlogpostByID(ctx context.Context, blogpostID st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Consume body for proper connection reuse
-		logrus.Errorf("Confluence blogpost API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
+		c.log().Errorf("Confluence blogpost API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
 		return ConfluenceBlogPost{}, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -830,23 +1373,13 @@ func (c *ConfluenceAdapter) processBlogpost(ctx context.Context, blogpost Conflu
 	}
 
 	// Create filename from title
-	filename := c.SanitizeFilename(blogpost.Title)
-	if c.config.UseMarkdownParser {
-		filename += ".md"
-	} else {
-		filename += ".txt"
-	}
-
-	// Format content as webui link + body content
-	webuiLink := ""
-	if webui, exists := blogpost.Links["webui"]; exists {
-		if webuiStr, ok := webui.(string); ok {
-			webuiLink = webuiStr
-		}
-	}
-	metaData := fmt.Sprintf("Author: %s\nCreatedAt: %s\nLinkToPage: %s", blogpost.AuthorDisplayName, blogpost.CreatedAt, c.config.BaseURL+"/wiki"+webuiLink)
+	filename := c.claimFilename(knowledgeID, blogpost.ID, c.SanitizeFilename(blogpost.Title)+c.pageExtension())
+	contentType := c.pageContentType()
 
-	content := fmt.Sprintf("%s\n\n%s", metaData, blogpostBody)
+	// Format content as metadata + source link + body content
+	metaData := strings.TrimSuffix(fmt.Sprintf("Author: %s\nCreatedAt: %s\n%s", blogpost.AuthorDisplayName, blogpost.CreatedAt, c.linkToPage(blogpost.Links)), "\n")
+
+	content := fmt.Sprintf("%s\n\n%s%s", metaData, c.sourceLink(blogpost.Links), blogpostBody)
 
 	// Create file content
 	fileContent := []byte(content)
@@ -863,11 +1396,16 @@ func (c *ConfluenceAdapter) processBlogpost(ctx context.Context, blogpost Conflu
 		Size:        int64(len(fileContent)),
 		Source:      "confluence",
 		KnowledgeID: knowledgeID,
+		ContentType: contentType,
 	}, nil
 }
 
 // fetchBlogpostBody fetches the body content of a specific blog post
 func (c *ConfluenceAdapter) fetchBlogpostBody(ctx context.Context, blogpostID string) (string, error) {
+	if c.isServer() {
+		return c.fetchServerBody(ctx, blogpostID)
+	}
+
 	url := fmt.Sprintf("%s/wiki/api/v2/blogposts/%s?body-format=export_view", c.config.BaseURL, blogpostID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -876,12 +1414,12 @@ func (c *ConfluenceAdapter) fetchBlogpostBody(ctx context.Context, blogpostID st
 	}
 
 	// Set authentication
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Confluence blogpost body API URL: %s", url)
+	c.log().Debugf("Confluence blogpost body API URL: %s", url)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to make request: %w", err)
 	}
@@ -907,7 +1445,8 @@ func (c *ConfluenceAdapter) fetchBlogpostBody(ctx context.Context, blogpostID st
 	return "", fmt.Errorf("no content found in blogpost body")
 }
 
-// HtmlToMarkdown converts HTML content to markdown
+// HtmlToMarkdown converts HTML content to markdown. Code and panel macros are rewritten first,
+// so they become fenced code blocks and blockquotes.
 func (c *ConfluenceAdapter) HtmlToMarkdown(htmlContent string) string {
 	conv := converter.NewConverter(
 		converter.WithPlugins(
@@ -920,9 +1459,9 @@ func (c *ConfluenceAdapter) HtmlToMarkdown(htmlContent string) string {
 			// ...additional plugins (e.g. table)
 		),
 	)
-	markdown, err := conv.ConvertString(htmlContent)
+	markdown, err := conv.ConvertString(rewriteConfluenceMacros(htmlContent))
 	if err != nil {
-		logrus.Warnf("Failed to convert HTML to markdown: %v", err)
+		c.log().Warnf("Failed to convert HTML to markdown: %v", err)
 		return htmlContent
 	}
 	return markdown
@@ -932,7 +1471,7 @@ func (c *ConfluenceAdapter) HtmlToMarkdown(htmlContent string) string {
 func (c *ConfluenceAdapter) HtmlToText(htmlContent string) string {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
-		logrus.Warnf("Failed to parse HTML: %v", err)
+		c.log().Warnf("Failed to parse HTML: %v", err)
 		return htmlContent
 	}
 
@@ -987,7 +1526,7 @@ func (c *ConfluenceAdapter) extractText(n *html.Node, text *strings.Builder) {
 	}
 }
 
-// sanitizeFilename converts a title to a safe filename
+// SanitizeFilename converts a title to a safe filename
 func (c *ConfluenceAdapter) SanitizeFilename(title string) string {
 	// Convert to lowercase and replace spaces with underscores
 	filename := strings.ToLower(title)
@@ -1003,9 +1542,9 @@ func (c *ConfluenceAdapter) SanitizeFilename(title string) string {
 	// Remove leading/trailing underscores
 	filename = strings.Trim(filename, "_")
 
-	// Limit length to 100 characters
-	if len(filename) > 100 {
-		filename = filename[:100]
+	// Limit length to confluence.max_filename_length characters
+	if limit := c.maxFilenameLength(); len(filename) > limit {
+		filename = filename[:limit]
 	}
 
 	// Ensure it's not empty
@@ -1049,15 +1588,15 @@ func (c *ConfluenceAdapter) fetchUsersByIds(ctx context.Context, accountIds []st
 	}
 
 	// Set authentication and headers
-	req.SetBasicAuth(c.config.Username, c.config.APIKey)
+	c.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
-	logrus.Debugf("Confluence bulk user API URL: %s", url)
-	logrus.Debugf("Confluence bulk user request body: %s", string(body))
+	c.log().Debugf("Confluence bulk user API URL: %s", url)
+	c.log().Debugf("Confluence bulk user request body: %s", string(body))
 
 	// Make the request
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -1065,7 +1604,7 @@ func (c *ConfluenceAdapter) fetchUsersByIds(ctx context.Context, accountIds []st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) // Consume body for proper connection reuse
-		logrus.Errorf("Confluence bulk user API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
+		c.log().Errorf("Confluence bulk user API failed - Status: %d, URL: %s, Response: %s", resp.StatusCode, url, string(body))
 		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ConfluenceSearchResult represents a content item from the CQL search API
+type ConfluenceSearchResult struct {
+	ID      string                 `json:"id"`
+	Type    string                 `json:"type"`
+	Status  string                 `json:"status"`
+	Title   string                 `json:"title"`
+	Version ConfluenceVersion      `json:"version"`
+	Links   map[string]interface{} `json:"_links"`
+}
+
+// ConfluenceSearchList represents the response from a CQL content search
+type ConfluenceSearchList struct {
+	Results []ConfluenceSearchResult `json:"results"`
+	Links   map[string]interface{}   `json:"_links"`
+}
+
+// searchPages returns the pages matching a CQL query. Results of other content types, such as
+// blog posts and attachments, are skipped. The search includes each page's version, so
+// unchanged pages are skipped by processPages without fetching them.
+func (c *ConfluenceAdapter) searchPages(ctx context.Context, cql string) ([]ConfluencePage, error) {
+	limit := c.config.PageLimit
+	if limit <= 0 {
+		limit = 100 // Default limit
+	}
+
+	var pages []ConfluencePage
+	searchURL := c.restAPIURL(fmt.Sprintf("/content/search?cql=%s&limit=%d&expand=version", url.QueryEscape(cql), limit))
+
+	for searchURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.authenticate(req)
+		req.Header.Set("Accept", "application/json")
+
+		c.log().Debugf("Confluence CQL search API URL: %s", searchURL)
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("API request failed with status %d: response body omitted", resp.StatusCode)
+		}
+
+		var results ConfluenceSearchList
+		if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, result := range results.Results {
+			if result.Type != "" && result.Type != "page" {
+				continue
+			}
+			pages = append(pages, ConfluencePage{
+				ID:      result.ID,
+				Status:  result.Status,
+				Title:   result.Title,
+				Version: result.Version,
+				Links:   result.Links,
+			})
+		}
+
+		searchURL = c.searchNextURL(results.Links)
+	}
+
+	return pages, nil
+}
+
+// searchNextURL returns the absolute URL of the next page of search results, or "" on the
+// last page. Unlike the v2 API, next links of the REST API are relative to the wiki context path.
+func (c *ConfluenceAdapter) searchNextURL(links map[string]interface{}) string {
+	next, _ := links["next"].(string)
+	if next == "" {
+		return ""
+	}
+	return c.wikiURL(next)
+}
@@ -0,0 +1,70 @@
+package adapter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// defaultMaxFilenameLength caps sanitized titles when confluence.max_filename_length is unset
+const defaultMaxFilenameLength = 100
+
+// maxFilenameLength returns the configured length limit of sanitized titles
+func (c *ConfluenceAdapter) maxFilenameLength() int {
+	if c.config.MaxFilenameLength > 0 {
+		return c.config.MaxFilenameLength
+	}
+	return defaultMaxFilenameLength
+}
+
+// pageExtension returns the extension of page and blog post files
+func (c *ConfluenceAdapter) pageExtension() string {
+	if c.config.UseMarkdownParser {
+		return ".md"
+	}
+	return ".txt"
+}
+
+// pageContentType returns the content type of page and blog post files
+func (c *ConfluenceAdapter) pageContentType() string {
+	if c.config.UseMarkdownParser {
+		return "text/markdown"
+	}
+	return "text/plain"
+}
+
+// resetFilenameClaims forgets the filenames claimed during the previous run
+func (c *ConfluenceAdapter) resetFilenameClaims() {
+	c.filenameOwners = make(map[string]string)
+}
+
+// claimFilename returns the filename under which the content with the given ID is synced into a
+// knowledge base. The first content of a run keeps its name; other content whose name clashes
+// with it, such as two long titles that only differ after the length limit, gets a short hash
+// of its ID appended before the extension. The ID keeps the suffixed name stable across runs.
+func (c *ConfluenceAdapter) claimFilename(knowledgeID, id, filename string) string {
+	if c.filenameOwners == nil {
+		c.resetFilenameClaims()
+	}
+
+	claim := knowledgeID + "\x00" + filename
+	owner, claimed := c.filenameOwners[claim]
+	if !claimed || owner == id {
+		c.filenameOwners[claim] = id
+		return filename
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	ext := path.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+	if limit := c.maxFilenameLength() - len(suffix); len(stem) > limit && limit > 0 {
+		stem = stem[:limit]
+	}
+	unique := stem + suffix + ext
+
+	c.log().Warnf("Confluence filename %s is already used in knowledge %s, syncing %s as %s", filename, knowledgeID, id, unique)
+	c.filenameOwners[knowledgeID+"\x00"+unique] = id
+	return unique
+}
@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ancestorSeparator joins the sanitized titles of a page's ancestors in its filename.
+	// SanitizeFilename collapses repeated underscores, so it never occurs inside a title.
+	ancestorSeparator = "__"
+	// maxAncestorFilenameLength caps ancestor filenames (without extension) well below the
+	// 255 byte limit of common filesystems
+	maxAncestorFilenameLength = 200
+)
+
+// ConfluenceAncestor represents an ancestor from the ancestors API
+type ConfluenceAncestor struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ConfluenceAncestorList represents the response from listing a page's ancestors
+type ConfluenceAncestorList struct {
+	Results []ConfluenceAncestor   `json:"results"`
+	Links   map[string]interface{} `json:"_links"`
+}
+
+// usesAncestors reports whether page filenames or headers include the page's ancestors
+func (c *ConfluenceAdapter) usesAncestors() bool {
+	return c.config.AncestorFilenames || c.config.Breadcrumbs
+}
+
+// rememberPageTitles caches the titles of fetched pages, so ancestors among them don't
+// need to be fetched again
+func (c *ConfluenceAdapter) rememberPageTitles(pages []ConfluencePage) {
+	if c.pageTitles == nil {
+		c.pageTitles = make(map[string]string)
+	}
+	for _, page := range pages {
+		if page.Title != "" {
+			c.pageTitles[page.ID] = page.Title
+		}
+	}
+}
+
+// pageAncestorTitles returns the titles of a page's ancestor pages, the top-level page first
+func (c *ConfluenceAdapter) pageAncestorTitles(ctx context.Context, pageID string) ([]string, error) {
+	if c.isServer() {
+		return c.fetchServerAncestorTitles(ctx, pageID)
+	}
+
+	url := fmt.Sprintf("%s/wiki/api/v2/pages/%s/ancestors", c.config.BaseURL, pageID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authenticate(req)
+	req.Header.Set("Accept", "application/json")
+
+	c.log().Debugf("Confluence page ancestors API URL: %s", url)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d: response body omitted", resp.StatusCode)
+	}
+
+	var ancestors ConfluenceAncestorList
+	if err := json.NewDecoder(resp.Body).Decode(&ancestors); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	titles := make([]string, 0, len(ancestors.Results))
+	for _, ancestor := range ancestors.Results {
+		// Folders and whiteboards can't be fetched as pages
+		if ancestor.Type != "" && ancestor.Type != "page" {
+			continue
+		}
+		title, err := c.pageTitle(ctx, ancestor.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch ancestor %s: %w", ancestor.ID, err)
+		}
+		titles = append(titles, title)
+	}
+	return titles, nil
+}
+
+// pageTitle returns the title of a page, fetching it if it isn't cached yet
+func (c *ConfluenceAdapter) pageTitle(ctx context.Context, pageID string) (string, error) {
+	if title, ok := c.pageTitles[pageID]; ok {
+		return title, nil
+	}
+	page, err := c.fetchPageByID(ctx, pageID)
+	if err != nil {
+		return "", err
+	}
+	c.rememberPageTitles([]ConfluencePage{page})
+	return page.Title, nil
+}
+
+// ancestorFilename joins the sanitized titles of a page's ancestors and the page itself.
+// Names over maxAncestorFilenameLength are truncated and made unique with a hash of the
+// full name.
+func (c *ConfluenceAdapter) ancestorFilename(titles []string) string {
+	segments := make([]string, len(titles))
+	for i, title := range titles {
+		segments[i] = c.SanitizeFilename(title)
+	}
+	filename := strings.Join(segments, ancestorSeparator)
+	if len(filename) <= maxAncestorFilenameLength {
+		return filename
+	}
+
+	sum := sha256.Sum256([]byte(filename))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	return filename[:maxAncestorFilenameLength-len(suffix)] + suffix
+}
@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+)
+
+// FetchItem fetches a single page, e.g. one named by a Confluence webhook, if it is a mapped
+// parent page or belongs to a mapped space. Whether other pages are synced as the sub-page of
+// a parent page or by a CQL query is only known from a full fetch. Like a full fetch, the page
+// is skipped if its version didn't change since it was last synced.
+func (c *ConfluenceAdapter) FetchItem(ctx context.Context, pageID string) ([]*File, error) {
+	page, err := c.fetchPageByID(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page %s: %w", pageID, err)
+	}
+
+	knowledgeID, ok := c.parentPageMappings[pageID]
+	if !ok {
+		knowledgeID, ok, err = c.spaceKnowledgeID(ctx, page.SpaceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case !ok && (len(c.parentPageIDs) > 0 || len(c.cqlMappings) > 0):
+		return nil, ErrUnknownItem
+	case !ok:
+		c.log().Debugf("Ignoring Confluence page %s of a space that isn't synced", pageID)
+		return nil, nil
+	}
+
+	c.resetPendingVersions()
+	var files []*File
+	for _, file := range c.processPages(ctx, []ConfluencePage{page}, knowledgeID) {
+		if !file.Unchanged {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// spaceKnowledgeID returns the knowledge ID of the mapped space with the given ID. Space IDs
+// are looked up once per space key.
+func (c *ConfluenceAdapter) spaceKnowledgeID(ctx context.Context, spaceID string) (string, bool, error) {
+	if c.spaceIDs == nil {
+		c.spaceIDs = make(map[string]string)
+	}
+	for _, spaceKey := range c.spaces {
+		id, ok := c.spaceIDs[spaceKey]
+		if !ok {
+			var err error
+			if id, err = c.getSpaceID(ctx, spaceKey); err != nil {
+				return "", false, fmt.Errorf("failed to get space ID for %s: %w", spaceKey, err)
+			}
+			c.spaceIDs[spaceKey] = id
+		}
+		if id == spaceID {
+			return c.spaceMappings[spaceKey], true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestConfluenceAdapter_FetchItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		parentID string
+		expected []string
+		wantErr  error
+	}{
+		{name: "mapped parent page", parentID: "100", expected: []string{"runbook.txt"}},
+		{name: "page that may be a sub-page", parentID: "200", wantErr: ErrUnknownItem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newConfluenceAttachmentServer(t)
+			adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+				BaseURL:  server.URL,
+				Username: "test@example.com",
+				APIKey:   "test-key",
+				ParentPageMappings: []config.ParentPageMapping{
+					{ParentPageID: tt.parentID, KnowledgeID: "runbooks"},
+				},
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+
+			files, err := adapter.FetchItem(context.Background(), "100")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchItem() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+				if file.KnowledgeID != "runbooks" {
+					t.Errorf("Expected knowledge ID runbooks for %s, got %s", file.Path, file.KnowledgeID)
+				}
+			}
+			if fmt.Sprint(paths) != fmt.Sprint(tt.expected) {
+				t.Errorf("FetchItem() returned %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
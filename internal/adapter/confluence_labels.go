@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// labelFetchConcurrency is the number of page label requests in flight at once
+const labelFetchConcurrency = 5
+
+// ConfluenceLabel represents a label from the Confluence API
+type ConfluenceLabel struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// ConfluenceLabelList represents the response from listing a page's labels
+type ConfluenceLabelList struct {
+	Results []ConfluenceLabel      `json:"results"`
+	Links   map[string]interface{} `json:"_links"`
+}
+
+// filtersLabels reports whether pages are filtered by include_labels or exclude_labels
+func (c *ConfluenceAdapter) filtersLabels() bool {
+	return len(c.config.IncludeLabels) > 0 || len(c.config.ExcludeLabels) > 0
+}
+
+// filterPagesByLabels drops the pages that don't pass the label filters. The labels of all
+// pages are fetched up front with a few concurrent requests. Pages whose labels can't be
+// fetched are dropped too, but mark the fetch incomplete so a transient error doesn't remove
+// them from the knowledge base.
+func (c *ConfluenceAdapter) filterPagesByLabels(ctx context.Context, pages []ConfluencePage) []ConfluencePage {
+	if !c.filtersLabels() || len(pages) == 0 {
+		return pages
+	}
+
+	labels := make([][]string, len(pages))
+	errs := make([]error, len(pages))
+	sem := make(chan struct{}, labelFetchConcurrency)
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, pageID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			labels[i], errs[i] = c.fetchPageLabels(ctx, pageID)
+		}(i, page.ID)
+	}
+	wg.Wait()
+
+	kept := make([]ConfluencePage, 0, len(pages))
+	for i, page := range pages {
+		if errs[i] != nil {
+			c.log().Errorf("Failed to fetch labels of page %s, skipping it: %v", page.Title, errs[i])
+			c.incomplete = true
+			continue
+		}
+		if !c.labelsAllowed(labels[i]) {
+			c.log().Debugf("Skipping page %s with labels %v", page.Title, labels[i])
+			// Forget the synced version so the page is fetched again once it passes the filters
+			delete(c.versions, page.ID)
+			continue
+		}
+		kept = append(kept, page)
+	}
+
+	if filtered := len(pages) - len(kept); filtered > 0 {
+		c.log().Infof("Skipped %d Confluence pages by label", filtered)
+	}
+	return kept
+}
+
+// labelsAllowed applies the label filters to a page's labels. Excluded labels win over
+// included ones; an empty include list admits every page that isn't excluded.
+func (c *ConfluenceAdapter) labelsAllowed(labels []string) bool {
+	for _, label := range labels {
+		for _, excluded := range c.config.ExcludeLabels {
+			if strings.EqualFold(label, excluded) {
+				return false
+			}
+		}
+	}
+
+	if len(c.config.IncludeLabels) == 0 {
+		return true
+	}
+	for _, label := range labels {
+		for _, included := range c.config.IncludeLabels {
+			if strings.EqualFold(label, included) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetchPageLabels fetches the names of all labels of a page
+func (c *ConfluenceAdapter) fetchPageLabels(ctx context.Context, pageID string) ([]string, error) {
+	url := fmt.Sprintf("%s/wiki/api/v2/pages/%s/labels?limit=250", c.config.BaseURL, pageID)
+	if c.isServer() {
+		url = c.restAPIURL("/content/" + pageID + "/label?limit=250")
+	}
+
+	var names []string
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.authenticate(req)
+		req.Header.Set("Accept", "application/json")
+
+		c.log().Debugf("Confluence page labels API URL: %s", url)
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("API request failed with status %d: response body omitted", resp.StatusCode)
+		}
+
+		var labelList ConfluenceLabelList
+		err = json.NewDecoder(resp.Body).Decode(&labelList)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		for _, label := range labelList.Results {
+			names = append(names, label.Name)
+		}
+
+		url = ""
+		if next, ok := labelList.Links["next"].(string); ok && next != "" {
+			url = c.wikiURL(next)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,266 @@
+package adapter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// confluenceMacroRewriter rewrites the export_view HTML of a Confluence macro in place into
+// plain HTML the markdown converter understands. It reports whether it handled the node.
+type confluenceMacroRewriter func(n *html.Node) bool
+
+// confluenceMacroRewriters are tried in order on every element before markdown conversion;
+// the first one that handles an element wins
+var confluenceMacroRewriters = []confluenceMacroRewriter{
+	rewriteCodeMacro,
+	rewriteInformationMacro,
+	rewritePanelMacro,
+}
+
+// confluenceBrushLanguages maps syntax highlighter brushes to the language hints of fenced
+// code blocks. Brushes that aren't listed are used as they are.
+var confluenceBrushLanguages = map[string]string{
+	"js":            "javascript",
+	"py":            "python",
+	"rb":            "ruby",
+	"ps":            "powershell",
+	"plain":         "",
+	"text":          "",
+	"none":          "",
+	"actionscript3": "actionscript",
+}
+
+// confluenceAdmonitionLabels maps information macros to the label that starts their blockquote
+var confluenceAdmonitionLabels = map[string]string{
+	"info":    "Info",
+	"note":    "Note",
+	"warning": "Warning",
+	"tip":     "Tip",
+}
+
+// rewriteConfluenceMacros rewrites the code and panel macros of Confluence export HTML, so
+// code macros become fenced code blocks with a language hint and panels become blockquotes.
+// Content without macros is returned unchanged.
+func rewriteConfluenceMacros(htmlContent string) string {
+	if !strings.Contains(htmlContent, "syntaxhighlighter") && !strings.Contains(htmlContent, "macro") &&
+		!strings.Contains(htmlContent, "panel") {
+		return htmlContent
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		logrus.Warnf("Failed to parse HTML for macro rewriting: %v", err)
+		return htmlContent
+	}
+	rewriteMacroNodes(doc)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		logrus.Warnf("Failed to render rewritten HTML: %v", err)
+		return htmlContent
+	}
+	return buf.String()
+}
+
+// rewriteMacroNodes applies the macro rewriters to n and its descendants. The children of a
+// rewritten element are visited too, so macros nested in panels are rewritten as well.
+func rewriteMacroNodes(n *html.Node) {
+	if n.Type == html.ElementNode {
+		for _, rewrite := range confluenceMacroRewriters {
+			if rewrite(n) {
+				break
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		rewriteMacroNodes(child)
+	}
+}
+
+// rewriteCodeMacro turns the <pre> of a code macro into <pre><code class="language-...">
+func rewriteCodeMacro(n *html.Node) bool {
+	params, hasParams := nodeAttr(n, "data-syntaxhighlighter-params")
+	if n.Data != "pre" || (!hasParams && !hasClass(n, "syntaxhighlighter-pre")) {
+		return false
+	}
+
+	code := &html.Node{Type: html.ElementNode, Data: "code"}
+	if language := brushLanguage(params); language != "" {
+		code.Attr = []html.Attribute{{Key: "class", Val: "language-" + language}}
+	}
+	code.AppendChild(&html.Node{Type: html.TextNode, Data: nodeText(n)})
+
+	removeChildren(n)
+	n.Attr = nil
+	n.AppendChild(code)
+	return true
+}
+
+// brushLanguage returns the language of syntax highlighter params such as
+// "brush: java; gutter: false"
+func brushLanguage(params string) string {
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(param, ":")
+		if !found || strings.TrimSpace(key) != "brush" {
+			continue
+		}
+		brush := strings.ToLower(strings.TrimSpace(value))
+		if language, ok := confluenceBrushLanguages[brush]; ok {
+			return language
+		}
+		return brush
+	}
+	return ""
+}
+
+// rewriteInformationMacro turns info, note, warning and tip macros into a blockquote that
+// starts with the macro's label and title
+func rewriteInformationMacro(n *html.Node) bool {
+	if n.Data != "div" || !hasClass(n, "confluence-information-macro") {
+		return false
+	}
+
+	macro, _ := nodeAttr(n, "data-macro-name")
+	label, ok := confluenceAdmonitionLabels[macro]
+	if !ok {
+		// Older exports only carry the macro type as a class
+		switch {
+		case hasClass(n, "confluence-information-macro-note"):
+			label = "Note"
+		case hasClass(n, "confluence-information-macro-warning"):
+			label = "Warning"
+		case hasClass(n, "confluence-information-macro-tip"):
+			label = "Tip"
+		default:
+			label = "Info"
+		}
+	}
+
+	var title string
+	var body []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case hasClass(child, "title"):
+			title = strings.TrimSpace(nodeText(child))
+		case hasClass(child, "confluence-information-macro-body"):
+			body = append(body, childNodes(child)...)
+		}
+	}
+
+	toBlockquote(n, label+":", title, body)
+	return true
+}
+
+// rewritePanelMacro turns panel macros into a blockquote that starts with the panel's title
+func rewritePanelMacro(n *html.Node) bool {
+	if n.Data != "div" {
+		return false
+	}
+	// Code macros are wrapped in a div with the panel class too
+	macro, _ := nodeAttr(n, "data-macro-name")
+	if macro != "panel" && (macro != "" || !hasClass(n, "panel") || hasClass(n, "code")) {
+		return false
+	}
+
+	var title string
+	var body []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case hasClass(child, "panelHeader"):
+			title = strings.TrimSpace(nodeText(child))
+		case hasClass(child, "panelContent"):
+			body = append(body, childNodes(child)...)
+		}
+	}
+
+	toBlockquote(n, title, "", body)
+	return true
+}
+
+// toBlockquote replaces the content of n with a paragraph holding the bold label and the
+// title, if any, followed by body
+func toBlockquote(n *html.Node, label, title string, body []*html.Node) {
+	removeChildren(n)
+	n.Data = "blockquote"
+	n.Attr = nil
+
+	if label != "" || title != "" {
+		heading := &html.Node{Type: html.ElementNode, Data: "p"}
+		if label != "" {
+			strong := &html.Node{Type: html.ElementNode, Data: "strong"}
+			strong.AppendChild(&html.Node{Type: html.TextNode, Data: label})
+			heading.AppendChild(strong)
+		}
+		if title != "" {
+			if label != "" {
+				title = " " + title
+			}
+			heading.AppendChild(&html.Node{Type: html.TextNode, Data: title})
+		}
+		n.AppendChild(heading)
+	}
+	for _, child := range body {
+		n.AppendChild(child)
+	}
+}
+
+// childNodes detaches and returns the children of n
+func childNodes(n *html.Node) []*html.Node {
+	var children []*html.Node
+	for child := n.FirstChild; child != nil; child = n.FirstChild {
+		n.RemoveChild(child)
+		children = append(children, child)
+	}
+	return children
+}
+
+// removeChildren detaches all children of n
+func removeChildren(n *html.Node) {
+	childNodes(n)
+}
+
+// nodeText returns the text content of n and its descendants, with <br> as a line break
+func nodeText(n *html.Node) string {
+	var text strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			text.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return text.String()
+}
+
+// nodeAttr returns the value of an attribute of n
+func nodeAttr(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasClass reports whether n is an element with the given class
+func hasClass(n *html.Node, class string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	classes, _ := nodeAttr(n, "class")
+	for _, c := range strings.Fields(classes) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Confluence Server and Data Center only offer the REST API (v1), served directly below the base
+// URL, which includes any context path such as /confluence. Its content is converted to the
+// types of the v2 API used with Confluence Cloud, so the rest of the adapter works the same on
+// both deployments. Spaces are addressed by key, which stands in for the space ID.
+
+// serverContentExpand lists the fields fetched with every page and blog post
+const serverContentExpand = "space,history,version"
+
+// ConfluenceContent represents a page, blog post or attachment from the REST API
+type ConfluenceContent struct {
+	ID         string                      `json:"id"`
+	Type       string                      `json:"type"`
+	Status     string                      `json:"status"`
+	Title      string                      `json:"title"`
+	Space      ConfluenceContentSpace      `json:"space"`
+	History    ConfluenceContentHistory    `json:"history"`
+	Version    ConfluenceVersion           `json:"version"`
+	Ancestors  []ConfluenceContent         `json:"ancestors"`
+	Body       ConfluenceBody              `json:"body"`
+	Extensions ConfluenceContentExtensions `json:"extensions"`
+	Links      map[string]interface{}      `json:"_links"`
+}
+
+// ConfluenceContentSpace represents the space of a content item, or a space from the space API
+type ConfluenceContentSpace struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// ConfluenceContentHistory represents when and by whom a content item was created
+type ConfluenceContentHistory struct {
+	CreatedDate string                `json:"createdDate"`
+	CreatedBy   ConfluenceContentUser `json:"createdBy"`
+}
+
+// ConfluenceContentUser represents a user from the REST API
+type ConfluenceContentUser struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+}
+
+// ConfluenceContentExtensions holds the media type and size of an attachment
+type ConfluenceContentExtensions struct {
+	MediaType string `json:"mediaType"`
+	FileSize  int    `json:"fileSize"`
+}
+
+// ConfluenceContentList represents a page of content items from the REST API
+type ConfluenceContentList struct {
+	Results []ConfluenceContent    `json:"results"`
+	Links   map[string]interface{} `json:"_links"`
+}
+
+// isServer reports whether the adapter talks to Confluence Server or Data Center
+func (c *ConfluenceAdapter) isServer() bool {
+	return c.config.Deployment == "server"
+}
+
+// restAPIURL returns the URL of a REST API (v1) path, which Confluence Cloud serves below /wiki
+func (c *ConfluenceAdapter) restAPIURL(path string) string {
+	if c.isServer() {
+		return c.config.BaseURL + "/rest/api" + path
+	}
+	return c.config.BaseURL + "/wiki/rest/api" + path
+}
+
+// wikiURL returns the absolute URL of a link relative to the wiki context path, such as a next
+// link of the REST API, a webui link or a download link
+func (c *ConfluenceAdapter) wikiURL(link string) string {
+	switch {
+	case strings.HasPrefix(link, "http://"), strings.HasPrefix(link, "https://"):
+		return link
+	case c.isServer(), strings.HasPrefix(link, "/wiki/"):
+		return c.config.BaseURL + link
+	default:
+		return c.config.BaseURL + "/wiki" + link
+	}
+}
+
+// pageLimit returns the number of results requested per page of a list
+func (c *ConfluenceAdapter) pageLimit() int {
+	if c.config.PageLimit <= 0 {
+		return 100 // Default limit
+	}
+	return c.config.PageLimit
+}
+
+// getServerJSON sends a GET request to the REST API and decodes the JSON response into target
+func (c *ConfluenceAdapter) getServerJSON(ctx context.Context, requestURL string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authenticate(req)
+	req.Header.Set("Accept", "application/json")
+
+	c.log().Debugf("Confluence REST API URL: %s", requestURL)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body) // Consume body for proper connection reuse
+		return fmt.Errorf("API request failed with status %d: response body omitted", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// fetchServerContents fetches a content list of the REST API, following its next links
+func (c *ConfluenceAdapter) fetchServerContents(ctx context.Context, requestURL string) ([]ConfluenceContent, error) {
+	var contents []ConfluenceContent
+	for requestURL != "" {
+		var list ConfluenceContentList
+		if err := c.getServerJSON(ctx, requestURL, &list); err != nil {
+			return nil, err
+		}
+		contents = append(contents, list.Results...)
+
+		requestURL = ""
+		if next, _ := list.Links["next"].(string); next != "" {
+			requestURL = c.wikiURL(next)
+		}
+	}
+	return contents, nil
+}
+
+// serverPage converts a page of the REST API. Like Cloud, the author is only included with
+// add_additional_data, although the REST API returns it anyway.
+func (c *ConfluenceAdapter) serverPage(content ConfluenceContent) ConfluencePage {
+	page := ConfluencePage{
+		ID:        content.ID,
+		Status:    content.Status,
+		Title:     content.Title,
+		SpaceID:   content.Space.Key,
+		CreatedAt: content.History.CreatedDate,
+		Version:   content.Version,
+		Body:      content.Body,
+		Links:     content.Links,
+	}
+	if c.config.AddAdditionalData {
+		page.AuthorDisplayName = content.History.CreatedBy.DisplayName
+	}
+	return page
+}
+
+// getServerSpaceKey checks that a space exists and returns its key, which stands in for the
+// space ID on Server and Data Center
+func (c *ConfluenceAdapter) getServerSpaceKey(ctx context.Context, spaceKey string) (string, error) {
+	var space ConfluenceContentSpace
+	if err := c.getServerJSON(ctx, c.restAPIURL("/space/"+url.PathEscape(spaceKey)), &space); err != nil {
+		return "", err
+	}
+	if space.Key == "" {
+		return "", fmt.Errorf("space %s not found", spaceKey)
+	}
+	return space.Key, nil
+}
+
+// fetchServerSpaceContents fetches the pages or blog posts of a space
+func (c *ConfluenceAdapter) fetchServerSpaceContents(ctx context.Context, spaceKey, contentType string) ([]ConfluencePage, error) {
+	contents, err := c.fetchServerContents(ctx, c.restAPIURL(fmt.Sprintf("/content?spaceKey=%s&type=%s&limit=%d&expand=%s",
+		url.QueryEscape(spaceKey), contentType, c.pageLimit(), serverContentExpand)))
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]ConfluencePage, 0, len(contents))
+	for _, content := range contents {
+		pages = append(pages, c.serverPage(content))
+	}
+	return pages, nil
+}
+
+// fetchServerSpaceBlogposts fetches the blog posts of a space. Blog posts are content items like
+// pages, so they are converted the same way.
+func (c *ConfluenceAdapter) fetchServerSpaceBlogposts(ctx context.Context, spaceKey string) ([]ConfluenceBlogPost, error) {
+	pages, err := c.fetchServerSpaceContents(ctx, spaceKey, "blogpost")
+	if err != nil {
+		return nil, err
+	}
+
+	blogposts := make([]ConfluenceBlogPost, 0, len(pages))
+	for _, page := range pages {
+		blogposts = append(blogposts, ConfluenceBlogPost(page))
+	}
+	return blogposts, nil
+}
+
+// fetchServerPage fetches a page by its ID
+func (c *ConfluenceAdapter) fetchServerPage(ctx context.Context, pageID string) (ConfluencePage, error) {
+	var content ConfluenceContent
+	if err := c.getServerJSON(ctx, c.restAPIURL("/content/"+url.PathEscape(pageID)+"?expand="+serverContentExpand), &content); err != nil {
+		return ConfluencePage{}, err
+	}
+	return c.serverPage(content), nil
+}
+
+// fetchServerSubPages fetches all sub-pages under a parent page. Unlike the v2 API, the
+// children come with their details, so they don't need to be fetched one by one.
+func (c *ConfluenceAdapter) fetchServerSubPages(ctx context.Context, parentPageID string) ([]ConfluencePage, error) {
+	contents, err := c.fetchServerContents(ctx, c.restAPIURL(fmt.Sprintf("/content/%s/child/page?limit=%d&expand=%s",
+		url.PathEscape(parentPageID), c.pageLimit(), serverContentExpand)))
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]ConfluencePage, 0, len(contents))
+	for _, content := range contents {
+		pages = append(pages, c.serverPage(content))
+	}
+	return pages, nil
+}
+
+// fetchServerBody fetches the body of a page or blog post, converted to text or markdown
+func (c *ConfluenceAdapter) fetchServerBody(ctx context.Context, contentID string) (string, error) {
+	var content ConfluenceContent
+	if err := c.getServerJSON(ctx, c.restAPIURL("/content/"+url.PathEscape(contentID)+"?expand=body.export_view"), &content); err != nil {
+		return "", err
+	}
+
+	if content.Body.ExportView.Value == "" {
+		return "", fmt.Errorf("no content found in %s body", content.Type)
+	}
+	if c.config.UseMarkdownParser {
+		return c.HtmlToMarkdown(content.Body.ExportView.Value), nil
+	}
+	return c.HtmlToText(content.Body.ExportView.Value), nil
+}
+
+// fetchServerAncestorTitles returns the titles of a page's ancestor pages, the top-level page
+// first. The REST API includes them with the page, so none are fetched separately.
+func (c *ConfluenceAdapter) fetchServerAncestorTitles(ctx context.Context, pageID string) ([]string, error) {
+	var content ConfluenceContent
+	if err := c.getServerJSON(ctx, c.restAPIURL("/content/"+url.PathEscape(pageID)+"?expand=ancestors"), &content); err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, 0, len(content.Ancestors))
+	for _, ancestor := range content.Ancestors {
+		if ancestor.Type != "" && ancestor.Type != "page" {
+			continue
+		}
+		titles = append(titles, ancestor.Title)
+	}
+	return titles, nil
+}
+
+// fetchServerAttachments fetches all attachments of a page
+func (c *ConfluenceAdapter) fetchServerAttachments(ctx context.Context, pageID string) ([]ConfluenceAttachment, error) {
+	contents, err := c.fetchServerContents(ctx, c.restAPIURL(fmt.Sprintf("/content/%s/child/attachment?limit=%d&expand=version",
+		url.PathEscape(pageID), c.pageLimit())))
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]ConfluenceAttachment, 0, len(contents))
+	for _, content := range contents {
+		attachments = append(attachments, ConfluenceAttachment{
+			ID:        content.ID,
+			Title:     content.Title,
+			MediaType: content.Extensions.MediaType,
+			FileSize:  content.Extensions.FileSize,
+			PageID:    pageID,
+			Version:   content.Version,
+			Links:     content.Links,
+		})
+	}
+	return attachments, nil
+}
@@ -0,0 +1,182 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// newConfluenceServerAPI serves the REST API (v1) of a Data Center instance below the context
+// path /confluence: space DOC with the pages Runbook (100) and Deploy (101), a child of Runbook
+// with an attachment. Space pages are listed one per page to check that next links are followed.
+func newConfluenceServerAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"100": `{"id":"100","type":"page","status":"current","title":"Runbook","space":{"key":"DOC"},
+			"history":{"createdDate":"2024-05-02T09:30:00.000+02:00","createdBy":{"username":"jdoe","displayName":"Jane Doe"}},
+			"version":{"number":3},"_links":{"webui":"/display/DOC/Runbook"}}`,
+		"101": `{"id":"101","type":"page","status":"current","title":"Deploy","space":{"key":"DOC"},
+			"history":{"createdDate":"2024-05-03T10:00:00.000+02:00","createdBy":{"username":"jroe","displayName":"John Roe"}},
+			"version":{"number":1},"_links":{"webui":"/pages/viewpage.action?pageId=101"}}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/confluence/rest/api/space/DOC", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":98306,"key":"DOC","name":"Documentation"}`)
+	})
+	mux.HandleFunc("/confluence/rest/api/content", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("spaceKey") != "DOC" || query.Get("type") != "page" || query.Get("expand") != serverContentExpand {
+			t.Errorf("Unexpected content query %s", r.URL.RawQuery)
+		}
+		if query.Get("start") == "" {
+			fmt.Fprintf(w, `{"results":[%s],"_links":{"next":"/rest/api/content?spaceKey=DOC&type=page&limit=1&expand=%s&start=1"}}`, pages["100"], serverContentExpand)
+			return
+		}
+		fmt.Fprintf(w, `{"results":[%s],"_links":{}}`, pages["101"])
+	})
+	mux.HandleFunc("/confluence/rest/api/content/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/confluence/rest/api/content/")
+		switch {
+		case path == "100/child/page":
+			fmt.Fprintf(w, `{"results":[%s],"_links":{}}`, pages["101"])
+		case path == "100/child/attachment":
+			fmt.Fprint(w, `{"results":[{"id":"att1","type":"attachment","title":"config.yaml",
+				"extensions":{"mediaType":"application/yaml","fileSize":12},"version":{"number":1},
+				"_links":{"download":"/download/attachments/100/config.yaml?version=1&api=v2"}}],"_links":{}}`)
+		case pages[path] == "":
+			http.NotFound(w, r)
+		case r.URL.Query().Get("expand") == "body.export_view":
+			fmt.Fprintf(w, `{"id":%q,"type":"page","body":{"export_view":{"value":"<p>Steps of page %s</p>"}}}`, path, path)
+		case r.URL.Query().Get("expand") == "ancestors" && path == "101":
+			fmt.Fprint(w, `{"id":"101","type":"page","ancestors":[{"id":"100","type":"page","title":"Runbook"}]}`)
+		default:
+			fmt.Fprint(w, pages[path])
+		}
+	})
+	mux.HandleFunc("/confluence/download/attachments/100/config.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "replicas: 3\n")
+	})
+	// Requests for the Cloud API fail, as they do on Data Center
+	mux.HandleFunc("/confluence/wiki/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request for the Cloud API: %s", r.URL.Path)
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConfluenceAdapter_FetchFiles_Server(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.ConfluenceConfig
+		expected map[string][]string // filename -> expected content
+	}{
+		{
+			name: "space",
+			config: config.ConfluenceConfig{
+				SpaceMappings:     []config.SpaceMapping{{SpaceKey: "DOC", KnowledgeID: "docs"}},
+				PageLimit:         1,
+				AddAdditionalData: true,
+				IncludeSourceLink: true,
+			},
+			expected: map[string][]string{
+				"runbook.txt": {"Author: Jane Doe\nCreatedAt: 2024-05-02T09:30:00.000+02:00\nTitle: Runbook", "[View in Confluence](%s/confluence/display/DOC/Runbook)", "Steps of page 100"},
+				"deploy.txt":  {"Author: John Roe\n", "[View in Confluence](%s/confluence/pages/viewpage.action?pageId=101)", "Steps of page 101"},
+			},
+		},
+		{
+			name: "parent page with attachments and breadcrumbs",
+			config: config.ConfluenceConfig{
+				ParentPageMappings: []config.ParentPageMapping{{ParentPageID: "100", KnowledgeID: "docs"}},
+				IncludeAttachments: true,
+				Breadcrumbs:        true,
+			},
+			expected: map[string][]string{
+				"runbook.txt":         {"Author: \n", "Breadcrumb: Runbook\n", "Steps of page 100"},
+				"deploy.txt":          {"Breadcrumb: Runbook > Deploy\n", "Steps of page 101"},
+				"runbook_config.yaml": {"replicas: 3\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newConfluenceServerAPI(t)
+			cfg := tt.config
+			cfg.BaseURL = server.URL + "/confluence"
+			cfg.Username = "jdoe"
+			cfg.APIKey = "password"
+			cfg.Deployment = "server"
+			adapter, err := NewConfluenceAdapter(cfg, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("FetchFiles() error = %v", err)
+			}
+
+			var paths, expectedPaths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+				for _, want := range tt.expected[file.Path] {
+					if strings.Contains(want, "%s") {
+						want = fmt.Sprintf(want, server.URL)
+					}
+					if !strings.Contains(string(file.Content), want) {
+						t.Errorf("Expected %s to contain %q, got:\n%s", file.Path, want, file.Content)
+					}
+				}
+			}
+			for path := range tt.expected {
+				expectedPaths = append(expectedPaths, path)
+			}
+			sort.Strings(paths)
+			sort.Strings(expectedPaths)
+			if fmt.Sprint(paths) != fmt.Sprint(expectedPaths) {
+				t.Errorf("FetchFiles() returned %v, want %v", paths, expectedPaths)
+			}
+		})
+	}
+}
+
+func TestConfluenceAdapter_fetchPageByID_Server(t *testing.T) {
+	server := newConfluenceServerAPI(t)
+	adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+		BaseURL:            server.URL + "/confluence",
+		Username:           "jdoe",
+		APIKey:             "password",
+		Deployment:         "server",
+		ParentPageMappings: []config.ParentPageMapping{{ParentPageID: "100", KnowledgeID: "docs"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewConfluenceAdapter() error = %v", err)
+	}
+
+	page, err := adapter.fetchPageByID(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("fetchPageByID() error = %v", err)
+	}
+	if page.ID != "100" || page.Title != "Runbook" || page.SpaceID != "DOC" || page.Version.Number != 3 || page.CreatedAt != "2024-05-02T09:30:00.000+02:00" {
+		t.Errorf("Unexpected page %+v", page)
+	}
+
+	spaceID, err := adapter.getSpaceID(context.Background(), "DOC")
+	if err != nil || spaceID != page.SpaceID {
+		t.Errorf("Expected the space key to stand in for the space ID, got %q (err: %v)", spaceID, err)
+	}
+	if _, err := adapter.fetchPageByID(context.Background(), "999"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Expected a 404 error for a missing page, got %v", err)
+	}
+}
@@ -1,10 +1,17 @@
 package adapter
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/storage"
 )
 
 func TestNewConfluenceAdapter(t *testing.T) {
@@ -72,7 +79,7 @@ func TestNewConfluenceAdapter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			adapter, err := NewConfluenceAdapter(tt.config)
+			adapter, err := NewConfluenceAdapter(tt.config, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewConfluenceAdapter() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -94,7 +101,7 @@ func TestConfluenceAdapter_Name(t *testing.T) {
 		},
 	}
 
-	adapter, err := NewConfluenceAdapter(config)
+	adapter, err := NewConfluenceAdapter(config, nil)
 	if err != nil {
 		t.Fatalf("NewConfluenceAdapter() error = %v", err)
 	}
@@ -114,7 +121,7 @@ func TestConfluenceAdapter_GetSetLastSync(t *testing.T) {
 		},
 	}
 
-	adapter, err := NewConfluenceAdapter(config)
+	adapter, err := NewConfluenceAdapter(config, nil)
 	if err != nil {
 		t.Fatalf("NewConfluenceAdapter() error = %v", err)
 	}
@@ -188,6 +195,838 @@ func TestHtmlToText(t *testing.T) {
 	}
 }
 
-// Note: FetchFiles test would require mocking HTTP requests
-// This would be more complex and would typically use a library like httptest
-// or a mocking framework like gomock
+func TestHtmlToMarkdown_Macros(t *testing.T) {
+	adapter := &ConfluenceAdapter{}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "code macro with language",
+			input: `<div class="code panel pdl conf-macro output-block" data-hasbody="true" data-macro-name="code">` +
+				`<div class="codeContent panelContent pdl"><pre class="syntaxhighlighter-pre" ` +
+				`data-syntaxhighlighter-params="brush: java; gutter: false; theme: Confluence" data-theme="Confluence">` +
+				"public class Main {\n  boolean b = 1 &lt; 2;\n}</pre></div></div>",
+			expected: "```java\npublic class Main {\n  boolean b = 1 < 2;\n}\n```",
+		},
+		{
+			name: "code macro with brush alias",
+			input: `<div class="code panel pdl conf-macro output-block" data-macro-name="code"><div class="codeContent panelContent pdl">` +
+				`<pre class="syntaxhighlighter-pre" data-syntaxhighlighter-params="brush: js; gutter: true">const a = 1;</pre></div></div>`,
+			expected: "```javascript\nconst a = 1;\n```",
+		},
+		{
+			name: "code macro without language",
+			input: `<div class="code panel pdl conf-macro output-block" data-macro-name="code"><div class="codeContent panelContent pdl">` +
+				`<pre class="syntaxhighlighter-pre" data-syntaxhighlighter-params="brush: text">plain output</pre></div></div>`,
+			expected: "```\nplain output\n```",
+		},
+		{
+			name: "warning macro with title",
+			input: `<div class="confluence-information-macro confluence-information-macro-warning conf-macro output-block" data-hasbody="true" data-macro-name="warning">` +
+				`<p class="title">Breaking change</p><span class="aui-icon aui-icon-small aui-iconfont-error confluence-information-macro-icon"> </span>` +
+				`<div class="confluence-information-macro-body"><p>Back up the database first.</p></div></div>`,
+			expected: "> __Warning:__ Breaking change\n> \n> Back up the database first.",
+		},
+		{
+			name: "info macro identified by class",
+			input: `<div class="confluence-information-macro confluence-information-macro-information">` +
+				`<span class="aui-icon aui-icon-small aui-iconfont-info confluence-information-macro-icon"> </span>` +
+				`<div class="confluence-information-macro-body"><p>Deployments run nightly.</p></div></div>`,
+			expected: "> __Info:__\n> \n> Deployments run nightly.",
+		},
+		{
+			name: "panel macro with nested code macro",
+			input: `<div class="panel conf-macro output-block" data-hasbody="true" data-macro-name="panel" style="border-width: 1px;">` +
+				`<div class="panelHeader" style="border-bottom-width: 1px;"><b>Setup</b></div>` +
+				`<div class="panelContent"><p>Run:</p><div class="code panel pdl conf-macro output-block" data-macro-name="code">` +
+				`<div class="codeContent panelContent pdl"><pre class="syntaxhighlighter-pre" data-syntaxhighlighter-params="brush: bash">make install</pre>` +
+				`</div></div></div></div>`,
+			expected: "> __Setup__\n> \n> Run:\n> \n> ```bash\n> make install\n> ```",
+		},
+		{
+			name:     "content without macros",
+			input:    `<p>Hello <strong>world</strong></p>`,
+			expected: "Hello __world__",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := adapter.HtmlToMarkdown(tt.input)
+			if result != tt.expected {
+				t.Errorf("HtmlToMarkdown() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsTextMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		expected  bool
+	}{
+		{"text/plain", true},
+		{"text/markdown; charset=utf-8", true},
+		{"application/json", true},
+		{"application/ld+json", true},
+		{"image/svg+xml", true},
+		{"application/pdf", false},
+		{"image/png", false},
+		{"application/zip", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			if got := isTextMediaType(tt.mediaType); got != tt.expected {
+				t.Errorf("isTextMediaType(%q) = %v, want %v", tt.mediaType, got, tt.expected)
+			}
+		})
+	}
+}
+
+// newConfluenceAttachmentServer serves a single page with a text and a binary attachment
+func newConfluenceAttachmentServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/pages/100", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("body-format") == "export_view" {
+			fmt.Fprint(w, `{"id":"100","title":"Runbook","body":{"export_view":{"value":"<p>Steps</p>"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"100","title":"Runbook","spaceId":"1"}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/100/children", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[],"_links":{}}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/100/attachments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[
+			{"id":"att1","title":"Config.yaml","mediaType":"application/yaml","pageId":"100","downloadLink":"/download/attachments/100/Config.yaml?version=1"},
+			{"id":"att2","title":"diagram.png","mediaType":"image/png","pageId":"100","_links":{"download":"/download/attachments/100/diagram.png?version=1"}}
+		],"_links":{}}`)
+	})
+	mux.HandleFunc("/wiki/download/attachments/100/Config.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if user, key, ok := r.BasicAuth(); !ok || user != "test@example.com" || key != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "replicas: 3\n")
+	})
+	mux.HandleFunc("/wiki/download/attachments/100/diagram.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConfluenceAdapter_FetchFiles_Attachments(t *testing.T) {
+	tests := []struct {
+		name               string
+		includeAttachments bool
+		includeBinary      bool
+		expected           []string
+	}{
+		{"attachments disabled", false, false, []string{"runbook.txt"}},
+		{"text attachments only", true, false, []string{"runbook.txt", "runbook_config.yaml"}},
+		{"binary attachments", true, true, []string{"runbook.txt", "runbook_config.yaml", "runbook_diagram.png"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newConfluenceAttachmentServer(t)
+			adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+				BaseURL:  server.URL,
+				Username: "test@example.com",
+				APIKey:   "test-key",
+				ParentPageMappings: []config.ParentPageMapping{
+					{ParentPageID: "100", KnowledgeID: "runbooks"},
+				},
+				IncludeAttachments:       tt.includeAttachments,
+				IncludeBinaryAttachments: tt.includeBinary,
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("FetchFiles() error = %v", err)
+			}
+
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+				if file.KnowledgeID != "runbooks" {
+					t.Errorf("Expected knowledge ID runbooks for %s, got %s", file.Path, file.KnowledgeID)
+				}
+				if file.Path == "runbook_config.yaml" && string(file.Content) != "replicas: 3\n" {
+					t.Errorf("Unexpected attachment content %q", string(file.Content))
+				}
+			}
+			sort.Strings(paths)
+
+			if fmt.Sprint(paths) != fmt.Sprint(tt.expected) {
+				t.Errorf("FetchFiles() returned %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_SkipsUnchangedPages(t *testing.T) {
+	versions := map[string]int{"100": 1, "101": 1}
+	bodyFetches := map[string]int{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/pages/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/wiki/api/v2/pages/"):]
+		switch id {
+		case "100/children":
+			fmt.Fprint(w, `{"results":[{"id":"101","title":"Child"}],"_links":{}}`)
+			return
+		case "100", "101":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("body-format") == "export_view" {
+			bodyFetches[id]++
+			fmt.Fprintf(w, `{"id":%q,"title":"Page %s","body":{"export_view":{"value":"<p>v%d</p>"}}}`, id, id, versions[id])
+			return
+		}
+		fmt.Fprintf(w, `{"id":%q,"title":"Page %s","version":{"number":%d}}`, id, id, versions[id])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := storage.NewMemory()
+	cfg := config.ConfluenceConfig{
+		BaseURL:  server.URL,
+		Username: "test@example.com",
+		APIKey:   "test-key",
+		ParentPageMappings: []config.ParentPageMapping{
+			{ParentPageID: "100", KnowledgeID: "docs"},
+		},
+	}
+
+	// fetch runs a sync in which every file but the failed one syncs and returns the paths of
+	// the changed files. Unchanged pages are returned too, marked Unchanged.
+	var adapter *ConfluenceAdapter
+	fetch := func(cfg config.ConfluenceConfig, failed string) []string {
+		t.Helper()
+		// A new adapter per run checks that versions survive restarts via the storage
+		var err error
+		adapter, err = NewConfluenceAdapter(cfg, store)
+		if err != nil {
+			t.Fatalf("NewConfluenceAdapter() error = %v", err)
+		}
+		files, err := adapter.FetchFiles(context.Background())
+		if err != nil {
+			t.Fatalf("FetchFiles() error = %v", err)
+		}
+		if len(files) != 2 || !adapter.FetchComplete() {
+			t.Errorf("Expected a complete fetch returning both pages, got %d files", len(files))
+		}
+		var paths []string
+		for _, file := range files {
+			if file.Unchanged {
+				if len(file.Content) != 0 || file.Hash == "" {
+					t.Errorf("Expected unchanged %s with its synced hash and without content, got %+v", file.Path, file)
+				}
+				continue
+			}
+			paths = append(paths, file.Path)
+			if file.Path != failed {
+				adapter.FileSynced(file)
+			}
+		}
+		if err := adapter.CommitSynced(); err != nil {
+			t.Fatalf("CommitSynced() error = %v", err)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	if paths := fetch(cfg, ""); fmt.Sprint(paths) != "[page_100.txt page_101.txt]" {
+		t.Fatalf("First sync returned %v, want both pages", paths)
+	}
+
+	// Nothing changed: neither body is fetched again
+	if paths := fetch(cfg, ""); len(paths) != 0 {
+		t.Errorf("Second sync returned %v, want no files", paths)
+	}
+	if bodyFetches["100"] != 1 || bodyFetches["101"] != 1 {
+		t.Errorf("Expected each body to be fetched once, got %v", bodyFetches)
+	}
+
+	// Only the edited page is fetched
+	versions["101"] = 2
+	if paths := fetch(cfg, "page_101.txt"); fmt.Sprint(paths) != "[page_101.txt]" {
+		t.Errorf("Third sync returned %v, want only the changed page", paths)
+	}
+
+	// The changed page failed to sync, so it is fetched until it syncs
+	if paths := fetch(cfg, ""); fmt.Sprint(paths) != "[page_101.txt]" {
+		t.Errorf("Sync after a failure returned %v, want the page that failed", paths)
+	}
+	if paths := fetch(cfg, ""); len(paths) != 0 {
+		t.Errorf("Sync after the retry returned %v, want no files", paths)
+	}
+
+	// An unchanged page the sync manager has no record of is loaded on demand
+	adapter = nil
+	fetch(cfg, "")
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	for _, file := range files {
+		if file.Path != "page_101.txt" {
+			continue
+		}
+		if err := adapter.LoadContent(context.Background(), file); err != nil {
+			t.Fatalf("LoadContent() error = %v", err)
+		}
+		if file.Unchanged || !strings.Contains(string(file.Content), "v2") {
+			t.Errorf("Expected the loaded page content, got %+v", file)
+		}
+	}
+	if bodyFetches["101"] != 4 {
+		t.Errorf("Expected the loaded body to be fetched, got %v", bodyFetches)
+	}
+
+	// ForceFullSync ignores the stored versions
+	cfg.ForceFullSync = true
+	if paths := fetch(cfg, ""); fmt.Sprint(paths) != "[page_100.txt page_101.txt]" {
+		t.Errorf("Forced sync returned %v, want both pages", paths)
+	}
+}
+
+func TestConfluenceAdapter_loadVersions_BareNumbers(t *testing.T) {
+	// Versions written by older releases lack the synced file, so the content is fetched again
+	store := storage.NewMemory()
+	if err := store.Write("confluence/page_versions.json", []byte(`{"100": 3}`)); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+		BaseURL:       "https://example.atlassian.net",
+		Username:      "test@example.com",
+		APIKey:        "test-key",
+		SpaceMappings: []config.SpaceMapping{{SpaceKey: "TEST", KnowledgeID: "knowledge-id"}},
+	}, store)
+	if err != nil {
+		t.Fatalf("NewConfluenceAdapter() error = %v", err)
+	}
+	if synced := adapter.versions["100"]; synced.Number != 3 {
+		t.Errorf("Expected version 3 to be loaded, got %+v", synced)
+	}
+	if _, ok := adapter.unchangedVersion("100", 3); ok {
+		t.Error("Expected content without a synced file to be fetched again")
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_LabelFilters(t *testing.T) {
+	var failLabels string
+	labels := map[string][]string{
+		"100": {},
+		"101": {"kb"},
+		"102": {"kb", "draft"},
+		"103": {"Public"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/pages/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/"), "/")
+		id := parts[0]
+		if _, ok := labels[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case len(parts) == 2 && parts[1] == "children":
+			fmt.Fprint(w, `{"results":[{"id":"101","title":"Child 101"},{"id":"102","title":"Child 102"},{"id":"103","title":"Child 103"}],"_links":{}}`)
+		case len(parts) == 2 && parts[1] == "labels" && id == failLabels:
+			w.WriteHeader(http.StatusForbidden)
+		case len(parts) == 2 && parts[1] == "labels":
+			var results []string
+			for i, name := range labels[id] {
+				results = append(results, fmt.Sprintf(`{"id":"%d","name":%q,"prefix":"global"}`, i, name))
+			}
+			fmt.Fprintf(w, `{"results":[%s],"_links":{}}`, strings.Join(results, ","))
+		case r.URL.Query().Get("body-format") == "export_view":
+			fmt.Fprintf(w, `{"id":%q,"title":"Page %s","body":{"export_view":{"value":"<p>body</p>"}}}`, id, id)
+		default:
+			fmt.Fprintf(w, `{"id":%q,"title":"Page %s","version":{"number":1}}`, id, id)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		includeLabels []string
+		excludeLabels []string
+		failLabels    string // page whose labels fail to fetch
+		expected      string
+		incomplete    bool
+	}{
+		{
+			name:     "no filters",
+			expected: "[page_100.txt page_101.txt page_102.txt page_103.txt]",
+		},
+		{
+			name:          "labels fail to fetch",
+			excludeLabels: []string{"draft"},
+			failLabels:    "103",
+			expected:      "[page_100.txt page_101.txt]",
+			incomplete:    true,
+		},
+		{
+			name:          "include labels",
+			includeLabels: []string{"kb", "public"},
+			expected:      "[page_101.txt page_102.txt page_103.txt]",
+		},
+		{
+			name:          "exclude labels",
+			excludeLabels: []string{"draft"},
+			expected:      "[page_100.txt page_101.txt page_103.txt]",
+		},
+		{
+			name:          "exclude wins over include",
+			includeLabels: []string{"kb"},
+			excludeLabels: []string{"draft"},
+			expected:      "[page_101.txt]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+				BaseURL:  server.URL,
+				Username: "test@example.com",
+				APIKey:   "test-key",
+				ParentPageMappings: []config.ParentPageMapping{
+					{ParentPageID: "100", KnowledgeID: "docs"},
+				},
+				IncludeLabels: tt.includeLabels,
+				ExcludeLabels: tt.excludeLabels,
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+			failLabels = tt.failLabels
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("FetchFiles() error = %v", err)
+			}
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+			}
+			sort.Strings(paths)
+
+			if fmt.Sprint(paths) != tt.expected {
+				t.Errorf("FetchFiles() returned %v, want %s", paths, tt.expected)
+			}
+			if complete := adapter.FetchComplete(); complete == tt.incomplete {
+				t.Errorf("FetchComplete() = %v, want %v", complete, !tt.incomplete)
+			}
+		})
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_CQLMappings(t *testing.T) {
+	const cql = `label = "kb" and lastModified > now("-30d")`
+	var queries []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/rest/api/content/search", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("cql"))
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprint(w, `{"results":[
+				{"id":"301","type":"page","title":"Runbook","version":{"number":2},"_links":{"webui":"/spaces/OPS/pages/301/Runbook"}},
+				{"id":"302","type":"blogpost","title":"Announcement","version":{"number":1}}
+			],"_links":{"base":"https://example.atlassian.net/wiki","next":"/rest/api/content/search?cql=ignored&cursor=abc"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"results":[{"id":"303","type":"page","title":"FAQ","version":{"number":5}}],"_links":{}}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/"), "/")[0]
+		fmt.Fprintf(w, `{"id":%q,"body":{"export_view":{"value":"<p>body of %s</p>"}}}`, id, id)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+		BaseURL:     server.URL,
+		Username:    "test@example.com",
+		APIKey:      "test-key",
+		CQLMappings: []config.CQLMapping{{CQL: cql, KnowledgeID: "ops"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewConfluenceAdapter() error = %v", err)
+	}
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+
+	if len(queries) != 2 || queries[0] != cql {
+		t.Errorf("Expected the CQL query to be sent and the next page to be followed, got %q", queries)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		if file.KnowledgeID != "ops" {
+			t.Errorf("Expected file %s in knowledge ops, got %q", file.Path, file.KnowledgeID)
+		}
+	}
+	sort.Strings(paths)
+	if expected := "[faq.txt runbook.txt]"; fmt.Sprint(paths) != expected {
+		t.Errorf("FetchFiles() returned %v, want %s", paths, expected)
+	}
+	if len(files) > 0 && !strings.Contains(string(files[0].Content), "body of 301") {
+		t.Errorf("Expected the page body in the file content, got %q", files[0].Content)
+	}
+
+	// The versions returned by the search skip synced pages on the next run
+	for _, file := range files {
+		adapter.FileSynced(file)
+	}
+	files, err = adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	if changed := countChangedFiles(files); changed != 0 {
+		t.Errorf("Expected unchanged pages to be skipped, got %d changed files", changed)
+	}
+
+	// A requested full fetch returns them once
+	adapter.RequestFullFetch()
+	for _, expected := range []int{2, 0} {
+		files, err = adapter.FetchFiles(context.Background())
+		if err != nil {
+			t.Fatalf("FetchFiles() error = %v", err)
+		}
+		if changed := countChangedFiles(files); changed != expected {
+			t.Errorf("Expected %d changed files, got %d", expected, changed)
+		}
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_AncestorFilenames(t *testing.T) {
+	titles := map[string]string{"100": "Docs", "101": "Backend", "102": "Frontend", "201": "Overview", "202": "Overview"}
+	ancestors := map[string][]string{"101": {"100"}, "102": {"100"}, "201": {"100", "101"}, "202": {"100", "102"}}
+	failAncestors := ""
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/spaces", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"id":"1","key":"DOCS"}]}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/spaces/1/pages", func(w http.ResponseWriter, r *http.Request) {
+		var pages []string
+		for _, id := range []string{"101", "102", "201", "202"} {
+			pages = append(pages, fmt.Sprintf(`{"id":%q,"title":%q}`, id, titles[id]))
+		}
+		fmt.Fprintf(w, `{"results":[%s],"_links":{}}`, strings.Join(pages, ","))
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/"), "/")
+		id := parts[0]
+		switch {
+		case len(parts) == 2 && parts[1] == "ancestors" && id == failAncestors:
+			w.WriteHeader(http.StatusForbidden)
+		case len(parts) == 2 && parts[1] == "ancestors":
+			var results []string
+			for _, ancestorID := range ancestors[id] {
+				results = append(results, fmt.Sprintf(`{"id":%q,"type":"page"}`, ancestorID))
+			}
+			fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(results, ","))
+		case r.URL.Query().Get("body-format") == "export_view":
+			fmt.Fprintf(w, `{"id":%q,"title":%q,"body":{"export_view":{"value":"<p>body</p>"}}}`, id, titles[id])
+		default:
+			fmt.Fprintf(w, `{"id":%q,"title":%q}`, id, titles[id])
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+		BaseURL:           server.URL,
+		Username:          "test@example.com",
+		APIKey:            "test-key",
+		SpaceMappings:     []config.SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "docs"}},
+		AncestorFilenames: true,
+		Breadcrumbs:       true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewConfluenceAdapter() error = %v", err)
+	}
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	byPath := make(map[string]*File)
+	var paths []string
+	for _, file := range files {
+		byPath[file.Path] = file
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+
+	expected := "[docs__backend.txt docs__backend__overview.txt docs__frontend.txt docs__frontend__overview.txt]"
+	if fmt.Sprint(paths) != expected {
+		t.Fatalf("FetchFiles() returned %v, want %s", paths, expected)
+	}
+
+	content := string(byPath["docs__frontend__overview.txt"].Content)
+	if !strings.Contains(content, "Breadcrumb: Docs > Frontend > Overview\n") {
+		t.Errorf("Expected breadcrumb in page header, got %q", content)
+	}
+	if !adapter.FetchComplete() {
+		t.Error("Expected a complete fetch")
+	}
+
+	// A page whose ancestors fail to fetch isn't renamed to its bare title but skipped, and
+	// the incomplete fetch keeps its synced file
+	failAncestors = "202"
+	files, err = adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	paths = nil
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+	if expected := "[docs__backend.txt docs__backend__overview.txt docs__frontend.txt]"; fmt.Sprint(paths) != expected {
+		t.Errorf("FetchFiles() returned %v, want %s", paths, expected)
+	}
+	if adapter.FetchComplete() {
+		t.Error("Expected the fetch to be incomplete after the ancestors failed to fetch")
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_UnchangedAncestorFilenames(t *testing.T) {
+	titles := map[string]string{"100": "Docs", "101": "Backend", "102": "Frontend", "201": "Overview", "202": "Overview"}
+	ancestors := map[string][]string{"201": {"100", "101"}, "202": {"100", "102"}}
+	versions := map[string]int{"201": 1, "202": 1}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/spaces", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"id":"1","key":"DOCS"}]}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/spaces/1/pages", func(w http.ResponseWriter, r *http.Request) {
+		// The changed page is listed before the unchanged one
+		var pages []string
+		for _, id := range []string{"202", "201"} {
+			pages = append(pages, fmt.Sprintf(`{"id":%q,"title":%q,"version":{"number":%d}}`, id, titles[id], versions[id]))
+		}
+		fmt.Fprintf(w, `{"results":[%s],"_links":{}}`, strings.Join(pages, ","))
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/wiki/api/v2/pages/"), "/")
+		id := parts[0]
+		switch {
+		case len(parts) == 2 && parts[1] == "ancestors":
+			var results []string
+			for _, ancestorID := range ancestors[id] {
+				results = append(results, fmt.Sprintf(`{"id":%q,"type":"page"}`, ancestorID))
+			}
+			fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(results, ","))
+		case r.URL.Query().Get("body-format") == "export_view":
+			fmt.Fprintf(w, `{"id":%q,"title":%q,"body":{"export_view":{"value":"<p>body</p>"}}}`, id, titles[id])
+		default:
+			fmt.Fprintf(w, `{"id":%q,"title":%q}`, id, titles[id])
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+		BaseURL:           server.URL,
+		Username:          "test@example.com",
+		APIKey:            "test-key",
+		SpaceMappings:     []config.SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "docs"}},
+		AncestorFilenames: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewConfluenceAdapter() error = %v", err)
+	}
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	for _, file := range files {
+		adapter.FileSynced(file)
+	}
+
+	// The changed page moved next to the unchanged one, so its new name clashes with the
+	// name the unchanged page synced under
+	ancestors["202"] = []string{"100", "101"}
+	versions["202"] = 2
+	files, err = adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+	byPath := make(map[string]*File)
+	for _, file := range files {
+		if _, ok := byPath[file.Path]; ok {
+			t.Errorf("Expected unique filenames, got %s twice", file.Path)
+		}
+		byPath[file.Path] = file
+	}
+	if kept := byPath["docs__backend__overview.txt"]; kept == nil || !kept.Unchanged {
+		t.Errorf("Expected the unchanged page to keep docs__backend__overview.txt, got %v", byPath)
+	}
+	if len(byPath) != 2 {
+		t.Errorf("Expected the changed page under a suffixed name, got %v", byPath)
+	}
+}
+
+func TestConfluenceAdapter_FetchFiles_SourceLink(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wiki/api/v2/spaces", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"id":"1","key":"OPS"}]}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/spaces/1/pages", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"id":"123","title":"Runbook","_links":{"webui":"/spaces/OPS/pages/123/Runbook"}}],"_links":{}}`)
+	})
+	mux.HandleFunc("/wiki/api/v2/pages/123", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"123","title":"Runbook","body":{"export_view":{"value":"<p>body</p>"}}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		includeSourceLink bool
+	}{
+		{name: "enabled", includeSourceLink: true},
+		{name: "disabled", includeSourceLink: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+				BaseURL:           server.URL,
+				Username:          "test@example.com",
+				APIKey:            "test-key",
+				SpaceMappings:     []config.SpaceMapping{{SpaceKey: "OPS", KnowledgeID: "ops"}},
+				IncludeSourceLink: tt.includeSourceLink,
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewConfluenceAdapter() error = %v", err)
+			}
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("FetchFiles() error = %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(files))
+			}
+
+			content := string(files[0].Content)
+			link := "[View in Confluence](" + server.URL + "/wiki/spaces/OPS/pages/123/Runbook)\n\n"
+			if tt.includeSourceLink && !strings.Contains(content, "---\n\n"+link+"body") {
+				t.Errorf("Expected an absolute source link before the body, got %q", content)
+			}
+			header := "LinkToPage: " + server.URL + "/wiki/spaces/OPS/pages/123/Runbook\n"
+			if tt.includeSourceLink && strings.Contains(content, "LinkToPage:") {
+				t.Errorf("Expected the source link to replace the LinkToPage header, got %q", content)
+			}
+			if !tt.includeSourceLink && (!strings.Contains(content, "CreatedAt: \n"+header+"Title: Runbook\n") || strings.Contains(content, "[View in Confluence]")) {
+				t.Errorf("Expected only the LinkToPage header, got %q", content)
+			}
+		})
+	}
+}
+
+func TestConfluenceAdapter_ancestorFilename_Length(t *testing.T) {
+	adapter := &ConfluenceAdapter{}
+	long := strings.Repeat("a", 90)
+
+	first := adapter.ancestorFilename([]string{long, long, long + "b"})
+	second := adapter.ancestorFilename([]string{long, long, long + "c"})
+
+	if len(first) != maxAncestorFilenameLength || len(second) != maxAncestorFilenameLength {
+		t.Errorf("Expected filenames capped at %d characters, got %d and %d", maxAncestorFilenameLength, len(first), len(second))
+	}
+	if first == second {
+		t.Errorf("Expected truncated filenames of different pages to differ, both are %s", first)
+	}
+}
+
+func TestConfluenceAdapter_claimFilename_TruncationCollisions(t *testing.T) {
+	adapter := &ConfluenceAdapter{config: config.ConfluenceConfig{MaxFilenameLength: 30}}
+	prefix := "Release notes for the platform team "
+
+	tests := []struct {
+		name        string
+		knowledgeID string
+		id          string
+		title       string
+		unique      bool // whether the page keeps its sanitized name
+	}{
+		{name: "first page keeps its name", knowledgeID: "knowledge-1", id: "101", title: prefix + "2024", unique: true},
+		{name: "truncated clash gets a suffix", knowledgeID: "knowledge-1", id: "102", title: prefix + "2025"},
+		{name: "same page keeps its name", knowledgeID: "knowledge-1", id: "101", title: prefix + "2024", unique: true},
+		{name: "other knowledge base keeps its name", knowledgeID: "knowledge-2", id: "103", title: prefix + "2026", unique: true},
+	}
+
+	seen := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sanitized := adapter.SanitizeFilename(tt.title)
+			if len(sanitized) != 30 {
+				t.Fatalf("Expected the title to be truncated to 30 characters, got %q", sanitized)
+			}
+
+			filename := adapter.claimFilename(tt.knowledgeID, tt.id, sanitized+".md")
+			if tt.unique != (filename == sanitized+".md") {
+				t.Errorf("claimFilename() = %q for sanitized name %q", filename, sanitized)
+			}
+			if !strings.HasSuffix(filename, ".md") || len(filename) > 30+len(".md") {
+				t.Errorf("Expected %q to keep its extension within the length limit", filename)
+			}
+			claim := tt.knowledgeID + "/" + filename
+			if owner, ok := seen[claim]; ok && owner != tt.id {
+				t.Errorf("Expected distinct names, %s and %s both use %s", owner, tt.id, claim)
+			}
+			seen[claim] = tt.id
+		})
+	}
+
+	// The suffix depends on the page ID, so the page gets the same name in the next run
+	again := &ConfluenceAdapter{config: config.ConfluenceConfig{MaxFilenameLength: 30}}
+	first := adapter.SanitizeFilename(prefix+"2024") + ".md"
+	again.claimFilename("knowledge-1", "101", first)
+	expected := adapter.claimFilename("knowledge-1", "102", first)
+	if name := again.claimFilename("knowledge-1", "102", first); name != expected {
+		t.Errorf("Expected a stable suffixed name %q, got %q", expected, name)
+	}
+}
+
+// countChangedFiles returns the number of files not marked Unchanged
+func countChangedFiles(files []*File) int {
+	changed := 0
+	for _, file := range files {
+		if !file.Unchanged {
+			changed++
+		}
+	}
+	return changed
+}
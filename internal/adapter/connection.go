@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// CheckConnection authenticates as the token's user
+func (g *GitHubAdapter) CheckConnection(ctx context.Context) error {
+	user, _, err := g.client.Users.Get(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with GitHub: %w", err)
+	}
+	g.log().Debugf("Authenticated with GitHub as %s", user.GetLogin())
+	return nil
+}
+
+// CheckConnection lists a single space, which requires valid credentials
+func (c *ConfluenceAdapter) CheckConnection(ctx context.Context) error {
+	if c.isServer() {
+		return checkAuthGET(ctx, c.client, c.restAPIURL("/space?limit=1"), c.authenticate)
+	}
+	return checkAuthGET(ctx, c.client, c.config.BaseURL+"/wiki/api/v2/spaces?limit=1", c.authenticate)
+}
+
+// CheckConnection requests the authenticated user
+func (j *JiraAdapter) CheckConnection(ctx context.Context) error {
+	return checkAuthGET(ctx, j.client, j.restAPIURL("/myself"), j.authenticate)
+}
+
+// CheckConnection calls auth.test with the bot token
+func (s *SlackAdapter) CheckConnection(ctx context.Context) error {
+	authTest, err := s.client.AuthTestContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with Slack: %w", err)
+	}
+	s.log().Debugf("Authenticated with Slack as %s (team: %s)", authTest.User, authTest.Team)
+	return nil
+}
+
+// CheckConnection verifies that every mapped folder is a readable directory
+func (l *LocalFolderAdapter) CheckConnection(ctx context.Context) error {
+	for _, folder := range l.folders {
+		if _, err := os.ReadDir(folder); err != nil {
+			return fmt.Errorf("failed to read folder %s: %w", folder, err)
+		}
+	}
+	return nil
+}
+
+// checkAuthGET sends a GET request with the credentials set by authenticate and fails on any
+// status but 200. It doesn't retry, so a bad URL or credentials are reported right away.
+func checkAuthGET(ctx context.Context, client *http.Client, url string, authenticate func(*http.Request)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	authenticate(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // Consume body for proper connection reuse
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestCheckConnection(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string // request path of the check
+		response  string // JSON body of a successful check
+		rejected  string // JSON body of a rejected check, sent with 200 when the API reports errors in the body
+		newClient func(t *testing.T, server *httptest.Server) ConnectionChecker
+	}{
+		{
+			name:     "github",
+			path:     "/user",
+			response: `{"login": "octocat"}`,
+			newClient: func(t *testing.T, server *httptest.Server) ConnectionChecker {
+				return newTestGitHubAdapter(t, server, config.GitHubConfig{
+					Mappings: []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+				})
+			},
+		},
+		{
+			name:     "confluence",
+			path:     "/wiki/api/v2/spaces",
+			response: `{"results": []}`,
+			newClient: func(t *testing.T, server *httptest.Server) ConnectionChecker {
+				adapter, err := NewConfluenceAdapter(config.ConfluenceConfig{
+					BaseURL:       server.URL,
+					Username:      "test@example.com",
+					APIKey:        "test-key",
+					SpaceMappings: []config.SpaceMapping{{SpaceKey: "TEST", KnowledgeID: "knowledge-id"}},
+				}, nil)
+				if err != nil {
+					t.Fatalf("Failed to create Confluence adapter: %v", err)
+				}
+				return adapter
+			},
+		},
+		{
+			name:     "jira",
+			path:     "/rest/api/3/myself",
+			response: `{"accountId": "123"}`,
+			newClient: func(t *testing.T, server *httptest.Server) ConnectionChecker {
+				return newTestJiraAdapter(t, server.URL, false)
+			},
+		},
+		{
+			name:     "jira server",
+			path:     "/rest/api/2/myself",
+			response: `{"name": "jdoe"}`,
+			newClient: func(t *testing.T, server *httptest.Server) ConnectionChecker {
+				adapter := newTestJiraAdapter(t, server.URL, false)
+				adapter.config.Deployment = "server"
+				return adapter
+			},
+		},
+		{
+			name:     "slack",
+			path:     "/auth.test",
+			response: `{"ok": true, "user": "bot", "team": "team"}`,
+			rejected: `{"ok": false, "error": "invalid_auth"}`,
+			newClient: func(t *testing.T, server *httptest.Server) ConnectionChecker {
+				return newTestSlackAdapter(t, server, storage.NewMemory())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, valid := range []bool{true, false} {
+			name := tt.name + "/valid credentials"
+			if !valid {
+				name = tt.name + "/rejected credentials"
+			}
+			t.Run(name, func(t *testing.T) {
+				requested := false
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != tt.path {
+						t.Errorf("Unexpected request for %s", r.URL.Path)
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					requested = true
+					w.Header().Set("Content-Type", "application/json")
+					switch {
+					case valid:
+						w.Write([]byte(tt.response))
+					case tt.rejected != "":
+						w.Write([]byte(tt.rejected))
+					default:
+						w.WriteHeader(http.StatusUnauthorized)
+						w.Write([]byte(`{"message": "Bad credentials"}`))
+					}
+				}))
+				defer server.Close()
+
+				err := tt.newClient(t, server).CheckConnection(context.Background())
+				if !requested {
+					t.Errorf("Expected a request to %s", tt.path)
+				}
+				if valid && err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				if !valid && err == nil {
+					t.Errorf("Expected rejected credentials to fail the check")
+				}
+			})
+		}
+	}
+}
+
+func TestLocalFolderAdapter_CheckConnection(t *testing.T) {
+	folder := t.TempDir()
+
+	adapter := &LocalFolderAdapter{folders: []string{folder}}
+	if err := adapter.CheckConnection(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	adapter.folders = append(adapter.folders, filepath.Join(folder, "missing"))
+	if err := adapter.CheckConnection(context.Background()); err == nil {
+		t.Errorf("Expected a missing folder to fail the check")
+	}
+}
@@ -5,14 +5,18 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v56/github"
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/oauth2"
 )
 
 // GitHubAdapter implements the Adapter interface for GitHub repositories
@@ -21,31 +25,66 @@ type GitHubAdapter struct {
 	config       config.GitHubConfig
 	lastSync     time.Time
 	repositories []string
-	mappings     map[string]string // repository -> knowledge_id mapping
+	mappings     map[string]string                    // repository -> knowledge_id mapping
+	branches     map[string]string                    // repository -> branch mapping (empty for default branch)
+	paths        map[string][]string                  // repository -> subpaths to sync (empty for the whole repository)
+	excludeDirs  map[string][]string                  // repository -> directories that are never fetched
+	releases     map[string]bool                      // repository -> whether to sync release notes
+	assets       map[string]bool                      // repository -> whether to download text release assets
+	issues       map[string]issueOptions              // repository -> issues and pull requests to sync
+	incomplete   bool                                 // whether the last fetch skipped files after an error
+	incompleteMu sync.Mutex                           // guards incomplete during parallel downloads
+	logger       logging.Logger                       // nil to log to the global logrus logger
+	repoStates   map[string]*githubRepoState          // repository -> previous fetch, with incremental_sync
+	issueStates  map[string]map[int]*githubIssueState // repository -> issue number -> previous fetch, with incremental_sync
+	statesMu     sync.Mutex                           // guards repoStates during parallel content loads
 }
 
+// defaultGitHubDownloadConcurrency is the number of parallel downloads when
+// github.download_concurrency is unset
+const defaultGitHubDownloadConcurrency = 4
+
 // NewGitHubAdapter creates a new GitHub adapter
 func NewGitHubAdapter(cfg config.GitHubConfig) (*GitHubAdapter, error) {
-	if cfg.Token == "" {
+	tokens := githubTokens(cfg.Token, cfg.Tokens)
+	if len(tokens) == 0 {
 		return nil, fmt.Errorf("GitHub token is required")
 	}
+	if len(tokens) > 1 {
+		logrus.Infof("Rotating %d GitHub tokens", len(tokens))
+	}
 
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: cfg.Token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+	tc := &http.Client{Transport: newGitHubTokenTransport(tokens, nil)}
 
-	client := github.NewClient(tc)
+	client, err := newGitHubClient(cfg, tc)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build repository mappings
 	mappings := make(map[string]string)
+	branches := make(map[string]string)
+	paths := make(map[string][]string)
+	excludeDirs := make(map[string][]string)
+	releases := make(map[string]bool)
+	assets := make(map[string]bool)
+	issues := make(map[string]issueOptions)
 	repos := []string{}
 
 	// Process mappings
 	for _, mapping := range cfg.Mappings {
 		if mapping.Repository != "" && mapping.KnowledgeID != "" {
 			mappings[mapping.Repository] = mapping.KnowledgeID
+			branches[mapping.Repository] = mapping.Branch
+			paths[mapping.Repository] = mapping.Paths
+			excludeDirs[mapping.Repository] = mapping.ExcludeDirs
+			releases[mapping.Repository] = mapping.IncludeReleases
+			assets[mapping.Repository] = mapping.ReleaseAssets
+			issues[mapping.Repository] = issueOptions{
+				issues:       mapping.IncludeIssues,
+				pullRequests: mapping.IncludePullRequests,
+				state:        mapping.IssueState,
+			}
 			repos = append(repos, mapping.Repository)
 		}
 	}
@@ -59,71 +98,284 @@ func NewGitHubAdapter(cfg config.GitHubConfig) (*GitHubAdapter, error) {
 		config:       cfg,
 		repositories: repos,
 		mappings:     mappings,
+		branches:     branches,
+		paths:        paths,
+		excludeDirs:  excludeDirs,
+		releases:     releases,
+		assets:       assets,
+		issues:       issues,
 		lastSync:     time.Now().Add(-24 * time.Hour), // Default to 24 hours ago
 	}, nil
 }
 
+// newGitHubClient creates a client for public GitHub or, when a base URL is configured, for a
+// GitHub Enterprise Server instance. Without an explicit upload URL it is derived from the base URL.
+func newGitHubClient(cfg config.GitHubConfig, httpClient *http.Client) (*github.Client, error) {
+	if cfg.BaseURL == "" {
+		return github.NewClient(httpClient), nil
+	}
+
+	if err := validateGitHubURL(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid GitHub base URL: %w", err)
+	}
+
+	uploadURL := cfg.UploadURL
+	if uploadURL == "" {
+		// go-github appends "api/uploads/" to the host root, so drop the REST API path
+		uploadURL = strings.TrimSuffix(strings.TrimSuffix(cfg.BaseURL, "/"), "/api/v3")
+	} else if err := validateGitHubURL(uploadURL); err != nil {
+		return nil, fmt.Errorf("invalid GitHub upload URL: %w", err)
+	}
+
+	client, err := github.NewEnterpriseClient(cfg.BaseURL, uploadURL, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub Enterprise client: %w", err)
+	}
+	logrus.Infof("Using GitHub Enterprise API at %s", client.BaseURL)
+	return client, nil
+}
+
+// validateGitHubURL checks that rawURL is an absolute http(s) URL
+func validateGitHubURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q must be an absolute http:// or https:// URL", rawURL)
+	}
+	return nil
+}
+
 // Name returns the adapter name
 func (g *GitHubAdapter) Name() string {
 	return "github"
 }
 
+// SetLogger sets the logger the adapter writes to
+func (g *GitHubAdapter) SetLogger(logger logging.Logger) {
+	g.logger = logger
+}
+
+// log returns the adapter's logger
+func (g *GitHubAdapter) log() logging.Logger {
+	if g.logger == nil {
+		return logging.Default()
+	}
+	return g.logger
+}
+
 // FetchFiles retrieves files from GitHub repositories
 func (g *GitHubAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	var files []*File
+	g.incompleteMu.Lock()
+	g.incomplete = false
+	g.incompleteMu.Unlock()
 
 	for _, repo := range g.repositories {
-		logrus.Debugf("Fetching files from repository: %s", repo)
+		g.log().Debugf("Fetching files from repository: %s", repo)
 		knowledgeID := g.mappings[repo]
-		repoFiles, err := g.fetchRepositoryFiles(ctx, repo, knowledgeID)
+		repoFiles, err := g.fetchRepository(ctx, repo, knowledgeID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch files from repository %s: %w", repo, err)
 		}
-		logrus.Debugf("Found %d files in repository %s (knowledge_id: %s)", len(repoFiles), repo, knowledgeID)
+		g.log().Debugf("Found %d files in repository %s (knowledge_id: %s)", len(repoFiles), repo, knowledgeID)
 		files = append(files, repoFiles...)
+
+		// Releases, issues and pull requests are supplementary, so a failure to fetch them is
+		// logged and the repository contents are still synced
+		if g.releases[repo] {
+			releaseFiles, err := g.fetchReleases(ctx, repo, g.assets[repo], knowledgeID)
+			if err != nil {
+				g.log().Warnf("Failed to fetch releases from repository %s: %v", repo, err)
+				g.setIncomplete()
+			} else {
+				g.log().Debugf("Found %d release files in repository %s", len(releaseFiles), repo)
+				files = append(files, releaseFiles...)
+			}
+		}
+
+		if opts := g.issues[repo]; opts.issues || opts.pullRequests {
+			issueFiles, err := g.fetchIssues(ctx, repo, opts, knowledgeID)
+			if err != nil {
+				g.log().Warnf("Failed to fetch issues from repository %s: %v", repo, err)
+				g.setIncomplete()
+			} else {
+				g.log().Debugf("Found %d issue and pull request files in repository %s", len(issueFiles), repo)
+				files = append(files, issueFiles...)
+			}
+		}
 	}
 
-	logrus.Debugf("Total files fetched: %d", len(files))
+	g.log().Debugf("Total files fetched: %d", len(files))
 	return files, nil
 }
 
-// fetchRepositoryFiles fetches files from a specific repository
-func (g *GitHubAdapter) fetchRepositoryFiles(ctx context.Context, repo string, knowledgeID string) ([]*File, error) {
+// fetchRepositoryFiles fetches files from a specific repository, starting at each of the
+// given subpaths or at the repository root when none are configured
+func (g *GitHubAdapter) fetchRepositoryFiles(ctx context.Context, repo string, branch string, paths []string, knowledgeID string) ([]*File, error) {
 	parts := strings.Split(repo, "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("invalid repository format, expected 'owner/repo'")
 	}
 
 	owner, repoName := parts[0], parts[1]
+	opts := contentOptions(branch)
+	if branch != "" {
+		g.log().Debugf("Using branch %s for repository %s", branch, repo)
+	}
 
-	// Get repository contents
-	_, contents, _, err := g.client.Repositories.GetContents(ctx, owner, repoName, "", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repository contents: %w", err)
+	if g.config.UseTreeAPI {
+		files, err := g.fetchRepositoryTree(ctx, owner, repoName, branch, paths, knowledgeID)
+		if err == nil {
+			return files, nil
+		}
+		g.log().Warnf("Failed to list repository %s with the Git Trees API, walking its contents instead: %v", repo, err)
+	}
+
+	startPaths := []string{""}
+	if len(paths) > 0 {
+		startPaths = paths
 	}
 
 	var files []*File
-	for _, content := range contents {
-		fileList, err := g.processContent(ctx, owner, repoName, content, "", knowledgeID)
+	for _, startPath := range startPaths {
+		startPath = strings.Trim(startPath, "/")
+		if startPath != "" {
+			g.log().Debugf("Fetching path %s from repository %s", startPath, repo)
+		}
+
+		// Get repository contents; a file path returns the file itself instead of a listing
+		fileContent, contents, _, err := g.client.Repositories.GetContents(ctx, owner, repoName, startPath, opts)
 		if err != nil {
-			continue // Skip files that can't be processed
+			return nil, fmt.Errorf("failed to get repository contents at %q: %w", startPath, err)
+		}
+		if fileContent != nil {
+			contents = []*github.RepositoryContent{fileContent}
 		}
-		if fileList != nil {
-			files = append(files, fileList...)
+
+		// Keep paths relative to the repository root so files from different subpaths don't collide
+		parentPath := startPath
+		if fileContent != nil {
+			parentPath = filepath.Dir(startPath)
+			if parentPath == "." {
+				parentPath = ""
+			}
 		}
+
+		files = append(files, g.processContents(ctx, owner, repoName, contents, parentPath, knowledgeID, opts)...)
 	}
 
 	return files, nil
 }
 
+// processContents processes the items of a directory listing. Directories and submodules are
+// walked one after another, while the files are downloaded in parallel. Items that can't be
+// processed are skipped and mark the fetch incomplete.
+func (g *GitHubAdapter) processContents(ctx context.Context, owner, repo string, contents []*github.RepositoryContent, path string, knowledgeID string, opts *github.RepositoryContentGetOptions) []*File {
+	var files []*File
+	var fileContents []*github.RepositoryContent
+	for _, content := range contents {
+		if content.GetType() == "file" && !isSubmodule(content) {
+			fileContents = append(fileContents, content)
+			continue
+		}
+		fileList, err := g.processContent(ctx, owner, repo, content, path, knowledgeID, opts)
+		if err != nil {
+			g.log().Debugf("Skipping %s: %v", content.GetPath(), err)
+			g.setIncomplete()
+			continue
+		}
+		files = append(files, fileList...)
+	}
+
+	return append(files, g.downloadFiles(len(fileContents), func(i int) (*File, error) {
+		fileList, err := g.processContent(ctx, owner, repo, fileContents[i], path, knowledgeID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileContents[i].GetPath(), err)
+		}
+		if len(fileList) == 0 {
+			return nil, nil
+		}
+		return fileList[0], nil
+	})...)
+}
+
+// downloadFiles calls download for each of count files through a pool of
+// download_concurrency workers. download returns nil for files it skips; files that fail to
+// download are logged, skipped and mark the fetch incomplete. The files are returned in the
+// order their downloads finished.
+func (g *GitHubAdapter) downloadFiles(count int, download func(i int) (*File, error)) []*File {
+	concurrency := g.config.DownloadConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultGitHubDownloadConcurrency
+	}
+
+	var files []*File
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			file, err := download(i)
+			if err != nil {
+				g.log().Debugf("Skipping %v", err)
+				g.setIncomplete()
+				return
+			}
+			if file != nil {
+				mu.Lock()
+				files = append(files, file)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return files
+}
+
+// setIncomplete records that the current fetch skipped files after an error
+func (g *GitHubAdapter) setIncomplete() {
+	g.incompleteMu.Lock()
+	defer g.incompleteMu.Unlock()
+	g.incomplete = true
+}
+
+// isIncomplete reports whether the current or last fetch skipped files after an error
+func (g *GitHubAdapter) isIncomplete() bool {
+	g.incompleteMu.Lock()
+	defer g.incompleteMu.Unlock()
+	return g.incomplete
+}
+
 // processContent processes a GitHub content item recursively
-func (g *GitHubAdapter) processContent(ctx context.Context, owner, repo string, content *github.RepositoryContent, path string, knowledgeID string) ([]*File, error) {
+func (g *GitHubAdapter) processContent(ctx context.Context, owner, repo string, content *github.RepositoryContent, path string, knowledgeID string, opts *github.RepositoryContentGetOptions) ([]*File, error) {
 	if content == nil {
 		return nil, nil
 	}
 
 	currentPath := filepath.Join(path, content.GetName())
 
+	// Excluded directories are skipped without listing them
+	if (content.GetType() == "dir" || isSubmodule(content)) && excludedGitHubDir(content.GetPath(), g.excludeDirs[owner+"/"+repo]) {
+		g.log().Debugf("Skipping excluded directory %s in %s/%s", content.GetPath(), owner, repo)
+		return nil, nil
+	}
+
+	// Submodules would otherwise look like empty files
+	if isSubmodule(content) {
+		if !g.config.FollowSubmodules {
+			g.log().Debugf("Skipping submodule %s in %s/%s", currentPath, owner, repo)
+			return nil, nil
+		}
+		return g.fetchSubmodule(ctx, owner, repo, currentPath, content, knowledgeID, opts)
+	}
+
 	// Skip binary files and non-text files
 	if content.GetType() == "file" {
 		// Check if it's a text file
@@ -131,52 +383,157 @@ func (g *GitHubAdapter) processContent(ctx context.Context, owner, repo string,
 			return nil, nil
 		}
 
+		// Skip files exceeding the configured size limit before downloading them
+		if g.config.MaxFileSizeBytes > 0 && int64(content.GetSize()) > g.config.MaxFileSizeBytes {
+			g.log().Debugf("Skipping file %s: size %d bytes exceeds limit of %d bytes", currentPath, content.GetSize(), g.config.MaxFileSizeBytes)
+			return nil, nil
+		}
+
 		// Get file content
-		fileContent, err := g.getFileContent(ctx, owner, repo, content)
+		fileContent, err := g.getFileContent(ctx, owner, repo, content, opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get file content: %w", err)
 		}
 
-		// Calculate hash
-		hash := fmt.Sprintf("%x", sha256.Sum256(fileContent))
-
-		return []*File{{
-			Path:        currentPath,
-			Content:     fileContent,
-			Hash:        hash,
-			Modified:    time.Now(), // GitHub API doesn't provide modification time for content
-			Size:        int64(len(fileContent)),
-			Source:      fmt.Sprintf("%s/%s", owner, repo),
-			KnowledgeID: knowledgeID,
-		}}, nil
+		return []*File{newGitHubFile(owner, repo, currentPath, fileContent, knowledgeID)}, nil
 	}
 
 	// If it's a directory, recurse
 	if content.GetType() == "dir" {
-		_, contents, _, err := g.client.Repositories.GetContents(ctx, owner, repo, content.GetPath(), nil)
+		_, contents, _, err := g.client.Repositories.GetContents(ctx, owner, repo, content.GetPath(), opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get directory contents: %w", err)
 		}
 
-		var allFiles []*File
-		for _, subContent := range contents {
-			files, err := g.processContent(ctx, owner, repo, subContent, currentPath, knowledgeID)
-			if err != nil {
+		return g.processContents(ctx, owner, repo, contents, currentPath, knowledgeID, opts), nil
+	}
+
+	return nil, nil
+}
+
+// fetchRepositoryTree lists the whole repository with a single recursive Git Trees API call
+// and downloads only the text files that pass the filters. It fails for truncated trees so
+// the caller can fall back to walking the repository contents.
+func (g *GitHubAdapter) fetchRepositoryTree(ctx context.Context, owner, repo, branch string, paths []string, knowledgeID string) ([]*File, error) {
+	ref := branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	tree, _, err := g.client.Git.GetTree(ctx, owner, repo, ref, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository tree: %w", err)
+	}
+	if tree.GetTruncated() {
+		return nil, fmt.Errorf("repository tree has too many entries and was truncated")
+	}
+	g.log().Debugf("Repository tree for %s/%s has %d entries", owner, repo, len(tree.Entries))
+
+	excludeDirs := g.excludeDirs[owner+"/"+repo]
+
+	var files []*File
+	var blobs []*github.TreeEntry
+	for _, entry := range tree.Entries {
+		if excludedGitHubDir(path.Dir(entry.GetPath()), excludeDirs) || (entry.GetType() == "commit" && excludedGitHubDir(entry.GetPath(), excludeDirs)) {
+			continue
+		}
+
+		// Submodules are listed as commits of another repository
+		if entry.GetType() == "commit" && inGitHubPaths(entry.GetPath(), paths) {
+			if !g.config.FollowSubmodules {
+				g.log().Debugf("Skipping submodule %s in %s/%s", entry.GetPath(), owner, repo)
 				continue
 			}
-			if files != nil {
-				allFiles = append(allFiles, files...)
+			submodule := &github.RepositoryContent{Path: entry.Path, SHA: entry.SHA}
+			submoduleFiles, err := g.fetchSubmodule(ctx, owner, repo, entry.GetPath(), submodule, knowledgeID, contentOptions(branch))
+			if err != nil {
+				g.log().Warnf("Skipping submodule %s in %s/%s: %v", entry.GetPath(), owner, repo, err)
+				g.setIncomplete()
+				continue
 			}
+			files = append(files, submoduleFiles...)
+			continue
+		}
+		if entry.GetType() != "blob" {
+			continue
+		}
+
+		path := entry.GetPath()
+		if !inGitHubPaths(path, paths) || !isTextFile(filepath.Base(path)) {
+			continue
 		}
 
-		return allFiles, nil
+		// Skip files exceeding the configured size limit before downloading them
+		if g.config.MaxFileSizeBytes > 0 && int64(entry.GetSize()) > g.config.MaxFileSizeBytes {
+			g.log().Debugf("Skipping file %s: size %d bytes exceeds limit of %d bytes", path, entry.GetSize(), g.config.MaxFileSizeBytes)
+			continue
+		}
+		blobs = append(blobs, entry)
 	}
 
-	return nil, nil
+	files = append(files, g.downloadFiles(len(blobs), func(i int) (*File, error) {
+		content, _, err := g.client.Git.GetBlobRaw(ctx, owner, repo, blobs[i].GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to get content: %w", blobs[i].GetPath(), err)
+		}
+		return newGitHubFile(owner, repo, blobs[i].GetPath(), content, knowledgeID), nil
+	})...)
+
+	return files, nil
+}
+
+// inGitHubPaths reports whether path is one of, or inside one of, the configured subpaths
+func inGitHubPaths(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = strings.Trim(p, "/")
+		if p == "" || path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedGitHubDir reports whether dir, a directory relative to the repository root, or one of
+// its parents matches excludeDirs. Entries without a slash, like node_modules, match directory
+// names at any depth; entries with one, like docs/internal, match paths from the root. Both
+// may be path.Match patterns.
+func excludedGitHubDir(dir string, excludeDirs []string) bool {
+	if len(excludeDirs) == 0 {
+		return false
+	}
+	for d := strings.Trim(dir, "/"); d != "" && d != "."; d = path.Dir(d) {
+		for _, pattern := range excludeDirs {
+			pattern = strings.Trim(pattern, "/")
+			target := path.Base(d)
+			if strings.Contains(pattern, "/") {
+				target = d
+			}
+			if matched, _ := path.Match(pattern, target); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newGitHubFile creates the adapter file for content at path in a repository
+func newGitHubFile(owner, repo, path string, content []byte, knowledgeID string) *File {
+	return &File{
+		Path:        path,
+		Content:     content,
+		Hash:        fmt.Sprintf("%x", sha256.Sum256(content)),
+		Modified:    time.Now(), // GitHub API doesn't provide modification time for content
+		Size:        int64(len(content)),
+		Source:      fmt.Sprintf("%s/%s", owner, repo),
+		KnowledgeID: knowledgeID,
+	}
 }
 
 // getFileContent retrieves the actual content of a file
-func (g *GitHubAdapter) getFileContent(ctx context.Context, owner, repo string, content *github.RepositoryContent) ([]byte, error) {
+func (g *GitHubAdapter) getFileContent(ctx context.Context, owner, repo string, content *github.RepositoryContent, opts *github.RepositoryContentGetOptions) ([]byte, error) {
 	fileContent, err := content.GetContent()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get content: %w", err)
@@ -190,7 +547,19 @@ func (g *GitHubAdapter) getFileContent(ctx context.Context, owner, repo string,
 	// For larger files, we need to download them
 	url := content.GetDownloadURL()
 	if url == "" {
-		return nil, fmt.Errorf("no download URL available for file")
+		// Fall back to fetching the file itself at the requested ref
+		fileInfo, _, _, err := g.client.Repositories.GetContents(ctx, owner, repo, content.GetPath(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file contents: %w", err)
+		}
+		if fileInfo == nil {
+			return nil, fmt.Errorf("no download URL available for file")
+		}
+		fileContent, err := fileInfo.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get content: %w", err)
+		}
+		return []byte(fileContent), nil
 	}
 
 	resp, err := g.client.Client().Get(url)
@@ -199,9 +568,22 @@ func (g *GitHubAdapter) getFileContent(ctx context.Context, owner, repo string,
 	}
 	defer resp.Body.Close()
 
+	// Error pages must not be synced as the file's content
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
 	return io.ReadAll(resp.Body)
 }
 
+// contentOptions returns the GetContents options for a branch, or nil for the default branch
+func contentOptions(branch string) *github.RepositoryContentGetOptions {
+	if branch == "" {
+		return nil
+	}
+	return &github.RepositoryContentGetOptions{Ref: branch}
+}
+
 // isTextFile checks if a file is likely to be a text file
 func isTextFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -256,6 +638,12 @@ func isTextFile(filename string) bool {
 	return textExts[ext] || ext == ""
 }
 
+// FetchComplete reports whether the last fetch returned every file of the configured
+// repositories, so files deleted from a repository can be removed from OpenWebUI
+func (g *GitHubAdapter) FetchComplete() bool {
+	return !g.isIncomplete()
+}
+
 // GetLastSync returns the last sync timestamp
 func (g *GitHubAdapter) GetLastSync() time.Time {
 	return g.lastSync
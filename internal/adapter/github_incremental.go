@@ -0,0 +1,213 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// githubMaxCompareFiles is the number of files GitHub lists at most when comparing two
+// commits. A comparison listing this many may be missing changes, so the repository is
+// walked instead.
+const githubMaxCompareFiles = 300
+
+// githubRepoState is what an incremental fetch of a repository starts from: the commit its
+// files were last fetched at and those files without their content
+type githubRepoState struct {
+	commit string
+	files  map[string]*File // path -> file
+}
+
+// fetchRepository fetches the files of a repository. With incremental_sync, only the files
+// changed since the commit of the previous fetch are downloaded and the others are returned
+// as unchanged; the first fetch, and any fetch whose changes can't be listed, walks the whole
+// repository and records the commit it was fetched at.
+func (g *GitHubAdapter) fetchRepository(ctx context.Context, repo string, knowledgeID string) ([]*File, error) {
+	branch, paths := g.branches[repo], g.paths[repo]
+	if !g.config.IncrementalSync || g.config.FollowSubmodules {
+		return g.fetchRepositoryFiles(ctx, repo, branch, paths, knowledgeID)
+	}
+
+	head, err := g.headCommit(ctx, repo, branch)
+	if err != nil {
+		g.log().Warnf("Failed to get the latest commit of repository %s, fetching all files: %v", repo, err)
+		return g.fetchRepositoryFiles(ctx, repo, branch, paths, knowledgeID)
+	}
+
+	if files, ok := g.fetchRepositoryChanges(ctx, repo, head, knowledgeID); ok {
+		return files, nil
+	}
+
+	files, err := g.fetchRepositoryFiles(ctx, repo, branch, paths, knowledgeID)
+	if err != nil {
+		return nil, err
+	}
+	// A walk that skipped files must not become the base of the next comparison
+	if !g.isIncomplete() {
+		g.saveRepoState(repo, head, files)
+	}
+	return files, nil
+}
+
+// headCommit returns the SHA of the latest commit on a repository's branch, or on its default
+// branch when branch is empty
+func (g *GitHubAdapter) headCommit(ctx context.Context, repo, branch string) (string, error) {
+	owner, repoName, ok := strings.Cut(repo, "/")
+	if !ok {
+		return "", fmt.Errorf("invalid repository format, expected 'owner/repo'")
+	}
+	commits, _, err := g.client.Repositories.ListCommits(ctx, owner, repoName, &github.CommitsListOptions{
+		SHA:         branch,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list commits: %w", err)
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("repository has no commits")
+	}
+	return commits[0].GetSHA(), nil
+}
+
+// fetchRepositoryChanges compares head to the commit of the previous fetch and downloads only
+// the added, modified and renamed files. Files removed since are left out, so the sync manager
+// removes them. It returns false when the repository has to be walked instead: on the first
+// fetch, when the branch was rewritten or when there are too many changes to list.
+func (g *GitHubAdapter) fetchRepositoryChanges(ctx context.Context, repo, head string, knowledgeID string) ([]*File, bool) {
+	state := g.repoState(repo)
+	if state == nil {
+		g.log().Debugf("No previous fetch of repository %s, fetching all files", repo)
+		return nil, false
+	}
+
+	owner, repoName, _ := strings.Cut(repo, "/")
+	files := make(map[string]*File, len(state.files))
+	for filePath, file := range state.files {
+		files[filePath] = file
+	}
+
+	var changed []*github.CommitFile
+	if head != state.commit {
+		comparison, _, err := g.client.Repositories.CompareCommits(ctx, owner, repoName, state.commit, head, nil)
+		if err != nil {
+			g.log().Warnf("Failed to compare repository %s to the previous fetch, fetching all files: %v", repo, err)
+			return nil, false
+		}
+		if status := comparison.GetStatus(); status != "ahead" && status != "identical" {
+			g.log().Infof("Repository %s is %s of the previous fetch, fetching all files", repo, status)
+			return nil, false
+		}
+		if len(comparison.Files) >= githubMaxCompareFiles {
+			g.log().Infof("Repository %s has too many changes since the previous fetch to list, fetching all files", repo)
+			return nil, false
+		}
+
+		for _, file := range comparison.Files {
+			delete(files, file.GetFilename())
+			switch file.GetStatus() {
+			case "removed":
+				g.log().Debugf("File %s was removed from repository %s", file.GetFilename(), repo)
+				continue
+			case "renamed":
+				delete(files, file.GetPreviousFilename())
+			}
+			filePath := file.GetFilename()
+			if inGitHubPaths(filePath, g.paths[repo]) && !excludedGitHubDir(path.Dir(filePath), g.excludeDirs[repo]) && isTextFile(path.Base(filePath)) {
+				changed = append(changed, file)
+			}
+		}
+		g.log().Debugf("Repository %s has %d changed files since commit %s", repo, len(changed), state.commit)
+	}
+
+	opts := contentOptions(head)
+	fetched := g.downloadFiles(len(changed), func(i int) (*File, error) {
+		filePath := changed[i].GetFilename()
+		content, _, _, err := g.client.Repositories.GetContents(ctx, owner, repoName, filePath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to get contents: %w", filePath, err)
+		}
+		dir := path.Dir(filePath)
+		if dir == "." {
+			dir = ""
+		}
+		fileList, err := g.processContent(ctx, owner, repoName, content, dir, knowledgeID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		if len(fileList) == 0 {
+			return nil, nil
+		}
+		return fileList[0], nil
+	})
+
+	result := make([]*File, 0, len(files)+len(fetched))
+	for _, file := range files {
+		unchanged := *file
+		unchanged.Unchanged = true
+		result = append(result, &unchanged)
+	}
+	result = append(result, fetched...)
+
+	// Files that failed to download are compared again on the next fetch
+	if !g.isIncomplete() {
+		g.saveRepoState(repo, head, result)
+	}
+	return result, true
+}
+
+// repoState returns the state of a repository's previous fetch, or nil if there is none
+func (g *GitHubAdapter) repoState(repo string) *githubRepoState {
+	g.statesMu.Lock()
+	defer g.statesMu.Unlock()
+	return g.repoStates[repo]
+}
+
+// saveRepoState records the files a repository had at commit, without their content. Release,
+// issue and pull request files are fetched in full each time and aren't part of the state.
+func (g *GitHubAdapter) saveRepoState(repo, commit string, files []*File) {
+	state := &githubRepoState{commit: commit, files: make(map[string]*File, len(files))}
+	for _, file := range files {
+		stored := *file
+		stored.Content = nil
+		stored.Unchanged = false
+		state.files[file.Path] = &stored
+	}
+
+	g.statesMu.Lock()
+	defer g.statesMu.Unlock()
+	if g.repoStates == nil {
+		g.repoStates = make(map[string]*githubRepoState)
+	}
+	g.repoStates[repo] = state
+}
+
+// LoadContent downloads the content of a file returned as unchanged, at the commit of the
+// previous fetch, for the sync manager when it has no record of that version
+func (g *GitHubAdapter) LoadContent(ctx context.Context, file *File) error {
+	state := g.repoState(file.Source)
+	if state == nil {
+		return fmt.Errorf("no previous fetch of repository %s", file.Source)
+	}
+	owner, repoName, _ := strings.Cut(file.Source, "/")
+
+	opts := contentOptions(state.commit)
+	content, _, _, err := g.client.Repositories.GetContents(ctx, owner, repoName, file.Path, opts)
+	if err != nil {
+		return fmt.Errorf("failed to get contents: %w", err)
+	}
+	if content == nil {
+		return fmt.Errorf("%s is not a file", file.Path)
+	}
+	data, err := g.getFileContent(ctx, owner, repoName, content, opts)
+	if err != nil {
+		return err
+	}
+
+	loaded := newGitHubFile(owner, repoName, file.Path, data, file.KnowledgeID)
+	file.Content, file.Hash, file.Size = loaded.Content, loaded.Hash, loaded.Size
+	file.Unchanged = false
+	return nil
+}
@@ -0,0 +1,201 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestGitHubAdapter_FetchFiles_IncrementalSync(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string // status of the comparison to the previous fetch
+		walked    bool   // whether the second fetch walks the repository
+		unchanged []string
+		expected  map[string]string // path -> content of the files downloaded by the second fetch
+	}{
+		{
+			name:      "changed files only",
+			status:    "ahead",
+			unchanged: []string{"keep.md"},
+			expected:  map[string]string{"changed.md": "changed v2", "added.md": "added", "docs/renamed.md": "renamed"},
+		},
+		{
+			name:     "branch rewritten",
+			status:   "diverged",
+			walked:   true,
+			expected: map[string]string{"changed.md": "changed v2", "added.md": "added", "docs/renamed.md": "renamed", "keep.md": "keep"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			var mu sync.Mutex
+			head := "c1"
+			var requests []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+
+				file := func(name, filePath string) map[string]interface{} {
+					return map[string]interface{}{"type": "file", "name": name, "path": filePath, "size": 10, "download_url": serverURL + "/raw/" + head + "/" + filePath}
+				}
+				switch r.URL.Path {
+				case "/repos/owner/repo/commits":
+					json.NewEncoder(w).Encode([]map[string]interface{}{{"sha": head}})
+				case "/repos/owner/repo/compare/c1...c2":
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"status": tt.status,
+						"files": []map[string]interface{}{
+							{"filename": "changed.md", "status": "modified"},
+							{"filename": "removed.md", "status": "removed"},
+							{"filename": "added.md", "status": "added"},
+							{"filename": "docs/renamed.md", "previous_filename": "old.md", "status": "renamed"},
+							{"filename": "image.png", "status": "added"},
+						},
+					})
+				case "/repos/owner/repo/contents/":
+					if head == "c1" {
+						json.NewEncoder(w).Encode([]map[string]interface{}{file("keep.md", "keep.md"), file("changed.md", "changed.md"), file("removed.md", "removed.md"), file("old.md", "old.md")})
+					} else {
+						json.NewEncoder(w).Encode([]map[string]interface{}{file("keep.md", "keep.md"), file("changed.md", "changed.md"), file("added.md", "added.md"), {"type": "dir", "name": "docs", "path": "docs"}})
+					}
+				case "/repos/owner/repo/contents/docs":
+					json.NewEncoder(w).Encode([]map[string]interface{}{file("renamed.md", "docs/renamed.md")})
+				case "/repos/owner/repo/contents/changed.md", "/repos/owner/repo/contents/added.md", "/repos/owner/repo/contents/docs/renamed.md":
+					if ref := r.URL.Query().Get("ref"); ref != "c2" {
+						t.Errorf("Expected changed files to be fetched at the new commit, got ref %q", ref)
+					}
+					filePath := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/contents/")
+					json.NewEncoder(w).Encode(file(filePath[strings.LastIndex(filePath, "/")+1:], filePath))
+				case "/raw/c1/keep.md", "/raw/c2/keep.md":
+					w.Write([]byte("keep"))
+				case "/raw/c1/changed.md":
+					w.Write([]byte("changed v1"))
+				case "/raw/c2/changed.md":
+					w.Write([]byte("changed v2"))
+				case "/raw/c1/removed.md", "/raw/c1/old.md":
+					w.Write([]byte("old"))
+				case "/raw/c2/added.md":
+					w.Write([]byte("added"))
+				case "/raw/c2/docs/renamed.md":
+					w.Write([]byte("renamed"))
+				default:
+					t.Errorf("Unexpected request for %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings:        []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+				IncrementalSync: true,
+			})
+
+			// The first fetch walks the repository
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("First fetch: unexpected error: %v", err)
+			}
+			if len(files) != 4 {
+				t.Fatalf("First fetch: expected 4 files, got %d", len(files))
+			}
+
+			mu.Lock()
+			head = "c2"
+			requests = nil
+			mu.Unlock()
+
+			files, err = adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Second fetch: unexpected error: %v", err)
+			}
+			if !adapter.FetchComplete() {
+				t.Errorf("Expected the fetch to be complete, so removed files are deleted")
+			}
+
+			var unchanged []string
+			downloaded := make(map[string]string)
+			for _, file := range files {
+				if file.Unchanged {
+					unchanged = append(unchanged, file.Path)
+					if len(file.Content) != 0 || file.Hash == "" {
+						t.Errorf("Expected unchanged file %s to keep its hash without content", file.Path)
+					}
+					continue
+				}
+				downloaded[file.Path] = string(file.Content)
+			}
+			sort.Strings(unchanged)
+			if strings.Join(unchanged, ",") != strings.Join(tt.unchanged, ",") {
+				t.Errorf("Expected unchanged files %v, got %v", tt.unchanged, unchanged)
+			}
+			if len(downloaded) != len(tt.expected) {
+				t.Errorf("Expected downloaded files %v, got %v", tt.expected, downloaded)
+			}
+			for filePath, content := range tt.expected {
+				if downloaded[filePath] != content {
+					t.Errorf("Expected %s to contain %q, got %q", filePath, content, downloaded[filePath])
+				}
+			}
+
+			walked := false
+			for _, request := range requests {
+				if request == "/repos/owner/repo/contents/" {
+					walked = true
+				}
+			}
+			if walked != tt.walked {
+				t.Errorf("Expected repository walk %v, got %v (requests %v)", tt.walked, walked, requests)
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_LoadContent(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/contents/README.md":
+			if ref := r.URL.Query().Get("ref"); ref != "c1" {
+				t.Errorf("Expected the file to be loaded at the fetched commit, got ref %q", ref)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"type": "file", "name": "README.md", "path": "README.md", "size": 6, "download_url": serverURL + "/raw/README.md"})
+		case "/raw/README.md":
+			w.Write([]byte("# Repo"))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+	})
+	stored := newGitHubFile("owner", "repo", "README.md", []byte("# Repo"), "knowledge-id")
+	adapter.saveRepoState("owner/repo", "c1", []*File{stored})
+
+	file := *adapter.repoState("owner/repo").files["README.md"]
+	file.Unchanged = true
+	if err := adapter.LoadContent(context.Background(), &file); err != nil {
+		t.Fatalf("LoadContent() error = %v", err)
+	}
+	if string(file.Content) != "# Repo" || file.Hash != stored.Hash || file.Unchanged {
+		t.Errorf("Expected the loaded file to match the fetched one, got %+v", file)
+	}
+}
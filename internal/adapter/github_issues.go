@@ -0,0 +1,221 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// issueOptions selects the issues and pull requests synced for a repository
+type issueOptions struct {
+	issues       bool
+	pullRequests bool
+	state        string // open, closed or all; empty means all
+}
+
+// githubIssueState is an issue or pull request as rendered by the previous fetch, kept with
+// incremental_sync so an item that wasn't updated since isn't rendered again and only the
+// comments updated since are listed
+type githubIssueState struct {
+	updatedAt time.Time
+	comments  []*github.IssueComment
+	file      *File
+}
+
+// fetchIssues lists the issues and/or pull requests of a repository in the configured state
+// and returns one markdown file per item with its description and comments. With
+// incremental_sync, items whose updated_at didn't change since the previous fetch are
+// returned as rendered then.
+func (g *GitHubAdapter) fetchIssues(ctx context.Context, repo string, opts issueOptions, knowledgeID string) ([]*File, error) {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository format, expected 'owner/repo'")
+	}
+	owner, repoName := parts[0], parts[1]
+
+	state := opts.state
+	if state == "" {
+		state = "all"
+	}
+
+	// The issues API returns pull requests too, so a single listing covers both
+	listOpts := &github.IssueListByRepoOptions{
+		State:       state,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	previous := g.issueStates[repo]
+	current := make(map[int]*githubIssueState)
+
+	var files []*File
+	for {
+		issues, resp, err := g.client.Issues.ListByRepo(ctx, owner, repoName, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			isPullRequest := issue.IsPullRequest()
+			if (isPullRequest && !opts.pullRequests) || (!isPullRequest && !opts.issues) {
+				continue
+			}
+
+			state := previous[issue.GetNumber()]
+			if state != nil && issue.GetUpdatedAt().Time.Equal(state.updatedAt) {
+				current[issue.GetNumber()] = state
+				unchanged := *state.file
+				files = append(files, &unchanged)
+				continue
+			}
+
+			comments, err := g.fetchIssueComments(ctx, owner, repoName, issue, state)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list comments of #%d: %w", issue.GetNumber(), err)
+			}
+
+			dir := "issues"
+			if isPullRequest {
+				dir = "pulls"
+			}
+			path := filepath.Join(dir, fmt.Sprintf("%s-%d.md", repoName, issue.GetNumber()))
+
+			file := newGitHubFile(owner, repoName, path, issueMarkdown(issue, comments), knowledgeID)
+			if updated := issue.GetUpdatedAt(); !updated.IsZero() {
+				file.Modified = updated.Time
+			}
+			files = append(files, file)
+
+			if g.config.IncrementalSync {
+				stored := *file
+				current[issue.GetNumber()] = &githubIssueState{updatedAt: issue.GetUpdatedAt().Time, comments: comments, file: &stored}
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
+	// Items no longer listed are dropped from the state along with their files
+	if g.config.IncrementalSync {
+		if g.issueStates == nil {
+			g.issueStates = make(map[string]map[int]*githubIssueState)
+		}
+		g.issueStates[repo] = current
+	}
+	return files, nil
+}
+
+// fetchIssueComments lists the comments of an issue or pull request. Issues without comments
+// are not requested. Given the item's previous fetch, only the comments updated since its
+// previous updated_at are listed and merged into the previous comments; deleted comments
+// aren't listed, so when the merged count doesn't match the item's, every comment is listed.
+func (g *GitHubAdapter) fetchIssueComments(ctx context.Context, owner, repo string, issue *github.Issue, previous *githubIssueState) ([]*github.IssueComment, error) {
+	if issue.GetComments() == 0 {
+		return nil, nil
+	}
+
+	var comments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	if previous != nil {
+		since := previous.updatedAt
+		opts.Since = &since
+	}
+	for {
+		page, resp, err := g.client.Issues.ListComments(ctx, owner, repo, issue.GetNumber(), opts)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if previous == nil {
+		return comments, nil
+	}
+	merged := mergeIssueComments(previous.comments, comments)
+	if len(merged) != issue.GetComments() {
+		g.log().Debugf("Comments of #%d don't add up after listing the updated ones, listing all of them", issue.GetNumber())
+		return g.fetchIssueComments(ctx, owner, repo, issue, nil)
+	}
+	return merged, nil
+}
+
+// mergeIssueComments replaces previous comments by their updated version, adds new ones and
+// returns them in the order they were created
+func mergeIssueComments(previous, updated []*github.IssueComment) []*github.IssueComment {
+	byID := make(map[int64]*github.IssueComment, len(previous)+len(updated))
+	for _, comment := range previous {
+		byID[comment.GetID()] = comment
+	}
+	for _, comment := range updated {
+		byID[comment.GetID()] = comment
+	}
+
+	merged := make([]*github.IssueComment, 0, len(byID))
+	for _, comment := range byID {
+		merged = append(merged, comment)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if created, other := merged[i].GetCreatedAt(), merged[j].GetCreatedAt(); !created.Equal(other) {
+			return created.Before(other.Time)
+		}
+		return merged[i].GetID() < merged[j].GetID()
+	})
+	return merged
+}
+
+// issueMarkdown formats an issue or pull request with its metadata, description and comments
+func issueMarkdown(issue *github.Issue, comments []*github.IssueComment) []byte {
+	kind := "Issue"
+	if issue.IsPullRequest() {
+		kind = "Pull request"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s (#%d)\n\n", issue.GetTitle(), issue.GetNumber()))
+	sb.WriteString(fmt.Sprintf("- Type: %s\n", kind))
+	sb.WriteString(fmt.Sprintf("- State: %s\n", issue.GetState()))
+	if author := issue.GetUser().GetLogin(); author != "" {
+		sb.WriteString(fmt.Sprintf("- Author: %s\n", author))
+	}
+	if len(issue.Labels) > 0 {
+		labels := make([]string, 0, len(issue.Labels))
+		for _, label := range issue.Labels {
+			labels = append(labels, label.GetName())
+		}
+		sb.WriteString(fmt.Sprintf("- Labels: %s\n", strings.Join(labels, ", ")))
+	}
+	if created := issue.GetCreatedAt(); !created.IsZero() {
+		sb.WriteString(fmt.Sprintf("- Created: %s\n", created.Format(time.RFC3339)))
+	}
+	if closed := issue.GetClosedAt(); !closed.IsZero() {
+		sb.WriteString(fmt.Sprintf("- Closed: %s\n", closed.Format(time.RFC3339)))
+	}
+	if url := issue.GetHTMLURL(); url != "" {
+		sb.WriteString(fmt.Sprintf("- URL: %s\n", url))
+	}
+	if body := strings.TrimSpace(issue.GetBody()); body != "" {
+		sb.WriteString("\n" + body + "\n")
+	}
+
+	if len(comments) > 0 {
+		sb.WriteString("\n## Comments\n")
+		for _, comment := range comments {
+			sb.WriteString(fmt.Sprintf("\n### %s (%s)\n\n", comment.GetUser().GetLogin(), comment.GetCreatedAt().Format(time.RFC3339)))
+			sb.WriteString(strings.TrimSpace(comment.GetBody()) + "\n")
+		}
+	}
+
+	return []byte(sb.String())
+}
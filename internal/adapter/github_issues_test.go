@@ -0,0 +1,213 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestGitHubAdapter_FetchFiles_Issues(t *testing.T) {
+	tests := []struct {
+		name          string
+		mapping       config.RepositoryMapping
+		expectedState string
+		expected      []string
+	}{
+		{
+			name:          "issues only",
+			mapping:       config.RepositoryMapping{IncludeIssues: true},
+			expectedState: "all",
+			expected:      []string{"issues/repo-1.md", "issues/repo-3.md"},
+		},
+		{
+			name:          "pull requests only",
+			mapping:       config.RepositoryMapping{IncludePullRequests: true, IssueState: "closed"},
+			expectedState: "closed",
+			expected:      []string{"pulls/repo-2.md"},
+		},
+		{
+			name:          "issues and pull requests",
+			mapping:       config.RepositoryMapping{IncludeIssues: true, IncludePullRequests: true, IssueState: "open"},
+			expectedState: "open",
+			expected:      []string{"issues/repo-1.md", "pulls/repo-2.md", "issues/repo-3.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/repos/owner/repo/contents/":
+					json.NewEncoder(w).Encode([]map[string]interface{}{})
+				case "/repos/owner/repo/issues":
+					if state := r.URL.Query().Get("state"); state != tt.expectedState {
+						t.Errorf("Expected state %q, got %q", tt.expectedState, state)
+					}
+					// Two pages: an issue and a pull request, then another issue
+					if r.URL.Query().Get("page") == "2" {
+						json.NewEncoder(w).Encode([]map[string]interface{}{
+							{"number": 3, "title": "Second issue", "state": "open", "comments": 0},
+						})
+						return
+					}
+					w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/issues?page=2>; rel="next"`, serverURL))
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{
+							"number":     1,
+							"title":      "Decide on storage",
+							"state":      "open",
+							"body":       "Should we use Postgres?",
+							"user":       map[string]interface{}{"login": "alice"},
+							"labels":     []map[string]interface{}{{"name": "decision"}, {"name": "backend"}},
+							"comments":   2,
+							"html_url":   "https://github.com/owner/repo/issues/1",
+							"created_at": "2024-01-01T10:00:00Z",
+							"updated_at": "2024-01-05T10:00:00Z",
+						},
+						{
+							"number":       2,
+							"title":        "Add Postgres support",
+							"state":        "closed",
+							"comments":     0,
+							"pull_request": map[string]interface{}{"url": serverURL + "/repos/owner/repo/pulls/2"},
+						},
+					})
+				case "/repos/owner/repo/issues/1/comments":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"body": "Yes, Postgres.", "user": map[string]interface{}{"login": "bob"}, "created_at": "2024-01-02T10:00:00Z"},
+						{"body": "Agreed.", "user": map[string]interface{}{"login": "carol"}, "created_at": "2024-01-03T10:00:00Z"},
+					})
+				default:
+					t.Errorf("Unexpected request for %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			mapping := tt.mapping
+			mapping.Repository = "owner/repo"
+			mapping.KnowledgeID = "knowledge-id"
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{Mappings: []config.RepositoryMapping{mapping}})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var paths []string
+			byPath := make(map[string]*File)
+			for _, file := range files {
+				paths = append(paths, file.Path)
+				byPath[file.Path] = file
+				if file.KnowledgeID != "knowledge-id" {
+					t.Errorf("Expected %s to use the mapping's knowledge ID, got %q", file.Path, file.KnowledgeID)
+				}
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected files %v, got %v", tt.expected, paths)
+			}
+
+			issue := byPath["issues/repo-1.md"]
+			if issue == nil {
+				return
+			}
+			content := string(issue.Content)
+			for _, want := range []string{
+				"# Decide on storage (#1)",
+				"- Type: Issue",
+				"- Author: alice",
+				"- Labels: decision, backend",
+				"Should we use Postgres?",
+				"## Comments",
+				"### bob (2024-01-02T10:00:00Z)",
+				"Agreed.",
+			} {
+				if !strings.Contains(content, want) {
+					t.Errorf("Expected issue to contain %q, got:\n%s", want, content)
+				}
+			}
+			if issue.Modified.Day() != 5 {
+				t.Errorf("Expected the issue update time as modification time, got %v", issue.Modified)
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_FetchIssues_Incremental(t *testing.T) {
+	// The first fetch lists every comment; by the second, issue 1 got a new comment while
+	// issue 2 is unchanged
+	fetch := 1
+	commentRequests := make(map[string][]string) // path -> since parameter of each request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues":
+			first := map[string]interface{}{"number": 1, "title": "Decide on storage", "state": "open", "comments": 1, "updated_at": "2024-01-05T10:00:00Z"}
+			if fetch == 2 {
+				first["comments"] = 2
+				first["updated_at"] = "2024-01-06T10:00:00Z"
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				first,
+				{"number": 2, "title": "Unchanged", "state": "open", "comments": 1, "updated_at": "2024-01-04T10:00:00Z"},
+			})
+		case "/repos/owner/repo/issues/1/comments", "/repos/owner/repo/issues/2/comments":
+			since := r.URL.Query().Get("since")
+			commentRequests[r.URL.Path] = append(commentRequests[r.URL.Path], since)
+			if since != "" {
+				json.NewEncoder(w).Encode([]map[string]interface{}{
+					{"id": 12, "body": "Agreed.", "user": map[string]interface{}{"login": "carol"}, "created_at": "2024-01-06T10:00:00Z"},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 11, "body": "Yes, Postgres.", "user": map[string]interface{}{"login": "bob"}, "created_at": "2024-01-02T10:00:00Z"},
+			})
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		IncrementalSync: true,
+		Mappings:        []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id", IncludeIssues: true}},
+	})
+	opts := issueOptions{issues: true, state: "all"}
+
+	if _, err := adapter.fetchIssues(context.Background(), "owner/repo", opts, "knowledge-id"); err != nil {
+		t.Fatalf("Unexpected error on the first fetch: %v", err)
+	}
+	fetch = 2
+	files, err := adapter.fetchIssues(context.Background(), "owner/repo", opts, "knowledge-id")
+	if err != nil {
+		t.Fatalf("Unexpected error on the second fetch: %v", err)
+	}
+
+	if since := commentRequests["/repos/owner/repo/issues/1/comments"]; len(since) != 2 || since[0] != "" || since[1] != "2024-01-05T10:00:00Z" {
+		t.Errorf("Expected the updated issue's comments to be listed since its previous update, got since parameters %q", since)
+	}
+	if requests := commentRequests["/repos/owner/repo/issues/2/comments"]; len(requests) != 1 {
+		t.Errorf("Expected the unchanged issue's comments to be listed once, got %d requests", len(requests))
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	content := string(files[0].Content)
+	if !strings.Contains(content, "Yes, Postgres.") || !strings.Contains(content, "Agreed.") ||
+		strings.Index(content, "Yes, Postgres.") > strings.Index(content, "Agreed.") {
+		t.Errorf("Expected the previous and the new comment in order, got:\n%s", content)
+	}
+	if files[1].Path != "issues/repo-2.md" || !strings.Contains(string(files[1].Content), "Yes, Postgres.") {
+		t.Errorf("Expected the unchanged issue as previously rendered, got %s:\n%s", files[1].Path, files[1].Content)
+	}
+}
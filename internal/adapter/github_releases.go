@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// fetchReleases lists the published releases of a repository and returns one markdown file
+// per release with its notes, plus its text assets when includeAssets is set. Draft
+// releases are skipped and a repository without releases yields no files.
+func (g *GitHubAdapter) fetchReleases(ctx context.Context, repo string, includeAssets bool, knowledgeID string) ([]*File, error) {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository format, expected 'owner/repo'")
+	}
+	owner, repoName := parts[0], parts[1]
+
+	var files []*File
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := g.client.Repositories.ListReleases(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+
+		for _, release := range releases {
+			if release.GetDraft() || release.GetTagName() == "" {
+				continue
+			}
+
+			file := newGitHubFile(owner, repoName, releasePath(repoName, release.GetTagName())+".md", releaseMarkdown(release), knowledgeID)
+			if published := release.GetPublishedAt(); !published.IsZero() {
+				file.Modified = published.Time
+			}
+			files = append(files, file)
+
+			if includeAssets {
+				files = append(files, g.fetchReleaseAssets(ctx, owner, repoName, release, knowledgeID)...)
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+// fetchReleaseAssets downloads the text assets of a release. Assets that can't be downloaded
+// are skipped so the release notes are still synced.
+func (g *GitHubAdapter) fetchReleaseAssets(ctx context.Context, owner, repo string, release *github.RepositoryRelease, knowledgeID string) []*File {
+	var files []*File
+	for _, asset := range release.Assets {
+		name := asset.GetName()
+		// Extensionless assets are usually binaries, unlike extensionless repository files
+		if filepath.Ext(name) == "" || !isTextFile(name) {
+			continue
+		}
+		if g.config.MaxFileSizeBytes > 0 && int64(asset.GetSize()) > g.config.MaxFileSizeBytes {
+			g.log().Debugf("Skipping release asset %s: size %d bytes exceeds limit of %d bytes", name, asset.GetSize(), g.config.MaxFileSizeBytes)
+			continue
+		}
+
+		content, err := g.downloadReleaseAsset(ctx, owner, repo, asset.GetID())
+		if err != nil {
+			g.log().Debugf("Skipping release asset %s: %v", name, err)
+			continue
+		}
+
+		file := newGitHubFile(owner, repo, filepath.Join(releasePath(repo, release.GetTagName()), name), content, knowledgeID)
+		if updated := asset.GetUpdatedAt(); !updated.IsZero() {
+			file.Modified = updated.Time
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// downloadReleaseAsset downloads the raw content of a release asset, following the redirect
+// to GitHub's asset storage
+func (g *GitHubAdapter) downloadReleaseAsset(ctx context.Context, owner, repo string, id int64) ([]byte, error) {
+	rc, _, err := g.client.Repositories.DownloadReleaseAsset(ctx, owner, repo, id, g.client.Client())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download release asset: %w", err)
+	}
+	if rc == nil {
+		return nil, fmt.Errorf("no content returned for release asset %d", id)
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// releasePath returns the path, without extension, of a release's files. The repository
+// name is included so releases with the same tag in different repositories don't collide.
+func releasePath(repo, tag string) string {
+	return filepath.Join("releases", repo+"-"+strings.ReplaceAll(tag, "/", "-"))
+}
+
+// releaseMarkdown formats a release's name, metadata and notes as markdown
+func releaseMarkdown(release *github.RepositoryRelease) []byte {
+	title := release.GetName()
+	if title == "" {
+		title = release.GetTagName()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
+	sb.WriteString(fmt.Sprintf("- Tag: %s\n", release.GetTagName()))
+	if published := release.GetPublishedAt(); !published.IsZero() {
+		sb.WriteString(fmt.Sprintf("- Published: %s\n", published.Format(time.RFC3339)))
+	}
+	if release.GetPrerelease() {
+		sb.WriteString("- Pre-release: yes\n")
+	}
+	if url := release.GetHTMLURL(); url != "" {
+		sb.WriteString(fmt.Sprintf("- URL: %s\n", url))
+	}
+	if body := strings.TrimSpace(release.GetBody()); body != "" {
+		sb.WriteString("\n" + body + "\n")
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestGitHubAdapter_FetchFiles_Releases(t *testing.T) {
+	tests := []struct {
+		name          string
+		releaseAssets bool
+		expected      []string
+	}{
+		{
+			name:     "release notes only",
+			expected: []string{"README.md", "releases/repo-v1.0.0.md"},
+		},
+		{
+			name:          "with text assets",
+			releaseAssets: true,
+			expected:      []string{"README.md", "releases/repo-v1.0.0.md", "releases/repo-v1.0.0/CHANGELOG.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/repos/owner/repo/contents/":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "README.md", "path": "README.md", "size": 8, "download_url": serverURL + "/raw/README.md"},
+					})
+				case "/raw/README.md":
+					w.Write([]byte("# Readme"))
+				case "/repos/owner/repo/releases":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{
+							"tag_name":     "v1.0.0",
+							"name":         "First release",
+							"body":         "Added the thing.",
+							"html_url":     "https://github.com/owner/repo/releases/tag/v1.0.0",
+							"published_at": "2024-03-01T12:00:00Z",
+							"assets": []map[string]interface{}{
+								{"id": 1, "name": "CHANGELOG.txt", "size": 9},
+								{"id": 2, "name": "app-linux-amd64", "size": 1024},
+								{"id": 3, "name": "app.zip", "size": 2048},
+							},
+						},
+						{"tag_name": "v2.0.0-draft", "draft": true, "body": "Not yet"},
+					})
+				case "/repos/owner/repo/releases/assets/1":
+					if r.Header.Get("Accept") != "application/octet-stream" {
+						t.Errorf("Expected asset to be requested as octet-stream, got %q", r.Header.Get("Accept"))
+					}
+					w.Write([]byte("- a thing"))
+				default:
+					t.Errorf("Unexpected request for %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings: []config.RepositoryMapping{
+					{Repository: "owner/repo", KnowledgeID: "knowledge-id", IncludeReleases: true, ReleaseAssets: tt.releaseAssets},
+				},
+			})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			byPath := make(map[string]*File)
+			for _, file := range files {
+				byPath[file.Path] = file
+			}
+			if len(byPath) != len(tt.expected) {
+				t.Errorf("Expected files %v, got %d files", tt.expected, len(byPath))
+			}
+			for _, path := range tt.expected {
+				file, ok := byPath[path]
+				if !ok {
+					t.Errorf("Expected file %s", path)
+					continue
+				}
+				if file.KnowledgeID != "knowledge-id" {
+					t.Errorf("Expected %s to use the mapping's knowledge ID, got %q", path, file.KnowledgeID)
+				}
+			}
+
+			notes := byPath["releases/repo-v1.0.0.md"]
+			if notes == nil {
+				return
+			}
+			content := string(notes.Content)
+			for _, want := range []string{"# First release", "- Tag: v1.0.0", "- Published: 2024-03-01T12:00:00Z", "Added the thing."} {
+				if !strings.Contains(content, want) {
+					t.Errorf("Expected release notes to contain %q, got %q", want, content)
+				}
+			}
+			if notes.Modified.Year() != 2024 {
+				t.Errorf("Expected the release publish time as modification time, got %v", notes.Modified)
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_NoReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/contents/":
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		case "/repos/owner/repo/releases":
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{
+			{Repository: "owner/repo", KnowledgeID: "knowledge-id", IncludeReleases: true},
+		},
+	})
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no files, got %d", len(files))
+	}
+}
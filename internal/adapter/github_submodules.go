@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// isSubmodule reports whether a content item is a git submodule. Directory listings report
+// submodules as files for backwards compatibility; their git URL points to a tree of the
+// submodule's repository instead of a blob.
+func isSubmodule(content *github.RepositoryContent) bool {
+	return content.GetType() == "submodule" || content.GetSubmoduleGitURL() != "" ||
+		(content.GetType() == "file" && strings.Contains(content.GetGitURL(), "/git/trees/"))
+}
+
+// fetchSubmodule fetches the files of the submodule at dir, at the commit the parent
+// repository pins. The files keep the parent repository as their source and are placed below
+// the submodule's directory, as they appear in a checkout.
+func (g *GitHubAdapter) fetchSubmodule(ctx context.Context, owner, repo, dir string, content *github.RepositoryContent, knowledgeID string, opts *github.RepositoryContentGetOptions) ([]*File, error) {
+	gitURL, sha := content.GetSubmoduleGitURL(), content.GetSHA()
+	if gitURL == "" {
+		// Directory listings and trees don't include the submodule's URL
+		submodule, _, _, err := g.client.Repositories.GetContents(ctx, owner, repo, content.GetPath(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get submodule: %w", err)
+		}
+		if submodule == nil || submodule.GetSubmoduleGitURL() == "" {
+			return nil, fmt.Errorf("no submodule URL available")
+		}
+		gitURL, sha = submodule.GetSubmoduleGitURL(), submodule.GetSHA()
+	}
+
+	subOwner, subRepo, err := parseSubmoduleURL(gitURL, owner, g.enterpriseHost())
+	if err != nil {
+		return nil, err
+	}
+	g.log().Debugf("Following submodule %s in %s/%s to %s/%s at %s", dir, owner, repo, subOwner, subRepo, sha)
+
+	files, err := g.fetchRepositoryFiles(ctx, subOwner+"/"+subRepo, sha, nil, knowledgeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch submodule %s/%s: %w", subOwner, subRepo, err)
+	}
+	for _, file := range files {
+		file.Path = filepath.Join(dir, file.Path)
+		file.Source = fmt.Sprintf("%s/%s", owner, repo)
+	}
+	return files, nil
+}
+
+// enterpriseHost returns the host of the configured GitHub Enterprise Server, or "" for public GitHub
+func (g *GitHubAdapter) enterpriseHost() string {
+	if g.config.BaseURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(g.config.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// parseSubmoduleURL returns the owner and name of the repository a submodule URL points to,
+// such as https://github.com/owner/repo.git, git@github.com:owner/repo.git or a URL relative to
+// the parent repository like ../repo.git. Submodules hosted anywhere but github.com or the
+// GitHub Enterprise host can't be fetched with the adapter's client and are rejected.
+func parseSubmoduleURL(rawURL, parentOwner, enterpriseHost string) (owner, repo string, err error) {
+	repoPath, host := rawURL, ""
+	switch {
+	case strings.HasPrefix(rawURL, "../"):
+		repoPath = parentOwner + "/" + strings.TrimPrefix(rawURL, "../")
+	case strings.Contains(rawURL, "://"):
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid submodule URL %q: %w", rawURL, err)
+		}
+		repoPath, host = parsed.Path, parsed.Hostname()
+	default:
+		// scp-like syntax: git@github.com:owner/repo.git
+		before, after, found := strings.Cut(rawURL, ":")
+		if !found {
+			return "", "", fmt.Errorf("unsupported submodule URL %q", rawURL)
+		}
+		repoPath = after
+		if _, userHost, hasUser := strings.Cut(before, "@"); hasUser {
+			before = userHost
+		}
+		host = before
+	}
+	if host != "" && !strings.EqualFold(host, "github.com") && (enterpriseHost == "" || !strings.EqualFold(host, enterpriseHost)) {
+		return "", "", fmt.Errorf("submodule URL %q is not hosted on GitHub", rawURL)
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(repoPath, ".git"), "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" || parts[len(parts)-2] == ".." {
+		return "", "", fmt.Errorf("unsupported submodule URL %q", rawURL)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
@@ -0,0 +1,143 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestGitHubAdapter_FetchFiles_Submodule(t *testing.T) {
+	tests := []struct {
+		name             string
+		followSubmodules bool
+		expected         map[string]string // path -> content
+	}{
+		{
+			name:     "skipped by default",
+			expected: map[string]string{"README.md": "# Repo"},
+		},
+		{
+			name:             "followed at the pinned commit",
+			followSubmodules: true,
+			expected:         map[string]string{"README.md": "# Repo", "vendor/lib/docs.md": "# Lib"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			submoduleFetched := false
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/repos/owner/repo/contents/":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "README.md", "path": "README.md", "size": 6, "download_url": serverURL + "/raw/README.md"},
+						{"type": "dir", "name": "vendor", "path": "vendor"},
+					})
+				case "/repos/owner/repo/contents/vendor":
+					// Directory listings report submodules as files without a download URL
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "lib", "path": "vendor/lib", "size": 0, "sha": "lib-sha",
+							"git_url": serverURL + "/repos/owner/lib/git/trees/lib-sha"},
+					})
+				case "/repos/owner/repo/contents/vendor/lib":
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"type": "submodule", "name": "lib", "path": "vendor/lib", "sha": "lib-sha",
+						"submodule_git_url": "git@github.com:owner/lib.git",
+					})
+				case "/repos/owner/lib/contents/":
+					submoduleFetched = true
+					if ref := r.URL.Query().Get("ref"); ref != "lib-sha" {
+						t.Errorf("Expected the submodule to be fetched at its pinned commit, got ref %q", ref)
+					}
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "docs.md", "path": "docs.md", "size": 5, "download_url": serverURL + "/raw/docs.md"},
+					})
+				case "/raw/README.md":
+					w.Write([]byte("# Repo"))
+				case "/raw/docs.md":
+					w.Write([]byte("# Lib"))
+				default:
+					t.Errorf("Unexpected request for %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings:         []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+				FollowSubmodules: tt.followSubmodules,
+			})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !adapter.FetchComplete() {
+				t.Errorf("Expected the fetch to be complete")
+			}
+			if submoduleFetched != tt.followSubmodules {
+				t.Errorf("Expected submodule fetched = %v, got %v", tt.followSubmodules, submoduleFetched)
+			}
+
+			if len(files) != len(tt.expected) {
+				t.Fatalf("Expected %d files, got %v", len(tt.expected), files)
+			}
+			for _, file := range files {
+				if content, ok := tt.expected[file.Path]; !ok || string(file.Content) != content {
+					t.Errorf("Unexpected file %s with content %q", file.Path, file.Content)
+				}
+				if file.Source != "owner/repo" {
+					t.Errorf("Expected %s to keep the parent repository as source, got %s", file.Path, file.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSubmoduleURL(t *testing.T) {
+	tests := []struct {
+		url            string
+		enterpriseHost string
+		owner          string
+		repo           string
+		expectErr      bool
+	}{
+		{url: "https://github.com/owner/lib.git", owner: "owner", repo: "lib"},
+		{url: "https://github.example.com/team/lib", enterpriseHost: "github.example.com", owner: "team", repo: "lib"},
+		{url: "git@github.com:owner/lib.git", owner: "owner", repo: "lib"},
+		{url: "git@github.example.com:team/lib.git", enterpriseHost: "github.example.com", owner: "team", repo: "lib"},
+		{url: "../lib.git", owner: "parent", repo: "lib"},
+		{url: "https://github.com/lib.git", expectErr: true},
+		{url: "../../lib.git", expectErr: true},
+		{url: "https://gitlab.com/owner/lib.git", expectErr: true},
+		{url: "git@bitbucket.org:owner/lib.git", expectErr: true},
+		{url: "https://github.example.com/team/lib", expectErr: true},
+		{url: "https://gitlab.com/owner/lib.git", enterpriseHost: "github.example.com", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, err := parseSubmoduleURL(tt.url, "parent", tt.enterpriseHost)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %s/%s", owner, repo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if owner != tt.owner || repo != tt.repo {
+				t.Errorf("parseSubmoduleURL(%q) = %s/%s, want %s/%s", tt.url, owner, repo, tt.owner, tt.repo)
+			}
+		})
+	}
+}
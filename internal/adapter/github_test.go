@@ -2,9 +2,19 @@ package adapter
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-github/v56/github"
 	"github.com/openwebui-content-sync/internal/config"
 )
 
@@ -91,6 +101,75 @@ func TestNewGitHubAdapter(t *testing.T) {
 	}
 }
 
+func TestNewGitHubAdapter_EnterpriseURL(t *testing.T) {
+	tests := []struct {
+		name              string
+		baseURL           string
+		uploadURL         string
+		expectError       bool
+		expectedBaseURL   string
+		expectedUploadURL string
+	}{
+		{
+			name:              "public GitHub by default",
+			expectedBaseURL:   "https://api.github.com/",
+			expectedUploadURL: "https://uploads.github.com/",
+		},
+		{
+			name:              "enterprise host",
+			baseURL:           "https://github.example.com",
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:              "enterprise API path",
+			baseURL:           "https://github.example.com/api/v3/",
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedUploadURL: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:              "explicit upload URL",
+			baseURL:           "https://github.example.com/api/v3",
+			uploadURL:         "https://uploads.github.example.com/api/uploads/",
+			expectedBaseURL:   "https://github.example.com/api/v3/",
+			expectedUploadURL: "https://uploads.github.example.com/api/uploads/",
+		},
+		{
+			name:        "base URL without scheme",
+			baseURL:     "github.example.com",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, err := NewGitHubAdapter(config.GitHubConfig{
+				Token:     "test-token",
+				BaseURL:   tt.baseURL,
+				UploadURL: tt.uploadURL,
+				Mappings: []config.RepositoryMapping{
+					{Repository: "owner/repo", KnowledgeID: "knowledge-id"},
+				},
+			})
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := adapter.client.BaseURL.String(); got != tt.expectedBaseURL {
+				t.Errorf("Expected base URL %s, got %s", tt.expectedBaseURL, got)
+			}
+			if got := adapter.client.UploadURL.String(); got != tt.expectedUploadURL {
+				t.Errorf("Expected upload URL %s, got %s", tt.expectedUploadURL, got)
+			}
+		})
+	}
+}
+
 func TestIsTextFile(t *testing.T) {
 	tests := []struct {
 		filename string
@@ -180,3 +259,491 @@ func TestFile_String(t *testing.T) {
 		t.Errorf("Expected source 'github', got '%s'", file.Source)
 	}
 }
+
+// newTestGitHubAdapter creates a GitHub adapter whose client talks to the given test server
+func newTestGitHubAdapter(t *testing.T, server *httptest.Server, cfg config.GitHubConfig) *GitHubAdapter {
+	t.Helper()
+
+	cfg.Token = "test-token"
+	adapter, err := NewGitHubAdapter(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	client := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+	adapter.client = client
+
+	return adapter
+}
+
+func TestGitHubAdapter_FetchFiles_Branch(t *testing.T) {
+	tests := []struct {
+		name        string
+		branch      string
+		expectedRef string
+	}{
+		{name: "configured branch", branch: "docs", expectedRef: "docs"},
+		{name: "default branch", branch: "", expectedRef: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var refs []string
+			var serverURL string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/contents"):
+					mu.Lock()
+					_, hasRef := r.URL.Query()["ref"]
+					if hasRef {
+						refs = append(refs, r.URL.Query().Get("ref"))
+					} else {
+						refs = append(refs, "")
+					}
+					mu.Unlock()
+
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{
+							"type":         "file",
+							"name":         "README.md",
+							"path":         "README.md",
+							"size":         6,
+							"download_url": serverURL + "/raw/README.md",
+						},
+					})
+				case r.URL.Path == "/raw/README.md":
+					w.Write([]byte("# Docs"))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings: []config.RepositoryMapping{
+					{Repository: "owner/repo", KnowledgeID: "knowledge-id", Branch: tt.branch},
+				},
+			})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(files))
+			}
+			if string(files[0].Content) != "# Docs" {
+				t.Errorf("Expected content '# Docs', got '%s'", string(files[0].Content))
+			}
+
+			if len(refs) == 0 {
+				t.Fatalf("Expected contents API to be called")
+			}
+			for _, ref := range refs {
+				if ref != tt.expectedRef {
+					t.Errorf("Expected ref '%s', got '%s'", tt.expectedRef, ref)
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_processContent_SkipsOversizedFile(t *testing.T) {
+	downloaded := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloaded = true
+		w.Write([]byte("large content"))
+	}))
+	defer server.Close()
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{
+			{Repository: "owner/repo", KnowledgeID: "knowledge-id"},
+		},
+		MaxFileSizeBytes: 1024,
+	})
+
+	content := &github.RepositoryContent{
+		Type:        github.String("file"),
+		Name:        github.String("CHANGELOG.md"),
+		Path:        github.String("CHANGELOG.md"),
+		Size:        github.Int(5 * 1024 * 1024),
+		DownloadURL: github.String(server.URL + "/raw/CHANGELOG.md"),
+	}
+
+	files, err := adapter.processContent(context.Background(), "owner", "repo", content, "", "knowledge-id", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected oversized file to be skipped, got %d files", len(files))
+	}
+	if downloaded {
+		t.Errorf("Expected no download attempt for oversized file")
+	}
+}
+
+func TestGitHubAdapter_processContent_NoSizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("large content"))
+	}))
+	defer server.Close()
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{
+			{Repository: "owner/repo", KnowledgeID: "knowledge-id"},
+		},
+	})
+
+	content := &github.RepositoryContent{
+		Type:        github.String("file"),
+		Name:        github.String("CHANGELOG.md"),
+		Path:        github.String("CHANGELOG.md"),
+		Size:        github.Int(5 * 1024 * 1024),
+		DownloadURL: github.String(server.URL + "/raw/CHANGELOG.md"),
+	}
+
+	files, err := adapter.processContent(context.Background(), "owner", "repo", content, "", "knowledge-id", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected file to be synced without a size limit, got %d files", len(files))
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_Paths(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	var serverURL string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/contents/docs":
+			mu.Lock()
+			requested = append(requested, "docs")
+			mu.Unlock()
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"type": "file", "name": "guide.md", "path": "docs/guide.md", "size": 7, "download_url": serverURL + "/raw/docs/guide.md"},
+			})
+		case "/repos/owner/repo/contents/README.md":
+			mu.Lock()
+			requested = append(requested, "README.md")
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"type": "file", "name": "README.md", "path": "README.md", "size": 8, "download_url": serverURL + "/raw/README.md",
+			})
+		case "/raw/docs/guide.md":
+			w.Write([]byte("# Guide"))
+		case "/raw/README.md":
+			w.Write([]byte("# Readme"))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{
+			{Repository: "owner/repo", KnowledgeID: "knowledge-id", Paths: []string{"/docs/", "README.md"}},
+		},
+	})
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(requested) != 2 {
+		t.Errorf("Expected only the configured paths to be listed, got %v", requested)
+	}
+	paths := make(map[string]string)
+	for _, file := range files {
+		paths[file.Path] = string(file.Content)
+	}
+	if len(paths) != 2 || paths["docs/guide.md"] != "# Guide" || paths["README.md"] != "# Readme" {
+		t.Errorf("Expected docs/guide.md and README.md, got %v", paths)
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_TreeAPI(t *testing.T) {
+	var mu sync.Mutex
+	var blobs []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/repo/git/trees/HEAD":
+			if r.URL.Query().Get("recursive") != "1" {
+				t.Errorf("Expected a recursive tree request, got %q", r.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"sha": "tree-sha",
+				"tree": []map[string]interface{}{
+					{"path": "docs", "type": "tree", "sha": "docs-sha"},
+					{"path": "docs/guide.md", "type": "blob", "sha": "guide-sha", "size": 7},
+					{"path": "docs/logo.png", "type": "blob", "sha": "logo-sha", "size": 100},
+					{"path": "docs/huge.md", "type": "blob", "sha": "huge-sha", "size": 5 * 1024 * 1024},
+					{"path": "src/main.go", "type": "blob", "sha": "main-sha", "size": 12},
+				},
+				"truncated": false,
+			})
+		case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+			sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/")
+			mu.Lock()
+			blobs = append(blobs, sha)
+			mu.Unlock()
+			w.Write([]byte("content of " + sha))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings: []config.RepositoryMapping{
+			{Repository: "owner/repo", KnowledgeID: "knowledge-id", Paths: []string{"docs"}},
+		},
+		MaxFileSizeBytes: 1024,
+		UseTreeAPI:       true,
+	})
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(files) != 1 || files[0].Path != "docs/guide.md" || string(files[0].Content) != "content of guide-sha" {
+		t.Fatalf("Expected only docs/guide.md, got %v", files)
+	}
+	if len(blobs) != 1 || blobs[0] != "guide-sha" {
+		t.Errorf("Expected only the filtered text file to be downloaded, got %v", blobs)
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_DownloadConcurrency(t *testing.T) {
+	const fileCount, concurrency = 12, 3
+
+	for _, useTreeAPI := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tree API %v", useTreeAPI), func(t *testing.T) {
+			var mu sync.Mutex
+			inFlight, maxInFlight := 0, 0
+			var serverURL string
+
+			// download tracks the parallel downloads, holding each long enough for others to start
+			download := func(w http.ResponseWriter, name string) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+				if name == "broken.md" {
+					w.WriteHeader(http.StatusInternalServerError)
+				} else {
+					w.Write([]byte("content of " + name))
+				}
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}
+
+			names := []string{"broken.md"}
+			for i := 0; i < fileCount; i++ {
+				names = append(names, fmt.Sprintf("doc-%d.md", i))
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/repos/owner/repo/git/trees/HEAD":
+					var entries []map[string]interface{}
+					for _, name := range names {
+						entries = append(entries, map[string]interface{}{"path": name, "type": "blob", "sha": name, "size": 10})
+					}
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(map[string]interface{}{"sha": "tree-sha", "tree": entries, "truncated": false})
+				case r.URL.Path == "/repos/owner/repo/contents/":
+					var contents []map[string]interface{}
+					for _, name := range names {
+						contents = append(contents, map[string]interface{}{"type": "file", "name": name, "path": name, "size": 10, "download_url": serverURL + "/raw/" + name})
+					}
+					w.Header().Set("Content-Type", "application/json")
+					json.NewEncoder(w).Encode(contents)
+				case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+					download(w, strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"))
+				case strings.HasPrefix(r.URL.Path, "/raw/"):
+					download(w, strings.TrimPrefix(r.URL.Path, "/raw/"))
+				default:
+					t.Errorf("Unexpected request for %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings:            []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+				UseTreeAPI:          useTreeAPI,
+				DownloadConcurrency: concurrency,
+			})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if maxInFlight > concurrency {
+				t.Errorf("Expected at most %d parallel downloads, got %d", concurrency, maxInFlight)
+			}
+			if maxInFlight < 2 {
+				t.Errorf("Expected downloads to run in parallel, got at most %d at once", maxInFlight)
+			}
+
+			var paths []string
+			for _, file := range files {
+				if string(file.Content) != "content of "+file.Path {
+					t.Errorf("Unexpected content %q for %s", file.Content, file.Path)
+				}
+				paths = append(paths, file.Path)
+			}
+			sort.Strings(paths)
+			expected := append([]string(nil), names[1:]...)
+			sort.Strings(expected)
+			if !reflect.DeepEqual(paths, expected) {
+				t.Errorf("Expected every file but the broken one, got %v", paths)
+			}
+			if adapter.FetchComplete() {
+				t.Errorf("Expected the failed download to mark the fetch incomplete")
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_ExcludeDirs(t *testing.T) {
+	for _, useTreeAPI := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tree API %v", useTreeAPI), func(t *testing.T) {
+			var mu sync.Mutex
+			var requested []string
+			var serverURL string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requested = append(requested, r.URL.Path)
+				mu.Unlock()
+
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/repos/owner/repo/git/trees/HEAD":
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"sha": "tree-sha",
+						"tree": []map[string]interface{}{
+							{"path": "README.md", "type": "blob", "sha": "readme-sha", "size": 8},
+							{"path": "node_modules/lib/README.md", "type": "blob", "sha": "lib-sha", "size": 5},
+							{"path": "docs/guide.md", "type": "blob", "sha": "guide-sha", "size": 7},
+							{"path": "docs/internal/secret.md", "type": "blob", "sha": "secret-sha", "size": 6},
+							{"path": "packages/app/node_modules/dep.md", "type": "blob", "sha": "dep-sha", "size": 3},
+						},
+					})
+				case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/"):
+					w.Write([]byte("content of " + strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/blobs/")))
+				case r.URL.Path == "/repos/owner/repo/contents/":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "README.md", "path": "README.md", "size": 8, "download_url": serverURL + "/raw/README.md"},
+						{"type": "dir", "name": "node_modules", "path": "node_modules"},
+						{"type": "dir", "name": "docs", "path": "docs"},
+					})
+				case r.URL.Path == "/repos/owner/repo/contents/docs":
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						{"type": "file", "name": "guide.md", "path": "docs/guide.md", "size": 7, "download_url": serverURL + "/raw/docs/guide.md"},
+						{"type": "dir", "name": "internal", "path": "docs/internal"},
+					})
+				case strings.HasPrefix(r.URL.Path, "/raw/"):
+					w.Write([]byte("content of " + strings.TrimPrefix(r.URL.Path, "/raw/")))
+				default:
+					t.Errorf("Unexpected request for excluded or unknown path %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+				Mappings: []config.RepositoryMapping{
+					{Repository: "owner/repo", KnowledgeID: "knowledge-id", ExcludeDirs: []string{"node_modules/", "docs/internal"}},
+				},
+				UseTreeAPI: useTreeAPI,
+			})
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+			}
+			sort.Strings(paths)
+			if expected := []string{"README.md", "docs/guide.md"}; !reflect.DeepEqual(paths, expected) {
+				t.Errorf("Expected %v, got %v", expected, paths)
+			}
+			for _, path := range requested {
+				if strings.Contains(path, "node_modules") || strings.Contains(path, "internal") || strings.Contains(path, "lib-sha") ||
+					strings.Contains(path, "secret") || strings.Contains(path, "dep-sha") {
+					t.Errorf("Expected excluded directories never to be fetched, got a request for %s", path)
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubAdapter_FetchFiles_TreeAPIFallback(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/owner/repo/git/trees/HEAD":
+			json.NewEncoder(w).Encode(map[string]interface{}{"sha": "tree-sha", "tree": []interface{}{}, "truncated": true})
+		case "/repos/owner/repo/contents/":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"type": "file", "name": "README.md", "path": "README.md", "size": 6, "download_url": serverURL + "/raw/README.md"},
+			})
+		case "/raw/README.md":
+			w.Write([]byte("# Docs"))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	adapter := newTestGitHubAdapter(t, server, config.GitHubConfig{
+		Mappings:   []config.RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-id"}},
+		UseTreeAPI: true,
+	})
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "README.md" {
+		t.Errorf("Expected truncated tree to fall back to the contents API, got %v", files)
+	}
+}
@@ -0,0 +1,174 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRateLimitWait is how long a rate-limited token is skipped when GitHub doesn't say when it resets
+const defaultRateLimitWait = time.Minute
+
+// githubTokenTransport authenticates GitHub requests with one of several tokens, using them
+// round-robin to spread requests across their rate limits. A token that hits its rate limit
+// is skipped until the limit resets; when every token is exhausted, requests wait for the
+// earliest reset.
+type githubTokenTransport struct {
+	tokens []string
+	base   http.RoundTripper
+	now    func() time.Time // replaced in tests
+
+	mu      sync.Mutex
+	next    int
+	resetAt []time.Time // per token: when its exhausted rate limit resets (zero when available)
+}
+
+// newGitHubTokenTransport creates a transport rotating the given tokens over base
+func newGitHubTokenTransport(tokens []string, base http.RoundTripper) *githubTokenTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &githubTokenTransport{
+		tokens:  tokens,
+		base:    base,
+		now:     time.Now,
+		resetAt: make([]time.Time, len(tokens)),
+	}
+}
+
+// githubTokens returns the configured tokens, with the single token first, without duplicates
+func githubTokens(token string, tokens []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, t := range append([]string{token}, tokens...) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// pickToken returns the index of the next available token in round-robin order. When all
+// tokens are rate limited it returns -1 and the time the first one becomes available again.
+func (t *githubTokenTransport) pickToken() (int, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var earliest time.Time
+	for i := 0; i < len(t.tokens); i++ {
+		index := (t.next + i) % len(t.tokens)
+		reset := t.resetAt[index]
+		if reset.IsZero() || !now.Before(reset) {
+			t.resetAt[index] = time.Time{}
+			t.next = (index + 1) % len(t.tokens)
+			return index, time.Time{}
+		}
+		if earliest.IsZero() || reset.Before(earliest) {
+			earliest = reset
+		}
+	}
+	return -1, earliest
+}
+
+// markRateLimited skips the token at index until reset
+func (t *githubTokenTransport) markRateLimited(index int, reset time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resetAt[index] = reset
+}
+
+// RoundTrip sends the request with the next available token, switching to another token
+// when GitHub reports the rate limit of the current one as exceeded
+func (t *githubTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var lastResp *http.Response
+	for attempt := 0; attempt <= len(t.tokens); attempt++ {
+		index, availableAt := t.pickToken()
+		if index < 0 {
+			wait := availableAt.Sub(t.now())
+			logrus.Warnf("All %d GitHub tokens are rate limited, waiting %v", len(t.tokens), wait.Round(time.Second))
+			select {
+			case <-req.Context().Done():
+				if lastResp != nil {
+					lastResp.Body.Close()
+				}
+				return nil, req.Context().Err()
+			case <-time.After(wait):
+			}
+			index, _ = t.pickToken()
+			if index < 0 {
+				index = 0
+			}
+		}
+
+		if lastResp != nil {
+			io.Copy(io.Discard, lastResp.Body)
+			lastResp.Body.Close()
+		}
+
+		attemptReq, err := cloneRequestWithToken(req, t.tokens[index])
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		if !isGitHubRateLimited(resp) {
+			return resp, nil
+		}
+
+		reset := gitHubRateLimitReset(resp, t.now())
+		t.markRateLimited(index, reset)
+		logrus.Warnf("GitHub token %d/%d is rate limited until %s", index+1, len(t.tokens), reset.Format(time.RFC3339))
+
+		// Requests whose body can't be re-sent return the rate limit response to the caller
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		lastResp = resp
+	}
+	return lastResp, nil
+}
+
+// cloneRequestWithToken copies req with its body rewound and the token set, since a
+// RoundTripper must not modify the original request
+func cloneRequestWithToken(req *http.Request, token string) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone, nil
+}
+
+// isGitHubRateLimited reports whether resp is a primary or secondary rate limit response
+func isGitHubRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// gitHubRateLimitReset returns when the rate limit reported by resp resets
+func gitHubRateLimitReset(resp *http.Response, now time.Time) time.Time {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset := time.Unix(epoch, 0); reset.After(now) {
+			return reset
+		}
+	}
+	return now.Add(defaultRateLimitWait)
+}
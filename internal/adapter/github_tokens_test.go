@@ -0,0 +1,141 @@
+package adapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGitHubTokens(t *testing.T) {
+	tokens := githubTokens("token-a", []string{"token-b", "token-a", "", "token-c"})
+	expected := []string{"token-a", "token-b", "token-c"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Expected %v, got %v", expected, tokens)
+	}
+
+	if tokens := githubTokens("", nil); len(tokens) != 0 {
+		t.Errorf("Expected no tokens, got %v", tokens)
+	}
+}
+
+func TestGitHubTokenTransport_pickToken(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	transport := newGitHubTokenTransport([]string{"a", "b", "c"}, nil)
+	transport.now = func() time.Time { return now }
+
+	// Tokens are used round-robin
+	var picked []int
+	for i := 0; i < 4; i++ {
+		index, _ := transport.pickToken()
+		picked = append(picked, index)
+	}
+	if !reflect.DeepEqual(picked, []int{0, 1, 2, 0}) {
+		t.Errorf("Expected round-robin order [0 1 2 0], got %v", picked)
+	}
+
+	// Rate limited tokens are skipped until their reset
+	transport.markRateLimited(1, now.Add(time.Minute))
+	picked = nil
+	for i := 0; i < 3; i++ {
+		index, _ := transport.pickToken()
+		picked = append(picked, index)
+	}
+	if !reflect.DeepEqual(picked, []int{2, 0, 2}) {
+		t.Errorf("Expected rate limited token to be skipped, got %v", picked)
+	}
+
+	// With every token exhausted, the earliest reset is reported
+	transport.markRateLimited(0, now.Add(30*time.Second))
+	transport.markRateLimited(2, now.Add(2*time.Minute))
+	index, availableAt := transport.pickToken()
+	if index != -1 || !availableAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("Expected no token until %v, got index %d at %v", now.Add(30*time.Second), index, availableAt)
+	}
+
+	// Tokens become available again once their reset has passed
+	now = now.Add(45 * time.Second)
+	if index, _ := transport.pickToken(); index != 0 {
+		t.Errorf("Expected token 0 after its reset, got %d", index)
+	}
+}
+
+func TestGitHubTokenTransport_RotatesOnRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		used = append(used, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") == "Bearer token-a" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newGitHubTokenTransport([]string{"token-a", "token-b"}, nil)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected request to succeed with the second token, got status %d", resp.StatusCode)
+		}
+	}
+
+	// The first request rotates away from token-a, the second doesn't try it again
+	expected := []string{"Bearer token-a", "Bearer token-b", "Bearer token-b"}
+	if !reflect.DeepEqual(used, expected) {
+		t.Errorf("Expected tokens %v, got %v", expected, used)
+	}
+}
+
+func TestGitHubRateLimitReset(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected time.Time
+	}{
+		{
+			name:     "retry after",
+			headers:  map[string]string{"Retry-After": "30"},
+			expected: now.Add(30 * time.Second),
+		},
+		{
+			name:     "rate limit reset",
+			headers:  map[string]string{"X-RateLimit-Reset": strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10)},
+			expected: now.Add(10 * time.Minute),
+		},
+		{
+			name:     "no headers",
+			headers:  map[string]string{},
+			expected: now.Add(defaultRateLimitWait),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+			if reset := gitHubRateLimitReset(resp, now); !reset.Equal(tt.expected) {
+				t.Errorf("Expected reset %v, got %v", tt.expected, reset)
+			}
+		})
+	}
+}
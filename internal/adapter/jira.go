@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
@@ -15,17 +17,29 @@ import (
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
 	"github.com/openwebui-content-sync/internal/config"
-	"github.com/sirupsen/logrus"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/utils"
 )
 
 // JiraAdapter implements the Adapter interface for Jira projects
 type JiraAdapter struct {
-	client   *http.Client
-	config   config.JiraConfig
-	lastSync time.Time
-	projects []string
-	mappings map[string]string // project_key -> knowledge_id mapping
-}
+	client          *http.Client
+	retryConfig     utils.RetryConfig // retries of network errors, 429 and 5xx responses
+	config          config.JiraConfig
+	lastSync        time.Time
+	projects        []string
+	mappings        map[string]string // project_key -> knowledge_id mapping
+	queries         map[string]string // project_key -> custom JQL query
+	initialSyncDone bool              // set after the first complete fetch so later runs can be incremental
+	updatedSince    time.Time         // start of the last complete fetch, the cursor of incremental runs
+	timeZone        *time.Location    // time zone Jira interprets JQL dates in, nil until resolved
+	fetchFailed     bool              // set when part of the current fetch failed, so the cursor isn't advanced
+	logger          logging.Logger    // nil to log to the global logrus logger
+}
+
+// jiraUpdatedOverlap is subtracted from the cursor of incremental runs, so issues updated
+// while the previous fetch ran, or hidden by clock skew, are fetched again
+const jiraUpdatedOverlap = 5 * time.Minute
 
 // JiraIssue represents a Jira issue from the API
 type JiraIssue struct {
@@ -81,22 +95,31 @@ type JiraBody struct {
 	Type    string      `json:"type"`
 	Version int         `json:"version"`
 	Content []JiraBlock `json:"content"`
+	Text    string      `json:"-"` // wiki markup body returned by REST API v2 instead of ADF
 }
 type JiraBlock struct {
 	Type    string     `json:"type"`
+	Attrs   JiraAttrs  `json:"attrs,omitempty"`
 	Content []JiraText `json:"content"`
 }
+
+// JiraText is an inline ADF node; list items and other containers nest further nodes in Content
 type JiraText struct {
-	Type  string     `json:"type"`
-	Text  string     `json:"text"`
-	Marks []JiraMark `json:"marks,omitempty"`
+	Type    string     `json:"type"`
+	Text    string     `json:"text"`
+	Attrs   JiraAttrs  `json:"attrs,omitempty"`
+	Marks   []JiraMark `json:"marks,omitempty"`
+	Content []JiraText `json:"content,omitempty"`
 }
 type JiraMark struct {
 	Type  string    `json:"type"`
 	Attrs JiraAttrs `json:"attrs,omitempty"`
 }
 type JiraAttrs struct {
-	Href string `json:"href"`
+	Href  string `json:"href"`
+	URL   string `json:"url,omitempty"`   // inline cards
+	Text  string `json:"text,omitempty"`  // mentions and emojis
+	Level int    `json:"level,omitempty"` // headings
 }
 
 // JiraProject represents a Jira project
@@ -251,7 +274,7 @@ func NewJiraAdapter(cfg config.JiraConfig) (*JiraAdapter, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("jira base URL is required")
 	}
-	if cfg.Username == "" {
+	if cfg.Username == "" && cfg.AuthType != "bearer" {
 		return nil, fmt.Errorf("jira username is required")
 	}
 	if cfg.APIKey == "" {
@@ -260,11 +283,19 @@ func NewJiraAdapter(cfg config.JiraConfig) (*JiraAdapter, error) {
 
 	// Build project mappings
 	mappings := make(map[string]string)
+	queries := make(map[string]string)
 	projects := []string{}
 
 	// Process mappings
 	for _, mapping := range cfg.ProjectMappings {
 		if mapping.ProjectKey != "" && mapping.KnowledgeID != "" {
+			if mapping.JQL != "" {
+				jql := strings.TrimSpace(mapping.JQL)
+				if jql == "" {
+					return nil, fmt.Errorf("jira JQL for project %s must not be blank", mapping.ProjectKey)
+				}
+				queries[mapping.ProjectKey] = jql
+			}
 			mappings[mapping.ProjectKey] = mapping.KnowledgeID
 			projects = append(projects, mapping.ProjectKey)
 		}
@@ -279,48 +310,104 @@ func NewJiraAdapter(cfg config.JiraConfig) (*JiraAdapter, error) {
 	}
 
 	return &JiraAdapter{
-		client:   client,
-		config:   cfg,
-		projects: projects,
-		mappings: mappings,
-		lastSync: time.Now(),
+		client:      client,
+		retryConfig: newRetryConfig(cfg.Retry, utils.DefaultRetryConfig()),
+		config:      cfg,
+		projects:    projects,
+		mappings:    mappings,
+		queries:     queries,
+		lastSync:    time.Now(),
 	}, nil
 }
 
+// SetHeaders sets the User-Agent and extra headers sent with every request to Jira
+func (j *JiraAdapter) SetHeaders(userAgent string, headers map[string]string) {
+	utils.WithHeaders(j.client, userAgent, headers)
+}
+
+// do sends a request to Jira, retrying network errors, 429 and 5xx responses
+func (j *JiraAdapter) do(req *http.Request) (*http.Response, error) {
+	return utils.DoWithRetry(j.client, req, j.retryConfig)
+}
+
+// isServer reports whether the adapter talks to Jira Server or Data Center
+func (j *JiraAdapter) isServer() bool {
+	return j.config.Deployment == "server"
+}
+
+// restAPIURL returns the URL of a REST API path: v3 on Jira Cloud, v2 on Jira Server and Data
+// Center, which don't serve v3
+func (j *JiraAdapter) restAPIURL(path string) string {
+	if j.isServer() {
+		return j.config.BaseURL + "/rest/api/2" + path
+	}
+	return j.config.BaseURL + "/rest/api/3" + path
+}
+
 // Name returns the adapter name
 func (j *JiraAdapter) Name() string {
 	return "jira"
 }
 
-// FetchFiles fetches all issues from the configured Jira projects
+// SetLogger sets the logger the adapter writes to
+func (j *JiraAdapter) SetLogger(logger logging.Logger) {
+	j.logger = logger
+}
+
+// log returns the adapter's logger
+func (j *JiraAdapter) log() logging.Logger {
+	if j.logger == nil {
+		return logging.Default()
+	}
+	return j.logger
+}
+
+// FetchFiles fetches all issues from the configured Jira projects. With incremental sync, the
+// cursor only advances when every issue was fetched, so issues that failed are fetched again.
 func (j *JiraAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	var allFiles []*File
+	syncStart := time.Now()
+	j.fetchFailed = false
+	if j.config.IncrementalSync && j.timeZone == nil {
+		j.resolveTimeZone(ctx)
+	}
 
 	for _, projectKey := range j.projects {
-		logrus.Debugf("Fetching files from Jira project: %s", projectKey)
+		j.log().Debugf("Fetching files from Jira project: %s", projectKey)
 		knowledgeID := j.mappings[projectKey]
 
 		// Fetch all issues from the project
 		issues, err := j.fetchIssues(ctx, projectKey)
 		if err != nil {
-			logrus.Errorf("Failed to fetch issues from Jira project %s: %v", projectKey, err)
+			j.log().Errorf("Failed to fetch issues from Jira project %s: %v", projectKey, err)
+			j.fetchFailed = true
 			continue
 		}
 
-		logrus.Debugf("Found %d issues in Jira project %s", len(issues), projectKey)
+		j.log().Debugf("Found %d issues in Jira project %s", len(issues), projectKey)
 
 		// Process each issue
 		for _, issue := range issues {
 			file, err := j.processIssue(ctx, issue, knowledgeID)
 			if err != nil {
-				logrus.Errorf("Failed to process issue %s: %v", issue.Key, err)
+				j.log().Errorf("Failed to process issue %s: %v", issue.Key, err)
+				j.fetchFailed = true
 				continue
 			}
 			allFiles = append(allFiles, file)
+			if j.config.IncludeAttachments {
+				allFiles = append(allFiles, j.processIssueAttachments(ctx, issue, knowledgeID)...)
+			}
 		}
 	}
 
-	j.lastSync = time.Now()
+	j.lastSync = syncStart
+	if j.fetchFailed {
+		j.log().Warnf("Some Jira issues failed to fetch, the next run fetches the same updates again")
+	} else {
+		j.updatedSince = syncStart
+		j.initialSyncDone = true
+	}
 	return allFiles, nil
 }
 
@@ -338,7 +425,8 @@ func (j *JiraAdapter) fetchIssues(ctx context.Context, projectKey string) ([]Jir
 	for _, issueID := range issueIDs {
 		issue, err := j.fetchIssue(ctx, issueID)
 		if err != nil {
-			logrus.Errorf("Failed to fetch issue %s: %v", issueID, err)
+			j.log().Errorf("Failed to fetch issue %s: %v", issueID, err)
+			j.fetchFailed = true
 			continue
 		}
 		allIssues = append(allIssues, issue)
@@ -361,26 +449,30 @@ func (j *JiraAdapter) fetchAllIssueIDs(ctx context.Context, projectKey string) (
 		maxResults = limit
 	}
 	for {
-		logrus.Debugf("Limit: %d, MaxResults: %d", limit, maxResults)
-		// Build JQL query to fetch issues from the project
-		jqlQuery := fmt.Sprintf("project = '%s'", projectKey)
-
-		// Build URL for search endpoint with pagination - following the exact API specification
-		url := fmt.Sprintf("%s/rest/api/3/search/jql?jql=%s&maxResults=%d&fields=id%s",
-			j.config.BaseURL, url.QueryEscape(jqlQuery), maxResults, nextPageToken)
+		j.log().Debugf("Limit: %d, MaxResults: %d", limit, maxResults)
+		jqlQuery := j.buildJQL(projectKey)
+
+		// Build URL for search endpoint with pagination - following the exact API specification.
+		// Server and Data Center lack search/jql and page by offset instead of token.
+		searchURL := fmt.Sprintf("%s?jql=%s&maxResults=%d&fields=id%s",
+			j.restAPIURL("/search/jql"), url.QueryEscape(jqlQuery), maxResults, nextPageToken)
+		if j.isServer() {
+			searchURL = fmt.Sprintf("%s?jql=%s&startAt=%d&maxResults=%d&fields=id",
+				j.restAPIURL("/search"), url.QueryEscape(jqlQuery), startAt, maxResults)
+		}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
 		// Set authentication
-		req.SetBasicAuth(j.config.Username, j.config.APIKey)
+		j.authenticate(req)
 		req.Header.Set("Accept", "application/json")
 
-		logrus.Debugf("Jira search API URL: %s", url)
+		j.log().Debugf("Jira search API URL: %s", searchURL)
 
-		resp, err := j.client.Do(req)
+		resp, err := j.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to make request: %w", err)
 		}
@@ -397,6 +489,7 @@ func (j *JiraAdapter) fetchAllIssueIDs(ctx context.Context, projectKey string) (
 
 			IsLast        bool   `json:"isLast"`
 			NextPageToken string `json:"nextPageToken,omitempty"`
+			Total         int    `json:"total"` // REST API v2 only
 		}
 
 		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
@@ -410,24 +503,113 @@ func (j *JiraAdapter) fetchAllIssueIDs(ctx context.Context, projectKey string) (
 			issueIDs = append(issueIDs, issue.ID)
 		}
 
-		if response.IsLast || len(issueIDs) >= limit {
+		// Check if there are more results
+		startAt += len(response.Issues)
+		if len(issueIDs) >= limit {
 			break
-		} else {
-			nextPageToken = fmt.Sprintf(`&nextPageToken=%s`, response.NextPageToken)
 		}
-		// Check if there are more results
-		startAt += maxResults
+		if j.isServer() {
+			if len(response.Issues) == 0 || startAt >= response.Total {
+				break
+			}
+			continue
+		}
+		if response.IsLast {
+			break
+		}
+		nextPageToken = fmt.Sprintf(`&nextPageToken=%s`, response.NextPageToken)
 	}
 
 	return issueIDs, nil
 }
 
+// jqlOrderByRegex matches a trailing ORDER BY clause in a JQL query
+var jqlOrderByRegex = regexp.MustCompile(`(?i)(^|\s+)ORDER\s+BY\s+.*$`)
+
+// buildJQL builds the JQL query used to search a project's issues.
+// A custom JQL query from the mapping is used verbatim instead of "project = 'KEY'".
+// With incremental sync enabled, runs after the first complete fetch only request issues updated
+// since the previous complete fetch started, less jiraUpdatedOverlap, in Jira's time zone.
+func (j *JiraAdapter) buildJQL(projectKey string) string {
+	jqlQuery := fmt.Sprintf("project = '%s'", projectKey)
+	custom, hasCustom := j.queries[projectKey]
+	if hasCustom {
+		jqlQuery = custom
+	}
+
+	if j.config.IncrementalSync && j.initialSyncDone && !j.updatedSince.IsZero() {
+		timeZone := j.timeZone
+		if timeZone == nil {
+			timeZone = time.Local
+		}
+		// Jira only accepts minute precision, so truncating errs on the side of re-fetching
+		since := j.updatedSince.Add(-jiraUpdatedOverlap).In(timeZone)
+		updatedClause := fmt.Sprintf(`updated >= "%s"`, since.Format("2006-01-02 15:04"))
+		if !hasCustom {
+			return jqlQuery + " AND " + updatedClause
+		}
+
+		// Keep any ORDER BY at the end and group the custom filter so OR clauses stay scoped
+		orderBy := jqlOrderByRegex.FindString(custom)
+		filter := strings.TrimSpace(strings.TrimSuffix(custom, orderBy))
+		if filter == "" {
+			return updatedClause + " " + strings.TrimSpace(orderBy)
+		}
+		return fmt.Sprintf("(%s) AND %s%s", filter, updatedClause, orderBy)
+	}
+	return jqlQuery
+}
+
+// resolveTimeZone looks up the time zone of the authenticated user, which Jira interprets JQL
+// dates in. On failure the local time zone is used until a later fetch resolves it.
+func (j *JiraAdapter) resolveTimeZone(ctx context.Context) {
+	req, err := http.NewRequestWithContext(ctx, "GET", j.restAPIURL("/myself"), nil)
+	if err != nil {
+		j.log().Warnf("Failed to create request for the Jira user: %v", err)
+		return
+	}
+	j.authenticate(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.do(req)
+	if err != nil {
+		j.log().Warnf("Failed to fetch the Jira user's time zone, using local time: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		j.log().Warnf("Failed to fetch the Jira user's time zone, using local time: status %d", resp.StatusCode)
+		return
+	}
+
+	var user struct {
+		TimeZone string `json:"timeZone"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		j.log().Warnf("Failed to decode the Jira user, using local time: %v", err)
+		return
+	}
+	timeZone, err := time.LoadLocation(user.TimeZone)
+	if err != nil || user.TimeZone == "" {
+		j.log().Warnf("Unknown Jira time zone %q, using local time", user.TimeZone)
+		return
+	}
+	j.timeZone = timeZone
+}
+
 // fetchIssue fetches a single issue by ID using the issue endpoint
 func (j *JiraAdapter) fetchIssue(ctx context.Context, issueID string) (JiraIssue, error) {
 	var issue JiraIssue
 
 	// Build URL for individual issue fetch
-	url := fmt.Sprintf("%s/rest/api/3/issue/%s?expand=renderedFields&name&fields=summary,description,parent,issuetype,reporter,status,comment", j.config.BaseURL, issueID)
+	expand := "renderedFields"
+	if j.config.IncludeChangelog {
+		expand += ",changelog"
+	}
+	url := fmt.Sprintf("%s?expand=%s&name&fields=summary,description,parent,issuetype,reporter,status,comment,assignee,priority,resolution,labels,components,created,updated", j.restAPIURL("/issue/"+issueID), expand)
+	if j.config.IncludeAttachments {
+		url += ",attachment"
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -435,12 +617,12 @@ func (j *JiraAdapter) fetchIssue(ctx context.Context, issueID string) (JiraIssue
 	}
 
 	// Set authentication
-	req.SetBasicAuth(j.config.Username, j.config.APIKey)
+	j.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Jira issue API URL: %s", url)
+	j.log().Debugf("Jira issue API URL: %s", url)
 
-	resp, err := j.client.Do(req)
+	resp, err := j.do(req)
 	if err != nil {
 		return issue, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -464,7 +646,7 @@ func (j *JiraAdapter) fetchProject(ctx context.Context, projectKey string) (Jira
 	var project JiraProject
 
 	// Build URL for project fetch
-	url := fmt.Sprintf("%s/rest/api/3/project/%s", j.config.BaseURL, projectKey)
+	url := j.restAPIURL("/project/" + projectKey)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -472,12 +654,12 @@ func (j *JiraAdapter) fetchProject(ctx context.Context, projectKey string) (Jira
 	}
 
 	// Set authentication
-	req.SetBasicAuth(j.config.Username, j.config.APIKey)
+	j.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Jira project API URL: %s", url)
+	j.log().Debugf("Jira project API URL: %s", url)
 
-	resp, err := j.client.Do(req)
+	resp, err := j.do(req)
 	if err != nil {
 		return project, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -511,20 +693,75 @@ func (j *JiraAdapter) HtmlToMarkdown(htmlContent string) string {
 	)
 	markdown, err := conv.ConvertString(htmlContent, converter.WithDomain(j.config.BaseURL))
 	if err != nil {
-		logrus.Warnf("Failed to convert HTML to markdown: %v", err)
+		j.log().Warnf("Failed to convert HTML to markdown: %v", err)
 		return htmlContent
 	}
 	return markdown
 }
 
+// buildIssueMetadata renders the metadata block of an issue. Optional fields are only
+// included when Jira returned a value for them.
+func buildIssueMetadata(issue JiraIssue) string {
+	fields := issue.Fields
+
+	var sb strings.Builder
+	sb.WriteString("# Jira Issue\n---\n## Issue Metadata:\n")
+	fmt.Fprintf(&sb, "Ticket-ID: %s\nReporter: %s\nIssueType: %s\nStatus: %s\nResolved: %t\n",
+		issue.Key, fields.Reporter.DisplayName, fields.IssueType.Name, fields.Status.Name, fields.Status.Resolved)
+
+	if fields.Assignee != nil && fields.Assignee.DisplayName != "" {
+		fmt.Fprintf(&sb, "Assignee: %s\n", fields.Assignee.DisplayName)
+	}
+	if fields.Priority.Name != "" {
+		fmt.Fprintf(&sb, "Priority: %s\n", fields.Priority.Name)
+	}
+	if fields.Resolution != nil && fields.Resolution.Name != "" {
+		fmt.Fprintf(&sb, "Resolution: %s\n", fields.Resolution.Name)
+	}
+	if len(fields.Labels) > 0 {
+		fmt.Fprintf(&sb, "Labels: %s\n", strings.Join(fields.Labels, ", "))
+	}
+	if len(fields.Components) > 0 {
+		names := make([]string, 0, len(fields.Components))
+		for _, component := range fields.Components {
+			if component.Name != "" {
+				names = append(names, component.Name)
+			}
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(&sb, "Components: %s\n", strings.Join(names, ", "))
+		}
+	}
+	if fields.Created != "" {
+		fmt.Fprintf(&sb, "Created: %s\n", formatJiraDate(fields.Created))
+	}
+	if fields.Updated != "" {
+		fmt.Fprintf(&sb, "Updated: %s\n", formatJiraDate(fields.Updated))
+	}
+
+	sb.WriteString("---\n ")
+	return sb.String()
+}
+
+// formatJiraDate shortens a Jira timestamp to YYYY-MM-DD HH:MM
+// (e.g., "2025-02-19T17:07:41.093+0100" -> "2025-02-19 17:07")
+func formatJiraDate(timestamp string) string {
+	if len(timestamp) < 16 {
+		return timestamp
+	}
+	return fmt.Sprintf("%s %s", timestamp[:10], timestamp[11:16])
+}
+
 // processIssue processes a single Jira issue and returns a File
 func (j *JiraAdapter) processIssue(ctx context.Context, issue JiraIssue, knowledgeID string) (*File, error) {
 	// Fetch comments for this issue
-	comments, err := j.fetchCommentsForIssue(ctx, issue.ID)
+	comments, err := j.fetchCommentsForIssue(ctx, issue)
 	if err != nil {
-		logrus.Warnf("Failed to fetch comments for issue %s: %v", issue.Key, err)
+		j.log().Warnf("Failed to fetch comments for issue %s: %v", issue.Key, err)
+		j.fetchFailed = true
 		// Continue processing without comments
 	}
+	comments = j.filterComments(comments, time.Now())
 
 	// Add comments to the issue
 	issue.FetchedComments = comments
@@ -532,20 +769,14 @@ func (j *JiraAdapter) processIssue(ctx context.Context, issue JiraIssue, knowled
 	// Convert issue to JSON
 
 	description := j.HtmlToMarkdown(issue.RenderedFields.Description)
-	metaData := fmt.Sprintf("# Jira Issue\n---\n## Issue Metadata:\nTicket-ID: %s\nReporter: %s\nIssueType: %s\nStatus: %s\nResolved: %t\n---\n ", issue.Key, issue.Fields.Reporter.DisplayName, issue.Fields.IssueType.Name, issue.Fields.Status.Name, issue.Fields.Status.Resolved)
+	metaData := buildIssueMetadata(issue)
 
 	// Format comments in markdown
 	var commentsMarkdown string
 	if len(comments) > 0 {
 		commentsMarkdown = "\n## Comments\n"
 		for _, comment := range comments {
-			// Format the created timestamp to YYYY-MM-DD HH:MM
-			formattedDate := comment.Created
-			if len(comment.Created) >= 16 {
-				// Extract date and time part (e.g., "2025-02-19T17:07:41.093+0100" -> "2025-02-19 17:07")
-				formattedDate = fmt.Sprintf("%s %s", comment.Created[:10], comment.Created[11:16])
-			}
-			commentsMarkdown += fmt.Sprintf("%s (%s): %s\n\n", comment.AuthorName, formattedDate, comment.RenderedBody)
+			commentsMarkdown += fmt.Sprintf("%s (%s): %s\n\n", comment.AuthorName, formatJiraDate(comment.Created), comment.RenderedBody)
 		}
 	}
 
@@ -554,7 +785,7 @@ func (j *JiraAdapter) processIssue(ctx context.Context, issue JiraIssue, knowled
 	// if err != nil {
 	// 	return nil, fmt.Errorf("failed to marshal issue to JSON: %w", err)
 	// }
-	content := fmt.Sprintf("%s\n\n## %s\n%s%s\n\n\n", metaData, issue.Fields.Summary, description, commentsMarkdown)
+	content := fmt.Sprintf("%s\n\n## %s\n%s%s%s\n\n\n", metaData, issue.Fields.Summary, description, commentsMarkdown, j.historyMarkdown(issue.Changelog))
 
 	// // Create file content
 	fileContent := []byte(content)
@@ -575,9 +806,25 @@ func (j *JiraAdapter) processIssue(ctx context.Context, issue JiraIssue, knowled
 		Size:        int64(len(fileContent)),
 		Source:      "jira",
 		KnowledgeID: knowledgeID,
+		ContentType: "text/markdown",
 	}, nil
 }
 
+// KnowledgeIDs returns the knowledge base IDs of the configured project mappings
+func (j *JiraAdapter) KnowledgeIDs() []string {
+	knowledgeIDs := make([]string, 0, len(j.projects))
+	for _, projectKey := range j.projects {
+		knowledgeIDs = append(knowledgeIDs, j.mappings[projectKey])
+	}
+	return knowledgeIDs
+}
+
+// RequestFullFetch makes the next fetch ignore incremental_sync; the cursor advances again once
+// it completes
+func (j *JiraAdapter) RequestFullFetch() {
+	j.initialSyncDone = false
+}
+
 // GetLastSync returns the last sync time
 func (j *JiraAdapter) GetLastSync() time.Time {
 	return j.lastSync
@@ -0,0 +1,120 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UnmarshalJSON decodes an ADF document, or the wiki markup string REST API v2 returns instead
+func (b *JiraBody) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &b.Text)
+	}
+	type plain JiraBody
+	return json.Unmarshal(data, (*plain)(b))
+}
+
+// adfToMarkdown converts an Atlassian Document Format body, as returned for Jira comments,
+// to markdown. Unknown block types are rendered as paragraphs of their text. Wiki markup
+// bodies of Jira Server and Data Center are returned as they are.
+func adfToMarkdown(body JiraBody) string {
+	if body.Text != "" {
+		return body.Text
+	}
+	var blocks []string
+	for _, block := range body.Content {
+		if markdown := adfBlockToMarkdown(block); markdown != "" {
+			blocks = append(blocks, markdown)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// adfBlockToMarkdown converts a top-level ADF block node
+func adfBlockToMarkdown(block JiraBlock) string {
+	switch block.Type {
+	case "heading":
+		level := block.Attrs.Level
+		if level < 1 || level > 6 {
+			level = 1
+		}
+		return strings.Repeat("#", level) + " " + adfInlineToMarkdown(block.Content)
+	case "bulletList", "orderedList":
+		var items []string
+		for i, item := range block.Content {
+			prefix := "- "
+			if block.Type == "orderedList" {
+				prefix = fmt.Sprintf("%d. ", i+1)
+			}
+			items = append(items, prefix+adfInlineToMarkdown(item.Content))
+		}
+		return strings.Join(items, "\n")
+	case "codeBlock":
+		var code strings.Builder
+		for _, node := range block.Content {
+			code.WriteString(node.Text)
+		}
+		return "```\n" + code.String() + "\n```"
+	case "blockquote":
+		var lines []string
+		for _, line := range strings.Split(adfInlineToMarkdown(block.Content), "\n") {
+			lines = append(lines, "> "+line)
+		}
+		return strings.Join(lines, "\n")
+	case "rule":
+		return "---"
+	default:
+		return adfInlineToMarkdown(block.Content)
+	}
+}
+
+// adfInlineToMarkdown converts inline ADF nodes. Nested containers such as the paragraphs
+// of a list item are flattened, one per line.
+func adfInlineToMarkdown(nodes []JiraText) string {
+	var sb strings.Builder
+	for i, node := range nodes {
+		switch node.Type {
+		case "text":
+			sb.WriteString(applyADFMarks(node.Text, node.Marks))
+		case "hardBreak":
+			sb.WriteString("\n")
+		case "mention", "emoji":
+			sb.WriteString(node.Attrs.Text)
+		case "inlineCard":
+			sb.WriteString(node.Attrs.URL)
+		default:
+			if len(node.Content) > 0 {
+				if i > 0 {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(adfInlineToMarkdown(node.Content))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// applyADFMarks wraps text in the markdown for its code, bold, italic, strikethrough and link marks
+func applyADFMarks(text string, marks []JiraMark) string {
+	var href string
+	for _, mark := range marks {
+		switch mark.Type {
+		case "code":
+			text = "`" + text + "`"
+		case "strong":
+			text = "__" + text + "__"
+		case "em":
+			text = "*" + text + "*"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "link":
+			href = mark.Attrs.Href
+		}
+	}
+	// Links wrap the other marks so the formatting ends up inside the link text
+	if href != "" {
+		text = fmt.Sprintf("[%s](%s)", text, href)
+	}
+	return text
+}
@@ -0,0 +1,72 @@
+package adapter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestADFToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		adf      string
+		expected string
+	}{
+		{
+			name:     "paragraphs",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "First"}]}, {"type": "paragraph", "content": [{"type": "text", "text": "Second"}]}]}`,
+			expected: "First\n\nSecond",
+		},
+		{
+			name:     "text marks",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "bold", "marks": [{"type": "strong"}]}, {"type": "text", "text": " and "}, {"type": "text", "text": "italic", "marks": [{"type": "em"}]}, {"type": "text", "text": " "}, {"type": "text", "text": "x := 1", "marks": [{"type": "code"}]}]}]}`,
+			expected: "__bold__ and *italic* `x := 1`",
+		},
+		{
+			name:     "link",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "See "}, {"type": "text", "text": "the docs", "marks": [{"type": "link", "attrs": {"href": "https://example.com/docs"}}, {"type": "strong"}]}]}]}`,
+			expected: "See [__the docs__](https://example.com/docs)",
+		},
+		{
+			name:     "hard break",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Line one"}, {"type": "hardBreak"}, {"type": "text", "text": "Line two"}]}]}`,
+			expected: "Line one\nLine two",
+		},
+		{
+			name:     "mention and emoji",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "mention", "attrs": {"id": "123", "text": "@Jane Doe"}}, {"type": "text", "text": " thanks "}, {"type": "emoji", "attrs": {"shortName": ":smile:", "text": "😄"}}]}]}`,
+			expected: "@Jane Doe thanks 😄",
+		},
+		{
+			name:     "heading and lists",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "heading", "attrs": {"level": 3}, "content": [{"type": "text", "text": "Steps"}]}, {"type": "orderedList", "content": [{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Build"}]}]}, {"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Deploy"}]}]}]}, {"type": "bulletList", "content": [{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Note"}]}]}]}]}`,
+			expected: "### Steps\n\n1. Build\n2. Deploy\n\n- Note",
+		},
+		{
+			name:     "code block and rule",
+			adf:      `{"type": "doc", "version": 1, "content": [{"type": "codeBlock", "attrs": {"language": "go"}, "content": [{"type": "text", "text": "fmt.Println(1)"}]}, {"type": "rule"}]}`,
+			expected: "```\nfmt.Println(1)\n```\n\n---",
+		},
+		{
+			name:     "wiki markup body of REST API v2",
+			adf:      `"Looks *good*, see [the docs|https://example.com/docs]"`,
+			expected: "Looks *good*, see [the docs|https://example.com/docs]",
+		},
+		{
+			name:     "empty body",
+			adf:      `{"type": "doc", "version": 1, "content": []}`,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body JiraBody
+			if err := json.Unmarshal([]byte(tt.adf), &body); err != nil {
+				t.Fatalf("Failed to decode ADF: %v", err)
+			}
+			if got := adfToMarkdown(body); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
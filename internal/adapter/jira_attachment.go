@@ -0,0 +1,100 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+// processIssueAttachments downloads the attachments of an issue, returning them as Files named
+// after the issue key. Binary attachments are skipped unless enabled, as are attachments above
+// the size cap. Failures are logged and skipped so a broken attachment doesn't prevent the
+// issue from syncing.
+func (j *JiraAdapter) processIssueAttachments(ctx context.Context, issue JiraIssue, knowledgeID string) []*File {
+	var files []*File
+	for _, attachment := range issue.Fields.Attachments {
+		if !j.config.IncludeBinaryAttachments && !isTextMediaType(attachment.MimeType) {
+			j.log().Debugf("Skipping binary attachment %s (%s) on issue %s", attachment.Filename, attachment.MimeType, issue.Key)
+			continue
+		}
+		if j.exceedsAttachmentSize(int64(attachment.Size)) {
+			j.log().Debugf("Skipping attachment %s on issue %s: size %d bytes exceeds limit of %d bytes", attachment.Filename, issue.Key, attachment.Size, j.config.MaxAttachmentSizeBytes)
+			continue
+		}
+
+		content, err := j.downloadAttachment(ctx, attachment)
+		if err != nil {
+			j.log().Errorf("Failed to download attachment %s on issue %s: %v", attachment.Filename, issue.Key, err)
+			j.fetchFailed = true
+			continue
+		}
+
+		// Prefix with the issue key so attachments with the same name on different issues don't collide
+		filename := issue.Key + "_" + path.Base(attachment.Filename)
+
+		hash := sha256.Sum256(content)
+		files = append(files, &File{
+			Path:        filename,
+			Content:     content,
+			Hash:        base64.StdEncoding.EncodeToString(hash[:]),
+			Modified:    time.Now(),
+			Size:        int64(len(content)),
+			Source:      "jira",
+			KnowledgeID: knowledgeID,
+			ContentType: attachment.MimeType,
+		})
+	}
+
+	return files
+}
+
+// downloadAttachment downloads the content of an attachment via its content URL. The size cap
+// is enforced while reading too, in case the reported size is wrong.
+func (j *JiraAdapter) downloadAttachment(ctx context.Context, attachment JiraAttachment) ([]byte, error) {
+	if attachment.Content == "" {
+		return nil, fmt.Errorf("attachment %s has no content URL", attachment.ID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", attachment.Content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set authentication
+	j.authenticate(req)
+
+	j.log().Debugf("Downloading attachment: %s", attachment.Filename)
+
+	resp, err := j.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d: response body omitted", resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if j.config.MaxAttachmentSizeBytes > 0 {
+		body = io.LimitReader(resp.Body, j.config.MaxAttachmentSizeBytes+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if j.exceedsAttachmentSize(int64(len(content))) {
+		return nil, fmt.Errorf("attachment exceeds limit of %d bytes", j.config.MaxAttachmentSizeBytes)
+	}
+	return content, nil
+}
+
+// exceedsAttachmentSize reports whether an attachment of this size is above the configured cap
+func (j *JiraAdapter) exceedsAttachmentSize(size int64) bool {
+	return j.config.MaxAttachmentSizeBytes > 0 && size > j.config.MaxAttachmentSizeBytes
+}
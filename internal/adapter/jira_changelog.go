@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// filterHistories returns an issue's history entries oldest first, keeping only the most
+// recent changelog_limit entries. Entries whose time cannot be parsed keep their position.
+func (j *JiraAdapter) filterHistories(histories []JiraIssueHistory) []JiraIssueHistory {
+	sorted := append([]JiraIssueHistory(nil), histories...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		createdA, errA := time.Parse(jiraTimeLayout, sorted[a].Created)
+		createdB, errB := time.Parse(jiraTimeLayout, sorted[b].Created)
+		return errA == nil && errB == nil && createdA.Before(createdB)
+	})
+
+	if j.config.ChangelogLimit > 0 && len(sorted) > j.config.ChangelogLimit {
+		sorted = sorted[len(sorted)-j.config.ChangelogLimit:]
+	}
+	return sorted
+}
+
+// historyMarkdown renders an issue's changelog as a History section, one line per changed
+// field, or returns "" if the changelog wasn't requested or is empty
+func (j *JiraAdapter) historyMarkdown(changelog JiraIssueChangelog) string {
+	if !j.config.IncludeChangelog || len(changelog.Histories) == 0 {
+		return ""
+	}
+
+	var markdown strings.Builder
+	markdown.WriteString("\n## History\n")
+	for _, history := range j.filterHistories(changelog.Histories) {
+		for _, item := range history.Items {
+			markdown.WriteString(fmt.Sprintf("- %s (%s): %s: %s → %s\n",
+				history.Author.DisplayName, formatJiraDate(history.Created), item.Field,
+				historyValue(item.FromString, item.From), historyValue(item.ToString, item.To)))
+		}
+	}
+	return markdown.String()
+}
+
+// historyValue returns the display form of a changed field's value, falling back to its raw
+// value and to "(none)" when the field was empty
+func historyValue(display, raw string) string {
+	switch {
+	case display != "":
+		return display
+	case raw != "":
+		return raw
+	default:
+		return "(none)"
+	}
+}
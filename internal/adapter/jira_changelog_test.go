@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJiraAdapter_FetchFiles_Changelog(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  bool
+		limit    int
+		expected []string
+		absent   []string
+	}{
+		{
+			name:    "disabled",
+			include: false,
+			absent:  []string{"## History", "status:"},
+		},
+		{
+			name:    "enabled",
+			include: true,
+			expected: []string{
+				"\n## History\n",
+				"- Jane Doe (2025-02-19 17:07): status: To Do → In Progress\n",
+				"- John Roe (2025-02-20 08:15): assignee: (none) → Jane Doe\n",
+				"- John Roe (2025-02-20 08:15): status: In Progress → Done\n",
+			},
+		},
+		{
+			name:     "limited to the most recent entry",
+			include:  true,
+			limit:    1,
+			expected: []string{"assignee: (none) → Jane Doe", "status: In Progress → Done"},
+			absent:   []string{"status: To Do → In Progress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/rest/api/3/search/jql":
+					w.Write([]byte(`{"issues": [{"id": "10001"}], "isLast": true}`))
+				case "/rest/api/3/issue/10001":
+					if strings.Contains(r.URL.Query().Get("expand"), "changelog") != tt.include {
+						t.Errorf("Unexpected expand parameter %q", r.URL.Query().Get("expand"))
+					}
+					// Histories out of order, to check they are rendered oldest first
+					w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Broken build"},
+						"changelog": {"histories": [
+							{"id": "2", "author": {"displayName": "John Roe"}, "created": "2025-02-20T08:15:00.000+0100", "items": [
+								{"field": "assignee", "to": "abc123", "toString": "Jane Doe"},
+								{"field": "status", "fromString": "In Progress", "toString": "Done"}
+							]},
+							{"id": "1", "author": {"displayName": "Jane Doe"}, "created": "2025-02-19T17:07:41.093+0100", "items": [
+								{"field": "status", "fromString": "To Do", "toString": "In Progress"}
+							]}
+						]}}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			adapter := newTestJiraAdapter(t, server.URL, false)
+			adapter.config.IncludeChangelog = tt.include
+			adapter.config.ChangelogLimit = tt.limit
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(files))
+			}
+			content := string(files[0].Content)
+
+			assertInOrder(t, content, tt.expected...)
+			for _, absent := range tt.absent {
+				if strings.Contains(content, absent) {
+					t.Errorf("Expected content not to contain %q, got:\n%s", absent, content)
+				}
+			}
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-
-	"github.com/sirupsen/logrus"
+	"time"
 )
 
+// jiraTimeLayout is the timestamp format used by the Jira REST API (e.g. "2025-02-19T17:07:41.093+0100")
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
 // CommentData holds the extracted comment data we want
 type CommentData struct {
 	RenderedBody string `json:"renderedBody"`
@@ -28,12 +30,12 @@ func (j *JiraAdapter) fetchComment(ctx context.Context, commentURL string) (*Com
 	}
 
 	// Set authentication
-	req.SetBasicAuth(j.config.Username, j.config.APIKey)
+	j.authenticate(req)
 	req.Header.Set("Accept", "application/json")
 
-	logrus.Debugf("Jira comment API URL: %s", url)
+	j.log().Debugf("Jira comment API URL: %s", url)
 
-	resp, err := j.client.Do(req)
+	resp, err := j.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -66,28 +68,25 @@ func (j *JiraAdapter) fetchComment(ctx context.Context, commentURL string) (*Com
 	}, nil
 }
 
-// fetchCommentsForIssue fetches all comments for a specific issue and returns only the renderedBody and author displayName
-func (j *JiraAdapter) fetchCommentsForIssue(ctx context.Context, issueID string) ([]CommentData, error) {
+// fetchCommentsForIssue converts the comments of an issue to markdown. Comment bodies are
+// converted from their ADF structure, unless rendered comments are configured, in which case
+// each comment's rendered HTML is fetched with an extra request.
+func (j *JiraAdapter) fetchCommentsForIssue(ctx context.Context, issue JiraIssue) ([]CommentData, error) {
 	var comments []CommentData
 
-	// First fetch the issue to get the comments
-	issue, err := j.fetchIssue(ctx, issueID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch issue %s: %w", issueID, err)
-	}
-
-	// Extract comments from the issue
 	for _, comment := range issue.Fields.Comment.Comments {
-		// Extract rendered body from the comment's body field
-		fetchedComment, err2 := j.fetchComment(ctx, comment.Self)
-		if err2 != nil {
-			return comments, fmt.Errorf("failed to Fetch Comment %w", err)
+		body := adfToMarkdown(comment.Body)
+		if j.config.UseRenderedComments {
+			fetchedComment, err := j.fetchComment(ctx, comment.Self)
+			if err != nil {
+				return comments, fmt.Errorf("failed to fetch comment %s: %w", comment.ID, err)
+			}
+			body = j.HtmlToMarkdown(fetchedComment.RenderedBody)
 		}
 
-		renderedBody := j.HtmlToMarkdown(fetchedComment.RenderedBody)
-		logrus.Debugf("FetchedComment: %s,renderedBody %s ", fetchedComment, renderedBody)
+		j.log().Debugf("Comment %s of issue %s: %s", comment.ID, issue.Key, body)
 		comments = append(comments, CommentData{
-			RenderedBody: j.HtmlToMarkdown(renderedBody),
+			RenderedBody: body,
 			AuthorName:   comment.Author.DisplayName,
 			Created:      comment.Created,
 		})
@@ -95,3 +94,30 @@ func (j *JiraAdapter) fetchCommentsForIssue(ctx context.Context, issueID string)
 
 	return comments, nil
 }
+
+// filterComments applies the configured comment cutoff and limit. Comments are returned
+// oldest first by Jira, so the limit keeps the most recent ones. Comments whose creation
+// time cannot be parsed are kept rather than silently dropped.
+func (j *JiraAdapter) filterComments(comments []CommentData, now time.Time) []CommentData {
+	if j.config.OnlyCommentsSince > 0 {
+		cutoff := now.Add(-j.config.OnlyCommentsSince)
+		var recent []CommentData
+		for _, comment := range comments {
+			created, err := time.Parse(jiraTimeLayout, comment.Created)
+			if err != nil {
+				j.log().Debugf("Failed to parse Jira comment timestamp %q: %v", comment.Created, err)
+				recent = append(recent, comment)
+				continue
+			}
+			if !created.Before(cutoff) {
+				recent = append(recent, comment)
+			}
+		}
+		comments = recent
+	}
+
+	if j.config.CommentLimit > 0 && len(comments) > j.config.CommentLimit {
+		comments = comments[len(comments)-j.config.CommentLimit:]
+	}
+	return comments
+}
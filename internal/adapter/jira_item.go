@@ -0,0 +1,38 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FetchItem fetches a single issue by key, e.g. one named by a Jira webhook. The project is
+// taken from the key; issues of projects mapped with a custom JQL query may not match it, so
+// whether they are synced is only known from a full fetch.
+func (j *JiraAdapter) FetchItem(ctx context.Context, issueKey string) ([]*File, error) {
+	projectKey, _, _ := strings.Cut(issueKey, "-")
+	knowledgeID, ok := j.mappings[projectKey]
+	_, custom := j.queries[projectKey]
+	switch {
+	case custom || (!ok && len(j.queries) > 0):
+		return nil, ErrUnknownItem
+	case !ok:
+		j.log().Debugf("Ignoring Jira issue %s of a project that isn't synced", issueKey)
+		return nil, nil
+	}
+
+	issue, err := j.fetchIssue(ctx, issueKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s: %w", issueKey, err)
+	}
+	file, err := j.processIssue(ctx, issue, knowledgeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process issue %s: %w", issueKey, err)
+	}
+
+	files := []*File{file}
+	if j.config.IncludeAttachments {
+		files = append(files, j.processIssueAttachments(ctx, issue, knowledgeID)...)
+	}
+	return files, nil
+}
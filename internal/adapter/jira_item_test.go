@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJiraAdapter_FetchItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		jql       string
+		issueKey  string
+		wantFiles int
+		wantErr   error
+	}{
+		{name: "issue of a mapped project", issueKey: "PROJ-1", wantFiles: 1},
+		{name: "issue of another project", issueKey: "OTHER-1"},
+		{name: "issue of a project with a custom query", jql: "project = PROJ AND labels = docs", issueKey: "PROJ-1", wantErr: ErrUnknownItem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = append(requested, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/rest/api/3/issue/PROJ-1":
+					w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Broken build"}}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			adapter := newTestJiraAdapterWithJQL(t, server.URL, false, tt.jql)
+			files, err := adapter.FetchItem(context.Background(), tt.issueKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchItem() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(files) != tt.wantFiles {
+				t.Fatalf("Expected %d files, got %d", tt.wantFiles, len(files))
+			}
+			if tt.wantFiles == 0 {
+				if len(requested) != 0 {
+					t.Errorf("Expected no requests, got %v", requested)
+				}
+				return
+			}
+			if files[0].KnowledgeID != "knowledge-id" || !strings.Contains(string(files[0].Content), "Broken build") {
+				t.Errorf("Unexpected file %s for knowledge %s: %s", files[0].Path, files[0].KnowledgeID, files[0].Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,514 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func newTestJiraAdapter(t *testing.T, baseURL string, incremental bool) *JiraAdapter {
+	t.Helper()
+	return newTestJiraAdapterWithJQL(t, baseURL, incremental, "")
+}
+
+func newTestJiraAdapterWithJQL(t *testing.T, baseURL string, incremental bool, jql string) *JiraAdapter {
+	t.Helper()
+
+	adapter, err := NewJiraAdapter(config.JiraConfig{
+		BaseURL:         baseURL,
+		Username:        "test@example.com",
+		APIKey:          "test-key",
+		IncrementalSync: incremental,
+		ProjectMappings: []config.JiraProjectMapping{
+			{ProjectKey: "PROJ", KnowledgeID: "knowledge-id", JQL: jql},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Jira adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestJiraAdapter_buildJQL(t *testing.T) {
+	// The cursor less the overlap, in the Jira user's time zone, is 2024-03-05 16:02:59
+	updatedSince := time.Date(2024, 3, 5, 14, 7, 59, 0, time.UTC)
+	timeZone := time.FixedZone("UTC+2", 2*60*60)
+
+	tests := []struct {
+		name            string
+		jql             string
+		incremental     bool
+		initialSyncDone bool
+		updatedSince    time.Time
+		expected        string
+	}{
+		{
+			name:            "full sync when incremental disabled",
+			incremental:     false,
+			initialSyncDone: true,
+			updatedSince:    updatedSince,
+			expected:        "project = 'PROJ'",
+		},
+		{
+			name:            "full sync on first run",
+			incremental:     true,
+			initialSyncDone: false,
+			updatedSince:    updatedSince,
+			expected:        "project = 'PROJ'",
+		},
+		{
+			name:            "full sync without a complete fetch",
+			incremental:     true,
+			initialSyncDone: true,
+			updatedSince:    time.Time{},
+			expected:        "project = 'PROJ'",
+		},
+		{
+			name:            "incremental sync after first run",
+			incremental:     true,
+			initialSyncDone: true,
+			updatedSince:    updatedSince,
+			expected:        `project = 'PROJ' AND updated >= "2024-03-05 16:02"`,
+		},
+		{
+			name:            "custom JQL used verbatim",
+			jql:             "project = PROJ AND type = Bug OR labels = team-a",
+			incremental:     false,
+			initialSyncDone: true,
+			updatedSince:    updatedSince,
+			expected:        "project = PROJ AND type = Bug OR labels = team-a",
+		},
+		{
+			name:            "custom JQL with incremental sync",
+			jql:             "project = PROJ AND type = Bug OR labels = team-a ORDER BY created DESC",
+			incremental:     true,
+			initialSyncDone: true,
+			updatedSince:    updatedSince,
+			expected:        `(project = PROJ AND type = Bug OR labels = team-a) AND updated >= "2024-03-05 16:02" ORDER BY created DESC`,
+		},
+		{
+			name:            "custom ORDER BY only with incremental sync",
+			jql:             "order by key",
+			incremental:     true,
+			initialSyncDone: true,
+			updatedSince:    updatedSince,
+			expected:        `updated >= "2024-03-05 16:02" order by key`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newTestJiraAdapterWithJQL(t, "https://test.atlassian.net", tt.incremental, tt.jql)
+			adapter.initialSyncDone = tt.initialSyncDone
+			adapter.updatedSince = tt.updatedSince
+			adapter.timeZone = timeZone
+
+			if got := adapter.buildJQL("PROJ"); got != tt.expected {
+				t.Errorf("buildJQL() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJiraAdapter_FetchFiles_Incremental(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+
+	var queries []string
+	issueFails := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/myself":
+			w.Write([]byte(`{"accountId": "1", "timeZone": "Asia/Tokyo"}`))
+		case "/rest/api/3/search/jql":
+			queries = append(queries, r.URL.Query().Get("jql"))
+			w.Write([]byte(`{"issues": [{"id": "10001"}], "isLast": true}`))
+		case "/rest/api/3/issue/10001":
+			if issueFails {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Broken build"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestJiraAdapter(t, server.URL, true)
+	fetch := func() {
+		t.Helper()
+		if _, err := adapter.FetchFiles(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The sync manager sets the last sync after uploading, which must not move the cursor
+		adapter.SetLastSync(time.Now().Add(time.Hour))
+	}
+
+	// The issue fails to fetch, so the next run is full again
+	fetch()
+	issueFails = false
+	start := time.Now()
+	fetch()
+	fetch()
+	adapter.RequestFullFetch()
+	fetch()
+
+	if len(queries) != 4 {
+		t.Fatalf("Expected 4 search requests, got %d", len(queries))
+	}
+	if queries[0] != "project = 'PROJ'" || queries[1] != "project = 'PROJ'" {
+		t.Errorf("Expected full JQL until a fetch completes, got %q", queries[:2])
+	}
+	// The cursor is the start of the complete fetch less the overlap, in the user's time zone
+	var expected []string
+	for _, at := range []time.Time{start, start.Add(time.Second)} {
+		since := at.Add(-jiraUpdatedOverlap).In(tokyo).Format("2006-01-02 15:04")
+		expected = append(expected, `project = 'PROJ' AND updated >= "`+since+`"`)
+	}
+	if queries[2] != expected[0] && queries[2] != expected[1] {
+		t.Errorf("Expected incremental JQL %q on the run after a complete fetch, got %q", expected[0], queries[2])
+	}
+	if queries[3] != "project = 'PROJ'" {
+		t.Errorf("Expected full JQL after a full fetch was requested, got %q", queries[3])
+	}
+}
+
+func TestJiraAdapter_FetchFiles_Server(t *testing.T) {
+	var startAts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			startAt := r.URL.Query().Get("startAt")
+			startAts = append(startAts, startAt)
+			if startAt == "0" {
+				w.Write([]byte(`{"issues": [{"id": "10001"}], "startAt": 0, "total": 2}`))
+				return
+			}
+			w.Write([]byte(`{"issues": [{"id": "10002"}], "startAt": 1, "total": 2}`))
+		case "/rest/api/2/issue/10001", "/rest/api/2/issue/10002":
+			id := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+			w.Write([]byte(`{"id": "` + id + `", "key": "PROJ-` + id + `", "fields": {"summary": "Server issue", "comment": {"comments": [{
+				"id": "1",
+				"author": {"displayName": "Jane Doe"},
+				"created": "2025-02-19T17:07:41.093+0100",
+				"body": "Looks *good*"
+			}]}}}`))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestJiraAdapter(t, server.URL, false)
+	adapter.config.Deployment = "server"
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(startAts) != 2 || startAts[0] != "0" || startAts[1] != "1" {
+		t.Errorf("Expected offset paging with startAt 0 and 1, got %v", startAts)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if expected := "Jane Doe (2025-02-19 17:07): Looks *good*"; !strings.Contains(string(files[0].Content), expected) {
+		t.Errorf("Expected content to contain the wiki markup comment %q, got:\n%s", expected, files[0].Content)
+	}
+}
+
+func TestJiraAdapter_KnowledgeIDs(t *testing.T) {
+	adapter := newTestJiraAdapter(t, "https://jira.example.com", true)
+
+	knowledgeIDs := adapter.KnowledgeIDs()
+	if len(knowledgeIDs) != 1 || knowledgeIDs[0] != "knowledge-id" {
+		t.Errorf("KnowledgeIDs() = %v, want [knowledge-id]", knowledgeIDs)
+	}
+	// Reporting knowledge IDs must not count as the initial full fetch
+	if adapter.initialSyncDone {
+		t.Error("Expected KnowledgeIDs() not to mark the initial sync as done")
+	}
+}
+
+func TestNewJiraAdapter_BlankJQL(t *testing.T) {
+	_, err := NewJiraAdapter(config.JiraConfig{
+		BaseURL:  "https://test.atlassian.net",
+		Username: "test@example.com",
+		APIKey:   "test-key",
+		ProjectMappings: []config.JiraProjectMapping{
+			{ProjectKey: "PROJ", KnowledgeID: "knowledge-id", JQL: "   "},
+		},
+	})
+	if err == nil {
+		t.Error("Expected error for blank JQL but got none")
+	}
+}
+
+func TestJiraAdapter_FetchFiles_CustomJQL(t *testing.T) {
+	jql := `project = PROJ AND status != Done AND assignee in membersOf("team-a") ORDER BY updated DESC`
+
+	var rawQuery, receivedJQL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			rawQuery = r.URL.RawQuery
+			receivedJQL = r.URL.Query().Get("jql")
+			w.Write([]byte(`{"issues": [{"id": "10001"}], "isLast": true}`))
+		case "/rest/api/3/issue/10001":
+			w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Broken build"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestJiraAdapterWithJQL(t, server.URL, false, "  "+jql+"\n")
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if receivedJQL != jql {
+		t.Errorf("Expected JQL %q to be sent unchanged, got %q", jql, receivedJQL)
+	}
+	if !strings.Contains(rawQuery, "jql="+url.QueryEscape(jql)) {
+		t.Errorf("Expected URL-encoded JQL in query string, got %q", rawQuery)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	if files[0].KnowledgeID != "knowledge-id" {
+		t.Errorf("Expected knowledge ID 'knowledge-id', got %q", files[0].KnowledgeID)
+	}
+}
+
+func TestJiraAdapter_filterComments(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	comments := []CommentData{
+		{AuthorName: "alice", Created: "2024-01-15T09:30:00.000+0000"},
+		{AuthorName: "bob", Created: "2025-02-10T17:07:41.093+0100"},
+		{AuthorName: "carol", Created: "not-a-timestamp"},
+		{AuthorName: "dave", Created: "2025-02-28T08:00:00.000-0500"},
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		since    time.Duration
+		expected []string
+	}{
+		{"no filtering", 0, 0, []string{"alice", "bob", "carol", "dave"}},
+		{"limit keeps most recent", 2, 0, []string{"carol", "dave"}},
+		{"limit larger than comments", 10, 0, []string{"alice", "bob", "carol", "dave"}},
+		{"date cutoff keeps unparseable", 0, 30 * 24 * time.Hour, []string{"bob", "carol", "dave"}},
+		{"date cutoff", 0, 7 * 24 * time.Hour, []string{"carol", "dave"}},
+		{"cutoff then limit", 1, 30 * 24 * time.Hour, []string{"dave"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &JiraAdapter{config: config.JiraConfig{CommentLimit: tt.limit, OnlyCommentsSince: tt.since}}
+
+			var authors []string
+			for _, comment := range adapter.filterComments(comments, now) {
+				authors = append(authors, comment.AuthorName)
+			}
+			if strings.Join(authors, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("filterComments() = %v, want %v", authors, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJiraAdapter_FetchFiles_IssueMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			w.Write([]byte(`{"issues": [{"id": "10001"}, {"id": "10002"}], "isLast": true}`))
+		case "/rest/api/3/issue/10001":
+			w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {
+				"summary": "Broken build",
+				"assignee": {"displayName": "Jane Doe"},
+				"priority": {"name": "High"},
+				"resolution": {"name": "Fixed"},
+				"labels": ["ci", "regression"],
+				"components": [{"name": "Backend"}, {"name": "Pipeline"}],
+				"created": "2025-02-19T17:07:41.093+0100",
+				"updated": "2025-02-20T08:15:00.000+0100"
+			}}`))
+		case "/rest/api/3/issue/10002":
+			w.Write([]byte(`{"id": "10002", "key": "PROJ-2", "fields": {"summary": "Unassigned task"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestJiraAdapter(t, server.URL, false)
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	contents := make(map[string]string)
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+
+	for _, expected := range []string{
+		"Assignee: Jane Doe\n",
+		"Priority: High\n",
+		"Resolution: Fixed\n",
+		"Labels: ci, regression\n",
+		"Components: Backend, Pipeline\n",
+		"Created: 2025-02-19 17:07\n",
+		"Updated: 2025-02-20 08:15\n",
+	} {
+		if !strings.Contains(contents["PROJ-1.md"], expected) {
+			t.Errorf("Expected PROJ-1.md to contain %q, got:\n%s", expected, contents["PROJ-1.md"])
+		}
+	}
+
+	for _, absent := range []string{"Assignee:", "Priority:", "Resolution:", "Labels:", "Components:", "Created:", "Updated:"} {
+		if strings.Contains(contents["PROJ-2.md"], absent) {
+			t.Errorf("Expected PROJ-2.md not to contain %q, got:\n%s", absent, contents["PROJ-2.md"])
+		}
+	}
+}
+
+func TestJiraAdapter_FetchFiles_Attachments(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			w.Write([]byte(`{"issues": [{"id": "10001"}], "isLast": true}`))
+		case "/rest/api/3/issue/10001":
+			if !strings.Contains(r.URL.Query().Get("fields"), "attachment") {
+				t.Errorf("Expected the issue request to include attachments, got fields %q", r.URL.Query().Get("fields"))
+			}
+			w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Runbook", "attachment": [
+				{"id": "1", "filename": "runbook.md", "size": 9, "mimeType": "text/markdown", "content": "` + server.URL + `/attachment/content/1"},
+				{"id": "2", "filename": "diagram.png", "size": 4, "mimeType": "image/png", "content": "` + server.URL + `/attachment/content/2"},
+				{"id": "3", "filename": "dump.log", "size": 4096, "mimeType": "text/plain", "content": "` + server.URL + `/attachment/content/3"},
+				{"id": "4", "filename": "notes.txt", "size": 5, "mimeType": "text/plain", "content": "` + server.URL + `/attachment/content/4"}
+			]}}`))
+		case "/attachment/content/1":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "test@example.com" || pass != "test-key" {
+				t.Errorf("Expected an authenticated attachment download")
+			}
+			w.Write([]byte("# Runbook"))
+		case "/attachment/content/4":
+			// The reported size is wrong, so the cap is enforced while downloading
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := newTestJiraAdapter(t, server.URL, false)
+	adapter.config.IncludeAttachments = true
+	adapter.config.MaxAttachmentSizeBytes = 1024
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	paths := make(map[string]*File)
+	for _, file := range files {
+		paths[file.Path] = file
+	}
+	if len(files) != 2 || paths["PROJ-1.md"] == nil || paths["PROJ-1_runbook.md"] == nil {
+		t.Fatalf("Expected the issue and its text attachment, got %v", paths)
+	}
+	attachment := paths["PROJ-1_runbook.md"]
+	if string(attachment.Content) != "# Runbook" || attachment.ContentType != "text/markdown" || attachment.KnowledgeID != "knowledge-id" {
+		t.Errorf("Unexpected attachment file: %+v", attachment)
+	}
+}
+
+func TestJiraAdapter_FetchFiles_ADFComments(t *testing.T) {
+	tests := []struct {
+		name            string
+		renderedComment bool
+		expected        string
+	}{
+		{name: "converted from ADF", renderedComment: false, expected: "Jane Doe (2025-02-19 17:07): Looks __good__"},
+		{name: "rendered HTML fallback", renderedComment: true, expected: "Jane Doe (2025-02-19 17:07): Rendered __comment__"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRequests := 0
+			var serverURL string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/rest/api/3/search/jql":
+					w.Write([]byte(`{"issues": [{"id": "10001"}], "isLast": true}`))
+				case "/rest/api/3/issue/10001":
+					w.Write([]byte(`{"id": "10001", "key": "PROJ-1", "fields": {"summary": "Review", "comment": {"comments": [{
+						"self": "` + serverURL + `/rest/api/3/issue/10001/comment/1",
+						"id": "1",
+						"author": {"displayName": "Jane Doe"},
+						"created": "2025-02-19T17:07:41.093+0100",
+						"body": {"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [
+							{"type": "text", "text": "Looks "}, {"type": "text", "text": "good", "marks": [{"type": "strong"}]}
+						]}]}
+					}]}}}`))
+				case "/rest/api/3/issue/10001/comment/1":
+					commentRequests++
+					w.Write([]byte(`{"id": "1", "renderedBody": "<p>Rendered <strong>comment</strong></p>"}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+			serverURL = server.URL
+
+			adapter := newTestJiraAdapter(t, server.URL, false)
+			adapter.config.UseRenderedComments = tt.renderedComment
+
+			files, err := adapter.FetchFiles(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(files))
+			}
+			if !strings.Contains(string(files[0].Content), tt.expected) {
+				t.Errorf("Expected content to contain %q, got:\n%s", tt.expected, files[0].Content)
+			}
+
+			expectedRequests := 0
+			if tt.renderedComment {
+				expectedRequests = 1
+			}
+			if commentRequests != expectedRequests {
+				t.Errorf("Expected %d comment requests, got %d", expectedRequests, commentRequests)
+			}
+		})
+	}
+}
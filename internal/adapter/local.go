@@ -27,17 +27,34 @@ import (
 	"time"
 
 	"github.com/openwebui-content-sync/internal/config"
-	"github.com/sirupsen/logrus"
+	"github.com/openwebui-content-sync/internal/logging"
 )
 
 // LocalFolderAdapter implements the Adapter interface for local folders
 type LocalFolderAdapter struct {
-	config   config.LocalFolderConfig
-	lastSync time.Time
-	folders  []string
-	mappings map[string]string // folder_path -> knowledge_id mapping
+	config     config.LocalFolderConfig
+	lastSync   time.Time
+	folders    []string
+	mappings   map[string]string                   // folder_path -> knowledge_id mapping
+	debounce   time.Duration                       // delay before a watched change is synced
+	incomplete bool                                // whether the last fetch skipped files after an error
+	stats      map[string]map[string]localFileStat // folder -> relative path -> file as last read, to skip unchanged files
+	extensions extensionFilter                     // include/exclude lists of file extensions
+	logger     logging.Logger                      // nil to log to the global logrus logger
 }
 
+// localFileStat is the modification time, size, hash and knowledge ID of a local file when it
+// was last read
+type localFileStat struct {
+	modified    time.Time
+	size        int64
+	hash        string
+	knowledgeID string // set when the file's front matter overrides the folder's knowledge ID
+}
+
+// readLocalFile reads local files; tests replace it to count reads
+var readLocalFile = os.ReadFile
+
 // NewLocalFolderAdapter creates a new local folder adapter
 func NewLocalFolderAdapter(cfg config.LocalFolderConfig) (*LocalFolderAdapter, error) {
 	if !cfg.Enabled {
@@ -65,10 +82,12 @@ func NewLocalFolderAdapter(cfg config.LocalFolderConfig) (*LocalFolderAdapter, e
 	}
 
 	return &LocalFolderAdapter{
-		config:   cfg,
-		folders:  folders,
-		mappings: mappings,
-		lastSync: time.Now().Add(-24 * time.Hour), // Default to 24 hours ago
+		config:     cfg,
+		folders:    folders,
+		mappings:   mappings,
+		lastSync:   time.Now().Add(-24 * time.Hour), // Default to 24 hours ago
+		debounce:   defaultWatchDebounce,
+		extensions: newExtensionFilter(cfg.IncludeExtensions, cfg.ExcludeExtensions),
 	}, nil
 }
 
@@ -77,87 +96,90 @@ func (l *LocalFolderAdapter) Name() string {
 	return "local"
 }
 
+// SetLogger sets the logger the adapter writes to
+func (l *LocalFolderAdapter) SetLogger(logger logging.Logger) {
+	l.logger = logger
+}
+
+// log returns the adapter's logger
+func (l *LocalFolderAdapter) log() logging.Logger {
+	if l.logger == nil {
+		return logging.Default()
+	}
+	return l.logger
+}
+
 // FetchFiles retrieves files from local folders
 func (l *LocalFolderAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	var files []*File
+	l.incomplete = false
 
 	for _, folder := range l.folders {
-		logrus.Debugf("Fetching files from local folder: %s", folder)
+		l.log().Debugf("Fetching files from local folder: %s", folder)
 		knowledgeID := l.mappings[folder]
 		folderFiles, err := l.fetchFolderFiles(ctx, folder, knowledgeID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch files from folder %s: %w", folder, err)
 		}
-		logrus.Debugf("Found %d files in folder %s (knowledge_id: %s)", len(folderFiles), folder, knowledgeID)
+		l.log().Debugf("Found %d files in folder %s (knowledge_id: %s)", len(folderFiles), folder, knowledgeID)
 		files = append(files, folderFiles...)
 	}
 
-	logrus.Debugf("Total files fetched: %d", len(files))
+	l.log().Debugf("Total files fetched: %d", len(files))
 	return files, nil
 }
 
 // fetchFolderFiles fetches files from a specific folder recursively
 func (l *LocalFolderAdapter) fetchFolderFiles(ctx context.Context, folderPath string, knowledgeID string) ([]*File, error) {
 	var files []*File
+	ignore := newIgnoreMatcher(folderPath, l.config.UseGitignore)
+
+	// Files whose modification time and size match the previous fetch aren't read again
+	previous := l.stats[folderPath]
+	stats := make(map[string]localFileStat)
 
 	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			logrus.Warnf("Error accessing path %s: %v", path, err)
+			l.log().Warnf("Error accessing path %s: %v", path, err)
+			l.incomplete = true
 			return nil // Continue walking
 		}
 
-		// Skip directories
-		if d.IsDir() {
-			return nil
-		}
-
-		// Skip hidden files and common ignore patterns
-		baseName := filepath.Base(path)
-		if strings.HasPrefix(baseName, ".") || l.shouldIgnoreFile(baseName) {
-			return nil
-		}
-
-		// Read file content
-		content, err := os.ReadFile(path)
-		if err != nil {
-			logrus.Warnf("Failed to read file %s: %v", path, err)
+		// Skip patterns from .owuisyncignore (and .gitignore) files
+		relPath, err := filepath.Rel(folderPath, path)
+		if err == nil && ignore.Ignored(relPath, d.IsDir()) {
+			l.log().Debugf("Skipping ignored path: %s", path)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Skip binary files (basic check)
-		if l.isBinaryFile(content) {
-			logrus.Debugf("Skipping binary file: %s", path)
+		// Skip directories
+		if d.IsDir() {
 			return nil
 		}
 
-		// Get file info
-		info, err := d.Info()
-		if err != nil {
-			logrus.Warnf("Failed to get file info for %s: %v", path, err)
+		if file := unchangedLocalFile(folderPath, path, relPath, knowledgeID, previous); file != nil {
+			stats[relPath] = previous[relPath]
+			files = append(files, file)
 			return nil
 		}
 
-		// Calculate relative path from the folder root
-		relPath, err := filepath.Rel(folderPath, path)
+		file, err := l.loadFile(folderPath, path, knowledgeID)
 		if err != nil {
-			logrus.Warnf("Failed to calculate relative path for %s: %v", path, err)
+			l.log().Warnf("Skipping file: %v", err)
+			l.incomplete = true
 			return nil
 		}
-
-		// Calculate hash
-		hash := fmt.Sprintf("%x", sha256.Sum256(content))
-
-		file := &File{
-			Path:        relPath,
-			Content:     content,
-			Hash:        hash,
-			Modified:    info.ModTime(),
-			Size:        info.Size(),
-			Source:      fmt.Sprintf("local:%s", folderPath),
-			KnowledgeID: knowledgeID,
+		if file != nil {
+			stat := localFileStat{modified: file.Modified, size: file.Size, hash: file.Hash}
+			if file.KnowledgeID != knowledgeID {
+				stat.knowledgeID = file.KnowledgeID
+			}
+			stats[file.Path] = stat
+			files = append(files, file)
 		}
-
-		files = append(files, file)
 		return nil
 	})
 
@@ -165,9 +187,119 @@ func (l *LocalFolderAdapter) fetchFolderFiles(ctx context.Context, folderPath st
 		return nil, fmt.Errorf("failed to walk directory %s: %w", folderPath, err)
 	}
 
+	if l.stats == nil {
+		l.stats = make(map[string]map[string]localFileStat)
+	}
+	l.stats[folderPath] = stats
 	return files, nil
 }
 
+// unchangedLocalFile returns a File marked Unchanged, without content, when the file at path
+// has the modification time and size it had when it was last read. It returns nil when the
+// file has to be read.
+func unchangedLocalFile(folderPath, path, relPath, knowledgeID string, previous map[string]localFileStat) *File {
+	stat, ok := previous[relPath]
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.ModTime().Equal(stat.modified) || info.Size() != stat.size {
+		return nil
+	}
+	if stat.knowledgeID != "" {
+		knowledgeID = stat.knowledgeID
+	}
+	return &File{
+		Path:        relPath,
+		Hash:        stat.hash,
+		Modified:    stat.modified,
+		Size:        stat.size,
+		Source:      fmt.Sprintf("local:%s", folderPath),
+		KnowledgeID: knowledgeID,
+		Unchanged:   true,
+	}
+}
+
+// LoadContent reads a file that a fetch reported as unchanged without reading it. Reading a
+// local file doesn't need the context.
+func (l *LocalFolderAdapter) LoadContent(ctx context.Context, file *File) error {
+	folderPath := strings.TrimPrefix(file.Source, "local:")
+	loaded, err := l.loadFile(folderPath, filepath.Join(folderPath, file.Path), file.KnowledgeID)
+	if err != nil {
+		return err
+	}
+	if loaded == nil {
+		return fmt.Errorf("file %s is no longer synced", file.Path)
+	}
+	file.Content = loaded.Content
+	file.Hash = loaded.Hash
+	file.Modified = loaded.Modified
+	file.Size = loaded.Size
+	file.Unchanged = false
+	return nil
+}
+
+// loadFile reads a file below folderPath into a File. It returns nil without an error if the
+// file should be skipped.
+func (l *LocalFolderAdapter) loadFile(folderPath, path, knowledgeID string) (*File, error) {
+	// Skip hidden files and common ignore patterns
+	baseName := filepath.Base(path)
+	if strings.HasPrefix(baseName, ".") || l.shouldIgnoreFile(baseName) {
+		return nil, nil
+	}
+	if !l.extensions.Allowed(baseName) {
+		l.log().Debugf("Skipping file by extension: %s", path)
+		return nil, nil
+	}
+
+	// Read file content
+	content, err := readLocalFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	// Skip binary files (basic check)
+	if l.isBinaryFile(content) {
+		l.log().Debugf("Skipping binary file: %s", path)
+		return nil, nil
+	}
+
+	// Get file info
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+
+	// Calculate relative path from the folder root
+	relPath, err := filepath.Rel(folderPath, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate relative path for %s: %w", path, err)
+	}
+
+	// Front matter may route the file to another knowledge base
+	if matter, body, ok := parseFrontMatter(path, content); ok {
+		if matter.KnowledgeID != "" {
+			knowledgeID = matter.KnowledgeID
+		}
+		if l.config.StripFrontMatter {
+			content = body
+		}
+	}
+
+	// Calculate hash
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	return &File{
+		Path:        relPath,
+		Content:     content,
+		Hash:        hash,
+		Modified:    info.ModTime(),
+		Size:        info.Size(),
+		Source:      fmt.Sprintf("local:%s", folderPath),
+		KnowledgeID: knowledgeID,
+	}, nil
+}
+
 // shouldIgnoreFile checks if a file should be ignored based on common patterns
 func (l *LocalFolderAdapter) shouldIgnoreFile(filename string) bool {
 	// Check for hidden files (starting with .)
@@ -237,6 +369,12 @@ func (l *LocalFolderAdapter) isBinaryFile(content []byte) bool {
 	return float64(nonPrintable)/float64(checkLen) > 0.3
 }
 
+// FetchComplete reports whether the last fetch read every file of the mapped folders, so
+// deleted files can be removed from OpenWebUI
+func (l *LocalFolderAdapter) FetchComplete() bool {
+	return !l.incomplete
+}
+
 // GetLastSync returns the last sync time
 func (l *LocalFolderAdapter) GetLastSync() time.Time {
 	return l.lastSync
@@ -0,0 +1,66 @@
+// OpenWebUI Content Sync
+// Copyright (C) 2025  OpenWebUI Content Sync Contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package adapter
+
+import "strings"
+
+// extensionFilter decides by file extension which local files are synced
+type extensionFilter struct {
+	include []string // lowercase extensions with a leading dot; if set, only these are synced
+	exclude []string // lowercase extensions with a leading dot that are never synced
+}
+
+// newExtensionFilter normalizes the configured extensions, so "md", ".md" and ".MD" are the same
+func newExtensionFilter(include, exclude []string) extensionFilter {
+	return extensionFilter{include: normalizeExtensions(include), exclude: normalizeExtensions(exclude)}
+}
+
+// normalizeExtensions lowercases extensions and adds the leading dot where it is missing
+func normalizeExtensions(extensions []string) []string {
+	var normalized []string
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
+// Allowed reports whether a file of that name is synced. Extensions are matched as suffixes, so
+// compound extensions like ".min.js" work; an excluded extension wins over an included one.
+func (f extensionFilter) Allowed(filename string) bool {
+	name := strings.ToLower(filename)
+	for _, ext := range f.exclude {
+		if strings.HasSuffix(name, ext) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, ext := range f.include {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package adapter
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestExtensionFilter_Allowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		allowed []string
+		skipped []string
+	}{
+		{
+			name:    "no lists",
+			allowed: []string{"notes.md", "yarn.lock", "Makefile"},
+		},
+		{
+			name:    "include only",
+			include: []string{"md", ".TXT"},
+			allowed: []string{"notes.md", "README.MD", "todo.txt"},
+			skipped: []string{"yarn.lock", "main.go", "Makefile"},
+		},
+		{
+			name:    "exclude only",
+			exclude: []string{".lock", "map", "min.js"},
+			allowed: []string{"notes.md", "app.js", "Makefile"},
+			skipped: []string{"yarn.lock", "app.js.map", "app.min.js"},
+		},
+		{
+			name:    "exclude wins over include",
+			include: []string{"js", "md"},
+			exclude: []string{"min.js", " "},
+			allowed: []string{"app.js", "notes.md"},
+			skipped: []string{"app.min.js", "styles.css"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newExtensionFilter(tt.include, tt.exclude)
+			for _, name := range tt.allowed {
+				if !filter.Allowed(name) {
+					t.Errorf("Allowed(%q) = false, want true", name)
+				}
+			}
+			for _, name := range tt.skipped {
+				if filter.Allowed(name) {
+					t.Errorf("Allowed(%q) = true, want false", name)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalFolderAdapter_FetchFiles_Extensions(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"README.md":         "# Readme",
+		"docs/guide.md":     "# Guide",
+		"notes.txt":         "notes",
+		"yarn.lock":         "lockfile",
+		"dist/app.min.js":   "minified",
+		"dist/app.js.map":   "{}",
+		"src/app.js":        "console.log('app')",
+		"docs/draft.min.md": "# Draft",
+	})
+
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled:           true,
+		Mappings:          []config.LocalFolderMapping{{FolderPath: root, KnowledgeID: "knowledge-1"}},
+		IncludeExtensions: []string{"md", "js"},
+		ExcludeExtensions: []string{"min.js", ".min.md"},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+	if got, want := strings.Join(paths, ","), "README.md,docs/guide.md,src/app.js"; got != want {
+		t.Errorf("FetchFiles() returned %s, want %s", got, want)
+	}
+}
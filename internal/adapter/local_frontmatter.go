@@ -0,0 +1,64 @@
+// OpenWebUI Content Sync
+// Copyright (C) 2025  OpenWebUI Content Sync Contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package adapter
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// localFrontMatter holds the directives the local folder adapter reads from YAML front matter
+type localFrontMatter struct {
+	KnowledgeID string `yaml:"owui_knowledge_id"` // knowledge base the file is synced to, overriding the folder mapping
+}
+
+// parseFrontMatter splits the YAML front matter, delimited by "---" lines at the start of a
+// Markdown file, from its body. ok is false when the file has no front matter, or it can't be
+// parsed, in which case the file is synced as is.
+func parseFrontMatter(path string, content []byte) (matter localFrontMatter, body []byte, ok bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+	default:
+		return matter, content, false
+	}
+
+	if !bytes.HasPrefix(content, []byte("---\n")) && !bytes.HasPrefix(content, []byte("---\r\n")) {
+		return matter, content, false
+	}
+	rest := content[bytes.IndexByte(content, '\n')+1:]
+
+	// The closing delimiter may also be "...", as in YAML documents
+	for offset := 0; offset < len(rest); {
+		line, next := rest[offset:], len(rest)
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line, next = line[:end], offset+end+1
+		}
+		if delimiter := string(bytes.TrimRight(line, "\r")); delimiter == "---" || delimiter == "..." {
+			if err := yaml.Unmarshal(rest[:offset], &matter); err != nil {
+				logrus.Warnf("Ignoring invalid front matter in %s: %v", path, err)
+				return localFrontMatter{}, content, false
+			}
+			return matter, rest[next:], true
+		}
+		offset = next
+	}
+	return matter, content, false
+}
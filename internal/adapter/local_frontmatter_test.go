@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		content     string
+		ok          bool
+		knowledgeID string
+		body        string
+	}{
+		{
+			name:        "knowledge ID directive",
+			path:        "notes.md",
+			content:     "---\ntitle: Notes\nowui_knowledge_id: team-kb\n---\n# Notes\n",
+			ok:          true,
+			knowledgeID: "team-kb",
+			body:        "# Notes\n",
+		},
+		{
+			name:    "front matter without the directive",
+			path:    "notes.markdown",
+			content: "---\r\ntitle: Notes\r\n...\r\n# Notes\r\n",
+			ok:      true,
+			body:    "# Notes\r\n",
+		},
+		{
+			name:    "no front matter",
+			path:    "notes.md",
+			content: "# Notes\n---\nowui_knowledge_id: team-kb\n---\n",
+			body:    "# Notes\n---\nowui_knowledge_id: team-kb\n---\n",
+		},
+		{
+			name:    "unclosed front matter",
+			path:    "notes.md",
+			content: "---\nowui_knowledge_id: team-kb\n# Notes\n",
+			body:    "---\nowui_knowledge_id: team-kb\n# Notes\n",
+		},
+		{
+			name:    "invalid YAML",
+			path:    "notes.md",
+			content: "---\nowui_knowledge_id: [team-kb\n---\n# Notes\n",
+			body:    "---\nowui_knowledge_id: [team-kb\n---\n# Notes\n",
+		},
+		{
+			name:    "not a Markdown file",
+			path:    "config.yaml",
+			content: "---\nowui_knowledge_id: team-kb\n---\n",
+			body:    "---\nowui_knowledge_id: team-kb\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matter, body, ok := parseFrontMatter(tt.path, []byte(tt.content))
+			if ok != tt.ok {
+				t.Errorf("Expected ok = %v, got %v", tt.ok, ok)
+			}
+			if matter.KnowledgeID != tt.knowledgeID {
+				t.Errorf("Expected knowledge ID %q, got %q", tt.knowledgeID, matter.KnowledgeID)
+			}
+			if string(body) != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, string(body))
+			}
+		})
+	}
+}
+
+func TestLocalFolderAdapter_FetchFiles_FrontMatterKnowledgeID(t *testing.T) {
+	for _, strip := range []bool{false, true} {
+		name := "keep front matter"
+		if strip {
+			name = "strip front matter"
+		}
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			routed := "---\nowui_knowledge_id: team-kb\n---\n# Routed\n"
+			writeTree(t, root, map[string]string{
+				"routed.md": routed,
+				"plain.md":  "# Plain\n",
+			})
+
+			adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+				Enabled:          true,
+				Mappings:         []config.LocalFolderMapping{{FolderPath: root, KnowledgeID: "folder-kb"}},
+				StripFrontMatter: strip,
+			})
+			if err != nil {
+				t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+			}
+
+			// The second fetch reports the files unchanged and must keep the override
+			for fetch := 1; fetch <= 2; fetch++ {
+				files, err := adapter.FetchFiles(context.Background())
+				if err != nil {
+					t.Fatalf("FetchFiles() error = %v", err)
+				}
+				byPath := make(map[string]*File)
+				for _, file := range files {
+					byPath[file.Path] = file
+				}
+
+				if file := byPath["routed.md"]; file == nil || file.KnowledgeID != "team-kb" {
+					t.Errorf("Fetch %d: expected routed.md in team-kb, got %+v", fetch, file)
+				}
+				if file := byPath["plain.md"]; file == nil || file.KnowledgeID != "folder-kb" {
+					t.Errorf("Fetch %d: expected plain.md in folder-kb, got %+v", fetch, file)
+				}
+				if fetch == 1 {
+					expected := routed
+					if strip {
+						expected = "# Routed\n"
+					}
+					if content := string(byPath["routed.md"].Content); content != expected {
+						t.Errorf("Expected routed.md content %q, got %q", expected, content)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// OpenWebUI Content Sync
+// Copyright (C) 2025  OpenWebUI Content Sync Contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package adapter
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syncIgnoreFile is the ignore file always honored by the local folder adapter
+const syncIgnoreFile = ".owuisyncignore"
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	base     string   // slash-separated directory of the ignore file, relative to the root ("" for the root)
+	segments []string // pattern split on "/"
+	negate   bool     // pattern started with "!"
+	dirOnly  bool     // pattern ended with "/"
+	anchored bool     // pattern contained a "/" other than a trailing one
+}
+
+// ignoreMatcher evaluates gitignore-style ignore files found below a root folder.
+// Ignore files are loaded lazily per directory, and rules in deeper directories take precedence.
+type ignoreMatcher struct {
+	root      string
+	fileNames []string
+	rules     map[string][]ignoreRule // directory -> rules from its ignore files
+}
+
+// newIgnoreMatcher creates a matcher for root that reads .owuisyncignore and, optionally, .gitignore files
+func newIgnoreMatcher(root string, useGitignore bool) *ignoreMatcher {
+	fileNames := []string{syncIgnoreFile}
+	if useGitignore {
+		fileNames = append([]string{".gitignore"}, fileNames...)
+	}
+	return &ignoreMatcher{
+		root:      root,
+		fileNames: fileNames,
+		rules:     make(map[string][]ignoreRule),
+	}
+}
+
+// Ignored reports whether relPath (relative to the root) or any of its parent directories is ignored
+func (m *ignoreMatcher) Ignored(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." || relPath == "" {
+		return false
+	}
+
+	// A file inside an ignored directory is ignored as well, like git does
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matches(relPath, isDir)
+}
+
+// matches evaluates the rules that apply to relPath itself; the last matching rule wins
+func (m *ignoreMatcher) matches(relPath string, isDir bool) bool {
+	ignored := false
+	for _, dir := range ancestorDirs(relPath) {
+		for _, rule := range m.rulesFor(dir) {
+			if rule.match(relPath, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// rulesFor returns the rules of the ignore files in dir, loading them on first use
+func (m *ignoreMatcher) rulesFor(dir string) []ignoreRule {
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []ignoreRule
+	for _, name := range m.fileNames {
+		rules = append(rules, loadIgnoreRules(filepath.Join(m.root, filepath.FromSlash(dir), name), dir)...)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// ancestorDirs returns the directories whose ignore files apply to relPath, outermost first
+func ancestorDirs(relPath string) []string {
+	dirs := []string{""}
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "/"))
+	}
+	return dirs
+}
+
+// loadIgnoreRules parses an ignore file, returning no rules if it does not exist
+func loadIgnoreRules(filePath, base string) []ignoreRule {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Warnf("Failed to read ignore file %s: %v", filePath, err)
+		}
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text(), base); ok {
+			rules = append(rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Warnf("Failed to read ignore file %s: %v", filePath, err)
+	}
+	return rules
+}
+
+// parseIgnoreRule parses a single gitignore line
+func parseIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// Escaped leading "#" or "!"
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.segments = strings.Split(line, "/")
+	return rule, true
+}
+
+// match reports whether the rule matches relPath (slash-separated, relative to the root)
+func (r ignoreRule) match(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	rel := relPath
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(relPath, r.base+"/")
+	}
+
+	parts := strings.Split(rel, "/")
+	if !r.anchored {
+		// Patterns without a slash match the name at any depth
+		return matchSegments(r.segments, parts[len(parts)-1:])
+	}
+	return matchSegments(r.segments, parts)
+}
+
+// matchSegments matches glob segments against path segments, where "**" matches any number of segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
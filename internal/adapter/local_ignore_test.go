@@ -0,0 +1,132 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// writeTree creates files (relative path -> content) below root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestIgnoreMatcher_Ignored(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".owuisyncignore":     "# build output\nbuild/\n*.generated.md\n!keep.generated.md\n/drafts\n**/cache/**\n*.txt\n",
+		"sub/.owuisyncignore": "!notes.txt\nprivate.md\n",
+		".gitignore":          "secrets.md\n",
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"plain file", "readme.md", false, false},
+		{"directory pattern", "build", true, true},
+		{"file inside ignored directory", "build/output.md", false, true},
+		{"nested directory pattern", "pkg/build/output.md", false, true},
+		{"directory pattern does not match file", "build", false, false},
+		{"glob pattern", "api.generated.md", false, true},
+		{"glob pattern at depth", "pkg/api.generated.md", false, true},
+		{"negation", "keep.generated.md", false, false},
+		{"anchored pattern at root", "drafts/idea.md", false, true},
+		{"anchored pattern not at depth", "pkg/drafts/idea.md", false, false},
+		{"double star", "a/b/cache/data.md", false, true},
+		{"root rule applies to nested dirs", "other/notes.txt", false, true},
+		{"nested negation overrides root rule", "sub/notes.txt", false, false},
+		{"nested rule", "sub/private.md", false, true},
+		{"nested rule scoped to its directory", "private.md", false, false},
+		{"gitignore disabled", "secrets.md", false, false},
+	}
+
+	matcher := newIgnoreMatcher(root, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.Ignored(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_Gitignore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":      "dist/\nsecrets.md\n",
+		".owuisyncignore": "!secrets.md\n",
+	})
+
+	matcher := newIgnoreMatcher(root, true)
+	if !matcher.Ignored("dist/app.md", false) {
+		t.Errorf("Expected dist/app.md to be ignored by .gitignore")
+	}
+	// .owuisyncignore is evaluated after .gitignore, so it can re-include files
+	if matcher.Ignored("secrets.md", false) {
+		t.Errorf("Expected secrets.md to be re-included by .owuisyncignore")
+	}
+}
+
+func TestLocalFolderAdapter_FetchFiles_IgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".owuisyncignore":        "build/\n*.txt\n",
+		"readme.md":              "readme",
+		"notes.txt":              "ignored",
+		"build/out.md":           "ignored",
+		"docs/build/out.md":      "ignored",
+		"docs/guide.md":          "guide",
+		"docs/.owuisyncignore":   "!keep.txt\n",
+		"docs/keep.txt":          "kept",
+		"vendor-docs/.gitignore": "*.md\n",
+		"vendor-docs/readme.md":  "kept without use_gitignore",
+	})
+
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled: true,
+		Mappings: []config.LocalFolderMapping{
+			{FolderPath: root, KnowledgeID: "test-knowledge"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+
+	files, err := adapter.FetchFiles(context.Background())
+	if err != nil {
+		t.Fatalf("FetchFiles() error = %v", err)
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	sort.Strings(paths)
+
+	expected := []string{"docs/guide.md", "docs/keep.txt", "readme.md", "vendor-docs/readme.md"}
+	if len(paths) != len(expected) {
+		t.Fatalf("FetchFiles() returned %v, want %v", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("FetchFiles() returned %v, want %v", paths, expected)
+			break
+		}
+	}
+}
@@ -178,6 +178,79 @@ func TestLocalFolderAdapter_FetchFiles(t *testing.T) {
 	}
 }
 
+func TestLocalFolderAdapter_FetchFiles_SkipsUnchanged(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, content := range map[string]string{"same.md": "same", "edited.md": "before"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", name, err)
+		}
+	}
+
+	var reads []string
+	readLocalFile = func(path string) ([]byte, error) {
+		reads = append(reads, filepath.Base(path))
+		return os.ReadFile(path)
+	}
+	defer func() { readLocalFile = os.ReadFile }()
+
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled:  true,
+		Mappings: []config.LocalFolderMapping{{FolderPath: tempDir, KnowledgeID: "test-knowledge"}},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+
+	fetch := func() map[string]*File {
+		t.Helper()
+		reads = nil
+		files, err := adapter.FetchFiles(context.Background())
+		if err != nil {
+			t.Fatalf("FetchFiles() error = %v", err)
+		}
+		byPath := make(map[string]*File)
+		for _, file := range files {
+			byPath[file.Path] = file
+		}
+		return byPath
+	}
+
+	first := fetch()
+	if len(reads) != 2 || first["same.md"].Unchanged {
+		t.Fatalf("Expected the first fetch to read every file, got reads %v", reads)
+	}
+
+	// Change the edited file's size and modification time
+	edited := filepath.Join(tempDir, "edited.md")
+	if err := os.WriteFile(edited, []byte("after the edit"), 0644); err != nil {
+		t.Fatalf("Failed to edit test file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(edited, later, later); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	second := fetch()
+	if len(reads) != 1 || reads[0] != "edited.md" {
+		t.Errorf("Expected only the edited file to be read again, got reads %v", reads)
+	}
+	same := second["same.md"]
+	if same == nil || !same.Unchanged || len(same.Content) != 0 || same.Hash != first["same.md"].Hash {
+		t.Errorf("Expected same.md to be reported unchanged with its previous hash, got %+v", same)
+	}
+	if file := second["edited.md"]; file == nil || file.Unchanged || string(file.Content) != "after the edit" {
+		t.Errorf("Expected edited.md with its new content, got %+v", file)
+	}
+
+	// The sync manager loads unchanged files it has no record of
+	if err := adapter.LoadContent(context.Background(), same); err != nil {
+		t.Fatalf("LoadContent() error = %v", err)
+	}
+	if same.Unchanged || string(same.Content) != "same" || same.Hash != first["same.md"].Hash {
+		t.Errorf("Expected LoadContent to read same.md, got %+v", same)
+	}
+}
+
 func TestLocalFolderAdapter_shouldIgnoreFile(t *testing.T) {
 	adapter := &LocalFolderAdapter{}
 
@@ -0,0 +1,208 @@
+// OpenWebUI Content Sync
+// Copyright (C) 2025  OpenWebUI Content Sync Contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce is how long a watched file must be quiet before it is synced,
+// so editors that write a file several times in a row trigger a single sync
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// FileChangeHandler is called with a changed file detected by a watcher
+type FileChangeHandler func(ctx context.Context, file *File) error
+
+// Watch registers a recursive fsnotify watcher on every mapped folder and calls onChange
+// for each created or modified file once its events have settled. Hidden and ignored
+// directories are not watched. Watching stops when ctx is cancelled; the returned channel is
+// closed once it stopped and the last onChange call returned. An error is returned if the
+// watcher cannot be set up, in which case callers should rely on interval polling.
+func (l *LocalFolderAdapter) Watch(ctx context.Context, onChange FileChangeHandler) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	for _, folder := range l.folders {
+		if err := l.watchRecursive(watcher, folder); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch folder %s: %w", folder, err)
+		}
+		l.log().Infof("Watching local folder for changes: %s", folder)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.watchLoop(ctx, watcher, onChange)
+	}()
+	return done, nil
+}
+
+// watchRecursive adds a watch for root and all of its non-ignored subdirectories
+func (l *LocalFolderAdapter) watchRecursive(watcher *fsnotify.Watcher, root string) error {
+	var ignore *ignoreMatcher
+	folder, hasFolder := l.folderFor(root)
+	if hasFolder {
+		ignore = newIgnoreMatcher(folder, l.config.UseGitignore)
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			l.log().Warnf("Error accessing path %s: %v", path, err)
+			return nil // Continue walking
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && l.shouldIgnoreFile(d.Name()) {
+			return filepath.SkipDir
+		}
+		if ignore != nil {
+			if relPath, err := filepath.Rel(folder, path); err == nil && ignore.Ignored(relPath, true) {
+				return filepath.SkipDir
+			}
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchLoop processes watcher events until ctx is cancelled
+func (l *LocalFolderAdapter) watchLoop(ctx context.Context, watcher *fsnotify.Watcher, onChange FileChangeHandler) {
+	defer watcher.Close()
+
+	var mu sync.Mutex
+	var handlers sync.WaitGroup // onChange calls in progress
+	stopped := false
+	timers := make(map[string]*time.Timer)
+	defer func() {
+		mu.Lock()
+		stopped = true
+		for _, timer := range timers {
+			timer.Stop()
+		}
+		mu.Unlock()
+		handlers.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			l.log().Warnf("Local folder watcher error: %v", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			// Start watching newly created directories; files inside them will trigger their own events
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				if event.Has(fsnotify.Create) && !l.shouldIgnoreFile(info.Name()) {
+					if err := l.watchRecursive(watcher, event.Name); err != nil {
+						l.log().Warnf("Failed to watch new directory %s: %v", event.Name, err)
+					}
+				}
+				continue
+			}
+
+			path := event.Name
+			mu.Lock()
+			if timer, exists := timers[path]; exists {
+				timer.Reset(l.debounce)
+			} else {
+				timers[path] = time.AfterFunc(l.debounce, func() {
+					mu.Lock()
+					delete(timers, path)
+					if stopped {
+						mu.Unlock()
+						return
+					}
+					handlers.Add(1)
+					mu.Unlock()
+					defer handlers.Done()
+					l.handleChange(ctx, path, onChange)
+				})
+			}
+			mu.Unlock()
+		}
+	}
+}
+
+// handleChange loads a changed file and passes it to onChange
+func (l *LocalFolderAdapter) handleChange(ctx context.Context, path string, onChange FileChangeHandler) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	folder, ok := l.folderFor(path)
+	if !ok {
+		return
+	}
+
+	if relPath, err := filepath.Rel(folder, path); err == nil && newIgnoreMatcher(folder, l.config.UseGitignore).Ignored(relPath, false) {
+		return
+	}
+
+	file, err := l.loadFile(folder, path, l.mappings[folder])
+	if err != nil {
+		l.log().Warnf("Failed to load changed file: %v", err)
+		return
+	}
+	if file == nil {
+		return
+	}
+
+	l.log().Infof("Detected change in local file: %s", path)
+	if err := onChange(ctx, file); err != nil {
+		l.log().Errorf("Failed to sync changed file %s: %v", path, err)
+	}
+}
+
+// folderFor returns the most specific mapped folder containing path
+func (l *LocalFolderAdapter) folderFor(path string) (string, bool) {
+	best := ""
+	for _, folder := range l.folders {
+		rel, err := filepath.Rel(folder, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(folder) > len(best) {
+			best = folder
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func newWatchedLocalAdapter(t *testing.T, dir string) (*LocalFolderAdapter, <-chan *File) {
+	t.Helper()
+
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled: true,
+		Watch:   true,
+		Mappings: []config.LocalFolderMapping{
+			{FolderPath: dir, KnowledgeID: "test-knowledge"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+	adapter.debounce = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	changes := make(chan *File, 10)
+	_, err = adapter.Watch(ctx, func(ctx context.Context, file *File) error {
+		changes <- file
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	return adapter, changes
+}
+
+func waitForChange(t *testing.T, changes <-chan *File) *File {
+	t.Helper()
+	select {
+	case file := <-changes:
+		return file
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for file change")
+		return nil
+	}
+}
+
+func TestLocalFolderAdapter_Watch_Debounce(t *testing.T) {
+	tempDir := t.TempDir()
+	_, changes := newWatchedLocalAdapter(t, tempDir)
+
+	// Editors often write a file several times in quick succession
+	path := filepath.Join(tempDir, "notes.md")
+	for _, content := range []string{"draft", "draft 2", "final"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	file := waitForChange(t, changes)
+	if file.Path != "notes.md" {
+		t.Errorf("Expected path notes.md, got %s", file.Path)
+	}
+	if string(file.Content) != "final" {
+		t.Errorf("Expected latest content 'final', got %q", string(file.Content))
+	}
+	if file.KnowledgeID != "test-knowledge" {
+		t.Errorf("Expected knowledge ID test-knowledge, got %s", file.KnowledgeID)
+	}
+
+	select {
+	case extra := <-changes:
+		t.Errorf("Expected a single debounced change, got another for %s", extra.Path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestLocalFolderAdapter_Watch_NewSubdirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	_, changes := newWatchedLocalAdapter(t, tempDir)
+
+	subDir := filepath.Join(tempDir, "guides")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	// Give the watcher a moment to register the new directory
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(subDir, "setup.md"), []byte("# Setup"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	file := waitForChange(t, changes)
+	if file.Path != filepath.Join("guides", "setup.md") {
+		t.Errorf("Expected path guides/setup.md, got %s", file.Path)
+	}
+}
+
+func TestLocalFolderAdapter_Watch_IgnoredFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	_, changes := newWatchedLocalAdapter(t, tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".notes.md.swp"), []byte("swap"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	select {
+	case file := <-changes:
+		t.Errorf("Expected ignored file not to trigger a change, got %s", file.Path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestLocalFolderAdapter_Watch_StopsAfterRunningChange(t *testing.T) {
+	tempDir := t.TempDir()
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled:  true,
+		Watch:    true,
+		Mappings: []config.LocalFolderMapping{{FolderPath: tempDir, KnowledgeID: "test-knowledge"}},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+	adapter.debounce = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started, release := make(chan struct{}), make(chan struct{})
+	done, err := adapter.Watch(ctx, func(ctx context.Context, file *File) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "notes.md"), []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for file change")
+	}
+
+	// Watching stops once the change being synced returns
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Expected Watch to wait for the running change")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for Watch to stop")
+	}
+}
+
+func TestLocalFolderAdapter_Watch_MissingFolder(t *testing.T) {
+	tempDir := t.TempDir()
+	adapter, err := NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled: true,
+		Mappings: []config.LocalFolderMapping{
+			{FolderPath: tempDir, KnowledgeID: "test-knowledge"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFolderAdapter() error = %v", err)
+	}
+
+	// Removing the folder after creation makes watcher setup fail
+	if err := os.RemoveAll(tempDir); err != nil {
+		t.Fatalf("Failed to remove folder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := adapter.Watch(ctx, func(ctx context.Context, file *File) error { return nil }); err == nil {
+		t.Error("Expected error when watching a missing folder")
+	}
+}
@@ -0,0 +1,21 @@
+package adapter
+
+import (
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/utils"
+)
+
+// newRetryConfig returns the retry settings of an adapter. config.Load fills in every setting,
+// so zeros such as max_retries: 0 are kept; an empty retry, e.g. in tests, is replaced by
+// defaults.
+func newRetryConfig(retry config.RetryConfig, defaults utils.RetryConfig) utils.RetryConfig {
+	if retry == (config.RetryConfig{}) {
+		return defaults
+	}
+	return utils.RetryConfig{
+		MaxRetries: retry.MaxRetries,
+		BaseDelay:  retry.BaseDelay,
+		MaxDelay:   retry.MaxDelay,
+		Multiplier: retry.Multiplier,
+	}
+}
@@ -4,19 +4,23 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/storage"
 	"github.com/openwebui-content-sync/internal/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
 )
 
 // SlackAdapter implements the Adapter interface for Slack
@@ -24,23 +28,96 @@ type SlackAdapter struct {
 	config         config.SlackConfig
 	client         *slack.Client
 	lastSync       time.Time
-	storageDir     string
-	cachedChannels []slack.Channel // Cache channels for the entire sync session
+	store          storage.Storage   // stored messages, cursors, downloaded files and caches below slack/
+	cachedChannels []slack.Channel   // Cache channels for the entire sync session
+	userNames      map[string]string // Cache of user ID -> display name, persisted to slack/users.json
+	userCacheDirty bool
+	limiter        *rate.Limiter                    // shared by all Slack API calls, since Slack rate limits per workspace
+	breaker        *utils.CircuitBreaker            // fails Slack API calls fast while Slack is unreachable
+	channelDays    map[string]int                   // channel ID -> days_to_fetch override of its mapping or regex pattern
+	channels       map[string]config.ChannelMapping // channel ID -> channel of the last fetch, with its knowledge ID
+	fullFetch      bool                             // set by RequestFullFetch to return the files of stored messages for one fetch
+
+	// Coverage bookkeeping of the current fetch, summarized into coverage when it ends
+	patternCoverage []SlackPatternCoverage
+	joinedChannels  map[string]bool  // channel ID -> joined during this fetch
+	channelErrors   map[string]error // channel ID -> error that made this fetch skip the channel
+	coverage        SlackCoverage    // coverage of the last fetch, read by the status endpoint
+	coverageMu      sync.Mutex
+	logger          logging.Logger // nil to log to the global logrus logger
+}
+
+// defaultRequestsPerMinute keeps Slack API calls within the Tier 3 limit (~50 requests per minute)
+const defaultRequestsPerMinute = 50
+
+// newSlackRateLimiter creates a limiter allowing requestsPerMinute calls without bursts
+func newSlackRateLimiter(requestsPerMinute int) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(requestsPerMinute)), 1)
+}
+
+// slackCircuitThreshold is the number of consecutive failed Slack API calls after which
+// further calls fail fast for slackCircuitCooldown
+const (
+	slackCircuitThreshold = 10
+	slackCircuitCooldown  = time.Minute
+)
+
+// slackRetryDefaults are the retry settings of Slack API calls when the config has no retry
+// settings, e.g. in tests; config.Load applies the same defaults to the settings neither
+// slack.retry nor the top-level retry set
+var slackRetryDefaults = utils.RetryConfig{
+	MaxRetries: 5,
+	BaseDelay:  time.Second,
+	MaxDelay:   5 * time.Minute,
+	Multiplier: 2,
+}
+
+// retryConfig returns the retry settings of Slack API calls, which share the circuit breaker
+func (s *SlackAdapter) retryConfig() utils.RetryConfig {
+	retryConfig := newRetryConfig(s.config.Retry, slackRetryDefaults)
+	retryConfig.CircuitBreaker, retryConfig.CircuitKey = s.breaker, "slack"
+	return retryConfig
+}
+
+// Joining a channel and looking up a user are retried at most slackLookupMaxRetries times and
+// slackLookupMaxDelay apart, so they don't hold up a channel for minutes
+const (
+	slackLookupMaxRetries = 3
+	slackLookupMaxDelay   = 30 * time.Second
+)
+
+// lookupRetryConfig returns the retry settings of joining channels and looking up users
+func (s *SlackAdapter) lookupRetryConfig() utils.RetryConfig {
+	retryConfig := s.retryConfig()
+	retryConfig.MaxRetries = min(retryConfig.MaxRetries, slackLookupMaxRetries)
+	retryConfig.MaxDelay = min(retryConfig.MaxDelay, slackLookupMaxDelay)
+	return retryConfig
+}
+
+// waitForRateLimit blocks until the shared limiter allows the next Slack API call
+func (s *SlackAdapter) waitForRateLimit(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.Wait(ctx)
+}
+
+// slackChannelKey returns the storage key of a channel's file below slack/channels/<id>
+func slackChannelKey(channelID string, name ...string) string {
+	return path.Join(append([]string{"slack", "channels", channelID}, name...)...)
 }
 
 // channelHasHistory returns true if we've previously stored any messages for the channel
 func (s *SlackAdapter) channelHasHistory(channelID string) bool {
-	filePath := filepath.Join(s.storageDir, "slack", "channels", channelID, "messages.json")
-	if _, err := os.Stat(filePath); err == nil {
-		return true
-	}
-	return false
+	_, err := s.store.Read(slackChannelKey(channelID, "messages.json"))
+	return err == nil
 }
 
 // SlackMessage represents a Slack message with metadata
 type SlackMessage struct {
 	Timestamp   string            `json:"timestamp"`
 	User        string            `json:"user"`
+	UserName    string            `json:"user_name,omitempty"`
 	Text        string            `json:"text"`
 	Channel     string            `json:"channel"` // stores channel name
 	ThreadTS    string            `json:"thread_ts,omitempty"`
@@ -74,25 +151,30 @@ type SlackAttachment struct {
 	AuthorName string `json:"author_name"`
 }
 
+// defaultConversationTypes are the conversation types listed when none are configured
+var defaultConversationTypes = []string{"public_channel", "private_channel"}
+
+// validConversationTypes are the conversation types accepted by conversations.list
+var validConversationTypes = map[string]bool{
+	"public_channel":  true,
+	"private_channel": true,
+	"im":              true,
+	"mpim":            true,
+}
+
 // NewSlackAdapter creates a new Slack adapter
-func NewSlackAdapter(cfg config.SlackConfig, storageDir string) (*SlackAdapter, error) {
+func NewSlackAdapter(cfg config.SlackConfig, store storage.Storage) (*SlackAdapter, error) {
 	logrus.Infof("Initializing Slack adapter with config: enabled=%v, channels=%d, days_to_fetch=%d, message_limit=%d",
 		cfg.Enabled, len(cfg.ChannelMappings), cfg.DaysToFetch, cfg.MessageLimit)
 
 	if !cfg.Enabled {
 		logrus.Info("Slack adapter is disabled")
 		// Return a disabled adapter without error
-		// Still create storage directory for consistency
-		slackStoragePath := filepath.Join(storageDir, "slack", "channels")
-		if err := os.MkdirAll(slackStoragePath, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create slack storage directory: %w", err)
-		}
-
 		return &SlackAdapter{
-			config:     cfg,
-			client:     nil,
-			storageDir: storageDir,
-			lastSync:   time.Time{},
+			config:   cfg,
+			client:   nil,
+			store:    store,
+			lastSync: time.Time{},
 		}, nil
 	}
 
@@ -111,6 +193,14 @@ func NewSlackAdapter(cfg config.SlackConfig, storageDir string) (*SlackAdapter,
 	}
 
 	// Set defaults
+	if len(cfg.ConversationTypes) == 0 {
+		cfg.ConversationTypes = defaultConversationTypes
+	}
+	for _, conversationType := range cfg.ConversationTypes {
+		if !validConversationTypes[conversationType] {
+			return nil, fmt.Errorf("invalid slack conversation type %q (expected public_channel, private_channel, im or mpim)", conversationType)
+		}
+	}
 	if cfg.DaysToFetch <= 0 {
 		cfg.DaysToFetch = 30
 		logrus.Infof("Set default days_to_fetch to %d", cfg.DaysToFetch)
@@ -119,6 +209,15 @@ func NewSlackAdapter(cfg config.SlackConfig, storageDir string) (*SlackAdapter,
 		cfg.MessageLimit = 1000
 		logrus.Infof("Set default message_limit to %d", cfg.MessageLimit)
 	}
+	if cfg.RequestsPerMinute <= 0 {
+		cfg.RequestsPerMinute = defaultRequestsPerMinute
+	}
+	if cfg.MessageFormat == "" {
+		cfg.MessageFormat = slackFormatThreaded
+	}
+	if cfg.MaxErrorLogBytes <= 0 {
+		cfg.MaxErrorLogBytes = defaultMaxErrorLogBytes
+	}
 
 	client := slack.New(cfg.Token)
 	logrus.Infof("Created Slack client with token starting with: %s", cfg.Token[:10]+"...")
@@ -134,18 +233,13 @@ func NewSlackAdapter(cfg config.SlackConfig, storageDir string) (*SlackAdapter,
 		logrus.Debugf("Skipping authentication for test token")
 	}
 
-	// Create storage directory for Slack
-	slackStoragePath := filepath.Join(storageDir, "slack", "channels")
-	if err := os.MkdirAll(slackStoragePath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create slack storage directory: %w", err)
-	}
-	logrus.Infof("Created Slack storage directory: %s", slackStoragePath)
-
 	return &SlackAdapter{
-		config:     cfg,
-		client:     client,
-		storageDir: storageDir,
-		lastSync:   time.Time{}, // Start with zero time
+		config:   cfg,
+		client:   client,
+		store:    store,
+		lastSync: time.Time{}, // Start with zero time
+		limiter:  newSlackRateLimiter(cfg.RequestsPerMinute),
+		breaker:  utils.NewCircuitBreaker(slackCircuitThreshold, slackCircuitCooldown),
 	}, nil
 }
 
@@ -154,71 +248,58 @@ func (s *SlackAdapter) Name() string {
 	return "slack"
 }
 
+// SetLogger sets the logger the adapter writes to
+func (s *SlackAdapter) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// log returns the adapter's logger
+func (s *SlackAdapter) log() logging.Logger {
+	if s.logger == nil {
+		return logging.Default()
+	}
+	return s.logger
+}
+
 // FetchFiles retrieves messages from Slack channels and converts them to files
 func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
-	logrus.Infof("Starting Slack adapter fetch with config: enabled=%v, maintain_history=%v, days_to_fetch=%d, message_limit=%d, include_threads=%v, include_reactions=%v",
+	s.log().Infof("Starting Slack adapter fetch with config: enabled=%v, maintain_history=%v, days_to_fetch=%d, message_limit=%d, include_threads=%v, include_reactions=%v",
 		s.config.Enabled, s.config.MaintainHistory, s.config.DaysToFetch, s.config.MessageLimit, s.config.IncludeThreads, s.config.IncludeReactions)
 
 	// Only clear channel cache if it's been more than 5 minutes since last discovery
 	// This prevents unnecessary API calls during frequent syncs
 	if s.cachedChannels != nil && len(s.cachedChannels) > 0 {
-		logrus.Debugf("Using existing channel cache (%d channels) - skipping fresh discovery", len(s.cachedChannels))
+		s.log().Debugf("Using existing channel cache (%d channels) - skipping fresh discovery", len(s.cachedChannels))
 	} else {
-		logrus.Debugf("No cached channels available - will perform fresh discovery")
+		s.log().Debugf("No cached channels available - will perform fresh discovery")
 	}
 
 	// Return empty slice if adapter is disabled
 	if !s.config.Enabled {
-		logrus.Infof("Slack adapter is disabled, returning empty files")
+		s.log().Infof("Slack adapter is disabled, returning empty files")
 		return []*File{}, nil
 	}
 
 	var files []*File
 	now := time.Now()
-
-	// Calculate time range for fetching messages
-	var oldestTime time.Time
-	if s.config.MaintainHistory {
-		// If maintaining history, fetch from last sync time
-		if s.lastSync.IsZero() {
-			// First run: fetch from the last N days
-			oldestTime = now.AddDate(0, 0, -s.config.DaysToFetch)
-			logrus.Infof("First run with maintain_history: fetching last %d days from %s (Unix: %d)", s.config.DaysToFetch, oldestTime.Format(time.RFC3339), oldestTime.Unix())
-		} else {
-			oldestTime = s.lastSync
-			logrus.Infof("Maintaining history: fetching from last sync time %s (Unix: %d)", oldestTime.Format(time.RFC3339), oldestTime.Unix())
-		}
-	} else {
-		// If not maintaining history, fetch only the last N days
-		oldestTime = now.AddDate(0, 0, -s.config.DaysToFetch)
-		logrus.Infof("Not maintaining history: fetching last %d days from %s (Unix: %d)", s.config.DaysToFetch, oldestTime.Format(time.RFC3339), oldestTime.Unix())
-	}
-
-	logrus.Infof("Time range for fetching messages: %s to %s", oldestTime.Format(time.RFC3339), now.Format(time.RFC3339))
-	logrus.Infof("Unix timestamps: oldest=%d, latest=%d", oldestTime.Unix(), now.Unix())
-
-	// Validate time range
-	if oldestTime.After(now) {
-		logrus.Errorf("Invalid time range: oldest time (%s) is after latest time (%s)",
-			oldestTime.Format(time.RFC3339), now.Format(time.RFC3339))
-		return []*File{}, fmt.Errorf("invalid time range: oldest time is after latest time")
+	oldestTime, err := s.fetchStart(now)
+	if err != nil {
+		return []*File{}, err
 	}
 
-	timeRange := now.Sub(oldestTime)
-	logrus.Infof("Time range duration: %v", timeRange)
-
 	// Discover channels using regex patterns
-	discoveredChannels, err := s.discoverChannelsByRegex(ctx)
-	if err != nil {
-		logrus.Warnf("Failed to discover channels by regex: %v", err)
+	s.resetCoverage()
+	discoveredChannels, discoveryErr := s.discoverChannelsByRegex(ctx)
+	if err := discoveryErr; err != nil {
+		s.log().Warnf("Failed to discover channels by regex: %v", err)
 	} else if len(discoveredChannels) > 0 {
-		logrus.Infof("Discovered %d channels using regex patterns", len(discoveredChannels))
+		s.log().Infof("Discovered %d channels using regex patterns", len(discoveredChannels))
 	}
 
 	// Load locally known channels from storage to ensure we keep syncing even if discovery is rate limited
 	localChannels := s.listLocalChannels()
 	if len(localChannels) > 0 {
-		logrus.Infof("Found %d locally known channels from storage", len(localChannels))
+		s.log().Infof("Found %d locally known channels from storage", len(localChannels))
 	}
 
 	// Combine explicit channel mappings with discovered channels
@@ -243,6 +324,10 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 				if existing.KnowledgeID == "" && m.KnowledgeID != "" {
 					existing.KnowledgeID = m.KnowledgeID
 				}
+				// Later lists take precedence, so an explicit mapping's override wins
+				if m.DaysToFetch > 0 {
+					existing.DaysToFetch = m.DaysToFetch
+				}
 				if (existing.ChannelName == "" || existing.ChannelName == existing.ChannelID) && m.ChannelName != "" {
 					existing.ChannelName = m.ChannelName
 				}
@@ -254,6 +339,10 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	}
 	allChannels = allChannels[:0]
 	for _, v := range seenByID {
+		// DM mappings often have no name; fall back to the ID for logs and filenames
+		if v.ChannelName == "" {
+			v.ChannelName = v.ChannelID
+		}
 		// Ensure all channels have a knowledge ID - use the first available one or fallback
 		if v.KnowledgeID == "" {
 			// Try to find a knowledge ID from explicit mappings first
@@ -267,109 +356,45 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 			if v.KnowledgeID == "" && len(s.config.RegexPatterns) > 0 {
 				v.KnowledgeID = s.config.RegexPatterns[0].KnowledgeID
 			}
-			logrus.Debugf("Assigned knowledge ID %s to channel %s (%s)", v.KnowledgeID, v.ChannelName, v.ChannelID)
+			s.log().Debugf("Assigned knowledge ID %s to channel %s (%s)", v.KnowledgeID, v.ChannelName, v.ChannelID)
 		}
 		allChannels = append(allChannels, v)
 	}
 
-	logrus.Infof("Processing %d total channels (%d explicit mappings + %d discovered + %d local)",
+	s.log().Infof("Processing %d total channels (%d explicit mappings + %d discovered + %d local)",
 		len(allChannels), len(s.config.ChannelMappings), len(discoveredChannels), len(localChannels))
 
+	// Keep stored history within the retention period before it is read back below
+	s.channelDays = make(map[string]int)
+	s.channels = make(map[string]config.ChannelMapping, len(allChannels))
+	for _, mapping := range allChannels {
+		if mapping.DaysToFetch > 0 {
+			s.channelDays[mapping.ChannelID] = mapping.DaysToFetch
+		}
+		s.channels[mapping.ChannelID] = mapping
+	}
+	s.pruneStoredMessages(now)
+
 	// Track processed channel IDs to ensure parity with local storage
 	processed := make(map[string]bool)
 
 	// Process each channel mapping
 	for i, mapping := range allChannels {
-		logrus.Infof("Processing channel %d/%d: %s (%s)", i+1, len(allChannels), mapping.ChannelName, mapping.ChannelID)
+		s.log().Infof("Processing channel %d/%d: %s (%s)", i+1, len(allChannels), mapping.ChannelName, mapping.ChannelID)
 
-		// Test channel access first
-		if err := s.testChannelAccess(mapping.ChannelID, mapping.ChannelName); err != nil {
-			logrus.Errorf("Failed to access channel %s (%s): %v", mapping.ChannelName, mapping.ChannelID, err)
-			// Continue processing other channels even if one fails
-			continue
-		}
-
-		// Determine effective oldest time per channel
-		effectiveOldest := oldestTime
-		if s.config.MaintainHistory && !s.channelHasHistory(mapping.ChannelID) {
-			// First time seeing this channel locally: backfill last N days
-			effectiveOldest = now.AddDate(0, 0, -s.config.DaysToFetch)
-			logrus.Infof("First local sync for channel %s (%s): backfilling last %d days from %s",
-				mapping.ChannelName, mapping.ChannelID, s.config.DaysToFetch, effectiveOldest.Format(time.RFC3339))
-		}
-
-		// Fetch messages from the channel
-		messages, err := s.fetchChannelMessages(ctx, mapping.ChannelID, mapping.ChannelName, effectiveOldest, now)
-		if err != nil {
-			logrus.Errorf("Failed to fetch messages from channel %s: %v", mapping.ChannelName, err)
-			continue
-		}
-
-		// When maintaining history, we should create a file even if no new messages were found
-		// because we want to include all historical messages
-		if len(messages) == 0 && !s.config.MaintainHistory {
-			logrus.Warnf("No new messages found in channel %s (%s)", mapping.ChannelName, mapping.ChannelID)
-			continue
-		}
-
-		// When maintaining history, generate file content from deduplicated storage to avoid duplicates
-		var fileContent string
-		if s.config.MaintainHistory {
-			// Save first (dedup inside), then load back for content generation
-			if len(messages) > 0 {
-				if err := s.saveMessagesToStorage(mapping.ChannelID, mapping.ChannelName, messages); err != nil {
-					logrus.Warnf("Failed to save messages to storage for channel %s: %v", mapping.ChannelName, err)
-				}
-			}
-			stored, err := s.loadMessagesFromStorage(mapping.ChannelID)
-			if err != nil {
-				logrus.Warnf("Failed to load messages from storage for channel %s: %v", mapping.ChannelName, err)
-				// Fallback to current messages
-				fileContent, err = s.messagesToFileContent(messages, mapping.ChannelName)
-			} else {
-				fileContent, err = s.messagesToFileContent(stored, mapping.ChannelName)
-			}
-		} else {
-			fileContent, err = s.messagesToFileContent(messages, mapping.ChannelName)
-		}
+		channelFiles, err := s.fetchChannel(ctx, mapping, oldestTime, now)
 		if err != nil {
-			logrus.Errorf("Failed to convert messages to file content for channel %s: %v", mapping.ChannelName, err)
+			s.recordChannelError(mapping.ChannelID, err)
+			// Continue processing other channels even if one fails
 			continue
 		}
-
-		// Skip creating file if content is empty
-		if len(fileContent) == 0 {
-			logrus.Warnf("No content generated for channel %s (%s), skipping file creation", mapping.ChannelName, mapping.ChannelID)
+		if len(channelFiles) == 0 {
 			continue
 		}
-
-		// Create file metadata
-		filename := fmt.Sprintf("%s_messages.md", sanitizeChannelName(mapping.ChannelName))
-		// Store just the filename here. The sync manager will place it under
-		// data/files/<source>/ so avoiding a leading "slack/" prevents a duplicate
-		// "slack/slack" path.
-		filePath := filename
-
-		file := &File{
-			Path:        filePath,
-			Content:     []byte(fileContent),
-			Hash:        fmt.Sprintf("%x", sha256.Sum256([]byte(fileContent))),
-			Modified:    now,
-			Size:        int64(len(fileContent)),
-			Source:      "slack",
-			KnowledgeID: mapping.KnowledgeID,
-		}
-
-		files = append(files, file)
+		files = append(files, channelFiles...)
 		processed[mapping.ChannelID] = true
-		logrus.Debugf("Created file for channel %s (%s) -> %s (knowledge: %s)", mapping.ChannelName, mapping.ChannelID, filename, mapping.KnowledgeID)
 
-		// Save messages to local storage for history tracking (no-op if not maintaining history)
 		if !s.config.MaintainHistory {
-			if err := s.saveMessagesToStorage(mapping.ChannelID, mapping.ChannelName, messages); err != nil {
-				logrus.Warnf("Failed to save messages to storage for channel %s: %v", mapping.ChannelName, err)
-			}
-
 			// Fallback: for any locally known channels not processed (e.g., due to discovery rate limit
 			// or missing access in this run), build files directly from stored history so that
 			// data/slack/channels/* count matches data/files/slack/* count.
@@ -396,7 +421,7 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 				if err != nil || len(content) == 0 {
 					continue
 				}
-				filename := fmt.Sprintf("%s_messages.md", sanitizeChannelName(channelName))
+				filename, contentType := s.messageFile(channelName)
 				file := &File{
 					Path:        filename,
 					Content:     []byte(content),
@@ -405,27 +430,28 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 					Size:        int64(len(content)),
 					Source:      "slack",
 					KnowledgeID: local.KnowledgeID,
+					ContentType: contentType,
+					ID:          channelFileID(local.ChannelID),
 				}
 				files = append(files, file)
-				logrus.Debugf("Added file from stored history for channel %s (%s)", channelName, local.ChannelID)
+				s.log().Debugf("Added file from stored history for channel %s (%s)", channelName, local.ChannelID)
 			}
 		}
 
-		logrus.Debugf("Processed %d messages from channel %s", len(messages), mapping.ChannelName)
-
 		// Add a longer delay between channels to avoid Slack rate limits
 		if i < len(allChannels)-1 { // Don't delay after the last channel
 			delay := 500 * time.Millisecond // Increased delay for Slack rate limiting
-			logrus.Debugf("Waiting %v before processing next channel to avoid rate limits", delay)
+			s.log().Debugf("Waiting %v before processing next channel to avoid rate limits", delay)
 			time.Sleep(delay)
 		}
 	}
 
 	// Update last sync time
 	s.lastSync = now
+	s.fullFetch = false
 
-	logrus.Infof("Fetched %d files from Slack channels", len(files))
-	logrus.Infof("Channel processing summary: %d total channels, %d files created, %d channels processed",
+	s.log().Infof("Fetched %d files from Slack channels", len(files))
+	s.log().Infof("Channel processing summary: %d total channels, %d files created, %d channels processed",
 		len(allChannels), len(files), len(processed))
 
 	// Log any channels that weren't processed
@@ -433,41 +459,200 @@ func (s *SlackAdapter) FetchFiles(ctx context.Context) ([]*File, error) {
 	for _, mapping := range allChannels {
 		if !processed[mapping.ChannelID] {
 			unprocessed++
-			logrus.Warnf("Channel %s (%s) was not processed - likely failed channel access test", mapping.ChannelName, mapping.ChannelID)
+			s.log().Warnf("Channel %s (%s) was not processed - likely failed channel access test", mapping.ChannelName, mapping.ChannelID)
 		}
 	}
 	if unprocessed > 0 {
-		logrus.Warnf("%d channels were not processed due to errors (out of %d total channels)", unprocessed, len(allChannels))
+		s.log().Warnf("%d channels were not processed due to errors (out of %d total channels)", unprocessed, len(allChannels))
 	}
 
+	s.finishCoverage(allChannels, processed, discoveryErr)
+
 	// Save channel tracking file
 	if err := s.saveChannelTracking(allChannels, processed); err != nil {
-		logrus.Warnf("Failed to save channel tracking file: %v", err)
+		s.log().Warnf("Failed to save channel tracking file: %v", err)
+	}
+
+	// Persist resolved user names so restarts don't re-fetch them
+	if err := s.saveUserCache(); err != nil {
+		s.log().Warnf("Failed to save Slack user cache: %v", err)
+	}
+
+	return files, nil
+}
+
+// fetchStart returns the time from which channels are fetched by default: the last sync time
+// when maintaining history, otherwise days_to_fetch days before now
+func (s *SlackAdapter) fetchStart(now time.Time) (time.Time, error) {
+	// Calculate time range for fetching messages
+	var oldestTime time.Time
+	if s.config.MaintainHistory {
+		// If maintaining history, fetch from last sync time
+		if s.lastSync.IsZero() {
+			// First run: fetch from the last N days
+			oldestTime = now.AddDate(0, 0, -s.config.DaysToFetch)
+			s.log().Infof("First run with maintain_history: fetching last %d days from %s (Unix: %d)", s.config.DaysToFetch, oldestTime.Format(time.RFC3339), oldestTime.Unix())
+		} else {
+			oldestTime = s.lastSync
+			s.log().Infof("Maintaining history: fetching from last sync time %s (Unix: %d)", oldestTime.Format(time.RFC3339), oldestTime.Unix())
+		}
+	} else {
+		// If not maintaining history, fetch only the last N days
+		oldestTime = now.AddDate(0, 0, -s.config.DaysToFetch)
+		s.log().Infof("Not maintaining history: fetching last %d days from %s (Unix: %d)", s.config.DaysToFetch, oldestTime.Format(time.RFC3339), oldestTime.Unix())
+	}
+
+	s.log().Infof("Time range for fetching messages: %s to %s", oldestTime.Format(time.RFC3339), now.Format(time.RFC3339))
+	s.log().Infof("Unix timestamps: oldest=%d, latest=%d", oldestTime.Unix(), now.Unix())
+
+	// Validate time range
+	if oldestTime.After(now) {
+		s.log().Errorf("Invalid time range: oldest time (%s) is after latest time (%s)",
+			oldestTime.Format(time.RFC3339), now.Format(time.RFC3339))
+		return time.Time{}, fmt.Errorf("invalid time range: oldest time is after latest time")
+	}
+
+	timeRange := now.Sub(oldestTime)
+	s.log().Infof("Time range duration: %v", timeRange)
+	return oldestTime, nil
+}
+
+// fetchChannel fetches the messages of a channel, starting at oldestTime unless the channel
+// overrides days_to_fetch or has no stored history yet, and returns its message file followed
+// by the files shared in its messages. It returns no files if there is nothing to sync.
+func (s *SlackAdapter) fetchChannel(ctx context.Context, mapping config.ChannelMapping, oldestTime, now time.Time) ([]*File, error) {
+	// Test channel access first
+	if err := s.testChannelAccess(ctx, mapping.ChannelID, mapping.ChannelName); err != nil {
+		s.log().Errorf("Failed to access channel %s (%s): %v", mapping.ChannelName, mapping.ChannelID, err)
+		return nil, err
+	}
+
+	// Determine effective oldest time per channel
+	daysToFetch := s.daysToFetch(mapping)
+	effectiveOldest := oldestTime
+	if mapping.DaysToFetch > 0 && (!s.config.MaintainHistory || s.lastSync.IsZero()) {
+		effectiveOldest = now.AddDate(0, 0, -daysToFetch)
+		s.log().Infof("Channel %s (%s) overrides days_to_fetch: fetching last %d days from %s",
+			mapping.ChannelName, mapping.ChannelID, daysToFetch, effectiveOldest.Format(time.RFC3339))
+	}
+	if s.config.MaintainHistory && !s.channelHasHistory(mapping.ChannelID) {
+		// First time seeing this channel locally: backfill last N days
+		effectiveOldest = now.AddDate(0, 0, -daysToFetch)
+		s.log().Infof("First local sync for channel %s (%s): backfilling last %d days from %s",
+			mapping.ChannelName, mapping.ChannelID, daysToFetch, effectiveOldest.Format(time.RFC3339))
 	}
 
+	// Fetch messages from the channel; with history, from where the last fetch left off
+	var messages []SlackMessage
+	var err error
+	if s.config.MaintainHistory {
+		messages, err = s.fetchChannelFromCursor(ctx, mapping.ChannelID, mapping.ChannelName, effectiveOldest, now)
+	} else {
+		messages, _, err = s.fetchChannelMessages(ctx, mapping.ChannelID, mapping.ChannelName, slackTimestamp(effectiveOldest), slackTimestamp(now), s.config.MessageLimit)
+	}
+	if err != nil {
+		s.log().Errorf("Failed to fetch messages from channel %s: %v", mapping.ChannelName, err)
+		return nil, err
+	}
+
+	// When maintaining history, we should create a file even if no new messages were found
+	// because we want to include all historical messages
+	if len(messages) == 0 && !s.config.MaintainHistory {
+		s.log().Warnf("No new messages found in channel %s (%s)", mapping.ChannelName, mapping.ChannelID)
+		return nil, nil
+	}
+
+	// When maintaining history, generate file content from deduplicated storage to avoid duplicates
+	var fileContent string
+	fileMessages := messages // messages whose attached files are returned
+	if s.config.MaintainHistory {
+		// fetchChannelFromCursor saved the messages, load them back for content generation
+		stored, err := s.loadMessagesFromStorage(mapping.ChannelID)
+		if err != nil {
+			s.log().Warnf("Failed to load messages from storage for channel %s: %v", mapping.ChannelName, err)
+			// Fallback to current messages
+			fileContent, err = s.messagesToFileContent(messages, mapping.ChannelName)
+		} else {
+			fileContent, err = s.messagesToFileContent(stored, mapping.ChannelName)
+			if s.fullFetch {
+				fileMessages = stored
+			}
+		}
+	} else {
+		fileContent, err = s.messagesToFileContent(messages, mapping.ChannelName)
+	}
+	if err != nil {
+		s.log().Errorf("Failed to convert messages to file content for channel %s: %v", mapping.ChannelName, err)
+		return nil, err
+	}
+
+	// Skip creating file if content is empty
+	if len(fileContent) == 0 {
+		s.log().Warnf("No content generated for channel %s (%s), skipping file creation", mapping.ChannelName, mapping.ChannelID)
+		return nil, nil
+	}
+
+	// Create file metadata
+	filename, contentType := s.messageFile(mapping.ChannelName)
+	// Store just the filename here. The sync manager will place it under
+	// data/files/<source>/ so avoiding a leading "slack/" prevents a duplicate
+	// "slack/slack" path.
+	filePath := filename
+
+	file := &File{
+		Path:        filePath,
+		Content:     []byte(fileContent),
+		Hash:        fmt.Sprintf("%x", sha256.Sum256([]byte(fileContent))),
+		Modified:    now,
+		Size:        int64(len(fileContent)),
+		Source:      "slack",
+		KnowledgeID: mapping.KnowledgeID,
+		ContentType: contentType,
+		ID:          channelFileID(mapping.ChannelID),
+	}
+
+	files := []*File{file}
+	files = append(files, s.processMessageFiles(ctx, mapping.ChannelID, mapping.ChannelName, mapping.KnowledgeID, fileMessages)...)
+	s.log().Debugf("Created file for channel %s (%s) -> %s (knowledge: %s)", mapping.ChannelName, mapping.ChannelID, filename, mapping.KnowledgeID)
+
+	// Save messages to local storage for history tracking (no-op if not maintaining history)
+	if !s.config.MaintainHistory {
+		if err := s.saveMessagesToStorage(mapping.ChannelID, mapping.ChannelName, messages); err != nil {
+			s.log().Warnf("Failed to save messages to storage for channel %s: %v", mapping.ChannelName, err)
+		}
+	}
+
+	s.log().Debugf("Processed %d messages from channel %s", len(messages), mapping.ChannelName)
 	return files, nil
 }
 
-// fetchChannelMessages retrieves messages from a specific Slack channel
-func (s *SlackAdapter) fetchChannelMessages(ctx context.Context, channelID, channelName string, oldestTime, latestTime time.Time) ([]SlackMessage, error) {
-	logrus.Infof("Fetching messages from channel %s (%s) from %s to %s",
-		channelName, channelID, oldestTime.Format(time.RFC3339), latestTime.Format(time.RFC3339))
+// daysToFetch returns the number of days to fetch for a channel: its own override, if set,
+// or the global days_to_fetch
+func (s *SlackAdapter) daysToFetch(mapping config.ChannelMapping) int {
+	if mapping.DaysToFetch > 0 {
+		return mapping.DaysToFetch
+	}
+	return s.config.DaysToFetch
+}
+
+// fetchChannelMessages retrieves the messages of a Slack channel sent between the oldest and
+// latest timestamps, newest first, until limit new messages were fetched. When it stops before
+// reaching oldest, after the limit or an error, it also returns the timestamp of the oldest
+// message it got, so a later fetch can continue from there.
+func (s *SlackAdapter) fetchChannelMessages(ctx context.Context, channelID, channelName, oldest, latest string, limit int) ([]SlackMessage, string, error) {
+	s.log().Infof("Fetching messages from channel %s (%s) from %s to %s", channelName, channelID, oldest, latest)
 
 	var allMessages []SlackMessage
-	latest := latestTime.Unix()
-	oldest := oldestTime.Unix()
 	cursor := ""
-
-	// Keep original latest time for consistent time range
-	originalLatest := latest
+	reached := "" // oldest message of the pages fetched so far
 
 	// Load existing messages from storage
 	existingMessages, err := s.loadMessagesFromStorage(channelID)
 	if err != nil {
-		logrus.Debugf("No existing messages found for channel %s: %v", channelID, err)
+		s.log().Debugf("No existing messages found for channel %s: %v", channelID, err)
 		existingMessages = []SlackMessage{}
 	} else {
-		logrus.Infof("Loaded %d existing messages from storage for channel %s", len(existingMessages), channelID)
+		s.log().Infof("Loaded %d existing messages from storage for channel %s", len(existingMessages), channelID)
 	}
 
 	// Create a map of existing message timestamps for deduplication
@@ -475,95 +660,102 @@ func (s *SlackAdapter) fetchChannelMessages(ctx context.Context, channelID, chan
 	for _, msg := range existingMessages {
 		existingTimestamps[msg.Timestamp] = true
 	}
-	logrus.Infof("Created deduplication map with %d existing timestamps for channel %s", len(existingTimestamps), channelID)
+	s.log().Infof("Created deduplication map with %d existing timestamps for channel %s", len(existingTimestamps), channelID)
 
 	pageCount := 0
 	for {
 		pageCount++
-		logrus.Infof("Fetching page %d for channel %s (cursor: %s)", pageCount, channelID, cursor)
+		s.log().Infof("Fetching page %d for channel %s (cursor: %s)", pageCount, channelID, cursor)
 
 		params := slack.GetConversationHistoryParameters{
 			ChannelID: channelID,
-			Latest:    fmt.Sprintf("%d", originalLatest), // Use original latest time
-			Oldest:    fmt.Sprintf("%d", oldest),
+			Latest:    latest,
+			Oldest:    oldest,
 			Limit:     200, // Slack API limit
 			Cursor:    cursor,
 		}
 
-		logrus.Debugf("API call parameters: ChannelID=%s, Latest=%s, Oldest=%s, Limit=%d, Cursor=%s",
+		s.log().Debugf("API call parameters: ChannelID=%s, Latest=%s, Oldest=%s, Limit=%d, Cursor=%s",
 			params.ChannelID, params.Latest, params.Oldest, params.Limit, params.Cursor)
 
 		var history *slack.GetConversationHistoryResponse
-		retryConfig := utils.DefaultRetryConfig()
-		retryConfig.BaseDelay = 1 * time.Second
-		retryConfig.MaxDelay = 5 * time.Minute // Allow longer delays for Slack rate limits
-		retryConfig.MaxRetries = 5             // More retries for Slack API
-
-		err := utils.RetryWithBackoff(ctx, retryConfig, func() error {
+		err := utils.RetryWithBackoff(ctx, s.retryConfig(), func() error {
+			if err := s.waitForRateLimit(ctx); err != nil {
+				return err
+			}
 			var err error
-			history, err = s.client.GetConversationHistory(&params)
+			history, err = s.client.GetConversationHistoryContext(ctx, &params)
 			return err
 		})
 
 		if err != nil {
-			logrus.Errorf("Failed to get conversation history for channel %s after retries: %v", channelID, err)
-			return nil, fmt.Errorf("failed to get conversation history after retries: %w", err)
+			s.log().Errorf("Failed to get conversation history for channel %s after retries: %v", channelID, err)
+			return allMessages, reached, fmt.Errorf("failed to get conversation history after retries: %w", err)
 		}
 
-		logrus.Infof("API response for channel %s: %d messages, has_more=%v, next_cursor=%s",
+		s.log().Infof("API response for channel %s: %d messages, has_more=%v, next_cursor=%s",
 			channelID, len(history.Messages), history.HasMore, history.ResponseMetaData.NextCursor)
 
 		// Convert Slack messages to our format
 		newMessagesCount := 0
 		for _, msg := range history.Messages {
+			reached = olderSlackTimestamp(reached, msg.Timestamp)
+
 			// Skip if we already have this message
 			if existingTimestamps[msg.Timestamp] {
-				logrus.Debugf("Skipping duplicate message with timestamp %s", msg.Timestamp)
+				s.log().Debugf("Skipping duplicate message with timestamp %s", msg.Timestamp)
 				continue
 			}
 
-			slackMsg := s.convertSlackMessage(msg.Msg, channelName)
+			slackMsg := s.convertSlackMessage(ctx, msg.Msg, channelName)
 			allMessages = append(allMessages, slackMsg)
 			newMessagesCount++
 
-			logrus.Debugf("Added message: timestamp=%s, user=%s, text_length=%d",
+			s.log().Debugf("Added message: timestamp=%s, user=%s, text_length=%d",
 				msg.Timestamp, msg.User, len(msg.Text))
 		}
 
-		logrus.Infof("Processed %d new messages from page %d for channel %s", newMessagesCount, pageCount, channelID)
+		s.log().Infof("Processed %d new messages from page %d for channel %s", newMessagesCount, pageCount, channelID)
 
 		// Break if no more messages or reached limit
 		if history.ResponseMetaData.NextCursor == "" || len(history.Messages) == 0 {
-			logrus.Infof("Reached end of messages for channel %s (has_more=%v, messages=%d)",
+			s.log().Infof("Reached end of messages for channel %s (has_more=%v, messages=%d)",
 				channelID, history.HasMore, len(history.Messages))
+			reached = ""
 			break
 		}
 
 		// Check if we've reached the message limit
-		if len(allMessages) >= s.config.MessageLimit {
-			logrus.Infof("Reached message limit (%d) for channel %s", s.config.MessageLimit, channelID)
+		if len(allMessages) >= limit {
+			s.log().Infof("Reached message limit (%d) for channel %s at message %s", limit, channelID, reached)
 			break
 		}
 
 		cursor = history.ResponseMetaData.NextCursor
 	}
 
-	logrus.Infof("Total new messages fetched for channel %s: %d", channelID, len(allMessages))
+	s.log().Infof("Total new messages fetched for channel %s: %d", channelID, len(allMessages))
 
 	// Return only newly fetched messages; merging with existing will be handled by storage layer
-	return allMessages, nil
+	return allMessages, reached, nil
 }
 
 // convertSlackMessage converts a Slack message to our format
-func (s *SlackAdapter) convertSlackMessage(msg slack.Msg, channelName string) SlackMessage {
+func (s *SlackAdapter) convertSlackMessage(ctx context.Context, msg slack.Msg, channelName string) SlackMessage {
 	slackMsg := SlackMessage{
 		Timestamp: msg.Timestamp,
 		User:      msg.User,
+		UserName:  s.resolveUserName(ctx, msg.User),
 		Text:      msg.Text,
 		Channel:   channelName,
 		ThreadTS:  msg.ThreadTimestamp,
 	}
 
+	// Resolve mentioned users up front so renderSlackText can use the cache
+	for _, match := range slackUserMentionRegex.FindAllStringSubmatch(msg.Text, -1) {
+		s.resolveUserName(ctx, match[1])
+	}
+
 	// Add reactions if enabled
 	if s.config.IncludeReactions && len(msg.Reactions) > 0 {
 		for _, reaction := range msg.Reactions {
@@ -605,41 +797,52 @@ func (s *SlackAdapter) convertSlackMessage(msg slack.Msg, channelName string) Sl
 }
 
 // testChannelAccess tests if the bot can access the channel and attempts to join if needed
-func (s *SlackAdapter) testChannelAccess(channelID, channelName string) error {
-	logrus.Debugf("Testing access to channel %s (%s)", channelName, channelID)
+func (s *SlackAdapter) testChannelAccess(ctx context.Context, channelID, channelName string) error {
+	s.log().Debugf("Testing access to channel %s (%s)", channelName, channelID)
+
+	if err := s.waitForRateLimit(ctx); err != nil {
+		return err
+	}
 
 	// Get channel information to check membership
-	channel, err := s.client.GetConversationInfo(&slack.GetConversationInfoInput{
+	channel, err := s.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
 		ChannelID: channelID,
 	})
 	if err != nil {
-		logrus.Warnf("Failed to get channel info for %s (%s): %v - will attempt to process anyway", channelName, channelID, err)
+		s.log().Warnf("Failed to get channel info for %s (%s): %v - will attempt to process anyway", channelName, channelID, err)
 		return nil // Don't fail - some channels might be accessible during actual processing
 	}
 
-	logrus.Debugf("Channel info: Name=%s, ID=%s, IsMember=%v, IsPrivate=%v, NumMembers=%d",
+	s.log().Debugf("Channel info: Name=%s, ID=%s, IsMember=%v, IsPrivate=%v, NumMembers=%d",
 		channel.Name, channel.ID, channel.IsMember, channel.IsPrivate, channel.NumMembers)
 
+	// DMs and group DMs can't be joined; access is determined by the conversation's participants
+	if channel.IsIM || channel.IsMpIM {
+		s.log().Debugf("Channel %s (%s) is a direct message, skipping membership check", channelName, channelID)
+		return nil
+	}
+
 	// Check if bot is a member of the channel
 	if !channel.IsMember {
-		logrus.Infof("Bot is not a member of channel %s (%s) - attempting to join", channelName, channelID)
-		if err := s.joinChannel(context.Background(), channelID); err != nil {
+		s.log().Infof("Bot is not a member of channel %s (%s) - attempting to join", channelName, channelID)
+		if err := s.joinChannel(ctx, channelID); err != nil {
 			// Log detailed error information
 			s.logJoinError(channelName, channelID, err)
 
 			// Check if this is a permanent error that should skip the channel
 			if s.isPermanentJoinError(err) {
-				logrus.Errorf("Permanent join error for channel %s (%s): %v - skipping channel", channelName, channelID, err)
+				s.log().Errorf("Permanent join error for channel %s (%s): %v - skipping channel", channelName, channelID, err)
 				return fmt.Errorf("permanent join error for channel %s (%s): %w", channelName, channelID, err)
 			} else {
-				logrus.Warnf("Retryable join error for channel %s (%s): %v - will attempt to process anyway", channelName, channelID, err)
+				s.log().Warnf("Retryable join error for channel %s (%s): %v - will attempt to process anyway", channelName, channelID, err)
 				// Don't return error for retryable errors - continue processing
 			}
 		} else {
-			logrus.Infof("Successfully joined channel %s (%s)", channelName, channelID)
+			s.log().Infof("Successfully joined channel %s (%s)", channelName, channelID)
+			s.recordJoin(channelID)
 		}
 	} else {
-		logrus.Debugf("Bot is already a member of channel %s (%s)", channelName, channelID)
+		s.log().Debugf("Bot is already a member of channel %s (%s)", channelName, channelID)
 	}
 
 	return nil
@@ -647,29 +850,32 @@ func (s *SlackAdapter) testChannelAccess(channelID, channelName string) error {
 
 // logJoinError logs detailed information about channel join errors to a file
 func (s *SlackAdapter) logJoinError(channelName, channelID string, joinErr error) {
-	// Create error log file path
-	errorLogPath := filepath.Join(s.storageDir, "slack", "join_errors.log")
+	const errorLogKey = "slack/join_errors.log"
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(errorLogPath), 0755); err != nil {
-		logrus.Errorf("Failed to create error log directory: %v", err)
-		return
+	// Start a new file once the log reaches its size cap, keeping the previous one as join_errors.log.1
+	if s.config.MaxErrorLogBytes > 0 {
+		if err := rotateLogFile(s.store, errorLogKey, s.config.MaxErrorLogBytes); err != nil {
+			s.log().Warnf("Failed to rotate error log file: %v", err)
+		}
 	}
 
-	// Open file for appending
-	file, err := os.OpenFile(errorLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		logrus.Errorf("Failed to open error log file: %v", err)
+	// Append to the log; storage backends can only replace whole files
+	data, err := s.store.Read(errorLogKey)
+	if err != nil && !errors.Is(err, storage.ErrNotExist) {
+		s.log().Errorf("Failed to read error log file: %v", err)
 		return
 	}
-	defer file.Close()
 
 	// Write detailed error information
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	fmt.Fprintf(file, "[%s] JOIN_ERROR: Channel=%s ID=%s Error=%v\n",
+	data = fmt.Appendf(data, "[%s] JOIN_ERROR: Channel=%s ID=%s Error=%v\n",
 		timestamp, channelName, channelID, joinErr)
+	if err := s.store.Write(errorLogKey, data); err != nil {
+		s.log().Errorf("Failed to write error log file: %v", err)
+		return
+	}
 
-	logrus.Debugf("Join error logged to: %s", errorLogPath)
+	s.log().Debugf("Join error logged to: %s", errorLogKey)
 }
 
 // isPermanentJoinError checks if a join error is permanent and should skip the channel
@@ -701,28 +907,19 @@ func (s *SlackAdapter) isPermanentJoinError(err error) bool {
 
 // saveChannelTracking saves a tracking file with all channels and their knowledge IDs
 func (s *SlackAdapter) saveChannelTracking(allChannels []config.ChannelMapping, processed map[string]bool) error {
-	trackingPath := filepath.Join(s.storageDir, "slack", "channels", "channel_tracking.txt")
+	const trackingKey = "slack/channels/channel_tracking.txt"
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(trackingPath), 0755); err != nil {
-		return fmt.Errorf("failed to create tracking directory: %w", err)
-	}
-
-	file, err := os.Create(trackingPath)
-	if err != nil {
-		return fmt.Errorf("failed to create tracking file: %w", err)
-	}
-	defer file.Close()
+	var file strings.Builder
 
 	// Write header
-	fmt.Fprintf(file, "Slack Channel Tracking Report\n")
-	fmt.Fprintf(file, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Fprintf(file, "Total Channels: %d\n", len(allChannels))
-	fmt.Fprintf(file, "Processed Channels: %d\n", len(processed))
-	fmt.Fprintf(file, "Unprocessed Channels: %d\n", len(allChannels)-len(processed))
-	fmt.Fprintf(file, "\n")
-	fmt.Fprintf(file, "%-50s %-20s %-40s %-10s\n", "Channel Name", "Channel ID", "Knowledge ID", "Status")
-	fmt.Fprintf(file, "%s\n", strings.Repeat("-", 120))
+	fmt.Fprintf(&file, "Slack Channel Tracking Report\n")
+	fmt.Fprintf(&file, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&file, "Total Channels: %d\n", len(allChannels))
+	fmt.Fprintf(&file, "Processed Channels: %d\n", len(processed))
+	fmt.Fprintf(&file, "Unprocessed Channels: %d\n", len(allChannels)-len(processed))
+	fmt.Fprintf(&file, "\n")
+	fmt.Fprintf(&file, "%-50s %-20s %-40s %-10s\n", "Channel Name", "Channel ID", "Knowledge ID", "Status")
+	fmt.Fprintf(&file, "%s\n", strings.Repeat("-", 120))
 
 	// Sort channels by name for better readability
 	sort.Slice(allChannels, func(i, j int) bool {
@@ -741,45 +938,64 @@ func (s *SlackAdapter) saveChannelTracking(allChannels []config.ChannelMapping,
 			knowledgeID = "DEFAULT"
 		}
 
-		fmt.Fprintf(file, "%-50s %-20s %-40s %-10s\n",
+		fmt.Fprintf(&file, "%-50s %-20s %-40s %-10s\n",
 			mapping.ChannelName,
 			mapping.ChannelID,
 			knowledgeID,
 			status)
 	}
 
-	logrus.Infof("Channel tracking file saved to: %s", trackingPath)
+	if err := s.store.Write(trackingKey, []byte(file.String())); err != nil {
+		return fmt.Errorf("failed to write tracking file: %w", err)
+	}
+
+	s.log().Infof("Channel tracking file saved to: %s", trackingKey)
 	return nil
 }
 
-// messagesToFileContent converts Slack messages to markdown content
+// messagesToFileContent converts Slack messages to the content of the channel's file in the
+// configured message_format
 func (s *SlackAdapter) messagesToFileContent(messages []SlackMessage, channelName string) (string, error) {
-	var content strings.Builder
+	switch s.config.MessageFormat {
+	case slackFormatFlat:
+		return s.flatMessagesContent(messages, channelName), nil
+	case slackFormatJSON:
+		return jsonMessagesContent(messages)
+	}
 
-	// Add header
-	content.WriteString(fmt.Sprintf("# Slack Messages - %s\n\n", channelName))
-	content.WriteString(fmt.Sprintf("**Channel:** %s\n", channelName))
-	content.WriteString(fmt.Sprintf("**Total Messages:** %d\n", len(messages)))
-	content.WriteString(fmt.Sprintf("**Generated:** %s\n\n", time.Now().Format(time.RFC3339)))
-	content.WriteString("---\n\n")
+	var content strings.Builder
+	writeSlackHeader(&content, messages, channelName)
 
-	// Add messages
-	for _, msg := range messages {
+	// Add messages, in the order they are stored unless replies are nested under their parent
+	entries := make([]nestedSlackMessage, 0, len(messages))
+	if s.config.MessageFormat == slackFormatNested {
+		entries = nestSlackMessages(messages)
+	} else {
+		for _, msg := range messages {
+			entries = append(entries, nestedSlackMessage{message: msg})
+		}
+	}
+	for _, entry := range entries {
+		msg := entry.message
 		timestamp, err := strconv.ParseFloat(msg.Timestamp, 64)
 		if err != nil {
-			logrus.Warnf("Failed to parse timestamp %s: %v", msg.Timestamp, err)
+			s.log().Warnf("Failed to parse timestamp %s: %v", msg.Timestamp, err)
 			continue
 		}
 
 		msgTime := time.Unix(int64(timestamp), 0)
-		content.WriteString(fmt.Sprintf("## %s\n", msgTime.Format("2006-01-02 15:04:05")))
+		if entry.reply {
+			content.WriteString(fmt.Sprintf("### Reply - %s\n", msgTime.Format("2006-01-02 15:04:05")))
+		} else {
+			content.WriteString(fmt.Sprintf("## %s\n", msgTime.Format("2006-01-02 15:04:05")))
+		}
 
 		if msg.User != "" {
-			content.WriteString(fmt.Sprintf("**User:** %s\n", msg.User))
+			content.WriteString(fmt.Sprintf("**User:** %s\n", s.messageUserName(msg)))
 		}
 
 		if msg.Text != "" {
-			content.WriteString(fmt.Sprintf("**Message:**\n%s\n", msg.Text))
+			content.WriteString(fmt.Sprintf("**Message:**\n%s\n", s.renderSlackText(msg.Text)))
 		}
 
 		// Add thread information
@@ -791,7 +1007,11 @@ func (s *SlackAdapter) messagesToFileContent(messages []SlackMessage, channelNam
 		if len(msg.Reactions) > 0 {
 			content.WriteString("**Reactions:**\n")
 			for _, reaction := range msg.Reactions {
-				content.WriteString(fmt.Sprintf("- :%s: %d (%s)\n", reaction.Name, reaction.Count, strings.Join(reaction.Users, ", ")))
+				users := make([]string, 0, len(reaction.Users))
+				for _, user := range reaction.Users {
+					users = append(users, s.cachedUserName(user))
+				}
+				content.WriteString(fmt.Sprintf("- :%s: %d (%s)\n", reaction.Name, reaction.Count, strings.Join(users, ", ")))
 			}
 		}
 
@@ -811,7 +1031,7 @@ func (s *SlackAdapter) messagesToFileContent(messages []SlackMessage, channelNam
 					content.WriteString(fmt.Sprintf("- **%s**\n", attachment.Title))
 				}
 				if attachment.Text != "" {
-					content.WriteString(fmt.Sprintf("  %s\n", attachment.Text))
+					content.WriteString(fmt.Sprintf("  %s\n", s.renderSlackText(attachment.Text)))
 				}
 			}
 		}
@@ -822,17 +1042,70 @@ func (s *SlackAdapter) messagesToFileContent(messages []SlackMessage, channelNam
 	return content.String(), nil
 }
 
-// saveMessagesToStorage saves messages to local storage for history tracking
+// slackTokenRegex matches Slack mrkdwn tokens such as <@U123>, <#C123|general> and <https://x|label>
+var slackTokenRegex = regexp.MustCompile(`<([^<>\s][^<>]*)>`)
+
+// slackUserMentionRegex matches user mention tokens and captures the user ID
+var slackUserMentionRegex = regexp.MustCompile(`<@([A-Z0-9]+)(?:\|[^<>]*)?>`)
+
+// renderSlackText rewrites Slack mention and link tokens into readable markdown
+func (s *SlackAdapter) renderSlackText(text string) string {
+	rendered := slackTokenRegex.ReplaceAllStringFunc(text, func(token string) string {
+		inner := token[1 : len(token)-1]
+		target, label := inner, ""
+		if idx := strings.Index(inner, "|"); idx >= 0 {
+			target, label = inner[:idx], inner[idx+1:]
+		}
+
+		switch {
+		case strings.HasPrefix(target, "@"):
+			userID := target[1:]
+			name := s.cachedUserName(userID)
+			if name == userID && label != "" {
+				name = label
+			}
+			return "@" + strings.TrimPrefix(name, "@")
+		case strings.HasPrefix(target, "#"):
+			channelID := target[1:]
+			name := label
+			if name == "" {
+				name = s.cachedChannelName(channelID)
+			}
+			return "#" + name
+		case strings.HasPrefix(target, "!"):
+			// Special mentions (<!here>, <!channel>) and formatted tokens (<!subteam^ID|@team>)
+			if label != "" {
+				return label
+			}
+			return "@" + strings.SplitN(target[1:], "^", 2)[0]
+		default:
+			if label != "" {
+				return fmt.Sprintf("[%s](%s)", label, target)
+			}
+			return target
+		}
+	})
+
+	// Slack escapes these characters in message text
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(rendered)
+}
+
+// cachedChannelName returns the name of a channel from the discovery cache, or the ID if unknown
+func (s *SlackAdapter) cachedChannelName(channelID string) string {
+	for _, channel := range s.cachedChannels {
+		if channel.ID == channelID && channel.Name != "" {
+			return channel.Name
+		}
+	}
+	return channelID
+}
+
+// saveMessagesToStorage saves messages to the storage for history tracking
 func (s *SlackAdapter) saveMessagesToStorage(channelID, channelName string, messages []SlackMessage) error {
 	if !s.config.MaintainHistory {
 		return nil // Don't save if not maintaining history
 	}
 
-	storagePath := filepath.Join(s.storageDir, "slack", "channels", channelID)
-	if err := os.MkdirAll(storagePath, 0755); err != nil {
-		return fmt.Errorf("failed to create storage directory: %w", err)
-	}
-
 	// Load existing messages
 	existingMessages, err := s.loadMessagesFromStorage(channelID)
 	if err != nil {
@@ -858,7 +1131,7 @@ func (s *SlackAdapter) saveMessagesToStorage(channelID, channelName string, mess
 			added++
 		}
 	}
-	logrus.Infof("Deduplicated Slack messages for channel %s: existing=%d, new=%d, added=%d, total=%d", channelName, len(existingMessages), len(messages), added, len(deduped))
+	s.log().Infof("Deduplicated Slack messages for channel %s: existing=%d, new=%d, added=%d, total=%d", channelName, len(existingMessages), len(messages), added, len(deduped))
 
 	// Sort by timestamp
 	sort.Slice(deduped, func(i, j int) bool {
@@ -868,20 +1141,17 @@ func (s *SlackAdapter) saveMessagesToStorage(channelID, channelName string, mess
 	})
 
 	// Save to file
-	filePath := filepath.Join(storagePath, "messages.json")
 	data, err := json.MarshalIndent(deduped, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal messages: %w", err)
 	}
 
-	return os.WriteFile(filePath, data, 0644)
+	return s.store.Write(slackChannelKey(channelID, "messages.json"), data)
 }
 
-// loadMessagesFromStorage loads messages from local storage
+// loadMessagesFromStorage loads messages from the storage
 func (s *SlackAdapter) loadMessagesFromStorage(channelID string) ([]SlackMessage, error) {
-	filePath := filepath.Join(s.storageDir, "slack", "channels", channelID, "messages.json")
-
-	data, err := os.ReadFile(filePath)
+	data, err := s.store.Read(slackChannelKey(channelID, "messages.json"))
 	if err != nil {
 		return nil, err
 	}
@@ -898,20 +1168,22 @@ func (s *SlackAdapter) loadMessagesFromStorage(channelID string) ([]SlackMessage
 	return messages, nil
 }
 
-// listLocalChannels scans local storage and returns channel mappings for any channel directories found.
+// listLocalChannels scans the storage and returns channel mappings for any channel directories found.
 // ChannelName is set to the directory name if a recent message file includes a channel name; otherwise uses ID.
 func (s *SlackAdapter) listLocalChannels() []config.ChannelMapping {
-	root := filepath.Join(s.storageDir, "slack", "channels")
-	entries, err := os.ReadDir(root)
+	const prefix = "slack/channels/"
+	keys, err := s.store.List(prefix)
 	if err != nil {
 		return nil
 	}
 	var mappings []config.ChannelMapping
-	for _, e := range entries {
-		if !e.IsDir() {
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		chID, _, isDir := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+		if !isDir || seen[chID] {
 			continue
 		}
-		chID := e.Name()
+		seen[chID] = true
 		// Try to read messages.json and extract a channel name from the last entry
 		name := chID
 		msgs, err := s.loadMessagesFromStorage(chID)
@@ -930,6 +1202,13 @@ func (s *SlackAdapter) listLocalChannels() []config.ChannelMapping {
 	return mappings
 }
 
+// RequestFullFetch makes the next fetch return the files attached to every stored message of a
+// channel with maintain_history, rather than only those of new messages. Channel files always
+// hold every stored message.
+func (s *SlackAdapter) RequestFullFetch() {
+	s.fullFetch = true
+}
+
 // GetLastSync returns the last sync time
 func (s *SlackAdapter) GetLastSync() time.Time {
 	return s.lastSync
@@ -940,6 +1219,12 @@ func (s *SlackAdapter) SetLastSync(t time.Time) {
 	s.lastSync = t
 }
 
+// channelFileID returns the stable ID of a channel's messages file, so a renamed channel
+// replaces its file instead of adding a second one
+func channelFileID(channelID string) string {
+	return "channel:" + channelID
+}
+
 // sanitizeChannelName sanitizes channel name for use in filenames
 func sanitizeChannelName(name string) string {
 	// Remove # prefix and replace invalid characters
@@ -965,48 +1250,48 @@ func sanitizeChannelName(name string) string {
 // discoverChannelsByRegex discovers channels that match the configured regex patterns
 func (s *SlackAdapter) discoverChannelsByRegex(ctx context.Context) ([]config.ChannelMapping, error) {
 	if len(s.config.RegexPatterns) == 0 {
-		logrus.Debugf("No regex patterns configured for channel discovery")
+		s.log().Debugf("No regex patterns configured for channel discovery")
 		return []config.ChannelMapping{}, nil
 	}
 
-	logrus.Infof("Starting channel discovery using %d regex patterns", len(s.config.RegexPatterns))
+	s.log().Infof("Starting channel discovery using %d regex patterns", len(s.config.RegexPatterns))
 
 	// Get all channels the bot can see (use cache if available, otherwise fetch)
 	var channels []slack.Channel
 	var err error
 
 	if len(s.cachedChannels) > 0 {
-		logrus.Debugf("Using cached channel list (%d channels)", len(s.cachedChannels))
+		s.log().Debugf("Using cached channel list (%d channels)", len(s.cachedChannels))
 		channels = s.cachedChannels
 	} else {
-		logrus.Debugf("Fetching fresh channel list...")
+		s.log().Debugf("Fetching fresh channel list...")
 		channels, err = s.getAllChannels(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get channels: %w", err)
 		}
 		// Cache the channels for this sync session
 		s.cachedChannels = channels
-		logrus.Debugf("Cached %d channels for this sync session", len(channels))
+		s.log().Debugf("Cached %d channels for this sync session", len(channels))
 	}
 
-	logrus.Debugf("Found %d total channels to evaluate against regex patterns", len(channels))
+	s.log().Debugf("Found %d total channels to evaluate against regex patterns", len(channels))
 
 	// Log all discovered channels for debugging
-	logrus.Infof("All discovered channels:")
+	s.log().Infof("All discovered channels:")
 	for i, channel := range channels {
 		if i < 10 { // Log first 10 channels
-			logrus.Infof("  %d: %s (%s) - Member: %v, Private: %v", i+1, channel.Name, channel.ID, channel.IsMember, channel.IsPrivate)
+			s.log().Infof("  %d: %s (%s) - Member: %v, Private: %v", i+1, channel.Name, channel.ID, channel.IsMember, channel.IsPrivate)
 		}
 	}
 	if len(channels) > 10 {
-		logrus.Infof("  ... and %d more channels", len(channels)-10)
+		s.log().Infof("  ... and %d more channels", len(channels)-10)
 	}
 
 	// Debug: Log all discovered channels for analysis
-	logrus.Infof("All discovered channels:")
+	s.log().Infof("All discovered channels:")
 	for i, channel := range channels {
 		if i < 10 { // Log first 10 channels
-			logrus.Infof("Channel %d: %s (%s) - Member: %v, Private: %v",
+			s.log().Infof("Channel %d: %s (%s) - Member: %v, Private: %v",
 				i+1, channel.Name, channel.ID, channel.IsMember, channel.IsPrivate)
 		}
 	}
@@ -1016,13 +1301,16 @@ func (s *SlackAdapter) discoverChannelsByRegex(ctx context.Context) ([]config.Ch
 
 	// Process each regex pattern
 	for _, pattern := range s.config.RegexPatterns {
-		logrus.Infof("Evaluating regex pattern: %s (knowledge: %s, auto_join: %v)",
+		s.log().Infof("Evaluating regex pattern: %s (knowledge: %s, auto_join: %v)",
 			pattern.Pattern, pattern.KnowledgeID, pattern.AutoJoin)
 
 		// Compile the regex pattern
+		patternCoverage := SlackPatternCoverage{Pattern: pattern.Pattern}
 		regex, err := regexp.Compile(pattern.Pattern)
 		if err != nil {
-			logrus.Errorf("Invalid regex pattern '%s': %v", pattern.Pattern, err)
+			s.log().Errorf("Invalid regex pattern '%s': %v", pattern.Pattern, err)
+			patternCoverage.Error = err.Error()
+			s.patternCoverage = append(s.patternCoverage, patternCoverage)
 			continue
 		}
 
@@ -1032,22 +1320,33 @@ func (s *SlackAdapter) discoverChannelsByRegex(ctx context.Context) ([]config.Ch
 			if seenChannels[channel.ID] {
 				continue
 			}
+			// DMs have no name to match against; they can only be synced through explicit mappings
+			if channel.IsIM {
+				continue
+			}
 
 			// Check if channel name matches the pattern
 			if regex.MatchString(channel.Name) {
-				logrus.Debugf("Regex match: pattern='%s' channel='%s' id='%s'", pattern.Pattern, channel.Name, channel.ID)
-				logrus.Infof("Channel '%s' (%s) matches pattern '%s'", channel.Name, channel.ID, pattern.Pattern)
+				s.log().Debugf("Regex match: pattern='%s' channel='%s' id='%s'", pattern.Pattern, channel.Name, channel.ID)
+				s.log().Infof("Channel '%s' (%s) matches pattern '%s'", channel.Name, channel.ID, pattern.Pattern)
+				patternCoverage.Matched++
 
 				// Check if we need to join the channel
-				if pattern.AutoJoin && !channel.IsMember {
-					logrus.Infof("Auto-joining channel '%s' (%s)", channel.Name, channel.ID)
+				if pattern.AutoJoin && !channel.IsMember && !channel.IsMpIM {
+					s.log().Infof("Auto-joining channel '%s' (%s)", channel.Name, channel.ID)
 					if err := s.joinChannel(ctx, channel.ID); err != nil {
-						logrus.Errorf("Failed to join channel '%s' (%s): %v", channel.Name, channel.ID, err)
+						s.log().Errorf("Failed to join channel '%s' (%s): %v", channel.Name, channel.ID, err)
 						// Log detailed error information
 						s.logJoinError(channel.Name, channel.ID, err)
+						s.recordChannelError(channel.ID, err)
+						patternCoverage.JoinFailed++
+						// Don't count the channel again for a later pattern
+						seenChannels[channel.ID] = true
 						continue
 					}
-					logrus.Infof("Successfully joined channel '%s' (%s)", channel.Name, channel.ID)
+					s.log().Infof("Successfully joined channel '%s' (%s)", channel.Name, channel.ID)
+					s.recordJoin(channel.ID)
+					patternCoverage.Joined++
 				}
 
 				// Add to discovered channels
@@ -1055,22 +1354,24 @@ func (s *SlackAdapter) discoverChannelsByRegex(ctx context.Context) ([]config.Ch
 					ChannelID:   channel.ID,
 					ChannelName: channel.Name,
 					KnowledgeID: pattern.KnowledgeID,
+					DaysToFetch: pattern.DaysToFetch,
 				})
 
 				seenChannels[channel.ID] = true
-				logrus.Infof("Added discovered channel: %s (%s) -> knowledge %s",
+				s.log().Infof("Added discovered channel: %s (%s) -> knowledge %s",
 					channel.Name, channel.ID, pattern.KnowledgeID)
 			}
 		}
+		s.patternCoverage = append(s.patternCoverage, patternCoverage)
 	}
 
-	logrus.Infof("Channel discovery completed: found %d matching channels", len(discoveredChannels))
+	s.log().Infof("Channel discovery completed: found %d matching channels", len(discoveredChannels))
 	return discoveredChannels, nil
 }
 
 // getAllChannels retrieves all channels the bot can access
 func (s *SlackAdapter) getAllChannels(ctx context.Context) ([]slack.Channel, error) {
-	logrus.Debugf("Fetching all accessible channels...")
+	s.log().Debugf("Fetching all accessible channels...")
 
 	var allChannels []slack.Channel
 	cursor := ""
@@ -1094,14 +1395,12 @@ func (s *SlackAdapter) getAllChannels(ctx context.Context) ([]slack.Channel, err
 		var nextCursor string
 		var err error
 
-		retryConfig := utils.DefaultRetryConfig()
-		retryConfig.BaseDelay = 2 * time.Second
-		retryConfig.MaxDelay = 5 * time.Minute // Allow longer delays for Slack rate limits
-		retryConfig.MaxRetries = 5             // More retries for Slack API
-
-		err = utils.RetryWithBackoff(ctx, retryConfig, func() error {
-			channels, nextCursor, err = s.client.GetConversations(&slack.GetConversationsParameters{
-				Types:  []string{"public_channel", "private_channel"},
+		err = utils.RetryWithBackoff(ctx, s.retryConfig(), func() error {
+			if err := s.waitForRateLimit(ctx); err != nil {
+				return err
+			}
+			channels, nextCursor, err = s.client.GetConversationsContext(ctx, &slack.GetConversationsParameters{
+				Types:  s.conversationTypes(),
 				Cursor: cursor,
 				Limit:  200, // Maximum allowed by Slack API
 			})
@@ -1109,15 +1408,15 @@ func (s *SlackAdapter) getAllChannels(ctx context.Context) ([]slack.Channel, err
 		})
 
 		if err != nil {
-			logrus.Errorf("Failed to get conversations after retries: %v", err)
+			s.log().Errorf("Failed to get conversations after retries: %v", err)
 			return nil, fmt.Errorf("failed to get conversations after retries: %w", err)
 		}
 
-		logrus.Debugf("Retrieved %d channels (cursor: %s)", len(channels), cursor)
+		s.log().Debugf("Retrieved %d channels (cursor: %s)", len(channels), cursor)
 
 		// Log each channel name for debugging
 		for _, channel := range channels {
-			logrus.Debugf("Retrieved channel: %s (ID: %s, Member: %v, Private: %v)",
+			s.log().Debugf("Retrieved channel: %s (ID: %s, Member: %v, Private: %v)",
 				channel.Name, channel.ID, channel.IsMember, channel.IsPrivate)
 		}
 
@@ -1131,22 +1430,28 @@ func (s *SlackAdapter) getAllChannels(ctx context.Context) ([]slack.Channel, err
 		cursor = nextCursor
 	}
 
-	logrus.Debugf("Total channels retrieved: %d", len(allChannels))
+	s.log().Debugf("Total channels retrieved: %d", len(allChannels))
 	return allChannels, nil
 }
 
+// conversationTypes returns the configured conversation types, falling back to the defaults
+func (s *SlackAdapter) conversationTypes() []string {
+	if len(s.config.ConversationTypes) == 0 {
+		return defaultConversationTypes
+	}
+	return s.config.ConversationTypes
+}
+
 // joinChannel attempts to join a Slack channel with retry logic
 func (s *SlackAdapter) joinChannel(ctx context.Context, channelID string) error {
-	logrus.Debugf("Attempting to join channel: %s", channelID)
+	s.log().Debugf("Attempting to join channel: %s", channelID)
 
-	retryConfig := utils.DefaultRetryConfig()
-	retryConfig.BaseDelay = 1 * time.Second
-	retryConfig.MaxDelay = 30 * time.Second
-	retryConfig.MaxRetries = 3
-
-	err := utils.RetryWithBackoff(ctx, retryConfig, func() error {
+	err := utils.RetryWithBackoff(ctx, s.lookupRetryConfig(), func() error {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return err
+		}
 		// Use the Slack API to join the channel
-		_, _, _, err := s.client.JoinConversation(channelID)
+		_, _, _, err := s.client.JoinConversationContext(ctx, channelID)
 		return err
 	})
 
@@ -1154,6 +1459,6 @@ func (s *SlackAdapter) joinChannel(ctx context.Context, channelID string) error
 		return fmt.Errorf("failed to join channel %s after retries: %w", channelID, err)
 	}
 
-	logrus.Debugf("Successfully joined channel: %s", channelID)
+	s.log().Debugf("Successfully joined channel: %s", channelID)
 	return nil
 }
@@ -0,0 +1,129 @@
+package adapter
+
+import (
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// SlackCoverage summarizes how many of the channels the last Slack fetch should have synced
+// it actually covered, so a knowledge base left incomplete by rate limits or missing access
+// doesn't go unnoticed. Every channel is counted once as processed, without new messages, or
+// skipped after a permanent or retryable error.
+type SlackCoverage struct {
+	Patterns         []SlackPatternCoverage `json:"patterns,omitempty"`
+	DiscoveryError   string                 `json:"discovery_error,omitempty"` // regex discovery failed, e.g. rate limited, so only mapped and known channels were synced
+	Channels         int                    `json:"channels"`
+	Processed        int                    `json:"processed"`
+	NoMessages       int                    `json:"no_messages"`
+	Joined           int                    `json:"joined"`
+	SkippedPermanent int                    `json:"skipped_permanent"` // e.g. archived or deleted channels, or revoked tokens
+	SkippedRetryable int                    `json:"skipped_retryable"` // e.g. rate limits and network errors
+}
+
+// SlackPatternCoverage counts the channels a regex pattern discovered. Channels matched by an
+// earlier pattern are counted for that pattern only.
+type SlackPatternCoverage struct {
+	Pattern    string `json:"pattern"`
+	Matched    int    `json:"matched"`
+	Joined     int    `json:"joined"`
+	JoinFailed int    `json:"join_failed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// resetCoverage starts the coverage bookkeeping of a fetch
+func (s *SlackAdapter) resetCoverage() {
+	s.patternCoverage = nil
+	s.joinedChannels = make(map[string]bool)
+	s.channelErrors = make(map[string]error)
+}
+
+// recordChannelError notes why a channel was skipped during the current fetch
+func (s *SlackAdapter) recordChannelError(channelID string, err error) {
+	if s.channelErrors == nil {
+		s.channelErrors = make(map[string]error)
+	}
+	s.channelErrors[channelID] = err
+}
+
+// recordJoin notes a channel joined during the current fetch
+func (s *SlackAdapter) recordJoin(channelID string) {
+	if s.joinedChannels == nil {
+		s.joinedChannels = make(map[string]bool)
+	}
+	s.joinedChannels[channelID] = true
+}
+
+// Coverage returns the channel coverage of the last fetch, reported in the adapter's status
+func (s *SlackAdapter) Coverage() any {
+	s.coverageMu.Lock()
+	defer s.coverageMu.Unlock()
+	return s.coverage
+}
+
+// finishCoverage computes, logs and stores the coverage of the fetch that just ended
+func (s *SlackAdapter) finishCoverage(channels []config.ChannelMapping, processed map[string]bool, discoveryErr error) {
+	coverage := s.summarizeCoverage(s.patternCoverage, channels, processed, s.joinedChannels, s.channelErrors, discoveryErr)
+
+	entry := s.log().
+		WithField("channels", coverage.Channels).
+		WithField("processed", coverage.Processed).
+		WithField("no_messages", coverage.NoMessages).
+		WithField("joined", coverage.Joined).
+		WithField("skipped_permanent", coverage.SkippedPermanent).
+		WithField("skipped_retryable", coverage.SkippedRetryable)
+	if coverage.DiscoveryError != "" {
+		entry = entry.WithField("discovery_error", coverage.DiscoveryError)
+	}
+	if coverage.SkippedPermanent > 0 || coverage.SkippedRetryable > 0 || coverage.DiscoveryError != "" {
+		entry.Warnf("Slack channel coverage is incomplete")
+	} else {
+		entry.Infof("Slack channel coverage")
+	}
+	for _, pattern := range coverage.Patterns {
+		s.log().
+			WithField("pattern", pattern.Pattern).
+			WithField("matched", pattern.Matched).
+			WithField("joined", pattern.Joined).
+			WithField("join_failed", pattern.JoinFailed).
+			Infof("Slack regex pattern coverage")
+	}
+
+	s.coverageMu.Lock()
+	s.coverage = coverage
+	s.coverageMu.Unlock()
+}
+
+// summarizeCoverage counts the outcome of every channel of a fetch: the merged channel list,
+// plus channels that regex discovery matched but failed to join, which never made it into the
+// list
+func (s *SlackAdapter) summarizeCoverage(patterns []SlackPatternCoverage, channels []config.ChannelMapping, processed, joined map[string]bool, failures map[string]error, discoveryErr error) SlackCoverage {
+	coverage := SlackCoverage{
+		Patterns: append([]SlackPatternCoverage(nil), patterns...),
+		Joined:   len(joined),
+	}
+	if discoveryErr != nil {
+		coverage.DiscoveryError = discoveryErr.Error()
+	}
+
+	ids := make(map[string]bool, len(channels)+len(failures))
+	for _, mapping := range channels {
+		ids[mapping.ChannelID] = true
+	}
+	for id := range failures {
+		ids[id] = true
+	}
+
+	for id := range ids {
+		coverage.Channels++
+		switch err := failures[id]; {
+		case processed[id]:
+			coverage.Processed++
+		case err == nil:
+			coverage.NoMessages++
+		case s.isPermanentJoinError(err):
+			coverage.SkippedPermanent++
+		default:
+			coverage.SkippedRetryable++
+		}
+	}
+	return coverage
+}
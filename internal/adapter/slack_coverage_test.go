@@ -0,0 +1,80 @@
+package adapter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestSlackAdapter_summarizeCoverage(t *testing.T) {
+	patterns := []SlackPatternCoverage{
+		{Pattern: "^team-", Matched: 3, Joined: 1, JoinFailed: 1},
+		{Pattern: "[", Error: "missing closing ]"},
+	}
+	channels := []config.ChannelMapping{
+		{ChannelID: "C1", ChannelName: "team-a"},
+		{ChannelID: "C2", ChannelName: "team-b"},
+		{ChannelID: "C3", ChannelName: "general"},
+		{ChannelID: "C4", ChannelName: "old"},
+		{ChannelID: "C5", ChannelName: "busy"},
+	}
+
+	tests := []struct {
+		name         string
+		processed    map[string]bool
+		joined       map[string]bool
+		failures     map[string]error
+		discoveryErr error
+		expected     SlackCoverage
+	}{
+		{
+			name:      "all channels processed",
+			processed: map[string]bool{"C1": true, "C2": true, "C3": true, "C4": true, "C5": true},
+			expected:  SlackCoverage{Patterns: patterns, Channels: 5, Processed: 5},
+		},
+		{
+			name:      "skipped channels are split by error kind",
+			processed: map[string]bool{"C1": true, "C2": true},
+			joined:    map[string]bool{"C2": true},
+			failures: map[string]error{
+				"C4": fmt.Errorf("permanent join error for channel old (C4): is_archived"),
+				"C5": fmt.Errorf("ratelimited"),
+				// Failed to join during discovery, so it never made it into the channel list
+				"C6": fmt.Errorf("channel_not_found"),
+			},
+			expected: SlackCoverage{
+				Patterns:         patterns,
+				Channels:         6,
+				Processed:        2,
+				NoMessages:       1,
+				Joined:           1,
+				SkippedPermanent: 2,
+				SkippedRetryable: 1,
+			},
+		},
+		{
+			name:         "discovery error is reported",
+			processed:    map[string]bool{"C1": true},
+			discoveryErr: fmt.Errorf("failed to get channels: ratelimited"),
+			expected: SlackCoverage{
+				Patterns:       patterns,
+				DiscoveryError: "failed to get channels: ratelimited",
+				Channels:       5,
+				Processed:      1,
+				NoMessages:     4,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &SlackAdapter{}
+			coverage := adapter.summarizeCoverage(patterns, channels, tt.processed, tt.joined, tt.failures, tt.discoveryErr)
+			if !reflect.DeepEqual(coverage, tt.expected) {
+				t.Errorf("Expected coverage %+v, got %+v", tt.expected, coverage)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// slackChannelCursor is the fetch position of a channel with maintain_history, stored in
+// slack/channels/<id>/cursor.json. Slack returns history newest first, so a fetch stopped by
+// an error or message_limit got the newest part of its range: the messages between Oldest
+// and Reached are still missing and are fetched before any newer ones.
+type slackChannelCursor struct {
+	Oldest  string `json:"oldest"`            // every message up to this timestamp is stored
+	Latest  string `json:"latest,omitempty"`  // end of the range of an interrupted fetch
+	Reached string `json:"reached,omitempty"` // oldest message the interrupted fetch got, empty if it wasn't interrupted
+}
+
+// slackTimestamp formats t as a Slack timestamp for the oldest and latest history parameters
+func slackTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d", t.Unix())
+}
+
+// olderSlackTimestamp returns the older of two Slack message timestamps, ignoring empty ones
+func olderSlackTimestamp(a, b string) string {
+	if a == "" {
+		return b
+	}
+	tsA, errA := strconv.ParseFloat(a, 64)
+	tsB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil && tsB < tsA {
+		return b
+	}
+	return a
+}
+
+// loadChannelCursor returns a channel's stored cursor, or false if there is none
+func (s *SlackAdapter) loadChannelCursor(channelID string) (slackChannelCursor, bool) {
+	var cursor slackChannelCursor
+	data, err := s.store.Read(slackChannelKey(channelID, "cursor.json"))
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			s.log().Warnf("Failed to read fetch cursor of channel %s: %v", channelID, err)
+		}
+		return cursor, false
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Oldest == "" {
+		s.log().Warnf("Ignoring invalid fetch cursor of channel %s", channelID)
+		return slackChannelCursor{}, false
+	}
+	return cursor, true
+}
+
+// saveChannelCursor stores a channel's cursor
+func (s *SlackAdapter) saveChannelCursor(channelID string, cursor slackChannelCursor) error {
+	data, err := json.MarshalIndent(cursor, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+	return s.store.Write(slackChannelKey(channelID, "cursor.json"), data)
+}
+
+// fetchChannelFromCursor fetches the messages of a channel with maintain_history from its
+// stored cursor, or from oldest if it has none, to now. It stores the messages it got, even
+// when the fetch fails part way, and then advances the cursor past them. message_limit caps
+// the messages of both the rest of an interrupted fetch and the new ones.
+func (s *SlackAdapter) fetchChannelFromCursor(ctx context.Context, channelID, channelName string, oldest, now time.Time) ([]SlackMessage, error) {
+	cursor, ok := s.loadChannelCursor(channelID)
+	if !ok {
+		cursor = slackChannelCursor{Oldest: slackTimestamp(oldest)}
+	}
+	limit := s.config.MessageLimit
+
+	var messages []SlackMessage
+	var err error
+	if cursor.Reached != "" {
+		s.log().Infof("Resuming the interrupted fetch of channel %s (%s) at message %s", channelName, channelID, cursor.Reached)
+		var fetched []SlackMessage
+		var reached string
+		fetched, reached, err = s.fetchChannelMessages(ctx, channelID, channelName, cursor.Oldest, cursor.Reached, limit)
+		messages = append(messages, fetched...)
+		limit -= len(fetched)
+		switch {
+		case reached != "":
+			cursor.Reached = reached
+		case err == nil:
+			cursor = slackChannelCursor{Oldest: cursor.Latest}
+		}
+	}
+
+	if cursor.Reached == "" && err == nil && limit > 0 {
+		latest := slackTimestamp(now)
+		var fetched []SlackMessage
+		var reached string
+		fetched, reached, err = s.fetchChannelMessages(ctx, channelID, channelName, cursor.Oldest, latest, limit)
+		messages = append(messages, fetched...)
+		switch {
+		case reached != "":
+			cursor.Latest, cursor.Reached = latest, reached
+		case err == nil:
+			cursor = slackChannelCursor{Oldest: latest}
+		}
+	}
+
+	// The cursor must not move past messages that weren't stored
+	if len(messages) > 0 {
+		if saveErr := s.saveMessagesToStorage(channelID, channelName, messages); saveErr != nil {
+			s.log().Warnf("Failed to save messages to storage for channel %s: %v", channelName, saveErr)
+			return messages, err
+		}
+	}
+	if saveErr := s.saveChannelCursor(channelID, cursor); saveErr != nil {
+		s.log().Warnf("Failed to save fetch cursor of channel %s: %v", channelName, saveErr)
+	}
+	return messages, err
+}
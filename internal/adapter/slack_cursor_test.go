@@ -0,0 +1,150 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestSlackAdapter_FetchFiles_ResumesInterruptedFetch(t *testing.T) {
+	// Five messages sent in the last five hours, newest first as Slack returns them
+	now := time.Now()
+	var channel []string
+	for hours := 1; hours <= 5; hours++ {
+		channel = append(channel, fmt.Sprintf("%d.000100", now.Add(-time.Duration(hours)*time.Hour).Unix()))
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		failPage2  bool // the first fetch fails on its second page
+		fetches    int  // fetches until every message is stored
+		firstCount int  // messages stored after the first fetch
+	}{
+		{name: "error on the second page", limit: 100, failPage2: true, fetches: 2, firstCount: 2},
+		{name: "message limit", limit: 2, fetches: 3, firstCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			fetch := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/conversations.info":
+					w.Write([]byte(`{"ok":true,"channel":{"id":"C1","is_channel":true,"is_member":true}}`))
+				case "/conversations.history":
+					mu.Lock()
+					failing := tt.failPage2 && fetch == 1 && r.Form.Get("cursor") != ""
+					mu.Unlock()
+					if failing {
+						w.Write([]byte(`{"ok":false,"error":"fatal_error"}`))
+						return
+					}
+					w.Write(slackHistoryPage(t, channel, r.Form.Get("oldest"), r.Form.Get("latest"), r.Form.Get("cursor")))
+				default:
+					w.Write([]byte(`{"ok":false,"error":"unknown_method"}`))
+				}
+			}))
+			defer server.Close()
+
+			adapter := newTestSlackAdapter(t, server, storage.NewMemory())
+			adapter.config.MaintainHistory = true
+			adapter.config.DaysToFetch = 1
+			adapter.config.MessageLimit = tt.limit
+			adapter.config.ChannelMappings = []config.ChannelMapping{{ChannelID: "C1", ChannelName: "general", KnowledgeID: "knowledge-id"}}
+
+			for i := 1; i <= tt.fetches; i++ {
+				mu.Lock()
+				fetch = i
+				mu.Unlock()
+				if _, err := adapter.FetchFiles(context.Background()); err != nil {
+					t.Fatalf("Fetch %d: FetchFiles() error = %v", i, err)
+				}
+
+				stored, _ := adapter.loadMessagesFromStorage("C1")
+				if i == 1 && len(stored) != tt.firstCount {
+					t.Errorf("Expected %d messages stored after the first fetch, got %d", tt.firstCount, len(stored))
+				}
+				cursor, ok := adapter.loadChannelCursor("C1")
+				if !ok {
+					t.Fatalf("Fetch %d: expected a stored cursor", i)
+				}
+				if i < tt.fetches && cursor.Reached == "" {
+					t.Errorf("Fetch %d: expected the cursor to record where the fetch stopped, got %+v", i, cursor)
+				}
+			}
+
+			stored, err := adapter.loadMessagesFromStorage("C1")
+			if err != nil {
+				t.Fatalf("Failed to load stored messages: %v", err)
+			}
+			if len(stored) != len(channel) {
+				t.Errorf("Expected all %d messages to be stored after resuming, got %d", len(channel), len(stored))
+			}
+			if cursor, _ := adapter.loadChannelCursor("C1"); cursor.Reached != "" {
+				t.Errorf("Expected the fetch to have caught up, got cursor %+v", cursor)
+			}
+		})
+	}
+}
+
+// slackHistoryPage returns a conversations.history page of two of the messages between oldest
+// and latest, where cursor is the index of the page's first message
+func slackHistoryPage(t *testing.T, timestamps []string, oldest, latest, cursor string) []byte {
+	t.Helper()
+	parse := func(ts string) float64 {
+		value, err := strconv.ParseFloat(ts, 64)
+		if err != nil {
+			t.Errorf("Invalid timestamp %q", ts)
+		}
+		return value
+	}
+
+	var matching []string
+	for _, ts := range timestamps {
+		if parse(ts) > parse(oldest) && parse(ts) < parse(latest) {
+			matching = append(matching, ts)
+		}
+	}
+	start, _ := strconv.Atoi(cursor)
+	end := min(start+2, len(matching))
+
+	type message struct {
+		Type string `json:"type"`
+		TS   string `json:"ts"`
+		Text string `json:"text"`
+	}
+	page := struct {
+		OK       bool      `json:"ok"`
+		Messages []message `json:"messages"`
+		HasMore  bool      `json:"has_more"`
+		Metadata struct {
+			NextCursor string `json:"next_cursor"`
+		} `json:"response_metadata"`
+	}{OK: true, Messages: []message{}}
+	for _, ts := range matching[start:end] {
+		page.Messages = append(page.Messages, message{Type: "message", TS: ts, Text: "message " + ts})
+	}
+	if end < len(matching) {
+		page.HasMore = true
+		page.Metadata.NextCursor = strconv.Itoa(end)
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("Failed to marshal history page: %v", err)
+	}
+	return data
+}
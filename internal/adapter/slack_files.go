@@ -0,0 +1,115 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// slackFileKey returns the storage key a downloaded file of a channel is stored under
+func slackFileKey(channelID string, slackFile SlackFile) string {
+	return slackChannelKey(channelID, "files", slackFile.ID+"_"+sanitizeChannelName(slackFile.Name))
+}
+
+// processMessageFiles downloads the files attached to messages and returns them as additional
+// files for the channel's knowledge base. Binary files are skipped unless download_binary_files is set.
+// Files that can't be downloaded are logged and skipped so the channel itself still syncs.
+func (s *SlackAdapter) processMessageFiles(ctx context.Context, channelID, channelName, knowledgeID string, messages []SlackMessage) []*File {
+	if !s.config.DownloadFiles {
+		return nil
+	}
+
+	var files []*File
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		for _, slackFile := range msg.Files {
+			if slackFile.ID == "" || seen[slackFile.ID] {
+				continue
+			}
+			seen[slackFile.ID] = true
+
+			if !s.config.DownloadBinaryFiles && !isTextMediaType(slackFile.Mimetype) {
+				s.log().Debugf("Skipping binary Slack file %s (%s) in channel %s", slackFile.Name, slackFile.Mimetype, channelName)
+				continue
+			}
+
+			content, err := s.loadSlackFile(ctx, channelID, slackFile)
+			if err != nil {
+				s.log().Warnf("Skipping Slack file %s in channel %s: %v", slackFile.Name, channelName, err)
+				continue
+			}
+
+			// Prefix with the channel and file ID so files with the same name don't collide
+			filename := fmt.Sprintf("%s_%s_%s", sanitizeChannelName(channelName), slackFile.ID, sanitizeChannelName(slackFile.Name))
+			files = append(files, &File{
+				Path:        filename,
+				Content:     content,
+				Hash:        fmt.Sprintf("%x", sha256.Sum256(content)),
+				Modified:    time.Now(),
+				Size:        int64(len(content)),
+				Source:      "slack",
+				KnowledgeID: knowledgeID,
+				ContentType: slackFile.Mimetype,
+			})
+		}
+	}
+
+	if len(files) > 0 {
+		s.log().Infof("Added %d file attachments from channel %s", len(files), channelName)
+	}
+	return files
+}
+
+// loadSlackFile returns the content of a Slack file, downloading it with the bot token and
+// storing it below the channel's files directory on first use
+func (s *SlackAdapter) loadSlackFile(ctx context.Context, channelID string, slackFile SlackFile) ([]byte, error) {
+	key := slackFileKey(channelID, slackFile)
+	if content, err := s.store.Read(key); err == nil {
+		return content, nil
+	}
+
+	if slackFile.URL == "" {
+		return nil, fmt.Errorf("file %s has no download URL", slackFile.ID)
+	}
+	if s.client == nil {
+		return nil, fmt.Errorf("slack client is not configured")
+	}
+
+	if err := s.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := s.client.GetFileContext(ctx, slackFile.URL, &buf); err != nil {
+		var statusErr slack.StatusCodeError
+		if errors.As(err, &statusErr) && (statusErr.Code == http.StatusForbidden || statusErr.Code == http.StatusNotFound) {
+			return nil, fmt.Errorf("file is no longer accessible (status %d); the URL may have expired or the bot lacks the files:read scope", statusErr.Code)
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	// Slack answers unauthorized or expired file URLs with its HTML sign-in page instead of an error
+	if !strings.HasPrefix(slackFile.Mimetype, "text/html") && looksLikeHTML(buf.Bytes()) {
+		return nil, fmt.Errorf("download returned an HTML page instead of the file; the URL may have expired or the bot lacks the files:read scope")
+	}
+
+	if err := s.store.Write(key, buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	s.log().Debugf("Downloaded Slack file %s to %s", slackFile.Name, key)
+
+	return buf.Bytes(), nil
+}
+
+// looksLikeHTML reports whether content starts like an HTML document
+func looksLikeHTML(content []byte) bool {
+	prefix := strings.ToLower(strings.TrimSpace(string(content[:min(len(content), 512)])))
+	return strings.HasPrefix(prefix, "<!doctype html") || strings.HasPrefix(prefix, "<html")
+}
@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of a channel's file, selected by slack.message_format
+const (
+	slackFormatThreaded = "threaded" // markdown section per message, in the order they are stored (default)
+	slackFormatNested   = "nested"   // markdown with replies nested under their parent
+	slackFormatFlat     = "flat"     // chronological markdown transcript, one line per message
+	slackFormatJSON     = "json"     // the stored messages as JSON
+)
+
+// messageFile returns the file name and content type of a channel's file in the configured format
+func (s *SlackAdapter) messageFile(channelName string) (string, string) {
+	if s.config.MessageFormat == slackFormatJSON {
+		return fmt.Sprintf("%s_messages.json", sanitizeChannelName(channelName)), "application/json"
+	}
+	return fmt.Sprintf("%s_messages.md", sanitizeChannelName(channelName)), "text/markdown"
+}
+
+// writeSlackHeader writes the heading of a channel's markdown file
+func writeSlackHeader(content *strings.Builder, messages []SlackMessage, channelName string) {
+	content.WriteString(fmt.Sprintf("# Slack Messages - %s\n\n", channelName))
+	content.WriteString(fmt.Sprintf("**Channel:** %s\n", channelName))
+	content.WriteString(fmt.Sprintf("**Total Messages:** %d\n", len(messages)))
+	content.WriteString(fmt.Sprintf("**Generated:** %s\n\n", time.Now().Format(time.RFC3339)))
+	content.WriteString("---\n\n")
+}
+
+// messageUserName returns the display name of a message's author
+func (s *SlackAdapter) messageUserName(msg SlackMessage) string {
+	if msg.UserName == "" || msg.UserName == msg.User {
+		return s.cachedUserName(msg.User)
+	}
+	return msg.UserName
+}
+
+// isSlackReply reports whether a message is a reply in another message's thread
+func isSlackReply(msg SlackMessage) bool {
+	return msg.ThreadTS != "" && msg.ThreadTS != msg.Timestamp
+}
+
+// nestedSlackMessage is a message in the order of the nested format
+type nestedSlackMessage struct {
+	message SlackMessage
+	reply   bool
+}
+
+// nestSlackMessages orders messages for the nested format: messages keep their order, and
+// each reply follows its parent, oldest first. Replies whose parent isn't among the messages
+// stay where they are.
+func nestSlackMessages(messages []SlackMessage) []nestedSlackMessage {
+	present := make(map[string]bool, len(messages))
+	for _, msg := range messages {
+		present[msg.Timestamp] = true
+	}
+	replies := make(map[string][]SlackMessage) // parent timestamp -> replies
+	for _, msg := range messages {
+		if isSlackReply(msg) && present[msg.ThreadTS] {
+			replies[msg.ThreadTS] = append(replies[msg.ThreadTS], msg)
+		}
+	}
+
+	ordered := make([]nestedSlackMessage, 0, len(messages))
+	for _, msg := range messages {
+		if isSlackReply(msg) && present[msg.ThreadTS] {
+			continue
+		}
+		ordered = append(ordered, nestedSlackMessage{message: msg})
+		thread := replies[msg.Timestamp]
+		sortSlackMessages(thread)
+		for _, reply := range thread {
+			ordered = append(ordered, nestedSlackMessage{message: reply, reply: true})
+		}
+	}
+	return ordered
+}
+
+// sortSlackMessages sorts messages by timestamp, oldest first
+func sortSlackMessages(messages []SlackMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		ts1, _ := strconv.ParseFloat(messages[i].Timestamp, 64)
+		ts2, _ := strconv.ParseFloat(messages[j].Timestamp, 64)
+		return ts1 < ts2
+	})
+}
+
+// flatMessagesContent renders messages as a chronological transcript, one line per message
+func (s *SlackAdapter) flatMessagesContent(messages []SlackMessage, channelName string) string {
+	sorted := append([]SlackMessage(nil), messages...)
+	sortSlackMessages(sorted)
+
+	var content strings.Builder
+	writeSlackHeader(&content, sorted, channelName)
+	for _, msg := range sorted {
+		timestamp, err := strconv.ParseFloat(msg.Timestamp, 64)
+		if err != nil {
+			s.log().Warnf("Failed to parse timestamp %s: %v", msg.Timestamp, err)
+			continue
+		}
+
+		line := fmt.Sprintf("[%s]", time.Unix(int64(timestamp), 0).Format("2006-01-02 15:04:05"))
+		if msg.User != "" {
+			line += " " + s.messageUserName(msg)
+		}
+		if isSlackReply(msg) {
+			line += " (thread reply)"
+		}
+		content.WriteString(fmt.Sprintf("%s: %s\n", line, s.renderSlackText(msg.Text)))
+	}
+	return content.String()
+}
+
+// jsonMessagesContent returns the messages as indented JSON
+func jsonMessagesContent(messages []SlackMessage) (string, error) {
+	if messages == nil {
+		messages = []SlackMessage{}
+	}
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,114 @@
+package adapter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestSlackAdapter_messagesToFileContent_Formats(t *testing.T) {
+	// A thread reply is stored after a later top-level message
+	messages := []SlackMessage{
+		{Timestamp: "1700000000.000100", User: "U1", UserName: "Alice", Text: "parent question", ThreadTS: "1700000000.000100"},
+		{Timestamp: "1700000100.000100", User: "U2", UserName: "Bob", Text: "later message"},
+		{Timestamp: "1700000200.000100", User: "U2", UserName: "Bob", Text: "reply answer", ThreadTS: "1700000000.000100"},
+	}
+
+	tests := []struct {
+		format      string
+		filename    string
+		contentType string
+		check       func(t *testing.T, content string)
+	}{
+		{
+			format:      "threaded",
+			filename:    "general_messages.md",
+			contentType: "text/markdown",
+			check: func(t *testing.T, content string) {
+				assertInOrder(t, content, "## ", "parent question", "## ", "later message", "## ", "reply answer")
+				if strings.Contains(content, "### Reply - ") {
+					t.Errorf("Expected messages in the order they are stored, got:\n%s", content)
+				}
+			},
+		},
+		{
+			format:      "nested",
+			filename:    "general_messages.md",
+			contentType: "text/markdown",
+			check: func(t *testing.T, content string) {
+				assertInOrder(t, content, "## ", "parent question", "### Reply - ", "reply answer", "## ", "later message")
+				if strings.Count(content, "### Reply - ") != 1 {
+					t.Errorf("Expected one nested reply, got:\n%s", content)
+				}
+			},
+		},
+		{
+			format:      "flat",
+			filename:    "general_messages.md",
+			contentType: "text/markdown",
+			check: func(t *testing.T, content string) {
+				var lines []string
+				for _, line := range strings.Split(content, "\n") {
+					if strings.HasPrefix(line, "[") {
+						lines = append(lines, line)
+					}
+				}
+				if len(lines) != 3 {
+					t.Fatalf("Expected one line per message, got:\n%s", content)
+				}
+				for i, suffix := range []string{"] Alice: parent question", "] Bob: later message", "] Bob (thread reply): reply answer"} {
+					if !strings.HasSuffix(lines[i], suffix) {
+						t.Errorf("Line %d: expected suffix %q, got %q", i, suffix, lines[i])
+					}
+				}
+			},
+		},
+		{
+			format:      "json",
+			filename:    "general_messages.json",
+			contentType: "application/json",
+			check: func(t *testing.T, content string) {
+				var decoded []SlackMessage
+				if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+					t.Fatalf("Expected JSON content, got error %v:\n%s", err, content)
+				}
+				if len(decoded) != 3 || decoded[2].ThreadTS != "1700000000.000100" || decoded[2].Text != "reply answer" {
+					t.Errorf("Expected the messages as stored, got %+v", decoded)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			adapter := &SlackAdapter{config: config.SlackConfig{MessageFormat: tt.format}}
+
+			content, err := adapter.messagesToFileContent(messages, "general")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, content)
+
+			filename, contentType := adapter.messageFile("general")
+			if filename != tt.filename || contentType != tt.contentType {
+				t.Errorf("Expected file %s (%s), got %s (%s)", tt.filename, tt.contentType, filename, contentType)
+			}
+		})
+	}
+}
+
+// assertInOrder fails unless every part occurs in content after the previous one
+func assertInOrder(t *testing.T, content string, parts ...string) {
+	t.Helper()
+	rest := content
+	for _, part := range parts {
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			t.Errorf("Expected %q in order, got:\n%s", part, content)
+			return
+		}
+		rest = rest[idx+len(part):]
+	}
+}
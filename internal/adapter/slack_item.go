@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// FetchItem fetches the messages of a single channel, e.g. one named by a Slack Events API
+// event. Only channels of the channel mappings and of the last fetch are synced, since the
+// knowledge base of a channel discovered by a regex pattern is only known after a fetch.
+func (s *SlackAdapter) FetchItem(ctx context.Context, channelID string) ([]*File, error) {
+	mapping, ok := s.channels[channelID]
+	if !ok {
+		mapping, ok = s.explicitChannel(channelID)
+	}
+	if !ok {
+		s.log().Debugf("Ignoring Slack channel %s, which isn't synced", channelID)
+		return nil, nil
+	}
+	if mapping.ChannelName == "" {
+		mapping.ChannelName = mapping.ChannelID
+	}
+
+	now := time.Now()
+	oldestTime, err := s.fetchStart(now)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.fetchChannel(ctx, mapping, oldestTime, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch channel %s: %w", mapping.ChannelName, err)
+	}
+
+	if err := s.saveUserCache(); err != nil {
+		s.log().Warnf("Failed to save Slack user cache: %v", err)
+	}
+	return files, nil
+}
+
+// explicitChannel returns the channel mapping of a channel, if it has one with a knowledge ID
+func (s *SlackAdapter) explicitChannel(channelID string) (config.ChannelMapping, bool) {
+	for _, mapping := range s.config.ChannelMappings {
+		if mapping.ChannelID == channelID && mapping.KnowledgeID != "" {
+			return mapping, true
+		}
+	}
+	return config.ChannelMapping{}, false
+}
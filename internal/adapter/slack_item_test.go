@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestSlackAdapter_FetchItem(t *testing.T) {
+	channel := []string{fmt.Sprintf("%d.000100", time.Now().Add(-time.Hour).Unix())}
+
+	tests := []struct {
+		name      string
+		mappings  []config.ChannelMapping
+		channelID string
+		wantFiles int
+	}{
+		{
+			name:      "mapped channel",
+			mappings:  []config.ChannelMapping{{ChannelID: "C1", ChannelName: "general", KnowledgeID: "knowledge-id"}},
+			channelID: "C1",
+			wantFiles: 1,
+		},
+		{
+			name:      "channel without a mapping",
+			mappings:  []config.ChannelMapping{{ChannelID: "C1", ChannelName: "general", KnowledgeID: "knowledge-id"}},
+			channelID: "C2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				requested = append(requested, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/conversations.info":
+					w.Write([]byte(`{"ok":true,"channel":{"id":"C1","is_channel":true,"is_member":true}}`))
+				case "/conversations.history":
+					w.Write(slackHistoryPage(t, channel, r.Form.Get("oldest"), r.Form.Get("latest"), r.Form.Get("cursor")))
+				default:
+					w.Write([]byte(`{"ok":false,"error":"unknown_method"}`))
+				}
+			}))
+			defer server.Close()
+
+			adapter := newTestSlackAdapter(t, server, storage.NewMemory())
+			adapter.config.MaintainHistory = true
+			adapter.config.DaysToFetch = 1
+			adapter.config.MessageLimit = 100
+			adapter.config.ChannelMappings = tt.mappings
+
+			files, err := adapter.FetchItem(context.Background(), tt.channelID)
+			if err != nil {
+				t.Fatalf("FetchItem() error = %v", err)
+			}
+			if len(files) != tt.wantFiles {
+				t.Fatalf("Expected %d files, got %d", tt.wantFiles, len(files))
+			}
+			if tt.wantFiles == 0 {
+				if len(requested) != 0 {
+					t.Errorf("Expected no requests, got %v", requested)
+				}
+				return
+			}
+			if files[0].KnowledgeID != "knowledge-id" {
+				t.Errorf("Expected knowledge-id, got %s", files[0].KnowledgeID)
+			}
+			if stored, _ := adapter.loadMessagesFromStorage("C1"); len(stored) != len(channel) {
+				t.Errorf("Expected %d stored messages, got %d", len(channel), len(stored))
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultMaxErrorLogBytes is the size at which join_errors.log is rotated
+const defaultMaxErrorLogBytes = 1 << 20
+
+// retentionDays returns how many days of a channel's stored messages to keep, or 0 to keep
+// them all. Without maintain_history the stored messages only back up the last days_to_fetch
+// days of the channel.
+func (s *SlackAdapter) retentionDays(channelID string) int {
+	if s.config.HistoryRetentionDays > 0 {
+		return s.config.HistoryRetentionDays
+	}
+	if !s.config.MaintainHistory {
+		if days := s.channelDays[channelID]; days > 0 {
+			return days
+		}
+		return s.config.DaysToFetch
+	}
+	return 0
+}
+
+// pruneStoredMessages drops stored messages older than the retention period from every
+// locally stored channel
+func (s *SlackAdapter) pruneStoredMessages(now time.Time) {
+	if s.config.MaintainHistory && s.config.HistoryRetentionDays <= 0 {
+		return
+	}
+
+	for _, channel := range s.listLocalChannels() {
+		days := s.retentionDays(channel.ChannelID)
+		if days <= 0 {
+			continue
+		}
+
+		pruned, err := s.pruneChannelMessages(channel.ChannelID, now.AddDate(0, 0, -days))
+		if err != nil {
+			s.log().Warnf("Failed to prune stored messages for channel %s: %v", channel.ChannelName, err)
+			continue
+		}
+		if pruned > 0 {
+			s.log().Infof("Pruned %d stored messages older than %d days from channel %s", pruned, days, channel.ChannelName)
+		}
+	}
+}
+
+// pruneChannelMessages rewrites a channel's messages.json without the messages sent before
+// cutoff and returns how many were dropped. Messages with an unparseable timestamp are kept.
+func (s *SlackAdapter) pruneChannelMessages(channelID string, cutoff time.Time) (int, error) {
+	messages, err := s.loadMessagesFromStorage(channelID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	kept := make([]SlackMessage, 0, len(messages))
+	for _, msg := range messages {
+		ts, err := strconv.ParseFloat(msg.Timestamp, 64)
+		if err == nil && time.Unix(int64(ts), 0).Before(cutoff) {
+			continue
+		}
+		kept = append(kept, msg)
+	}
+
+	pruned := len(messages) - len(kept)
+	if pruned == 0 {
+		return 0, nil
+	}
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	if err := s.store.Write(slackChannelKey(channelID, "messages.json"), data); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+// rotateLogFile moves key to key.1, replacing an older rotation, once it has grown to
+// maxBytes. A missing file is left alone.
+func rotateLogFile(store storage.Storage, key string, maxBytes int64) error {
+	data, err := store.Read(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if int64(len(data)) < maxBytes {
+		return nil
+	}
+
+	logrus.Debugf("Rotating %s at %d bytes", key, len(data))
+	if err := store.Write(key+".1", data); err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
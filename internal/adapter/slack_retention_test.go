@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestSlackAdapter_pruneStoredMessages(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	ts := func(daysAgo int) string {
+		return fmt.Sprintf("%d.000100", now.AddDate(0, 0, -daysAgo).Unix())
+	}
+	stored := []SlackMessage{
+		{Timestamp: "not-a-timestamp", Text: "unparseable", Channel: "general"},
+		{Timestamp: ts(40), Text: "ancient", Channel: "general"},
+		{Timestamp: ts(10), Text: "old", Channel: "general"},
+		{Timestamp: ts(1), Text: "recent", Channel: "general"},
+	}
+
+	tests := []struct {
+		name     string
+		config   config.SlackConfig
+		expected []string
+	}{
+		{
+			name:     "not maintaining history keeps days_to_fetch",
+			config:   config.SlackConfig{DaysToFetch: 30},
+			expected: []string{"unparseable", "old", "recent"},
+		},
+		{
+			name:     "explicit retention",
+			config:   config.SlackConfig{DaysToFetch: 30, HistoryRetentionDays: 7},
+			expected: []string{"unparseable", "recent"},
+		},
+		{
+			name:     "maintaining history keeps everything by default",
+			config:   config.SlackConfig{DaysToFetch: 30, MaintainHistory: true},
+			expected: []string{"unparseable", "ancient", "old", "recent"},
+		},
+		{
+			name:     "maintaining history with retention",
+			config:   config.SlackConfig{DaysToFetch: 30, MaintainHistory: true, HistoryRetentionDays: 20},
+			expected: []string{"unparseable", "old", "recent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemory()
+
+			// Seed storage through the normal save path
+			seeder := &SlackAdapter{config: config.SlackConfig{MaintainHistory: true}, store: store}
+			if err := seeder.saveMessagesToStorage("C123", "general", stored); err != nil {
+				t.Fatalf("Failed to seed messages: %v", err)
+			}
+
+			adapter := &SlackAdapter{config: tt.config, store: store}
+			adapter.pruneStoredMessages(now)
+
+			messages, err := adapter.loadMessagesFromStorage("C123")
+			if err != nil {
+				t.Fatalf("Failed to load messages: %v", err)
+			}
+			var texts []string
+			for _, msg := range messages {
+				texts = append(texts, msg.Text)
+			}
+			if strings.Join(texts, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected messages %v, got %v", tt.expected, texts)
+			}
+		})
+	}
+}
+
+func TestSlackAdapter_logJoinError_Rotates(t *testing.T) {
+	store := storage.NewMemory()
+	adapter := &SlackAdapter{config: config.SlackConfig{MaxErrorLogBytes: 200}, store: store}
+	logKey := "slack/join_errors.log"
+
+	for i := 0; i < 10; i++ {
+		adapter.logJoinError("general", fmt.Sprintf("C%03d", i), fmt.Errorf("not_in_channel"))
+	}
+
+	current, err := store.Read(logKey)
+	if err != nil {
+		t.Fatalf("Expected join error log to exist: %v", err)
+	}
+	// The cap is checked before each write, so the log exceeds it by at most one line
+	if len(current) >= 300 {
+		t.Errorf("Expected join error log to be rotated near 200 bytes, got %d bytes", len(current))
+	}
+
+	rotated, err := store.Read(logKey + ".1")
+	if err != nil {
+		t.Fatalf("Expected rotated log to exist: %v", err)
+	}
+	if !strings.Contains(string(rotated), "JOIN_ERROR") {
+		t.Errorf("Expected rotated log to contain earlier errors, got %q", rotated)
+	}
+
+	if !strings.Contains(string(current), "ID=C009") {
+		t.Errorf("Expected the latest error in the current log, got %q", current)
+	}
+}
@@ -0,0 +1,127 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/openwebui-content-sync/internal/utils"
+	"github.com/slack-go/slack"
+)
+
+// userCacheKey is the storage key of the persisted user ID -> display name cache
+const userCacheKey = "slack/users.json"
+
+// loadUserCache loads the persisted user cache from the storage
+func (s *SlackAdapter) loadUserCache() {
+	s.userNames = make(map[string]string)
+
+	data, err := s.store.Read(userCacheKey)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			s.log().Warnf("Failed to read Slack user cache: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &s.userNames); err != nil {
+		s.log().Warnf("Failed to parse Slack user cache: %v", err)
+		s.userNames = make(map[string]string)
+		return
+	}
+
+	s.log().Debugf("Loaded %d Slack users from cache", len(s.userNames))
+}
+
+// saveUserCache persists the user cache to the storage if it changed
+func (s *SlackAdapter) saveUserCache() error {
+	if !s.userCacheDirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.userNames, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal user cache: %w", err)
+	}
+
+	if err := s.store.Write(userCacheKey, data); err != nil {
+		return fmt.Errorf("failed to write user cache: %w", err)
+	}
+
+	s.userCacheDirty = false
+	s.log().Debugf("Saved %d Slack users to cache: %s", len(s.userNames), userCacheKey)
+	return nil
+}
+
+// resolveUserName returns the display name for a Slack user ID, fetching it once and caching the result.
+// Unknown or deleted users fall back to the raw ID.
+func (s *SlackAdapter) resolveUserName(ctx context.Context, userID string) string {
+	if userID == "" {
+		return ""
+	}
+
+	if s.userNames == nil {
+		s.loadUserCache()
+	}
+
+	if name, ok := s.userNames[userID]; ok {
+		return name
+	}
+
+	if s.client == nil {
+		return userID
+	}
+
+	var user *slack.User
+	err := utils.RetryWithBackoff(ctx, s.lookupRetryConfig(), func() error {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+		var err error
+		user, err = s.client.GetUserInfoContext(ctx, userID)
+		return err
+	})
+
+	name := userID
+	if err != nil {
+		if !isUnknownUserError(err) {
+			// Transient failure: don't cache so the next run tries again
+			s.log().Warnf("Failed to resolve Slack user %s: %v", userID, err)
+			return userID
+		}
+		s.log().Debugf("Slack user %s not found, using raw ID", userID)
+	} else if user != nil {
+		name = userDisplayName(user)
+	}
+
+	s.userNames[userID] = name
+	s.userCacheDirty = true
+	return name
+}
+
+// cachedUserName returns the cached display name for a user ID without calling the API
+func (s *SlackAdapter) cachedUserName(userID string) string {
+	if name, ok := s.userNames[userID]; ok && name != "" {
+		return name
+	}
+	return userID
+}
+
+// userDisplayName picks the most readable name for a Slack user
+func userDisplayName(user *slack.User) string {
+	for _, name := range []string{user.Profile.DisplayName, user.Profile.RealName, user.RealName, user.Name} {
+		if strings.TrimSpace(name) != "" {
+			return name
+		}
+	}
+	return user.ID
+}
+
+// isUnknownUserError checks if an error means the user does not exist (rather than a transient failure)
+func isUnknownUserError(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "user_not_found") || strings.Contains(errStr, "user_not_visible")
+}
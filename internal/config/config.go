@@ -8,150 +8,412 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// Version is the application version, set at build time with
+// -ldflags "-X github.com/openwebui-content-sync/internal/config.Version=<version>"
+var Version = "dev"
+
 // Config represents the application configuration
 type Config struct {
-	LogLevel     string            `yaml:"log_level"`
-	Schedule     ScheduleConfig    `yaml:"schedule"`
-	Storage      StorageConfig     `yaml:"storage"`
-	OpenWebUI    OpenWebUIConfig   `yaml:"openwebui"`
-	GitHub       GitHubConfig      `yaml:"github"`
-	Confluence   ConfluenceConfig  `yaml:"confluence"`
-	Jira         JiraConfig        `yaml:"jira"`
-	LocalFolders LocalFolderConfig `yaml:"local_folders"`
-	Slack        SlackConfig       `yaml:"slack"`
+	LogLevel      string            `yaml:"log_level"`
+	HealthEnabled bool              `yaml:"health_enabled"` // Serve /health, /metrics and /sync
+	HealthPort    int               `yaml:"health_port"`
+	Schedule      ScheduleConfig    `yaml:"schedule"`
+	Storage       StorageConfig     `yaml:"storage"`
+	Sync          SyncConfig        `yaml:"sync"`
+	HTTP          HTTPConfig        `yaml:"http"`
+	Retry         RetryConfig       `yaml:"retry"` // Retries of OpenWebUI, Confluence, Jira and Slack requests, overridden by each section's retry
+	OpenWebUI     OpenWebUIConfig   `yaml:"openwebui"`
+	GitHub        GitHubConfig      `yaml:"github"`
+	Confluence    ConfluenceConfig  `yaml:"confluence"`
+	Jira          JiraConfig        `yaml:"jira"`
+	LocalFolders  LocalFolderConfig `yaml:"local_folders"`
+	Slack         SlackConfig       `yaml:"slack"`
+	Webhooks      WebhookConfig     `yaml:"webhooks"`
 }
 
 // ScheduleConfig defines the sync schedule
 type ScheduleConfig struct {
 	Interval time.Duration `yaml:"interval"`
+	Cron     string        `yaml:"cron"` // Optional standard cron expression, takes precedence over Interval
+}
+
+// IsSet reports whether an interval or cron expression is configured
+func (s ScheduleConfig) IsSet() bool {
+	return s.Interval > 0 || s.Cron != ""
+}
+
+// WebhookConfig defines the webhook receiver, which syncs a Slack channel, Confluence page or
+// Jira issue as soon as its source reports a change. An endpoint is only served once its
+// secret is set.
+type WebhookConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	Port               int    `yaml:"port"`                 // Own port of the receiver (0 = serve /webhooks/ on the health server)
+	SlackSigningSecret string `yaml:"slack_signing_secret"` // Signing secret of the Slack app, serves /webhooks/slack
+	ConfluenceSecret   string `yaml:"confluence_secret"`    // Secret of the Confluence webhook, serves /webhooks/confluence
+	JiraSecret         string `yaml:"jira_secret"`          // Secret of the Jira webhook, serves /webhooks/jira
 }
 
 // StorageConfig defines local storage settings
 type StorageConfig struct {
-	Path string `yaml:"path"`
+	Path    string          `yaml:"path"`
+	Backend string          `yaml:"backend"` // Where the sync state is kept: local (default, below path) or s3
+	S3      S3StorageConfig `yaml:"s3"`      // Bucket of the s3 backend
+
+	SnapshotHistory     bool   `yaml:"snapshot_history"`     // Keep the raw source content of every synced version of a file
+	SnapshotCompression string `yaml:"snapshot_compression"` // gzip (default) or none
+	SnapshotRetention   int    `yaml:"snapshot_retention"`   // Number of versions kept per file
+}
+
+// S3StorageConfig defines the bucket of the s3 storage backend
+type S3StorageConfig struct {
+	Endpoint        string `yaml:"endpoint"`          // S3-compatible endpoint, e.g. https://minio.example.com (empty = AWS S3 in region)
+	Region          string `yaml:"region"`            // Signing region (default us-east-1)
+	Bucket          string `yaml:"bucket"`            // Bucket holding the state
+	Prefix          string `yaml:"prefix"`            // Optional key prefix, so several deployments can share a bucket
+	AccessKeyID     string `yaml:"access_key_id"`     // Set via S3_ACCESS_KEY_ID environment variable
+	SecretAccessKey string `yaml:"secret_access_key"` // Set via S3_SECRET_ACCESS_KEY environment variable
+	PathStyle       bool   `yaml:"path_style"`        // Address the bucket as endpoint/bucket instead of bucket.endpoint, as MinIO expects
+}
+
+// SyncConfig defines sync manager settings
+type SyncConfig struct {
+	Concurrency       int           `yaml:"concurrency"`         // Number of files uploaded to OpenWebUI in parallel
+	ContentTemplate   string        `yaml:"content_template"`    // Optional text/template file applied to the content of every text file before upload
+	KnowledgeAddDelay time.Duration `yaml:"knowledge_add_delay"` // Minimum time between two files being added to knowledge bases (0 = no throttle)
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`    // How long a shutdown waits for the running sync to stop (0 = don't wait)
+	Timeout           time.Duration `yaml:"timeout"`             // Maximum duration of a sync run (0 = no limit)
+	AdapterTimeout    time.Duration `yaml:"adapter_timeout"`     // Maximum duration of a single adapter's fetch and uploads within a run (0 = no limit)
+	DedupContent      bool          `yaml:"dedup_content"`       // Link files whose content was already uploaded by any source instead of uploading it again
+	LogKnowledgeNames bool          `yaml:"log_knowledge_names"` // Refer to knowledge bases as 'Name' (ID) in logs instead of by ID only (default: true)
+	ConflictStrategy  string        `yaml:"conflict_strategy"`   // overwrite (default) or skip files that were edited in OpenWebUI since they were last synced
+}
+
+// HTTPConfig defines settings shared by the HTTP clients of OpenWebUI, Confluence and Jira
+type HTTPConfig struct {
+	UserAgent string            `yaml:"user_agent"` // Default: OpenWebUI-Content-Sync/<version>
+	Headers   map[string]string `yaml:"headers"`    // Extra headers sent with every request, e.g. for a WAF or proxy
 }
 
 // OpenWebUIConfig defines OpenWebUI API settings
 type OpenWebUIConfig struct {
-	BaseURL string `yaml:"base_url"`
-	APIKey  string `yaml:"api_key"`
+	BaseURL string      `yaml:"base_url"`
+	APIKey  string      `yaml:"api_key"`
+	Retry   RetryConfig `yaml:"retry"`
+
+	ProcessingTimeout      time.Duration `yaml:"processing_timeout"`       // How long to wait for an uploaded file to be processed
+	ProcessingPollInterval time.Duration `yaml:"processing_poll_interval"` // First interval between status checks, grows up to 10x towards the timeout
+
+	RequestTimeout     time.Duration `yaml:"request_timeout"`      // Timeout of a single HTTP request to OpenWebUI
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify"` // Skip TLS certificate verification (self-signed deployments only)
+	CACertPath         string        `yaml:"ca_cert_path"`         // PEM file with extra CA certificates to trust, e.g. a corporate CA
+
+	CreateMissingKnowledge bool `yaml:"create_missing_knowledge"` // Create knowledge bases named by knowledge_name mappings that don't exist yet
+
+	Targets []OpenWebUITarget `yaml:"targets"` // Optional: additional OpenWebUI instances, e.g. staging, that receive the same files
+}
+
+// OpenWebUITarget defines an additional OpenWebUI instance every sync uploads to. It shares the
+// retry, timeout and TLS settings of the main instance.
+type OpenWebUITarget struct {
+	Name         string            `yaml:"name"` // Unique name, used in logs and to keep the target's file index apart
+	BaseURL      string            `yaml:"base_url"`
+	APIKey       string            `yaml:"api_key"`
+	KnowledgeIDs map[string]string `yaml:"knowledge_ids"` // Knowledge ID of the main instance -> knowledge ID on this target; unmapped IDs are used as they are
+}
+
+// RetryConfig defines how failed requests (network errors, 429 and 5xx) are retried
+type RetryConfig struct {
+	MaxRetries int           `yaml:"max_retries"`
+	BaseDelay  time.Duration `yaml:"base_delay"` // Delay before the first retry
+	MaxDelay   time.Duration `yaml:"max_delay"`
+	Multiplier float64       `yaml:"multiplier"` // Factor the delay grows by with each further retry
+}
+
+// slackRetryDefaults apply to the Slack retry settings that neither slack.retry nor the
+// top-level retry set, as Slack rate limits call for more patience than the other sources
+var slackRetryDefaults = RetryConfig{
+	MaxRetries: 5,
+	BaseDelay:  time.Second,
+	MaxDelay:   5 * time.Minute,
+	Multiplier: 2,
+}
+
+// retryKeys are the settings a retry section of the config file sets
+type retryKeys map[string]any
+
+// merge returns r with the settings in keys taken from override, including explicit zeros
+// such as max_retries: 0
+func (r RetryConfig) merge(override RetryConfig, keys retryKeys) RetryConfig {
+	set := func(key string) bool {
+		_, ok := keys[key]
+		return ok
+	}
+	if set("max_retries") {
+		r.MaxRetries = override.MaxRetries
+	}
+	if set("base_delay") {
+		r.BaseDelay = override.BaseDelay
+	}
+	if set("max_delay") {
+		r.MaxDelay = override.MaxDelay
+	}
+	if set("multiplier") {
+		r.Multiplier = override.Multiplier
+	}
+	return r
+}
+
+// retrySections are the retry sections of the config file, decoded separately from Config
+// to tell settings that are set to 0 from missing ones
+type retrySections struct {
+	Retry     retryKeys `yaml:"retry"`
+	OpenWebUI struct {
+		Retry retryKeys `yaml:"retry"`
+	} `yaml:"openwebui"`
+	Confluence struct {
+		Retry retryKeys `yaml:"retry"`
+	} `yaml:"confluence"`
+	Jira struct {
+		Retry retryKeys `yaml:"retry"`
+	} `yaml:"jira"`
+	Slack struct {
+		Retry retryKeys `yaml:"retry"`
+	} `yaml:"slack"`
 }
 
 // RepositoryMapping defines a mapping between a GitHub repository and a knowledge base
 type RepositoryMapping struct {
-	Repository  string `yaml:"repository"` // Format: "owner/repo"
-	KnowledgeID string `yaml:"knowledge_id"`
+	Repository          string   `yaml:"repository"` // Format: "owner/repo"
+	KnowledgeID         string   `yaml:"knowledge_id"`
+	KnowledgeName       string   `yaml:"knowledge_name"`        // Optional: knowledge base name, resolved to knowledge_id at startup
+	Branch              string   `yaml:"branch"`                // Optional: branch to sync (default branch if empty)
+	Paths               []string `yaml:"paths"`                 // Optional: subpaths to sync, e.g. "docs" (whole repository if empty)
+	ExcludeDirs         []string `yaml:"exclude_dirs"`          // Optional: directories never fetched, by name (e.g. "node_modules") or path from the root (e.g. "docs/internal")
+	IncludeReleases     bool     `yaml:"include_releases"`      // Optional: also sync release notes as markdown files
+	ReleaseAssets       bool     `yaml:"release_assets"`        // Optional: also download text assets of each release
+	IncludeIssues       bool     `yaml:"include_issues"`        // Optional: also sync issues with their comments as markdown files
+	IncludePullRequests bool     `yaml:"include_pull_requests"` // Optional: also sync pull requests with their comments as markdown files
+	IssueState          string   `yaml:"issue_state"`           // Optional: issues/pull requests to sync: open, closed or all (default all)
 }
 
 // SpaceMapping defines a mapping between a Confluence space and a knowledge base
 type SpaceMapping struct {
-	SpaceKey    string `yaml:"space_key"`
-	KnowledgeID string `yaml:"knowledge_id"`
+	SpaceKey      string `yaml:"space_key"`
+	KnowledgeID   string `yaml:"knowledge_id"`
+	KnowledgeName string `yaml:"knowledge_name"` // Optional: knowledge base name, resolved to knowledge_id at startup
 }
 
 // ParentPageMapping defines a mapping between a Confluence parent page and a knowledge base
 type ParentPageMapping struct {
-	ParentPageID string `yaml:"parent_page_id"`
-	KnowledgeID  string `yaml:"knowledge_id"`
+	ParentPageID  string `yaml:"parent_page_id"`
+	KnowledgeID   string `yaml:"knowledge_id"`
+	KnowledgeName string `yaml:"knowledge_name"` // Optional: knowledge base name, resolved to knowledge_id at startup
+}
+
+// CQLMapping defines a mapping between the pages matching a Confluence CQL query and a knowledge base
+type CQLMapping struct {
+	CQL           string `yaml:"cql"` // e.g. label = "kb" and lastModified > now("-30d")
+	KnowledgeID   string `yaml:"knowledge_id"`
+	KnowledgeName string `yaml:"knowledge_name"` // Optional: knowledge base name, resolved to knowledge_id at startup
 }
 
 // LocalFolderMapping defines a mapping between a local folder and a knowledge base
 type LocalFolderMapping struct {
-	FolderPath  string `yaml:"folder_path"`
-	KnowledgeID string `yaml:"knowledge_id"`
+	FolderPath    string `yaml:"folder_path"`
+	KnowledgeID   string `yaml:"knowledge_id"`
+	KnowledgeName string `yaml:"knowledge_name"` // Optional: knowledge base name, resolved to knowledge_id at startup
 }
 
 // GitHubConfig defines GitHub adapter settings
 type GitHubConfig struct {
-	Enabled  bool                `yaml:"enabled"`
-	Token    string              `yaml:"token"`
-	Mappings []RepositoryMapping `yaml:"mappings"` // Per-repository knowledge mappings
+	Enabled             bool                `yaml:"enabled"`
+	Token               string              `yaml:"token"`
+	Tokens              []string            `yaml:"tokens"`               // Additional tokens used round-robin to spread the rate limit
+	BaseURL             string              `yaml:"base_url"`             // GitHub Enterprise API URL, e.g. https://github.example.com/api/v3 (empty = github.com)
+	UploadURL           string              `yaml:"upload_url"`           // GitHub Enterprise upload URL (derived from base_url if empty)
+	Mappings            []RepositoryMapping `yaml:"mappings"`             // Per-repository knowledge mappings
+	MaxFileSizeBytes    int64               `yaml:"max_file_size_bytes"`  // Skip files larger than this (0 = no limit)
+	UseTreeAPI          bool                `yaml:"use_tree_api"`         // List repositories with one Git Trees API call instead of one call per directory
+	FollowSubmodules    bool                `yaml:"follow_submodules"`    // Sync the files of submodules at their pinned commit instead of skipping them
+	DownloadConcurrency int                 `yaml:"download_concurrency"` // Number of file contents downloaded in parallel (0 = 4)
+	IncrementalSync     bool                `yaml:"incremental_sync"`     // Only download the files changed since the commit of the previous fetch, and the comments of updated issues
+	Schedule            ScheduleConfig      `yaml:",inline"`              // Optional interval/cron overriding the global schedule
 }
 
 // ConfluenceConfig defines Confluence adapter settings
 type ConfluenceConfig struct {
-	Enabled            bool                `yaml:"enabled"`
-	BaseURL            string              `yaml:"base_url"`
-	Username           string              `yaml:"username"`
-	APIKey             string              `yaml:"api_key"`
-	SpaceMappings      []SpaceMapping      `yaml:"space_mappings"`       // Per-space knowledge mappings
-	ParentPageMappings []ParentPageMapping `yaml:"parent_page_mappings"` // Per-parent-page knowledge mappings
-	PageLimit          int                 `yaml:"page_limit"`
-	IncludeAttachments bool                `yaml:"include_attachments"`
-	UseMarkdownParser  bool                `yaml:"use_markdown_parser"`
-	IncludeBlogPosts   bool                `yaml:"include_blog_posts"`
-	AddAdditionalData  bool                `yaml:"add_additional_data"`
+	Enabled                  bool                `yaml:"enabled"`
+	BaseURL                  string              `yaml:"base_url"`
+	Username                 string              `yaml:"username"`
+	APIKey                   string              `yaml:"api_key"`
+	AuthType                 string              `yaml:"auth_type"`            // basic (default, username and api_key) or bearer (api_key is a personal access token)
+	SpaceMappings            []SpaceMapping      `yaml:"space_mappings"`       // Per-space knowledge mappings
+	ParentPageMappings       []ParentPageMapping `yaml:"parent_page_mappings"` // Per-parent-page knowledge mappings
+	CQLMappings              []CQLMapping        `yaml:"cql_mappings"`         // Per-CQL-query knowledge mappings
+	PageLimit                int                 `yaml:"page_limit"`
+	IncludeAttachments       bool                `yaml:"include_attachments"`
+	IncludeBinaryAttachments bool                `yaml:"include_binary_attachments"` // Also sync attachments with non-text media types
+	UseMarkdownParser        bool                `yaml:"use_markdown_parser"`
+	IncludeBlogPosts         bool                `yaml:"include_blog_posts"`
+	AddAdditionalData        bool                `yaml:"add_additional_data"`
+	ForceFullSync            bool                `yaml:"force_full_sync"`     // Re-fetch every page even if its version is unchanged
+	IncludeLabels            []string            `yaml:"include_labels"`      // Only sync pages with at least one of these labels (empty = all pages)
+	ExcludeLabels            []string            `yaml:"exclude_labels"`      // Never sync pages with any of these labels, even if included
+	AncestorFilenames        bool                `yaml:"ancestor_filenames"`  // Prefix page filenames with their ancestors' titles
+	Breadcrumbs              bool                `yaml:"breadcrumbs"`         // Add the ancestors' titles to each page's header
+	IncludeSourceLink        bool                `yaml:"include_source_link"` // Replace the LinkToPage header with a markdown link to the page in Confluence
+	MaxFilenameLength        int                 `yaml:"max_filename_length"` // Truncate sanitized titles to this many characters (0 = 100)
+	Deployment               string              `yaml:"deployment"`          // cloud (default, /wiki/api/v2) or server for Confluence Server and Data Center (REST API v1)
+	Retry                    RetryConfig         `yaml:"retry"`               // Optional retry settings overriding the top-level retry
+	Schedule                 ScheduleConfig      `yaml:",inline"`             // Optional interval/cron overriding the global schedule
 }
 
 // LocalFolderConfig defines local folder adapter settings
 type LocalFolderConfig struct {
-	Enabled  bool                 `yaml:"enabled"`
-	Mappings []LocalFolderMapping `yaml:"mappings"` // Per-folder knowledge mappings
+	Enabled           bool                 `yaml:"enabled"`
+	Mappings          []LocalFolderMapping `yaml:"mappings"`           // Per-folder knowledge mappings
+	Watch             bool                 `yaml:"watch"`              // Sync changed files as soon as they are written
+	UseGitignore      bool                 `yaml:"use_gitignore"`      // Also honor .gitignore files (.owuisyncignore is always honored)
+	StripFrontMatter  bool                 `yaml:"strip_front_matter"` // Remove YAML front matter from Markdown files before upload
+	IncludeExtensions []string             `yaml:"include_extensions"` // Only sync files with these extensions, e.g. [md, txt] (empty = every text file)
+	ExcludeExtensions []string             `yaml:"exclude_extensions"` // Never sync files with these extensions, e.g. [lock, map, min.js]
+	Schedule          ScheduleConfig       `yaml:",inline"`            // Optional interval/cron overriding the global schedule
 }
 
 // SlackConfig defines Slack adapter settings
 type SlackConfig struct {
-	Enabled          bool             `yaml:"enabled"`
-	Token            string           `yaml:"token"`
-	ChannelMappings  []ChannelMapping `yaml:"channel_mappings"`  // Per-channel knowledge mappings
-	RegexPatterns    []RegexPattern   `yaml:"regex_patterns"`    // Regex patterns for auto-discovering channels
-	DaysToFetch      int              `yaml:"days_to_fetch"`     // Number of days to fetch messages
-	MaintainHistory  bool             `yaml:"maintain_history"`  // Whether to maintain indefinite history or age off
-	MessageLimit     int              `yaml:"message_limit"`     // Max messages per channel per run
-	IncludeThreads   bool             `yaml:"include_threads"`   // Whether to include thread messages
-	IncludeReactions bool             `yaml:"include_reactions"` // Whether to include reaction data
+	Enabled              bool             `yaml:"enabled"`
+	Token                string           `yaml:"token"`
+	ChannelMappings      []ChannelMapping `yaml:"channel_mappings"`       // Per-channel knowledge mappings
+	RegexPatterns        []RegexPattern   `yaml:"regex_patterns"`         // Regex patterns for auto-discovering channels
+	DaysToFetch          int              `yaml:"days_to_fetch"`          // Number of days to fetch messages
+	MaintainHistory      bool             `yaml:"maintain_history"`       // Whether to maintain indefinite history or age off
+	MessageLimit         int              `yaml:"message_limit"`          // Max messages per channel per run
+	IncludeThreads       bool             `yaml:"include_threads"`        // Whether to include thread messages
+	IncludeReactions     bool             `yaml:"include_reactions"`      // Whether to include reaction data
+	MessageFormat        string           `yaml:"message_format"`         // Rendering of channel files: threaded (default), nested, flat or json
+	ConversationTypes    []string         `yaml:"conversation_types"`     // Conversation types to discover: public_channel, private_channel, im, mpim
+	DownloadFiles        bool             `yaml:"download_files"`         // Download file attachments and sync them as additional files
+	DownloadBinaryFiles  bool             `yaml:"download_binary_files"`  // Also sync attachments with non-text media types
+	RequestsPerMinute    int              `yaml:"requests_per_minute"`    // Max Slack API calls per minute across the adapter (default 50)
+	HistoryRetentionDays int              `yaml:"history_retention_days"` // Prune stored messages older than this (default days_to_fetch without maintain_history, 0 keeps all)
+	MaxErrorLogBytes     int64            `yaml:"max_error_log_bytes"`    // Rotate join_errors.log once it reaches this size (default 1 MiB)
+	Retry                RetryConfig      `yaml:"retry"`                  // Optional retry settings overriding the top-level retry (default 5 retries, up to 5m apart)
+	Schedule             ScheduleConfig   `yaml:",inline"`                // Optional interval/cron overriding the global schedule
 }
 
 // ChannelMapping defines mapping between Slack channels and knowledge bases
 type ChannelMapping struct {
-	ChannelID   string `yaml:"channel_id"`   // Slack channel ID
-	ChannelName string `yaml:"channel_name"` // Slack channel name (for display)
-	KnowledgeID string `yaml:"knowledge_id"` // Target knowledge base ID
+	ChannelID     string `yaml:"channel_id"`     // Slack channel ID
+	ChannelName   string `yaml:"channel_name"`   // Slack channel name (for display)
+	KnowledgeID   string `yaml:"knowledge_id"`   // Target knowledge base ID
+	KnowledgeName string `yaml:"knowledge_name"` // Target knowledge base name, resolved to knowledge_id at startup
+	DaysToFetch   int    `yaml:"days_to_fetch"`  // Days to fetch for this channel, overriding the global value (0 = global)
 }
 
 // RegexPattern defines regex patterns for auto-discovering Slack channels
 type RegexPattern struct {
-	Pattern     string `yaml:"pattern"`      // Regex pattern to match channel names
-	KnowledgeID string `yaml:"knowledge_id"` // Target knowledge base ID for matching channels
-	AutoJoin    bool   `yaml:"auto_join"`    // Whether to automatically join matching channels
+	Pattern       string `yaml:"pattern"`        // Regex pattern to match channel names
+	KnowledgeID   string `yaml:"knowledge_id"`   // Target knowledge base ID for matching channels
+	KnowledgeName string `yaml:"knowledge_name"` // Target knowledge base name, resolved to knowledge_id at startup
+	AutoJoin      bool   `yaml:"auto_join"`      // Whether to automatically join matching channels
+	DaysToFetch   int    `yaml:"days_to_fetch"`  // Days to fetch for matching channels, overriding the global value (0 = global)
 }
 
 // JiraProjectMapping defines a mapping between a Jira project and a knowledge base
 type JiraProjectMapping struct {
-	ProjectKey  string `yaml:"project_key"`
-	KnowledgeID string `yaml:"knowledge_id"`
+	ProjectKey    string `yaml:"project_key"`
+	KnowledgeID   string `yaml:"knowledge_id"`
+	KnowledgeName string `yaml:"knowledge_name"` // Optional: knowledge base name, resolved to knowledge_id at startup
+	JQL           string `yaml:"jql"`            // Optional custom JQL query used instead of "project = 'KEY'"
 }
 
 // JiraConfig defines Jira adapter settings
 type JiraConfig struct {
-	Enabled         bool                 `yaml:"enabled"`
-	BaseURL         string               `yaml:"base_url"`
-	Username        string               `yaml:"username"`
-	APIKey          string               `yaml:"api_key"`
-	ProjectMappings []JiraProjectMapping `yaml:"project_mappings"` // Per-project knowledge mappings
-	PageLimit       int                  `yaml:"page_limit"`
+	Enabled             bool                 `yaml:"enabled"`
+	BaseURL             string               `yaml:"base_url"`
+	Username            string               `yaml:"username"`
+	APIKey              string               `yaml:"api_key"`
+	AuthType            string               `yaml:"auth_type"`        // basic (default, username and api_key) or bearer (api_key is a personal access token)
+	Deployment          string               `yaml:"deployment"`       // cloud (default, REST API v3) or server for Jira Server and Data Center (REST API v2)
+	ProjectMappings     []JiraProjectMapping `yaml:"project_mappings"` // Per-project knowledge mappings
+	PageLimit           int                  `yaml:"page_limit"`
+	IncrementalSync     bool                 `yaml:"incremental_sync"`      // Only fetch issues updated since the last sync
+	CommentLimit        int                  `yaml:"comment_limit"`         // Keep only the most recent N comments per issue (0 = all)
+	OnlyCommentsSince   time.Duration        `yaml:"only_comments_since"`   // Drop comments older than this (0 = no cutoff)
+	UseRenderedComments bool                 `yaml:"use_rendered_comments"` // Fetch each comment's rendered HTML instead of converting its ADF body
+	Retry               RetryConfig          `yaml:"retry"`                 // Optional retry settings overriding the top-level retry
+	Schedule            ScheduleConfig       `yaml:",inline"`               // Optional interval/cron overriding the global schedule
+
+	IncludeAttachments       bool  `yaml:"include_attachments"`        // Also sync issue attachments as separate files
+	IncludeBinaryAttachments bool  `yaml:"include_binary_attachments"` // Also sync attachments with non-text media types
+	MaxAttachmentSizeBytes   int64 `yaml:"max_attachment_size_bytes"`  // Skip attachments larger than this (0 = no limit)
+
+	IncludeChangelog bool `yaml:"include_changelog"` // Render each issue's field change history
+	ChangelogLimit   int  `yaml:"changelog_limit"`   // Keep only the most recent N history entries per issue (0 = all)
 }
 
-// Load loads configuration from file and environment variables
+// Load loads configuration from file and environment variables. Variables from the .env file
+// named by ENV_FILE (default .env) are loaded first, without overriding the real environment,
+// followed by secrets read from the files named by *_FILE variables such as GITHUB_TOKEN_FILE.
 func Load(path string) (*Config, error) {
 	fmt.Printf("Loading configuration from: %s\n", path)
 
+	// Load the .env file first, so its variables feed both the defaults and the overrides below
+	if err := loadEnvFile(getEnv("ENV_FILE", defaultEnvFile)); err != nil {
+		return nil, err
+	}
+	if err := loadSecretFiles(); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		LogLevel: "info",
+		LogLevel:      "info",
+		HealthEnabled: true,
+		HealthPort:    8080,
 		Schedule: ScheduleConfig{
 			Interval: 1 * time.Hour,
 		},
 		Storage: StorageConfig{
-			Path: "/data",
+			Path:                "/data",
+			Backend:             "local",
+			SnapshotCompression: "gzip",
+			SnapshotRetention:   10,
+			S3: S3StorageConfig{
+				Region:          "us-east-1",
+				AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			},
+		},
+		Sync: SyncConfig{
+			Concurrency:       1,
+			ShutdownTimeout:   30 * time.Second,
+			Timeout:           30 * time.Minute,
+			LogKnowledgeNames: true,
+			ConflictStrategy:  "overwrite",
+		},
+		HTTP: HTTPConfig{
+			UserAgent: "OpenWebUI-Content-Sync/" + Version,
+		},
+		Retry: RetryConfig{
+			MaxRetries: 3,
+			BaseDelay:  time.Second,
+			MaxDelay:   time.Minute,
+			Multiplier: 2,
 		},
 		OpenWebUI: OpenWebUIConfig{
-			BaseURL: getEnv("OPENWEBUI_BASE_URL", "http://localhost:8080"),
-			APIKey:  getEnv("OPENWEBUI_API_KEY", ""),
+			BaseURL:                getEnv("OPENWEBUI_BASE_URL", "http://localhost:8080"),
+			APIKey:                 getEnv("OPENWEBUI_API_KEY", ""),
+			ProcessingTimeout:      680 * time.Second,
+			ProcessingPollInterval: 2 * time.Second,
+			RequestTimeout:         5 * time.Minute,
 		},
 		GitHub: GitHubConfig{
-			Enabled:  false,
-			Token:    getEnv("GITHUB_TOKEN", ""),
-			Mappings: []RepositoryMapping{},
+			Enabled:             false,
+			Token:               getEnv("GITHUB_TOKEN", ""),
+			Mappings:            []RepositoryMapping{},
+			UseTreeAPI:          true,
+			DownloadConcurrency: 4,
 		},
 		Confluence: ConfluenceConfig{
 			Enabled:            false,
@@ -164,13 +426,15 @@ func Load(path string) (*Config, error) {
 			IncludeAttachments: true,
 			UseMarkdownParser:  false,
 			IncludeBlogPosts:   false,
+			MaxFilenameLength:  100,
 		},
 		Jira: JiraConfig{
-			Enabled:         false,
-			BaseURL:         "",
-			Username:        "",
-			APIKey:          getEnv("JIRA_API_KEY", ""),
-			ProjectMappings: []JiraProjectMapping{},
+			Enabled:                false,
+			BaseURL:                "",
+			Username:               "",
+			APIKey:                 getEnv("JIRA_API_KEY", ""),
+			ProjectMappings:        []JiraProjectMapping{},
+			MaxAttachmentSizeBytes: 10 << 20, // 10 MiB
 		},
 		LocalFolders: LocalFolderConfig{
 			Enabled:  false,
@@ -185,6 +449,12 @@ func Load(path string) (*Config, error) {
 			MessageLimit:     1000,
 			IncludeThreads:   true,
 			IncludeReactions: false,
+			MessageFormat:    "threaded",
+		},
+		Webhooks: WebhookConfig{
+			SlackSigningSecret: getEnv("SLACK_SIGNING_SECRET", ""),
+			ConfluenceSecret:   getEnv("CONFLUENCE_WEBHOOK_SECRET", ""),
+			JiraSecret:         getEnv("JIRA_WEBHOOK_SECRET", ""),
 		},
 	}
 
@@ -197,6 +467,7 @@ func Load(path string) (*Config, error) {
 	}())
 
 	// Load from file if it exists
+	var retries retrySections
 	if _, err := os.Stat(path); err == nil {
 		fmt.Printf("Config file exists, loading from: %s\n", path)
 		data, err := os.ReadFile(path)
@@ -209,6 +480,9 @@ func Load(path string) (*Config, error) {
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
+		if err := yaml.Unmarshal(data, &retries); err != nil {
+			return nil, fmt.Errorf("failed to parse retry settings: %w", err)
+		}
 
 		fmt.Printf("After loading config file - OpenWebUI BaseURL: %s\n", cfg.OpenWebUI.BaseURL)
 	} else {
@@ -220,8 +494,22 @@ func Load(path string) (*Config, error) {
 	cfg.OpenWebUI.APIKey = getEnv("OPENWEBUI_API_KEY", cfg.OpenWebUI.APIKey)
 	cfg.GitHub.Token = getEnv("GITHUB_TOKEN", cfg.GitHub.Token)
 	cfg.Confluence.APIKey = getEnv("CONFLUENCE_API_KEY", cfg.Confluence.APIKey)
-	cfg.Jira.APIKey = getEnv("CONFLUENCE_API_KEY", cfg.Jira.APIKey)
+	// Jira Cloud accepts the Atlassian API key of Confluence, unless a Jira key is set
+	cfg.Jira.APIKey = getEnv("JIRA_API_KEY", getEnv("CONFLUENCE_API_KEY", cfg.Jira.APIKey))
+	cfg.Slack.Token = getEnv("SLACK_TOKEN", cfg.Slack.Token)
+	cfg.Webhooks.SlackSigningSecret = getEnv("SLACK_SIGNING_SECRET", cfg.Webhooks.SlackSigningSecret)
+	cfg.Webhooks.ConfluenceSecret = getEnv("CONFLUENCE_WEBHOOK_SECRET", cfg.Webhooks.ConfluenceSecret)
+	cfg.Webhooks.JiraSecret = getEnv("JIRA_WEBHOOK_SECRET", cfg.Webhooks.JiraSecret)
 	cfg.Storage.Path = getEnv("STORAGE_PATH", cfg.Storage.Path)
+	cfg.Storage.S3.AccessKeyID = getEnv("S3_ACCESS_KEY_ID", cfg.Storage.S3.AccessKeyID)
+	cfg.Storage.S3.SecretAccessKey = getEnv("S3_SECRET_ACCESS_KEY", cfg.Storage.S3.SecretAccessKey)
+
+	// Settings missing from a section's retry are taken from the top-level retry, and for
+	// Slack from its own defaults when the top-level retry doesn't set them either
+	cfg.OpenWebUI.Retry = cfg.Retry.merge(cfg.OpenWebUI.Retry, retries.OpenWebUI.Retry)
+	cfg.Confluence.Retry = cfg.Retry.merge(cfg.Confluence.Retry, retries.Confluence.Retry)
+	cfg.Jira.Retry = cfg.Retry.merge(cfg.Jira.Retry, retries.Jira.Retry)
+	cfg.Slack.Retry = slackRetryDefaults.merge(cfg.Retry, retries.Retry).merge(cfg.Slack.Retry, retries.Slack.Retry)
 
 	fmt.Printf("Final OpenWebUI BaseURL: %s\n", cfg.OpenWebUI.BaseURL)
 	fmt.Printf("Environment OPENWEBUI_BASE_URL: %s\n", os.Getenv("OPENWEBUI_BASE_URL"))
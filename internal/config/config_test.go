@@ -30,6 +30,39 @@ func TestLoad_DefaultConfig(t *testing.T) {
 	if cfg.GitHub.Enabled != false {
 		t.Errorf("Expected GitHub enabled false, got %v", cfg.GitHub.Enabled)
 	}
+	if cfg.Confluence.IncludeSourceLink {
+		t.Errorf("Expected Confluence source links to be disabled by default")
+	}
+	if cfg.Confluence.MaxFilenameLength != 100 {
+		t.Errorf("Expected Confluence max filename length 100, got %d", cfg.Confluence.MaxFilenameLength)
+	}
+	if !cfg.GitHub.UseTreeAPI {
+		t.Errorf("Expected GitHub tree API to be enabled by default")
+	}
+	if cfg.GitHub.DownloadConcurrency != 4 {
+		t.Errorf("Expected GitHub download concurrency 4, got %d", cfg.GitHub.DownloadConcurrency)
+	}
+	if cfg.Sync.Timeout != 30*time.Minute {
+		t.Errorf("Expected sync timeout 30m, got %v", cfg.Sync.Timeout)
+	}
+	if cfg.Sync.ConflictStrategy != "overwrite" {
+		t.Errorf("Expected conflict strategy overwrite, got %s", cfg.Sync.ConflictStrategy)
+	}
+	if !cfg.Sync.LogKnowledgeNames {
+		t.Errorf("Expected knowledge names in logs by default")
+	}
+	if cfg.Sync.Concurrency != 1 {
+		t.Errorf("Expected sync concurrency 1, got %d", cfg.Sync.Concurrency)
+	}
+	if !cfg.HealthEnabled || cfg.HealthPort != 8080 {
+		t.Errorf("Expected health server enabled on port 8080, got enabled=%v port=%d", cfg.HealthEnabled, cfg.HealthPort)
+	}
+	if cfg.HTTP.UserAgent != "OpenWebUI-Content-Sync/"+Version {
+		t.Errorf("Expected default User-Agent with the version, got '%s'", cfg.HTTP.UserAgent)
+	}
+	if cfg.OpenWebUI.Retry.MaxRetries != 3 || cfg.OpenWebUI.Retry.BaseDelay != time.Second {
+		t.Errorf("Expected OpenWebUI retry defaults of 3 retries from 1s, got %+v", cfg.OpenWebUI.Retry)
+	}
 }
 
 func TestLoad_FromFile(t *testing.T) {
@@ -49,11 +82,15 @@ openwebui:
 github:
   enabled: true
   token: "custom-token"
+  interval: 6h
   mappings:
     - repository: "owner/repo1"
       knowledge_id: "custom-knowledge-id"
     - repository: "owner/repo2"
       knowledge_id: "custom-knowledge-id"
+local_folders:
+  enabled: true
+  cron: "*/5 * * * *"
 `
 
 	err := os.WriteFile(configPath, []byte(configContent), 0644)
@@ -97,6 +134,149 @@ github:
 	if cfg.GitHub.Mappings[0].KnowledgeID != "custom-knowledge-id" {
 		t.Errorf("Expected first knowledge ID 'custom-knowledge-id', got '%s'", cfg.GitHub.Mappings[0].KnowledgeID)
 	}
+	if cfg.GitHub.Schedule.Interval != 6*time.Hour {
+		t.Errorf("Expected GitHub schedule interval 6h, got %v", cfg.GitHub.Schedule.Interval)
+	}
+	if cfg.LocalFolders.Schedule.Cron != "*/5 * * * *" {
+		t.Errorf("Expected local folders cron '*/5 * * * *', got '%s'", cfg.LocalFolders.Schedule.Cron)
+	}
+	if cfg.Slack.Schedule.IsSet() {
+		t.Errorf("Expected Slack to fall back to the global schedule, got %+v", cfg.Slack.Schedule)
+	}
+}
+
+func TestLoad_HealthServer(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		expectedEnabled bool
+		expectedPort    int
+	}{
+		{"defaults", "log_level: info\n", true, 8080},
+		{"custom port", "health_port: 9091\n", true, 9091},
+		{"disabled", "health_enabled: false\n", false, 8080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.HealthEnabled != tt.expectedEnabled {
+				t.Errorf("Expected health enabled %v, got %v", tt.expectedEnabled, cfg.HealthEnabled)
+			}
+			if cfg.HealthPort != tt.expectedPort {
+				t.Errorf("Expected health port %d, got %d", tt.expectedPort, cfg.HealthPort)
+			}
+		})
+	}
+}
+
+func TestLoad_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		openwebui  RetryConfig
+		confluence RetryConfig
+		jira       RetryConfig
+		slack      RetryConfig
+	}{
+		{
+			name:       "defaults",
+			content:    "log_level: info\n",
+			openwebui:  RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			confluence: RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			jira:       RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			slack:      RetryConfig{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Minute, Multiplier: 2},
+		},
+		{
+			name: "top-level retry with section overrides",
+			content: `
+retry:
+  max_retries: 6
+  base_delay: 500ms
+  max_delay: 2m
+  multiplier: 1.5
+openwebui:
+  retry:
+    max_retries: 2
+jira:
+  retry:
+    base_delay: 3s
+    multiplier: 3
+slack:
+  retry:
+    max_delay: 10m
+`,
+			openwebui:  RetryConfig{MaxRetries: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 2 * time.Minute, Multiplier: 1.5},
+			confluence: RetryConfig{MaxRetries: 6, BaseDelay: 500 * time.Millisecond, MaxDelay: 2 * time.Minute, Multiplier: 1.5},
+			jira:       RetryConfig{MaxRetries: 6, BaseDelay: 3 * time.Second, MaxDelay: 2 * time.Minute, Multiplier: 3},
+			slack:      RetryConfig{MaxRetries: 6, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Minute, Multiplier: 1.5},
+		},
+		{
+			name: "explicit zeros override",
+			content: `
+retry:
+  max_retries: 0
+confluence:
+  retry:
+    max_retries: 2
+jira:
+  retry:
+    base_delay: 0s
+slack:
+  retry:
+    max_delay: 30s
+`,
+			openwebui:  RetryConfig{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			confluence: RetryConfig{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			jira:       RetryConfig{MaxRetries: 0, BaseDelay: 0, MaxDelay: time.Minute, Multiplier: 2},
+			slack:      RetryConfig{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: 30 * time.Second, Multiplier: 2},
+		},
+		{
+			name: "Slack defaults only fill settings neither retry sets",
+			content: `
+retry:
+  max_retries: 4
+`,
+			openwebui:  RetryConfig{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			confluence: RetryConfig{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			jira:       RetryConfig{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+			slack:      RetryConfig{MaxRetries: 4, BaseDelay: time.Second, MaxDelay: 5 * time.Minute, Multiplier: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.OpenWebUI.Retry != tt.openwebui {
+				t.Errorf("Expected OpenWebUI retry %+v, got %+v", tt.openwebui, cfg.OpenWebUI.Retry)
+			}
+			if cfg.Confluence.Retry != tt.confluence {
+				t.Errorf("Expected Confluence retry %+v, got %+v", tt.confluence, cfg.Confluence.Retry)
+			}
+			if cfg.Jira.Retry != tt.jira {
+				t.Errorf("Expected Jira retry %+v, got %+v", tt.jira, cfg.Jira.Retry)
+			}
+			if cfg.Slack.Retry != tt.slack {
+				t.Errorf("Expected Slack retry %+v, got %+v", tt.slack, cfg.Slack.Retry)
+			}
+		})
+	}
 }
 
 func TestLoad_EnvironmentOverride(t *testing.T) {
@@ -204,6 +384,110 @@ github:
 	}
 }
 
+func TestLoad_EnvFile(t *testing.T) {
+	tempDir := t.TempDir()
+	envPath := filepath.Join(tempDir, "sync.env")
+
+	envContent := `# Local overrides
+OPENWEBUI_API_KEY=dotenv-api-key
+export GITHUB_TOKEN="dotenv-github-token"
+SLACK_TOKEN='dotenv-slack-token' # trailing comment
+`
+	if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	t.Setenv("ENV_FILE", envPath)
+	// t.Setenv restores these after the test, including the ones set by the env file
+	for _, key := range []string{"OPENWEBUI_API_KEY", "SLACK_TOKEN"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	// Real environment variables take precedence over the env file
+	t.Setenv("GITHUB_TOKEN", "env-github-token")
+
+	cfg, err := Load("non-existent-config.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.OpenWebUI.APIKey != "dotenv-api-key" {
+		t.Errorf("Expected OpenWebUI API key from env file, got '%s'", cfg.OpenWebUI.APIKey)
+	}
+	if cfg.Slack.Token != "dotenv-slack-token" {
+		t.Errorf("Expected Slack token from env file, got '%s'", cfg.Slack.Token)
+	}
+	if cfg.GitHub.Token != "env-github-token" {
+		t.Errorf("Expected environment to take precedence over env file, got '%s'", cfg.GitHub.Token)
+	}
+}
+
+func TestLoad_InvalidEnvFile(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envPath, []byte("NOT A VARIABLE\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	t.Setenv("ENV_FILE", envPath)
+
+	if _, err := Load("non-existent-config.yaml"); err == nil {
+		t.Error("Expected error for an invalid env file, got none")
+	}
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	secrets := map[string]string{
+		"OPENWEBUI_API_KEY":  "file-openwebui-key",
+		"GITHUB_TOKEN":       "file-github-token",
+		"CONFLUENCE_API_KEY": "file-confluence-key",
+		"JIRA_API_KEY":       "file-jira-key",
+		"SLACK_TOKEN":        "file-slack-token",
+	}
+	for key, secret := range secrets {
+		path := filepath.Join(tempDir, key)
+		// Mounted secrets often end with a newline
+		if err := os.WriteFile(path, []byte(secret+"\n"), 0600); err != nil {
+			t.Fatalf("Failed to write secret file: %v", err)
+		}
+		t.Setenv(key+"_FILE", path)
+		// t.Setenv restores the variable after the test, including when set from its file
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	// A variable set directly wins over its file
+	t.Setenv("GITHUB_TOKEN", "env-github-token")
+
+	cfg, err := Load("non-existent-config.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.OpenWebUI.APIKey != "file-openwebui-key" {
+		t.Errorf("Expected OpenWebUI API key from file, got '%s'", cfg.OpenWebUI.APIKey)
+	}
+	if cfg.GitHub.Token != "env-github-token" {
+		t.Errorf("Expected environment to take precedence over secret file, got '%s'", cfg.GitHub.Token)
+	}
+	if cfg.Confluence.APIKey != "file-confluence-key" {
+		t.Errorf("Expected Confluence API key from file, got '%s'", cfg.Confluence.APIKey)
+	}
+	if cfg.Slack.Token != "file-slack-token" {
+		t.Errorf("Expected Slack token from file, got '%s'", cfg.Slack.Token)
+	}
+	if cfg.Jira.APIKey != "file-jira-key" {
+		t.Errorf("Expected Jira API key from file, got '%s'", cfg.Jira.APIKey)
+	}
+}
+
+func TestLoad_MissingSecretFile(t *testing.T) {
+	t.Setenv("SLACK_TOKEN", "")
+	t.Setenv("SLACK_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	if _, err := Load("non-existent-config.yaml"); err == nil {
+		t.Error("Expected error for a missing secret file, got none")
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	// Test with existing environment variable
 	os.Setenv("TEST_VAR", "test-value")
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretEnvVars are the environment variables that can also be read from a file named by
+// <NAME>_FILE, e.g. a Docker or Kubernetes secret mounted as GITHUB_TOKEN_FILE=/run/secrets/github
+var secretEnvVars = []string{
+	"OPENWEBUI_API_KEY",
+	"GITHUB_TOKEN",
+	"CONFLUENCE_API_KEY",
+	"JIRA_API_KEY",
+	"SLACK_TOKEN",
+	"S3_SECRET_ACCESS_KEY",
+	"SLACK_SIGNING_SECRET",
+	"CONFLUENCE_WEBHOOK_SECRET",
+	"JIRA_WEBHOOK_SECRET",
+}
+
+// loadSecretFiles sets each unset secret variable to the trimmed content of the file named
+// by its _FILE variable. A variable set directly takes precedence over its file.
+func loadSecretFiles() error {
+	for _, key := range secretEnvVars {
+		path := os.Getenv(key + "_FILE")
+		if path == "" || os.Getenv(key) != "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_FILE: %w", key, err)
+		}
+		if err := os.Setenv(key, strings.TrimSpace(string(data))); err != nil {
+			return fmt.Errorf("failed to set %s from file: %w", key, err)
+		}
+	}
+	return nil
+}
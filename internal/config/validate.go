@@ -0,0 +1,357 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// minConfluenceFilenameLength leaves room for a title next to the 9 character suffix that
+// tells clashing Confluence filenames apart
+const minConfluenceFilenameLength = 16
+
+// Validate checks the configuration for problems that would otherwise only surface once
+// an adapter starts. Every problem found is reported in the returned (joined) error.
+func (c *Config) Validate() error {
+	var errs []error
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if err := validateURL(c.OpenWebUI.BaseURL); err != nil {
+		addErr("openwebui.base_url: %w", err)
+	}
+	if c.OpenWebUI.ProcessingTimeout < 0 {
+		addErr("openwebui.processing_timeout must not be negative")
+	}
+	if c.OpenWebUI.ProcessingPollInterval < 0 {
+		addErr("openwebui.processing_poll_interval must not be negative")
+	}
+	if c.OpenWebUI.RequestTimeout < 0 {
+		addErr("openwebui.request_timeout must not be negative")
+	}
+	targetNames := make(map[string]bool)
+	for i, target := range c.OpenWebUI.Targets {
+		switch {
+		case target.Name == "":
+			addErr("openwebui.targets[%d].name is required", i)
+		case target.Name == "." || target.Name == ".." || strings.ContainsAny(target.Name, `/\`):
+			addErr("openwebui.targets[%d].name %q must not be a path", i, target.Name)
+		case targetNames[target.Name]:
+			addErr("openwebui.targets[%d].name %q is used by another target", i, target.Name)
+		}
+		targetNames[target.Name] = true
+		if err := validateURL(target.BaseURL); err != nil {
+			addErr("openwebui.targets[%d].base_url: %w", i, err)
+		}
+	}
+	// Sections inherit the top-level retry, so only their own settings are reported for them
+	errs = append(errs, validateRetry("retry", c.Retry, RetryConfig{})...)
+	errs = append(errs, validateRetry("openwebui.retry", c.OpenWebUI.Retry, c.Retry)...)
+	errs = append(errs, validateRetry("confluence.retry", c.Confluence.Retry, c.Retry)...)
+	errs = append(errs, validateRetry("jira.retry", c.Jira.Retry, c.Retry)...)
+	errs = append(errs, validateRetry("slack.retry", c.Slack.Retry, c.Retry)...)
+	if c.Sync.KnowledgeAddDelay < 0 {
+		addErr("sync.knowledge_add_delay must not be negative")
+	}
+	if c.Sync.ShutdownTimeout < 0 {
+		addErr("sync.shutdown_timeout must not be negative")
+	}
+	if c.Sync.Timeout < 0 {
+		addErr("sync.timeout must not be negative")
+	}
+	if c.Sync.AdapterTimeout < 0 {
+		addErr("sync.adapter_timeout must not be negative")
+	}
+	switch c.Sync.ConflictStrategy {
+	case "", "overwrite", "skip":
+	default:
+		addErr("sync.conflict_strategy %q must be overwrite or skip", c.Sync.ConflictStrategy)
+	}
+	if c.Storage.Path == "" {
+		addErr("storage.path is required")
+	}
+	switch c.Storage.Backend {
+	case "", "local":
+	case "s3":
+		if c.Storage.S3.Bucket == "" {
+			addErr("storage.s3.bucket is required")
+		}
+		if c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			addErr("storage.s3.access_key_id and secret_access_key are required")
+		}
+		if c.Storage.S3.Endpoint != "" {
+			if err := validateURL(c.Storage.S3.Endpoint); err != nil {
+				addErr("storage.s3.endpoint: %w", err)
+			}
+		}
+	default:
+		addErr("storage.backend %q must be local or s3", c.Storage.Backend)
+	}
+	if c.Storage.SnapshotHistory {
+		switch c.Storage.SnapshotCompression {
+		case "", "gzip", "none":
+		default:
+			addErr("storage.snapshot_compression %q must be gzip or none", c.Storage.SnapshotCompression)
+		}
+		if c.Storage.SnapshotRetention < 1 {
+			addErr("storage.snapshot_retention must be at least 1")
+		}
+	}
+
+	if !c.GitHub.Enabled && !c.Confluence.Enabled && !c.Jira.Enabled && !c.LocalFolders.Enabled && !c.Slack.Enabled {
+		addErr("at least one adapter must be enabled")
+	}
+
+	if c.GitHub.Enabled {
+		if c.GitHub.Token == "" && len(c.GitHub.Tokens) == 0 {
+			addErr("github.token is required (or set GITHUB_TOKEN or github.tokens)")
+		}
+		if c.GitHub.BaseURL != "" {
+			if err := validateURL(c.GitHub.BaseURL); err != nil {
+				addErr("github.base_url: %w", err)
+			}
+		}
+		if c.GitHub.UploadURL != "" {
+			if err := validateURL(c.GitHub.UploadURL); err != nil {
+				addErr("github.upload_url: %w", err)
+			}
+		}
+		if c.GitHub.DownloadConcurrency < 0 {
+			addErr("github.download_concurrency must not be negative")
+		}
+		if len(c.GitHub.Mappings) == 0 {
+			addErr("github.mappings must contain at least one repository")
+		}
+		for i, mapping := range c.GitHub.Mappings {
+			if parts := strings.Split(mapping.Repository, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				addErr("github.mappings[%d].repository %q must have the format owner/repo", i, mapping.Repository)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("github.mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+			switch mapping.IssueState {
+			case "", "open", "closed", "all":
+			default:
+				addErr("github.mappings[%d].issue_state %q must be open, closed or all", i, mapping.IssueState)
+			}
+			for _, dir := range mapping.ExcludeDirs {
+				if _, err := path.Match(dir, ""); err != nil || strings.Trim(dir, "/") == "" {
+					addErr("github.mappings[%d].exclude_dirs entry %q is not a valid directory pattern", i, dir)
+				}
+			}
+		}
+	}
+
+	if c.Confluence.Enabled {
+		if err := validateURL(c.Confluence.BaseURL); err != nil {
+			addErr("confluence.base_url: %w", err)
+		}
+		switch c.Confluence.AuthType {
+		case "", "basic":
+			if c.Confluence.Username == "" {
+				addErr("confluence.username is required")
+			}
+		case "bearer":
+		default:
+			addErr("confluence.auth_type %q must be basic or bearer", c.Confluence.AuthType)
+		}
+		if c.Confluence.APIKey == "" {
+			addErr("confluence.api_key is required (or set CONFLUENCE_API_KEY)")
+		}
+		if len(c.Confluence.SpaceMappings) == 0 && len(c.Confluence.ParentPageMappings) == 0 && len(c.Confluence.CQLMappings) == 0 {
+			addErr("confluence needs at least one space_mappings, parent_page_mappings or cql_mappings entry")
+		}
+		for i, mapping := range c.Confluence.SpaceMappings {
+			if mapping.SpaceKey == "" {
+				addErr("confluence.space_mappings[%d].space_key is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("confluence.space_mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+		}
+		for i, mapping := range c.Confluence.ParentPageMappings {
+			if mapping.ParentPageID == "" {
+				addErr("confluence.parent_page_mappings[%d].parent_page_id is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("confluence.parent_page_mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+		}
+		for i, mapping := range c.Confluence.CQLMappings {
+			if strings.TrimSpace(mapping.CQL) == "" {
+				addErr("confluence.cql_mappings[%d].cql is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("confluence.cql_mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+		}
+		if c.Confluence.MaxFilenameLength < 0 || (c.Confluence.MaxFilenameLength > 0 && c.Confluence.MaxFilenameLength < minConfluenceFilenameLength) {
+			addErr("confluence.max_filename_length must be at least %d", minConfluenceFilenameLength)
+		}
+		if c.Confluence.Deployment != "" && c.Confluence.Deployment != "cloud" && c.Confluence.Deployment != "server" {
+			addErr("confluence.deployment %q must be cloud or server", c.Confluence.Deployment)
+		}
+	}
+
+	if c.Jira.Enabled {
+		if err := validateURL(c.Jira.BaseURL); err != nil {
+			addErr("jira.base_url: %w", err)
+		}
+		switch c.Jira.AuthType {
+		case "", "basic":
+			if c.Jira.Username == "" {
+				addErr("jira.username is required")
+			}
+		case "bearer":
+		default:
+			addErr("jira.auth_type %q must be basic or bearer", c.Jira.AuthType)
+		}
+		if c.Jira.APIKey == "" {
+			addErr("jira.api_key is required")
+		}
+		if c.Jira.Deployment != "" && c.Jira.Deployment != "cloud" && c.Jira.Deployment != "server" {
+			addErr("jira.deployment %q must be cloud or server", c.Jira.Deployment)
+		}
+		if len(c.Jira.ProjectMappings) == 0 {
+			addErr("jira.project_mappings must contain at least one project")
+		}
+		for i, mapping := range c.Jira.ProjectMappings {
+			if mapping.ProjectKey == "" {
+				addErr("jira.project_mappings[%d].project_key is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("jira.project_mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+		}
+		if c.Jira.MaxAttachmentSizeBytes < 0 {
+			addErr("jira.max_attachment_size_bytes must not be negative")
+		}
+		if c.Jira.ChangelogLimit < 0 {
+			addErr("jira.changelog_limit must not be negative")
+		}
+	}
+
+	if c.LocalFolders.Enabled {
+		if len(c.LocalFolders.Mappings) == 0 {
+			addErr("local_folders.mappings must contain at least one folder")
+		}
+		for i, mapping := range c.LocalFolders.Mappings {
+			if mapping.FolderPath == "" {
+				addErr("local_folders.mappings[%d].folder_path is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("local_folders.mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+		}
+		for _, list := range []struct {
+			name       string
+			extensions []string
+		}{{"include_extensions", c.LocalFolders.IncludeExtensions}, {"exclude_extensions", c.LocalFolders.ExcludeExtensions}} {
+			for _, ext := range list.extensions {
+				if strings.TrimSpace(ext) == "" || strings.ContainsAny(ext, `/\*`) {
+					addErr("local_folders.%s entry %q is not a file extension", list.name, ext)
+				}
+			}
+		}
+	}
+
+	if c.Slack.Enabled {
+		if c.Slack.Token == "" {
+			addErr("slack.token is required (or set SLACK_TOKEN)")
+		}
+		if len(c.Slack.ChannelMappings) == 0 && len(c.Slack.RegexPatterns) == 0 {
+			addErr("slack needs at least one channel_mappings or regex_patterns entry")
+		}
+		for i, mapping := range c.Slack.ChannelMappings {
+			if mapping.ChannelID == "" {
+				addErr("slack.channel_mappings[%d].channel_id is required", i)
+			}
+			if mapping.KnowledgeID == "" && mapping.KnowledgeName == "" {
+				addErr("slack.channel_mappings[%d].knowledge_id or knowledge_name is required", i)
+			}
+			if mapping.DaysToFetch < 0 {
+				addErr("slack.channel_mappings[%d].days_to_fetch must not be negative", i)
+			}
+		}
+		switch c.Slack.MessageFormat {
+		case "", "threaded", "nested", "flat", "json":
+		default:
+			addErr("slack.message_format %q must be threaded, nested, flat or json", c.Slack.MessageFormat)
+		}
+		if c.Slack.HistoryRetentionDays < 0 {
+			addErr("slack.history_retention_days must not be negative")
+		}
+		if c.Slack.MaxErrorLogBytes < 0 {
+			addErr("slack.max_error_log_bytes must not be negative")
+		}
+		for i, pattern := range c.Slack.RegexPatterns {
+			if pattern.Pattern == "" {
+				addErr("slack.regex_patterns[%d].pattern is required", i)
+			} else if _, err := regexp.Compile(pattern.Pattern); err != nil {
+				addErr("slack.regex_patterns[%d].pattern %q is invalid: %w", i, pattern.Pattern, err)
+			}
+			if pattern.KnowledgeID == "" && pattern.KnowledgeName == "" {
+				addErr("slack.regex_patterns[%d].knowledge_id or knowledge_name is required", i)
+			}
+			if pattern.DaysToFetch < 0 {
+				addErr("slack.regex_patterns[%d].days_to_fetch must not be negative", i)
+			}
+		}
+	}
+
+	if c.Webhooks.Enabled {
+		if c.Webhooks.SlackSigningSecret == "" && c.Webhooks.ConfluenceSecret == "" && c.Webhooks.JiraSecret == "" {
+			addErr("webhooks need at least one of slack_signing_secret, confluence_secret or jira_secret")
+		}
+		switch {
+		case c.Webhooks.Port < 0 || c.Webhooks.Port > 65535:
+			addErr("webhooks.port %d is not a valid port", c.Webhooks.Port)
+		case c.Webhooks.Port == 0 && !c.HealthEnabled:
+			addErr("webhooks.port is required when the health server is disabled")
+		case c.Webhooks.Port != 0 && c.Webhooks.Port == c.HealthPort && c.HealthEnabled:
+			addErr("webhooks.port %d is used by the health server, set it to 0 to share it", c.Webhooks.Port)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRetry checks the retry settings under key that differ from the inherited ones
+func validateRetry(key string, retry, inherited RetryConfig) []error {
+	var errs []error
+	if retry.MaxRetries != inherited.MaxRetries && retry.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_retries must not be negative", key))
+	}
+	if retry.BaseDelay != inherited.BaseDelay && retry.BaseDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.base_delay must not be negative", key))
+	}
+	if retry.MaxDelay != inherited.MaxDelay && retry.MaxDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_delay must not be negative", key))
+	}
+	if retry.Multiplier != inherited.Multiplier && retry.Multiplier != 0 && retry.Multiplier < 1 {
+		errs = append(errs, fmt.Errorf("%s.multiplier %v must be at least 1", key, retry.Multiplier))
+	}
+	return errs
+}
+
+// validateURL checks that rawURL is an absolute http(s) URL
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("URL is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("URL %q must start with http:// or https://", rawURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("URL %q has no host", rawURL)
+	}
+	return nil
+}
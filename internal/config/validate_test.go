@@ -0,0 +1,406 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns a config with a single, correctly configured GitHub adapter
+func validConfig() *Config {
+	return &Config{
+		Storage:   StorageConfig{Path: "/data"},
+		OpenWebUI: OpenWebUIConfig{BaseURL: "http://localhost:8080"},
+		GitHub: GitHubConfig{
+			Enabled:  true,
+			Token:    "ghp_test",
+			Mappings: []RepositoryMapping{{Repository: "owner/repo", KnowledgeID: "knowledge-1"}},
+		},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(cfg *Config)
+		expected []string // substrings expected in the error, none for a valid config
+	}{
+		{
+			name:   "valid config",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "mapping by knowledge name",
+			modify: func(cfg *Config) {
+				cfg.GitHub.Mappings[0].KnowledgeID = ""
+				cfg.GitHub.Mappings[0].KnowledgeName = "Engineering Docs"
+			},
+		},
+		{
+			name: "no adapter enabled",
+			modify: func(cfg *Config) {
+				cfg.GitHub.Enabled = false
+			},
+			expected: []string{"at least one adapter must be enabled"},
+		},
+		{
+			name: "openwebui URL without scheme",
+			modify: func(cfg *Config) {
+				cfg.OpenWebUI.BaseURL = "localhost:8080"
+			},
+			expected: []string{"openwebui.base_url"},
+		},
+		{
+			name: "valid openwebui targets",
+			modify: func(cfg *Config) {
+				cfg.OpenWebUI.Targets = []OpenWebUITarget{
+					{Name: "staging", BaseURL: "https://staging.example.com", KnowledgeIDs: map[string]string{"knowledge-1": "staging-1"}},
+					{Name: "eu", BaseURL: "https://eu.example.com"},
+				}
+			},
+		},
+		{
+			name: "invalid openwebui targets",
+			modify: func(cfg *Config) {
+				cfg.OpenWebUI.Targets = []OpenWebUITarget{
+					{BaseURL: "https://staging.example.com"},
+					{Name: "../prod", BaseURL: "https://prod.example.com"},
+					{Name: "eu", BaseURL: "eu.example.com"},
+					{Name: "eu", BaseURL: "https://eu.example.com"},
+				}
+			},
+			expected: []string{"openwebui.targets[0].name is required", "openwebui.targets[1].name \"../prod\" must not be a path", "openwebui.targets[2].base_url", "openwebui.targets[3].name \"eu\" is used by another target"},
+		},
+		{
+			name: "negative openwebui processing settings",
+			modify: func(cfg *Config) {
+				cfg.OpenWebUI.ProcessingTimeout = -time.Second
+				cfg.OpenWebUI.ProcessingPollInterval = -time.Second
+				cfg.OpenWebUI.RequestTimeout = -time.Second
+			},
+			expected: []string{"openwebui.processing_timeout must not be negative", "openwebui.processing_poll_interval must not be negative", "openwebui.request_timeout must not be negative"},
+		},
+		{
+			name: "negative sync durations",
+			modify: func(cfg *Config) {
+				cfg.Sync.KnowledgeAddDelay = -time.Millisecond
+				cfg.Sync.ShutdownTimeout = -time.Second
+				cfg.Sync.Timeout = -time.Minute
+				cfg.Sync.AdapterTimeout = -time.Minute
+			},
+			expected: []string{"sync.knowledge_add_delay must not be negative", "sync.shutdown_timeout must not be negative",
+				"sync.timeout must not be negative", "sync.adapter_timeout must not be negative"},
+		},
+		{
+			name: "invalid retry settings",
+			modify: func(cfg *Config) {
+				cfg.Retry = RetryConfig{MaxRetries: -1, BaseDelay: -time.Second, Multiplier: 0.5}
+				cfg.Jira.Retry = RetryConfig{MaxRetries: -1, BaseDelay: -time.Second, MaxDelay: -time.Minute, Multiplier: 0.5}
+				cfg.Slack.Retry = RetryConfig{MaxRetries: 5, Multiplier: 0.8}
+			},
+			expected: []string{"retry.max_retries must not be negative", "retry.base_delay must not be negative", "retry.multiplier 0.5 must be at least 1",
+				"jira.retry.max_delay must not be negative", "slack.retry.multiplier 0.8 must be at least 1"},
+		},
+		{
+			name: "invalid conflict strategy",
+			modify: func(cfg *Config) {
+				cfg.Sync.ConflictStrategy = "merge"
+			},
+			expected: []string{`sync.conflict_strategy "merge" must be overwrite or skip`},
+		},
+		{
+			name: "invalid snapshot settings",
+			modify: func(cfg *Config) {
+				cfg.Storage.SnapshotHistory = true
+				cfg.Storage.SnapshotCompression = "zstd"
+				cfg.Storage.SnapshotRetention = 0
+			},
+			expected: []string{"storage.snapshot_compression \"zstd\" must be gzip or none", "storage.snapshot_retention must be at least 1"},
+		},
+		{
+			name: "invalid storage backend",
+			modify: func(cfg *Config) {
+				cfg.Storage.Backend = "ftp"
+			},
+			expected: []string{`storage.backend "ftp" must be local or s3`},
+		},
+		{
+			name: "incomplete s3 storage",
+			modify: func(cfg *Config) {
+				cfg.Storage.Backend = "s3"
+				cfg.Storage.S3 = S3StorageConfig{Endpoint: "minio:9000", AccessKeyID: "key"}
+			},
+			expected: []string{"storage.s3.bucket is required", "storage.s3.access_key_id and secret_access_key are required", "storage.s3.endpoint:"},
+		},
+		{
+			name: "valid webhooks on the health server",
+			modify: func(cfg *Config) {
+				cfg.HealthEnabled = true
+				cfg.Webhooks = WebhookConfig{Enabled: true, JiraSecret: "secret"}
+			},
+		},
+		{
+			name: "webhooks without secrets or a port",
+			modify: func(cfg *Config) {
+				cfg.Webhooks = WebhookConfig{Enabled: true}
+			},
+			expected: []string{"webhooks need at least one of slack_signing_secret, confluence_secret or jira_secret", "webhooks.port is required when the health server is disabled"},
+		},
+		{
+			name: "webhooks on the health port",
+			modify: func(cfg *Config) {
+				cfg.HealthEnabled = true
+				cfg.HealthPort = 8080
+				cfg.Webhooks = WebhookConfig{Enabled: true, Port: 8080, SlackSigningSecret: "secret"}
+			},
+			expected: []string{"webhooks.port 8080 is used by the health server"},
+		},
+		{
+			name: "github mapping problems",
+			modify: func(cfg *Config) {
+				cfg.GitHub.Token = ""
+				cfg.GitHub.Mappings = []RepositoryMapping{{Repository: "just-a-repo", KnowledgeID: ""}}
+			},
+			expected: []string{"github.token is required", "github.mappings[0].repository", "github.mappings[0].knowledge_id or knowledge_name is required"},
+		},
+		{
+			name: "github invalid issue state",
+			modify: func(cfg *Config) {
+				cfg.GitHub.Mappings[0].IncludeIssues = true
+				cfg.GitHub.Mappings[0].IssueState = "merged"
+			},
+			expected: []string{"github.mappings[0].issue_state \"merged\" must be open, closed or all"},
+		},
+		{
+			name: "github invalid exclude dirs",
+			modify: func(cfg *Config) {
+				cfg.GitHub.Mappings[0].ExcludeDirs = []string{"vendor", "[", "/"}
+			},
+			expected: []string{`github.mappings[0].exclude_dirs entry "[" is not a valid directory pattern`, `github.mappings[0].exclude_dirs entry "/" is not a valid directory pattern`},
+		},
+		{
+			name: "negative github download concurrency",
+			modify: func(cfg *Config) {
+				cfg.GitHub.DownloadConcurrency = -1
+			},
+			expected: []string{"github.download_concurrency must not be negative"},
+		},
+		{
+			name: "confluence base URL missing scheme",
+			modify: func(cfg *Config) {
+				cfg.Confluence = ConfluenceConfig{
+					Enabled:       true,
+					BaseURL:       "example.atlassian.net",
+					Username:      "user@example.com",
+					APIKey:        "key",
+					SpaceMappings: []SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "knowledge-1"}},
+				}
+			},
+			expected: []string{"confluence.base_url", "must start with http:// or https://"},
+		},
+		{
+			name: "confluence CQL mapping without a query",
+			modify: func(cfg *Config) {
+				cfg.Confluence = ConfluenceConfig{
+					Enabled:     true,
+					BaseURL:     "https://example.atlassian.net",
+					Username:    "user@example.com",
+					APIKey:      "key",
+					CQLMappings: []CQLMapping{{CQL: " ", KnowledgeID: "knowledge-1"}, {CQL: "label = kb"}},
+				}
+			},
+			expected: []string{"confluence.cql_mappings[0].cql is required", "confluence.cql_mappings[1].knowledge_id or knowledge_name is required"},
+		},
+		{
+			name: "confluence filename length too short",
+			modify: func(cfg *Config) {
+				cfg.Confluence = ConfluenceConfig{
+					Enabled:           true,
+					BaseURL:           "https://example.atlassian.net",
+					Username:          "user@example.com",
+					APIKey:            "key",
+					SpaceMappings:     []SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "knowledge-1"}},
+					MaxFilenameLength: 8,
+				}
+			},
+			expected: []string{"confluence.max_filename_length must be at least 16"},
+		},
+		{
+			name: "unknown confluence deployment",
+			modify: func(cfg *Config) {
+				cfg.Confluence = ConfluenceConfig{
+					Enabled:       true,
+					BaseURL:       "https://wiki.example.com",
+					Username:      "user",
+					APIKey:        "key",
+					SpaceMappings: []SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "knowledge-1"}},
+					Deployment:    "datacenter",
+				}
+			},
+			expected: []string{`confluence.deployment "datacenter" must be cloud or server`},
+		},
+		{
+			name: "unknown jira deployment",
+			modify: func(cfg *Config) {
+				cfg.Jira = JiraConfig{
+					Enabled:         true,
+					BaseURL:         "https://jira.example.com",
+					AuthType:        "bearer",
+					APIKey:          "token",
+					ProjectMappings: []JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "knowledge-1"}},
+					Deployment:      "datacenter",
+				}
+			},
+			expected: []string{`jira.deployment "datacenter" must be cloud or server`},
+		},
+		{
+			name: "slack regex patterns without knowledge IDs",
+			modify: func(cfg *Config) {
+				cfg.Slack = SlackConfig{
+					Enabled: true,
+					Token:   "xoxb-test",
+					RegexPatterns: []RegexPattern{
+						{Pattern: "^eng-.*"},
+						{Pattern: "([", KnowledgeID: "knowledge-2"},
+					},
+				}
+			},
+			expected: []string{"slack.regex_patterns[0].knowledge_id or knowledge_name is required", "slack.regex_patterns[1].pattern \"([\" is invalid"},
+		},
+		{
+			name: "slack negative days_to_fetch override",
+			modify: func(cfg *Config) {
+				cfg.Slack = SlackConfig{
+					Enabled:         true,
+					Token:           "xoxb-test",
+					ChannelMappings: []ChannelMapping{{ChannelID: "C1", KnowledgeID: "knowledge-1", DaysToFetch: -1}},
+				}
+			},
+			expected: []string{"slack.channel_mappings[0].days_to_fetch must not be negative"},
+		},
+		{
+			name: "invalid slack message format",
+			modify: func(cfg *Config) {
+				cfg.Slack = SlackConfig{
+					Enabled:         true,
+					Token:           "xoxb-test",
+					ChannelMappings: []ChannelMapping{{ChannelID: "C1", KnowledgeID: "knowledge-1"}},
+					MessageFormat:   "csv",
+				}
+			},
+			expected: []string{`slack.message_format "csv" must be threaded, nested, flat or json`},
+		},
+		{
+			name: "jira and local folders without mappings",
+			modify: func(cfg *Config) {
+				cfg.Jira = JiraConfig{Enabled: true, BaseURL: "https://jira.example.com", Username: "user"}
+				cfg.LocalFolders = LocalFolderConfig{Enabled: true, Mappings: []LocalFolderMapping{{KnowledgeID: "knowledge-3"}}}
+			},
+			expected: []string{"jira.api_key is required", "jira.project_mappings must contain at least one project", "local_folders.mappings[0].folder_path is required"},
+		},
+		{
+			name: "jira bearer token without username",
+			modify: func(cfg *Config) {
+				cfg.Jira = JiraConfig{
+					Enabled:         true,
+					BaseURL:         "https://jira.example.com",
+					APIKey:          "personal-access-token",
+					AuthType:        "bearer",
+					ProjectMappings: []JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "knowledge-3"}},
+				}
+			},
+		},
+		{
+			name: "unknown confluence auth type",
+			modify: func(cfg *Config) {
+				cfg.Confluence = ConfluenceConfig{
+					Enabled:       true,
+					BaseURL:       "https://wiki.example.com",
+					APIKey:        "key",
+					AuthType:      "token",
+					SpaceMappings: []SpaceMapping{{SpaceKey: "DOCS", KnowledgeID: "knowledge-1"}},
+				}
+			},
+			expected: []string{`confluence.auth_type "token" must be basic or bearer`},
+		},
+		{
+			name: "invalid local folder extensions",
+			modify: func(cfg *Config) {
+				cfg.LocalFolders = LocalFolderConfig{
+					Enabled:           true,
+					Mappings:          []LocalFolderMapping{{FolderPath: "/docs", KnowledgeID: "knowledge-3"}},
+					IncludeExtensions: []string{"md", ""},
+					ExcludeExtensions: []string{"*.lock"},
+				}
+			},
+			expected: []string{`local_folders.include_extensions entry "" is not a file extension`, `local_folders.exclude_extensions entry "*.lock" is not a file extension`},
+		},
+		{
+			name: "negative jira attachment size cap",
+			modify: func(cfg *Config) {
+				cfg.Jira = JiraConfig{
+					Enabled:                true,
+					BaseURL:                "https://jira.example.com",
+					Username:               "user",
+					APIKey:                 "key",
+					ProjectMappings:        []JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "knowledge-1"}},
+					MaxAttachmentSizeBytes: -1,
+				}
+			},
+			expected: []string{"jira.max_attachment_size_bytes must not be negative"},
+		},
+		{
+			name: "negative jira changelog limit",
+			modify: func(cfg *Config) {
+				cfg.Jira = JiraConfig{
+					Enabled:         true,
+					BaseURL:         "https://jira.example.com",
+					Username:        "user",
+					APIKey:          "key",
+					ProjectMappings: []JiraProjectMapping{{ProjectKey: "PROJ", KnowledgeID: "knowledge-1"}},
+					ChangelogLimit:  -1,
+				}
+			},
+			expected: []string{"jira.changelog_limit must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() expected errors %v, got none", tt.expected)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Validate() error %q does not contain %q", err.Error(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Storage.Path = ""
+	cfg.GitHub.Token = ""
+	cfg.Confluence = ConfluenceConfig{Enabled: true}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected an error")
+	}
+	// storage path, GitHub token and four Confluence problems
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 6 {
+		t.Errorf("Expected 6 problems, got %d:\n%v", len(lines), err)
+	}
+}
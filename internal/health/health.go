@@ -5,12 +5,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
 )
 
-// Server provides health check endpoints
+// readyCacheTTL is how long a readiness check result is reused, so frequent probes don't hammer OpenWebUI
+const readyCacheTTL = 5 * time.Second
+
+// readyCheckTimeout bounds a single readiness check, staying below typical probe timeouts
+const readyCheckTimeout = 2 * time.Second
+
+// SyncTrigger starts a synchronization run in the background and returns a channel receiving
+// its result. It returns an error without starting a run when a sync is already in progress.
+type SyncTrigger func() (<-chan error, error)
+
+// StatusProvider returns the JSON-encodable sync status served by the /status endpoint
+type StatusProvider func() any
+
+// ReadinessCheck reports whether the service can reach its dependencies
+type ReadinessCheck func(ctx context.Context) error
+
+// Server provides health check, Prometheus metrics and manual sync endpoints
 type Server struct {
-	server *http.Server
+	server      *http.Server
+	mux         *http.ServeMux
+	syncTrigger SyncTrigger
+	status      StatusProvider
+
+	readyCheck     ReadinessCheck
+	readyMu        sync.Mutex // guards the cached readiness result and serializes checks
+	readyCheckedAt time.Time
+	readyErr       error
 }
 
 // HealthResponse represents the health check response
@@ -18,6 +47,13 @@ type HealthResponse struct {
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
 	Version   string    `json:"version"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// SyncResponse represents the response of a manual sync request
+type SyncResponse struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // NewServer creates a new health check server
@@ -31,15 +67,50 @@ func NewServer(port int) *Server {
 
 	healthServer := &Server{
 		server: server,
+		mux:    mux,
 	}
 
 	// Register health check endpoint
 	mux.HandleFunc("/health", healthServer.healthHandler)
 	mux.HandleFunc("/ready", healthServer.readyHandler)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/sync", healthServer.syncHandler)
+	mux.HandleFunc("/status", healthServer.statusHandler)
 
 	return healthServer
 }
 
+// SetSyncTrigger sets the callback used by the POST /sync endpoint. It must be called before Start.
+func (s *Server) SetSyncTrigger(trigger SyncTrigger) {
+	s.syncTrigger = trigger
+}
+
+// Handle serves additional endpoints, such as the webhook receiver. It must be called before Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// SetReadinessCheck sets the check consulted by the /ready endpoint. It must be called before Start.
+func (s *Server) SetReadinessCheck(check ReadinessCheck) {
+	s.readyCheck = check
+}
+
+// SetStatusProvider sets the source of the /status endpoint. It must be called before Start.
+func (s *Server) SetStatusProvider(provider StatusProvider) {
+	s.status = provider
+}
+
+// OpenWebUICheck returns a readiness check that lists knowledge sources from OpenWebUI,
+// failing when the server is unreachable or rejects the API key
+func OpenWebUICheck(client openwebui.ClientInterface) ReadinessCheck {
+	return func(ctx context.Context) error {
+		if _, err := client.ListKnowledge(ctx); err != nil {
+			return fmt.Errorf("openwebui unreachable: %w", err)
+		}
+		return nil
+	}
+}
+
 // Start starts the health check server
 func (s *Server) Start() error {
 	return s.server.ListenAndServe()
@@ -63,7 +134,7 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// readyHandler handles readiness check requests
+// readyHandler handles readiness check requests, returning 503 while the readiness check fails
 func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 	response := HealthResponse{
 		Status:    "ready",
@@ -71,7 +142,91 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 		Version:   "1.0.0",
 	}
 
+	code := http.StatusOK
+	if err := s.checkReady(r.Context()); err != nil {
+		response.Status = "not_ready"
+		response.Error = err.Error()
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkReady runs the readiness check, reusing a result younger than readyCacheTTL
+func (s *Server) checkReady(ctx context.Context) error {
+	if s.readyCheck == nil {
+		return nil
+	}
+
+	s.readyMu.Lock()
+	defer s.readyMu.Unlock()
+
+	if !s.readyCheckedAt.IsZero() && time.Since(s.readyCheckedAt) < readyCacheTTL {
+		return s.readyErr
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+
+	s.readyErr = s.readyCheck(checkCtx)
+	s.readyCheckedAt = time.Now()
+	if s.readyErr != nil {
+		logrus.Warnf("Readiness check failed: %v", s.readyErr)
+	}
+	return s.readyErr
+}
+
+// statusHandler reports the last sync results of every adapter
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	if s.status == nil {
+		http.Error(w, "status unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(s.status())
+}
+
+// syncHandler handles manual sync requests, starting a sync unless one is already running
+func (s *Server) syncHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.syncTrigger == nil {
+		writeSyncResponse(w, http.StatusServiceUnavailable, "unavailable")
+		return
+	}
+
+	done, err := s.syncTrigger()
+	if err != nil {
+		writeSyncResponse(w, http.StatusAccepted, "already_running")
+		return
+	}
+
+	logrus.Info("Running manually triggered sync")
+	go func() {
+		if err := <-done; err != nil {
+			logrus.Errorf("Manually triggered sync failed: %v", err)
+		}
+	}()
+
+	writeSyncResponse(w, http.StatusOK, "started")
+}
+
+// writeSyncResponse writes a JSON sync response with the given status code
+func writeSyncResponse(w http.ResponseWriter, code int, status string) {
+	response := SyncResponse{
+		Status:    status,
+		Timestamp: time.Now(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(response)
 }
@@ -3,11 +3,21 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/metrics"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/scheduler"
 )
 
 func TestNewServer(t *testing.T) {
@@ -81,6 +91,299 @@ func TestServer_readyHandler(t *testing.T) {
 	}
 }
 
+func TestServer_readyHandler_OpenWebUIUnavailable(t *testing.T) {
+	calls := 0
+	client := &mocks.MockOpenWebUIClient{
+		ListKnowledgeFunc: func(ctx context.Context) ([]*openwebui.Knowledge, error) {
+			calls++
+			return nil, fmt.Errorf("list knowledge failed with status 401: invalid API key")
+		},
+	}
+
+	server := NewServer(8080)
+	server.SetReadinessCheck(OpenWebUICheck(client))
+
+	// Frequent probes within the cache window reuse the first result
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		server.readyHandler(w, httptest.NewRequest("GET", "/ready", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+
+		var response HealthResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Status != "not_ready" {
+			t.Errorf("Expected status 'not_ready', got '%s'", response.Status)
+		}
+		if !strings.Contains(response.Error, "invalid API key") {
+			t.Errorf("Expected error detail in response, got %q", response.Error)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 OpenWebUI call across cached probes, got %d", calls)
+	}
+}
+
+func TestServer_readyHandler_Recovers(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := NewServer(8080)
+	server.SetReadinessCheck(func(ctx context.Context) error {
+		if failing.Load() {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	server.readyHandler(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	// Once the cached result expires the check runs again
+	failing.Store(false)
+	server.readyCheckedAt = time.Now().Add(-readyCacheTTL)
+
+	w = httptest.NewRecorder()
+	server.readyHandler(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d after recovery, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestServer_metricsHandler(t *testing.T) {
+	server := NewServer(8080)
+	metrics.FilesUploaded.WithLabelValues("github").Inc()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	server.server.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	body := w.Body.String()
+	for _, name := range []string{"openwebui_sync_files_uploaded_total", "openwebui_sync_files_removed_total", "openwebui_sync_duration_seconds"} {
+		if !strings.Contains(body, name) {
+			t.Errorf("Expected metric %s in /metrics output", name)
+		}
+	}
+	if !strings.Contains(body, `openwebui_sync_files_uploaded_total{adapter="github"}`) {
+		t.Errorf("Expected labelled upload counter in /metrics output, got:\n%s", body)
+	}
+}
+
+func TestServer_statusHandler(t *testing.T) {
+	server := NewServer(8080)
+
+	req := httptest.NewRequest("GET", "/status", nil)
+	w := httptest.NewRecorder()
+	server.statusHandler(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d without a status provider, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	server.SetStatusProvider(func() any {
+		return map[string]any{"last_sync": nil, "adapters": map[string]any{"github": map[string]any{"files_synced": 3}}}
+	})
+
+	w = httptest.NewRecorder()
+	server.statusHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", contentType)
+	}
+
+	var response map[string]map[string]map[string]int
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response["adapters"]["github"]["files_synced"] != 3 {
+		t.Errorf("Expected provider status to be served, got %v", response)
+	}
+}
+
+func TestServer_syncHandler(t *testing.T) {
+	server := NewServer(8080)
+
+	var calls atomic.Int32
+	var running atomic.Bool
+	release := make(chan struct{})
+	done := make(chan struct{}, 2)
+	server.SetSyncTrigger(func() (<-chan error, error) {
+		if !running.CompareAndSwap(false, true) {
+			return nil, errors.New("sync already running")
+		}
+		calls.Add(1)
+		result := make(chan error, 1)
+		go func() {
+			<-release
+			running.Store(false)
+			done <- struct{}{}
+			result <- nil
+		}()
+		return result, nil
+	})
+
+	// Fire concurrent requests while the first sync is still running
+	const requests = 10
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			server.syncHandler(w, httptest.NewRequest("POST", "/sync", nil))
+			codes <- w.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	started, alreadyRunning := 0, 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			started++
+		case http.StatusAccepted:
+			alreadyRunning++
+		default:
+			t.Errorf("Unexpected status code %d", code)
+		}
+	}
+	if started != 1 || alreadyRunning != requests-1 {
+		t.Errorf("Expected 1 started and %d already running, got %d and %d", requests-1, started, alreadyRunning)
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for sync to finish")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected sync trigger to be called once, got %d", calls.Load())
+	}
+
+	// Once the sync finished, a new request starts another one
+	w := httptest.NewRecorder()
+	server.syncHandler(w, httptest.NewRequest("POST", "/sync", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d after sync finished, got %d", http.StatusOK, w.Code)
+	}
+
+	var response SyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Status != "started" {
+		t.Errorf("Expected status 'started', got '%s'", response.Status)
+	}
+	<-done
+}
+
+// blockingSyncManager blocks every sync until release is closed
+type blockingSyncManager struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingSyncManager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) error {
+	m.started <- struct{}{}
+	<-m.release
+	return nil
+}
+
+func (m *blockingSyncManager) SyncAdapter(ctx context.Context, adpt adapter.Adapter) error {
+	return m.SyncFiles(ctx, []adapter.Adapter{adpt})
+}
+
+func (m *blockingSyncManager) SyncItem(ctx context.Context, adpt adapter.Adapter, id string) error {
+	return nil
+}
+
+func (m *blockingSyncManager) SetKnowledgeID(knowledgeID string) {}
+
+func (m *blockingSyncManager) InitializeFileIndex(ctx context.Context, adapters []adapter.Adapter) error {
+	return nil
+}
+
+func TestServer_syncHandler_ScheduledSyncRunning(t *testing.T) {
+	syncManager := &blockingSyncManager{started: make(chan struct{}, 2), release: make(chan struct{})}
+	sched := scheduler.New(time.Hour, nil, syncManager)
+
+	server := NewServer(8080)
+	server.SetSyncTrigger(func() (<-chan error, error) {
+		return sched.StartSyncWithContext(context.Background())
+	})
+
+	// A scheduled sync holds the scheduler while the request arrives
+	scheduled := make(chan error, 1)
+	go func() {
+		scheduled <- sched.RunSyncWithContext(context.Background())
+	}()
+	<-syncManager.started
+
+	w := httptest.NewRecorder()
+	server.syncHandler(w, httptest.NewRequest("POST", "/sync", nil))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status code %d during a scheduled sync, got %d", http.StatusAccepted, w.Code)
+	}
+	var response SyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Status != "already_running" {
+		t.Errorf("Expected status 'already_running', got '%s'", response.Status)
+	}
+
+	close(syncManager.release)
+	if err := <-scheduled; err != nil {
+		t.Fatalf("Scheduled sync failed: %v", err)
+	}
+
+	// Once the scheduled sync finished, a request starts a sync
+	w = httptest.NewRecorder()
+	server.syncHandler(w, httptest.NewRequest("POST", "/sync", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d after the scheduled sync, got %d", http.StatusOK, w.Code)
+	}
+	select {
+	case <-syncManager.started:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the triggered sync to start")
+	}
+	sched.Wait()
+}
+
+func TestServer_syncHandler_Errors(t *testing.T) {
+	server := NewServer(8080)
+
+	w := httptest.NewRecorder()
+	server.syncHandler(w, httptest.NewRequest("POST", "/sync", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d without sync trigger, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	server.SetSyncTrigger(func() (<-chan error, error) { return make(chan error, 1), nil })
+	w = httptest.NewRecorder()
+	server.syncHandler(w, httptest.NewRequest("GET", "/sync", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code %d for GET, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
 func TestServer_Start(t *testing.T) {
 	server := NewServer(8080) // Use port 0 for random port
 
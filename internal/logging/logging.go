@@ -0,0 +1,53 @@
+// Package logging defines the logger the sync manager and adapters write to, so tests can
+// capture their logs and each component can carry its own fields and level.
+package logging
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Logger writes formatted log messages at the usual levels
+type Logger interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+
+	// WithField returns a logger that adds key=value to every message
+	WithField(key string, value any) Logger
+}
+
+// logrusLogger is a Logger backed by a logrus entry
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// New returns a Logger writing to logger with its level and formatter
+func New(logger *logrus.Logger) Logger {
+	return &logrusLogger{entry: logrus.NewEntry(logger)}
+}
+
+// Default returns a Logger writing to the global logrus logger
+func Default() Logger {
+	return New(logrus.StandardLogger())
+}
+
+func (l *logrusLogger) Debugf(format string, args ...any) {
+	l.entry.Debugf(format, args...)
+}
+
+func (l *logrusLogger) Infof(format string, args ...any) {
+	l.entry.Infof(format, args...)
+}
+
+func (l *logrusLogger) Warnf(format string, args ...any) {
+	l.entry.Warnf(format, args...)
+}
+
+func (l *logrusLogger) Errorf(format string, args ...any) {
+	l.entry.Errorf(format, args...)
+}
+
+func (l *logrusLogger) WithField(key string, value any) Logger {
+	return &logrusLogger{entry: l.entry.WithField(key, value)}
+}
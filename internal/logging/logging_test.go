@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buf)
+	logrusLogger.SetLevel(logrus.InfoLevel)
+	logrusLogger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	logger := New(logrusLogger).WithField("adapter", "github")
+	logger.Debugf("Fetching %s", "owner/repo")
+	logger.Warnf("Skipping %s", "docs/image.png")
+
+	output := buf.String()
+	if strings.Contains(output, "Fetching") {
+		t.Errorf("Expected the debug message to be dropped at info level, got %q", output)
+	}
+	for _, expected := range []string{"level=warning", `msg="Skipping docs/image.png"`, "adapter=github"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got %q", expected, output)
+		}
+	}
+}
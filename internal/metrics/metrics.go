@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics exposed on the health server's /metrics endpoint
+var (
+	// FilesUploaded counts files uploaded to OpenWebUI, labelled by adapter
+	FilesUploaded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "openwebui_sync_files_uploaded_total",
+		Help: "Total number of files uploaded to OpenWebUI.",
+	}, []string{"adapter"})
+
+	// FilesRemoved counts orphaned files removed from OpenWebUI
+	FilesRemoved = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openwebui_sync_files_removed_total",
+		Help: "Total number of orphaned files removed from OpenWebUI.",
+	})
+
+	// SyncErrors counts fetch and file sync failures, labelled by adapter
+	SyncErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "openwebui_sync_errors_total",
+		Help: "Total number of sync errors.",
+	}, []string{"adapter"})
+
+	// LastSuccessfulSync records when each adapter last synced without errors
+	LastSuccessfulSync = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openwebui_sync_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last sync that completed without errors.",
+	}, []string{"adapter"})
+
+	// SyncDuration tracks how long full sync runs take
+	SyncDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "openwebui_sync_duration_seconds",
+		Help:    "Duration of sync runs in seconds.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	})
+)
@@ -2,17 +2,22 @@ package mocks
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
 	"github.com/openwebui-content-sync/internal/openwebui"
 )
 
 // MockOpenWebUIClient is a mock implementation of OpenWebUI client
 type MockOpenWebUIClient struct {
-	UploadFileFunc              func(ctx context.Context, filename string, content []byte) (*openwebui.File, error)
+	UploadFileFunc              func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error)
 	GetFileFunc                 func(ctx context.Context, fileID string) (*openwebui.File, error)
+	UpdateFileContentFunc       func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error)
 	ListKnowledgeFunc           func(ctx context.Context) ([]*openwebui.Knowledge, error)
+	CreateKnowledgeFunc         func(ctx context.Context, name, description string) (*openwebui.Knowledge, error)
 	AddFileToKnowledgeFunc      func(ctx context.Context, knowledgeID, fileID string) error
 	RemoveFileFromKnowledgeFunc func(ctx context.Context, knowledgeID, fileID string) error
 	GetKnowledgeFilesFunc       func(ctx context.Context, knowledgeID string) ([]*openwebui.File, error)
@@ -20,9 +25,9 @@ type MockOpenWebUIClient struct {
 }
 
 // UploadFile mocks the UploadFile method
-func (m *MockOpenWebUIClient) UploadFile(ctx context.Context, filename string, content []byte) (*openwebui.File, error) {
+func (m *MockOpenWebUIClient) UploadFile(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
 	if m.UploadFileFunc != nil {
-		return m.UploadFileFunc(ctx, filename, content)
+		return m.UploadFileFunc(ctx, filename, contentType, content)
 	}
 	return &openwebui.File{
 		ID:       "mock-file-id",
@@ -83,6 +88,14 @@ func (m *MockOpenWebUIClient) GetFile(ctx context.Context, fileID string) (*open
 	}, nil
 }
 
+// UpdateFileContent mocks the UpdateFileContent method
+func (m *MockOpenWebUIClient) UpdateFileContent(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+	if m.UpdateFileContentFunc != nil {
+		return m.UpdateFileContentFunc(ctx, fileID, filename, content)
+	}
+	return &openwebui.File{ID: fileID, Filename: filename}, nil
+}
+
 // ListKnowledge mocks the ListKnowledge method
 func (m *MockOpenWebUIClient) ListKnowledge(ctx context.Context) ([]*openwebui.Knowledge, error) {
 	if m.ListKnowledgeFunc != nil {
@@ -103,6 +116,14 @@ func (m *MockOpenWebUIClient) ListKnowledge(ctx context.Context) ([]*openwebui.K
 	}, nil
 }
 
+// CreateKnowledge mocks the CreateKnowledge method
+func (m *MockOpenWebUIClient) CreateKnowledge(ctx context.Context, name, description string) (*openwebui.Knowledge, error) {
+	if m.CreateKnowledgeFunc != nil {
+		return m.CreateKnowledgeFunc(ctx, name, description)
+	}
+	return &openwebui.Knowledge{ID: "mock-created-knowledge-id", Name: name, Description: description}, nil
+}
+
 // AddFileToKnowledge mocks the AddFileToKnowledge method
 func (m *MockOpenWebUIClient) AddFileToKnowledge(ctx context.Context, knowledgeID, fileID string) error {
 	if m.AddFileToKnowledgeFunc != nil {
@@ -165,11 +186,12 @@ func (m *MockOpenWebUIClient) DeleteFile(ctx context.Context, fileID string) err
 
 // MockAdapter is a mock implementation of the Adapter interface
 type MockAdapter struct {
-	NameFunc        func() string
-	FetchFilesFunc  func(ctx context.Context) ([]*adapter.File, error)
-	GetLastSyncFunc func() time.Time
-	SetLastSyncFunc func(t time.Time)
-	lastSync        time.Time
+	NameFunc          func() string
+	FetchFilesFunc    func(ctx context.Context) ([]*adapter.File, error)
+	GetLastSyncFunc   func() time.Time
+	SetLastSyncFunc   func(t time.Time)
+	FetchCompleteFunc func() bool
+	lastSync          time.Time
 }
 
 // Name mocks the Name method
@@ -197,6 +219,14 @@ func (m *MockAdapter) FetchFiles(ctx context.Context) ([]*adapter.File, error) {
 	}, nil
 }
 
+// FetchComplete mocks the FetchComplete method; fetches are incomplete by default
+func (m *MockAdapter) FetchComplete() bool {
+	if m.FetchCompleteFunc != nil {
+		return m.FetchCompleteFunc()
+	}
+	return false
+}
+
 // GetLastSync mocks the GetLastSync method
 func (m *MockAdapter) GetLastSync() time.Time {
 	if m.GetLastSyncFunc != nil {
@@ -213,3 +243,65 @@ func (m *MockAdapter) SetLastSync(t time.Time) {
 		m.lastSync = t
 	}
 }
+
+// LogEntry is a message written to a MockLogger
+type LogEntry struct {
+	Level   string // debug, info, warn or error
+	Message string
+	Fields  map[string]any
+}
+
+// MockLogger is a logging.Logger that records every message instead of writing it
+type MockLogger struct {
+	fields  map[string]any
+	entries *[]LogEntry
+	mu      *sync.Mutex
+}
+
+// NewMockLogger creates a logger that records the messages of itself and the loggers derived from it
+func NewMockLogger() *MockLogger {
+	return &MockLogger{entries: &[]LogEntry{}, mu: &sync.Mutex{}}
+}
+
+// Entries returns the messages recorded so far
+func (m *MockLogger) Entries() []LogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]LogEntry(nil), *m.entries...)
+}
+
+func (m *MockLogger) record(level, format string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*m.entries = append(*m.entries, LogEntry{Level: level, Message: fmt.Sprintf(format, args...), Fields: m.fields})
+}
+
+// Debugf records a debug message
+func (m *MockLogger) Debugf(format string, args ...any) {
+	m.record("debug", format, args)
+}
+
+// Infof records an info message
+func (m *MockLogger) Infof(format string, args ...any) {
+	m.record("info", format, args)
+}
+
+// Warnf records a warning
+func (m *MockLogger) Warnf(format string, args ...any) {
+	m.record("warn", format, args)
+}
+
+// Errorf records an error message
+func (m *MockLogger) Errorf(format string, args ...any) {
+	m.record("error", format, args)
+}
+
+// WithField returns a logger recording into the same entries with key=value added
+func (m *MockLogger) WithField(key string, value any) logging.Logger {
+	fields := make(map[string]any, len(m.fields)+1)
+	for k, v := range m.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return &MockLogger{fields: fields, entries: m.entries, mu: m.mu}
+}
@@ -3,22 +3,52 @@ package openwebui
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/utils"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultPageSize is the number of knowledge sources requested per page
+	defaultPageSize = 100
+	// defaultProcessingTimeout is how long to wait for an uploaded file to be processed
+	defaultProcessingTimeout = 680 * time.Second
+	// defaultProcessingPollInterval is the initial interval between file status checks
+	defaultProcessingPollInterval = 2 * time.Second
+	// defaultRequestTimeout is the timeout of a single HTTP request
+	defaultRequestTimeout = 5 * time.Minute
+)
+
+// ErrFileNotFound is returned by GetFile when OpenWebUI has no file with the given ID
+var ErrFileNotFound = errors.New("file not found")
+
 // Client represents the OpenWebUI API client
 type Client struct {
-	baseURL string
-	apiKey  string
-	client  *http.Client
+	baseURL     string
+	apiKey      string
+	client      *http.Client
+	pageSize    int
+	retryConfig utils.RetryConfig
+
+	processingTimeout      time.Duration
+	processingPollInterval time.Duration
+
+	userAgent string
+	headers   map[string]string
 }
 
 // File represents a file in OpenWebUI
@@ -64,24 +94,174 @@ func NewClient(baseURL, apiKey string) *Client {
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		client: &http.Client{
-			Timeout: 5 * time.Minute,
+			Timeout: defaultRequestTimeout,
 		},
+		pageSize:               defaultPageSize,
+		retryConfig:            utils.DefaultRetryConfig(),
+		processingTimeout:      defaultProcessingTimeout,
+		processingPollInterval: defaultProcessingPollInterval,
 	}
 }
 
-// UploadFile uploads a file to OpenWebUI
-func (c *Client) UploadFile(ctx context.Context, filename string, content []byte) (*File, error) {
+// SetRetryConfig sets how failed requests are retried
+func (c *Client) SetRetryConfig(retryConfig utils.RetryConfig) {
+	c.retryConfig = retryConfig
+}
+
+// SetProcessingConfig sets how long to wait for uploaded files to be processed and how often
+// to check their status at first. Zero values keep the current setting.
+func (c *Client) SetProcessingConfig(timeout, pollInterval time.Duration) {
+	if timeout > 0 {
+		c.processingTimeout = timeout
+	}
+	if pollInterval > 0 {
+		c.processingPollInterval = pollInterval
+	}
+}
+
+// SetHTTPConfig sets the timeout of a single request and how the server certificate is verified.
+// A zero timeout keeps the current one. caCertPath names a PEM file whose certificates are
+// trusted in addition to the system roots. Without TLS settings the default transport is kept.
+func (c *Client) SetHTTPConfig(timeout time.Duration, insecureSkipVerify bool, caCertPath string) error {
+	if timeout > 0 {
+		c.client.Timeout = timeout
+	}
+	if !insecureSkipVerify && caCertPath == "" {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if insecureSkipVerify {
+		logrus.Warn("TLS certificate verification for OpenWebUI is disabled")
+	}
+	if caCertPath != "" {
+		pemData, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return fmt.Errorf("no PEM certificates found in %s", caCertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Clone the default transport to keep its proxy settings and connection pooling
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.client.Transport = transport
+	return nil
+}
+
+// SetHeaders sets the User-Agent and extra headers sent with every request
+func (c *Client) SetHeaders(userAgent string, headers map[string]string) {
+	c.userAgent = userAgent
+	c.headers = headers
+}
+
+// doWithRetry sends the request built by newRequest, retrying network errors and 429/5xx
+// responses with exponential backoff. A fresh request is built for every attempt so request
+// bodies can be re-sent. Any other response is returned for the caller to handle.
+func (c *Client) doWithRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	var resp *http.Response
+	err := utils.RetryWithBackoff(ctx, c.retryConfig, func() error {
+		req, err := newRequest()
+		if err != nil {
+			return utils.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		utils.SetRequestHeaders(req, c.userAgent, c.headers)
+
+		r, err := c.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return utils.Permanent(fmt.Errorf("failed to send request: %w", err))
+			}
+			return utils.Retryable(fmt.Errorf("failed to send request: %w", err))
+		}
+
+		if r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= http.StatusInternalServerError {
+			body, _ := io.ReadAll(r.Body)
+			r.Body.Close()
+			logrus.Debugf("%s %s returned retryable status %d", req.Method, req.URL.Path, r.StatusCode)
+			return utils.Retryable(fmt.Errorf("request failed with status %d: %s", r.StatusCode, string(body)))
+		}
+
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// newJSONRequest creates a request with a JSON body
+func newJSONRequest(ctx context.Context, method, url string, jsonData []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// quoteEscaper escapes a multipart filename like mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// contentTypes maps extensions of the text formats adapters produce to their MIME types,
+// so they don't depend on the host's MIME database
+var contentTypes = map[string]string{
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".txt":      "text/plain",
+	".json":     "application/json",
+	".html":     "text/html",
+	".htm":      "text/html",
+	".csv":      "text/csv",
+	".tsv":      "text/tab-separated-values",
+	".yaml":     "application/yaml",
+	".yml":      "application/yaml",
+}
+
+// ContentTypeForFilename returns the MIME type for a filename based on its extension,
+// falling back to application/octet-stream for unknown extensions
+func ContentTypeForFilename(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if contentType, ok := contentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// UploadFile uploads a file to OpenWebUI. An empty contentType is detected from the filename extension.
+func (c *Client) UploadFile(ctx context.Context, filename, contentType string, content []byte) (*File, error) {
 	url := fmt.Sprintf("%s/api/v1/files/", c.baseURL)
 
-	logrus.Debugf("Uploading file to OpenWebUI: %s (size: %d bytes)", filename, len(content))
+	if contentType == "" {
+		contentType = ContentTypeForFilename(filename)
+	}
+
+	logrus.Debugf("Uploading file to OpenWebUI: %s (size: %d bytes, type: %s)", filename, len(content), contentType)
 	logrus.Debugf("Upload URL: %s", url)
 
 	// Create multipart form
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
-	// Add file field
-	fileWriter, err := writer.CreateFormFile("file", filename)
+	// Add file field; unlike CreateFormFile, this sends the real content type instead of application/octet-stream
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	partHeader.Set("Content-Type", contentType)
+	fileWriter, err := writer.CreatePart(partHeader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
@@ -92,15 +272,7 @@ func (c *Client) UploadFile(ctx context.Context, filename string, content []byte
 
 	writer.Close()
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", url, &buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 		logrus.Debugf("Using API key for authentication")
 	} else {
 		logrus.Debugf("No API key provided")
@@ -109,35 +281,28 @@ func (c *Client) UploadFile(ctx context.Context, filename string, content []byte
 	// Send request with retry logic
 	logrus.Debugf("Sending file upload request...")
 
-	var resp *http.Response
-	retryConfig := utils.DefaultRetryConfig()
-	retryConfig.BaseDelay = 2 * time.Second
-	retryConfig.MaxDelay = 30 * time.Second
-
-	err = utils.RetryWithBackoff(ctx, retryConfig, func() error {
-		var err error
-		resp, err = c.client.Do(req)
+	payload := buf.Bytes()
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		logrus.Debugf("File upload response status: %d %s", resp.StatusCode, resp.Status)
-
-		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-			body, _ := io.ReadAll(resp.Body)
-			logrus.Errorf("File upload failed with status %d: %s", resp.StatusCode, string(body))
-			resp.Body.Close()
-			return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
-		}
-
-		return nil
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req, nil
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file after retries: %w", err)
 	}
 	defer resp.Body.Close()
 
+	logrus.Debugf("File upload response status: %d %s", resp.StatusCode, resp.Status)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		logrus.Errorf("File upload failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
 	// Read response body for debugging
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -167,61 +332,130 @@ func (c *Client) UploadFile(ctx context.Context, filename string, content []byte
 	return &file, nil
 }
 
-// ListKnowledge retrieves all knowledge sources
+// ListKnowledge lists all knowledge sources, following pagination until every page has been read
 func (c *Client) ListKnowledge(ctx context.Context) ([]*Knowledge, error) {
-	url := fmt.Sprintf("%s/api/v1/knowledge/", c.baseURL)
-
 	logrus.Debugf("Listing all knowledge sources")
-	logrus.Debugf("List knowledge URL: %s", url)
-	logrus.Debugf("Base URL: %s", c.baseURL)
-	logrus.Debugf("Context: %+v", ctx)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		logrus.Errorf("Failed to create HTTP request for list knowledge: %v", err)
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var allKnowledge []*Knowledge
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		knowledge, err := c.listKnowledgePage(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, k := range knowledge {
+			if seen[k.ID] {
+				continue
+			}
+			seen[k.ID] = true
+			allKnowledge = append(allKnowledge, k)
+			added++
+		}
+
+		// A short page is the last one. Servers that ignore the page parameter return the
+		// same items again, which adds nothing new and must not loop forever.
+		if len(knowledge) < c.pageSize || added == 0 {
+			break
+		}
 	}
 
-	// Log request details
-	logrus.Debugf("Request method: %s", req.Method)
-	logrus.Debugf("Request URL: %s", req.URL.String())
-	logrus.Debugf("Request headers: %+v", req.Header)
+	logrus.Debugf("Listed %d knowledge sources", len(allKnowledge))
+	return allKnowledge, nil
+}
+
+// knowledgePage is the paginated response shape of newer OpenWebUI versions
+type knowledgePage struct {
+	Items []*Knowledge `json:"items"`
+	Total int          `json:"total"`
+}
+
+// listKnowledgePage fetches a single page of knowledge sources
+func (c *Client) listKnowledgePage(ctx context.Context, page int) ([]*Knowledge, error) {
+	url := fmt.Sprintf("%s/api/v1/knowledge/?page=%d&limit=%d", c.baseURL, page, c.pageSize)
+
+	logrus.Debugf("List knowledge URL: %s", url)
 
 	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 		logrus.Debugf("Using API key for list knowledge request (length: %d)", len(c.apiKey))
 	} else {
 		logrus.Debugf("No API key provided for list knowledge request")
 	}
 
-	// Log final request headers after API key is set
-	logrus.Debugf("Final request headers: %+v", req.Header)
-
-	logrus.Debugf("Sending list knowledge request...")
-	resp, err := c.client.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", url, nil)
+	})
 	if err != nil {
 		logrus.Errorf("HTTP request failed for list knowledge: %v", err)
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("list knowledge failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	logrus.Debugf("List knowledge response status: %d %s", resp.StatusCode, resp.Status)
-	logrus.Debugf("Response headers: %+v", resp.Header)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logrus.Errorf("List knowledge request failed with status %d: %s", resp.StatusCode, string(body))
-		logrus.Errorf("Request URL was: %s", req.URL.String())
-		logrus.Errorf("Request headers were: %+v", req.Header)
+		logrus.Errorf("Request URL was: %s", url)
 		return nil, fmt.Errorf("list knowledge failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Older versions return a plain array, newer ones wrap the page in an object
 	var knowledge []*Knowledge
+	if err := json.Unmarshal(body, &knowledge); err == nil {
+		return knowledge, nil
+	}
+
+	var paged knowledgePage
+	if err := json.Unmarshal(body, &paged); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return paged.Items, nil
+}
+
+// CreateKnowledge creates a knowledge source with the given name and description
+func (c *Client) CreateKnowledge(ctx context.Context, name, description string) (*Knowledge, error) {
+	url := fmt.Sprintf("%s/api/v1/knowledge/create", c.baseURL)
+
+	logrus.Debugf("Creating knowledge: name=%s", name)
+
+	jsonData, err := json.Marshal(map[string]string{
+		"name":        name,
+		"description": description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return newJSONRequest(ctx, "POST", url, jsonData)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create knowledge failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("create knowledge failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var knowledge Knowledge
 	if err := json.NewDecoder(resp.Body).Decode(&knowledge); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	if knowledge.ID == "" {
+		return nil, fmt.Errorf("create knowledge response has no ID")
+	}
 
-	return knowledge, nil
+	logrus.Infof("Created knowledge %s (%s)", knowledge.Name, knowledge.ID)
+	return &knowledge, nil
 }
 
 // AddFileToKnowledge adds a file to a knowledge source
@@ -242,23 +476,18 @@ func (c *Client) AddFileToKnowledge(ctx context.Context, knowledgeID, fileID str
 
 	// logrus.Debugf("Add file payload: %s", string(jsonData))
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
 	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 		logrus.Debugf("Using API key for add file request")
 	} else {
 		logrus.Debugf("No API key provided for add file request")
 	}
 
 	logrus.Debugf("Sending add file to knowledge request...")
-	resp, err := c.client.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return newJSONRequest(ctx, "POST", url, jsonData)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("add file to knowledge failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -283,56 +512,30 @@ func (c *Client) AddFileToKnowledge(ctx context.Context, knowledgeID, fileID str
 }
 
 // waitForFileProcessing waits for a file to finish processing with adaptive polling
-// Uses exponential backoff to handle both quick and slow file ingestion
+// Polls often at first to handle quick files, then backs off for slow file ingestion
 func (c *Client) waitForFileProcessing(ctx context.Context, fileID string) error {
-	// Polling strategy:
-	// - First 5 attempts: 2s interval (handles quick files, 0-10s)
-	// - Next 5 attempts: 5s interval (handles medium files, 10-35s)
-	// - Next 10 attempts: 10s interval (handles slow files, 35-135s)
-	// - Next 15 attempts: 15s interval (handles very slow files, 135-360s)
-	// - Final 16 attempts: 20s interval (handles extremely slow files, 360-680s = 11.3 minutes)
-	// Total: ~51 attempts over ~11 minutes
-
-	pollIntervals := []struct {
-		attempts int
-		delay    time.Duration
-	}{
-		{attempts: 5, delay: 2 * time.Second},   // 0-10s
-		{attempts: 5, delay: 5 * time.Second},   // 10-35s
-		{attempts: 10, delay: 10 * time.Second}, // 35-135s
-		{attempts: 15, delay: 15 * time.Second}, // 135-360s
-		{attempts: 16, delay: 20 * time.Second}, // 360-680s (~11 minutes)
-	}
-
 	startTime := time.Now()
-	attempt := 0
-	totalAttempts := 0
-	for _, interval := range pollIntervals {
-		totalAttempts += interval.attempts
-	}
-
-	for _, interval := range pollIntervals {
-		for i := 0; i < interval.attempts; i++ {
-			attempt++
-			elapsed := time.Since(startTime)
-
-			// Check if context is cancelled
-			select {
-			case <-ctx.Done():
-				return fmt.Errorf("context cancelled while waiting for file processing after %v: %w", elapsed.Round(time.Second), ctx.Err())
-			default:
-			}
+	deadline := startTime.Add(c.processingTimeout)
 
-			// Get file status
-			file, err := c.GetFile(ctx, fileID)
-			if err != nil {
-				logrus.Debugf("After %v: Failed to get file status: %v", elapsed.Round(time.Second), err)
-				time.Sleep(interval.delay)
-				continue
-			}
+	for {
+		elapsed := time.Since(startTime)
 
+		// Check if context is cancelled
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while waiting for file processing after %v: %w", elapsed.Round(time.Second), ctx.Err())
+		default:
+		}
+
+		delay := c.processingPollDelay(elapsed)
+
+		// Get file status
+		file, err := c.GetFile(ctx, fileID)
+		if err != nil {
+			logrus.Debugf("After %v: Failed to get file status: %v", elapsed.Round(time.Second), err)
+		} else {
 			logrus.Debugf("After %v: File %s status: %s (checking every %v)",
-				elapsed.Round(time.Second), fileID, file.Data.Status, interval.delay)
+				elapsed.Round(time.Second), fileID, file.Data.Status, delay)
 
 			// Check if file processing is complete
 			if file.Data.Status == "processed" || file.Data.Status == "completed" || file.Data.Status == "" {
@@ -344,19 +547,25 @@ func (c *Client) waitForFileProcessing(ctx context.Context, fileID string) error
 			if file.Data.Status == "error" || file.Data.Status == "failed" {
 				return fmt.Errorf("file processing failed with status: %s after %v", file.Data.Status, elapsed.Round(time.Second))
 			}
+		}
 
-			// Wait before next attempt
-			if attempt < totalAttempts {
-				logrus.Debugf("File still processing, waiting %v before retry...", interval.delay)
-
-				// Use context-aware sleep to allow cancellation
-				select {
-				case <-ctx.Done():
-					return fmt.Errorf("context cancelled during wait after %v: %w", elapsed.Round(time.Second), ctx.Err())
-				case <-time.After(interval.delay):
-					// Continue to next attempt
-				}
-			}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+
+		// Wait before next attempt
+		logrus.Debugf("File still processing, waiting %v before retry...", delay)
+
+		// Use context-aware sleep to allow cancellation
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled during wait after %v: %w", elapsed.Round(time.Second), ctx.Err())
+		case <-time.After(delay):
+			// Continue to next attempt
 		}
 	}
 
@@ -364,30 +573,99 @@ func (c *Client) waitForFileProcessing(ctx context.Context, fileID string) error
 	return fmt.Errorf("file processing timeout after %v", elapsed.Round(time.Second))
 }
 
+// processingPollDelay returns how long to wait before the next status check. The poll
+// interval grows from 1x to 10x the configured interval as elapsed time approaches the
+// processing timeout. With the defaults (2s over ~11 minutes) this gives:
+// - 2s until 10s (quick files)
+// - 5s until 35s (medium files)
+// - 10s until 135s (slow files)
+// - 15s until 360s (very slow files)
+// - 20s until the timeout (extremely slow files)
+func (c *Client) processingPollDelay(elapsed time.Duration) time.Duration {
+	steps := []struct {
+		until  float64 // fraction of the processing timeout
+		factor float64 // multiple of the poll interval
+	}{
+		{until: 10.0 / 680, factor: 1},
+		{until: 35.0 / 680, factor: 2.5},
+		{until: 135.0 / 680, factor: 5},
+		{until: 360.0 / 680, factor: 7.5},
+	}
+
+	progress := float64(elapsed) / float64(c.processingTimeout)
+	factor := 10.0
+	for _, step := range steps {
+		if progress < step.until {
+			factor = step.factor
+			break
+		}
+	}
+	return time.Duration(float64(c.processingPollInterval) * factor)
+}
+
 // GetFile retrieves a file by ID
 func (c *Client) GetFile(ctx context.Context, fileID string) (*File, error) {
 	url := fmt.Sprintf("%s/api/v1/files/%s", c.baseURL, fileID)
 
 	logrus.Debugf("Getting file: %s", fileID)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", url, nil)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("get file failed: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("get file %s: %w", fileID, ErrFileNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("get file failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(body, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &file, nil
+}
+
+// UpdateFileContent replaces the content of an existing file in place, keeping its file ID
+// and knowledge memberships. OpenWebUI re-processes the file as part of the request.
+func (c *Client) UpdateFileContent(ctx context.Context, fileID, filename string, content []byte) (*File, error) {
+	url := fmt.Sprintf("%s/api/v1/files/%s/data/content/update", c.baseURL, fileID)
+
+	logrus.Debugf("Updating content of file %s (ID: %s)", filename, fileID)
+
+	payload := map[string]string{
+		"content": string(content),
 	}
 
-	resp, err := c.client.Do(req)
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return newJSONRequest(ctx, "POST", url, jsonData)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update file content failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("get file failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("update file content failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -400,6 +678,7 @@ func (c *Client) GetFile(ctx context.Context, fileID string) (*File, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	logrus.Debugf("Successfully updated content of file %s (ID: %s)", filename, fileID)
 	return &file, nil
 }
 
@@ -416,19 +695,11 @@ func (c *Client) RemoveFileFromKnowledge(ctx context.Context, knowledgeID, fileI
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return newJSONRequest(ctx, "POST", url, jsonData)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("remove file from knowledge failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -450,20 +721,12 @@ func (c *Client) RemoveFileFromKnowledge(ctx context.Context, knowledgeID, fileI
 func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
 	url := fmt.Sprintf("%s/api/v1/files/%s", c.baseURL, fileID)
 
-	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
 	logrus.Debugf("Deleting file from OpenWebUI: fileID=%s", fileID)
 	logrus.Debugf("Delete URL: %s", url)
-	logrus.Debugf("Using API key for delete request")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -471,7 +734,13 @@ func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
 
 	logrus.Debugf("File delete response status: %d %s", resp.StatusCode, resp.Status)
 
-	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+	// Consider 404 as success - file was already deleted
+	if resp.StatusCode == http.StatusNotFound {
+		logrus.Debugf("File %s not found in OpenWebUI (already deleted or doesn't exist)", fileID)
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
 		logrus.Debugf("File delete response body: %s", string(body))
 		return fmt.Errorf("file delete failed with status %d: %s", resp.StatusCode, string(body))
@@ -481,72 +750,16 @@ func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
 	return nil
 }
 
-// GetKnowledgeFiles retrieves files from a specific knowledge source
+// GetKnowledgeFiles retrieves files from a specific knowledge source.
+// An error is returned if no knowledge source with the given ID exists.
 func (c *Client) GetKnowledgeFiles(ctx context.Context, knowledgeID string) ([]*File, error) {
-	url := fmt.Sprintf("%s/api/v1/knowledge/", c.baseURL)
-
 	logrus.Debugf("Getting files from knowledge source: %s", knowledgeID)
-	logrus.Debugf("Knowledge files URL: %s", url)
-	logrus.Debugf("Base URL: %s", c.baseURL)
-	logrus.Debugf("Context: %+v", ctx)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		logrus.Errorf("Failed to create HTTP request: %v", err)
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Log request details
-	logrus.Debugf("Request method: %s", req.Method)
-	logrus.Debugf("Request URL: %s", req.URL.String())
-	logrus.Debugf("Request headers: %+v", req.Header)
-
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
-		logrus.Debugf("Using API key for knowledge files request (length: %d)", len(c.apiKey))
-	} else {
-		logrus.Debugf("No API key provided for knowledge files request")
-	}
-
-	// Log final request headers after API key is set
-	logrus.Debugf("Final request headers: %+v", req.Header)
-
-	logrus.Debugf("Sending knowledge files request...")
-	resp, err := c.client.Do(req)
-	if err != nil {
-		logrus.Errorf("HTTP request failed: %v", err)
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	logrus.Debugf("Knowledge files response status: %d %s", resp.StatusCode, resp.Status)
-	logrus.Debugf("Response headers: %+v", resp.Header)
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		logrus.Errorf("Knowledge files request failed with status %d: %s", resp.StatusCode, string(body))
-		logrus.Errorf("Request URL was: %s", req.URL.String())
-		logrus.Errorf("Request headers were: %+v", req.Header)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
-	}
 
-	body, err := io.ReadAll(resp.Body)
+	knowledgeList, err := c.ListKnowledge(ctx)
 	if err != nil {
-		logrus.Errorf("Failed to read response body: %v", err)
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	//logrus.Debugf("Knowledge files response body: %s", string(body))
-	logrus.Debugf("Response body length: %d bytes", len(body))
-
-	var knowledgeList []*Knowledge
-	if err := json.Unmarshal(body, &knowledgeList); err != nil {
-		logrus.Errorf("Failed to decode knowledge list response: %v", err)
-		//logrus.Errorf("Response body was: %s", string(body))
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
-	//logrus.Debugf("Successfully decoded %d knowledge sources", len(knowledgeList))
 	for i, knowledge := range knowledgeList {
 		logrus.Debugf("Knowledge[%d]: ID=%s, Name=%s, Files count=%d", i, knowledge.ID, knowledge.Name, len(knowledge.Files))
 	}
@@ -554,7 +767,6 @@ func (c *Client) GetKnowledgeFiles(ctx context.Context, knowledgeID string) ([]*
 	// Find the specific knowledge source
 	var targetKnowledge *Knowledge
 	for _, knowledge := range knowledgeList {
-		logrus.Debugf("Checking knowledge ID: %s (looking for: %s)", knowledge.ID, knowledgeID)
 		if knowledge.ID == knowledgeID {
 			targetKnowledge = knowledge
 			logrus.Debugf("Found matching knowledge source: %s", knowledgeID)
@@ -563,7 +775,6 @@ func (c *Client) GetKnowledgeFiles(ctx context.Context, knowledgeID string) ([]*
 	}
 
 	if targetKnowledge == nil {
-		//logrus.Warnf("Knowledge source %s not found in %d available sources", knowledgeID, len(knowledgeList))
 		logrus.Debugf("Available knowledge source IDs: %v", func() []string {
 			ids := make([]string, len(knowledgeList))
 			for i, k := range knowledgeList {
@@ -571,7 +782,7 @@ func (c *Client) GetKnowledgeFiles(ctx context.Context, knowledgeID string) ([]*
 			}
 			return ids
 		}())
-		return []*File{}, nil
+		return nil, fmt.Errorf("knowledge source %s not found among %d knowledge sources", knowledgeID, len(knowledgeList))
 	}
 
 	logrus.Debugf("Successfully retrieved %d files from knowledge source %s", len(targetKnowledge.Files), knowledgeID)
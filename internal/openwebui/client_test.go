@@ -3,13 +3,33 @@ package openwebui
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/openwebui-content-sync/internal/utils"
 )
 
+// newTestClient creates a client that retries quickly so failure cases don't slow tests down
+func newTestClient(baseURL string) *Client {
+	client := NewClient(baseURL, "test-api-key")
+	client.SetRetryConfig(utils.RetryConfig{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Millisecond,
+		Multiplier: 2,
+	})
+	return client
+}
+
 func TestNewClient(t *testing.T) {
 	client := NewClient("http://localhost:8080", "test-api-key")
 	if client == nil {
@@ -131,10 +151,10 @@ func TestClient_UploadFile(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient(server.URL, "test-api-key")
+			client := newTestClient(server.URL)
 			ctx := context.Background()
 
-			result, err := client.UploadFile(ctx, tt.filename, tt.content)
+			result, err := client.UploadFile(ctx, tt.filename, "", tt.content)
 
 			if tt.expectError {
 				if err == nil {
@@ -158,6 +178,113 @@ func TestClient_UploadFile(t *testing.T) {
 	}
 }
 
+func TestClient_UploadFile_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		expected    string
+	}{
+		{"markdown detected from extension", "PROJ-1.md", "", "text/markdown"},
+		{"plain text detected from extension", "page.txt", "", "text/plain"},
+		{"csv detected from extension", "data.csv", "", "text/csv"},
+		{"tsv detected from extension", "data.tsv", "", "text/tab-separated-values"},
+		{"html without charset parameter", "index.html", "", "text/html"},
+		{"htm treated as html", "legacy.htm", "", "text/html"},
+		{"json detected from extension", "config.json", "", "application/json"},
+		{"yaml detected from extension", "values.yml", "", "application/yaml"},
+		{"extension is case insensitive", "README.MD", "", "text/markdown"},
+		{"MIME database fallback", "manual.pdf", "", "application/pdf"},
+		{"unknown extension", "data.unknownext", "", "application/octet-stream"},
+		{"explicit content type wins", "report", "application/pdf", "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var partHeader map[string][]string
+			var partFilename string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					file, header, err := r.FormFile("file")
+					if err != nil {
+						t.Errorf("Failed to read multipart file: %v", err)
+					} else {
+						file.Close()
+						partHeader = header.Header
+						partFilename = header.Filename
+					}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"id":   "file-123",
+					"data": map[string]interface{}{"status": "processed"},
+				})
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			if _, err := client.UploadFile(context.Background(), tt.filename, tt.contentType, []byte("content")); err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+
+			if got := partHeader["Content-Type"]; len(got) != 1 || got[0] != tt.expected {
+				t.Errorf("Expected multipart Content-Type %s, got %v", tt.expected, got)
+			}
+			if partFilename != tt.filename {
+				t.Errorf("Expected multipart filename %s, got %s", tt.filename, partFilename)
+			}
+		})
+	}
+}
+
+func TestClient_waitForFileProcessing_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The file never finishes processing
+		file := File{ID: "file-123", Filename: "test.md"}
+		file.Data.Status = "pending"
+		json.NewEncoder(w).Encode(file)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.SetProcessingConfig(100*time.Millisecond, 5*time.Millisecond)
+
+	start := time.Now()
+	err := client.waitForFileProcessing(context.Background(), "file-123")
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("Expected a processing timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the timeout to be reached promptly, took %v", elapsed)
+	}
+}
+
+func TestClient_processingPollDelay(t *testing.T) {
+	client := NewClient("http://localhost:8080", "test-api-key")
+
+	// The defaults keep the original polling schedule
+	tests := []struct {
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{elapsed: 0, expected: 2 * time.Second},
+		{elapsed: 20 * time.Second, expected: 5 * time.Second},
+		{elapsed: 100 * time.Second, expected: 10 * time.Second},
+		{elapsed: 200 * time.Second, expected: 15 * time.Second},
+		{elapsed: 500 * time.Second, expected: 20 * time.Second},
+	}
+	for _, tt := range tests {
+		if delay := client.processingPollDelay(tt.elapsed); delay != tt.expected {
+			t.Errorf("processingPollDelay(%v) = %v, expected %v", tt.elapsed, delay, tt.expected)
+		}
+	}
+
+	// A shorter timeout scales the schedule down
+	client.SetProcessingConfig(68*time.Second, 200*time.Millisecond)
+	if delay := client.processingPollDelay(50 * time.Second); delay != 2*time.Second {
+		t.Errorf("Expected scaled delay of 2s near the timeout, got %v", delay)
+	}
+}
+
 func TestClient_ListKnowledge(t *testing.T) {
 	expectedKnowledge := []*Knowledge{
 		{
@@ -189,7 +316,7 @@ func TestClient_ListKnowledge(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient(server.URL, "test-api-key")
+	client := newTestClient(server.URL)
 	ctx := context.Background()
 
 	result, err := client.ListKnowledge(ctx)
@@ -206,6 +333,74 @@ func TestClient_ListKnowledge(t *testing.T) {
 	}
 }
 
+func TestClient_CreateKnowledge(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverStatus int
+		response     string
+		expectError  bool
+	}{
+		{
+			name:         "successful create",
+			serverStatus: http.StatusOK,
+			response:     `{"id": "knowledge-new", "name": "Engineering Docs"}`,
+		},
+		{
+			name:         "response without ID",
+			serverStatus: http.StatusOK,
+			response:     `{"detail": "ok"}`,
+			expectError:  true,
+		},
+		{
+			name:         "server error",
+			serverStatus: http.StatusBadRequest,
+			response:     `{"detail": "invalid name"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" {
+					t.Errorf("Expected POST method, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/v1/knowledge/create" {
+					t.Errorf("Expected path /api/v1/knowledge/create, got %s", r.URL.Path)
+				}
+
+				var requestBody map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+				if requestBody["name"] != "Engineering Docs" || requestBody["description"] != "Synced docs" {
+					t.Errorf("Unexpected request body %v", requestBody)
+				}
+
+				w.WriteHeader(tt.serverStatus)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			knowledge, err := client.CreateKnowledge(context.Background(), "Engineering Docs", "Synced docs")
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if knowledge.ID != "knowledge-new" {
+				t.Errorf("Expected ID knowledge-new, got %s", knowledge.ID)
+			}
+		})
+	}
+}
+
 func TestClient_AddFileToKnowledge(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -257,7 +452,7 @@ func TestClient_AddFileToKnowledge(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient(server.URL, "test-api-key")
+			client := newTestClient(server.URL)
 			ctx := context.Background()
 
 			err := client.AddFileToKnowledge(ctx, tt.knowledgeID, tt.fileID)
@@ -327,7 +522,7 @@ func TestClient_RemoveFileFromKnowledge(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := NewClient(server.URL, "test-api-key")
+			client := newTestClient(server.URL)
 			ctx := context.Background()
 
 			err := client.RemoveFileFromKnowledge(ctx, tt.knowledgeID, tt.fileID)
@@ -345,3 +540,492 @@ func TestClient_RemoveFileFromKnowledge(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverStatus int
+		expectedErr  error // nil for success
+		expectError  bool
+	}{
+		{
+			name:         "existing file",
+			serverStatus: http.StatusOK,
+		},
+		{
+			name:         "missing file",
+			serverStatus: http.StatusNotFound,
+			expectedErr:  ErrFileNotFound,
+			expectError:  true,
+		},
+		{
+			name:         "server error",
+			serverStatus: http.StatusInternalServerError,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/files/file-123" {
+					t.Errorf("Expected path /api/v1/files/file-123, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.serverStatus)
+				fmt.Fprint(w, `{"id":"file-123","filename":"doc.md","data":{"status":"processed"}}`)
+			}))
+			defer server.Close()
+
+			file, err := newTestClient(server.URL).GetFile(context.Background(), "file-123")
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				if tt.expectedErr == nil && errors.Is(err, ErrFileNotFound) {
+					t.Errorf("Expected a server error not to be reported as a missing file, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetFile() error = %v", err)
+			}
+			if file.ID != "file-123" || file.Filename != "doc.md" {
+				t.Errorf("Unexpected file %+v", file)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileID       string
+		serverStatus int
+		expectError  bool
+	}{
+		{
+			name:         "successful delete",
+			fileID:       "file-123",
+			serverStatus: http.StatusOK,
+			expectError:  false,
+		},
+		{
+			name:         "file already deleted",
+			fileID:       "file-123",
+			serverStatus: http.StatusNotFound,
+			expectError:  false,
+		},
+		{
+			name:         "server error",
+			fileID:       "file-123",
+			serverStatus: http.StatusInternalServerError,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "DELETE" {
+					t.Errorf("Expected DELETE method, got %s", r.Method)
+				}
+				expectedPath := "/api/v1/files/" + tt.fileID
+				if r.URL.Path != expectedPath {
+					t.Errorf("Expected path %s, got %s", expectedPath, r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer test-api-key" {
+					t.Errorf("Expected Authorization header, got %s", r.Header.Get("Authorization"))
+				}
+
+				w.WriteHeader(tt.serverStatus)
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			ctx := context.Background()
+
+			err := client.DeleteFile(ctx, tt.fileID)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateFileContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverStatus int
+		expectError  bool
+	}{
+		{
+			name:         "successful update",
+			serverStatus: http.StatusOK,
+			expectError:  false,
+		},
+		{
+			name:         "file not found",
+			serverStatus: http.StatusNotFound,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" {
+					t.Errorf("Expected POST method, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/v1/files/file-123/data/content/update" {
+					t.Errorf("Expected update content path, got %s", r.URL.Path)
+				}
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Expected JSON content type, got %s", r.Header.Get("Content-Type"))
+				}
+
+				var payload map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+				if payload["content"] != "# Updated" {
+					t.Errorf("Expected content '# Updated', got %q", payload["content"])
+				}
+
+				w.WriteHeader(tt.serverStatus)
+				if tt.serverStatus == http.StatusOK {
+					json.NewEncoder(w).Encode(File{ID: "file-123", Filename: "test.md"})
+				}
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			file, err := client.UpdateFileContent(context.Background(), "file-123", "test.md", []byte("# Updated"))
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if file.ID != "file-123" {
+				t.Errorf("Expected file ID 'file-123', got '%s'", file.ID)
+			}
+		})
+	}
+}
+
+// newPagedKnowledgeServer serves knowledge sources in pages of pageSize, either as a plain
+// array or wrapped in an items object like newer OpenWebUI versions
+func newPagedKnowledgeServer(t *testing.T, knowledge []*Knowledge, pageSize int, wrapped bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			t.Errorf("Expected a page query parameter, got %q", r.URL.RawQuery)
+			page = 1
+		}
+		if r.URL.Query().Get("limit") != strconv.Itoa(pageSize) {
+			t.Errorf("Expected limit=%d, got %q", pageSize, r.URL.Query().Get("limit"))
+		}
+
+		start := (page - 1) * pageSize
+		end := start + pageSize
+		if start > len(knowledge) {
+			start = len(knowledge)
+		}
+		if end > len(knowledge) {
+			end = len(knowledge)
+		}
+
+		if wrapped {
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": knowledge[start:end], "total": len(knowledge)})
+			return
+		}
+		json.NewEncoder(w).Encode(knowledge[start:end])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_ListKnowledge_Pagination(t *testing.T) {
+	knowledge := []*Knowledge{
+		{ID: "knowledge-1"},
+		{ID: "knowledge-2"},
+		{ID: "knowledge-3"},
+	}
+
+	for _, wrapped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("wrapped=%v", wrapped), func(t *testing.T) {
+			server := newPagedKnowledgeServer(t, knowledge, 2, wrapped)
+			client := newTestClient(server.URL)
+			client.pageSize = 2
+
+			result, err := client.ListKnowledge(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(result) != 3 {
+				t.Fatalf("Expected 3 knowledge items across both pages, got %d", len(result))
+			}
+			for i, k := range result {
+				if k.ID != knowledge[i].ID {
+					t.Errorf("Expected knowledge[%d] ID %s, got %s", i, knowledge[i].ID, k.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_ListKnowledge_IgnoredPagination(t *testing.T) {
+	// Servers without pagination return every knowledge source for every page
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode([]*Knowledge{{ID: "knowledge-1"}, {ID: "knowledge-2"}})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.pageSize = 2
+
+	result, err := client.ListKnowledge(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("Expected 2 knowledge items, got %d", len(result))
+	}
+	if requests != 2 {
+		t.Errorf("Expected pagination to stop after a repeated page, got %d requests", requests)
+	}
+}
+
+func TestClient_GetKnowledgeFiles_Pagination(t *testing.T) {
+	knowledge := []*Knowledge{
+		{ID: "knowledge-1"},
+		{ID: "knowledge-2"},
+		{ID: "knowledge-3", Files: []*File{{ID: "file-1"}}},
+	}
+	server := newPagedKnowledgeServer(t, knowledge, 2, true)
+	client := newTestClient(server.URL)
+	client.pageSize = 2
+
+	files, err := client.GetKnowledgeFiles(context.Background(), "knowledge-3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].ID != "file-1" {
+		t.Errorf("Expected file-1 from the second page, got %+v", files)
+	}
+
+	if _, err := client.GetKnowledgeFiles(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for a missing knowledge source")
+	}
+}
+
+// flakyServer fails the first failures requests with status, then responds with ok
+func flakyServer(t *testing.T, failures, status int, ok http.HandlerFunc) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		ok(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   http.HandlerFunc
+		call func(client *Client) error
+	}{
+		{
+			name: "UploadFile",
+			ok: func(w http.ResponseWriter, r *http.Request) {
+				// The multipart body must be re-sent on every attempt
+				if _, _, err := r.FormFile("file"); err != nil {
+					t.Errorf("Expected file in retried upload: %v", err)
+				}
+				json.NewEncoder(w).Encode(File{ID: "file-1"})
+			},
+			call: func(client *Client) error {
+				_, err := client.UploadFile(context.Background(), "doc.md", "", []byte("# Doc"))
+				return err
+			},
+		},
+		{
+			name: "ListKnowledge",
+			ok: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode([]*Knowledge{{ID: "knowledge-1"}})
+			},
+			call: func(client *Client) error {
+				_, err := client.ListKnowledge(context.Background())
+				return err
+			},
+		},
+		{
+			name: "AddFileToKnowledge",
+			ok: func(w http.ResponseWriter, r *http.Request) {
+				var payload map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["file_id"] != "file-1" {
+					t.Errorf("Expected file_id in retried request body, got %v (%v)", payload, err)
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			call: func(client *Client) error {
+				return client.AddFileToKnowledge(context.Background(), "knowledge-1", "file-1")
+			},
+		},
+		{
+			name: "DeleteFile",
+			ok: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			call: func(client *Client) error {
+				return client.DeleteFile(context.Background(), "file-1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, 2, http.StatusServiceUnavailable, tt.ok)
+			if err := tt.call(newTestClient(server.URL)); err != nil {
+				t.Fatalf("Expected success after retries, got %v", err)
+			}
+			if *requests != 3 {
+				t.Errorf("Expected 3 requests (2 failures + success), got %d", *requests)
+			}
+		})
+	}
+}
+
+func TestClient_Retry_ClientErrorsNotRetried(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server, requests := flakyServer(t, 1, status, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			if err := newTestClient(server.URL).AddFileToKnowledge(context.Background(), "knowledge-1", "file-1"); err == nil {
+				t.Fatal("Expected error for client error response")
+			}
+			if *requests != 1 {
+				t.Errorf("Expected client errors not to be retried, got %d requests", *requests)
+			}
+		})
+	}
+}
+
+func TestClient_Retry_GivesUp(t *testing.T) {
+	server, requests := flakyServer(t, 10, http.StatusBadGateway, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if err := newTestClient(server.URL).DeleteFile(context.Background(), "file-1"); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if *requests != 3 {
+		t.Errorf("Expected 3 attempts with MaxRetries 2, got %d", *requests)
+	}
+}
+
+func TestClient_SetHTTPConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Knowledge{{ID: "knowledge-1"}})
+	}))
+	defer server.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA certificate: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		insecureSkipVerify bool
+		caCertPath         string
+		expectConfigErr    bool
+		expectRequestErr   bool
+	}{
+		{name: "default transport rejects unknown CA", expectRequestErr: true},
+		{name: "custom CA", caCertPath: caPath},
+		{name: "skip verification", insecureSkipVerify: true},
+		{name: "missing CA file", caCertPath: filepath.Join(t.TempDir(), "missing.pem"), expectConfigErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(server.URL, "test-api-key")
+			client.SetRetryConfig(utils.RetryConfig{})
+
+			err := client.SetHTTPConfig(30*time.Second, tt.insecureSkipVerify, tt.caCertPath)
+			if tt.expectConfigErr {
+				if err == nil {
+					t.Fatal("Expected an error for the CA certificate")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetHTTPConfig() error = %v", err)
+			}
+			if client.client.Timeout != 30*time.Second {
+				t.Errorf("Expected request timeout of 30s, got %v", client.client.Timeout)
+			}
+
+			_, err = client.ListKnowledge(context.Background())
+			if tt.expectRequestErr && err == nil {
+				t.Error("Expected the request to fail certificate verification")
+			}
+			if !tt.expectRequestErr && err != nil {
+				t.Errorf("Expected the request to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_SetHeaders(t *testing.T) {
+	var captured http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+		json.NewEncoder(w).Encode([]Knowledge{{ID: "knowledge-1"}})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.SetHeaders("OpenWebUI-Content-Sync/test", map[string]string{"X-Team": "docs"})
+
+	if _, err := client.ListKnowledge(context.Background()); err != nil {
+		t.Fatalf("ListKnowledge() error = %v", err)
+	}
+	if got := captured.Get("User-Agent"); got != "OpenWebUI-Content-Sync/test" {
+		t.Errorf("Expected the configured User-Agent, got %q", got)
+	}
+	if got := captured.Get("X-Team"); got != "docs" {
+		t.Errorf("Expected the extra header, got %q", got)
+	}
+	if got := captured.Get("Authorization"); got != "Bearer test-api-key" {
+		t.Errorf("Expected the API key to be kept, got %q", got)
+	}
+}
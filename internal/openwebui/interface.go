@@ -6,9 +6,11 @@ import (
 
 // ClientInterface defines the interface for OpenWebUI client operations
 type ClientInterface interface {
-	UploadFile(ctx context.Context, filename string, content []byte) (*File, error)
+	UploadFile(ctx context.Context, filename, contentType string, content []byte) (*File, error)
 	GetFile(ctx context.Context, fileID string) (*File, error)
+	UpdateFileContent(ctx context.Context, fileID, filename string, content []byte) (*File, error)
 	ListKnowledge(ctx context.Context) ([]*Knowledge, error)
+	CreateKnowledge(ctx context.Context, name, description string) (*Knowledge, error)
 	AddFileToKnowledge(ctx context.Context, knowledgeID, fileID string) error
 	RemoveFileFromKnowledge(ctx context.Context, knowledgeID, fileID string) error
 	GetKnowledgeFiles(ctx context.Context, knowledgeID string) ([]*File, error)
@@ -2,10 +2,14 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	gosync "sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
 	"github.com/openwebui-content-sync/internal/sync"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
@@ -13,35 +17,97 @@ import (
 
 // Scheduler manages periodic synchronization
 type Scheduler struct {
-	cron        *cron.Cron
-	interval    time.Duration
-	adapters    []adapter.Adapter
-	syncManager sync.ManagerInterface
+	cron             *cron.Cron
+	interval         time.Duration
+	cronSpec         string
+	schedule         cron.Schedule // parsed cronSpec, nil when running on the interval
+	adapterSchedules map[string]adapterSchedule
+	adapters         []adapter.Adapter
+	syncManager      sync.ManagerInterface
+	timeout          time.Duration // maximum duration of a sync run, 0 for no limit
+
+	mu             gosync.Mutex
+	running        int             // syncs in progress, see Wait
+	idle           *gosync.Cond    // broadcast when running drops to zero
+	adapterSyncing map[string]bool // adapters with their own schedule whose sync is in progress
+	syncing        atomic.Bool     // a sync of the global adapters is in progress
+}
+
+// ErrSyncRunning is returned for a sync that is skipped because the previous one is still running
+var ErrSyncRunning = errors.New("skipped, the previous sync is still running")
+
+// adapterSchedule is a schedule that overrides the global one for a single adapter
+type adapterSchedule struct {
+	spec     string
+	schedule cron.Schedule
 }
 
+// defaultSyncTimeout bounds sync runs unless SetSyncTimeout changes it
+const defaultSyncTimeout = 30 * time.Minute
+
 // New creates a new scheduler
 func New(interval time.Duration, adapters []adapter.Adapter, syncManager sync.ManagerInterface) *Scheduler {
-	return &Scheduler{
-		cron:        cron.New(cron.WithSeconds()),
-		interval:    interval,
-		adapters:    adapters,
-		syncManager: syncManager,
+	s := &Scheduler{
+		cron:             cron.New(cron.WithSeconds()),
+		interval:         interval,
+		adapterSchedules: make(map[string]adapterSchedule),
+		adapters:         adapters,
+		syncManager:      syncManager,
+		timeout:          defaultSyncTimeout,
+		adapterSyncing:   make(map[string]bool),
 	}
+	s.idle = gosync.NewCond(&s.mu)
+	return s
 }
 
-// Start starts the scheduler
-func (s *Scheduler) Start(ctx context.Context) {
-	logrus.Infof("Starting scheduler with interval: %v", s.interval)
+// NewFromConfig creates a new scheduler from the schedule configuration.
+// A non-empty cron expression takes precedence over the interval and is validated here.
+func NewFromConfig(cfg config.ScheduleConfig, adapters []adapter.Adapter, syncManager sync.ManagerInterface) (*Scheduler, error) {
+	s := New(cfg.Interval, adapters, syncManager)
+	if cfg.Cron == "" {
+		return s, nil
+	}
 
-	// Schedule the sync job
-	cronSpec := fmt.Sprintf("@every %v", s.interval)
-	_, err := s.cron.AddFunc(cronSpec, func() {
-		logrus.Info("Running scheduled sync")
-		if err := s.RunSyncWithContext(ctx); err != nil {
-			logrus.Errorf("Scheduled sync failed: %v", err)
-		}
-	})
+	schedule, err := cron.ParseStandard(cfg.Cron)
 	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", cfg.Cron, err)
+	}
+	s.cronSpec = cfg.Cron
+	s.schedule = schedule
+	return s, nil
+}
+
+// SetAdapterSchedule gives the named adapter its own schedule. Adapters without one
+// (or with an empty config) are synced together on the global schedule.
+func (s *Scheduler) SetAdapterSchedule(adapterName string, cfg config.ScheduleConfig) error {
+	if !cfg.IsSet() {
+		return nil
+	}
+
+	if cfg.Cron != "" {
+		schedule, err := cron.ParseStandard(cfg.Cron)
+		if err != nil {
+			return fmt.Errorf("invalid cron expression %q for adapter %s: %w", cfg.Cron, adapterName, err)
+		}
+		s.adapterSchedules[adapterName] = adapterSchedule{spec: cfg.Cron, schedule: schedule}
+		return nil
+	}
+
+	s.adapterSchedules[adapterName] = adapterSchedule{
+		spec:     fmt.Sprintf("@every %v", cfg.Interval),
+		schedule: cron.Every(cfg.Interval),
+	}
+	return nil
+}
+
+// SetSyncTimeout sets the maximum duration of a sync run; 0 lets runs take as long as they need
+func (s *Scheduler) SetSyncTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// Start starts the scheduler
+func (s *Scheduler) Start(ctx context.Context) {
+	if err := s.registerJobs(ctx); err != nil {
 		logrus.Errorf("Failed to schedule sync job: %v", err)
 		return
 	}
@@ -54,11 +120,182 @@ func (s *Scheduler) Start(ctx context.Context) {
 	s.cron.Stop()
 }
 
-// RunSyncWithContext runs a synchronization cycle with the provided context
+// registerJobs adds one cron entry per adapter with its own schedule and a shared
+// entry on the global schedule for the remaining adapters
+func (s *Scheduler) registerJobs(ctx context.Context) error {
+	var shared []adapter.Adapter
+	for _, adpt := range s.adapters {
+		own, ok := s.adapterSchedules[adpt.Name()]
+		if !ok {
+			shared = append(shared, adpt)
+			continue
+		}
+
+		logrus.Infof("Scheduling adapter %s with its own schedule: %s", adpt.Name(), own.spec)
+		s.cron.Schedule(own.schedule, cron.FuncJob(func() {
+			logrus.Infof("Running scheduled sync for adapter: %s", adpt.Name())
+			if err := s.RunAdapterSyncWithContext(ctx, adpt); err != nil && !errors.Is(err, ErrSyncRunning) {
+				logrus.Errorf("Scheduled sync for adapter %s failed: %v", adpt.Name(), err)
+			}
+		}))
+	}
+
+	// Every adapter has its own schedule, so the global entry has nothing to do
+	if len(s.adapters) > 0 && len(shared) == 0 {
+		return nil
+	}
+
+	job := func() {
+		logrus.Info("Running scheduled sync")
+		if err := s.runSync(ctx, shared); err != nil && !errors.Is(err, ErrSyncRunning) {
+			logrus.Errorf("Scheduled sync failed: %v", err)
+		}
+	}
+
+	// Schedule the sync job
+	if s.schedule != nil {
+		logrus.Infof("Starting scheduler with cron expression: %s", s.cronSpec)
+		// The cron instance parses specs with seconds, so use the pre-parsed standard schedule directly
+		s.cron.Schedule(s.schedule, cron.FuncJob(job))
+		return nil
+	}
+
+	logrus.Infof("Starting scheduler with interval: %v", s.interval)
+	cronSpec := fmt.Sprintf("@every %v", s.interval)
+	if _, err := s.cron.AddFunc(cronSpec, job); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NextSync returns when the next scheduled sync runs, or the zero time when nothing is scheduled yet
+func (s *Scheduler) NextSync() time.Time {
+	var next time.Time
+	for _, entry := range s.cron.Entries() {
+		if !entry.Next.IsZero() && (next.IsZero() || entry.Next.Before(next)) {
+			next = entry.Next
+		}
+	}
+	return next
+}
+
+// Wait blocks until no sync is running. Syncs return early once their context is cancelled,
+// so after cancelling the context passed to Start, Wait returns when in-flight syncs have
+// actually stopped.
+func (s *Scheduler) Wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.running > 0 {
+		s.idle.Wait()
+	}
+}
+
+// syncStarted and syncFinished track the syncs in progress for Wait
+func (s *Scheduler) syncStarted() {
+	s.mu.Lock()
+	s.running++
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) syncFinished() {
+	s.mu.Lock()
+	s.running--
+	if s.running == 0 {
+		s.idle.Broadcast()
+	}
+	s.mu.Unlock()
+}
+
+// RunSyncWithContext runs a synchronization cycle of all adapters, unless one is already
+// running, in which case it returns ErrSyncRunning. The sync stops when ctx is cancelled, e.g.
+// on shutdown during the initial sync, or when the sync timeout expires.
 func (s *Scheduler) RunSyncWithContext(ctx context.Context) error {
-	// Create a timeout context, but make it respect the parent context cancellation
-	syncCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	return s.runSync(ctx, s.adapters)
+}
+
+// StartSyncWithContext starts a synchronization cycle of all adapters in the background and
+// returns a channel receiving its result. Unlike RunSyncWithContext, it returns ErrSyncRunning
+// right away when a sync, whether scheduled or started before, is still running.
+func (s *Scheduler) StartSyncWithContext(ctx context.Context) (<-chan error, error) {
+	if !s.syncing.CompareAndSwap(false, true) {
+		return nil, ErrSyncRunning
+	}
+	s.syncStarted()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.finishSync(ctx, s.adapters)
+	}()
+	return done, nil
+}
+
+// RunAdapterSyncWithContext runs a synchronization cycle for a single adapter. Like runSync, it
+// returns ErrSyncRunning instead when the adapter's previous sync is still running.
+func (s *Scheduler) RunAdapterSyncWithContext(ctx context.Context, adpt adapter.Adapter) error {
+	s.mu.Lock()
+	if s.adapterSyncing[adpt.Name()] {
+		s.mu.Unlock()
+		logrus.Warnf("Skipping sync of adapter %s: its previous sync is still running", adpt.Name())
+		return ErrSyncRunning
+	}
+	s.adapterSyncing[adpt.Name()] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.adapterSyncing, adpt.Name())
+		s.mu.Unlock()
+	}()
+
+	s.syncStarted()
+	defer s.syncFinished()
+
+	syncCtx, cancel := s.syncContext(ctx)
 	defer cancel()
 
-	return s.syncManager.SyncFiles(syncCtx, s.adapters)
+	return s.syncManager.SyncAdapter(syncCtx, adpt)
+}
+
+// RunItemSyncWithContext syncs a single item of an adapter, such as a Slack channel named by a
+// webhook event
+func (s *Scheduler) RunItemSyncWithContext(ctx context.Context, adpt adapter.Adapter, id string) error {
+	s.syncStarted()
+	defer s.syncFinished()
+
+	syncCtx, cancel := s.syncContext(ctx)
+	defer cancel()
+
+	return s.syncManager.SyncItem(syncCtx, adpt, id)
+}
+
+// runSync syncs the given adapters with a timeout that respects parent cancellation. A run
+// that starts while the previous one is still in progress, e.g. when a sync takes longer than
+// the interval, is skipped with ErrSyncRunning instead of uploading the same files concurrently.
+func (s *Scheduler) runSync(ctx context.Context, adapters []adapter.Adapter) error {
+	if !s.syncing.CompareAndSwap(false, true) {
+		logrus.Warn("Skipping sync: the previous sync is still running")
+		return ErrSyncRunning
+	}
+	s.syncStarted()
+
+	return s.finishSync(ctx, adapters)
+}
+
+// finishSync runs a sync of adapters started by runSync or StartSyncWithContext, then releases
+// the syncing flag
+func (s *Scheduler) finishSync(ctx context.Context, adapters []adapter.Adapter) error {
+	defer s.syncing.Store(false)
+	defer s.syncFinished()
+
+	syncCtx, cancel := s.syncContext(ctx)
+	defer cancel()
+
+	return s.syncManager.SyncFiles(syncCtx, adapters)
+}
+
+// syncContext derives the context of a sync run from ctx, bounded by the sync timeout
+func (s *Scheduler) syncContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
 }
@@ -2,11 +2,14 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
 	"github.com/openwebui-content-sync/internal/mocks"
 )
 
@@ -17,6 +20,14 @@ func (m *MockSyncManager) SyncFiles(ctx context.Context, adapters []adapter.Adap
 	return nil
 }
 
+func (m *MockSyncManager) SyncAdapter(ctx context.Context, adpt adapter.Adapter) error {
+	return nil
+}
+
+func (m *MockSyncManager) SyncItem(ctx context.Context, adpt adapter.Adapter, id string) error {
+	return nil
+}
+
 func (m *MockSyncManager) SetKnowledgeID(knowledgeID string) {
 	// Mock implementation
 }
@@ -43,6 +54,66 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewFromConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       config.ScheduleConfig
+		wantErr      bool
+		wantSchedule bool
+	}{
+		{
+			name:         "valid cron expression",
+			config:       config.ScheduleConfig{Interval: 1 * time.Hour, Cron: "0 6 * * 1-5"},
+			wantSchedule: true,
+		},
+		{
+			name:    "invalid cron expression",
+			config:  config.ScheduleConfig{Interval: 1 * time.Hour, Cron: "every weekday at 6"},
+			wantErr: true,
+		},
+		{
+			name:         "falls back to interval",
+			config:       config.ScheduleConfig{Interval: 30 * time.Minute},
+			wantSchedule: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, err := NewFromConfig(tt.config, []adapter.Adapter{}, &MockSyncManager{})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if scheduler.interval != tt.config.Interval {
+				t.Errorf("Expected interval %v, got %v", tt.config.Interval, scheduler.interval)
+			}
+			if (scheduler.schedule != nil) != tt.wantSchedule {
+				t.Errorf("Expected cron schedule set: %v, got %v", tt.wantSchedule, scheduler.schedule != nil)
+			}
+		})
+	}
+}
+
+func TestNewFromConfig_CronNextRun(t *testing.T) {
+	scheduler, err := NewFromConfig(config.ScheduleConfig{Cron: "0 6 * * 1-5"}, []adapter.Adapter{}, &MockSyncManager{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Saturday evening -> next run is Monday 06:00
+	saturday := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	expected := time.Date(2024, 6, 3, 6, 0, 0, 0, time.UTC)
+	if next := scheduler.schedule.Next(saturday); !next.Equal(expected) {
+		t.Errorf("Expected next run %v, got %v", expected, next)
+	}
+}
+
 func TestScheduler_RunSync(t *testing.T) {
 	// Create mock sync manager
 	syncManager := &MockSyncManager{}
@@ -63,6 +134,57 @@ func TestScheduler_RunSync(t *testing.T) {
 	}
 }
 
+// deadlineSyncManager records whether the context of a sync run had a deadline
+type deadlineSyncManager struct {
+	MockSyncManager
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (m *deadlineSyncManager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) error {
+	m.deadline, m.hasDeadline = ctx.Deadline()
+	return nil
+}
+
+func TestScheduler_SyncTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		timeout         *time.Duration // nil keeps the default
+		expectDeadline  bool
+		expectRemaining time.Duration
+	}{
+		{name: "default", expectDeadline: true, expectRemaining: defaultSyncTimeout},
+		{name: "configured", timeout: durationPtr(2 * time.Hour), expectDeadline: true, expectRemaining: 2 * time.Hour},
+		{name: "no limit", timeout: durationPtr(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncManager := &deadlineSyncManager{}
+			scheduler := New(time.Hour, []adapter.Adapter{&mocks.MockAdapter{}}, syncManager)
+			if tt.timeout != nil {
+				scheduler.SetSyncTimeout(*tt.timeout)
+			}
+
+			start := time.Now()
+			if err := scheduler.RunSyncWithContext(context.Background()); err != nil {
+				t.Fatalf("RunSyncWithContext failed: %v", err)
+			}
+
+			if syncManager.hasDeadline != tt.expectDeadline {
+				t.Fatalf("Expected deadline = %v, got %v", tt.expectDeadline, syncManager.hasDeadline)
+			}
+			if remaining := syncManager.deadline.Sub(start); tt.expectDeadline && (remaining < tt.expectRemaining || remaining > tt.expectRemaining+time.Minute) {
+				t.Errorf("Expected a deadline %v after the start, got %v", tt.expectRemaining, remaining)
+			}
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
 func TestScheduler_Start(t *testing.T) {
 	// Create mock sync manager
 	syncManager := &MockSyncManager{}
@@ -90,6 +212,182 @@ func TestScheduler_Start(t *testing.T) {
 	wg.Wait()
 }
 
+// slowSyncManager blocks every sync until its context is cancelled
+type slowSyncManager struct {
+	MockSyncManager
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (m *slowSyncManager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) error {
+	close(m.started)
+	defer close(m.stopped)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+		return nil
+	}
+}
+
+func TestScheduler_Wait(t *testing.T) {
+	syncManager := &slowSyncManager{started: make(chan struct{}), stopped: make(chan struct{})}
+	scheduler := New(time.Hour, []adapter.Adapter{&mocks.MockAdapter{}}, syncManager)
+
+	// Without a running sync, Wait returns immediately
+	scheduler.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.RunSyncWithContext(ctx)
+	<-syncManager.started
+
+	waited := make(chan struct{})
+	go func() {
+		scheduler.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while the sync was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the sync was cancelled")
+	}
+	select {
+	case <-syncManager.stopped:
+	default:
+		t.Error("Wait returned before the sync did")
+	}
+}
+
+func TestScheduler_RunSyncWithContext_Cancel(t *testing.T) {
+	syncManager := &slowSyncManager{started: make(chan struct{}), stopped: make(chan struct{})}
+	scheduler := New(time.Hour, []adapter.Adapter{&mocks.MockAdapter{}}, syncManager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- scheduler.RunSyncWithContext(ctx)
+	}()
+	<-syncManager.started
+
+	cancel()
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the sync to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunSyncWithContext did not return after its context was cancelled")
+	}
+}
+
+// countingSyncManager counts syncs and blocks each until release is closed
+type countingSyncManager struct {
+	MockSyncManager
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *countingSyncManager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) error {
+	m.calls.Add(1)
+	m.started <- struct{}{}
+	<-m.release
+	return nil
+}
+
+func (m *countingSyncManager) SyncAdapter(ctx context.Context, adpt adapter.Adapter) error {
+	return m.SyncFiles(ctx, []adapter.Adapter{adpt})
+}
+
+func TestScheduler_SkipsOverlappingRun(t *testing.T) {
+	slack := &mocks.MockAdapter{NameFunc: func() string { return "slack" }}
+	tests := []struct {
+		name string
+		run  func(s *Scheduler) error
+	}{
+		{"all adapters", func(s *Scheduler) error { return s.RunSyncWithContext(context.Background()) }},
+		{"all adapters in the background", func(s *Scheduler) error {
+			done, err := s.StartSyncWithContext(context.Background())
+			if err != nil {
+				return err
+			}
+			return <-done
+		}},
+		{"adapter with its own schedule", func(s *Scheduler) error { return s.RunAdapterSyncWithContext(context.Background(), slack) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncManager := &countingSyncManager{started: make(chan struct{}, 1), release: make(chan struct{})}
+			scheduler := New(time.Hour, []adapter.Adapter{slack}, syncManager)
+
+			first := make(chan error, 1)
+			go func() {
+				first <- tt.run(scheduler)
+			}()
+			<-syncManager.started
+
+			second := make(chan error, 1)
+			go func() {
+				second <- tt.run(scheduler)
+			}()
+			select {
+			case err := <-second:
+				if !errors.Is(err, ErrSyncRunning) {
+					t.Errorf("Expected the overlapping run to be skipped with ErrSyncRunning, got %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("The overlapping run waited for the first one")
+			}
+			if calls := syncManager.calls.Load(); calls != 1 {
+				t.Errorf("Expected the sync manager to be called once, got %d", calls)
+			}
+
+			close(syncManager.release)
+			if err := <-first; err != nil {
+				t.Fatalf("First run failed: %v", err)
+			}
+
+			// Once the first run finished, the next one syncs again
+			if err := tt.run(scheduler); err != nil {
+				t.Fatalf("Next run failed: %v", err)
+			}
+			if calls := syncManager.calls.Load(); calls != 2 {
+				t.Errorf("Expected the next run to call the sync manager, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestScheduler_NextSync(t *testing.T) {
+	scheduler := New(time.Hour, []adapter.Adapter{&mocks.MockAdapter{}}, &MockSyncManager{})
+	if next := scheduler.NextSync(); !next.IsZero() {
+		t.Errorf("Expected no next sync before the scheduler starts, got %v", next)
+	}
+
+	if err := scheduler.registerJobs(context.Background()); err != nil {
+		t.Fatalf("Failed to register jobs: %v", err)
+	}
+	scheduler.cron.Start()
+	defer scheduler.cron.Stop()
+
+	next := scheduler.NextSync()
+	if next.IsZero() || next.Before(time.Now().Add(59*time.Minute)) || next.After(time.Now().Add(61*time.Minute)) {
+		t.Errorf("Expected next sync in about an hour, got %v", next)
+	}
+}
+
 func TestScheduler_Interval(t *testing.T) {
 	interval := 2 * time.Hour
 	scheduler := New(interval, []adapter.Adapter{}, &MockSyncManager{})
@@ -116,3 +414,61 @@ func TestScheduler_Adapters(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduler_PerAdapterSchedules(t *testing.T) {
+	slackAdapter := &mocks.MockAdapter{NameFunc: func() string { return "slack" }}
+	localAdapter := &mocks.MockAdapter{NameFunc: func() string { return "local" }}
+
+	scheduler := New(1*time.Hour, []adapter.Adapter{slackAdapter, localAdapter}, &MockSyncManager{})
+	if err := scheduler.SetAdapterSchedule("slack", config.ScheduleConfig{Interval: 6 * time.Hour}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := scheduler.SetAdapterSchedule("local", config.ScheduleConfig{Interval: 1 * time.Minute}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := scheduler.registerJobs(context.Background()); err != nil {
+		t.Fatalf("Failed to register jobs: %v", err)
+	}
+
+	entries := scheduler.cron.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 cron entries (one per adapter, no global entry), got %d", len(entries))
+	}
+
+	now := time.Now()
+	first := entries[0].Schedule.Next(now).Sub(now)
+	second := entries[1].Schedule.Next(now).Sub(now)
+	if first == second {
+		t.Errorf("Expected distinct schedules, both run in %v", first)
+	}
+}
+
+func TestScheduler_PerAdapterSchedules_FallbackToGlobal(t *testing.T) {
+	slackAdapter := &mocks.MockAdapter{NameFunc: func() string { return "slack" }}
+	githubAdapter := &mocks.MockAdapter{NameFunc: func() string { return "github" }}
+
+	scheduler := New(1*time.Hour, []adapter.Adapter{slackAdapter, githubAdapter}, &MockSyncManager{})
+	if err := scheduler.SetAdapterSchedule("slack", config.ScheduleConfig{Cron: "0 */6 * * *"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// An empty schedule keeps the adapter on the global schedule
+	if err := scheduler.SetAdapterSchedule("github", config.ScheduleConfig{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := scheduler.registerJobs(context.Background()); err != nil {
+		t.Fatalf("Failed to register jobs: %v", err)
+	}
+
+	if entries := scheduler.cron.Entries(); len(entries) != 2 {
+		t.Errorf("Expected 2 cron entries (slack + global), got %d", len(entries))
+	}
+}
+
+func TestScheduler_SetAdapterSchedule_InvalidCron(t *testing.T) {
+	scheduler := New(1*time.Hour, []adapter.Adapter{}, &MockSyncManager{})
+	if err := scheduler.SetAdapterSchedule("slack", config.ScheduleConfig{Cron: "not a cron"}); err == nil {
+		t.Error("Expected error for invalid cron expression but got none")
+	}
+}
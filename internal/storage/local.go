@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openwebui-content-sync/internal/utils"
+)
+
+// writeFileAtomic writes local files; tests replace it to simulate interrupted writes
+var writeFileAtomic = utils.WriteFileAtomic
+
+// Local stores each key as a file below a root directory
+type Local struct {
+	root string
+}
+
+// NewLocal creates a storage below root, creating the directory if needed
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &Local{root: root}, nil
+}
+
+// path returns the file path of a key
+func (l *Local) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(key))
+}
+
+// Read returns the content of a key's file
+func (l *Local) Read(key string) ([]byte, error) {
+	return os.ReadFile(l.path(key))
+}
+
+// Write replaces a key's file through a temporary file, creating its directory if needed
+func (l *Local) Write(key string, data []byte) error {
+	filePath := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return writeFileAtomic(filePath, data, 0644)
+}
+
+// List walks the directory holding prefix and returns the keys of the files starting with it.
+// Temporary files left behind by interrupted writes are skipped.
+func (l *Local) List(prefix string) ([]string, error) {
+	dir := prefix
+	if !strings.HasSuffix(prefix, "/") {
+		dir = path.Dir(prefix)
+	}
+
+	var keys []string
+	err := filepath.WalkDir(l.path(dir), func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || (strings.HasPrefix(d.Name(), ".") && strings.Contains(d.Name(), ".tmp-")) {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, filePath)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes a key's file
+func (l *Local) Delete(key string) error {
+	if err := os.Remove(l.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
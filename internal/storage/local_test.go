@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocal_Write_PartialWrite(t *testing.T) {
+	tempDir := t.TempDir()
+	store, err := NewLocal(tempDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	if err := store.Write("file_index.json", []byte(`{"file.md":"old-hash"}`)); err != nil {
+		t.Fatalf("Failed to write initial index: %v", err)
+	}
+
+	// Simulate a crash halfway through the next write
+	original := writeFileAtomic
+	writeFileAtomic = func(path string, data []byte, perm os.FileMode) error {
+		partial := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-crash")
+		if err := os.WriteFile(partial, data[:len(data)/2], perm); err != nil {
+			t.Fatalf("Failed to write partial file: %v", err)
+		}
+		return fmt.Errorf("simulated crash")
+	}
+	defer func() { writeFileAtomic = original }()
+
+	if err := store.Write("file_index.json", []byte(`{"file.md":"new-hash","other.md":"other-hash"}`)); err == nil {
+		t.Fatal("Expected Write to fail")
+	}
+	if data, err := store.Read("file_index.json"); err != nil || string(data) != `{"file.md":"old-hash"}` {
+		t.Errorf("Expected the previous index to be intact, got %q (err: %v)", data, err)
+	}
+	if keys, err := store.List(""); err != nil || strings.Join(keys, ",") != "file_index.json" {
+		t.Errorf("Expected List to skip the partial file, got %v (err: %v)", keys, err)
+	}
+
+	// A successful write replaces the index and leaves no temporary files of its own behind
+	writeFileAtomic = original
+	if err := store.Write("file_index.json", []byte(`{"file.md":"new-hash","other.md":"other-hash"}`)); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read storage dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected only the index and the simulated partial file, got %d entries", len(entries))
+	}
+	if data, err := store.Read("file_index.json"); err != nil || !strings.Contains(string(data), "other.md") {
+		t.Errorf("Expected the new index, got %q (err: %v)", data, err)
+	}
+}
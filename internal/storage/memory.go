@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory keeps the stored data in memory, for tests and dry runs that must not persist anything
+type Memory struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemory creates an empty in-memory storage
+func NewMemory() *Memory {
+	return &Memory{data: make(map[string][]byte)}
+}
+
+// Read returns a copy of the data stored under key
+func (m *Memory) Read(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotExist)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Write stores a copy of data under key
+func (m *Memory) Write(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = append([]byte{}, data...)
+	return nil
+}
+
+// List returns the stored keys starting with prefix
+func (m *Memory) List(prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes key
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+const (
+	// defaultS3Region is the signing region when storage.s3.region is unset
+	defaultS3Region = "us-east-1"
+	// s3RequestTimeout bounds a single S3 request
+	s3RequestTimeout = time.Minute
+	// s3TimeFormat is the timestamp format of AWS Signature Version 4
+	s3TimeFormat = "20060102T150405Z"
+)
+
+// S3 stores each key as an object in an S3-compatible bucket, such as AWS S3, MinIO or
+// Cloudflare R2. Requests are signed with AWS Signature Version 4.
+type S3 struct {
+	client          *http.Client
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	prefix          string
+	accessKeyID     string
+	secretAccessKey string
+	pathStyle       bool
+	now             func() time.Time
+}
+
+// NewS3 creates a storage in the configured bucket. Without an endpoint, AWS S3 in the
+// configured region is used.
+func NewS3(cfg config.S3StorageConfig) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key ID and secret access key are required")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(rawEndpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", rawEndpoint)
+	}
+
+	return &S3{
+		client:          &http.Client{Timeout: s3RequestTimeout},
+		endpoint:        endpoint,
+		region:          region,
+		bucket:          cfg.Bucket,
+		prefix:          strings.Trim(cfg.Prefix, "/"),
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		pathStyle:       cfg.PathStyle,
+		now:             time.Now,
+	}, nil
+}
+
+// Read downloads a key's object
+func (s *S3) Read(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, joinKey(s.prefix, key), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, "get", key)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Write uploads data as a key's object. S3 replaces objects atomically.
+func (s *S3) Write(key string, data []byte) error {
+	resp, err := s.do(http.MethodPut, joinKey(s.prefix, key), nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "put", key)
+	}
+	return nil
+}
+
+// s3ListResult is the part of a ListObjectsV2 response the storage uses
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the keys of the objects starting with prefix, following the pages of ListObjectsV2
+func (s *S3) List(prefix string) ([]string, error) {
+	keyPrefix := ""
+	if s.prefix != "" {
+		keyPrefix = s.prefix + "/"
+	}
+
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {keyPrefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp, "list", prefix)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 listing of %s: %w", prefix, err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, strings.TrimPrefix(object.Key, keyPrefix))
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes a key's object. S3 reports success for objects that don't exist.
+func (s *S3) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, joinKey(s.prefix, key), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp, "delete", key)
+	}
+	return nil
+}
+
+// do sends a signed request for an object, or for the bucket when objectKey is empty
+func (s *S3) do(method, objectKey string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := s.newRequest(method, objectKey, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// newRequest creates a signed request for an object, or for the bucket when objectKey is empty
+func (s *S3) newRequest(method, objectKey string, query url.Values, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	objectPath := "/" + objectKey
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, u.RawPath, body)
+	return req, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to a request. canonicalPath is the escaped
+// path the request is sent to.
+func (s *S3) sign(req *http.Request, canonicalPath string, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature))
+}
+
+// s3Error describes a failed S3 request, including the start of S3's error document
+func s3Error(resp *http.Response, operation, key string) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("failed to %s %s in S3: status %d: %s", operation, key, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// s3EscapePath escapes each segment of a path as S3 signatures expect: everything but
+// letters, digits and -_.~ is percent-encoded
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name, as S3 signatures expect
+func s3CanonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, s3Escape(name)+"="+s3Escape(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// s3Escape percent-encodes everything but unreserved characters
+func s3Escape(s string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(s) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || strings.IndexByte("-_.~", b) >= 0 {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of data
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
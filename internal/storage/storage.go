@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// ErrNotExist is returned, possibly wrapped, by Read for keys that aren't stored
+var ErrNotExist = fs.ErrNotExist
+
+// Storage persists the sync state, such as the file index, the synced file contents and the
+// Slack message history. Keys are slash-separated paths like "slack/channels/C123/messages.json".
+type Storage interface {
+	// Read returns the data stored under key, or an error wrapping ErrNotExist if there is none
+	Read(key string) ([]byte, error)
+
+	// Write stores data under key, replacing its previous data. Readers see either the old or
+	// the new data, never a partial write.
+	Write(key string, data []byte) error
+
+	// List returns the keys starting with prefix, sorted
+	List(prefix string) ([]string, error)
+
+	// Delete removes key. Deleting a key that isn't stored is not an error.
+	Delete(key string) error
+}
+
+// Backends selected by storage.backend
+const (
+	BackendLocal = "local" // files below storage.path (default)
+	BackendS3    = "s3"    // objects in an S3-compatible bucket
+)
+
+// New creates the storage backend selected by the configuration
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocal(cfg.Path)
+	case BackendS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// joinKey joins key parts with slashes, ignoring empty parts and surplus slashes
+func joinKey(parts ...string) string {
+	var trimmed []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			trimmed = append(trimmed, part)
+		}
+	}
+	return strings.Join(trimmed, "/")
+}
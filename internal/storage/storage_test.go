@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+func TestStorage(t *testing.T) {
+	backends := []struct {
+		name string
+		new  func(t *testing.T) Storage
+	}{
+		{name: "memory", new: func(t *testing.T) Storage { return NewMemory() }},
+		{name: "local", new: func(t *testing.T) Storage {
+			store, err := NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocal() error = %v", err)
+			}
+			return store
+		}},
+		{name: "s3", new: func(t *testing.T) Storage { return newTestS3(t, NewMemory(), "deployment") }},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			store := backend.new(t)
+
+			if _, err := store.Read("file_index.json"); !errors.Is(err, ErrNotExist) {
+				t.Errorf("Read() of a missing key: expected ErrNotExist, got %v", err)
+			}
+
+			for key, data := range map[string]string{
+				"file_index.json":                    "{}",
+				"slack/channels/C1/messages.json":    "[1]",
+				"slack/channels/C1/cursor.json":      "{}",
+				"slack/channels/C2/messages.json":    "[2]",
+				"slack/channels/C2/files/F1 (1).txt": "notes",
+			} {
+				if err := store.Write(key, []byte(data)); err != nil {
+					t.Fatalf("Write(%s) error = %v", key, err)
+				}
+			}
+			if err := store.Write("slack/channels/C1/messages.json", []byte("[1,3]")); err != nil {
+				t.Fatalf("Write() of an existing key error = %v", err)
+			}
+
+			data, err := store.Read("slack/channels/C1/messages.json")
+			if err != nil || string(data) != "[1,3]" {
+				t.Errorf("Read() = %q, %v, want the replaced data", data, err)
+			}
+			if data, err := store.Read("slack/channels/C2/files/F1 (1).txt"); err != nil || string(data) != "notes" {
+				t.Errorf("Read() of a key with special characters = %q, %v", data, err)
+			}
+
+			keys, err := store.List("slack/channels/")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			expected := "slack/channels/C1/cursor.json,slack/channels/C1/messages.json,slack/channels/C2/files/F1 (1).txt,slack/channels/C2/messages.json"
+			if strings.Join(keys, ",") != expected {
+				t.Errorf("List() = %v, want %s", keys, expected)
+			}
+			if keys, err := store.List("slack/channels/C1/m"); err != nil || strings.Join(keys, ",") != "slack/channels/C1/messages.json" {
+				t.Errorf("List() of a partial name = %v, %v", keys, err)
+			}
+			if keys, err := store.List("snapshots/"); err != nil || len(keys) != 0 {
+				t.Errorf("List() of a missing prefix = %v, %v, want no keys", keys, err)
+			}
+
+			if err := store.Delete("slack/channels/C1/cursor.json"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := store.Read("slack/channels/C1/cursor.json"); !errors.Is(err, ErrNotExist) {
+				t.Errorf("Read() of a deleted key: expected ErrNotExist, got %v", err)
+			}
+			if err := store.Delete("slack/channels/C1/cursor.json"); err != nil {
+				t.Errorf("Delete() of a missing key error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.StorageConfig
+		wantErr string
+	}{
+		{name: "local by default", cfg: config.StorageConfig{Path: t.TempDir()}},
+		{name: "s3", cfg: config.StorageConfig{Backend: "s3", S3: config.S3StorageConfig{Bucket: "sync", AccessKeyID: "key", SecretAccessKey: "secret"}}},
+		{name: "s3 without bucket", cfg: config.StorageConfig{Backend: "s3", S3: config.S3StorageConfig{AccessKeyID: "key", SecretAccessKey: "secret"}}, wantErr: "bucket is required"},
+		{name: "unknown backend", cfg: config.StorageConfig{Backend: "ftp"}, wantErr: `unknown storage backend "ftp"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("New() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
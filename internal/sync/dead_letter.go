@@ -0,0 +1,231 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// failedSyncsFile is the storage key of the dead-letter store
+const failedSyncsFile = "failed_syncs.json"
+
+// A file that failed once is retried on the next run; after further failures it waits
+// deadLetterBaseDelay, doubling with each failure up to deadLetterMaxDelay
+const (
+	deadLetterBaseDelay = 15 * time.Minute
+	deadLetterMaxDelay  = 24 * time.Hour
+)
+
+// FailedSync is a file that failed to sync after all retries
+type FailedSync struct {
+	Path      string    `json:"path"`
+	Source    string    `json:"source"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`  // most recent failure
+	Attempts  int       `json:"attempts"`   // runs the file failed in since it last synced
+	NextRetry time.Time `json:"next_retry"` // runs before this time skip the file
+}
+
+// DeadLetterStore persists the files that failed to sync, keyed by file index key, so they are
+// retried first on the next runs, with a backoff, and can be reconciled by hand
+type DeadLetterStore struct {
+	store   storage.Storage
+	mu      sync.Mutex
+	entries map[string]*FailedSync
+	dirty   bool
+}
+
+// NewDeadLetterStore creates a store backed by failed_syncs.json in store and loads it.
+// A missing or corrupt file results in an empty store and a warning logged to log.
+func NewDeadLetterStore(store storage.Storage, log logging.Logger) *DeadLetterStore {
+	deadLetters := &DeadLetterStore{
+		store:   store,
+		entries: make(map[string]*FailedSync),
+	}
+	if err := deadLetters.load(); err != nil {
+		log.Warnf("Failed to load failed syncs, starting without them: %v", err)
+		deadLetters.entries = make(map[string]*FailedSync)
+	}
+	return deadLetters
+}
+
+// Get returns the failed sync stored for a file index key
+func (s *DeadLetterStore) Get(key string) (FailedSync, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return FailedSync{}, false
+	}
+	return *entry, true
+}
+
+// Count returns the number of files that failed to sync
+func (s *DeadLetterStore) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Due reports whether a file of source that failed to sync is due for its retry at now
+func (s *DeadLetterStore) Due(source string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries {
+		if entry.Source == source && !now.Before(entry.NextRetry) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record stores a failure of a file at now and schedules its next retry. Save writes the store.
+func (s *DeadLetterStore) Record(key, path, source string, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &FailedSync{}
+		s.entries[key] = entry
+	}
+	entry.Path = path
+	entry.Source = source
+	entry.Error = err.Error()
+	entry.FailedAt = now
+	entry.Attempts++
+	entry.NextRetry = now.Add(deadLetterDelay(entry.Attempts))
+	s.dirty = true
+}
+
+// Resolve forgets a file that synced. Save writes the store.
+func (s *DeadLetterStore) Resolve(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.dirty = true
+	}
+}
+
+// Prune forgets the files of a source that aren't in keys, e.g. because they were removed
+// from the source. Save writes the store.
+func (s *DeadLetterStore) Prune(source string, keys map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range s.entries {
+		if entry.Source == source && !keys[key] {
+			delete(s.entries, key)
+			s.dirty = true
+		}
+	}
+}
+
+// Save writes the store to the storage if it changed since it was loaded or last saved
+func (s *DeadLetterStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal failed syncs: %w", err)
+	}
+	if err := s.store.Write(failedSyncsFile, data); err != nil {
+		return fmt.Errorf("failed to write failed syncs: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// load reads the store from the storage
+func (s *DeadLetterStore) load() error {
+	data, err := s.store.Read(failedSyncsFile)
+	if errors.Is(err, storage.ErrNotExist) {
+		return nil // No failed syncs recorded yet
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read failed syncs: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return fmt.Errorf("failed to unmarshal failed syncs: %w", err)
+	}
+	return nil
+}
+
+// deadLetterDelay returns how long a file that failed in attempts runs waits for its next retry
+func deadLetterDelay(attempts int) time.Duration {
+	if attempts <= 1 {
+		return 0
+	}
+	delay := deadLetterBaseDelay
+	for i := 2; i < attempts && delay < deadLetterMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, deadLetterMaxDelay)
+}
+
+// prioritizeFailed returns the files of an adapter with the ones that failed to sync before first
+func (m *Manager) prioritizeFailed(source string, files []*adapter.File) []*adapter.File {
+	if m.deadLetters == nil || m.deadLetters.Count() == 0 {
+		return files
+	}
+	prioritized := append([]*adapter.File(nil), files...)
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		_, failedI := m.deadLetters.Get(m.fileIndexKey(source, prioritized[i]))
+		_, failedJ := m.deadLetters.Get(m.fileIndexKey(source, prioritized[j]))
+		return failedI && !failedJ
+	})
+	return prioritized
+}
+
+// backingOff reports whether a file that failed to sync before waits for its next retry at now
+func (m *Manager) backingOff(key string, now time.Time) bool {
+	if m.deadLetters == nil {
+		return false
+	}
+	entry, ok := m.deadLetters.Get(key)
+	if ok && now.Before(entry.NextRetry) {
+		m.log().Debugf("File %s failed to sync %d times, retrying after %s", entry.Path, entry.Attempts, entry.NextRetry.Format(time.RFC3339))
+		return true
+	}
+	return false
+}
+
+// recordSyncResult adds a file that failed to sync to the dead-letter store, or removes it once
+// it synced. Failures caused by the run being cancelled or timing out aren't recorded.
+func (m *Manager) recordSyncResult(ctx context.Context, key string, file *adapter.File, source string, err error) {
+	if m.deadLetters == nil || m.DryRun {
+		return
+	}
+	if err == nil {
+		m.deadLetters.Resolve(key)
+	} else if ctx.Err() == nil {
+		m.deadLetters.Record(key, file.Path, source, err, time.Now())
+	}
+}
+
+// saveFailedSyncs writes the dead-letter store
+func (m *Manager) saveFailedSyncs() {
+	if m.deadLetters == nil {
+		return
+	}
+	if err := m.deadLetters.Save(); err != nil {
+		m.log().Warnf("Failed to save failed syncs: %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestDeadLetterStore_RoundTrip(t *testing.T) {
+	backend := storage.NewMemory()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	store := NewDeadLetterStore(backend, logging.Default())
+	store.Record("github/docs/a.md", "docs/a.md", "github", errors.New("upload rejected"), now)
+	store.Record("jira/PROJ-1.json", "PROJ-1.json", "jira", errors.New("timeout"), now)
+	store.Record("jira/PROJ-1.json", "PROJ-1.json", "jira", errors.New("still failing"), now.Add(time.Hour))
+	store.Record("slack/general.md", "general.md", "slack", errors.New("rejected"), now)
+	store.Resolve("slack/general.md")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded := NewDeadLetterStore(backend, logging.Default())
+	if count := reloaded.Count(); count != 2 {
+		t.Errorf("Expected 2 failed syncs after reload, got %d", count)
+	}
+	tests := []struct {
+		key      string
+		expected FailedSync
+	}{
+		{"github/docs/a.md", FailedSync{Path: "docs/a.md", Source: "github", Error: "upload rejected", FailedAt: now, Attempts: 1, NextRetry: now}},
+		{"jira/PROJ-1.json", FailedSync{Path: "PROJ-1.json", Source: "jira", Error: "still failing", FailedAt: now.Add(time.Hour), Attempts: 2, NextRetry: now.Add(time.Hour + deadLetterBaseDelay)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := reloaded.Get(tt.key)
+			if !ok {
+				t.Fatalf("Expected a failed sync for %s after reload", tt.key)
+			}
+			if got.Path != tt.expected.Path || got.Source != tt.expected.Source || got.Error != tt.expected.Error || got.Attempts != tt.expected.Attempts ||
+				!got.FailedAt.Equal(tt.expected.FailedAt) || !got.NextRetry.Equal(tt.expected.NextRetry) {
+				t.Errorf("Get(%q) = %+v, want %+v", tt.key, got, tt.expected)
+			}
+		})
+	}
+	if _, ok := reloaded.Get("slack/general.md"); ok {
+		t.Error("Expected a resolved file not to be stored")
+	}
+
+	reloaded.Prune("jira", map[string]bool{})
+	if _, ok := reloaded.Get("jira/PROJ-1.json"); ok {
+		t.Error("Expected pruned files of the source to be forgotten")
+	}
+	if _, ok := reloaded.Get("github/docs/a.md"); !ok {
+		t.Error("Expected files of other sources to be kept when pruning")
+	}
+}
+
+func TestDeadLetterStore_MissingOrCorruptFile(t *testing.T) {
+	if count := NewDeadLetterStore(storage.NewMemory(), logging.Default()).Count(); count != 0 {
+		t.Errorf("Expected empty store when the file does not exist, got %d entries", count)
+	}
+
+	backend := storage.NewMemory()
+	if err := backend.Write(failedSyncsFile, []byte("{not json")); err != nil {
+		t.Fatalf("Failed to write corrupt file: %v", err)
+	}
+	logger := mocks.NewMockLogger()
+	corrupt := NewDeadLetterStore(backend, logger)
+	if count := corrupt.Count(); count != 0 {
+		t.Errorf("Expected empty store when the file is corrupt, got %d entries", count)
+	}
+	if entries := logger.Entries(); len(entries) != 1 || entries[0].Level != "warn" {
+		t.Errorf("Expected a warning logged to the given logger, got %+v", entries)
+	}
+	// Nothing is written until a file fails or syncs
+	if err := corrupt.Save(); err != nil {
+		t.Errorf("Save() error = %v", err)
+	}
+	if data, _ := backend.Read(failedSyncsFile); string(data) != "{not json" {
+		t.Errorf("Expected an unchanged store not to be written, got %s", data)
+	}
+}
+
+func TestDeadLetterDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 0},
+		{2, deadLetterBaseDelay},
+		{3, 2 * deadLetterBaseDelay},
+		{5, 8 * deadLetterBaseDelay},
+		{20, deadLetterMaxDelay},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d attempts", tt.attempts), func(t *testing.T) {
+			if delay := deadLetterDelay(tt.attempts); delay != tt.expected {
+				t.Errorf("deadLetterDelay(%d) = %v, want %v", tt.attempts, delay, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_DeadLetters(t *testing.T) {
+	failing := true
+	var uploads []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads = append(uploads, filename)
+			if filename == "broken.md" && failing {
+				return nil, errors.New("upload rejected")
+			}
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+				{Path: "broken.md", Content: []byte("# Broken"), Hash: "hash-broken", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	backend := storage.NewMemory()
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           backend,
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		deadLetters:     NewDeadLetterStore(backend, logging.Default()),
+	}
+	run := func() {
+		t.Helper()
+		uploads = nil
+		manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter})
+	}
+
+	run()
+	data, err := backend.Read(failedSyncsFile)
+	if err != nil {
+		t.Fatalf("Expected the failed file to be written to %s: %v", failedSyncsFile, err)
+	}
+	var stored map[string]FailedSync
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("Failed to decode %s: %v", data, err)
+	}
+	entry, ok := stored["github/broken.md@knowledge-id"]
+	if len(stored) != 1 || !ok || entry.Source != "github" || entry.Path != "broken.md" || entry.Error == "" || entry.FailedAt.IsZero() {
+		t.Errorf("Expected the failed file in %s, got %s", failedSyncsFile, data)
+	}
+	if failed := manager.Status().FailedFiles; failed != 1 {
+		t.Errorf("Expected 1 failed file in the status, got %d", failed)
+	}
+
+	// The failed file is retried first on the next run and backs off after failing again
+	run()
+	if len(uploads) == 0 || uploads[0] != "broken.md" {
+		t.Errorf("Expected the failed file to be retried first, got uploads %v", uploads)
+	}
+	run()
+	if len(uploads) != 0 {
+		t.Errorf("Expected the failed file to wait for its next retry, got uploads %v", uploads)
+	}
+	if entry, _ := manager.deadLetters.Get("github/broken.md@knowledge-id"); entry.Attempts != 2 {
+		t.Errorf("Expected 2 failed attempts, got %d", entry.Attempts)
+	}
+
+	// Once it syncs, the file is removed from the store
+	failing = false
+	manager.deadLetters.entries["github/broken.md@knowledge-id"].NextRetry = time.Time{}
+	run()
+	if len(uploads) != 1 || uploads[0] != "broken.md" {
+		t.Errorf("Expected the failed file to be uploaded, got uploads %v", uploads)
+	}
+	if failed := NewDeadLetterStore(backend, logging.Default()).Count(); failed != 0 {
+		t.Errorf("Expected the synced file to be removed from %s, got %d entries", failedSyncsFile, failed)
+	}
+	if failed := manager.Status().FailedFiles; failed != 0 {
+		t.Errorf("Expected no failed files in the status, got %d", failed)
+	}
+}
+
+func TestManager_SyncFiles_DeadLettersIncremental(t *testing.T) {
+	var uploads []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads = append(uploads, filename)
+			if filename == "broken.md" {
+				return nil, errors.New("upload rejected")
+			}
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+	files := []*adapter.File{
+		{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+		{Path: "broken.md", Content: []byte("# Broken"), Hash: "hash-broken", KnowledgeID: "knowledge-id"},
+	}
+	incremental := &fullFetchAdapter{
+		MockAdapter: mocks.MockAdapter{NameFunc: func() string { return "jira" }},
+		files:       files,
+		changed:     files,
+	}
+
+	backend := storage.NewMemory()
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           backend,
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		deadLetters:     NewDeadLetterStore(backend, logging.Default()),
+	}
+	run := func() {
+		t.Helper()
+		uploads = nil
+		manager.SyncFiles(context.Background(), []adapter.Adapter{incremental})
+	}
+
+	// The first fetch returns every file, of which broken.md fails
+	run()
+	if incremental.requested != 0 || manager.deadLetters.Count() != 1 {
+		t.Fatalf("Expected broken.md to be recorded without a full fetch, got %d requests and %d failed files", incremental.requested, manager.deadLetters.Count())
+	}
+
+	// The next incremental fetch returns nothing, so the failed file is fetched in full
+	incremental.changed = nil
+	run()
+	if incremental.requested != 1 || len(uploads) != 1 || uploads[0] != "broken.md" {
+		t.Errorf("Expected a full fetch retrying broken.md, got %d requests and uploads %v", incremental.requested, uploads)
+	}
+
+	// While it backs off, incremental fetches stay incremental
+	run()
+	if incremental.requested != 1 || len(uploads) != 0 {
+		t.Errorf("Expected no full fetch while the failed file backs off, got %d requests and uploads %v", incremental.requested, uploads)
+	}
+
+	// A full fetch no longer returning the file forgets it
+	incremental.files = files[:1]
+	manager.deadLetters.entries["jira/broken.md@knowledge-id"].NextRetry = time.Time{}
+	run()
+	if incremental.requested != 2 || manager.deadLetters.Count() != 0 {
+		t.Errorf("Expected the removed file to be pruned after a full fetch, got %d requests and %d failed files", incremental.requested, manager.deadLetters.Count())
+	}
+}
@@ -9,6 +9,8 @@ import (
 // ManagerInterface defines the interface for sync manager operations
 type ManagerInterface interface {
 	SyncFiles(ctx context.Context, adapters []adapter.Adapter) error
+	SyncAdapter(ctx context.Context, adpt adapter.Adapter) error
+	SyncItem(ctx context.Context, adpt adapter.Adapter, id string) error
 	SetKnowledgeID(knowledgeID string)
 	InitializeFileIndex(ctx context.Context, adapters []adapter.Adapter) error
 }
@@ -0,0 +1,178 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/openwebui"
+)
+
+// createdKnowledgeDescription is the description of knowledge bases created for knowledge_name mappings
+const createdKnowledgeDescription = "Created by openwebui-content-sync"
+
+// knowledgeRef points at the knowledge ID of a single mapping so it can be filled in from its name
+type knowledgeRef struct {
+	field string // config path of the mapping, for messages
+	id    *string
+	name  string
+}
+
+// knowledgeRefs returns the mappings of all enabled adapters that name their knowledge base
+// instead of (or in addition to) giving its ID
+func knowledgeRefs(cfg *config.Config) []knowledgeRef {
+	var refs []knowledgeRef
+	add := func(field string, id *string, name string) {
+		if name != "" {
+			refs = append(refs, knowledgeRef{field: field, id: id, name: name})
+		}
+	}
+
+	if cfg.GitHub.Enabled {
+		for i := range cfg.GitHub.Mappings {
+			m := &cfg.GitHub.Mappings[i]
+			add(fmt.Sprintf("github.mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+	}
+	if cfg.Confluence.Enabled {
+		for i := range cfg.Confluence.SpaceMappings {
+			m := &cfg.Confluence.SpaceMappings[i]
+			add(fmt.Sprintf("confluence.space_mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+		for i := range cfg.Confluence.ParentPageMappings {
+			m := &cfg.Confluence.ParentPageMappings[i]
+			add(fmt.Sprintf("confluence.parent_page_mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+		for i := range cfg.Confluence.CQLMappings {
+			m := &cfg.Confluence.CQLMappings[i]
+			add(fmt.Sprintf("confluence.cql_mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+	}
+	if cfg.Jira.Enabled {
+		for i := range cfg.Jira.ProjectMappings {
+			m := &cfg.Jira.ProjectMappings[i]
+			add(fmt.Sprintf("jira.project_mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+	}
+	if cfg.LocalFolders.Enabled {
+		for i := range cfg.LocalFolders.Mappings {
+			m := &cfg.LocalFolders.Mappings[i]
+			add(fmt.Sprintf("local_folders.mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+	}
+	if cfg.Slack.Enabled {
+		for i := range cfg.Slack.ChannelMappings {
+			m := &cfg.Slack.ChannelMappings[i]
+			add(fmt.Sprintf("slack.channel_mappings[%d]", i), &m.KnowledgeID, m.KnowledgeName)
+		}
+		for i := range cfg.Slack.RegexPatterns {
+			p := &cfg.Slack.RegexPatterns[i]
+			add(fmt.Sprintf("slack.regex_patterns[%d]", i), &p.KnowledgeID, p.KnowledgeName)
+		}
+	}
+	return refs
+}
+
+// ResolveKnowledgeNames fills in the knowledge ID of every mapping that only names its
+// knowledge base. Knowledge bases that don't exist are created when
+// openwebui.create_missing_knowledge is set and reported as an error otherwise. It must run
+// before the adapters are created, as they copy their mappings.
+func (m *Manager) ResolveKnowledgeNames(ctx context.Context, cfg *config.Config) error {
+	refs := knowledgeRefs(cfg)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	knowledge, err := m.openwebuiClient.ListKnowledge(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list knowledge bases: %w", err)
+	}
+	idsByName := make(map[string]string, len(knowledge))
+	for _, k := range knowledge {
+		if existing, ok := idsByName[k.Name]; ok {
+			m.log().Warnf("Several knowledge bases are named %q, using %s", k.Name, existing)
+			continue
+		}
+		idsByName[k.Name] = k.ID
+	}
+
+	for _, ref := range refs {
+		if *ref.id != "" {
+			if id, ok := idsByName[ref.name]; ok && id != *ref.id {
+				m.log().Warnf("%s: knowledge_id %s does not match knowledge base %q (%s), using knowledge_id", ref.field, *ref.id, ref.name, id)
+			}
+			continue
+		}
+
+		if id, ok := idsByName[ref.name]; ok {
+			m.log().Debugf("%s: resolved knowledge base %q to %s", ref.field, ref.name, id)
+			*ref.id = id
+			continue
+		}
+
+		if !cfg.OpenWebUI.CreateMissingKnowledge {
+			return fmt.Errorf("%s: knowledge base %q does not exist (set openwebui.create_missing_knowledge to create it)", ref.field, ref.name)
+		}
+		if m.DryRun {
+			m.log().Infof("[dry-run] Would create knowledge base %q for %s", ref.name, ref.field)
+			continue
+		}
+
+		created, err := m.openwebuiClient.CreateKnowledge(ctx, ref.name, createdKnowledgeDescription)
+		if err != nil {
+			return fmt.Errorf("%s: failed to create knowledge base %q: %w", ref.field, ref.name, err)
+		}
+		idsByName[ref.name] = created.ID
+		*ref.id = created.ID
+	}
+	return nil
+}
+
+// cacheKnowledgeNames replaces the knowledge base names used in logs
+func (m *Manager) cacheKnowledgeNames(knowledge []*openwebui.Knowledge) {
+	names := make(map[string]string, len(knowledge))
+	for _, k := range knowledge {
+		names[k.ID] = k.Name
+	}
+
+	m.knowledgeNamesMu.Lock()
+	defer m.knowledgeNamesMu.Unlock()
+	m.knowledgeNames = names
+	m.knowledgeNamesRefreshed = make(map[string]bool)
+}
+
+// knowledgeLabel returns how logs refer to a knowledge base: 'Name' (ID) with
+// sync.log_knowledge_names, or just the ID. An ID missing from the cache, such as a knowledge
+// base created since the sync started, refreshes the cache once.
+func (m *Manager) knowledgeLabel(ctx context.Context, id string) string {
+	if !m.logKnowledgeNames || id == "" {
+		return id
+	}
+
+	m.knowledgeNamesMu.Lock()
+	name, ok := m.knowledgeNames[id]
+	refresh := !ok && !m.knowledgeNamesRefreshed[id]
+	m.knowledgeNamesMu.Unlock()
+
+	if refresh {
+		knowledge, err := m.openwebuiClient.ListKnowledge(ctx)
+		if err != nil {
+			m.log().Debugf("Failed to list knowledge bases to resolve the name of %s: %v", id, err)
+		} else {
+			m.cacheKnowledgeNames(knowledge)
+		}
+
+		m.knowledgeNamesMu.Lock()
+		name, ok = m.knowledgeNames[id]
+		if m.knowledgeNamesRefreshed == nil {
+			m.knowledgeNamesRefreshed = make(map[string]bool)
+		}
+		m.knowledgeNamesRefreshed[id] = true
+		m.knowledgeNamesMu.Unlock()
+	}
+
+	if !ok || name == "" {
+		return id
+	}
+	return fmt.Sprintf("'%s' (%s)", name, id)
+}
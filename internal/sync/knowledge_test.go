@@ -0,0 +1,206 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/sirupsen/logrus"
+)
+
+func TestManager_ResolveKnowledgeNames(t *testing.T) {
+	tests := []struct {
+		name          string
+		createMissing bool
+		dryRun        bool
+		expectedIDs   []string // github mapping IDs after resolution
+		expectCreated []string
+		expectError   string
+	}{
+		{
+			name:        "missing knowledge base without create",
+			expectError: `github.mappings[1]: knowledge base "New Docs" does not exist`,
+		},
+		{
+			name:          "creates missing knowledge base once",
+			createMissing: true,
+			expectedIDs:   []string{"knowledge-eng", "knowledge-created", "knowledge-explicit", "knowledge-created"},
+			expectCreated: []string{"New Docs"},
+		},
+		{
+			name:          "dry run does not create",
+			createMissing: true,
+			dryRun:        true,
+			expectedIDs:   []string{"knowledge-eng", "", "knowledge-explicit", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			client := &mocks.MockOpenWebUIClient{
+				ListKnowledgeFunc: func(ctx context.Context) ([]*openwebui.Knowledge, error) {
+					return []*openwebui.Knowledge{
+						{ID: "knowledge-eng", Name: "Engineering"},
+						{ID: "knowledge-other", Name: "Other"},
+					}, nil
+				},
+				CreateKnowledgeFunc: func(ctx context.Context, name, description string) (*openwebui.Knowledge, error) {
+					created = append(created, name)
+					return &openwebui.Knowledge{ID: "knowledge-created", Name: name}, nil
+				},
+			}
+			manager := &Manager{openwebuiClient: client, DryRun: tt.dryRun}
+
+			cfg := &config.Config{
+				OpenWebUI: config.OpenWebUIConfig{CreateMissingKnowledge: tt.createMissing},
+				GitHub: config.GitHubConfig{
+					Enabled: true,
+					Mappings: []config.RepositoryMapping{
+						{Repository: "owner/a", KnowledgeName: "Engineering"},
+						{Repository: "owner/b", KnowledgeName: "New Docs"},
+						{Repository: "owner/c", KnowledgeID: "knowledge-explicit", KnowledgeName: "Other"},
+						{Repository: "owner/d", KnowledgeName: "New Docs"},
+					},
+				},
+				// Disabled adapters are not resolved
+				Slack: config.SlackConfig{
+					ChannelMappings: []config.ChannelMapping{{ChannelID: "C1", KnowledgeName: "Unused"}},
+				},
+			}
+
+			err := manager.ResolveKnowledgeNames(context.Background(), cfg)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for i, expected := range tt.expectedIDs {
+				if got := cfg.GitHub.Mappings[i].KnowledgeID; got != expected {
+					t.Errorf("Mapping %d: expected knowledge ID %q, got %q", i, expected, got)
+				}
+			}
+			if strings.Join(created, ",") != strings.Join(tt.expectCreated, ",") {
+				t.Errorf("Expected created knowledge bases %v, got %v", tt.expectCreated, created)
+			}
+			if cfg.Slack.ChannelMappings[0].KnowledgeID != "" {
+				t.Errorf("Expected disabled Slack mapping to stay unresolved")
+			}
+		})
+	}
+}
+
+func TestManager_ResolveKnowledgeNames_NoNames(t *testing.T) {
+	client := &mocks.MockOpenWebUIClient{
+		ListKnowledgeFunc: func(ctx context.Context) ([]*openwebui.Knowledge, error) {
+			t.Error("Expected no knowledge listing without knowledge_name mappings")
+			return nil, nil
+		},
+	}
+	manager := &Manager{openwebuiClient: client}
+
+	cfg := &config.Config{
+		GitHub: config.GitHubConfig{
+			Enabled:  true,
+			Mappings: []config.RepositoryMapping{{Repository: "owner/a", KnowledgeID: "knowledge-1"}},
+		},
+	}
+	if err := manager.ResolveKnowledgeNames(context.Background(), cfg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestManager_KnowledgeNamesInLogs(t *testing.T) {
+	tests := []struct {
+		name              string
+		logKnowledgeNames bool
+		expected          []string
+		unexpected        []string
+	}{
+		{
+			name:              "names resolved",
+			logKnowledgeNames: true,
+			expected: []string{
+				"Would upload file docs/a.md to knowledge 'Engineering Docs' (abc123)",
+				// Created after the run started, resolved by listing the knowledge bases again
+				"Would upload file docs/b.md to knowledge 'New Docs' (def456)",
+				"Would upload file docs/c.md to knowledge missing",
+			},
+		},
+		{
+			name:       "IDs only",
+			expected:   []string{"Would upload file docs/a.md to knowledge abc123"},
+			unexpected: []string{"Engineering Docs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			listCalls := 0
+			client := &mocks.MockOpenWebUIClient{
+				ListKnowledgeFunc: func(ctx context.Context) ([]*openwebui.Knowledge, error) {
+					listCalls++
+					knowledge := []*openwebui.Knowledge{{ID: "abc123", Name: "Engineering Docs"}}
+					if listCalls > 1 {
+						knowledge = append(knowledge, &openwebui.Knowledge{ID: "def456", Name: "New Docs"})
+					}
+					return knowledge, nil
+				},
+			}
+			manager := &Manager{
+				openwebuiClient:   client,
+				store:             newLocalStore(t, tempDir),
+				concurrency:       1,
+				fileIndex:         make(map[string]*FileMetadata),
+				logKnowledgeNames: tt.logKnowledgeNames,
+				DryRun:            true,
+			}
+			mockAdapter := &mocks.MockAdapter{
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{
+						{Path: "docs/a.md", Content: []byte("a"), Hash: "hash-a", KnowledgeID: "abc123"},
+						{Path: "docs/b.md", Content: []byte("b"), Hash: "hash-b", KnowledgeID: "def456"},
+						{Path: "docs/c.md", Content: []byte("c"), Hash: "hash-c", KnowledgeID: "missing"},
+						{Path: "docs/d.md", Content: []byte("d"), Hash: "hash-d", KnowledgeID: "missing"},
+					}, nil
+				},
+			}
+
+			var logs bytes.Buffer
+			logrus.SetOutput(&logs)
+			defer logrus.SetOutput(os.Stderr)
+
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output := logs.String()
+			for _, msg := range tt.expected {
+				if !strings.Contains(output, msg) {
+					t.Errorf("Expected logs to contain %q, got:\n%s", msg, output)
+				}
+			}
+			for _, msg := range tt.unexpected {
+				if strings.Contains(output, msg) {
+					t.Errorf("Expected logs not to contain %q, got:\n%s", msg, output)
+				}
+			}
+			if tt.logKnowledgeNames && listCalls != 3 {
+				// Once at the start of the run, once for def456, once for missing
+				t.Errorf("Expected the knowledge bases to be listed 3 times, got %d", listCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// lastSyncFile is the storage key of the last-sync store
+const lastSyncFile = "last_sync.json"
+
+// LastSyncStore persists the last successful sync time of each adapter, keyed by adapter name,
+// so incremental adapters can pick up where they left off after a restart
+type LastSyncStore struct {
+	store storage.Storage
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// NewLastSyncStore creates a store backed by last_sync.json in store and loads it.
+// A missing or corrupt file results in an empty store, so adapters keep their default behavior,
+// and a warning logged to log.
+func NewLastSyncStore(store storage.Storage, log logging.Logger) *LastSyncStore {
+	lastSync := &LastSyncStore{
+		store: store,
+		times: make(map[string]time.Time),
+	}
+	if err := lastSync.load(); err != nil {
+		log.Warnf("Failed to load last sync times, starting without them: %v", err)
+		lastSync.times = make(map[string]time.Time)
+	}
+	return lastSync
+}
+
+// Get returns the stored last sync time for an adapter
+func (s *LastSyncStore) Get(adapterName string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.times[adapterName]
+	return t, ok
+}
+
+// Record stores the last sync time for an adapter and saves the store
+func (s *LastSyncStore) Record(adapterName string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.times[adapterName] = t
+	return s.save()
+}
+
+// Restore calls SetLastSync on every adapter that has a stored last sync time, logging each to log
+func (s *LastSyncStore) Restore(adapters []adapter.Adapter, log logging.Logger) {
+	for _, adpt := range adapters {
+		if t, ok := s.Get(adpt.Name()); ok {
+			log.Infof("Restoring last sync time for adapter %s: %s", adpt.Name(), t.Format(time.RFC3339))
+			adpt.SetLastSync(t)
+		}
+	}
+}
+
+// load reads the store from the storage
+func (s *LastSyncStore) load() error {
+	data, err := s.store.Read(lastSyncFile)
+	if errors.Is(err, storage.ErrNotExist) {
+		return nil // No sync recorded yet
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read last sync times: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.times); err != nil {
+		return fmt.Errorf("failed to unmarshal last sync times: %w", err)
+	}
+	return nil
+}
+
+// save writes the store to the storage
+func (s *LastSyncStore) save() error {
+	data, err := json.MarshalIndent(s.times, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last sync times: %w", err)
+	}
+
+	if err := s.store.Write(lastSyncFile, data); err != nil {
+		return fmt.Errorf("failed to write last sync times: %w", err)
+	}
+	return nil
+}
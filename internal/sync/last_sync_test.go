@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// lastSyncAdapter is a mock adapter that remembers the last sync time it was given
+type lastSyncAdapter struct {
+	mocks.MockAdapter
+	lastSync time.Time
+}
+
+func (a *lastSyncAdapter) GetLastSync() time.Time  { return a.lastSync }
+func (a *lastSyncAdapter) SetLastSync(t time.Time) { a.lastSync = t }
+
+func TestLastSyncStore_RoundTrip(t *testing.T) {
+	backend := storage.NewMemory()
+	slackSync := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	jiraSync := time.Date(2025, 3, 2, 8, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	store := NewLastSyncStore(backend, logging.Default())
+	if err := store.Record("slack", slackSync); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := store.Record("jira", jiraSync); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	reloaded := NewLastSyncStore(backend, logging.Default())
+	for name, expected := range map[string]time.Time{"slack": slackSync, "jira": jiraSync} {
+		got, ok := reloaded.Get(name)
+		if !ok {
+			t.Errorf("Expected last sync time for %s after reload", name)
+			continue
+		}
+		if !got.Equal(expected) {
+			t.Errorf("Get(%q) = %v, want %v", name, got, expected)
+		}
+	}
+	if _, ok := reloaded.Get("github"); ok {
+		t.Error("Expected no last sync time for an adapter that never synced")
+	}
+
+	slack := &lastSyncAdapter{MockAdapter: mocks.MockAdapter{NameFunc: func() string { return "slack" }}}
+	github := &lastSyncAdapter{MockAdapter: mocks.MockAdapter{NameFunc: func() string { return "github" }}}
+	reloaded.Restore([]adapter.Adapter{slack, github}, logging.Default())
+	if !slack.lastSync.Equal(slackSync) {
+		t.Errorf("Expected restored last sync %v, got %v", slackSync, slack.lastSync)
+	}
+	if !github.lastSync.IsZero() {
+		t.Errorf("Expected adapter without a stored time to keep its default, got %v", github.lastSync)
+	}
+}
+
+func TestLastSyncStore_MissingOrCorruptFile(t *testing.T) {
+	missing := NewLastSyncStore(storage.NewMemory(), logging.Default())
+	if _, ok := missing.Get("slack"); ok {
+		t.Error("Expected empty store when the file does not exist")
+	}
+
+	backend := storage.NewMemory()
+	if err := backend.Write(lastSyncFile, []byte("{not json")); err != nil {
+		t.Fatalf("Failed to write corrupt file: %v", err)
+	}
+	logger := mocks.NewMockLogger()
+	corrupt := NewLastSyncStore(backend, logger)
+	if _, ok := corrupt.Get("slack"); ok {
+		t.Error("Expected empty store when the file is corrupt")
+	}
+	if entries := logger.Entries(); len(entries) != 1 || entries[0].Level != "warn" {
+		t.Errorf("Expected a warning logged to the given logger, got %+v", entries)
+	}
+	// A corrupt file is replaced on the next successful sync
+	if err := corrupt.Record("slack", time.Now()); err != nil {
+		t.Errorf("Record() error = %v", err)
+	}
+}
+
+func TestManager_SyncAdapter_PersistsLastSync(t *testing.T) {
+	backend := storage.NewMemory()
+	manager := &Manager{
+		openwebuiClient: &mocks.MockOpenWebUIClient{},
+		store:           backend,
+		fileIndex:       make(map[string]*FileMetadata),
+		lastSync:        NewLastSyncStore(backend, logging.Default()),
+	}
+
+	adpt := &lastSyncAdapter{MockAdapter: mocks.MockAdapter{NameFunc: func() string { return "slack" }}}
+	if err := manager.SyncAdapter(context.Background(), adpt); err != nil {
+		t.Fatalf("SyncAdapter() error = %v", err)
+	}
+
+	stored, ok := NewLastSyncStore(backend, logging.Default()).Get("slack")
+	if !ok {
+		t.Fatal("Expected last sync time to be persisted after a successful sync")
+	}
+	if !stored.Equal(adpt.lastSync) {
+		t.Errorf("Expected persisted last sync %v, got %v", adpt.lastSync, stored)
+	}
+}
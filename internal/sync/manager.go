@@ -4,24 +4,98 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/openwebui-content-sync/internal/adapter"
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/metrics"
 	"github.com/openwebui-content-sync/internal/openwebui"
-	"github.com/sirupsen/logrus"
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/openwebui-content-sync/internal/utils"
+)
+
+// fileIndexFile is the storage key of the file index
+const fileIndexFile = "file_index.json"
+
+// openwebuiCircuitThreshold is the number of consecutive failed OpenWebUI requests after
+// which further requests fail fast for openwebuiCircuitCooldown
+const (
+	openwebuiCircuitThreshold = 5
+	openwebuiCircuitCooldown  = time.Minute
 )
 
 // Manager handles synchronization between adapters and OpenWebUI
 type Manager struct {
 	openwebuiClient openwebui.ClientInterface
-	storagePath     string
+	store           storage.Storage // file index, file copies and other state
 	knowledgeID     string
 	fileIndex       map[string]*FileMetadata
-	indexPath       string
+	runKeys         map[string]bool // index keys of the files fetched this run, guarded by mu; nil outside a run
+	concurrency     int
+	mu              sync.Mutex       // guards fileIndex during concurrent syncs
+	runMu           sync.Mutex       // serializes sync runs started by different schedules
+	lastSync        *LastSyncStore   // persisted per-adapter last sync times, nil when not persisted
+	snapshots       *SnapshotStore   // raw source content of synced versions, nil when disabled
+	deadLetters     *DeadLetterStore // files that failed to sync, retried first; nil when not persisted
+
+	// DryRun logs planned changes without modifying OpenWebUI or the file index
+	DryRun bool
+
+	summary    SyncSummary
+	fileErrors []error // per-file errors of the current run, reported by Report
+	summaryMu  sync.Mutex
+
+	filenameClaims map[string]string     // knowledge ID + filename -> index key of the file synced under it this run
+	runUploads     map[string]*runUpload // content hash + filename -> file uploaded this run, nil outside a run
+
+	contentTemplate *contentTemplate // optional sync.content_template applied to each file before upload
+
+	dedupContent bool // link files whose content was already uploaded for another file instead of uploading it again
+
+	conflictStrategy string            // sync.conflict_strategy for files edited in OpenWebUI
+	remoteHashes     map[string]string // file ID -> hash OpenWebUI reported when the file index was initialized
+
+	knowledgeAddDelay time.Duration // minimum time between two knowledge additions, 0 to add without waiting
+	knowledgeAddMu    sync.Mutex    // serializes throttled knowledge additions across workers
+	lastKnowledgeAdd  time.Time
+
+	adapterTimeout time.Duration // maximum duration of one adapter's fetch and uploads, 0 for no limit
+
+	knowledgeLocks   map[string]*sync.Mutex // knowledge ID -> lock serializing changes to its file list
+	knowledgeLocksMu sync.Mutex
+
+	adapterStatus map[string]AdapterStatus // last run of each adapter, reported by Status
+	statusMu      sync.Mutex
+
+	logKnowledgeNames       bool              // refer to knowledge bases as 'Name' (ID) in logs
+	knowledgeNames          map[string]string // knowledge ID -> name, listed at the start of every run
+	knowledgeNamesRefreshed map[string]bool   // unknown knowledge IDs the names were listed again for
+	knowledgeNamesMu        sync.Mutex
+
+	targets            []*Manager        // additional OpenWebUI instances every sync is replayed to
+	targetName         string            // name of this manager's openwebui.targets entry, empty for the main instance
+	targetKnowledgeIDs map[string]string // knowledge ID of the main instance -> knowledge ID on this target
+
+	logger logging.Logger // nil to log to the global logrus logger
+}
+
+// SyncSummary counts the actions taken (or planned, in dry-run mode) during a sync
+type SyncSummary struct {
+	DryRun   bool `json:"dry_run"`
+	Uploaded int  `json:"uploaded"`
+	Linked   int  `json:"linked"`
+	Updated  int  `json:"updated"`
+	Skipped  int  `json:"skipped"`
+	Deleted  int  `json:"deleted"`
+	Failed   int  `json:"failed"`
 }
 
 // FileMetadata stores metadata about synced files
@@ -33,38 +107,105 @@ type FileMetadata struct {
 	KnowledgeID string    `json:"knowledge_id,omitempty"`
 	SyncedAt    time.Time `json:"synced_at"`
 	Modified    time.Time `json:"modified"`
+	ID          string    `json:"id,omitempty"`          // stable identity reported by the adapter, see adapter.File
+	RemoteHash  string    `json:"remote_hash,omitempty"` // hash OpenWebUI reported for the content we synced, to detect edits made in OpenWebUI
 }
 
 // NewManager creates a new sync manager
-func NewManager(openwebuiConfig config.OpenWebUIConfig, storageConfig config.StorageConfig) (*Manager, error) {
+func NewManager(openwebuiConfig config.OpenWebUIConfig, storageConfig config.StorageConfig, syncConfig config.SyncConfig, httpConfig config.HTTPConfig) (*Manager, error) {
 	client := openwebui.NewClient(openwebuiConfig.BaseURL, openwebuiConfig.APIKey)
+	retryConfig := utils.DefaultRetryConfig()
+	if retry := openwebuiConfig.Retry; retry != (config.RetryConfig{}) {
+		retryConfig = utils.RetryConfig{
+			MaxRetries: retry.MaxRetries,
+			BaseDelay:  retry.BaseDelay,
+			MaxDelay:   retry.MaxDelay,
+			Multiplier: retry.Multiplier,
+		}
+	}
+	// Stop retrying every file for minutes when OpenWebUI is down
+	retryConfig.CircuitBreaker = utils.NewCircuitBreaker(openwebuiCircuitThreshold, openwebuiCircuitCooldown)
+	retryConfig.CircuitKey = "openwebui"
+	client.SetRetryConfig(retryConfig)
+	client.SetProcessingConfig(openwebuiConfig.ProcessingTimeout, openwebuiConfig.ProcessingPollInterval)
+	if err := client.SetHTTPConfig(openwebuiConfig.RequestTimeout, openwebuiConfig.InsecureSkipVerify, openwebuiConfig.CACertPath); err != nil {
+		return nil, fmt.Errorf("failed to configure OpenWebUI client: %w", err)
+	}
+	client.SetHeaders(httpConfig.UserAgent, httpConfig.Headers)
 
-	// Ensure storage directory exists
-	if err := os.MkdirAll(storageConfig.Path, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	store, err := storage.New(storageConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	indexPath := filepath.Join(storageConfig.Path, "file_index.json")
+	concurrency := syncConfig.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
 
 	manager := &Manager{
 		openwebuiClient: client,
-		storagePath:     storageConfig.Path,
-		indexPath:       indexPath,
+		store:           store,
 		fileIndex:       make(map[string]*FileMetadata),
+		concurrency:     concurrency,
+		snapshots:       NewSnapshotStore(storageConfig, store),
+		dedupContent:    syncConfig.DedupContent,
+
+		conflictStrategy: syncConfig.ConflictStrategy,
+
+		logKnowledgeNames: syncConfig.LogKnowledgeNames,
+
+		knowledgeAddDelay: syncConfig.KnowledgeAddDelay,
+		adapterTimeout:    syncConfig.AdapterTimeout,
+	}
+	manager.lastSync = NewLastSyncStore(store, manager.log())
+	manager.deadLetters = NewDeadLetterStore(store, manager.log())
+
+	if syncConfig.ContentTemplate != "" {
+		tmpl, err := loadContentTemplate(syncConfig.ContentTemplate)
+		if err != nil {
+			return nil, err
+		}
+		manager.contentTemplate = tmpl
 	}
 
 	// Load existing file index
 	if err := manager.loadFileIndex(); err != nil {
-		logrus.Warnf("Failed to load file index: %v", err)
+		manager.log().Warnf("Failed to load file index: %v", err)
+	}
+
+	targets, err := newTargetManagers(openwebuiConfig, storageConfig, syncConfig, httpConfig)
+	if err != nil {
+		return nil, err
 	}
+	manager.targets = targets
 
 	return manager, nil
 }
 
 // SetKnowledgeID sets the knowledge ID for file operations
 func (m *Manager) SetKnowledgeID(knowledgeID string) {
-	logrus.Debugf("Setting knowledge ID: %s", knowledgeID)
+	m.log().Debugf("Setting knowledge ID: %s", knowledgeID)
 	m.knowledgeID = knowledgeID
+	for _, target := range m.targets {
+		target.SetKnowledgeID(target.targetKnowledgeID(knowledgeID))
+	}
+}
+
+// SetLogger sets the logger of the manager and its additional targets
+func (m *Manager) SetLogger(logger logging.Logger) {
+	m.logger = logger
+	for _, target := range m.targets {
+		target.SetLogger(logger.WithField("target", target.targetName))
+	}
+}
+
+// log returns the manager's logger
+func (m *Manager) log() logging.Logger {
+	if m.logger == nil {
+		return logging.Default()
+	}
+	return m.logger
 }
 
 // InitializeFileIndex populates the file index with existing files from OpenWebUI
@@ -79,9 +220,22 @@ func (m *Manager) InitializeFileIndex(ctx context.Context, adapters []adapter.Ad
 
 	// Collect knowledge IDs from adapters
 	for _, adpt := range adapters {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Prefer asking the adapter directly, so incremental adapters keep their state for the first sync
+		if provider, ok := adpt.(adapter.KnowledgeIDProvider); ok {
+			for _, knowledgeID := range provider.KnowledgeIDs() {
+				if knowledgeID != "" {
+					knowledgeIDs[knowledgeID] = true
+				}
+			}
+			continue
+		}
+
 		files, err := adpt.FetchFiles(ctx)
 		if err != nil {
-			logrus.Warnf("Failed to fetch files from adapter %s during initialization: %v", adpt.Name(), err)
+			m.log().Warnf("Failed to fetch files from adapter %s during initialization: %v", adpt.Name(), err)
 			continue
 		}
 
@@ -93,27 +247,61 @@ func (m *Manager) InitializeFileIndex(ctx context.Context, adapters []adapter.Ad
 	}
 
 	if len(knowledgeIDs) == 0 {
-		logrus.Debugf("No knowledge IDs found, skipping file index initialization")
+		m.log().Debugf("No knowledge IDs found, skipping file index initialization")
 		return nil
 	}
 
-	logrus.Info("Initializing file index from OpenWebUI knowledge bases...")
+	m.initializeFromKnowledge(ctx, knowledgeIDs)
+	for _, target := range m.targets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.log().Infof("Initializing file index of OpenWebUI target %s...", target.targetName)
+		targetKnowledgeIDs := make(map[string]bool, len(knowledgeIDs))
+		for knowledgeID := range knowledgeIDs {
+			targetKnowledgeIDs[target.targetKnowledgeID(knowledgeID)] = true
+		}
+		target.initializeFromKnowledge(ctx, targetKnowledgeIDs)
+	}
+	return ctx.Err()
+}
+
+// initializeFromKnowledge adds the files of the given knowledge bases to the file index
+func (m *Manager) initializeFromKnowledge(ctx context.Context, knowledgeIDs map[string]bool) {
+	m.log().Infof("Initializing file index from OpenWebUI knowledge bases...")
+
+	// Files already tracked by an adapter entry are keyed by path, not filename, so match them by ID
+	trackedFileIDs := make(map[string]bool)
+	for _, metadata := range m.fileIndex {
+		if metadata.Source != "openwebui" && metadata.FileID != "" {
+			trackedFileIDs[metadata.FileID] = true
+		}
+	}
+
+	m.remoteHashes = make(map[string]string)
 
 	// Initialize file index for each knowledge base
 	for knowledgeID := range knowledgeIDs {
-		logrus.Debugf("Initializing file index for knowledge base: %s", knowledgeID)
+		if ctx.Err() != nil {
+			return
+		}
+		m.log().Debugf("Initializing file index for knowledge base: %s", knowledgeID)
 
 		// Get files from the knowledge source
 		files, err := m.openwebuiClient.GetKnowledgeFiles(ctx, knowledgeID)
 		if err != nil {
-			logrus.Warnf("Failed to get files from knowledge source %s: %v", knowledgeID, err)
+			m.log().Warnf("Failed to get files from knowledge source %s: %v", knowledgeID, err)
 			continue
 		}
 
-		logrus.Debugf("Found %d existing files in knowledge source %s", len(files), knowledgeID)
+		m.log().Debugf("Found %d existing files in knowledge source %s", len(files), knowledgeID)
 
 		// Add files to existing index (merge instead of replace)
 		for _, file := range files {
+			if file.Hash != "" {
+				m.remoteHashes[file.ID] = file.Hash
+			}
+
 			// Use filename as path if no path is available
 			filePath := file.Path
 			if filePath == "" {
@@ -125,12 +313,17 @@ func (m *Manager) InitializeFileIndex(ctx context.Context, adapters []adapter.Ad
 			// with files that will be synced from adapters
 			fileKey := filePath
 
+			if trackedFileIDs[file.ID] {
+				m.log().Debugf("File %s (ID: %s) already tracked by an adapter, keeping existing entry", filePath, file.ID)
+				continue
+			}
+
 			// Check if we already have this file in the index (from previous syncs)
 			if existing, exists := m.fileIndex[fileKey]; exists {
 				// If we already have the file with a hash from an adapter, keep that hash
 				// Only update the file ID and knowledge ID if they're missing
 				if existing.Source != "openwebui" {
-					logrus.Debugf("File %s already in index from %s, keeping existing hash", fileKey, existing.Source)
+					m.log().Debugf("File %s already in index from %s, keeping existing hash", fileKey, existing.Source)
 					// Update file ID and knowledge ID if they're missing
 					if existing.FileID == "" {
 						existing.FileID = file.ID
@@ -161,91 +354,629 @@ func (m *Manager) InitializeFileIndex(ctx context.Context, adapters []adapter.Ad
 			}
 
 			m.fileIndex[fileKey] = metadata
-			logrus.Debugf("Added existing file to index: %s (ID: %s, Hash: %s, Knowledge: %s)", filePath, file.ID, fileHash, knowledgeID)
+			m.log().Debugf("Added existing file to index: %s (ID: %s, Hash: %s, Knowledge: %s)", filePath, file.ID, fileHash, knowledgeID)
 		}
 	}
 
-	logrus.Infof("File index now contains %d files from %d knowledge bases", len(m.fileIndex), len(knowledgeIDs))
+	// Files synced without a hash from OpenWebUI take the current one as the baseline to detect
+	// later edits in OpenWebUI against
+	for _, metadata := range m.fileIndex {
+		if metadata.Source != "openwebui" && metadata.RemoteHash == "" && m.remoteHashes[metadata.FileID] != "" {
+			metadata.RemoteHash = m.remoteHashes[metadata.FileID]
+		}
+	}
+
+	m.log().Infof("File index now contains %d files from %d knowledge bases", len(m.fileIndex), len(knowledgeIDs))
 
 	// Save the updated index
 	if err := m.saveFileIndex(); err != nil {
-		logrus.Warnf("Failed to save initialized file index: %v", err)
+		m.log().Warnf("Failed to save initialized file index: %v", err)
 	}
+}
 
-	return nil
+// RestoreLastSync sets each adapter's last sync time from the persisted store
+func (m *Manager) RestoreLastSync(adapters []adapter.Adapter) {
+	if m.lastSync != nil {
+		m.lastSync.Restore(adapters, m.log())
+	}
 }
 
 // SyncFiles synchronizes files from adapters to OpenWebUI
 func (m *Manager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) error {
-	logrus.Info("Starting file synchronization")
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
 
-	// List available knowledge sources for debugging
-	logrus.Debugf("Listing available knowledge sources...")
-	knowledgeList, err := m.openwebuiClient.ListKnowledge(ctx)
-	if err != nil {
-		logrus.Warnf("Failed to list knowledge sources: %v", err)
-	} else {
-		logrus.Debugf("Available knowledge sources:")
-		for _, knowledge := range knowledgeList {
-			logrus.Debugf("  - ID: %s, Name: %s, Description: %s", knowledge.ID, knowledge.Name, knowledge.Description)
-		}
-	}
+	m.log().Infof("Starting file synchronization")
+	m.startRun()
+	defer m.endRun()
+
+	syncStart := time.Now()
+	defer func() {
+		metrics.SyncDuration.Observe(time.Since(syncStart).Seconds())
+	}()
+
+	m.logKnowledgeSources(ctx)
 
 	// Track files that are currently present in repositories
-	currentFiles := make(map[string]bool)
+	current := newCurrentFiles()
 
 	for _, adpt := range adapters {
 		// Check if context is cancelled before processing each adapter
 		select {
 		case <-ctx.Done():
-			logrus.Info("Sync cancelled, stopping file synchronization")
+			m.log().Infof("Sync cancelled, stopping file synchronization")
 			return ctx.Err()
 		default:
 		}
 
-		logrus.Infof("Syncing files from adapter: %s", adpt.Name())
+		if err := m.syncAdapterFiles(ctx, adpt, current); err != nil {
+			return err
+		}
+	}
 
-		files, err := adpt.FetchFiles(ctx)
-		if err != nil {
-			logrus.Errorf("Failed to fetch files from adapter %s: %v", adpt.Name(), err)
+	// Clean up orphaned files (files that are no longer in repositories)
+	if err := m.cleanupOrphanedFiles(ctx, current); err != nil {
+		m.log().Errorf("Failed to cleanup orphaned files: %v", err)
+	}
+
+	// Save updated file index
+	if err := m.saveFileIndex(); err != nil {
+		m.log().Errorf("Failed to save file index: %v", err)
+	}
+
+	m.logSummary()
+	return errors.Join(m.Report().Err(), m.syncTargets(ctx, current.fetches, true))
+}
+
+// SyncAdapter synchronizes the files of a single adapter to OpenWebUI.
+// Orphan cleanup needs the files of every adapter, so it only runs as part of SyncFiles.
+func (m *Manager) SyncAdapter(ctx context.Context, adpt adapter.Adapter) error {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	m.log().Infof("Starting file synchronization for adapter: %s", adpt.Name())
+	m.startRun()
+	defer m.endRun()
+
+	syncStart := time.Now()
+	defer func() {
+		metrics.SyncDuration.Observe(time.Since(syncStart).Seconds())
+	}()
+
+	current := newCurrentFiles()
+	if err := m.syncAdapterFiles(ctx, adpt, current); err != nil {
+		return err
+	}
+
+	if err := m.saveFileIndex(); err != nil {
+		m.log().Errorf("Failed to save file index: %v", err)
+	}
+
+	m.logSummary()
+	return errors.Join(m.Report().Err(), m.syncTargets(ctx, current.fetches, false))
+}
+
+// SyncChangedFile synchronizes a single file reported by a watching adapter and saves the index
+func (m *Manager) SyncChangedFile(ctx context.Context, file *adapter.File, source string) error {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	err := m.syncFile(ctx, file, source)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(source).Inc()
+	} else if err := m.saveFileIndex(); err != nil {
+		m.log().Errorf("Failed to save file index: %v", err)
+	}
+
+	return errors.Join(err, m.eachTarget(func(target *Manager) error {
+		return target.SyncChangedFile(ctx, target.targetFile(file), source)
+	}))
+}
+
+// SyncItem synchronizes a single item of an adapter, such as a Slack channel named by a webhook
+// event, like changed files and saves the index. Nothing is deleted. An empty ID, an adapter
+// that can't fetch single items, or an item it can only place with a full fetch syncs the
+// whole adapter instead.
+func (m *Manager) SyncItem(ctx context.Context, adpt adapter.Adapter, id string) error {
+	fetcher, ok := adpt.(adapter.ItemFetcher)
+	if !ok || id == "" {
+		return m.SyncAdapter(ctx, adpt)
+	}
+
+	err := m.syncItem(ctx, fetcher, adpt.Name(), id)
+	if errors.Is(err, adapter.ErrUnknownItem) {
+		m.log().Infof("Adapter %s can only sync item %s with its whole source, syncing the adapter", adpt.Name(), id)
+		return m.SyncAdapter(ctx, adpt)
+	}
+	return err
+}
+
+// syncItem fetches an item while no other sync runs and syncs its files
+func (m *Manager) syncItem(ctx context.Context, fetcher adapter.ItemFetcher, source, id string) error {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	files, err := fetcher.FetchItem(ctx, id)
+	if err != nil {
+		metrics.SyncErrors.WithLabelValues(source).Inc()
+		return fmt.Errorf("failed to fetch item %s from adapter %s: %w", id, source, err)
+	}
+	m.log().Infof("Syncing %d files of item %s from adapter %s", len(files), id, source)
+
+	var errs []error
+	for _, file := range files {
+		if err := m.syncFile(ctx, file, source); err != nil {
+			metrics.SyncErrors.WithLabelValues(source).Inc()
+			errs = append(errs, err)
+		} else {
+			m.fileSynced(fetcher, file)
+		}
+	}
+	m.commitSynced(fetcher)
+	if len(files) > 0 {
+		if err := m.saveFileIndex(); err != nil {
+			m.log().Errorf("Failed to save file index: %v", err)
+		}
+	}
+
+	for _, file := range files {
+		errs = append(errs, m.eachTarget(func(target *Manager) error {
+			return target.SyncChangedFile(ctx, target.targetFile(file), source)
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// fileSynced reports a synced file to adapters that skip content they synced before
+func (m *Manager) fileSynced(adpt any, file *adapter.File) {
+	if recorder, ok := adpt.(adapter.SyncRecorder); ok && !m.DryRun {
+		recorder.FileSynced(file)
+	}
+}
+
+// commitSynced lets adapters that skip content they synced before persist the synced files
+func (m *Manager) commitSynced(adpt any) {
+	if recorder, ok := adpt.(adapter.SyncRecorder); ok && !m.DryRun {
+		if err := recorder.CommitSynced(); err != nil {
+			m.log().Warnf("Failed to save the synced content of an adapter: %v", err)
+		}
+	}
+}
+
+// startRun resets the summary for a new sync run
+func (m *Manager) startRun() {
+	if m.DryRun {
+		m.log().Infof("Dry run enabled: no changes will be made to OpenWebUI")
+	}
+
+	m.summaryMu.Lock()
+	m.summary = SyncSummary{DryRun: m.DryRun}
+	m.fileErrors = nil
+	m.filenameClaims = nil
+	m.runUploads = make(map[string]*runUpload)
+	m.summaryMu.Unlock()
+
+	m.mu.Lock()
+	m.runKeys = make(map[string]bool)
+	m.mu.Unlock()
+}
+
+// logKnowledgeSources lists available knowledge sources for debugging and caches their names for logs
+func (m *Manager) logKnowledgeSources(ctx context.Context) {
+	m.log().Debugf("Listing available knowledge sources...")
+	knowledgeList, err := m.openwebuiClient.ListKnowledge(ctx)
+	if err != nil {
+		m.log().Warnf("Failed to list knowledge sources: %v", err)
+	} else {
+		m.cacheKnowledgeNames(knowledgeList)
+		m.log().Debugf("Available knowledge sources:")
+		for _, knowledge := range knowledgeList {
+			m.log().Debugf("  - ID: %s, Name: %s, Description: %s", knowledge.ID, knowledge.Name, knowledge.Description)
+		}
+	}
+}
+
+// logSummary logs the action counts of the current run and the first few file errors
+func (m *Manager) logSummary() {
+	summary := m.Summary()
+	m.log().Infof("File synchronization completed (uploaded: %d, linked: %d, updated: %d, skipped: %d, deleted: %d, failed: %d)",
+		summary.Uploaded, summary.Linked, summary.Updated, summary.Skipped, summary.Deleted, summary.Failed)
+
+	report := m.Report()
+	if report.Failed == 0 {
+		return
+	}
+	m.log().Warnf("%d files failed to sync (succeeded: %d, skipped: %d)", report.Failed, report.Succeeded, report.Skipped)
+	for _, msg := range report.Errors {
+		m.log().Warnf("  - %s", msg)
+	}
+	if report.Failed > len(report.Errors) {
+		m.log().Warnf("  ... and %d more", report.Failed-len(report.Errors))
+	}
+}
+
+// syncAdapterFiles fetches the files of one adapter and uploads them through a bounded worker pool.
+// Fetched files are added to current. Only context cancellation is returned as an error;
+// fetch failures, an adapter running out of sync.adapter_timeout, and per-file failures are
+// logged and recorded, so other adapters can still sync.
+func (m *Manager) syncAdapterFiles(ctx context.Context, adpt adapter.Adapter, current *currentFiles) error {
+	concurrency := m.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	m.log().Infof("Syncing files from adapter: %s", adpt.Name())
+	start := time.Now()
+
+	// The adapter's fetch and uploads run under its own deadline, while the run's context
+	// stays in runCtx to tell a timed out adapter from a cancelled run
+	runCtx := ctx
+	if m.adapterTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.adapterTimeout)
+		defer cancel()
+	}
+	timedOut := func() bool {
+		return runCtx.Err() == nil && ctx.Err() != nil
+	}
+
+	fullFetch := m.requestFullFetch(adpt)
+	files, err := adpt.FetchFiles(ctx)
+	m.recordFetch(adpt, files, err, fullFetch, current)
+	if err != nil {
+		if timedOut() {
+			err = fmt.Errorf("adapter timed out after %v: %w", m.adapterTimeout, err)
+		}
+		m.log().Errorf("Failed to fetch files from adapter %s: %v", adpt.Name(), err)
+		metrics.SyncErrors.WithLabelValues(adpt.Name()).Inc()
+		m.recordAdapterStatus(adpt, start, 0, 0, fmt.Errorf("failed to fetch files: %w", err))
+		m.markFetchFailed(adpt, current)
+		return nil
+	}
+
+	m.log().Debugf("Fetched %d files from adapter %s", len(files), adpt.Name())
+	m.markFetched(adpt, files, current)
+
+	// Every fetched file is known before any is synced, so a file isn't mistaken for a rename
+	// of another file of this run with the same content
+	m.mu.Lock()
+	if m.runKeys == nil {
+		m.runKeys = make(map[string]bool)
+	}
+	for _, file := range files {
+		m.runKeys[m.fileIndexKey(adpt.Name(), file)] = true
+	}
+	m.mu.Unlock()
+
+	// Upload files through a bounded worker pool, starting with the files that failed before
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var fileErrors []error
+	sem := make(chan struct{}, concurrency)
+	cancelled := false
+	dispatched := 0
+	deferred := 0
+
+	for _, file := range m.prioritizeFailed(adpt.Name(), files) {
+		// Check if context is cancelled before processing each file
+		select {
+		case <-ctx.Done():
+			cancelled = true
+		case sem <- struct{}{}:
+		}
+		if cancelled {
+			break
+		}
+
+		fileKey := m.fileIndexKey(adpt.Name(), file)
+		current.filenames[filepath.Base(file.Path)] = true // Track by filename to match OpenWebUI behavior
+		current.keys[fileKey] = true
+		m.claimFilename(file, fileKey)
+		if m.backingOff(fileKey, start) {
+			<-sem
+			deferred++
 			continue
 		}
 
-		logrus.Debugf("Fetched %d files from adapter %s", len(files), adpt.Name())
+		dispatched++
+		wg.Add(1)
+		go func(file *adapter.File, fileKey string) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-		for _, file := range files {
-			// Check if context is cancelled before processing each file
-			select {
-			case <-ctx.Done():
-				logrus.Info("Sync cancelled, stopping file synchronization")
-				return ctx.Err()
-			default:
+			err := m.loadUnchangedContent(ctx, adpt, file)
+			if err == nil {
+				err = m.syncFile(ctx, file, adpt.Name())
+			}
+			m.recordSyncResult(ctx, fileKey, file, adpt.Name(), err)
+			if err == nil {
+				m.fileSynced(adpt, file)
+			} else {
+				m.log().Errorf("Failed to sync file %s: %v", file.Path, err)
+				m.recordAction(actionFailed)
+				metrics.SyncErrors.WithLabelValues(adpt.Name()).Inc()
+				errMu.Lock()
+				fileErrors = append(fileErrors, fmt.Errorf("%s: %w", file.Path, err))
+				errMu.Unlock()
 			}
+		}(file, fileKey)
+	}
 
-			filename := filepath.Base(file.Path)
-			currentFiles[filename] = true // Track by filename to match OpenWebUI behavior
+	wg.Wait()
+	m.recordFileErrors(fileErrors)
+	m.commitSynced(adpt)
+	if !cancelled && (current.complete[adpt.Name()] || fullFetch) && m.deadLetters != nil {
+		// Files a complete or full fetch no longer returns were removed from the source, or
+		// fell out of what it syncs, and can't be retried
+		m.deadLetters.Prune(adpt.Name(), current.keys)
+	}
+	m.saveFailedSyncs()
+
+	if cancelled && timedOut() {
+		m.log().Errorf("Adapter %s timed out after %v with %d of %d files left, continuing with the next adapter", adpt.Name(), m.adapterTimeout, len(files)-dispatched, len(files))
+		metrics.SyncErrors.WithLabelValues(adpt.Name()).Inc()
+		m.recordAdapterStatus(adpt, start, dispatched-len(fileErrors), len(fileErrors), fmt.Errorf("adapter timed out after %v", m.adapterTimeout))
+		// Files that were never synced must not be removed as orphans
+		delete(current.complete, adpt.Name())
+		m.markFetchFailed(adpt, current)
+		return nil
+	}
+	if cancelled {
+		m.log().Infof("Sync cancelled, stopping file synchronization")
+		m.recordAdapterStatus(adpt, start, 0, len(fileErrors), ctx.Err())
+		return ctx.Err()
+	}
 
-			if err := m.syncFile(ctx, file, adpt.Name()); err != nil {
-				logrus.Errorf("Failed to sync file %s: %v", file.Path, err)
-				continue
-			}
+	var statusErr error
+	if len(fileErrors) > 0 {
+		m.log().Warnf("%d of %d files from adapter %s failed to sync", len(fileErrors), len(files), adpt.Name())
+		statusErr = fmt.Errorf("%d of %d files failed to sync", len(fileErrors), len(files))
+	} else if !m.DryRun {
+		metrics.LastSuccessfulSync.WithLabelValues(adpt.Name()).SetToCurrentTime()
+	}
+
+	// Update last sync time
+	adpt.SetLastSync(time.Now())
+
+	// Only persist fully successful syncs, so failed files and files waiting for their retry
+	// are picked up again after a restart
+	if m.lastSync != nil && len(fileErrors) == 0 && deferred == 0 && !m.DryRun {
+		if err := m.lastSync.Record(adpt.Name(), adpt.GetLastSync()); err != nil {
+			m.log().Warnf("Failed to save last sync time for adapter %s: %v", adpt.Name(), err)
 		}
+	}
 
-		// Update last sync time
-		adpt.SetLastSync(time.Now())
+	if deferred > 0 {
+		m.log().Infof("%d files from adapter %s that failed to sync before wait for their next retry", deferred, adpt.Name())
 	}
 
-	// Clean up orphaned files (files that are no longer in repositories)
-	if err := m.cleanupOrphanedFiles(ctx, currentFiles); err != nil {
-		logrus.Errorf("Failed to cleanup orphaned files: %v", err)
+	m.recordAdapterStatus(adpt, start, len(files)-len(fileErrors)-deferred, len(fileErrors), statusErr)
+	return nil
+}
+
+// Sync actions recorded in the summary
+const (
+	actionUpload = "upload"
+	actionLink   = "link"
+	actionUpdate = "update"
+	actionSkip   = "skip"
+	actionDelete = "delete"
+	actionFailed = "failed"
+)
+
+// recordAction increments the summary counter for an action
+func (m *Manager) recordAction(action string) {
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+
+	switch action {
+	case actionUpload:
+		m.summary.Uploaded++
+	case actionLink:
+		m.summary.Linked++
+	case actionUpdate:
+		m.summary.Updated++
+	case actionSkip:
+		m.summary.Skipped++
+	case actionDelete:
+		m.summary.Deleted++
+	case actionFailed:
+		m.summary.Failed++
 	}
+}
 
-	// Save updated file index
-	if err := m.saveFileIndex(); err != nil {
-		logrus.Errorf("Failed to save file index: %v", err)
+// Summary returns the action counts of the most recent sync
+func (m *Manager) Summary() SyncSummary {
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+	return m.summary
+}
+
+// currentFiles tracks the files fetched during a sync run, to detect orphaned index entries
+type currentFiles struct {
+	filenames map[string]bool // filenames of fetched files, matched against entries initialized from OpenWebUI
+	keys      map[string]bool // file index keys of fetched files
+	complete  map[string]bool // adapters whose fetch returned every file of their source
+	fetched   map[string]bool // knowledge bases of adapters that fetched successfully
+	failed    map[string]bool // knowledge bases of adapters whose fetch failed
+	fetches   []*adapterFetch // fetches replayed to the additional targets
+}
+
+func newCurrentFiles() *currentFiles {
+	return &currentFiles{
+		filenames: make(map[string]bool),
+		keys:      make(map[string]bool),
+		complete:  make(map[string]bool),
+		fetched:   make(map[string]bool),
+		failed:    make(map[string]bool),
 	}
+}
+
+// coversKnowledge reports whether every adapter syncing to a knowledge base fetched
+// successfully, so entries initialized from OpenWebUI in it can be matched against this run
+func (c *currentFiles) coversKnowledge(knowledgeID string) bool {
+	return c.fetched[knowledgeID] && !c.failed[knowledgeID]
+}
+
+// markFetched records a successful fetch of an adapter's files in current
+func (m *Manager) markFetched(adpt adapter.Adapter, files []*adapter.File, current *currentFiles) {
+	if fetcher, ok := adpt.(adapter.CompleteFetcher); ok && fetcher.FetchComplete() {
+		current.complete[adpt.Name()] = true
+	}
+	if provider, ok := adpt.(adapter.KnowledgeIDProvider); ok {
+		for _, knowledgeID := range provider.KnowledgeIDs() {
+			current.fetched[knowledgeID] = true
+		}
+	}
+	for _, file := range files {
+		knowledgeID := file.KnowledgeID
+		if knowledgeID == "" {
+			knowledgeID = m.knowledgeID
+		}
+		current.fetched[knowledgeID] = true
+	}
+}
+
+// markFetchFailed records a failed fetch in current. The adapter's knowledge bases are the
+// ones it reports, plus the ones its indexed files were synced to.
+func (m *Manager) markFetchFailed(adpt adapter.Adapter, current *currentFiles) {
+	if provider, ok := adpt.(adapter.KnowledgeIDProvider); ok {
+		for _, knowledgeID := range provider.KnowledgeIDs() {
+			current.failed[knowledgeID] = true
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, metadata := range m.fileIndex {
+		if metadata.Source != adpt.Name() {
+			continue
+		}
+		knowledgeID := metadata.KnowledgeID
+		if knowledgeID == "" {
+			knowledgeID = m.knowledgeID
+		}
+		current.failed[knowledgeID] = true
+	}
+}
+
+// fileIndexKey returns the file index key of an adapter file: the adapter name, the file's
+// origin within the adapter (e.g. the GitHub repository), its path and, after an @, its
+// knowledge base. Files with the same name from different sources or synced to different
+// knowledge bases are tracked independently; only entries initialized from OpenWebUI are
+// keyed by filename.
+func (m *Manager) fileIndexKey(source string, file *adapter.File) string {
+	key := fileOrigin(source, file) + "/" + file.Path
+	knowledgeID := file.KnowledgeID
+	if knowledgeID == "" {
+		knowledgeID = m.knowledgeID
+	}
+	if knowledgeID != "" {
+		key += "@" + knowledgeID
+	}
+	return key
+}
+
+// fileOrigin returns the adapter name of a file followed by its origin within the adapter, if
+// any, e.g. "github/owner/repo"
+func fileOrigin(source string, file *adapter.File) string {
+	if file.Source != "" && file.Source != source {
+		return source + "/" + file.Source
+	}
+	return source
+}
+
+// migrateIndexKeys re-keys entries of adapter files written by older versions, whose keys
+// lack the knowledge base. The caller must hold m.mu or own the manager.
+func (m *Manager) migrateIndexKeys() {
+	for key, metadata := range m.fileIndex {
+		if metadata.Source == "openwebui" || metadata.KnowledgeID == "" || !strings.HasSuffix(key, "/"+metadata.Path) {
+			continue
+		}
+		if _, taken := m.fileIndex[key+"@"+metadata.KnowledgeID]; !taken {
+			m.moveIndexEntry(key, key+"@"+metadata.KnowledgeID)
+		}
+	}
+}
+
+// findByID returns the index entry of a source's file with the given stable ID, or nil if
+// there is none or id is empty. The caller must hold m.mu.
+func (m *Manager) findByID(source, id string) (string, *FileMetadata) {
+	if id == "" {
+		return "", nil
+	}
+	for indexKey, metadata := range m.fileIndex {
+		if metadata.Source == source && metadata.ID == id {
+			return indexKey, metadata
+		}
+	}
+	return "", nil
+}
+
+// moveIndexEntry re-keys a file index entry. The caller must hold m.mu.
+func (m *Manager) moveIndexEntry(oldKey, newKey string) {
+	metadata, ok := m.fileIndex[oldKey]
+	if !ok || oldKey == newKey {
+		return
+	}
+	delete(m.fileIndex, oldKey)
+	m.fileIndex[newKey] = metadata
+	m.log().Debugf("Updating file key from %s to %s", oldKey, newKey)
+}
 
-	logrus.Info("File synchronization completed")
+// claimFilename records which file is synced under a filename in a knowledge base during the
+// current run and warns when a file from another source already claimed it. Both files are
+// still synced, but OpenWebUI will show two files with the same name.
+func (m *Manager) claimFilename(file *adapter.File, key string) {
+	knowledgeID := file.KnowledgeID
+	if knowledgeID == "" {
+		knowledgeID = m.knowledgeID
+	}
+	claim := knowledgeID + "/" + filepath.Base(file.Path)
+
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+	if m.filenameClaims == nil {
+		m.filenameClaims = make(map[string]string)
+	}
+	if owner, ok := m.filenameClaims[claim]; ok && owner != key {
+		m.log().Warnf("Filename collision in knowledge %s: %s and %s are both named %s", knowledgeID, owner, key, filepath.Base(file.Path))
+		return
+	}
+	m.filenameClaims[claim] = key
+}
+
+// loadUnchangedContent has the adapter read a file it reported as unchanged without reading
+// it, unless the file index holds that version in the file's knowledge base, so syncFile can
+// skip the file
+func (m *Manager) loadUnchangedContent(ctx context.Context, adpt adapter.Adapter, file *adapter.File) error {
+	if !file.Unchanged {
+		return nil
+	}
+
+	knowledgeID := file.KnowledgeID
+	if knowledgeID == "" {
+		knowledgeID = m.knowledgeID
+	}
+	m.mu.Lock()
+	metadata := m.fileIndex[m.fileIndexKey(adpt.Name(), file)]
+	indexed := metadata != nil && metadata.Source != "openwebui" && metadata.FileID != "" && metadata.Hash == m.syncedHash(file)
+	if indexed && metadata.KnowledgeID != "" {
+		indexed = metadata.KnowledgeID == knowledgeID
+	}
+	m.mu.Unlock()
+	if indexed {
+		return nil
+	}
+
+	loader, ok := adpt.(adapter.ContentLoader)
+	if !ok {
+		return fmt.Errorf("adapter %s reported the file as unchanged but can't load its content", adpt.Name())
+	}
+	m.log().Debugf("File %s is unchanged but not synced yet, loading its content", file.Path)
+	if err := loader.LoadContent(ctx, file); err != nil {
+		return fmt.Errorf("failed to load content: %w", err)
+	}
 	return nil
 }
 
@@ -253,113 +984,276 @@ func (m *Manager) SyncFiles(ctx context.Context, adapters []adapter.Adapter) err
 func (m *Manager) syncFile(ctx context.Context, file *adapter.File, source string) error {
 	filename := filepath.Base(file.Path)
 
+	// loadUnchangedContent found this version in the index
+	if file.Unchanged {
+		m.log().Debugf("File %s unchanged, skipping", file.Path)
+		m.recordAction(actionSkip)
+		return nil
+	}
+
 	// Skip files with empty content as OpenWebUI rejects them
 	if len(file.Content) == 0 {
-		logrus.Warnf("Skipping file %s: content is empty", file.Path)
+		m.log().Warnf("Skipping file %s: content is empty", file.Path)
+		m.recordAction(actionSkip)
 		return nil
 	}
 
+	key := m.fileIndexKey(source, file)
+	fileKnowledgeID := file.KnowledgeID
+	if fileKnowledgeID == "" {
+		fileKnowledgeID = m.knowledgeID
+	}
+
+	// Templates render per-file metadata, so templated files never share content
+	canDedup := m.dedupContent && (m.contentTemplate == nil || !isTemplatedContentType(file.ContentType))
+
+	rawContent := file.Content
+	file, err := m.applyContentTemplate(file, source, fileKnowledgeID)
+	if err != nil {
+		return err
+	}
+
 	// Find existing file by multiple criteria
 	var existing *FileMetadata
 	var exists bool
 	var matchReason string
-
-	// First, try to find by exact filename match
-	if existing, exists = m.fileIndex[filename]; exists {
-		matchReason = "filename"
-	} else {
-		// If not found by filename, search by hash to find potential matches
-		for _, metadata := range m.fileIndex {
-			if metadata.Hash == file.Hash {
-				existing = metadata
-				exists = true
-				matchReason = "hash"
-				break
+	existingKey := key
+
+	m.mu.Lock()
+	if existing, exists = m.fileIndex[key]; exists {
+		matchReason = "path"
+	} else if idKey, metadata := m.findByID(source, file.ID); metadata != nil {
+		// The adapter renamed the file, e.g. a renamed Slack channel
+		existing, exists, existingKey, matchReason = metadata, true, idKey, "id"
+	} else if legacy, ok := m.fileIndex[filename]; ok && (legacy.Source == "openwebui" || (legacy.Source == source && legacy.Path == file.Path)) {
+		// Entries initialized from OpenWebUI, and entries written by older versions, are keyed by filename
+		existing, exists, existingKey, matchReason = legacy, true, filename, "filename"
+	} else if m.runKeys != nil {
+		// If not found by path or filename, search by hash to find a renamed file. Only entries of
+		// the same source and knowledge base qualify, so identical files elsewhere aren't claimed,
+		// and only entries of files this run didn't fetch, so a copy of a file isn't taken for
+		// its rename. Outside a run, the fetched files aren't known and nothing is matched.
+		for indexKey, metadata := range m.fileIndex {
+			if metadata.Hash != file.Hash || (metadata.Source != source && metadata.Source != "openwebui") || m.runKeys[indexKey] {
+				continue
+			}
+			knowledgeID := metadata.KnowledgeID
+			if knowledgeID == "" {
+				knowledgeID = m.knowledgeID
 			}
+			if knowledgeID != fileKnowledgeID {
+				continue
+			}
+			existing, exists, existingKey, matchReason = metadata, true, indexKey, "hash"
+			break
+		}
+	}
+	if exists {
+		// Work on a copy so concurrent index updates don't race with the checks below
+		snapshot := *existing
+		existing = &snapshot
+	}
+	m.mu.Unlock()
+
+	// existingKnowledgeID is the knowledge base the existing file was synced to
+	var existingKnowledgeID string
+	if exists {
+		existingKnowledgeID = existing.KnowledgeID
+		if existingKnowledgeID == "" {
+			existingKnowledgeID = m.knowledgeID
 		}
 	}
 
 	if exists {
-		logrus.Debugf("Found existing file %s by %s (existing: %s, new: %s)", filename, matchReason, existing.Path, file.Path)
+		m.log().Debugf("Found existing file %s by %s (existing: %s, new: %s)", filename, matchReason, existing.Path, file.Path)
 
 
 		// Check if it's the same content (but only for files from the same source type)
-		// Files from "openwebui" have file IDs as hashes, not content hashes, so we can't compare them
-		if existing.Source != "openwebui" && existing.Hash == file.Hash {
-			logrus.Debugf("File %s unchanged, skipping", file.Path)
+		// Files from "openwebui" have file IDs as hashes, not content hashes, so we can't compare them.
+		// Unchanged content moved to another knowledge base still needs to be uploaded there.
+		if existing.Source != "openwebui" && existing.Hash == file.Hash && existingKnowledgeID == fileKnowledgeID {
+			m.log().Debugf("File %s unchanged, skipping", file.Path)
+			if !m.DryRun {
+				m.mu.Lock()
+				// Entries written before the adapter reported IDs learn them here, so later renames are detected
+				if metadata := m.fileIndex[existingKey]; metadata != nil && file.ID != "" {
+					metadata.ID = file.ID
+				}
+				// A file renamed without changing its content keeps its upload under the new key,
+				// so orphan cleanup doesn't delete it
+				m.moveIndexEntry(existingKey, key)
+				if metadata := m.fileIndex[key]; metadata != nil {
+					metadata.Path = file.Path
+				}
+				m.mu.Unlock()
+			}
+			m.recordAction(actionSkip)
 			return nil
 		}
 		if existing.Source != "openwebui" && existing.Hash != file.Hash {
-			logrus.Infof("File %s has changed, updating", file.Path)
+			if m.editedInOpenWebUI(existing) {
+				if m.conflictStrategy == conflictSkip {
+					m.log().Warnf("Conflict: file %s (ID: %s) was edited in OpenWebUI since it was last synced, keeping the edit and skipping the change from %s", file.Path, existing.FileID, source)
+					m.recordAction(actionSkip)
+					return nil
+				}
+				m.log().Warnf("Conflict: file %s (ID: %s) was edited in OpenWebUI since it was last synced, overwriting the edit with the change from %s", file.Path, existing.FileID, source)
+			}
+			m.log().Infof("File %s has changed, updating", file.Path)
 		}
 	}
 
-	if exists {
-		// Check if the file is already in the correct knowledge base
-		fileKnowledgeID := file.KnowledgeID
-		if fileKnowledgeID == "" {
-			fileKnowledgeID = m.knowledgeID
-		}
+	// replacedFileID is the old file object to delete after a successful re-upload
+	var replacedFileID string
 
-		existingKnowledgeID := existing.KnowledgeID
-		if existingKnowledgeID == "" {
-			existingKnowledgeID = m.knowledgeID
-		}
+	// replacedKey is the index entry superseded by this file, when it was keyed differently
+	var replacedKey string
+
+	// movedFromKnowledgeID is the knowledge base to remove the replaced file from once this file
+	// was added to its new one
+	var movedFromKnowledgeID string
 
+	if exists {
 		// If the file exists in the same knowledge base, check if it needs updating
 		if existingKnowledgeID == fileKnowledgeID {
+			// OpenWebUI reports the SHA-256 of the stored content. When it matches, adopt the
+			// existing file instead of re-uploading so later runs can skip on the adapter hash.
+			if existing.Source == "openwebui" && existing.FileID != "" && matchReason == "filename" && existing.Hash == GetFileHash(file.Content) {
+				m.log().Debugf("File %s matches content already in OpenWebUI (ID: %s), skipping upload", file.Path, existing.FileID)
+				if !m.DryRun {
+					m.mu.Lock()
+					delete(m.fileIndex, existingKey)
+					m.fileIndex[key] = &FileMetadata{
+						Path:        file.Path,
+						Hash:        file.Hash,
+						FileID:      existing.FileID,
+						Source:      source,
+						KnowledgeID: fileKnowledgeID,
+						SyncedAt:    time.Now(),
+						Modified:    file.Modified,
+						ID:          file.ID,
+						RemoteHash:  existing.Hash,
+					}
+					m.mu.Unlock()
+				}
+				m.recordAction(actionSkip)
+				return nil
+			}
+
 			// For files from OpenWebUI (source: "openwebui"), or entries without a file ID,
 			// we should not skip on hash equality because remote state may have changed.
 			if existing.Source == "openwebui" || existing.FileID == "" {
-				logrus.Debugf("Existing entry came from OpenWebUI or missing file ID; proceeding to upload to ensure consistency")
+				m.log().Debugf("Existing entry came from OpenWebUI or missing file ID; proceeding to upload to ensure consistency")
 			} else {
 				// For files we previously uploaded (adapter source), allow hash-based skip
 				if existing.Hash == file.Hash {
-					logrus.Debugf("File %s unchanged (hash match for adapter source), skipping upload", file.Path)
+					m.log().Debugf("File %s unchanged (hash match for adapter source), skipping upload", file.Path)
+					m.recordAction(actionSkip)
 					return nil
 				}
-				logrus.Infof("File %s has changed, updating", file.Path)
+				m.log().Infof("File %s has changed, updating", file.Path)
 			}
 
-			// Remove old file from knowledge and delete the file if knowledge ID is set
-			if fileKnowledgeID != "" && existing.FileID != "" {
-				logrus.Debugf("Removing old file %s from knowledge %s", existing.FileID, fileKnowledgeID)
-				if err := m.openwebuiClient.RemoveFileFromKnowledge(ctx, fileKnowledgeID, existing.FileID); err != nil {
-					logrus.Warnf("Failed to remove old file from knowledge: %v", err)
-					// Continue with upload even if removal fails
-				} else {
-					logrus.Debugf("Successfully removed old file from knowledge")
+			if m.DryRun {
+				m.log().Infof("[dry-run] Would update file %s in knowledge %s (hash %s -> %s)", file.Path, m.knowledgeLabel(ctx, fileKnowledgeID), existing.Hash, file.Hash)
+				m.recordAction(actionUpdate)
+				return nil
+			}
+
+			if err := m.saveSnapshot(source, file, rawContent); err != nil {
+				return err
+			}
+
+			if existingKey != key {
+				replacedKey = existingKey
+			}
+
+			// A file linked to other index entries by sync.dedup_content must keep its content
+			// and stay in the knowledge bases they use
+			m.mu.Lock()
+			otherKeys := map[string]bool{key: true, existingKey: true}
+			shared := m.fileIDInUse(existing.FileID, "", otherKeys)
+			sharedInKnowledge := m.fileIDInUse(existing.FileID, fileKnowledgeID, otherKeys)
+			m.mu.Unlock()
+
+			// Files we uploaded ourselves are updated in place so the knowledge base never lacks them.
+			// If OpenWebUI can't update the file, fall back to removing it and uploading a new one.
+			// Renamed files are re-uploaded, since an in-place update keeps the old filename, and so
+			// are binary files, since the update sends the content as text.
+			if existing.Source != "openwebui" && existing.FileID != "" && filepath.Base(existing.Path) == filename && !shared && updatableInPlace(file) {
+				err := m.updateFileInPlace(ctx, file, source, key, replacedKey, existing.FileID, fileKnowledgeID)
+				if err == nil {
+					return nil
 				}
+				m.log().Warnf("Failed to update file %s in place, re-uploading it: %v", file.Path, err)
+			}
 
-				// Delete the actual file from OpenWebUI to prevent filename conflicts
-				logrus.Debugf("Deleting old file %s from OpenWebUI", existing.FileID)
-				if err := m.openwebuiClient.DeleteFile(ctx, existing.FileID); err != nil {
-					logrus.Warnf("Failed to delete old file from OpenWebUI: %v", err)
-					// Continue with upload even if deletion fails
+			// Remove old file from knowledge if knowledge ID is set; the file itself is
+			// deleted once its replacement has been uploaded
+			if fileKnowledgeID != "" && existing.FileID != "" {
+				if sharedInKnowledge {
+					m.log().Debugf("Keeping old file %s in knowledge %s - still linked to another file", existing.FileID, m.knowledgeLabel(ctx, fileKnowledgeID))
 				} else {
-					logrus.Debugf("Successfully deleted old file from OpenWebUI")
+					m.log().Debugf("Removing old file %s from knowledge %s", existing.FileID, m.knowledgeLabel(ctx, fileKnowledgeID))
+					if err := m.removeFileFromKnowledge(ctx, fileKnowledgeID, existing.FileID); err != nil {
+						m.log().Warnf("Failed to remove old file from knowledge: %v", err)
+						// Continue with upload even if removal fails
+					} else {
+						m.log().Debugf("Successfully removed old file from knowledge")
+					}
+				}
+				if !shared {
+					replacedFileID = existing.FileID
 				}
 			}
 		} else {
 			// File exists in a different knowledge base, we need to upload it to the new one
-			logrus.Debugf("File %s exists in different knowledge base (%s -> %s), uploading to new knowledge base", file.Path, existingKnowledgeID, fileKnowledgeID)
+			m.log().Debugf("File %s exists in different knowledge base (%s -> %s), uploading to new knowledge base", file.Path, existingKnowledgeID, fileKnowledgeID)
+
+			// The same item, matched by its ID, moved to another knowledge base, e.g. after its
+			// mapping changed, so its file leaves the old one. Files at another path are cleaned
+			// up as orphans instead.
+			if matchReason == "id" && existing.Source != "openwebui" && !m.DryRun {
+				replacedKey = existingKey
+				if existing.FileID != "" {
+					movedFromKnowledgeID = existingKnowledgeID
+				}
+			}
 		}
 	}
 
-	// Save file to local storage
-	localPath := filepath.Join(m.storagePath, "files", source, file.Path)
-	if err := m.saveFileLocally(localPath, file.Content); err != nil {
-		return fmt.Errorf("failed to save file locally: %w", err)
+	// With sync.dedup_content, content already uploaded for another file is linked to this
+	// file's knowledge base instead of being uploaded again
+	var duplicate *FileMetadata
+	if canDedup {
+		m.mu.Lock()
+		duplicate = m.findByContent(file.Hash, fileKnowledgeID)
+		m.mu.Unlock()
 	}
 
-	// Upload to OpenWebUI
-	logrus.Debugf("Starting file upload to OpenWebUI for: %s", file.Path)
-	uploadedFile, err := m.openwebuiClient.UploadFile(ctx, filepath.Base(file.Path), file.Content)
-	if err != nil {
-		return fmt.Errorf("failed to upload file to OpenWebUI: %w", err)
+	if m.DryRun {
+		knowledgeID := file.KnowledgeID
+		if knowledgeID == "" {
+			knowledgeID = m.knowledgeID
+		}
+		if duplicate != nil {
+			m.log().Infof("[dry-run] Would link file %s to knowledge %s using the content of %s (ID: %s)", file.Path, m.knowledgeLabel(ctx, knowledgeID), duplicate.Path, duplicate.FileID)
+			m.recordAction(actionLink)
+			return nil
+		}
+		m.log().Infof("[dry-run] Would upload file %s to knowledge %s (hash %s)", file.Path, m.knowledgeLabel(ctx, knowledgeID), file.Hash)
+		m.recordAction(actionUpload)
+		return nil
 	}
 
-	logrus.Debugf("File uploaded successfully: ID=%s, Filename=%s", uploadedFile.ID, uploadedFile.Filename)
+	if err := m.saveSnapshot(source, file, rawContent); err != nil {
+		return err
+	}
+
+	// Save a copy of the file to the storage
+	if err := m.saveFileLocally(source, file.Path, file.Content); err != nil {
+		return fmt.Errorf("failed to save file locally: %w", err)
+	}
 
 	// Add to knowledge if knowledge ID is set (use file's knowledge ID if available, otherwise manager's)
 	knowledgeID := file.KnowledgeID
@@ -367,52 +1261,281 @@ func (m *Manager) syncFile(ctx context.Context, file *adapter.File, source strin
 		knowledgeID = m.knowledgeID
 	}
 
-	if knowledgeID != "" {
-		logrus.Debugf("Adding file %s to knowledge %s", uploadedFile.ID, knowledgeID)
-		if err := m.openwebuiClient.AddFileToKnowledge(ctx, knowledgeID, uploadedFile.ID); err != nil {
-			logrus.Errorf("Failed to add file to knowledge: %v", err)
+	var fileID, remoteHash string
+	var reused bool
+	if duplicate != nil {
+		fileID, remoteHash = duplicate.FileID, duplicate.RemoteHash
+		m.log().Infof("File %s has the same content as %s, linking file %s instead of uploading it", file.Path, duplicate.Path, fileID)
+	} else {
+		// Upload to OpenWebUI
+		m.log().Debugf("Starting file upload to OpenWebUI for: %s", file.Path)
+		fileID, remoteHash, reused, err = m.uploadFile(ctx, file, knowledgeID)
+		if err != nil {
+			return fmt.Errorf("failed to upload file to OpenWebUI: %w", err)
+		}
+
+		if reused {
+			m.log().Infof("File %s was already uploaded for another knowledge base in this run, reusing file %s", file.Path, fileID)
+		} else {
+			m.log().Debugf("File uploaded successfully: ID=%s, Filename=%s", fileID, filename)
+		}
+	}
+
+	if knowledgeID != "" && duplicate != nil && duplicate.KnowledgeID == knowledgeID {
+		m.log().Debugf("File %s is already in knowledge %s", fileID, m.knowledgeLabel(ctx, knowledgeID))
+	} else if knowledgeID != "" {
+		m.log().Debugf("Adding file %s to knowledge %s", fileID, m.knowledgeLabel(ctx, knowledgeID))
+		if err := m.addFileToKnowledge(ctx, knowledgeID, fileID); err != nil {
+			m.log().Errorf("Failed to add file to knowledge: %v", err)
 			return fmt.Errorf("failed to add file to knowledge: %w", err)
 		}
-		logrus.Debugf("File successfully added to knowledge")
+		m.log().Debugf("File successfully added to knowledge")
 	} else {
-		logrus.Warnf("No knowledge ID set, file uploaded but not added to any knowledge base")
+		m.log().Warnf("No knowledge ID set, file uploaded but not added to any knowledge base")
 	}
 
-	// Update file index - only if file doesn't exist or was updated
-	if !exists || existing.Hash != file.Hash {
-		// Use filename as the key to match OpenWebUI behavior
-		key := filepath.Base(file.Path)
+	// Remove the file of an item that moved from its old knowledge base, unless another entry
+	// still links it there
+	if movedFromKnowledgeID != "" {
+		m.mu.Lock()
+		otherKeys := map[string]bool{key: true, existingKey: true}
+		sharedInKnowledge := m.fileIDInUse(existing.FileID, movedFromKnowledgeID, otherKeys)
+		shared := m.fileIDInUse(existing.FileID, "", otherKeys)
+		m.mu.Unlock()
+		if !sharedInKnowledge {
+			m.log().Infof("File %s moved from knowledge %s, removing it there", file.Path, m.knowledgeLabel(ctx, movedFromKnowledgeID))
+			if err := m.removeFileFromKnowledge(ctx, movedFromKnowledgeID, existing.FileID); err != nil {
+				m.log().Warnf("Failed to remove moved file from knowledge: %v", err)
+			}
+		}
+		if !shared {
+			replacedFileID = existing.FileID
+		}
+	}
 
-		// If we found an existing file by hash but with different filename, update the key
-		if exists && matchReason == "hash" && existing.Path != file.Path {
-			// Remove the old entry and add with new key
-			delete(m.fileIndex, filepath.Base(existing.Path))
-			logrus.Debugf("Updating file key from %s to %s", filepath.Base(existing.Path), key)
+	// Delete the replaced file object so changed content doesn't leak storage in OpenWebUI
+	if replacedFileID != "" && replacedFileID != fileID {
+		m.log().Debugf("Deleting old file %s from OpenWebUI", replacedFileID)
+		if err := m.openwebuiClient.DeleteFile(ctx, replacedFileID); err != nil {
+			m.log().Warnf("Failed to delete old file from OpenWebUI: %v", err)
+		} else {
+			m.log().Debugf("Successfully deleted old file from OpenWebUI")
 		}
+	}
+
+	// Update file index with the adapter hash and source so the next run can skip unchanged
+	// content, including entries that were initialized from OpenWebUI
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Drop the entry this file replaced, e.g. a renamed file or one keyed by filename
+	if replacedKey != "" {
+		delete(m.fileIndex, replacedKey)
+		m.log().Debugf("Updating file key from %s to %s", replacedKey, key)
+	}
+
+	m.fileIndex[key] = &FileMetadata{
+		Path:        file.Path, // Store full path in metadata
+		Hash:        file.Hash,
+		FileID:      fileID,
+		Source:      source,
+		KnowledgeID: knowledgeID,
+		SyncedAt:    time.Now(),
+		Modified:    file.Modified,
+		ID:          file.ID,
+		RemoteHash:  remoteHash,
+	}
+	m.log().Debugf("Updated file index with file: %s (ID: %s, key: %s)", file.Path, fileID, key)
+
+	m.log().Debugf("File index now contains %d files", len(m.fileIndex))
+
+	switch {
+	case duplicate != nil || reused:
+		m.recordAction(actionLink)
+	case exists && existing.KnowledgeID == knowledgeID:
+		m.recordAction(actionUpdate)
+	default:
+		m.recordAction(actionUpload)
+	}
+	if duplicate == nil && !reused {
+		metrics.FilesUploaded.WithLabelValues(source).Inc()
+	}
+
+	m.log().Infof("Successfully synced file: %s", file.Path)
+	return nil
+}
 
-		m.fileIndex[key] = &FileMetadata{
-			Path:        file.Path, // Store full path in metadata
-			Hash:        file.Hash,
-			FileID:      uploadedFile.ID,
-			Source:      source,
-			KnowledgeID: knowledgeID,
-			SyncedAt:    time.Now(),
-			Modified:    file.Modified,
+// addFileToKnowledge adds an uploaded file to a knowledge base, waiting until at least
+// knowledgeAddDelay has passed since the previous addition so an initial sync of thousands of
+// files doesn't overwhelm OpenWebUI
+func (m *Manager) addFileToKnowledge(ctx context.Context, knowledgeID, fileID string) error {
+	if m.knowledgeAddDelay > 0 {
+		m.knowledgeAddMu.Lock()
+		defer m.knowledgeAddMu.Unlock()
+
+		if wait := time.Until(m.lastKnowledgeAdd.Add(m.knowledgeAddDelay)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
 		}
-		logrus.Debugf("Updated file index with file: %s (ID: %s, key: %s)", file.Path, uploadedFile.ID, key)
-	} else {
-		logrus.Debugf("File %s already exists and unchanged, keeping existing metadata", file.Path)
+		defer func() { m.lastKnowledgeAdd = time.Now() }()
+	}
+
+	defer m.lockKnowledge(knowledgeID)()
+	return m.openwebuiClient.AddFileToKnowledge(ctx, knowledgeID, fileID)
+}
+
+// removeFileFromKnowledge removes a file from a knowledge base, serialized with the other
+// changes to that knowledge base
+func (m *Manager) removeFileFromKnowledge(ctx context.Context, knowledgeID, fileID string) error {
+	defer m.lockKnowledge(knowledgeID)()
+	return m.openwebuiClient.RemoveFileFromKnowledge(ctx, knowledgeID, fileID)
+}
+
+// lockKnowledge locks a knowledge base's file list and returns the unlock function. OpenWebUI
+// updates the list read-modify-write, so concurrent additions and removals on the same
+// knowledge base could drop each other's changes; different knowledge bases proceed in parallel.
+func (m *Manager) lockKnowledge(knowledgeID string) func() {
+	m.knowledgeLocksMu.Lock()
+	if m.knowledgeLocks == nil {
+		m.knowledgeLocks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := m.knowledgeLocks[knowledgeID]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.knowledgeLocks[knowledgeID] = lock
+	}
+	m.knowledgeLocksMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// updatableInPlace reports whether a file's content can be sent as text to update it in place:
+// its content type (detected from the extension if the adapter doesn't set one) must be
+// text, JSON, YAML or XML, and its content valid UTF-8
+func updatableInPlace(file *adapter.File) bool {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = openwebui.ContentTypeForFilename(file.Path)
 	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	switch {
+	case strings.HasPrefix(contentType, "text/"),
+		contentType == "application/json", strings.HasSuffix(contentType, "+json"),
+		contentType == "application/yaml", contentType == "application/x-yaml",
+		contentType == "application/xml", strings.HasSuffix(contentType, "+xml"):
+		return utf8.Valid(file.Content)
+	}
+	return false
+}
 
-	logrus.Debugf("File index now contains %d files", len(m.fileIndex))
+// updateFileInPlace replaces the content of an already uploaded file, keeping its file ID
+// and knowledge membership, and updates the file index entry at key (dropping replacedKey)
+func (m *Manager) updateFileInPlace(ctx context.Context, file *adapter.File, source, key, replacedKey, fileID, knowledgeID string) error {
+	if err := m.saveFileLocally(source, file.Path, file.Content); err != nil {
+		return fmt.Errorf("failed to save file locally: %w", err)
+	}
 
-	logrus.Infof("Successfully synced file: %s", file.Path)
+	updated, err := m.openwebuiClient.UpdateFileContent(ctx, fileID, filepath.Base(file.Path), file.Content)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if replacedKey != "" {
+		delete(m.fileIndex, replacedKey)
+	}
+	m.fileIndex[key] = &FileMetadata{
+		Path:        file.Path,
+		Hash:        file.Hash,
+		FileID:      fileID,
+		Source:      source,
+		KnowledgeID: knowledgeID,
+		SyncedAt:    time.Now(),
+		Modified:    file.Modified,
+		ID:          file.ID,
+		RemoteHash:  updated.Hash,
+	}
+	// The hash read at startup no longer describes the file
+	delete(m.remoteHashes, fileID)
+	m.mu.Unlock()
+
+	m.recordAction(actionUpdate)
+	metrics.FilesUploaded.WithLabelValues(source).Inc()
+
+	m.log().Infof("Successfully updated file in place: %s (ID: %s)", file.Path, fileID)
 	return nil
 }
 
+// conflictSkip is the sync.conflict_strategy that keeps edits made in OpenWebUI instead of
+// overwriting them with changes from the source
+const conflictSkip = "skip"
+
+// editedInOpenWebUI reports whether the file of an index entry was edited in OpenWebUI since
+// it was last synced: the hash OpenWebUI reported at startup differs from the one recorded when
+// the file was synced. Files without both hashes are never reported.
+func (m *Manager) editedInOpenWebUI(metadata *FileMetadata) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	remote := m.remoteHashes[metadata.FileID]
+	return metadata.RemoteHash != "" && remote != "" && remote != metadata.RemoteHash
+}
+
+// findByContent returns a copy of the index entry of an uploaded adapter file with the given
+// content hash, preferring one already in knowledgeID, or nil. The caller holds m.mu.
+func (m *Manager) findByContent(hash, knowledgeID string) *FileMetadata {
+	var found *FileMetadata
+	for _, metadata := range m.fileIndex {
+		// Entries initialized from OpenWebUI hold file IDs instead of content hashes
+		if metadata.Source == "openwebui" || metadata.FileID == "" || metadata.Hash != hash {
+			continue
+		}
+		entryKnowledgeID := metadata.KnowledgeID
+		if entryKnowledgeID == "" {
+			entryKnowledgeID = m.knowledgeID
+		}
+		if found == nil || entryKnowledgeID == knowledgeID {
+			match := *metadata
+			match.KnowledgeID = entryKnowledgeID
+			found = &match
+		}
+		if entryKnowledgeID == knowledgeID {
+			break
+		}
+	}
+	return found
+}
+
+// fileIDInUse reports whether an index entry outside excludeKeys maps to fileID in
+// knowledgeID, or in any knowledge base when knowledgeID is empty. With sync.dedup_content
+// several entries can share one uploaded file. The caller holds m.mu or runs no workers.
+func (m *Manager) fileIDInUse(fileID, knowledgeID string, excludeKeys map[string]bool) bool {
+	if fileID == "" {
+		return false
+	}
+	for fileKey, metadata := range m.fileIndex {
+		if excludeKeys[fileKey] || metadata.FileID != fileID {
+			continue
+		}
+		entryKnowledgeID := metadata.KnowledgeID
+		if entryKnowledgeID == "" {
+			entryKnowledgeID = m.knowledgeID
+		}
+		if knowledgeID == "" || entryKnowledgeID == knowledgeID {
+			return true
+		}
+	}
+	return false
+}
+
 // cleanupOrphanedFiles removes files from OpenWebUI that are no longer present in repositories
-func (m *Manager) cleanupOrphanedFiles(ctx context.Context, currentFiles map[string]bool) error {
-	logrus.Debugf("Checking for orphaned files...")
+func (m *Manager) cleanupOrphanedFiles(ctx context.Context, current *currentFiles) error {
+	m.log().Debugf("Checking for orphaned files...")
 
 	var orphanedFiles []string
 	for fileKey, metadata := range m.fileIndex {
@@ -422,24 +1545,53 @@ func (m *Manager) cleanupOrphanedFiles(ctx context.Context, currentFiles map[str
 			filename = filepath.Base(fileKey)
 		}
 
-		// A file is orphaned if:
-		// 1. It's not in the current files list by filename
-		// 2. It's from OpenWebUI (not from an adapter)
-		// 3. It has a valid file ID (can be removed)
-		if !currentFiles[filename] && metadata.Source == "openwebui" && metadata.FileID != "" {
+		// A file is orphaned if it has a valid file ID (can be removed) and either:
+		// 1. It's from OpenWebUI, in a knowledge base whose adapters all fetched successfully,
+		//    and not in the current files list by filename
+		// 2. It's from an adapter whose fetch returned every file this run, and wasn't among them
+		var orphaned bool
+		if metadata.Source == "openwebui" {
+			knowledgeID := metadata.KnowledgeID
+			if knowledgeID == "" {
+				knowledgeID = m.knowledgeID
+			}
+			orphaned = current.coversKnowledge(knowledgeID) && !current.filenames[filename]
+		} else {
+			// Entries written by older versions stay keyed by filename until they are re-keyed
+			seen := current.keys[fileKey] || (fileKey == filename && current.filenames[filename])
+			orphaned = current.complete[metadata.Source] && !seen
+		}
+
+		if orphaned && metadata.FileID != "" {
 			orphanedFiles = append(orphanedFiles, fileKey)
-			logrus.Debugf("Marking file as orphaned: %s (filename: %s, source: %s)", fileKey, filename, metadata.Source)
-		} else if !currentFiles[filename] {
-			logrus.Debugf("File not in current files but keeping: %s (filename: %s, source: %s, fileID: %s)", fileKey, filename, metadata.Source, metadata.FileID)
+			m.log().Debugf("Marking file as orphaned: %s (filename: %s, source: %s)", fileKey, filename, metadata.Source)
+		} else if !current.keys[fileKey] && !current.filenames[filename] {
+			m.log().Debugf("File not in current files but keeping: %s (filename: %s, source: %s, fileID: %s)", fileKey, filename, metadata.Source, metadata.FileID)
 		}
 	}
 
 	if len(orphanedFiles) == 0 {
-		logrus.Debugf("No orphaned files found")
+		m.log().Debugf("No orphaned files found")
 		return nil
 	}
 
-	logrus.Infof("Found %d orphaned files to remove", len(orphanedFiles))
+	m.log().Infof("Found %d orphaned files to remove", len(orphanedFiles))
+
+	// Collect which knowledge bases still reference each file so shared files are not deleted
+	isOrphaned := make(map[string]bool, len(orphanedFiles))
+	for _, fileKey := range orphanedFiles {
+		isOrphaned[fileKey] = true
+	}
+	references := m.collectFileReferences(ctx, isOrphaned)
+
+	if m.DryRun {
+		for _, fileKey := range orphanedFiles {
+			metadata := m.fileIndex[fileKey]
+			m.log().Infof("[dry-run] Would remove orphaned file %s (ID: %s, hash %s) from knowledge %s and delete it", metadata.Path, metadata.FileID, metadata.Hash, m.knowledgeLabel(ctx, metadata.KnowledgeID))
+			m.recordAction(actionDelete)
+		}
+		return nil
+	}
 
 	for _, fileKey := range orphanedFiles {
 		metadata := m.fileIndex[fileKey]
@@ -450,49 +1602,204 @@ func (m *Manager) cleanupOrphanedFiles(ctx context.Context, currentFiles map[str
 			knowledgeID = m.knowledgeID
 		}
 
-		if knowledgeID != "" && metadata.FileID != "" {
-			logrus.Debugf("Removing orphaned file %s (ID: %s) from knowledge %s", metadata.Path, metadata.FileID, knowledgeID)
-			if err := m.openwebuiClient.RemoveFileFromKnowledge(ctx, knowledgeID, metadata.FileID); err != nil {
-				logrus.Warnf("Failed to remove orphaned file from knowledge: %v", err)
+		if knowledgeID != "" && metadata.FileID != "" && m.fileIDInUse(metadata.FileID, knowledgeID, isOrphaned) {
+			m.log().Debugf("Keeping orphaned file %s (ID: %s) in knowledge %s - still linked to another file", metadata.Path, metadata.FileID, m.knowledgeLabel(ctx, knowledgeID))
+		} else if knowledgeID != "" && metadata.FileID != "" {
+			m.log().Debugf("Removing orphaned file %s (ID: %s) from knowledge %s", metadata.Path, metadata.FileID, m.knowledgeLabel(ctx, knowledgeID))
+			if err := m.removeFileFromKnowledge(ctx, knowledgeID, metadata.FileID); err != nil {
+				m.log().Warnf("Failed to remove orphaned file from knowledge: %v", err)
 				// Continue with other files even if one fails
 			} else {
-				logrus.Debugf("Successfully removed orphaned file from knowledge")
+				m.log().Debugf("Successfully removed orphaned file from knowledge")
+				delete(references[metadata.FileID], knowledgeID)
+
+				// Only delete the underlying file once no other knowledge base uses it
+				if len(references[metadata.FileID]) > 0 {
+					m.log().Debugf("Keeping orphaned file %s (ID: %s) - still referenced by %d other knowledge base(s)", metadata.Path, metadata.FileID, len(references[metadata.FileID]))
+				} else {
+					m.log().Debugf("Deleting orphaned file %s from OpenWebUI", metadata.FileID)
+					if err := m.openwebuiClient.DeleteFile(ctx, metadata.FileID); err != nil {
+						m.log().Warnf("Failed to delete orphaned file from OpenWebUI: %v", err)
+					} else {
+						m.log().Debugf("Successfully deleted orphaned file from OpenWebUI")
+					}
+				}
 			}
 		} else {
-			logrus.Debugf("Skipping orphaned file %s - no knowledge ID or file ID available", metadata.Path)
+			m.log().Debugf("Skipping orphaned file %s - no knowledge ID or file ID available", metadata.Path)
 		}
 
 		// Remove from file index
 		delete(m.fileIndex, fileKey)
-		logrus.Infof("Removed orphaned file: %s", metadata.Path)
+		m.recordAction(actionDelete)
+		metrics.FilesRemoved.Inc()
+		m.log().Infof("Removed orphaned file: %s", metadata.Path)
 	}
 
 	return nil
 }
 
-// saveFileLocally saves a file to the local storage
-func (m *Manager) saveFileLocally(path string, content []byte) error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// PurgeSource removes every indexed file that was synced by the named source: each file is
+// removed from its knowledge base, deleted from OpenWebUI once no other knowledge base uses it,
+// and dropped from the index. Files that fail to be removed stay in the index, so the purge can
+// be re-run safely; it returns the number of purged files, including those purged from the
+// additional targets.
+func (m *Manager) PurgeSource(ctx context.Context, source string) (int, error) {
+	purged, err := m.purgeSource(ctx, source)
+	targetsErr := m.eachTarget(func(target *Manager) error {
+		targetPurged, err := target.PurgeSource(ctx, source)
+		purged += targetPurged
+		return err
+	})
+	return purged, errors.Join(err, targetsErr)
+}
+
+// purgeSource purges the files of source from this manager's OpenWebUI instance
+func (m *Manager) purgeSource(ctx context.Context, source string) (int, error) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purgeKeys := make(map[string]bool)
+	for fileKey, metadata := range m.fileIndex {
+		if metadata.Source == source {
+			purgeKeys[fileKey] = true
+		}
+	}
+
+	if len(purgeKeys) == 0 {
+		m.log().Infof("No files from source %s found in the file index", source)
+		return 0, nil
 	}
 
-	// Write file
-	if err := os.WriteFile(path, content, 0644); err != nil {
+	m.log().Infof("Purging %d files from source %s", len(purgeKeys), source)
+
+	if m.DryRun {
+		for fileKey := range purgeKeys {
+			metadata := m.fileIndex[fileKey]
+			m.log().Infof("[dry-run] Would remove file %s (ID: %s) from knowledge %s and delete it", metadata.Path, metadata.FileID, m.knowledgeLabel(ctx, metadata.KnowledgeID))
+		}
+		return len(purgeKeys), nil
+	}
+
+	references := m.collectFileReferences(ctx, purgeKeys)
+
+	purged, failed := 0, 0
+	for fileKey := range purgeKeys {
+		metadata := m.fileIndex[fileKey]
+
+		knowledgeID := metadata.KnowledgeID
+		if knowledgeID == "" {
+			knowledgeID = m.knowledgeID
+		}
+
+		if metadata.FileID != "" && m.fileIDInUse(metadata.FileID, knowledgeID, purgeKeys) {
+			m.log().Debugf("Keeping file %s (ID: %s) in knowledge %s - still linked to another file", metadata.Path, metadata.FileID, m.knowledgeLabel(ctx, knowledgeID))
+		} else if metadata.FileID != "" {
+			if knowledgeID != "" {
+				if err := m.removeFileFromKnowledge(ctx, knowledgeID, metadata.FileID); err != nil {
+					m.log().Warnf("Failed to remove file %s from knowledge %s: %v", metadata.Path, m.knowledgeLabel(ctx, knowledgeID), err)
+					failed++
+					continue
+				}
+				delete(references[metadata.FileID], knowledgeID)
+			}
+
+			// Only delete the underlying file once no other knowledge base uses it
+			if len(references[metadata.FileID]) > 0 {
+				m.log().Debugf("Keeping file %s (ID: %s) - still referenced by %d other knowledge base(s)", metadata.Path, metadata.FileID, len(references[metadata.FileID]))
+			} else if err := m.openwebuiClient.DeleteFile(ctx, metadata.FileID); err != nil {
+				m.log().Warnf("Failed to delete file %s from OpenWebUI: %v", metadata.Path, err)
+				failed++
+				continue
+			}
+		}
+
+		delete(m.fileIndex, fileKey)
+		purged++
+		metrics.FilesRemoved.Inc()
+		m.log().Infof("Purged file: %s", metadata.Path)
+	}
+
+	if err := m.saveFileIndex(); err != nil {
+		return purged, fmt.Errorf("failed to save file index: %w", err)
+	}
+
+	if failed > 0 {
+		return purged, fmt.Errorf("failed to purge %d of %d files from source %s", failed, len(purgeKeys), source)
+	}
+	return purged, nil
+}
+
+// collectFileReferences maps file IDs to the knowledge bases that reference them.
+// References come from the remote knowledge listing (best effort) and from index
+// entries that are not about to be removed.
+func (m *Manager) collectFileReferences(ctx context.Context, excludeKeys map[string]bool) map[string]map[string]bool {
+	references := make(map[string]map[string]bool)
+	addReference := func(fileID, knowledgeID string) {
+		if fileID == "" || knowledgeID == "" {
+			return
+		}
+		if references[fileID] == nil {
+			references[fileID] = make(map[string]bool)
+		}
+		references[fileID][knowledgeID] = true
+	}
+
+	knowledgeList, err := m.openwebuiClient.ListKnowledge(ctx)
+	if err != nil {
+		m.log().Warnf("Failed to list knowledge sources for reference check: %v", err)
+	} else {
+		for _, knowledge := range knowledgeList {
+			for _, file := range knowledge.Files {
+				addReference(file.ID, knowledge.ID)
+			}
+		}
+	}
+
+	for fileKey, metadata := range m.fileIndex {
+		if excludeKeys[fileKey] {
+			continue
+		}
+		knowledgeID := metadata.KnowledgeID
+		if knowledgeID == "" {
+			knowledgeID = m.knowledgeID
+		}
+		addReference(metadata.FileID, knowledgeID)
+	}
+
+	return references
+}
+
+// saveFileLocally saves a copy of a source file to the storage as files/<source>/<path>
+func (m *Manager) saveFileLocally(source, filePath string, content []byte) error {
+	key := path.Join("files", source, filepath.ToSlash(filePath))
+	if err := m.store.Write(key, content); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	return nil
+}
 
+// saveSnapshot stores the raw source content of a file version when snapshot history is enabled.
+// A version that falls back from an in-place update to an upload is only stored once. Files
+// are kept apart by their origin, like in the file index.
+func (m *Manager) saveSnapshot(source string, file *adapter.File, content []byte) error {
+	if m.snapshots == nil {
+		return nil
+	}
+	if err := m.snapshots.Save(fileOrigin(source, file), file.Path, content, m.log()); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
 	return nil
 }
 
-// loadFileIndex loads the file index from disk
+// loadFileIndex loads the file index from the storage
 func (m *Manager) loadFileIndex() error {
-	if _, err := os.Stat(m.indexPath); os.IsNotExist(err) {
+	data, err := m.store.Read(fileIndexFile)
+	if errors.Is(err, storage.ErrNotExist) {
 		return nil // Index doesn't exist yet
 	}
-
-	data, err := os.ReadFile(m.indexPath)
 	if err != nil {
 		return fmt.Errorf("failed to read file index: %w", err)
 	}
@@ -500,29 +1807,35 @@ func (m *Manager) loadFileIndex() error {
 	if err := json.Unmarshal(data, &m.fileIndex); err != nil {
 		return fmt.Errorf("failed to unmarshal file index: %w", err)
 	}
+	m.migrateIndexKeys()
 
 	return nil
 }
 
-// saveFileIndex saves the file index to disk
+// saveFileIndex saves the file index to the storage
 func (m *Manager) saveFileIndex() error {
-	logrus.Debugf("Saving file index to: %s", m.indexPath)
-	logrus.Debugf("File index contains %d files", len(m.fileIndex))
+	if m.DryRun {
+		m.log().Debugf("Dry run enabled: not writing file index")
+		return nil
+	}
+
+	m.log().Debugf("Saving file index")
+	m.log().Debugf("File index contains %d files", len(m.fileIndex))
 
 	data, err := json.MarshalIndent(m.fileIndex, "", "  ")
 	if err != nil {
-		logrus.Errorf("Failed to marshal file index: %v", err)
+		m.log().Errorf("Failed to marshal file index: %v", err)
 		return fmt.Errorf("failed to marshal file index: %w", err)
 	}
 
-	logrus.Debugf("File index JSON size: %d bytes", len(data))
+	m.log().Debugf("File index JSON size: %d bytes", len(data))
 
-	if err := os.WriteFile(m.indexPath, data, 0644); err != nil {
-		logrus.Errorf("Failed to write file index to %s: %v", m.indexPath, err)
+	if err := m.store.Write(fileIndexFile, data); err != nil {
+		m.log().Errorf("Failed to write file index: %v", err)
 		return fmt.Errorf("failed to write file index: %w", err)
 	}
 
-	logrus.Debugf("Successfully saved file index to: %s", m.indexPath)
+	m.log().Debugf("Successfully saved file index")
 	return nil
 }
 
@@ -1,18 +1,40 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/openwebui-content-sync/internal/adapter"
 	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/metrics"
 	"github.com/openwebui-content-sync/internal/mocks"
 	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
 )
 
+// newLocalStore returns a local storage below dir
+func newLocalStore(t *testing.T, dir string) storage.Storage {
+	t.Helper()
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	return store
+}
+
 func TestNewManager(t *testing.T) {
 	tempDir := t.TempDir()
 	defer os.RemoveAll(tempDir)
@@ -25,7 +47,11 @@ func TestNewManager(t *testing.T) {
 		Path: tempDir,
 	}
 
-	manager, err := NewManager(openwebuiConfig, storageConfig)
+	syncConfig := config.SyncConfig{
+		Concurrency: 4,
+	}
+
+	manager, err := NewManager(openwebuiConfig, storageConfig, syncConfig, config.HTTPConfig{})
 	if err != nil {
 		t.Fatalf("Failed to create manager: %v", err)
 	}
@@ -33,8 +59,11 @@ func TestNewManager(t *testing.T) {
 	if manager == nil {
 		t.Fatal("Expected manager to be created")
 	}
-	if manager.storagePath != tempDir {
-		t.Errorf("Expected storage path %s, got %s", tempDir, manager.storagePath)
+	if _, ok := manager.store.(*storage.Local); !ok {
+		t.Errorf("Expected local storage by default, got %T", manager.store)
+	}
+	if manager.concurrency != 4 {
+		t.Errorf("Expected concurrency 4, got %d", manager.concurrency)
 	}
 }
 
@@ -43,8 +72,8 @@ func TestManager_SetKnowledgeID(t *testing.T) {
 	defer os.RemoveAll(tempDir)
 
 	manager := &Manager{
-		storagePath: tempDir,
-		fileIndex:   make(map[string]*FileMetadata),
+		store:     newLocalStore(t, tempDir),
+		fileIndex: make(map[string]*FileMetadata),
 	}
 
 	knowledgeID := "test-knowledge-id"
@@ -60,7 +89,7 @@ func TestManager_syncFile_NewFile(t *testing.T) {
 	defer os.RemoveAll(tempDir)
 
 	mockClient := &mocks.MockOpenWebUIClient{
-		UploadFileFunc: func(ctx context.Context, filename string, content []byte) (*openwebui.File, error) {
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
 			return &openwebui.File{
 				ID:       "mock-file-id",
 				Filename: filename,
@@ -70,7 +99,7 @@ func TestManager_syncFile_NewFile(t *testing.T) {
 
 	manager := &Manager{
 		openwebuiClient: mockClient,
-		storagePath:     tempDir,
+		store:           newLocalStore(t, tempDir),
 		fileIndex:       make(map[string]*FileMetadata),
 	}
 
@@ -90,7 +119,7 @@ func TestManager_syncFile_NewFile(t *testing.T) {
 	}
 
 	// Check that file was added to index
-	fileKey := "new-file.md" // Now using filename as key
+	fileKey := "test-source/test/new-file.md" // Keyed by source, origin and path
 	if _, exists := manager.fileIndex[fileKey]; !exists {
 		t.Errorf("Expected file to be added to index")
 	}
@@ -109,13 +138,13 @@ func TestManager_syncFile_UnchangedFile(t *testing.T) {
 	mockClient := &mocks.MockOpenWebUIClient{}
 	manager := &Manager{
 		openwebuiClient: mockClient,
-		storagePath:     tempDir,
+		store:           newLocalStore(t, tempDir),
 		fileIndex:       make(map[string]*FileMetadata),
 	}
 
-	// Add file to index first
-	fileKey := "unchanged-file.md" // Now using filename as key
-	manager.fileIndex[fileKey] = &FileMetadata{
+	// Add file to index first, keyed by filename as older versions did
+	legacyKey := "unchanged-file.md"
+	manager.fileIndex[legacyKey] = &FileMetadata{
 		Path:     "unchanged-file.md",
 		Hash:     "same-hash",
 		FileID:   "existing-file-id",
@@ -141,8 +170,12 @@ func TestManager_syncFile_UnchangedFile(t *testing.T) {
 
 	// File should not be uploaded again (we can't easily test this without more complex mocking)
 	// But we can verify the file index wasn't updated with a new file ID
-	if manager.fileIndex[fileKey].FileID != "existing-file-id" {
-		t.Errorf("Expected file ID to remain unchanged")
+	entry := manager.fileIndex[manager.fileIndexKey("test-source", file)]
+	if entry == nil || entry.FileID != "existing-file-id" {
+		t.Errorf("Expected file ID to remain unchanged, got %+v", entry)
+	}
+	if _, exists := manager.fileIndex[legacyKey]; exists {
+		t.Errorf("Expected the entry keyed by filename to be re-keyed")
 	}
 }
 
@@ -151,13 +184,13 @@ func TestManager_saveFileLocally(t *testing.T) {
 	defer os.RemoveAll(tempDir)
 
 	manager := &Manager{
-		storagePath: tempDir,
+		store: newLocalStore(t, tempDir),
 	}
 
-	filePath := filepath.Join(tempDir, "test", "nested", "file.md")
+	filePath := filepath.Join(tempDir, "files", "test", "nested", "file.md")
 	content := []byte("# Test Content")
 
-	err := manager.saveFileLocally(filePath, content)
+	err := manager.saveFileLocally("test", filepath.Join("nested", "file.md"), content)
 	if err != nil {
 		t.Fatalf("Failed to save file locally: %v", err)
 	}
@@ -192,9 +225,8 @@ func TestManager_loadFileIndex(t *testing.T) {
 	defer os.RemoveAll(tempDir)
 
 	manager := &Manager{
-		storagePath: tempDir,
-		fileIndex:   make(map[string]*FileMetadata),
-		indexPath:   filepath.Join(tempDir, "file_index.json"),
+		store:     newLocalStore(t, tempDir),
+		fileIndex: make(map[string]*FileMetadata),
 	}
 
 	// Test loading non-existent index (should not error)
@@ -224,9 +256,8 @@ func TestManager_loadFileIndex(t *testing.T) {
 
 	// Create new manager and load index
 	newManager := &Manager{
-		storagePath: tempDir,
-		fileIndex:   make(map[string]*FileMetadata),
-		indexPath:   filepath.Join(tempDir, "file_index.json"),
+		store:     newLocalStore(t, tempDir),
+		fileIndex: make(map[string]*FileMetadata),
 	}
 
 	err = newManager.loadFileIndex()
@@ -243,3 +274,2009 @@ func TestManager_loadFileIndex(t *testing.T) {
 		t.Errorf("Expected file %s to be in index", fileKey)
 	}
 }
+
+func TestManager_SyncFiles_KnowledgeBaseChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		id       string
+		complete bool
+	}{
+		// e.g. a local file whose owui_knowledge_id changed with strip_front_matter enabled
+		{name: "same path", source: "local", complete: true},
+		// e.g. a Slack channel mapped to another knowledge base
+		{name: "same ID", source: "slack", id: "C1", complete: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads := 0
+			var added, removed, deleted []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploads++
+					return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+				},
+				AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					added = append(added, knowledgeID+"/"+fileID)
+					return nil
+				},
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					removed = append(removed, knowledgeID+"/"+fileID)
+					return nil
+				},
+				DeleteFileFunc: func(ctx context.Context, fileID string) error {
+					deleted = append(deleted, fileID)
+					return nil
+				},
+			}
+			knowledgeID := "kb-old"
+			mockAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return tt.source },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{{Path: "notes.md", Content: []byte("# Notes"), Hash: "hash-notes", KnowledgeID: knowledgeID, ID: tt.id}}, nil
+				},
+				FetchCompleteFunc: func() bool { return tt.complete },
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           storage.NewMemory(),
+				concurrency:     1,
+				fileIndex:       make(map[string]*FileMetadata),
+			}
+			for _, knowledgeID = range []string{"kb-old", "kb-new"} {
+				if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+					t.Fatalf("Sync to %s failed: %v", knowledgeID, err)
+				}
+			}
+
+			// The unchanged content is uploaded to the new knowledge base and leaves the old one
+			if uploads != 2 || !reflect.DeepEqual(added, []string{"kb-old/id-1", "kb-new/id-2"}) {
+				t.Errorf("Expected the file to be added to both knowledge bases in turn, got %d uploads, added %v", uploads, added)
+			}
+			if !reflect.DeepEqual(removed, []string{"kb-old/id-1"}) || !reflect.DeepEqual(deleted, []string{"id-1"}) {
+				t.Errorf("Expected the old file to be removed from kb-old and deleted, got removed %v, deleted %v", removed, deleted)
+			}
+			if len(manager.fileIndex) != 1 {
+				t.Errorf("Expected 1 index entry, got %v", manager.fileIndex)
+			}
+			if entry := manager.fileIndex[tt.source+"/notes.md@kb-new"]; entry == nil || entry.FileID != "id-2" || entry.KnowledgeID != "kb-new" {
+				t.Errorf("Expected the file to be indexed in kb-new, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestManager_loadFileIndex_MigratesKeys(t *testing.T) {
+	store := storage.NewMemory()
+	legacy := map[string]*FileMetadata{
+		"github/owner/repo/docs/a.md":    {Path: "docs/a.md", FileID: "file-a", Source: "github", KnowledgeID: "knowledge-1"},
+		"local/notes.md":                 {Path: "notes.md", FileID: "file-notes", Source: "local"},
+		"readme.md":                      {Path: "readme.md", FileID: "file-readme", Source: "openwebui", KnowledgeID: "knowledge-1"},
+		"confluence/page.md@knowledge-2": {Path: "page.md", FileID: "file-page", Source: "confluence", KnowledgeID: "knowledge-2"},
+	}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("Failed to encode index: %v", err)
+	}
+	if err := store.Write(fileIndexFile, data); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+
+	manager := &Manager{store: store, fileIndex: make(map[string]*FileMetadata)}
+	if err := manager.loadFileIndex(); err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+
+	// Only adapter entries with a knowledge base get it appended to their key
+	expected := map[string]string{
+		"github/owner/repo/docs/a.md@knowledge-1": "file-a",
+		"local/notes.md":                 "file-notes",
+		"readme.md":                      "file-readme",
+		"confluence/page.md@knowledge-2": "file-page",
+	}
+	if len(manager.fileIndex) != len(expected) {
+		t.Errorf("Expected %d entries, got %d", len(expected), len(manager.fileIndex))
+	}
+	for key, fileID := range expected {
+		if entry := manager.fileIndex[key]; entry == nil || entry.FileID != fileID {
+			t.Errorf("Expected %s to hold %s, got %+v", key, fileID, entry)
+		}
+	}
+}
+
+func TestManager_SyncFiles_SamePathInDifferentKnowledgeBases(t *testing.T) {
+	uploads := 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads++
+			return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+		},
+	}
+	// Pages with the same title in two spaces mapped to different knowledge bases
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "confluence" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "Overview.md", Content: []byte("# Docs"), Hash: "hash-docs", KnowledgeID: "knowledge-docs"},
+				{Path: "Overview.md", Content: []byte("# Team"), Hash: "hash-team", KnowledgeID: "knowledge-team"},
+			}, nil
+		},
+		FetchCompleteFunc: func() bool { return true },
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           storage.NewMemory(),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+	for i := 0; i < 3; i++ {
+		if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+			t.Fatalf("Sync %d failed: %v", i+1, err)
+		}
+	}
+
+	if uploads != 2 {
+		t.Errorf("Expected each page to be uploaded once across three syncs, got %d uploads", uploads)
+	}
+	for key, hash := range map[string]string{"confluence/Overview.md@knowledge-docs": "hash-docs", "confluence/Overview.md@knowledge-team": "hash-team"} {
+		if entry := manager.fileIndex[key]; entry == nil || entry.Hash != hash {
+			t.Errorf("Expected %s to be tracked with hash %s, got %+v", key, hash, entry)
+		}
+	}
+}
+
+func TestManager_SyncFiles_MemoryStorage(t *testing.T) {
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			return &openwebui.File{ID: "file-1", Filename: filename}, nil
+		},
+	}
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "docs/guide.md", Content: []byte("# Guide"), Hash: "guide-hash", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	store := storage.NewMemory()
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           store,
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Failed to sync files: %v", err)
+	}
+
+	keys, err := store.List("")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if strings.Join(keys, ",") != "file_index.json,files/mock-adapter/docs/guide.md" {
+		t.Errorf("Expected the file index and a copy of the file in the storage, got %v", keys)
+	}
+
+	restarted := &Manager{
+		store:     store,
+		fileIndex: make(map[string]*FileMetadata),
+	}
+	if err := restarted.loadFileIndex(); err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if entry := restarted.fileIndex["mock-adapter/docs/guide.md@knowledge-id"]; entry == nil || entry.FileID != "file-1" {
+		t.Errorf("Expected the synced file in the reloaded index, got %+v", restarted.fileIndex)
+	}
+}
+
+func TestManager_cleanupOrphanedFiles_DeletesFile(t *testing.T) {
+	var removed, deleted []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = append(removed, fileID)
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleted = append(deleted, fileID)
+			return nil
+		},
+	}
+
+	manager := &Manager{
+		store:           storage.NewMemory(),
+		openwebuiClient: mockClient,
+		fileIndex: map[string]*FileMetadata{
+			"orphan.md": {
+				Path:        "orphan.md",
+				FileID:      "orphan-file-id",
+				Source:      "openwebui",
+				KnowledgeID: "knowledge-1",
+			},
+		},
+	}
+
+	current := newCurrentFiles()
+	current.fetched["knowledge-1"] = true
+	if err := manager.cleanupOrphanedFiles(context.Background(), current); err != nil {
+		t.Fatalf("Failed to cleanup orphaned files: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != "orphan-file-id" {
+		t.Errorf("Expected orphaned file to be removed from knowledge, got %v", removed)
+	}
+	if len(deleted) != 1 || deleted[0] != "orphan-file-id" {
+		t.Errorf("Expected orphaned file to be deleted, got %v", deleted)
+	}
+	if _, exists := manager.fileIndex["orphan.md"]; exists {
+		t.Errorf("Expected orphaned file to be removed from index")
+	}
+}
+
+func TestManager_cleanupOrphanedFiles_KeepsSharedFile(t *testing.T) {
+	deleteCalled := false
+	mockClient := &mocks.MockOpenWebUIClient{
+		ListKnowledgeFunc: func(ctx context.Context) ([]*openwebui.Knowledge, error) {
+			return []*openwebui.Knowledge{
+				{ID: "knowledge-1", Files: []*openwebui.File{{ID: "shared-file-id"}}},
+				{ID: "knowledge-2", Files: []*openwebui.File{{ID: "shared-file-id"}}},
+			}, nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleteCalled = true
+			return nil
+		},
+	}
+
+	manager := &Manager{
+		store:           storage.NewMemory(),
+		openwebuiClient: mockClient,
+		fileIndex: map[string]*FileMetadata{
+			"shared.md": {
+				Path:        "shared.md",
+				FileID:      "shared-file-id",
+				Source:      "openwebui",
+				KnowledgeID: "knowledge-1",
+			},
+		},
+	}
+
+	current := newCurrentFiles()
+	current.fetched["knowledge-1"] = true
+	if err := manager.cleanupOrphanedFiles(context.Background(), current); err != nil {
+		t.Fatalf("Failed to cleanup orphaned files: %v", err)
+	}
+
+	if deleteCalled {
+		t.Errorf("Expected file referenced by another knowledge base not to be deleted")
+	}
+}
+
+func TestManager_SyncFiles_RemovesDeletedAdapterFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	var removed, deleted []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = append(removed, fileID)
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleted = append(deleted, fileID)
+			return nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "docs/kept.md", Content: []byte("# Kept"), Hash: "hash-kept", Source: "owner/repo", KnowledgeID: "knowledge-1"},
+			}, nil
+		},
+		FetchCompleteFunc: func() bool { return true },
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			"github/owner/repo/docs/kept.md@knowledge-1":    {Path: "docs/kept.md", Hash: "hash-kept", FileID: "kept-file-id", Source: "github", KnowledgeID: "knowledge-1"},
+			"github/owner/repo/docs/deleted.md@knowledge-1": {Path: "docs/deleted.md", Hash: "hash-deleted", FileID: "deleted-file-id", Source: "github", KnowledgeID: "knowledge-1"},
+			"confluence/DOCS/page.md@knowledge-1":           {Path: "DOCS/page.md", Hash: "hash-page", FileID: "page-file-id", Source: "confluence", KnowledgeID: "knowledge-1"},
+		},
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != "deleted-file-id" {
+		t.Errorf("Expected only the deleted repository file to be removed from knowledge, got %v", removed)
+	}
+	if len(deleted) != 1 || deleted[0] != "deleted-file-id" {
+		t.Errorf("Expected only the deleted repository file to be deleted, got %v", deleted)
+	}
+	if _, exists := manager.fileIndex["github/owner/repo/docs/deleted.md@knowledge-1"]; exists {
+		t.Errorf("Expected the deleted repository file to be removed from the index")
+	}
+	if _, exists := manager.fileIndex["github/owner/repo/docs/kept.md@knowledge-1"]; !exists {
+		t.Errorf("Expected the fetched repository file to stay in the index")
+	}
+	// Adapters that weren't synced this run keep their files
+	if _, exists := manager.fileIndex["confluence/DOCS/page.md@knowledge-1"]; !exists {
+		t.Errorf("Expected files of other adapters to stay in the index")
+	}
+}
+
+func TestManager_SyncFiles_SameContentAtDifferentPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileIndex    map[string]*FileMetadata
+		files        []string // paths fetched, all with the same content
+		expectedKeys []string
+		uploads      int
+		deleted      int
+	}{
+		{
+			name:         "copies are uploaded and tracked separately",
+			fileIndex:    map[string]*FileMetadata{},
+			files:        []string{"docs/a.md", "docs/b.md"},
+			expectedKeys: []string{"github/docs/a.md@knowledge-1", "github/docs/b.md@knowledge-1"},
+			uploads:      2,
+		},
+		{
+			name: "a copy of a tracked file doesn't take over its entry",
+			fileIndex: map[string]*FileMetadata{
+				"github/docs/a.md@knowledge-1": {Path: "docs/a.md", Hash: "same-hash", FileID: "file-a", Source: "github", KnowledgeID: "knowledge-1"},
+			},
+			files:        []string{"docs/a.md", "docs/b.md"},
+			expectedKeys: []string{"github/docs/a.md@knowledge-1", "github/docs/b.md@knowledge-1"},
+			uploads:      1,
+		},
+		{
+			name: "a renamed file keeps its upload",
+			fileIndex: map[string]*FileMetadata{
+				"github/docs/old.md@knowledge-1": {Path: "docs/old.md", Hash: "same-hash", FileID: "file-old", Source: "github", KnowledgeID: "knowledge-1"},
+			},
+			files:        []string{"docs/new.md"},
+			expectedKeys: []string{"github/docs/new.md@knowledge-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads, deleted := 0, 0
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploads++
+					return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+				},
+				DeleteFileFunc: func(ctx context.Context, fileID string) error {
+					deleted++
+					return nil
+				},
+			}
+			mockAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "github" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					var files []*adapter.File
+					for _, path := range tt.files {
+						files = append(files, &adapter.File{Path: path, Content: []byte("# Same"), Hash: "same-hash", KnowledgeID: "knowledge-1"})
+					}
+					return files, nil
+				},
+				FetchCompleteFunc: func() bool { return true },
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           storage.NewMemory(),
+				concurrency:     1,
+				fileIndex:       tt.fileIndex,
+			}
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var keys []string
+			for key, metadata := range manager.fileIndex {
+				keys = append(keys, key)
+				if metadata.FileID == "" {
+					t.Errorf("Expected %s to be tracked with its file ID", key)
+				}
+			}
+			sort.Strings(keys)
+			if strings.Join(keys, ",") != strings.Join(tt.expectedKeys, ",") {
+				t.Errorf("Expected index keys %v, got %v", tt.expectedKeys, keys)
+			}
+			if uploads != tt.uploads || deleted != tt.deleted {
+				t.Errorf("Expected %d uploads and %d deletions, got %d and %d", tt.uploads, tt.deleted, uploads, deleted)
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_KeepsFilesOfIncompleteFetch(t *testing.T) {
+	tests := []struct {
+		name     string
+		fetch    func(ctx context.Context) ([]*adapter.File, error)
+		complete bool
+	}{
+		{
+			name: "failed fetch",
+			fetch: func(ctx context.Context) ([]*adapter.File, error) {
+				return nil, fmt.Errorf("rate limited")
+			},
+			complete: true,
+		},
+		{
+			name: "fetch that skipped files",
+			fetch: func(ctx context.Context) ([]*adapter.File, error) {
+				return []*adapter.File{}, nil
+			},
+			complete: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			removeCalled := false
+			mockClient := &mocks.MockOpenWebUIClient{
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					removeCalled = true
+					return nil
+				},
+			}
+
+			mockAdapter := &mocks.MockAdapter{
+				NameFunc:          func() string { return "github" },
+				FetchFilesFunc:    tt.fetch,
+				FetchCompleteFunc: func() bool { return tt.complete },
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				concurrency:     1,
+				fileIndex: map[string]*FileMetadata{
+					"github/owner/repo/docs/guide.md@knowledge-1": {Path: "docs/guide.md", Hash: "hash-guide", FileID: "guide-file-id", Source: "github", KnowledgeID: "knowledge-1"},
+				},
+			}
+
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if removeCalled {
+				t.Errorf("Expected no files to be removed from knowledge")
+			}
+			if _, exists := manager.fileIndex["github/owner/repo/docs/guide.md@knowledge-1"]; !exists {
+				t.Errorf("Expected the indexed file to be kept")
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_FailedAdapterKeepsFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	var removed []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = append(removed, fileID)
+			return nil
+		},
+	}
+
+	failing := &mocks.MockAdapter{
+		NameFunc: func() string { return "jira" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return nil, fmt.Errorf("connection refused")
+		},
+	}
+	succeeding := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "docs/guide.md", Content: []byte("# Guide"), Hash: "hash-guide", Source: "owner/repo", KnowledgeID: "knowledge-b"},
+				{Path: "docs/shared.md", Content: []byte("# Shared"), Hash: "hash-shared", Source: "owner/repo", KnowledgeID: "knowledge-shared"},
+			}, nil
+		},
+		FetchCompleteFunc: func() bool { return true },
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			// Files of the failing adapter, one of them in a knowledge base it shares with the other
+			"jira/PROJ/PROJ-1.md@knowledge-shared": {Path: "PROJ/PROJ-1.md", Hash: "hash-issue", FileID: "issue-file-id", Source: "jira", KnowledgeID: "knowledge-shared"},
+			"PROJ-2.md":                            {Path: "PROJ-2.md", FileID: "issue-2-file-id", Source: "openwebui", KnowledgeID: "knowledge-shared"},
+			"PROJ-3.md":                            {Path: "PROJ-3.md", FileID: "issue-3-file-id", Source: "openwebui", KnowledgeID: "knowledge-a"},
+			// Orphans of the succeeding adapter
+			"github/owner/repo/docs/deleted.md@knowledge-b": {Path: "docs/deleted.md", Hash: "hash-deleted", FileID: "deleted-file-id", Source: "github", KnowledgeID: "knowledge-b"},
+			"stale.md": {Path: "stale.md", FileID: "stale-file-id", Source: "openwebui", KnowledgeID: "knowledge-b"},
+		},
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{failing, succeeding}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sort.Strings(removed)
+	if expected := []string{"deleted-file-id", "stale-file-id"}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("Expected only the orphans of the succeeding adapter to be removed, got %v", removed)
+	}
+	for _, key := range []string{"jira/PROJ/PROJ-1.md@knowledge-shared", "PROJ-2.md", "PROJ-3.md"} {
+		if _, exists := manager.fileIndex[key]; !exists {
+			t.Errorf("Expected file %s of the failed adapter to be kept", key)
+		}
+	}
+}
+
+func TestManager_SyncFiles_AdapterTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		slowFetch bool // the slow adapter's fetch runs out of time, otherwise its first upload does
+	}{
+		{name: "slow fetch", slowFetch: true},
+		{name: "slow upload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			var uploaded, removed []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					if strings.HasPrefix(filename, "slow") {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+					uploaded = append(uploaded, filename)
+					return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+				},
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					removed = append(removed, fileID)
+					return nil
+				},
+			}
+
+			slow := &mocks.MockAdapter{
+				NameFunc: func() string { return "confluence" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					if tt.slowFetch {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+					return []*adapter.File{
+						{Path: "slow-1.md", Content: []byte("1"), Hash: "hash-1", KnowledgeID: "knowledge-slow"},
+						{Path: "slow-2.md", Content: []byte("2"), Hash: "hash-2", KnowledgeID: "knowledge-slow"},
+					}, nil
+				},
+				FetchCompleteFunc: func() bool { return true },
+			}
+			fast := &mocks.MockAdapter{
+				NameFunc: func() string { return "github" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{{Path: "fast.md", Content: []byte("fast"), Hash: "hash-fast", KnowledgeID: "knowledge-fast"}}, nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				concurrency:     1,
+				fileIndex: map[string]*FileMetadata{
+					// Synced by an earlier run and not reached before the timeout
+					"confluence/slow-2.md@knowledge-slow": {Path: "slow-2.md", Hash: "old-hash", FileID: "slow-2-id", Source: "confluence", KnowledgeID: "knowledge-slow"},
+				},
+				adapterTimeout: 50 * time.Millisecond,
+			}
+
+			// The run itself is not cancelled, so the sync only reports the failed upload
+			manager.SyncFiles(context.Background(), []adapter.Adapter{slow, fast})
+
+			if !reflect.DeepEqual(uploaded, []string{"fast.md"}) {
+				t.Errorf("Expected the next adapter to sync after the timeout, got uploads %v", uploaded)
+			}
+			if len(removed) != 0 {
+				t.Errorf("Expected no files of the timed out adapter to be removed, got %v", removed)
+			}
+			if _, exists := manager.fileIndex["confluence/slow-2.md@knowledge-slow"]; !exists {
+				t.Errorf("Expected the unsynced file of the timed out adapter to stay indexed")
+			}
+
+			status := manager.Status()
+			if err := status.Adapters["confluence"].Error; !strings.Contains(err, "timed out after 50ms") {
+				t.Errorf("Expected the slow adapter's status to report the timeout, got %q", err)
+			}
+			if github := status.Adapters["github"]; github.Error != "" || github.FilesSynced != 1 {
+				t.Errorf("Expected the fast adapter to sync, got %+v", github)
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_Concurrent(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight, uploads := 0, 0, 0
+
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			mu.Lock()
+			inFlight++
+			uploads++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	var files []*adapter.File
+	for i := 0; i < 10; i++ {
+		files = append(files, &adapter.File{
+			Path:        fmt.Sprintf("file-%d.md", i),
+			Content:     []byte(fmt.Sprintf("# File %d", i)),
+			Hash:        fmt.Sprintf("hash-%d", i),
+			KnowledgeID: "knowledge-id",
+		})
+	}
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return files, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex:       make(map[string]*FileMetadata),
+		concurrency:     3,
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Failed to sync files: %v", err)
+	}
+
+	if uploads != len(files) {
+		t.Errorf("Expected %d uploads, got %d", len(files), uploads)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most 3 concurrent uploads, got %d", maxInFlight)
+	}
+	if len(manager.fileIndex) != len(files) {
+		t.Errorf("Expected %d files in index, got %d", len(files), len(manager.fileIndex))
+	}
+}
+
+func TestManager_SyncFiles_KnowledgeAddDelay(t *testing.T) {
+	tempDir := t.TempDir()
+	const delay = 30 * time.Millisecond
+
+	var addMu sync.Mutex
+	var addTimes []time.Time
+	mockClient := &mocks.MockOpenWebUIClient{
+		AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			addMu.Lock()
+			defer addMu.Unlock()
+			addTimes = append(addTimes, time.Now())
+			return nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			var files []*adapter.File
+			for i := 0; i < 4; i++ {
+				content := []byte(fmt.Sprintf("# File %d", i))
+				files = append(files, &adapter.File{Path: fmt.Sprintf("file-%d.md", i), Content: content, Hash: GetFileHash(content), KnowledgeID: "knowledge-id"})
+			}
+			return files, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient:   mockClient,
+		store:             newLocalStore(t, tempDir),
+		concurrency:       4,
+		fileIndex:         make(map[string]*FileMetadata),
+		knowledgeAddDelay: delay,
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(addTimes) != 4 {
+		t.Fatalf("Expected 4 knowledge additions, got %d", len(addTimes))
+	}
+	// Additions from concurrent workers are still spaced out
+	for i := 1; i < len(addTimes); i++ {
+		if gap := addTimes[i].Sub(addTimes[i-1]); gap < delay {
+			t.Errorf("Expected at least %v between additions %d and %d, got %v", delay, i-1, i, gap)
+		}
+	}
+}
+
+func TestManager_SyncFiles_SerializesKnowledgeChanges(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+	maxPerKnowledge := make(map[string]int)
+	total, maxTotal := 0, 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			mu.Lock()
+			inFlight[knowledgeID]++
+			total++
+			if inFlight[knowledgeID] > maxPerKnowledge[knowledgeID] {
+				maxPerKnowledge[knowledgeID] = inFlight[knowledgeID]
+			}
+			if total > maxTotal {
+				maxTotal = total
+			}
+			mu.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			inFlight[knowledgeID]--
+			total--
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			var files []*adapter.File
+			for i, knowledgeID := range []string{"knowledge-a", "knowledge-b", "knowledge-a", "knowledge-b"} {
+				content := []byte(fmt.Sprintf("# File %d", i))
+				files = append(files, &adapter.File{Path: fmt.Sprintf("file-%d.md", i), Content: content, Hash: GetFileHash(content), KnowledgeID: knowledgeID})
+			}
+			return files, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     4,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, knowledgeID := range []string{"knowledge-a", "knowledge-b"} {
+		if maxPerKnowledge[knowledgeID] != 1 {
+			t.Errorf("Expected changes to %s to be serialized, got %d at once", knowledgeID, maxPerKnowledge[knowledgeID])
+		}
+	}
+	if maxTotal != 2 {
+		t.Errorf("Expected both knowledge bases to be changed in parallel, got at most %d changes at once", maxTotal)
+	}
+}
+
+func TestManager_SyncFiles_DryRun(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mutated := false
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			mutated = true
+			return &openwebui.File{ID: "new-id"}, nil
+		},
+		AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			mutated = true
+			return nil
+		},
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			mutated = true
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			mutated = true
+			return nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "new.md", Content: []byte("# New"), Hash: "new-hash", KnowledgeID: "knowledge-id"},
+				{Path: "changed.md", Content: []byte("# Changed"), Hash: "changed-hash", KnowledgeID: "knowledge-id"},
+				{Path: "same.md", Content: []byte("# Same"), Hash: "same-hash", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		DryRun:          true,
+		fileIndex: map[string]*FileMetadata{
+			"changed.md": {Path: "changed.md", Hash: "old-hash", FileID: "changed-id", Source: "mock-adapter", KnowledgeID: "knowledge-id"},
+			"same.md":    {Path: "same.md", Hash: "same-hash", FileID: "same-id", Source: "mock-adapter", KnowledgeID: "knowledge-id"},
+			"orphan.md":  {Path: "orphan.md", Hash: "orphan-id", FileID: "orphan-id", Source: "openwebui", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Failed to sync files: %v", err)
+	}
+
+	if mutated {
+		t.Errorf("Expected no mutating OpenWebUI calls in dry-run mode")
+	}
+	if _, err := manager.store.Read(fileIndexFile); !errors.Is(err, storage.ErrNotExist) {
+		t.Errorf("Expected file index not to be written in dry-run mode")
+	}
+	if len(manager.fileIndex) != 3 {
+		t.Errorf("Expected file index to be unchanged, got %d entries", len(manager.fileIndex))
+	}
+
+	summary := manager.Summary()
+	expected := SyncSummary{DryRun: true, Uploaded: 1, Updated: 1, Skipped: 1, Deleted: 1}
+	if summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+}
+
+func TestManager_SyncFiles_Metrics(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			if filename == "broken.md" {
+				return nil, fmt.Errorf("upload failed")
+			}
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	okAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "metrics-ok" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+				{Path: "b.md", Content: []byte("# B"), Hash: "hash-b", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+	failingAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "metrics-failing" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "broken.md", Content: []byte("# Broken"), Hash: "hash-broken", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			"orphan.md": {Path: "orphan.md", Hash: "orphan-id", FileID: "orphan-id", Source: "openwebui", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	uploadedBefore := testutil.ToFloat64(metrics.FilesUploaded.WithLabelValues("metrics-ok"))
+	removedBefore := testutil.ToFloat64(metrics.FilesRemoved)
+	errorsBefore := testutil.ToFloat64(metrics.SyncErrors.WithLabelValues("metrics-failing"))
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{okAdapter, failingAdapter}); err == nil {
+		t.Fatalf("Expected an error for the failed file")
+	}
+
+	if got := testutil.ToFloat64(metrics.FilesUploaded.WithLabelValues("metrics-ok")) - uploadedBefore; got != 2 {
+		t.Errorf("Expected 2 uploads recorded, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.FilesRemoved) - removedBefore; got != 1 {
+		t.Errorf("Expected 1 removal recorded, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.SyncErrors.WithLabelValues("metrics-failing")) - errorsBefore; got != 1 {
+		t.Errorf("Expected 1 sync error recorded, got %v", got)
+	}
+	if testutil.ToFloat64(metrics.LastSuccessfulSync.WithLabelValues("metrics-ok")) == 0 {
+		t.Errorf("Expected last successful sync timestamp to be set for metrics-ok")
+	}
+	if testutil.ToFloat64(metrics.LastSuccessfulSync.WithLabelValues("metrics-failing")) != 0 {
+		t.Errorf("Expected no last successful sync timestamp for metrics-failing")
+	}
+}
+
+func TestManager_SyncAdapter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	removed := false
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = true
+			return nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			// Belongs to another adapter that has not synced yet
+			"other.md": {Path: "other.md", Hash: "other-id", FileID: "other-id", Source: "openwebui", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	if err := manager.SyncAdapter(context.Background(), mockAdapter); err != nil {
+		t.Fatalf("Failed to sync adapter: %v", err)
+	}
+
+	if _, ok := manager.fileIndex["mock-adapter/a.md@knowledge-id"]; !ok {
+		t.Errorf("Expected a.md to be added to the file index")
+	}
+	if _, ok := manager.fileIndex["other.md"]; !ok || removed {
+		t.Errorf("Expected files of other adapters to be left alone by SyncAdapter")
+	}
+	if summary := manager.Summary(); summary.Uploaded != 1 {
+		t.Errorf("Expected 1 upload in summary, got %+v", summary)
+	}
+	if _, err := manager.store.Read(fileIndexFile); err != nil {
+		t.Errorf("Expected file index to be saved: %v", err)
+	}
+}
+
+func TestManager_Status(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			if filename == "broken.md" {
+				return nil, fmt.Errorf("upload rejected")
+			}
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	okAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+				{Path: "broken.md", Content: []byte("# Broken"), Hash: "hash-b", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+	failingAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "jira" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return nil, fmt.Errorf("jira unreachable")
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{okAdapter, failingAdapter}); err == nil {
+		t.Fatalf("Expected an error for the failed file")
+	}
+
+	data, err := json.Marshal(manager.Status())
+	if err != nil {
+		t.Fatalf("Failed to encode status: %v", err)
+	}
+
+	var status struct {
+		LastSync *time.Time `json:"last_sync"`
+		NextSync *time.Time `json:"next_sync"`
+		Adapters map[string]struct {
+			LastSync        time.Time `json:"last_sync"`
+			DurationSeconds *float64  `json:"duration_seconds"`
+			FilesSynced     int       `json:"files_synced"`
+			FilesFailed     int       `json:"files_failed"`
+			Error           string    `json:"error"`
+		} `json:"adapters"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("Failed to decode status %s: %v", data, err)
+	}
+
+	if status.LastSync == nil || status.NextSync != nil {
+		t.Errorf("Expected last_sync to be set and next_sync to be null, got %s", data)
+	}
+	github, ok := status.Adapters["github"]
+	if !ok || github.FilesSynced != 1 || github.FilesFailed != 1 || github.Error == "" || github.DurationSeconds == nil || github.LastSync.IsZero() {
+		t.Errorf("Unexpected github status in %s", data)
+	}
+	jira, ok := status.Adapters["jira"]
+	if !ok || jira.FilesSynced != 0 || !strings.Contains(jira.Error, "jira unreachable") {
+		t.Errorf("Unexpected jira status in %s", data)
+	}
+}
+
+func TestManager_SyncFiles_OpenWebUIFileUploadedOnce(t *testing.T) {
+	tempDir := t.TempDir()
+
+	uploads := 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads++
+			return &openwebui.File{ID: fmt.Sprintf("new-id-%d", uploads), Filename: filename}, nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "doc.md", Content: []byte("# Doc"), Hash: "adapter-hash", KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			// Initialized from OpenWebUI without a content hash, so the file ID stands in for it
+			"doc.md": {Path: "doc.md", Hash: "remote-id", FileID: "remote-id", Source: "openwebui", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+			t.Fatalf("Sync %d failed: %v", i+1, err)
+		}
+	}
+
+	if uploads != 1 {
+		t.Errorf("Expected 1 upload across two syncs with identical content, got %d", uploads)
+	}
+	if summary := manager.Summary(); summary.Uploaded != 0 || summary.Updated != 0 || summary.Skipped != 1 {
+		t.Errorf("Expected second sync to only skip, got %+v", summary)
+	}
+
+	entry := manager.fileIndex["mock-adapter/doc.md@knowledge-id"]
+	if entry == nil || entry.Source != "mock-adapter" || entry.Hash != "adapter-hash" {
+		t.Errorf("Expected index entry to move to adapter source and hash, got %+v", entry)
+	}
+	if _, exists := manager.fileIndex["doc.md"]; exists {
+		t.Errorf("Expected the entry initialized from OpenWebUI to be replaced")
+	}
+}
+
+func TestManager_syncFile_AdoptsMatchingOpenWebUIContent(t *testing.T) {
+	tempDir := t.TempDir()
+
+	content := []byte("# Doc")
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			t.Errorf("Expected no upload when OpenWebUI already has the same content")
+			return &openwebui.File{ID: "new-id"}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex: map[string]*FileMetadata{
+			"doc.md": {Path: "doc.md", Hash: GetFileHash(content), FileID: "remote-id", Source: "openwebui", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	file := &adapter.File{Path: "doc.md", Content: content, Hash: "adapter-hash", KnowledgeID: "knowledge-id"}
+	if err := manager.syncFile(context.Background(), file, "github"); err != nil {
+		t.Fatalf("Failed to sync file: %v", err)
+	}
+
+	entry := manager.fileIndex["github/doc.md@knowledge-id"]
+	if entry == nil || entry.FileID != "remote-id" || entry.Source != "github" || entry.Hash != "adapter-hash" {
+		t.Errorf("Expected existing file to be adopted with adapter source and hash, got %+v", entry)
+	}
+	if summary := manager.Summary(); summary.Skipped != 1 {
+		t.Errorf("Expected file to be recorded as skipped, got %+v", summary)
+	}
+}
+
+func TestManager_syncFile_UpdatesChangedFileInPlace(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var updatedID string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+			updatedID = fileID
+			return &openwebui.File{ID: fileID, Filename: filename}, nil
+		},
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			t.Errorf("Expected no upload when the file can be updated in place")
+			return &openwebui.File{ID: "new-id"}, nil
+		},
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			t.Errorf("Expected file to stay in knowledge when updated in place")
+			return nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex: map[string]*FileMetadata{
+			"doc.md": {Path: "doc.md", Hash: "old-hash", FileID: "file-id", Source: "github", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	file := &adapter.File{Path: "doc.md", Content: []byte("# Updated"), Hash: "new-hash", KnowledgeID: "knowledge-id"}
+	if err := manager.syncFile(context.Background(), file, "github"); err != nil {
+		t.Fatalf("Failed to sync file: %v", err)
+	}
+
+	if updatedID != "file-id" {
+		t.Errorf("Expected file-id to be updated in place, got %q", updatedID)
+	}
+	entry := manager.fileIndex["github/doc.md@knowledge-id"]
+	if entry == nil || entry.FileID != "file-id" || entry.Hash != "new-hash" {
+		t.Errorf("Expected index entry to keep file ID with new hash, got %+v", entry)
+	}
+	if summary := manager.Summary(); summary.Updated != 1 {
+		t.Errorf("Expected file to be recorded as updated, got %+v", summary)
+	}
+}
+
+func TestManager_syncFile_ReuploadsBinaryFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		file *adapter.File
+	}{
+		{"binary extension", &adapter.File{Path: "report.pdf", Content: []byte("%PDF-1.7\x00\xff")}},
+		{"binary content type", &adapter.File{Path: "diagram", Content: []byte("\x89PNG"), ContentType: "image/png"}},
+		{"invalid UTF-8 text", &adapter.File{Path: "notes.txt", Content: []byte("caf\xe9")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploaded []byte
+			mockClient := &mocks.MockOpenWebUIClient{
+				UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+					t.Errorf("Expected %s not to be updated in place", filename)
+					return &openwebui.File{ID: fileID, Filename: filename}, nil
+				},
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploaded = content
+					return &openwebui.File{ID: "new-id", Filename: filename}, nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, t.TempDir()),
+				fileIndex: map[string]*FileMetadata{
+					"github/" + tt.file.Path + "@knowledge-id": {Path: tt.file.Path, Hash: "old-hash", FileID: "old-id", Source: "github", KnowledgeID: "knowledge-id"},
+				},
+			}
+
+			tt.file.Hash, tt.file.KnowledgeID = "new-hash", "knowledge-id"
+			if err := manager.syncFile(context.Background(), tt.file, "github"); err != nil {
+				t.Fatalf("Failed to sync file: %v", err)
+			}
+			if string(uploaded) != string(tt.file.Content) {
+				t.Errorf("Expected the unchanged content to be re-uploaded, got %q", uploaded)
+			}
+			if entry := manager.fileIndex["github/"+tt.file.Path+"@knowledge-id"]; entry == nil || entry.FileID != "new-id" {
+				t.Errorf("Expected index entry to point at the new upload, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestManager_syncFile_UpdateInPlaceFallsBackToUpload(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var removed, deleted []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+			return nil, fmt.Errorf("update not supported")
+		},
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			return &openwebui.File{ID: "new-id", Filename: filename}, nil
+		},
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = append(removed, fileID)
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleted = append(deleted, fileID)
+			return nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex: map[string]*FileMetadata{
+			"doc.md": {Path: "doc.md", Hash: "old-hash", FileID: "old-id", Source: "github", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	file := &adapter.File{Path: "doc.md", Content: []byte("# Updated"), Hash: "new-hash", KnowledgeID: "knowledge-id"}
+	if err := manager.syncFile(context.Background(), file, "github"); err != nil {
+		t.Fatalf("Failed to sync file: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != "old-id" || len(deleted) != 1 || deleted[0] != "old-id" {
+		t.Errorf("Expected old file to be removed and deleted, got removed=%v deleted=%v", removed, deleted)
+	}
+	if entry := manager.fileIndex["github/doc.md@knowledge-id"]; entry == nil || entry.FileID != "new-id" {
+		t.Errorf("Expected index entry to point at the new upload, got %+v", entry)
+	}
+}
+
+func TestManager_syncFile_DeletesReplacedFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		hash          string
+		uploadErr     error
+		expectDeleted bool
+	}{
+		{name: "changed content", hash: "new-hash", expectDeleted: true},
+		{name: "unchanged content", hash: "old-hash", expectDeleted: false},
+		{name: "failed upload", hash: "new-hash", uploadErr: fmt.Errorf("upload failed"), expectDeleted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			var deleted []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+					return nil, fmt.Errorf("update not supported")
+				},
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					if tt.uploadErr != nil {
+						return nil, tt.uploadErr
+					}
+					return &openwebui.File{ID: "new-id", Filename: filename}, nil
+				},
+				DeleteFileFunc: func(ctx context.Context, fileID string) error {
+					deleted = append(deleted, fileID)
+					return nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				fileIndex: map[string]*FileMetadata{
+					"doc.md": {Path: "doc.md", Hash: "old-hash", FileID: "old-id", Source: "github", KnowledgeID: "knowledge-id"},
+				},
+			}
+
+			file := &adapter.File{Path: "doc.md", Content: []byte("# Doc"), Hash: tt.hash, KnowledgeID: "knowledge-id"}
+			err := manager.syncFile(context.Background(), file, "github")
+			if (err != nil) != (tt.uploadErr != nil) {
+				t.Fatalf("Unexpected sync error: %v", err)
+			}
+
+			wasDeleted := len(deleted) == 1 && deleted[0] == "old-id"
+			if wasDeleted != tt.expectDeleted || len(deleted) > 1 {
+				t.Errorf("Expected old file deleted=%v, got deletions %v", tt.expectDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_ConflictStrategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       string
+		remoteHash     string // hash OpenWebUI reports at startup
+		expectUpdate   bool
+		expectConflict bool
+	}{
+		{name: "unedited file is updated", strategy: "overwrite", remoteHash: "synced-hash", expectUpdate: true},
+		{name: "overwrite replaces the edit", strategy: "overwrite", remoteHash: "edited-hash", expectUpdate: true, expectConflict: true},
+		{name: "skip keeps the edit", strategy: "skip", remoteHash: "edited-hash", expectConflict: true},
+		{name: "skip updates unedited files", strategy: "skip", remoteHash: "synced-hash", expectUpdate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			updated := false
+			mockClient := &mocks.MockOpenWebUIClient{
+				GetKnowledgeFilesFunc: func(ctx context.Context, knowledgeID string) ([]*openwebui.File, error) {
+					return []*openwebui.File{{ID: "file-id", Filename: "doc.md", Hash: tt.remoteHash}}, nil
+				},
+				UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+					updated = true
+					return &openwebui.File{ID: fileID, Filename: filename, Hash: "new-remote-hash"}, nil
+				},
+			}
+			mockAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "github" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{{Path: "doc.md", Content: []byte("# New"), Hash: "new-hash", Source: "owner/repo", KnowledgeID: "knowledge-id"}}, nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				concurrency:     1,
+				fileIndex: map[string]*FileMetadata{
+					"github/owner/repo/doc.md@knowledge-id": {Path: "doc.md", Hash: "old-hash", FileID: "file-id", Source: "github", KnowledgeID: "knowledge-id", RemoteHash: "synced-hash"},
+				},
+				conflictStrategy: tt.strategy,
+			}
+
+			var logs bytes.Buffer
+			logrus.SetOutput(&logs)
+			defer logrus.SetOutput(os.Stderr)
+
+			if err := manager.InitializeFileIndex(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+				t.Fatalf("Failed to initialize the file index: %v", err)
+			}
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if updated != tt.expectUpdate {
+				t.Errorf("Expected update = %v, got %v", tt.expectUpdate, updated)
+			}
+			if conflict := strings.Contains(logs.String(), "was edited in OpenWebUI"); conflict != tt.expectConflict {
+				t.Errorf("Expected conflict warning = %v, got logs:\n%s", tt.expectConflict, logs.String())
+			}
+
+			metadata := manager.fileIndex["github/owner/repo/doc.md@knowledge-id"]
+			switch {
+			case tt.expectUpdate && (metadata.Hash != "new-hash" || metadata.RemoteHash != "new-remote-hash"):
+				t.Errorf("Expected the index to record the update, got %+v", metadata)
+			case !tt.expectUpdate && (metadata.Hash != "old-hash" || metadata.RemoteHash != "synced-hash"):
+				t.Errorf("Expected the index to keep the last synced version, got %+v", metadata)
+			}
+		})
+	}
+}
+
+func TestManager_SyncChangedFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	uploads := 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads++
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	file := &adapter.File{Path: "notes.md", Content: []byte("# Notes"), Hash: "hash-1", KnowledgeID: "knowledge-id"}
+	if err := manager.SyncChangedFile(context.Background(), file, "local"); err != nil {
+		t.Fatalf("Failed to sync changed file: %v", err)
+	}
+	// The same content reported again should not be re-uploaded
+	if err := manager.SyncChangedFile(context.Background(), file, "local"); err != nil {
+		t.Fatalf("Failed to sync changed file: %v", err)
+	}
+
+	if uploads != 1 {
+		t.Errorf("Expected 1 upload, got %d", uploads)
+	}
+	if entry := manager.fileIndex["local/notes.md@knowledge-id"]; entry == nil || entry.Source != "local" {
+		t.Errorf("Expected notes.md in file index from local source, got %+v", entry)
+	}
+	if _, err := manager.store.Read(fileIndexFile); err != nil {
+		t.Errorf("Expected file index to be saved: %v", err)
+	}
+}
+
+// itemAdapter fetches single items and knows only the items in its map
+type itemAdapter struct {
+	mocks.MockAdapter
+	items map[string][]*adapter.File
+}
+
+func (a *itemAdapter) FetchItem(ctx context.Context, id string) ([]*adapter.File, error) {
+	files, ok := a.items[id]
+	if !ok {
+		return nil, adapter.ErrUnknownItem
+	}
+	return files, nil
+}
+
+func TestManager_SyncItem(t *testing.T) {
+	tests := []struct {
+		name            string
+		id              string
+		expectedUploads []string
+	}{
+		{name: "known item", id: "C123", expectedUploads: []string{"general.md"}},
+		{name: "item without changes", id: "C456"},
+		{name: "unknown item syncs the adapter", id: "C789", expectedUploads: []string{"general.md", "random.md"}},
+		{name: "empty ID syncs the adapter", expectedUploads: []string{"general.md", "random.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploaded []string
+			removed := false
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploaded = append(uploaded, filename)
+					return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+				},
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					removed = true
+					return nil
+				},
+			}
+
+			general := &adapter.File{Path: "general.md", Content: []byte("# General"), Hash: "hash-general", KnowledgeID: "knowledge-id"}
+			random := &adapter.File{Path: "random.md", Content: []byte("# Random"), Hash: "hash-random", KnowledgeID: "knowledge-id"}
+			itemFetcher := &itemAdapter{
+				MockAdapter: mocks.MockAdapter{
+					NameFunc: func() string { return "slack" },
+					FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+						return []*adapter.File{general, random}, nil
+					},
+				},
+				items: map[string][]*adapter.File{"C123": {general}, "C456": nil},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           storage.NewMemory(),
+				concurrency:     1,
+				fileIndex: map[string]*FileMetadata{
+					// Another channel of the adapter, left to the next full sync
+					"slack/old.md@knowledge-id": {Path: "old.md", Hash: "hash-old", FileID: "id-old", Source: "slack", KnowledgeID: "knowledge-id"},
+				},
+			}
+
+			if err := manager.SyncItem(context.Background(), itemFetcher, tt.id); err != nil {
+				t.Fatalf("Failed to sync item: %v", err)
+			}
+
+			if strings.Join(uploaded, ",") != strings.Join(tt.expectedUploads, ",") {
+				t.Errorf("Expected uploads %v, got %v", tt.expectedUploads, uploaded)
+			}
+			if tt.id == "C123" && (removed || manager.fileIndex["slack/old.md@knowledge-id"] == nil) {
+				t.Errorf("Expected files of other items to be left alone")
+			}
+			if len(tt.expectedUploads) > 0 && manager.fileIndex["slack/general.md@knowledge-id"] == nil {
+				t.Errorf("Expected general.md in file index")
+			}
+		})
+	}
+}
+
+// knowledgeIDAdapter reports its knowledge bases without being fetched
+type knowledgeIDAdapter struct {
+	mocks.MockAdapter
+	knowledgeIDs []string
+}
+
+func (a *knowledgeIDAdapter) KnowledgeIDs() []string {
+	return a.knowledgeIDs
+}
+
+func TestManager_SyncFiles_Logger(t *testing.T) {
+	tempDir := t.TempDir()
+	folder := filepath.Join(tempDir, "docs")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "notes.md"), []byte("# Notes"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	localAdapter, err := adapter.NewLocalFolderAdapter(config.LocalFolderConfig{
+		Enabled:  true,
+		Mappings: []config.LocalFolderMapping{{FolderPath: folder, KnowledgeID: "knowledge-id"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create local folder adapter: %v", err)
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	logger := mocks.NewMockLogger()
+	manager.SetLogger(logger)
+	localAdapter.SetLogger(logger.WithField("adapter", "local"))
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{localAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []mocks.LogEntry{
+		{Level: "info", Message: "Starting file synchronization"},
+		{Level: "debug", Message: "Fetching files from local folder: " + folder, Fields: map[string]any{"adapter": "local"}},
+		{Level: "info", Message: "Successfully synced file: notes.md"},
+	}
+	entries := logger.Entries()
+	for _, want := range expected {
+		found := false
+		for _, entry := range entries {
+			if entry.Level == want.Level && entry.Message == want.Message && reflect.DeepEqual(entry.Fields, want.Fields) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected log entry %+v, got %+v", want, entries)
+		}
+	}
+}
+
+// contentLoaderAdapter reports files as unchanged and loads their content on request
+type contentLoaderAdapter struct {
+	mocks.MockAdapter
+	loaded []string
+}
+
+func (a *contentLoaderAdapter) LoadContent(ctx context.Context, file *adapter.File) error {
+	a.loaded = append(a.loaded, file.Path)
+	file.Content = []byte("# " + file.Path)
+	file.Unchanged = false
+	return nil
+}
+
+func TestManager_SyncFiles_UnchangedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var uploaded []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploaded = append(uploaded, filename)
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	loader := &contentLoaderAdapter{
+		MockAdapter: mocks.MockAdapter{
+			NameFunc: func() string { return "local" },
+			FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+				return []*adapter.File{
+					{Path: "synced.md", Hash: "hash-1", KnowledgeID: "knowledge-id", Unchanged: true},
+					{Path: "new.md", Hash: "hash-2", KnowledgeID: "knowledge-id", Unchanged: true},
+				}, nil
+			},
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex: map[string]*FileMetadata{
+			"local/synced.md@knowledge-id": {Path: "synced.md", Hash: "hash-1", FileID: "file-1", Source: "local", KnowledgeID: "knowledge-id"},
+		},
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{loader}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only the file missing from the index needs its content
+	if !reflect.DeepEqual(loader.loaded, []string{"new.md"}) {
+		t.Errorf("Expected only new.md to be loaded, got %v", loader.loaded)
+	}
+	if !reflect.DeepEqual(uploaded, []string{"new.md"}) {
+		t.Errorf("Expected only new.md to be uploaded, got %v", uploaded)
+	}
+	if entry := manager.fileIndex["local/synced.md@knowledge-id"]; entry == nil || entry.FileID != "file-1" {
+		t.Errorf("Expected synced.md to keep its index entry, got %+v", entry)
+	}
+}
+
+func TestManager_InitializeFileIndex_KnowledgeIDProvider(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var requested []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		GetKnowledgeFilesFunc: func(ctx context.Context, knowledgeID string) ([]*openwebui.File, error) {
+			requested = append(requested, knowledgeID)
+			return []*openwebui.File{{ID: "remote-id", Path: "issue.md", Hash: "remote-hash"}}, nil
+		},
+	}
+
+	provider := &knowledgeIDAdapter{
+		MockAdapter: mocks.MockAdapter{
+			FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+				t.Errorf("Expected FetchFiles not to be called for a KnowledgeIDProvider")
+				return nil, nil
+			},
+		},
+		knowledgeIDs: []string{"jira-knowledge"},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	if err := manager.InitializeFileIndex(context.Background(), []adapter.Adapter{provider}); err != nil {
+		t.Fatalf("InitializeFileIndex() error = %v", err)
+	}
+
+	if len(requested) != 1 || requested[0] != "jira-knowledge" {
+		t.Errorf("Expected knowledge files for jira-knowledge to be requested, got %v", requested)
+	}
+	if entry := manager.fileIndex["issue.md"]; entry == nil || entry.Source != "openwebui" {
+		t.Errorf("Expected issue.md in file index from openwebui, got %+v", entry)
+	}
+}
+
+func TestManager_PurgeSource(t *testing.T) {
+	var removed, deleted []string
+	failRemove := map[string]bool{"flaky-file-id": true}
+	mockClient := &mocks.MockOpenWebUIClient{
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			if failRemove[fileID] {
+				return fmt.Errorf("temporary failure")
+			}
+			removed = append(removed, fileID)
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleted = append(deleted, fileID)
+			return nil
+		},
+	}
+
+	manager := &Manager{
+		store:           storage.NewMemory(),
+		openwebuiClient: mockClient,
+		fileIndex: map[string]*FileMetadata{
+			"PROJ-1.md":  {Path: "PROJ-1.md", FileID: "jira-file-1", Source: "jira", KnowledgeID: "knowledge-1"},
+			"PROJ-2.md":  {Path: "PROJ-2.md", FileID: "jira-file-2", Source: "jira", KnowledgeID: "knowledge-1"},
+			"PROJ-3.md":  {Path: "PROJ-3.md", FileID: "flaky-file-id", Source: "jira", KnowledgeID: "knowledge-1"},
+			"README.md":  {Path: "README.md", FileID: "github-file", Source: "github", KnowledgeID: "knowledge-1"},
+			"unknown.md": {Path: "unknown.md", FileID: "openwebui-file", Source: "openwebui", KnowledgeID: "knowledge-1"},
+		},
+	}
+
+	purged, err := manager.PurgeSource(context.Background(), "jira")
+	if err == nil {
+		t.Error("Expected error when a file fails to be removed")
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 purged files, got %d", purged)
+	}
+	if _, exists := manager.fileIndex["PROJ-3.md"]; !exists {
+		t.Error("Expected file that failed to be removed to stay in the index")
+	}
+
+	// Re-running retries the remaining file and leaves other sources untouched
+	delete(failRemove, "flaky-file-id")
+	purged, err = manager.PurgeSource(context.Background(), "jira")
+	if err != nil {
+		t.Fatalf("PurgeSource() error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged file on re-run, got %d", purged)
+	}
+	purged, err = manager.PurgeSource(context.Background(), "jira")
+	if err != nil || purged != 0 {
+		t.Errorf("Expected purging an already purged source to be a no-op, got %d files, err %v", purged, err)
+	}
+
+	for _, fileID := range append(removed, deleted...) {
+		if fileID == "github-file" || fileID == "openwebui-file" {
+			t.Errorf("Expected file %s from another source not to be touched", fileID)
+		}
+	}
+	if len(removed) != 3 || len(deleted) != 3 {
+		t.Errorf("Expected 3 files removed and deleted, got removed %v, deleted %v", removed, deleted)
+	}
+	if len(manager.fileIndex) != 2 {
+		t.Errorf("Expected only files from other sources to remain in the index, got %v", manager.fileIndex)
+	}
+	for _, key := range []string{"README.md", "unknown.md"} {
+		if _, exists := manager.fileIndex[key]; !exists {
+			t.Errorf("Expected %s to remain in the index", key)
+		}
+	}
+}
+
+func TestManager_SyncFiles_SameFilenameFromDifferentSources(t *testing.T) {
+	tests := []struct {
+		name       string
+		knowledge1 string
+		knowledge2 string
+	}{
+		{name: "different knowledge bases", knowledge1: "knowledge-1", knowledge2: "knowledge-2"},
+		{name: "same knowledge base", knowledge1: "knowledge-1", knowledge2: "knowledge-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			uploads := 0
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploads++
+					return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+				},
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					t.Errorf("Expected no file to replace the other, but %s was removed from %s", fileID, knowledgeID)
+					return nil
+				},
+			}
+
+			githubAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "github" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{
+						{Path: "README.md", Content: []byte("# Repo 1"), Hash: "hash-1", Source: "owner/repo1", KnowledgeID: tt.knowledge1},
+						{Path: "README.md", Content: []byte("# Repo 2"), Hash: "hash-2", Source: "owner/repo2", KnowledgeID: tt.knowledge2},
+					}, nil
+				},
+			}
+			localAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "local" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{
+						{Path: "README.md", Content: []byte("# Local"), Hash: "hash-3", KnowledgeID: tt.knowledge1},
+					}, nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				concurrency:     1,
+				fileIndex:       make(map[string]*FileMetadata),
+			}
+
+			// A second run must not re-upload files that overwrote each other's index entry
+			for i := 0; i < 2; i++ {
+				if err := manager.SyncFiles(context.Background(), []adapter.Adapter{githubAdapter, localAdapter}); err != nil {
+					t.Fatalf("Sync %d failed: %v", i+1, err)
+				}
+			}
+
+			if uploads != 3 {
+				t.Errorf("Expected 3 uploads across two syncs, got %d", uploads)
+			}
+			if summary := manager.Summary(); summary.Skipped != 3 {
+				t.Errorf("Expected all files to be skipped on the second sync, got %+v", summary)
+			}
+			expected := map[string]string{
+				"github/owner/repo1/README.md@" + tt.knowledge1: "hash-1",
+				"github/owner/repo2/README.md@" + tt.knowledge2: "hash-2",
+				"local/README.md@" + tt.knowledge1:              "hash-3",
+			}
+			if len(manager.fileIndex) != len(expected) {
+				t.Errorf("Expected %d index entries, got %v", len(expected), manager.fileIndex)
+			}
+			for key, hash := range expected {
+				if entry := manager.fileIndex[key]; entry == nil || entry.Hash != hash {
+					t.Errorf("Expected %s to be tracked with hash %s, got %+v", key, hash, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_DedupContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		knowledge1   string
+		knowledge2   string
+		expectedAdds int
+	}{
+		{name: "different knowledge bases", knowledge1: "knowledge-1", knowledge2: "knowledge-2", expectedAdds: 2},
+		{name: "same knowledge base", knowledge1: "knowledge-1", knowledge2: "knowledge-1", expectedAdds: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			uploads := 0
+			var adds []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					uploads++
+					return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+				},
+				AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					adds = append(adds, knowledgeID+"/"+fileID)
+					return nil
+				},
+				UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+					t.Errorf("Expected shared file %s not to be updated in place", fileID)
+					return &openwebui.File{ID: fileID, Filename: filename}, nil
+				},
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					if knowledgeID == tt.knowledge1 {
+						t.Errorf("Expected shared file %s to stay in %s", fileID, knowledgeID)
+					}
+					return nil
+				},
+				DeleteFileFunc: func(ctx context.Context, fileID string) error {
+					t.Errorf("Expected shared file %s not to be deleted", fileID)
+					return nil
+				},
+			}
+
+			content := []byte("# Shared guide")
+			localAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "local" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{
+						{Path: "guide.md", Content: content, Hash: GetFileHash(content), KnowledgeID: tt.knowledge1},
+					}, nil
+				},
+			}
+			githubContent := content
+			githubAdapter := &mocks.MockAdapter{
+				NameFunc: func() string { return "github" },
+				FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+					return []*adapter.File{
+						{Path: "docs/guide.md", Content: githubContent, Hash: GetFileHash(githubContent), KnowledgeID: tt.knowledge2},
+					}, nil
+				},
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           newLocalStore(t, tempDir),
+				concurrency:     1,
+				fileIndex:       make(map[string]*FileMetadata),
+				dedupContent:    true,
+			}
+
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{localAdapter, githubAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if uploads != 1 {
+				t.Errorf("Expected identical content to be uploaded once, got %d uploads", uploads)
+			}
+			if len(adds) != tt.expectedAdds {
+				t.Errorf("Expected %d knowledge additions, got %v", tt.expectedAdds, adds)
+			}
+			if summary := manager.Summary(); summary.Uploaded != 1 || summary.Linked != 1 {
+				t.Errorf("Expected 1 upload and 1 link, got %+v", summary)
+			}
+			for _, key := range []string{"local/guide.md@" + tt.knowledge1, "github/docs/guide.md@" + tt.knowledge2} {
+				if entry := manager.fileIndex[key]; entry == nil || entry.FileID != "id-1" {
+					t.Errorf("Expected %s to be tracked with the shared file, got %+v", key, entry)
+				}
+			}
+
+			// Changing one copy uploads it separately and leaves the shared file to the other
+			githubContent = []byte("# Changed guide")
+			if err := manager.SyncFiles(context.Background(), []adapter.Adapter{localAdapter, githubAdapter}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if uploads != 2 {
+				t.Errorf("Expected the changed copy to be uploaded, got %d uploads", uploads)
+			}
+			if entry := manager.fileIndex["local/guide.md@"+tt.knowledge1]; entry == nil || entry.FileID != "id-1" {
+				t.Errorf("Expected the unchanged copy to keep the shared file, got %+v", entry)
+			}
+			if entry := manager.fileIndex["github/docs/guide.md@"+tt.knowledge2]; entry == nil || entry.FileID != "id-2" {
+				t.Errorf("Expected the changed copy to use its own file, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestManager_SyncFiles_RenamedFileReplacesPrevious(t *testing.T) {
+	tempDir := t.TempDir()
+	var uploaded, removed, deleted []string
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploaded = append(uploaded, filename)
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+		RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			removed = append(removed, fileID)
+			return nil
+		},
+		DeleteFileFunc: func(ctx context.Context, fileID string) error {
+			deleted = append(deleted, fileID)
+			return nil
+		},
+		UpdateFileContentFunc: func(ctx context.Context, fileID, filename string, content []byte) (*openwebui.File, error) {
+			t.Errorf("Expected a renamed file to be re-uploaded, not updated in place as %s", fileID)
+			return &openwebui.File{ID: fileID}, nil
+		},
+	}
+
+	channelName := "general"
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "slack" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			content := []byte("# Slack Channel: " + channelName)
+			return []*adapter.File{
+				{Path: channelName + "_messages.md", Content: content, Hash: GetFileHash(content), Source: "slack", KnowledgeID: "knowledge-id", ID: "channel:C1"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The channel is renamed
+	channelName = "town_square"
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"general_messages.md", "town_square_messages.md"}; !reflect.DeepEqual(uploaded, expected) {
+		t.Errorf("Expected uploads %v, got %v", expected, uploaded)
+	}
+	if len(removed) != 1 || removed[0] != "id-general_messages.md" || len(deleted) != 1 || deleted[0] != "id-general_messages.md" {
+		t.Errorf("Expected the file of the old channel name to be removed and deleted, got removed %v, deleted %v", removed, deleted)
+	}
+	if len(manager.fileIndex) != 1 {
+		t.Fatalf("Expected a single index entry for the channel, got %d", len(manager.fileIndex))
+	}
+	for key, metadata := range manager.fileIndex {
+		if key != "slack/town_square_messages.md@knowledge-id" || metadata.FileID != "id-town_square_messages.md" || metadata.ID != "channel:C1" {
+			t.Errorf("Unexpected index entry %s: %+v", key, metadata)
+		}
+	}
+}
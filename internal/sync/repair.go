@@ -0,0 +1,216 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/openwebui-content-sync/internal/openwebui"
+)
+
+// RepairReport counts the file index entries checked and dropped by RepairIndex
+type RepairReport struct {
+	Checked    int `json:"checked"`
+	Missing    int `json:"missing"`    // entries whose file no longer exists in OpenWebUI
+	Duplicates int `json:"duplicates"` // entries that conflicted with another entry of the same file
+	Deleted    int `json:"deleted"`    // files of dropped duplicates removed from OpenWebUI
+}
+
+// add sums the counts of another report
+func (r *RepairReport) add(other RepairReport) {
+	r.Checked += other.Checked
+	r.Missing += other.Missing
+	r.Duplicates += other.Duplicates
+	r.Deleted += other.Deleted
+}
+
+// RepairIndex cleans up the file index: entries whose file no longer exists in OpenWebUI are
+// dropped, and of entries that conflict because they track the same file, only the most
+// recently synced one is kept. A dropped duplicate's own upload is removed from its knowledge
+// base and deleted, as no entry tracks it anymore. Entries that can't be checked or whose file
+// can't be removed stay in the index, so the repair can be re-run safely. The additional
+// targets are repaired too.
+func (m *Manager) RepairIndex(ctx context.Context) (RepairReport, error) {
+	report, err := m.repairIndex(ctx)
+	targetsErr := m.eachTarget(func(target *Manager) error {
+		targetReport, err := target.RepairIndex(ctx)
+		report.add(targetReport)
+		return err
+	})
+	return report, errors.Join(err, targetsErr)
+}
+
+// repairIndex repairs the file index of this manager's OpenWebUI instance
+func (m *Manager) repairIndex(ctx context.Context) (RepairReport, error) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.fileIndex))
+	for fileKey := range m.fileIndex {
+		keys = append(keys, fileKey)
+	}
+	sort.Strings(keys)
+
+	report := RepairReport{Checked: len(keys)}
+	drop := "Dropping"
+	if m.DryRun {
+		drop = "[dry-run] Would drop"
+	}
+	m.log().Infof("Checking %d file index entries against OpenWebUI", len(keys))
+
+	// Several entries can share one uploaded file, so each file is only looked up once
+	exists := make(map[string]bool)
+	dropKeys := make(map[string]bool)
+	failed := 0
+	for _, fileKey := range keys {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		metadata := m.fileIndex[fileKey]
+		if metadata.FileID == "" {
+			continue
+		}
+		found, checked := exists[metadata.FileID]
+		if !checked {
+			_, err := m.openwebuiClient.GetFile(ctx, metadata.FileID)
+			if err != nil && !errors.Is(err, openwebui.ErrFileNotFound) {
+				m.log().Warnf("Failed to check file %s (ID: %s): %v", metadata.Path, metadata.FileID, err)
+				failed++
+				continue
+			}
+			found = err == nil
+			exists[metadata.FileID] = found
+		}
+		if !found {
+			dropKeys[fileKey] = true
+			report.Missing++
+			m.log().Infof("%s index entry %s: file %s no longer exists in OpenWebUI", drop, fileKey, metadata.FileID)
+		}
+	}
+
+	var replacedKeys []string
+	for _, conflict := range m.conflictingEntries(keys, dropKeys) {
+		dropKeys[conflict.key] = true
+		report.Duplicates++
+		metadata := m.fileIndex[conflict.key]
+		m.log().Infof("%s index entry %s: conflicts with another entry of %s", drop, conflict.key, metadata.Path)
+		if metadata.FileID != "" && metadata.FileID != m.fileIndex[conflict.kept].FileID {
+			replacedKeys = append(replacedKeys, conflict.key)
+		}
+	}
+
+	// Files only the dropped duplicates tracked would be left behind in OpenWebUI
+	deleted := make(map[string]bool)
+	for _, fileKey := range replacedKeys {
+		metadata := m.fileIndex[fileKey]
+		if deleted[metadata.FileID] || m.fileIDInUse(metadata.FileID, "", dropKeys) {
+			continue
+		}
+		if m.DryRun {
+			m.log().Infof("[dry-run] Would delete file %s (ID: %s) of index entry %s", metadata.Path, metadata.FileID, fileKey)
+			report.Deleted++
+			continue
+		}
+		if err := m.deleteReplacedFile(ctx, metadata); err != nil {
+			m.log().Warnf("Failed to delete file %s (ID: %s), keeping index entry %s: %v", metadata.Path, metadata.FileID, fileKey, err)
+			delete(dropKeys, fileKey)
+			report.Duplicates--
+			failed++
+			continue
+		}
+		deleted[metadata.FileID] = true
+		report.Deleted++
+	}
+
+	if len(dropKeys) > 0 && !m.DryRun {
+		for fileKey := range dropKeys {
+			delete(m.fileIndex, fileKey)
+		}
+		if err := m.saveFileIndex(); err != nil {
+			return report, fmt.Errorf("failed to save file index: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return report, fmt.Errorf("failed to check or clean up %d of %d file index entries", failed, len(keys))
+	}
+	return report, nil
+}
+
+// deleteReplacedFile removes the file of a dropped index entry from its knowledge base and
+// deletes it. The caller holds m.mu.
+func (m *Manager) deleteReplacedFile(ctx context.Context, metadata *FileMetadata) error {
+	if knowledgeID := m.entryKnowledgeID(metadata); knowledgeID != "" {
+		err := m.removeFileFromKnowledge(ctx, knowledgeID, metadata.FileID)
+		if err != nil && !errors.Is(err, openwebui.ErrFileNotFound) {
+			return fmt.Errorf("failed to remove file from knowledge %s: %w", knowledgeID, err)
+		}
+	}
+	if err := m.openwebuiClient.DeleteFile(ctx, metadata.FileID); err != nil && !errors.Is(err, openwebui.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	m.log().Infof("Deleted file %s (ID: %s) of a dropped duplicate entry", metadata.Path, metadata.FileID)
+	return nil
+}
+
+// indexConflict is an index entry to drop in favour of another entry tracking the same file
+type indexConflict struct {
+	key  string // entry to drop
+	kept string // entry kept instead
+}
+
+// conflictingEntries returns the entries outside dropKeys that track the same file as
+// another entry and should be dropped: entries of a source with the same stable ID, keeping
+// the most recently synced one, and entries initialized from OpenWebUI for a file an adapter
+// entry already tracks in the same knowledge base. keys must be sorted. The caller holds m.mu.
+func (m *Manager) conflictingEntries(keys []string, dropKeys map[string]bool) []indexConflict {
+	byID := make(map[string]string)         // source + ID -> key of the entry kept so far
+	adapterFiles := make(map[string]string) // knowledge ID + file ID -> key of an adapter entry
+	for _, fileKey := range keys {
+		metadata := m.fileIndex[fileKey]
+		if dropKeys[fileKey] || metadata.Source == "openwebui" {
+			continue
+		}
+		if metadata.FileID != "" {
+			if _, ok := adapterFiles[m.entryKnowledgeID(metadata)+"/"+metadata.FileID]; !ok {
+				adapterFiles[m.entryKnowledgeID(metadata)+"/"+metadata.FileID] = fileKey
+			}
+		}
+		if metadata.ID == "" {
+			continue
+		}
+		identity := metadata.Source + "/" + metadata.ID
+		if kept, ok := byID[identity]; !ok || metadata.SyncedAt.After(m.fileIndex[kept].SyncedAt) {
+			byID[identity] = fileKey
+		}
+	}
+
+	var conflicting []indexConflict
+	for _, fileKey := range keys {
+		metadata := m.fileIndex[fileKey]
+		if dropKeys[fileKey] {
+			continue
+		}
+		if metadata.Source == "openwebui" {
+			if kept, ok := adapterFiles[m.entryKnowledgeID(metadata)+"/"+metadata.FileID]; ok {
+				conflicting = append(conflicting, indexConflict{key: fileKey, kept: kept})
+			}
+			continue
+		}
+		if kept := byID[metadata.Source+"/"+metadata.ID]; metadata.ID != "" && kept != fileKey {
+			conflicting = append(conflicting, indexConflict{key: fileKey, kept: kept})
+		}
+	}
+	return conflicting
+}
+
+// entryKnowledgeID returns the knowledge base of an index entry, defaulting to the manager's
+func (m *Manager) entryKnowledgeID(metadata *FileMetadata) string {
+	if metadata.KnowledgeID == "" {
+		return m.knowledgeID
+	}
+	return metadata.KnowledgeID
+}
@@ -0,0 +1,245 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestManager_RepairIndex(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		fileIndex    map[string]*FileMetadata
+		missing      []string // file IDs OpenWebUI answers with 404
+		failing      []string // file IDs OpenWebUI fails to look up
+		failDelete   []string // file IDs OpenWebUI fails to delete
+		dryRun       bool
+		expectedKeys []string
+		expectedDels []string // knowledge ID + file ID of the files removed and deleted
+		expected     RepairReport
+		expectError  bool
+	}{
+		{
+			name: "drops entries of missing files",
+			fileIndex: map[string]*FileMetadata{
+				"github/docs/a.md": {Path: "docs/a.md", FileID: "file-a", Source: "github", KnowledgeID: "knowledge-1"},
+				"github/docs/b.md": {Path: "docs/b.md", FileID: "file-b", Source: "github", KnowledgeID: "knowledge-1"},
+				"jira/PROJ-1.md":   {Path: "PROJ-1.md", FileID: "file-c", Source: "jira", KnowledgeID: "knowledge-2"},
+			},
+			missing:      []string{"file-b", "file-c"},
+			expectedKeys: []string{"github/docs/a.md"},
+			expected:     RepairReport{Checked: 3, Missing: 2},
+		},
+		{
+			name: "drops every entry of a missing shared file",
+			fileIndex: map[string]*FileMetadata{
+				"local/a/notes.md": {Path: "a/notes.md", FileID: "shared", Source: "local", KnowledgeID: "knowledge-1"},
+				"local/b/notes.md": {Path: "b/notes.md", FileID: "shared", Source: "local", KnowledgeID: "knowledge-2"},
+			},
+			missing:  []string{"shared"},
+			expected: RepairReport{Checked: 2, Missing: 2},
+		},
+		{
+			name: "keeps the most recently synced entry of the same item",
+			fileIndex: map[string]*FileMetadata{
+				"confluence/old-title.md": {Path: "old-title.md", FileID: "file-old", Source: "confluence", ID: "page-1", SyncedAt: older},
+				"confluence/new-title.md": {Path: "new-title.md", FileID: "file-new", Source: "confluence", ID: "page-1", SyncedAt: newer},
+				"jira/PROJ-1.md":          {Path: "PROJ-1.md", FileID: "file-jira", Source: "jira", ID: "page-1", SyncedAt: older},
+			},
+			expectedKeys: []string{"confluence/new-title.md", "jira/PROJ-1.md"},
+			expectedDels: []string{"/file-old"},
+			expected:     RepairReport{Checked: 3, Duplicates: 1, Deleted: 1},
+		},
+		{
+			name: "deletes the files of dropped duplicates no other entry tracks",
+			fileIndex: map[string]*FileMetadata{
+				"slack/old.md":      {Path: "old.md", FileID: "file-old", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: older},
+				"slack/new.md":      {Path: "new.md", FileID: "file-new", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: newer},
+				"slack/same.md":     {Path: "same.md", FileID: "file-same", Source: "slack", ID: "C2", KnowledgeID: "knowledge-1", SyncedAt: older},
+				"slack/same-new.md": {Path: "same-new.md", FileID: "file-same", Source: "slack", ID: "C2", KnowledgeID: "knowledge-1", SyncedAt: newer},
+				"slack/shared.md":   {Path: "shared.md", FileID: "file-shared", Source: "slack", ID: "C3", KnowledgeID: "knowledge-1", SyncedAt: older},
+				"slack/shared-2.md": {Path: "shared-2.md", FileID: "file-shared-2", Source: "slack", ID: "C3", KnowledgeID: "knowledge-1", SyncedAt: newer},
+				"local/shared.md":   {Path: "shared.md", FileID: "file-shared", Source: "local", KnowledgeID: "knowledge-2"},
+			},
+			expectedKeys: []string{"local/shared.md", "slack/new.md", "slack/same-new.md", "slack/shared-2.md"},
+			expectedDels: []string{"knowledge-1/file-old"},
+			expected:     RepairReport{Checked: 7, Duplicates: 3, Deleted: 1},
+		},
+		{
+			name: "keeps a dropped duplicate whose file can't be deleted",
+			fileIndex: map[string]*FileMetadata{
+				"slack/old.md": {Path: "old.md", FileID: "file-old", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: older},
+				"slack/new.md": {Path: "new.md", FileID: "file-new", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: newer},
+			},
+			failDelete:   []string{"file-old"},
+			expectedKeys: []string{"slack/new.md", "slack/old.md"},
+			expected:     RepairReport{Checked: 2},
+			expectError:  true,
+		},
+		{
+			name: "dry run doesn't delete the files of dropped duplicates",
+			fileIndex: map[string]*FileMetadata{
+				"slack/old.md": {Path: "old.md", FileID: "file-old", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: older},
+				"slack/new.md": {Path: "new.md", FileID: "file-new", Source: "slack", ID: "C1", KnowledgeID: "knowledge-1", SyncedAt: newer},
+			},
+			dryRun:       true,
+			expectedKeys: []string{"slack/new.md", "slack/old.md"},
+			expected:     RepairReport{Checked: 2, Duplicates: 1, Deleted: 1},
+		},
+		{
+			name: "keeps the remaining entry when the newer duplicate is missing",
+			fileIndex: map[string]*FileMetadata{
+				"confluence/old-title.md": {Path: "old-title.md", FileID: "file-old", Source: "confluence", ID: "page-1", SyncedAt: older},
+				"confluence/new-title.md": {Path: "new-title.md", FileID: "file-new", Source: "confluence", ID: "page-1", SyncedAt: newer},
+			},
+			missing:      []string{"file-new"},
+			expectedKeys: []string{"confluence/old-title.md"},
+			expected:     RepairReport{Checked: 2, Missing: 1},
+		},
+		{
+			name: "drops entries from OpenWebUI shadowed by an adapter entry",
+			fileIndex: map[string]*FileMetadata{
+				"guide.md":        {Path: "guide.md", FileID: "file-guide", Source: "openwebui", KnowledgeID: "knowledge-1"},
+				"github/guide.md": {Path: "guide.md", FileID: "file-guide", Source: "github", KnowledgeID: "knowledge-1"},
+				"other.md":        {Path: "other.md", FileID: "file-guide", Source: "openwebui", KnowledgeID: "knowledge-2"},
+				"dedup/copy.md":   {Path: "copy.md", FileID: "file-guide", Source: "local", KnowledgeID: "knowledge-1"},
+			},
+			expectedKeys: []string{"dedup/copy.md", "github/guide.md", "other.md"},
+			expected:     RepairReport{Checked: 4, Duplicates: 1},
+		},
+		{
+			name: "keeps entries that could not be checked",
+			fileIndex: map[string]*FileMetadata{
+				"github/a.md": {Path: "a.md", FileID: "file-a", Source: "github"},
+				"github/b.md": {Path: "b.md", FileID: "file-b", Source: "github"},
+			},
+			missing:      []string{"file-a"},
+			failing:      []string{"file-b"},
+			expectedKeys: []string{"github/b.md"},
+			expected:     RepairReport{Checked: 2, Missing: 1},
+			expectError:  true,
+		},
+		{
+			name: "dry run leaves the index untouched",
+			fileIndex: map[string]*FileMetadata{
+				"github/a.md": {Path: "a.md", FileID: "file-a", Source: "github"},
+				"github/b.md": {Path: "b.md", FileID: "file-b", Source: "github"},
+			},
+			missing:      []string{"file-a"},
+			dryRun:       true,
+			expectedKeys: []string{"github/a.md", "github/b.md"},
+			expected:     RepairReport{Checked: 2, Missing: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookups := make(map[string]int)
+			removed := make(map[string]bool)
+			var deleted []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				RemoveFileFromKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					removed[knowledgeID+"/"+fileID] = true
+					return nil
+				},
+				DeleteFileFunc: func(ctx context.Context, fileID string) error {
+					for _, failing := range tt.failDelete {
+						if fileID == failing {
+							return fmt.Errorf("delete file failed with status 500: internal error")
+						}
+					}
+					deleted = append(deleted, fileID)
+					return nil
+				},
+				GetFileFunc: func(ctx context.Context, fileID string) (*openwebui.File, error) {
+					lookups[fileID]++
+					for _, missing := range tt.missing {
+						if fileID == missing {
+							return nil, fmt.Errorf("get file %s: %w", fileID, openwebui.ErrFileNotFound)
+						}
+					}
+					for _, failing := range tt.failing {
+						if fileID == failing {
+							return nil, fmt.Errorf("get file failed with status 500: internal error")
+						}
+					}
+					return &openwebui.File{ID: fileID}, nil
+				},
+			}
+
+			store := storage.NewMemory()
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           store,
+				fileIndex:       tt.fileIndex,
+				DryRun:          tt.dryRun,
+			}
+
+			report, err := manager.RepairIndex(context.Background())
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Fatalf("RepairIndex() error = %v", err)
+			}
+			if report != tt.expected {
+				t.Errorf("Expected report %+v, got %+v", tt.expected, report)
+			}
+			var expectedDels []string
+			for _, del := range tt.expectedDels {
+				knowledgeID, fileID, _ := strings.Cut(del, "/")
+				expectedDels = append(expectedDels, fileID)
+				if knowledgeID != "" && !removed[del] {
+					t.Errorf("Expected file %s to be removed from knowledge %s", fileID, knowledgeID)
+				}
+			}
+			if strings.Join(deleted, ",") != strings.Join(expectedDels, ",") {
+				t.Errorf("Expected deleted files %v, got %v", expectedDels, deleted)
+			}
+			for fileID, count := range lookups {
+				if count > 1 {
+					t.Errorf("Expected file %s to be looked up once, got %d lookups", fileID, count)
+				}
+			}
+
+			keys := make([]string, 0, len(manager.fileIndex))
+			for key := range manager.fileIndex {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if strings.Join(keys, ",") != strings.Join(tt.expectedKeys, ",") {
+				t.Errorf("Expected index keys %v, got %v", tt.expectedKeys, keys)
+			}
+
+			// The cleaned index is written unless nothing changed or it's a dry run
+			data, err := store.Read(fileIndexFile)
+			if tt.dryRun || report.Missing+report.Duplicates == 0 {
+				if err == nil {
+					t.Errorf("Expected the file index not to be written, got %s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the cleaned file index to be written: %v", err)
+			}
+			var saved map[string]*FileMetadata
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatalf("Failed to decode saved file index: %v", err)
+			}
+			if len(saved) != len(tt.expectedKeys) {
+				t.Errorf("Expected %d saved entries, got %d", len(tt.expectedKeys), len(saved))
+			}
+		})
+	}
+}
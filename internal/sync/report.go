@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxReportErrors is the number of per-file error messages kept in a SyncReport
+const maxReportErrors = 5
+
+// SyncReport is the outcome of a sync run: how many files were synced, failed or skipped,
+// and the messages of the first few failures
+type SyncReport struct {
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Skipped   int      `json:"skipped"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Err returns an error describing the failed files, or nil when every file synced
+func (r SyncReport) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("%d files failed to sync", r.Failed)
+	if len(r.Errors) > 0 {
+		msg += ": " + strings.Join(r.Errors, "; ")
+	}
+	return errors.New(msg)
+}
+
+// recordFileErrors adds per-file sync errors to the current run
+func (m *Manager) recordFileErrors(errs []error) {
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+	m.fileErrors = append(m.fileErrors, errs...)
+}
+
+// Report returns the outcome of the most recent sync run
+func (m *Manager) Report() SyncReport {
+	m.summaryMu.Lock()
+	defer m.summaryMu.Unlock()
+
+	report := SyncReport{
+		Succeeded: m.summary.Uploaded + m.summary.Linked + m.summary.Updated,
+		Failed:    m.summary.Failed,
+		Skipped:   m.summary.Skipped,
+	}
+	for i, err := range m.fileErrors {
+		if i == maxReportErrors {
+			break
+		}
+		report.Errors = append(report.Errors, err.Error())
+	}
+	return report
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+)
+
+func TestManager_SyncFiles_Report(t *testing.T) {
+	tempDir := t.TempDir()
+
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			if strings.HasPrefix(filename, "broken") {
+				return nil, fmt.Errorf("upload rejected")
+			}
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "partial" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			files := []*adapter.File{
+				{Path: "ok.md", Content: []byte("# OK"), Hash: "hash-ok", KnowledgeID: "knowledge-id"},
+				{Path: "empty.md", Content: nil, Hash: "hash-empty", KnowledgeID: "knowledge-id"},
+			}
+			for i := 0; i < maxReportErrors+2; i++ {
+				files = append(files, &adapter.File{
+					Path:        fmt.Sprintf("broken-%d.md", i),
+					Content:     []byte("# Broken"),
+					Hash:        fmt.Sprintf("hash-broken-%d", i),
+					KnowledgeID: "knowledge-id",
+				})
+			}
+			return files, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+
+	err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter})
+	if err == nil {
+		t.Fatal("Expected SyncFiles to return an error when files fail")
+	}
+	if !strings.Contains(err.Error(), "7 files failed to sync") || !strings.Contains(err.Error(), "upload rejected") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	report := manager.Report()
+	if report.Succeeded != 1 || report.Failed != maxReportErrors+2 || report.Skipped != 1 {
+		t.Errorf("Unexpected report counts: %+v", report)
+	}
+	if len(report.Errors) != maxReportErrors {
+		t.Errorf("Expected %d error messages, got %d", maxReportErrors, len(report.Errors))
+	}
+	if len(report.Errors) > 0 && !strings.HasPrefix(report.Errors[0], "broken-0.md: ") {
+		t.Errorf("Expected error messages to name the file, got %q", report.Errors[0])
+	}
+
+	// A clean run resets the report
+	mockAdapter.FetchFilesFunc = func(ctx context.Context) ([]*adapter.File, error) {
+		return []*adapter.File{{Path: "ok.md", Content: []byte("# OK"), Hash: "hash-ok", KnowledgeID: "knowledge-id"}}, nil
+	}
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Expected a clean sync, got %v", err)
+	}
+	if report := manager.Report(); report.Failed != 0 || len(report.Errors) != 0 || report.Skipped != 1 {
+		t.Errorf("Expected the report to be reset, got %+v", report)
+	}
+}
+
+func TestSyncReport_Err(t *testing.T) {
+	if err := (SyncReport{Succeeded: 3, Skipped: 1}).Err(); err != nil {
+		t.Errorf("Expected no error without failures, got %v", err)
+	}
+
+	err := SyncReport{Failed: 2, Errors: []string{"a.md: boom", "b.md: bang"}}.Err()
+	if err == nil || err.Error() != "2 files failed to sync: a.md: boom; b.md: bang" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package sync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+const (
+	// snapshotDir is the storage key prefix of source snapshots
+	snapshotDir = "snapshots"
+	// snapshotTimeFormat is the timestamp suffix of snapshot files. It has a fixed length and
+	// sorts chronologically.
+	snapshotTimeFormat = "20060102T150405.000000000Z"
+	// gzipSuffix marks compressed snapshots
+	gzipSuffix = ".gz"
+)
+
+// SnapshotStore keeps the raw source content of every synced version of a file as
+// snapshots/<source>/<path>.<timestamp>, gzipped unless compression is disabled, and prunes
+// each file's history to the newest versions. The source is the adapter name, followed by the
+// file's origin within the adapter, if any.
+type SnapshotStore struct {
+	store     storage.Storage
+	compress  bool
+	retention int
+	now       func() time.Time
+	mu        sync.Mutex // serializes saves, so concurrent workers don't prune each other's versions
+}
+
+// NewSnapshotStore creates a store in store, or returns nil when snapshot history is disabled
+func NewSnapshotStore(cfg config.StorageConfig, store storage.Storage) *SnapshotStore {
+	if !cfg.SnapshotHistory {
+		return nil
+	}
+	retention := cfg.SnapshotRetention
+	if retention < 1 {
+		retention = 1
+	}
+	return &SnapshotStore{
+		store:     store,
+		compress:  cfg.SnapshotCompression != "none",
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Save stores content as the newest version of a source file. Content equal to the newest
+// stored version is not stored again. Pruned versions are logged to log.
+func (s *SnapshotStore) Save(source, path string, content []byte, log logging.Logger) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.List(source, path)
+	if err != nil {
+		return err
+	}
+	if len(versions) > 0 {
+		latest, err := s.Read(versions[len(versions)-1])
+		if err == nil && bytes.Equal(latest, content) {
+			return nil
+		}
+	}
+
+	data := content
+	name := s.baseKey(source, path) + "." + s.now().UTC().Format(snapshotTimeFormat)
+	if s.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(content); err != nil {
+			return fmt.Errorf("failed to compress snapshot: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress snapshot: %w", err)
+		}
+		data = buf.Bytes()
+		name += gzipSuffix
+	}
+
+	if err := s.store.Write(name, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return s.prune(append(versions, name), log)
+}
+
+// List returns the storage keys of a source file's stored versions, oldest first
+func (s *SnapshotStore) List(source, path string) ([]string, error) {
+	prefix := s.baseKey(source, path) + "."
+	keys, err := s.store.List(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var versions []string
+	for _, key := range keys {
+		// Only the timestamp may follow the prefix, so "a.md" doesn't match versions of "a.md.bak"
+		stamp := strings.TrimSuffix(strings.TrimPrefix(key, prefix), gzipSuffix)
+		if _, err := time.Parse(snapshotTimeFormat, stamp); err != nil {
+			continue
+		}
+		versions = append(versions, key)
+	}
+
+	// Compressed and uncompressed versions sort by their timestamp alone
+	sort.Slice(versions, func(i, j int) bool {
+		return strings.TrimSuffix(versions[i], gzipSuffix) < strings.TrimSuffix(versions[j], gzipSuffix)
+	})
+	return versions, nil
+}
+
+// Read returns the content stored in a snapshot, decompressing it if needed
+func (s *SnapshotStore) Read(key string) ([]byte, error) {
+	data, err := s.store.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if !strings.HasSuffix(key, gzipSuffix) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	defer zr.Close()
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	return content, nil
+}
+
+// prune removes the oldest of a file's versions beyond the retention count
+func (s *SnapshotStore) prune(versions []string, log logging.Logger) error {
+	if len(versions) <= s.retention {
+		return nil
+	}
+	for _, version := range versions[:len(versions)-s.retention] {
+		if err := s.store.Delete(version); err != nil {
+			return fmt.Errorf("failed to prune snapshot: %w", err)
+		}
+		log.Debugf("Pruned snapshot %s", version)
+	}
+	return nil
+}
+
+// baseKey returns the snapshot key of a source file without the timestamp suffix
+func (s *SnapshotStore) baseKey(source, filePath string) string {
+	return path.Join(snapshotDir, source, filepath.ToSlash(filePath))
+}
@@ -0,0 +1,208 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// newTestSnapshotStore returns a store whose clock advances by a minute on every save
+func newTestSnapshotStore(t *testing.T, compression string, retention int) *SnapshotStore {
+	t.Helper()
+	store := NewSnapshotStore(config.StorageConfig{
+		SnapshotHistory:     true,
+		SnapshotCompression: compression,
+		SnapshotRetention:   retention,
+	}, storage.NewMemory())
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return store
+}
+
+func TestSnapshotStore_SaveAndRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression string
+		suffix      string
+	}{
+		{name: "gzip", compression: "gzip", suffix: ".gz"},
+		{name: "default compression", compression: "", suffix: ".gz"},
+		{name: "uncompressed", compression: "none", suffix: "Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestSnapshotStore(t, tt.compression, 10)
+
+			for _, content := range []string{"# Version 1", "# Version 2", "# Version 2"} {
+				if err := store.Save("github", "docs/guide.md", []byte(content), logging.Default()); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			versions, err := store.List("github", "docs/guide.md")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			// Saving unchanged content doesn't add a version
+			if len(versions) != 2 {
+				t.Fatalf("Expected 2 versions, got %v", versions)
+			}
+			for i, expected := range []string{"# Version 1", "# Version 2"} {
+				if !strings.HasSuffix(versions[i], tt.suffix) {
+					t.Errorf("Expected version %s to end with %q", versions[i], tt.suffix)
+				}
+				content, err := store.Read(versions[i])
+				if err != nil {
+					t.Fatalf("Read() error = %v", err)
+				}
+				if string(content) != expected {
+					t.Errorf("Expected version %d to contain %q, got %q", i, expected, content)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotStore_Retention(t *testing.T) {
+	store := newTestSnapshotStore(t, "gzip", 2)
+
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		if err := store.Save("local", "notes.md", []byte(content), logging.Default()); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	// Versions of a file whose name starts with the same name are kept separately
+	if err := store.Save("local", "notes.md.bak", []byte("backup"), logging.Default()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	versions, err := store.List("local", "notes.md")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected the 2 newest versions to be kept, got %v", versions)
+	}
+	for i, expected := range []string{"v3", "v4"} {
+		content, err := store.Read(versions[i])
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected kept version %d to contain %q, got %q", i, expected, content)
+		}
+	}
+
+	if backups, err := store.List("local", "notes.md.bak"); err != nil || len(backups) != 1 {
+		t.Errorf("Expected 1 version of notes.md.bak, got %v (err: %v)", backups, err)
+	}
+}
+
+func TestManager_SyncFiles_SnapshotsRawContent(t *testing.T) {
+	tempDir := t.TempDir()
+	templatePath := filepath.Join(tempDir, "wrap.tmpl")
+	if err := os.WriteFile(templatePath, []byte("Source: {{.Source}}\n{{.Content}}"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	tmpl, err := loadContentTemplate(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+	store := newTestSnapshotStore(t, "gzip", 10)
+
+	content := "# Guide\n"
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "docs/guide.md", Content: []byte(content), Hash: GetFileHash([]byte(content)), KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: &mocks.MockOpenWebUIClient{},
+		store:           storage.NewMemory(),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		contentTemplate: tmpl,
+		snapshots:       store,
+	}
+
+	for _, version := range []string{"# Guide\n", "# Guide\n", "# Guide v2\n"} {
+		content = version
+		if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	versions, err := store.List("github", "docs/guide.md")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected a snapshot per synced version, got %v", versions)
+	}
+	latest, err := store.Read(versions[1])
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(latest) != "# Guide v2\n" {
+		t.Errorf("Expected the snapshot to hold the untemplated content, got %q", latest)
+	}
+}
+
+func TestManager_SyncFiles_SnapshotsPerOrigin(t *testing.T) {
+	store := newTestSnapshotStore(t, "none", 10)
+
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "README.md", Source: "owner/api", Content: []byte("# API\n"), Hash: GetFileHash([]byte("# API\n")), KnowledgeID: "knowledge-id"},
+				{Path: "README.md", Source: "owner/web", Content: []byte("# Web\n"), Hash: GetFileHash([]byte("# Web\n")), KnowledgeID: "knowledge-id"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: &mocks.MockOpenWebUIClient{},
+		store:           storage.NewMemory(),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		snapshots:       store,
+	}
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for origin, expected := range map[string]string{"github/owner/api": "# API\n", "github/owner/web": "# Web\n"} {
+		versions, err := store.List(origin, "README.md")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(versions) != 1 {
+			t.Fatalf("Expected one snapshot of %s, got %v", origin, versions)
+		}
+		content, err := store.Read(versions[0])
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected the snapshot of %s to contain %q, got %q", origin, expected, content)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+)
+
+// AdapterStatus describes the most recent sync run of a single adapter
+type AdapterStatus struct {
+	LastSync        time.Time `json:"last_sync"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	FilesSynced     int       `json:"files_synced"`
+	FilesFailed     int       `json:"files_failed"`
+	Error           string    `json:"error,omitempty"`
+	Coverage        any       `json:"coverage,omitempty"` // reported by adapters implementing adapter.CoverageReporter
+}
+
+// SyncStatus is a snapshot of the last sync run of every adapter. LastSync is the most
+// recent adapter run, NextSync is filled in by the scheduler; both are nil when unknown.
+// FailedFiles counts the files in the dead-letter store. Targets holds the status of every
+// additional OpenWebUI target by name.
+type SyncStatus struct {
+	LastSync    *time.Time               `json:"last_sync"`
+	NextSync    *time.Time               `json:"next_sync"`
+	FailedFiles int                      `json:"failed_files"`
+	Adapters    map[string]AdapterStatus `json:"adapters"`
+	Targets     map[string]SyncStatus    `json:"targets,omitempty"`
+}
+
+// recordAdapterStatus stores the outcome of an adapter's sync run that started at start
+func (m *Manager) recordAdapterStatus(adpt adapter.Adapter, start time.Time, synced, failed int, err error) {
+	status := AdapterStatus{
+		LastSync:        time.Now(),
+		DurationSeconds: time.Since(start).Seconds(),
+		FilesSynced:     synced,
+		FilesFailed:     failed,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if reporter, ok := adpt.(adapter.CoverageReporter); ok {
+		status.Coverage = reporter.Coverage()
+	}
+
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	if m.adapterStatus == nil {
+		m.adapterStatus = make(map[string]AdapterStatus)
+	}
+	m.adapterStatus[adpt.Name()] = status
+}
+
+// Status returns the last sync run of every adapter that has synced since startup
+func (m *Manager) Status() SyncStatus {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	status := SyncStatus{Adapters: make(map[string]AdapterStatus, len(m.adapterStatus))}
+	if m.deadLetters != nil {
+		status.FailedFiles = m.deadLetters.Count()
+	}
+	for name, adapterStatus := range m.adapterStatus {
+		status.Adapters[name] = adapterStatus
+		if status.LastSync == nil || adapterStatus.LastSync.After(*status.LastSync) {
+			lastSync := adapterStatus.LastSync
+			status.LastSync = &lastSync
+		}
+	}
+	for _, target := range m.targets {
+		if status.Targets == nil {
+			status.Targets = make(map[string]SyncStatus, len(m.targets))
+		}
+		status.Targets[target.targetName] = target.Status()
+	}
+	return status
+}
@@ -0,0 +1,219 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/openwebui-content-sync/internal/logging"
+)
+
+// newTargetManagers creates a manager for every additional OpenWebUI target. Each target keeps
+// its own file index below targets/<name> of the storage, since file IDs differ per instance,
+// and shares the client and sync settings of the main instance.
+func newTargetManagers(openwebuiConfig config.OpenWebUIConfig, storageConfig config.StorageConfig, syncConfig config.SyncConfig, httpConfig config.HTTPConfig) ([]*Manager, error) {
+	var targets []*Manager
+	for _, target := range openwebuiConfig.Targets {
+		targetConfig := openwebuiConfig
+		targetConfig.BaseURL = target.BaseURL
+		targetConfig.APIKey = target.APIKey
+		targetConfig.Targets = nil
+
+		// Snapshots of the source content are kept once, by the main instance
+		targetStorage := config.StorageConfig{
+			Backend: storageConfig.Backend,
+			Path:    filepath.Join(storageConfig.Path, "targets", target.Name),
+			S3:      storageConfig.S3,
+		}
+		targetStorage.S3.Prefix = path.Join(storageConfig.S3.Prefix, "targets", target.Name)
+
+		manager, err := NewManager(targetConfig, targetStorage, syncConfig, httpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		// The main instance's sync advances incremental adapters
+		manager.lastSync = nil
+		manager.targetName = target.Name
+		manager.targetKnowledgeIDs = target.KnowledgeIDs
+		manager.logger = logging.Default().WithField("target", target.Name)
+		targets = append(targets, manager)
+	}
+	return targets, nil
+}
+
+// targetKnowledgeID returns the knowledge ID on this target for a knowledge ID of the main instance
+func (m *Manager) targetKnowledgeID(knowledgeID string) string {
+	if mapped, ok := m.targetKnowledgeIDs[knowledgeID]; ok {
+		return mapped
+	}
+	return knowledgeID
+}
+
+// targetFile returns a copy of a fetched file assigned to this target's knowledge base
+func (m *Manager) targetFile(file *adapter.File) *adapter.File {
+	copied := *file
+	if copied.KnowledgeID != "" {
+		copied.KnowledgeID = m.targetKnowledgeID(copied.KnowledgeID)
+	}
+	return &copied
+}
+
+// eachTarget runs fn for every additional target and joins their errors
+func (m *Manager) eachTarget(fn func(target *Manager) error) error {
+	var errs []error
+	for _, target := range m.targets {
+		target.DryRun = m.DryRun
+		if err := fn(target); err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", target.targetName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// adapterFetch is the outcome of an adapter's fetch during the current run, replayed to the
+// additional targets so every source is only fetched once
+type adapterFetch struct {
+	adpt     adapter.Adapter
+	files    []*adapter.File
+	err      error
+	complete bool
+	full     bool // a full fetch was requested from the incremental adapter
+}
+
+// recordFetch keeps the outcome of an adapter's fetch for the additional targets
+func (m *Manager) recordFetch(adpt adapter.Adapter, files []*adapter.File, err error, full bool, current *currentFiles) {
+	if len(m.targets) == 0 {
+		return
+	}
+	fetch := &adapterFetch{adpt: adpt, files: files, err: err, full: full}
+	if fetcher, ok := adpt.(adapter.CompleteFetcher); ok {
+		fetch.complete = fetcher.FetchComplete()
+	}
+	current.fetches = append(current.fetches, fetch)
+}
+
+// requestFullFetch asks an incremental adapter for every file of its source when a target lacks
+// content an incremental fetch wouldn't return again: the target has no files of the adapter
+// yet, e.g. because it was just added, or files of it that failed to sync there are due for
+// their retry. The same goes for files that failed to sync to the main instance. It reports
+// whether a full fetch was requested; a target replaying a fetch reports the main instance's.
+func (m *Manager) requestFullFetch(adpt adapter.Adapter) bool {
+	if replay, ok := adpt.(*replayAdapter); ok {
+		return replay.full
+	}
+	fetcher, ok := adpt.(adapter.FullFetcher)
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+	if m.deadLetters != nil && m.deadLetters.Due(adpt.Name(), now) {
+		m.log().Infof("Files of adapter %s that failed to sync are due for their retry, fetching every file", adpt.Name())
+		fetcher.RequestFullFetch()
+		return true
+	}
+	for _, target := range m.targets {
+		target.mu.Lock()
+		synced := false
+		for _, metadata := range target.fileIndex {
+			if metadata.Source == adpt.Name() {
+				synced = true
+				break
+			}
+		}
+		target.mu.Unlock()
+
+		failed := target.deadLetters != nil && target.deadLetters.Due(adpt.Name(), now)
+		if !synced || failed {
+			m.log().Infof("Target %s lacks files of adapter %s, fetching every file", target.targetName, adpt.Name())
+			fetcher.RequestFullFetch()
+			return true
+		}
+	}
+	return false
+}
+
+// syncTargets syncs the fetches of the current run to every additional target. With all set,
+// every adapter was fetched and the targets remove their orphaned files too.
+func (m *Manager) syncTargets(ctx context.Context, fetches []*adapterFetch, all bool) error {
+	return m.eachTarget(func(target *Manager) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.log().Infof("Syncing to OpenWebUI target %s", target.targetName)
+
+		adapters := make([]adapter.Adapter, 0, len(fetches))
+		for _, fetch := range fetches {
+			adapters = append(adapters, target.replayAdapter(fetch))
+		}
+		if all {
+			return target.SyncFiles(ctx, adapters)
+		}
+		for _, adpt := range adapters {
+			if err := target.SyncAdapter(ctx, adpt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replayAdapter returns an adapter that hands this target the files of a fetch of the main instance
+func (m *Manager) replayAdapter(fetch *adapterFetch) *replayAdapter {
+	replay := &replayAdapter{Adapter: fetch.adpt, err: fetch.err, complete: fetch.complete, full: fetch.full}
+	for _, file := range fetch.files {
+		replay.files = append(replay.files, m.targetFile(file))
+	}
+	if provider, ok := fetch.adpt.(adapter.KnowledgeIDProvider); ok {
+		for _, knowledgeID := range provider.KnowledgeIDs() {
+			replay.knowledgeIDs = append(replay.knowledgeIDs, m.targetKnowledgeID(knowledgeID))
+		}
+	}
+	return replay
+}
+
+// replayAdapter replays an adapter's fetch of the current run to a target, with the knowledge
+// IDs mapped to the target's
+type replayAdapter struct {
+	adapter.Adapter
+	files        []*adapter.File
+	err          error
+	complete     bool
+	full         bool
+	knowledgeIDs []string
+}
+
+func (r *replayAdapter) FetchFiles(ctx context.Context) ([]*adapter.File, error) {
+	return r.files, r.err
+}
+
+func (r *replayAdapter) FetchComplete() bool {
+	return r.complete
+}
+
+func (r *replayAdapter) KnowledgeIDs() []string {
+	return r.knowledgeIDs
+}
+
+func (r *replayAdapter) Coverage() any {
+	if reporter, ok := r.Adapter.(adapter.CoverageReporter); ok {
+		return reporter.Coverage()
+	}
+	return nil
+}
+
+func (r *replayAdapter) LoadContent(ctx context.Context, file *adapter.File) error {
+	loader, ok := r.Adapter.(adapter.ContentLoader)
+	if !ok {
+		return fmt.Errorf("adapter %s can't load file content", r.Name())
+	}
+	return loader.LoadContent(ctx, file)
+}
+
+// SetLastSync is a no-op, the main instance's sync already advanced the adapter
+func (r *replayAdapter) SetLastSync(t time.Time) {}
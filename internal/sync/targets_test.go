@@ -0,0 +1,187 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/logging"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+// recordingClient returns a mock client that records uploaded filenames and the knowledge
+// bases files were added to, giving uploads IDs prefixed with prefix
+func recordingClient(prefix string, uploaded *[]string, added map[string][]string) *mocks.MockOpenWebUIClient {
+	return &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			*uploaded = append(*uploaded, filename)
+			return &openwebui.File{ID: prefix + "-" + filename, Filename: filename}, nil
+		},
+		AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+			added[knowledgeID] = append(added[knowledgeID], fileID)
+			return nil
+		},
+	}
+}
+
+func TestManager_SyncFiles_Targets(t *testing.T) {
+	mainDir, stagingDir := t.TempDir(), t.TempDir()
+
+	var mainUploads, stagingUploads []string
+	mainAdded, stagingAdded := make(map[string][]string), make(map[string][]string)
+
+	staging := &Manager{
+		openwebuiClient:    recordingClient("staging", &stagingUploads, stagingAdded),
+		store:              newLocalStore(t, stagingDir),
+		concurrency:        1,
+		fileIndex:          make(map[string]*FileMetadata),
+		targetName:         "staging",
+		targetKnowledgeIDs: map[string]string{"knowledge-docs": "staging-docs"},
+	}
+	manager := &Manager{
+		openwebuiClient: recordingClient("main", &mainUploads, mainAdded),
+		store:           newLocalStore(t, mainDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		targets:         []*Manager{staging},
+	}
+
+	fetches := 0
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			fetches++
+			return []*adapter.File{
+				{Path: "docs.md", Content: []byte("docs"), Hash: "hash-docs", KnowledgeID: "knowledge-docs"},
+				{Path: "shared.md", Content: []byte("shared"), Hash: "hash-shared", KnowledgeID: "knowledge-shared"},
+			}, nil
+		},
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("Expected the adapter to be fetched once for both targets, got %d fetches", fetches)
+	}
+	expectedUploads := []string{"docs.md", "shared.md"}
+	if !reflect.DeepEqual(mainUploads, expectedUploads) {
+		t.Errorf("Expected the main instance to receive %v, got %v", expectedUploads, mainUploads)
+	}
+	if !reflect.DeepEqual(stagingUploads, expectedUploads) {
+		t.Errorf("Expected the staging target to receive %v, got %v", expectedUploads, stagingUploads)
+	}
+
+	expectedMain := map[string][]string{"knowledge-docs": {"main-docs.md"}, "knowledge-shared": {"main-shared.md"}}
+	if !reflect.DeepEqual(mainAdded, expectedMain) {
+		t.Errorf("Expected main knowledge additions %v, got %v", expectedMain, mainAdded)
+	}
+	// Mapped knowledge IDs are replaced, unmapped ones are used as they are
+	expectedStaging := map[string][]string{"staging-docs": {"staging-docs.md"}, "knowledge-shared": {"staging-shared.md"}}
+	if !reflect.DeepEqual(stagingAdded, expectedStaging) {
+		t.Errorf("Expected staging knowledge additions %v, got %v", expectedStaging, stagingAdded)
+	}
+
+	if fileID := staging.fileIndex["github/docs.md@staging-docs"].FileID; fileID != "staging-docs.md" {
+		t.Errorf("Expected the staging target to index its own file ID, got %s", fileID)
+	}
+	if fileID := manager.fileIndex["github/docs.md@knowledge-docs"].FileID; fileID != "main-docs.md" {
+		t.Errorf("Expected the main instance to index its own file ID, got %s", fileID)
+	}
+	if status := manager.Status(); status.Targets["staging"].Adapters["github"].FilesSynced != 2 {
+		t.Errorf("Expected the staging target's status to be reported, got %+v", status.Targets)
+	}
+}
+
+// fullFetchAdapter returns only the changed files, unless a full fetch was requested
+type fullFetchAdapter struct {
+	mocks.MockAdapter
+	files     []*adapter.File
+	changed   []*adapter.File
+	fullFetch bool
+	requested int
+}
+
+func (a *fullFetchAdapter) RequestFullFetch() {
+	a.fullFetch = true
+	a.requested++
+}
+
+func (a *fullFetchAdapter) FetchFiles(ctx context.Context) ([]*adapter.File, error) {
+	files := a.changed
+	if a.fullFetch {
+		files = a.files
+	}
+	a.fullFetch = false
+	return files, nil
+}
+
+func TestManager_SyncFiles_TargetBackfill(t *testing.T) {
+	var mainUploads, stagingUploads []string
+	stagingFails := map[string]bool{"b.md": true}
+	stagingClient := recordingClient("staging", &stagingUploads, make(map[string][]string))
+	recordUpload := stagingClient.UploadFileFunc
+	stagingClient.UploadFileFunc = func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+		if stagingFails[filename] {
+			return nil, errors.New("upload rejected")
+		}
+		return recordUpload(ctx, filename, contentType, content)
+	}
+
+	stagingStore := storage.NewMemory()
+	staging := &Manager{
+		openwebuiClient: stagingClient,
+		store:           stagingStore,
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		deadLetters:     NewDeadLetterStore(stagingStore, logging.Default()),
+		targetName:      "staging",
+	}
+	// The main instance synced both files before the staging target was added
+	manager := &Manager{
+		openwebuiClient: recordingClient("main", &mainUploads, make(map[string][]string)),
+		store:           storage.NewMemory(),
+		concurrency:     1,
+		fileIndex: map[string]*FileMetadata{
+			"jira/a.md@knowledge-id": {Path: "a.md", Hash: "hash-a", FileID: "main-a.md", Source: "jira", KnowledgeID: "knowledge-id"},
+			"jira/b.md@knowledge-id": {Path: "b.md", Hash: "hash-b", FileID: "main-b.md", Source: "jira", KnowledgeID: "knowledge-id"},
+		},
+		targets: []*Manager{staging},
+	}
+	incremental := &fullFetchAdapter{
+		MockAdapter: mocks.MockAdapter{NameFunc: func() string { return "jira" }},
+		files: []*adapter.File{
+			{Path: "a.md", Content: []byte("# A"), Hash: "hash-a", KnowledgeID: "knowledge-id"},
+			{Path: "b.md", Content: []byte("# B"), Hash: "hash-b", KnowledgeID: "knowledge-id"},
+		},
+	}
+	run := func() {
+		t.Helper()
+		mainUploads, stagingUploads = nil, nil
+		manager.SyncFiles(context.Background(), []adapter.Adapter{incremental})
+	}
+
+	// The new target gets every file, of which b.md fails
+	run()
+	if incremental.requested != 1 || len(mainUploads) != 0 || !reflect.DeepEqual(stagingUploads, []string{"a.md"}) {
+		t.Errorf("Expected a full fetch backfilling the target, got %d requests, main uploads %v, staging uploads %v", incremental.requested, mainUploads, stagingUploads)
+	}
+
+	// The failed file is retried through another full fetch
+	delete(stagingFails, "b.md")
+	run()
+	if incremental.requested != 2 || !reflect.DeepEqual(stagingUploads, []string{"b.md"}) {
+		t.Errorf("Expected a full fetch retrying b.md, got %d requests, staging uploads %v", incremental.requested, stagingUploads)
+	}
+
+	// With the target caught up, fetches are incremental again
+	run()
+	if incremental.requested != 2 || len(stagingUploads) != 0 {
+		t.Errorf("Expected no further full fetch, got %d requests, staging uploads %v", incremental.requested, stagingUploads)
+	}
+}
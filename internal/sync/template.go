@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+)
+
+// TemplateData is the data a content template is rendered with
+type TemplateData struct {
+	Source      string    // adapter name, e.g. "github"
+	Path        string    // file path as reported by the adapter
+	Filename    string    // file name uploaded to OpenWebUI
+	KnowledgeID string    // target knowledge base
+	ContentType string    // MIME type of the content
+	Modified    time.Time // modification time reported by the adapter
+	SyncedAt    time.Time // time the file is rendered for upload
+	Content     string    // original file content
+}
+
+// contentTemplate is a parsed sync.content_template file
+type contentTemplate struct {
+	tmpl   *template.Template
+	digest string // hash of the template source, part of every rendered file's hash
+}
+
+// loadContentTemplate parses the text/template file at path
+func loadContentTemplate(path string) (*contentTemplate, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content template: %w", err)
+	}
+	sum := sha256.Sum256(source)
+	return &contentTemplate{tmpl: tmpl, digest: hex.EncodeToString(sum[:])}, nil
+}
+
+// applyContentTemplate returns a copy of file with its content rendered through the content
+// template. Files are returned unchanged without a template and for non-text content.
+//
+// The copy's hash combines the original hash with the template's, instead of hashing the
+// rendered content, so templates using SyncedAt don't make every file look changed while
+// editing the template still re-uploads everything.
+func (m *Manager) applyContentTemplate(file *adapter.File, source, knowledgeID string) (*adapter.File, error) {
+	if m.contentTemplate == nil || !isTemplatedContentType(file.ContentType) {
+		return file, nil
+	}
+
+	data := TemplateData{
+		Source:      source,
+		Path:        file.Path,
+		Filename:    filepath.Base(file.Path),
+		KnowledgeID: knowledgeID,
+		ContentType: file.ContentType,
+		Modified:    file.Modified,
+		SyncedAt:    time.Now(),
+		Content:     string(file.Content),
+	}
+	var buf bytes.Buffer
+	if err := m.contentTemplate.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render content template for %s: %w", file.Path, err)
+	}
+
+	rendered := *file
+	rendered.Content = buf.Bytes()
+	rendered.Size = int64(buf.Len())
+	rendered.Hash = m.syncedHash(file)
+	return &rendered, nil
+}
+
+// syncedHash returns the hash a file is indexed with once synced: the combined hash of
+// applyContentTemplate for content the template wraps, or the file's own hash
+func (m *Manager) syncedHash(file *adapter.File) string {
+	if m.contentTemplate == nil || !isTemplatedContentType(file.ContentType) {
+		return file.Hash
+	}
+	hash := sha256.Sum256([]byte(file.Hash + "\x00" + m.contentTemplate.digest))
+	return hex.EncodeToString(hash[:])
+}
+
+// isTemplatedContentType reports whether content of this type is text the template can wrap
+func isTemplatedContentType(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "text/")
+}
@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+)
+
+const frontMatterTemplate = `---
+source: {{.Source}}
+path: {{.Path}}
+knowledge_id: {{.KnowledgeID}}
+modified: {{.Modified.Format "2006-01-02"}}
+---
+{{.Content}}`
+
+func TestManager_SyncFiles_ContentTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	templatePath := filepath.Join(tempDir, "front-matter.tmpl")
+	if err := os.WriteFile(templatePath, []byte(frontMatterTemplate), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	tmpl, err := loadContentTemplate(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+
+	uploaded := make(map[string]string)
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploaded[filename] = string(content)
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	modified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "github" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{
+				{Path: "docs/guide.md", Content: []byte("# Guide\n"), Hash: "hash-guide", KnowledgeID: "knowledge-id", ContentType: "text/markdown", Modified: modified},
+				{Path: "logo.png", Content: []byte("\x89PNG"), Hash: "hash-logo", KnowledgeID: "knowledge-id", ContentType: "image/png"},
+			}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		contentTemplate: tmpl,
+	}
+
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "---\nsource: github\npath: docs/guide.md\nknowledge_id: knowledge-id\nmodified: 2025-03-01\n---\n# Guide\n"
+	if uploaded["guide.md"] != expected {
+		t.Errorf("Expected rendered content %q, got %q", expected, uploaded["guide.md"])
+	}
+	if uploaded["logo.png"] != "\x89PNG" {
+		t.Errorf("Expected binary content to be uploaded unchanged, got %q", uploaded["logo.png"])
+	}
+
+	// The rendered hash is stable, so an unchanged file is skipped on the next run
+	uploaded = make(map[string]string)
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(uploaded) != 0 {
+		t.Errorf("Expected no uploads for unchanged files, got %v", uploaded)
+	}
+}
+
+func TestManager_SyncFiles_ContentTemplateUnchangedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	templatePath := filepath.Join(tempDir, "front-matter.tmpl")
+	if err := os.WriteFile(templatePath, []byte(frontMatterTemplate), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	tmpl, err := loadContentTemplate(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+
+	uploads := 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads++
+			return &openwebui.File{ID: "id-" + filename, Filename: filename}, nil
+		},
+	}
+
+	// The file is read on the first fetch and reported unchanged afterwards
+	unchanged := false
+	loader := &contentLoaderAdapter{
+		MockAdapter: mocks.MockAdapter{
+			NameFunc: func() string { return "local" },
+			FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+				file := &adapter.File{Path: "guide.md", Hash: "hash-guide", KnowledgeID: "knowledge-id", ContentType: "text/markdown", Unchanged: unchanged}
+				if !unchanged {
+					file.Content = []byte("# Guide\n")
+				}
+				return []*adapter.File{file}, nil
+			},
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           newLocalStore(t, tempDir),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+		contentTemplate: tmpl,
+	}
+	for i := 0; i < 2; i++ {
+		if err := manager.SyncFiles(context.Background(), []adapter.Adapter{loader}); err != nil {
+			t.Fatalf("Sync %d failed: %v", i+1, err)
+		}
+		unchanged = true
+	}
+
+	// The index holds the templated hash, which an unchanged file must still match
+	if len(loader.loaded) != 0 {
+		t.Errorf("Expected the synced file not to be loaded again, got %v", loader.loaded)
+	}
+	if uploads != 1 {
+		t.Errorf("Expected 1 upload, got %d", uploads)
+	}
+}
+
+func TestManager_applyContentTemplate_NoTemplate(t *testing.T) {
+	manager := &Manager{}
+	file := &adapter.File{Path: "a.md", Content: []byte("# A"), Hash: "hash-a"}
+
+	rendered, err := manager.applyContentTemplate(file, "local", "knowledge-id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rendered != file {
+		t.Errorf("Expected the file to be returned untouched without a template")
+	}
+}
+
+func TestLoadContentTemplate_Invalid(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "broken.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{.Content"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if _, err := loadContentTemplate(templatePath); err == nil {
+		t.Error("Expected an error for an unparseable template")
+	}
+}
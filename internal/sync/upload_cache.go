@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+)
+
+// runUpload is a file uploaded during the current run. Files with the same content and filename
+// synced to other knowledge bases later in the run reuse it instead of uploading it again.
+type runUpload struct {
+	done         chan struct{}   // closed once the upload finished
+	fileID       string          // empty if the upload failed
+	remoteHash   string          // hash OpenWebUI reported for the upload
+	knowledgeIDs map[string]bool // knowledge bases the upload is used in, guarded by summaryMu
+}
+
+// uploadFile uploads a file's content to OpenWebUI, or returns the file uploaded with the same
+// content and filename for another knowledge base earlier in the current run and reports that
+// it was reused. A second file of that name in the same knowledge base is uploaded separately,
+// and outside a sync run every file is uploaded.
+func (m *Manager) uploadFile(ctx context.Context, file *adapter.File, knowledgeID string) (fileID, remoteHash string, reused bool, err error) {
+	filename := filepath.Base(file.Path)
+	key := GetFileHash(file.Content) + "/" + filename
+
+	for {
+		m.summaryMu.Lock()
+		upload, ok := m.runUploads[key]
+		if m.runUploads == nil || (ok && upload.knowledgeIDs[knowledgeID]) {
+			m.summaryMu.Unlock()
+			break
+		}
+		if !ok {
+			upload = &runUpload{done: make(chan struct{}), knowledgeIDs: map[string]bool{knowledgeID: true}}
+			m.runUploads[key] = upload
+			m.summaryMu.Unlock()
+
+			uploaded, err := m.openwebuiClient.UploadFile(ctx, filename, file.ContentType, file.Content)
+			if err != nil {
+				// Files waiting for this upload try again themselves
+				m.summaryMu.Lock()
+				delete(m.runUploads, key)
+				m.summaryMu.Unlock()
+				close(upload.done)
+				return "", "", false, err
+			}
+			upload.fileID, upload.remoteHash = uploaded.ID, uploaded.Hash
+			close(upload.done)
+			return uploaded.ID, uploaded.Hash, false, nil
+		}
+		upload.knowledgeIDs[knowledgeID] = true
+		m.summaryMu.Unlock()
+
+		// Files of the same content synced in parallel wait for the first upload
+		select {
+		case <-ctx.Done():
+			return "", "", false, ctx.Err()
+		case <-upload.done:
+		}
+		if upload.fileID != "" {
+			return upload.fileID, upload.remoteHash, true, nil
+		}
+	}
+
+	uploaded, err := m.openwebuiClient.UploadFile(ctx, filename, file.ContentType, file.Content)
+	if err != nil {
+		return "", "", false, err
+	}
+	return uploaded.ID, uploaded.Hash, false, nil
+}
+
+// endRun forgets the uploads and fetched files of the finished run, so files synced between
+// runs, e.g. by a watching adapter, never reuse a file that was replaced since
+func (m *Manager) endRun() {
+	m.summaryMu.Lock()
+	m.runUploads = nil
+	m.summaryMu.Unlock()
+
+	m.mu.Lock()
+	m.runKeys = nil
+	m.mu.Unlock()
+}
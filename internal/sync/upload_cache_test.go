@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openwebui-content-sync/internal/adapter"
+	"github.com/openwebui-content-sync/internal/mocks"
+	"github.com/openwebui-content-sync/internal/openwebui"
+	"github.com/openwebui-content-sync/internal/storage"
+)
+
+func TestManager_SyncFiles_ReusesUploadsWithinRun(t *testing.T) {
+	content := []byte("# General\n\nSame messages")
+	tests := []struct {
+		name            string
+		files           []*adapter.File
+		expectedUploads int
+		expectedAdds    []string
+	}{
+		{
+			name: "same content in two knowledge bases",
+			files: []*adapter.File{
+				{Path: "engineering/general.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-1"},
+				{Path: "support/general.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-2"},
+			},
+			expectedUploads: 1,
+			expectedAdds:    []string{"knowledge-1/id-1", "knowledge-2/id-1"},
+		},
+		{
+			name: "same content in the same knowledge base",
+			files: []*adapter.File{
+				{Path: "engineering/general.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-1"},
+				{Path: "support/general.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-1"},
+			},
+			expectedUploads: 2,
+			expectedAdds:    []string{"knowledge-1/id-1", "knowledge-1/id-2"},
+		},
+		{
+			name: "same content under another filename",
+			files: []*adapter.File{
+				{Path: "general.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-1"},
+				{Path: "random.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-2"},
+			},
+			expectedUploads: 2,
+			expectedAdds:    []string{"knowledge-1/id-1", "knowledge-2/id-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			uploads := 0
+			var adds []string
+			mockClient := &mocks.MockOpenWebUIClient{
+				UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					uploads++
+					return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+				},
+				AddFileToKnowledgeFunc: func(ctx context.Context, knowledgeID, fileID string) error {
+					mu.Lock()
+					defer mu.Unlock()
+					adds = append(adds, knowledgeID+"/"+fileID)
+					return nil
+				},
+			}
+			// Each file comes from its own adapter, as files of one adapter with the same hash in
+			// the same knowledge base count as a renamed file
+			var adapters []adapter.Adapter
+			for i, file := range tt.files {
+				adapters = append(adapters, &mocks.MockAdapter{
+					NameFunc: func() string { return fmt.Sprintf("adapter-%d", i) },
+					FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+						return []*adapter.File{file}, nil
+					},
+				})
+			}
+
+			manager := &Manager{
+				openwebuiClient: mockClient,
+				store:           storage.NewMemory(),
+				concurrency:     1,
+				fileIndex:       make(map[string]*FileMetadata),
+			}
+			if err := manager.SyncFiles(context.Background(), adapters); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if uploads != tt.expectedUploads {
+				t.Errorf("Expected %d uploads, got %d", tt.expectedUploads, uploads)
+			}
+			if strings.Join(adds, ",") != strings.Join(tt.expectedAdds, ",") {
+				t.Errorf("Expected knowledge additions %v, got %v", tt.expectedAdds, adds)
+			}
+			if summary := manager.Summary(); summary.Uploaded != tt.expectedUploads || summary.Linked != 2-tt.expectedUploads {
+				t.Errorf("Expected %d uploads and %d links in the summary, got %+v", tt.expectedUploads, 2-tt.expectedUploads, summary)
+			}
+		})
+	}
+}
+
+func TestManager_SyncChangedFile_DoesNotReuseUploadsOfFinishedRun(t *testing.T) {
+	uploads := 0
+	mockClient := &mocks.MockOpenWebUIClient{
+		UploadFileFunc: func(ctx context.Context, filename, contentType string, content []byte) (*openwebui.File, error) {
+			uploads++
+			return &openwebui.File{ID: fmt.Sprintf("id-%d", uploads), Filename: filename}, nil
+		},
+	}
+	content := []byte("# Notes")
+	mockAdapter := &mocks.MockAdapter{
+		NameFunc: func() string { return "local" },
+		FetchFilesFunc: func(ctx context.Context) ([]*adapter.File, error) {
+			return []*adapter.File{{Path: "a/notes.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-1"}}, nil
+		},
+	}
+
+	manager := &Manager{
+		openwebuiClient: mockClient,
+		store:           storage.NewMemory(),
+		concurrency:     1,
+		fileIndex:       make(map[string]*FileMetadata),
+	}
+	if err := manager.SyncFiles(context.Background(), []adapter.Adapter{mockAdapter}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first upload may have been replaced since the run, so it must not be reused
+	file := &adapter.File{Path: "b/notes.md", Content: content, Hash: "hash-1", KnowledgeID: "knowledge-2"}
+	if err := manager.SyncChangedFile(context.Background(), file, "local"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if uploads != 2 {
+		t.Errorf("Expected the changed file to be uploaded, got %d uploads", uploads)
+	}
+}
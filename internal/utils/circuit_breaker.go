@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned when calls for a key are rejected because its circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of the circuit for a single key
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fast-fails every call until the cooldown has passed
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe call through to decide whether to close again
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker trips open after a number of consecutive failures for a key (e.g. a
+// service name) and fast-fails further calls for that key until a cooldown has passed.
+// After the cooldown a single probe call is allowed: success closes the circuit again,
+// failure re-opens it for another cooldown. It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time // replaced in tests
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit tracks the state of a single key
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool // a half-open probe call is in flight
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens after threshold consecutive
+// failures and stays open for cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// circuit returns the circuit for key, creating a closed one if needed. Callers must hold mu.
+func (b *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+// State returns the current state of the circuit for key
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Allow reports whether a call for key may proceed, returning ErrCircuitOpen if not
+func (b *CircuitBreaker) Allow(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	switch c.state {
+	case CircuitOpen:
+		remaining := b.cooldown - b.now().Sub(c.openedAt)
+		if remaining > 0 {
+			return fmt.Errorf("%w for %s, retrying in %v", ErrCircuitOpen, key, remaining.Round(time.Second))
+		}
+		logrus.Debugf("Circuit for %s is half-open, allowing a probe call", key)
+		c.state = CircuitHalfOpen
+		c.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return fmt.Errorf("%w for %s, waiting for probe call", ErrCircuitOpen, key)
+		}
+		c.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess closes the circuit for key and resets its failure count
+func (b *CircuitBreaker) RecordSuccess(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	if c.state != CircuitClosed {
+		logrus.Infof("Circuit for %s closed again", key)
+	}
+	*c = circuit{}
+}
+
+// RecordFailure counts a failed call for key, opening the circuit once the threshold of
+// consecutive failures is reached or when a half-open probe fails
+func (b *CircuitBreaker) RecordFailure(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	c.failures++
+	c.probing = false
+	if c.state == CircuitHalfOpen || (c.state == CircuitClosed && c.failures >= b.threshold) {
+		logrus.Warnf("Circuit for %s opened after %d consecutive failures, failing fast for %v", key, c.failures, b.cooldown)
+		c.state = CircuitOpen
+		c.openedAt = b.now()
+	}
+}
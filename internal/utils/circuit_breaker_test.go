@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestCircuitBreaker creates a circuit breaker whose clock is advanced by the returned function
+func newTestCircuitBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, func(time.Duration)) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(threshold, cooldown)
+	breaker.now = func() time.Time { return now }
+	return breaker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker, _ := newTestCircuitBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		breaker.RecordFailure("svc")
+	}
+	if state := breaker.State("svc"); state != CircuitClosed {
+		t.Fatalf("Expected circuit to stay closed below the threshold, got %s", state)
+	}
+
+	// A success resets the consecutive failure count
+	breaker.RecordSuccess("svc")
+	for i := 0; i < 2; i++ {
+		breaker.RecordFailure("svc")
+	}
+	if state := breaker.State("svc"); state != CircuitClosed {
+		t.Fatalf("Expected circuit to stay closed after a success, got %s", state)
+	}
+
+	breaker.RecordFailure("svc")
+	if state := breaker.State("svc"); state != CircuitOpen {
+		t.Fatalf("Expected circuit to open at the threshold, got %s", state)
+	}
+	if err := breaker.Allow("svc"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	// Other keys are unaffected
+	if err := breaker.Allow("other"); err != nil {
+		t.Errorf("Expected other key to be allowed, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		probeSucceeds bool
+		expected      CircuitState
+	}{
+		{name: "successful probe closes the circuit", probeSucceeds: true, expected: CircuitClosed},
+		{name: "failed probe re-opens the circuit", probeSucceeds: false, expected: CircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, advance := newTestCircuitBreaker(1, time.Minute)
+			breaker.RecordFailure("svc")
+
+			advance(time.Minute)
+			if state := breaker.State("svc"); state != CircuitHalfOpen {
+				t.Fatalf("Expected circuit to be half-open after the cooldown, got %s", state)
+			}
+
+			if err := breaker.Allow("svc"); err != nil {
+				t.Fatalf("Expected probe call to be allowed, got %v", err)
+			}
+			if err := breaker.Allow("svc"); !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("Expected only one probe call while half-open, got %v", err)
+			}
+
+			if tt.probeSucceeds {
+				breaker.RecordSuccess("svc")
+			} else {
+				breaker.RecordFailure("svc")
+			}
+			if state := breaker.State("svc"); state != tt.expected {
+				t.Errorf("Expected circuit to be %s, got %s", tt.expected, state)
+			}
+		})
+	}
+}
+
+func TestRetryWithBackoff_CircuitBreaker(t *testing.T) {
+	breaker, _ := newTestCircuitBreaker(2, time.Minute)
+	config := RetryConfig{
+		MaxRetries:     5,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		Multiplier:     2,
+		CircuitBreaker: breaker,
+		CircuitKey:     "svc",
+	}
+
+	calls := 0
+	err := RetryWithBackoff(context.Background(), config, func() error {
+		calls++
+		return Retryable(errors.New("service unavailable"))
+	})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected retries to stop with ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls before the circuit opened, got %d", calls)
+	}
+
+	// Further operations fail fast without being called
+	err = RetryWithBackoff(context.Background(), config, func() error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Errorf("Expected fast failure without calling the operation, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetryWithBackoff_CircuitBreakerIgnoresPermanentErrors(t *testing.T) {
+	breaker, _ := newTestCircuitBreaker(1, time.Minute)
+	config := RetryConfig{MaxRetries: 1, CircuitBreaker: breaker, CircuitKey: "svc"}
+
+	err := RetryWithBackoff(context.Background(), config, func() error {
+		return Permanent(errors.New("not found"))
+	})
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the permanent error, got %v", err)
+	}
+	if state := breaker.State("svc"); state != CircuitClosed {
+		t.Errorf("Expected permanent errors not to open the circuit, got %s", state)
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SetRequestHeaders sets the User-Agent and the extra headers on req. Headers the request
+// already sets, such as Authorization or Content-Type, are left untouched.
+func SetRequestHeaders(req *http.Request, userAgent string, headers map[string]string) {
+	if userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for key, value := range headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+}
+
+// HeaderTransport is an http.RoundTripper that applies SetRequestHeaders to every request
+// before sending it with Base
+type HeaderTransport struct {
+	Base      http.RoundTripper // http.DefaultTransport if nil
+	UserAgent string
+	Headers   map[string]string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	SetRequestHeaders(req, t.UserAgent, t.Headers)
+	return base.RoundTrip(req)
+}
+
+// WithHeaders wraps the transport of client so every request carries the User-Agent and the
+// extra headers
+func WithHeaders(client *http.Client, userAgent string, headers map[string]string) {
+	if userAgent == "" && len(headers) == 0 {
+		return
+	}
+	client.Transport = &HeaderTransport{Base: client.Transport, UserAgent: userAgent, Headers: headers}
+}
+
+// DoWithRetry sends req with client, retrying network errors and 429/5xx responses with
+// RetryWithBackoff. A Retry-After header on a failed response replaces the computed backoff.
+// Every attempt sends a copy of req, so a request with a body must have GetBody set, as
+// http.NewRequest does for in-memory bodies.
+func DoWithRetry(client *http.Client, req *http.Request, config RetryConfig) (*http.Response, error) {
+	ctx := req.Context()
+	var resp *http.Response
+	err := RetryWithBackoff(ctx, config, func() error {
+		attempt := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return Permanent(fmt.Errorf("failed to copy request body: %w", err))
+			}
+			attempt.Body = body
+		}
+
+		r, err := client.Do(attempt)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Permanent(err)
+			}
+			return Retryable(err)
+		}
+
+		if r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= http.StatusInternalServerError {
+			io.Copy(io.Discard, r.Body)
+			r.Body.Close()
+			logrus.Debugf("%s %s returned retryable status %d", req.Method, req.URL.Path, r.StatusCode)
+			err := Retryable(fmt.Errorf("request failed with status %d", r.StatusCode))
+			if delay := ParseRetryAfter(r.Header.Get("Retry-After"), time.Now()); delay > 0 {
+				return RetryAfter(err, delay)
+			}
+			return err
+		}
+
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ParseRetryAfter returns the delay requested by a Retry-After header, given in seconds or as
+// an HTTP date, or 0 if the header is missing or invalid
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithHeaders(t *testing.T) {
+	var captured http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	WithHeaders(client, "OpenWebUI-Content-Sync/test", map[string]string{
+		"X-Team":        "docs",
+		"Authorization": "Bearer configured",
+	})
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer request")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := captured.Get("User-Agent"); got != "OpenWebUI-Content-Sync/test" {
+		t.Errorf("Expected the configured User-Agent, got %q", got)
+	}
+	if got := captured.Get("X-Team"); got != "docs" {
+		t.Errorf("Expected the extra header, got %q", got)
+	}
+	// Headers set by the request itself win over configured ones
+	if got := captured.Get("Authorization"); got != "Bearer request" {
+		t.Errorf("Expected the request's own Authorization header, got %q", got)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Error("Expected the caller's request to be left unmodified")
+	}
+}
+
+func TestDoWithRetry_RetryAfter(t *testing.T) {
+	var requests int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader("query"))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	// The computed backoff would retry almost immediately
+	config := RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second, Multiplier: 2}
+
+	start := time.Now()
+	resp, err := DoWithRetry(&http.Client{}, req, config)
+	if err != nil {
+		t.Fatalf("DoWithRetry() error = %v", err)
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start)
+
+	if resp.StatusCode != http.StatusOK || requests != 2 {
+		t.Errorf("Expected a successful second attempt, got status %d after %d requests", resp.StatusCode, requests)
+	}
+	if elapsed < time.Second {
+		t.Errorf("Expected the retry to wait for Retry-After (1s), waited %v", elapsed)
+	}
+	if bodies[0] != "query" || bodies[1] != "query" {
+		t.Errorf("Expected the body to be re-sent on retry, got %q", bodies)
+	}
+}
+
+func TestDoWithRetry_ClientErrorNotRetried(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := DoWithRetry(&http.Client{}, req, DefaultRetryConfig())
+	if err != nil {
+		t.Fatalf("DoWithRetry() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || requests != 1 {
+		t.Errorf("Expected the 404 to be returned without retrying, got status %d after %d requests", resp.StatusCode, requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{header: "", expected: 0},
+		{header: "30", expected: 30 * time.Second},
+		{header: "-5", expected: 0},
+		{header: "Sat, 01 Mar 2025 12:00:10 GMT", expected: 10 * time.Second},
+		{header: "Sat, 01 Mar 2025 11:59:00 GMT", expected: 0},
+		{header: "soon", expected: 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.header, now); got != tt.expected {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.header, got, tt.expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -18,6 +19,11 @@ type RetryConfig struct {
 	BaseDelay  time.Duration // Base delay between retries
 	MaxDelay   time.Duration // Maximum delay between retries
 	Multiplier float64       // Exponential backoff multiplier
+
+	// CircuitBreaker, when set, fast-fails attempts while the circuit for CircuitKey is open
+	// and records the outcome of every attempt
+	CircuitBreaker *CircuitBreaker
+	CircuitKey     string
 }
 
 // DefaultRetryConfig returns a sensible default retry configuration
@@ -30,12 +36,52 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
+// classifiedError carries an explicit retry decision that overrides message-based detection
+type classifiedError struct {
+	err       error
+	retryable bool
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// Retryable marks err as retryable regardless of its message
+func Retryable(err error) error {
+	return &classifiedError{err: err, retryable: true}
+}
+
+// Permanent marks err as not retryable regardless of its message
+func Permanent(err error) error {
+	return &classifiedError{err: err, retryable: false}
+}
+
+// retryAfterError carries the delay a server asked for before the next attempt
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// RetryAfter makes RetryWithBackoff wait delay (capped at MaxDelay) before retrying err,
+// instead of the computed backoff
+func RetryAfter(err error, delay time.Duration) error {
+	return &retryAfterError{err: err, delay: delay}
+}
+
 // IsRetryableError checks if an error is retryable
 func IsRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	// Errors explicitly classified by the caller take precedence
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return classified.retryable
+	}
+
 	// Check for network errors
 	if netErr, ok := err.(net.Error); ok {
 		return netErr.Temporary() || netErr.Timeout()
@@ -88,8 +134,9 @@ func IsRetryableError(err error) bool {
 	return false
 }
 
-// GetRetryDelay calculates the appropriate delay for retrying based on error type
-func GetRetryDelay(err error, attempt int, baseDelay time.Duration) time.Duration {
+// GetRetryDelay calculates the appropriate delay for retrying based on error type. Errors
+// without a known type back off exponentially by multiplier (2 if unset).
+func GetRetryDelay(err error, attempt int, baseDelay time.Duration, multiplier float64) time.Duration {
 	if err == nil {
 		return baseDelay
 	}
@@ -117,7 +164,10 @@ func GetRetryDelay(err error, attempt int, baseDelay time.Duration) time.Duratio
 	}
 
 	// Default exponential backoff
-	delay := time.Duration(float64(baseDelay) * math.Pow(2, float64(attempt)))
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	delay := time.Duration(float64(baseDelay) * math.Pow(multiplier, float64(attempt)))
 	if delay > 2*time.Minute {
 		delay = 2 * time.Minute
 	}
@@ -130,15 +180,24 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, operation func()
 
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// Calculate delay based on error type and attempt number
-			delay := GetRetryDelay(lastErr, attempt-1, config.BaseDelay)
-			if delay > config.MaxDelay {
-				delay = config.MaxDelay
-			}
+			// Calculate delay based on error type and attempt number, unless the server asked for one
+			var retryAfter *retryAfterError
+			var delay time.Duration
+			if errors.As(lastErr, &retryAfter) {
+				delay = retryAfter.delay
+				if delay > config.MaxDelay {
+					delay = config.MaxDelay
+				}
+			} else {
+				delay = GetRetryDelay(lastErr, attempt-1, config.BaseDelay, config.Multiplier)
+				if delay > config.MaxDelay {
+					delay = config.MaxDelay
+				}
 
-			// Add jitter to prevent thundering herd
-			jitter := time.Duration(rand.Float64() * float64(delay) * 0.1)
-			delay += jitter
+				// Add jitter to prevent thundering herd
+				jitter := time.Duration(rand.Float64() * float64(delay) * 0.1)
+				delay += jitter
+			}
 
 			logrus.Debugf("Retry attempt %d/%d after %v (last error: %v)",
 				attempt+1, config.MaxRetries+1, delay, lastErr)
@@ -150,7 +209,14 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, operation func()
 			}
 		}
 
+		if config.CircuitBreaker != nil {
+			if err := config.CircuitBreaker.Allow(config.CircuitKey); err != nil {
+				return Permanent(err)
+			}
+		}
+
 		err := operation()
+		config.recordOutcome(err)
 		if err == nil {
 			if attempt > 0 {
 				logrus.Debugf("Operation succeeded on attempt %d", attempt+1)
@@ -176,3 +242,16 @@ func RetryWithBackoff(ctx context.Context, config RetryConfig, operation func()
 
 	return fmt.Errorf("operation failed after %d retries: %w", config.MaxRetries+1, lastErr)
 }
+
+// recordOutcome reports the result of an attempt to the circuit breaker, if any. Only
+// retryable errors count as failures; other errors mean the service itself responded.
+func (config RetryConfig) recordOutcome(err error) {
+	if config.CircuitBreaker == nil {
+		return
+	}
+	if err != nil && IsRetryableError(err) {
+		config.CircuitBreaker.RecordFailure(config.CircuitKey)
+		return
+	}
+	config.CircuitBreaker.RecordSuccess(config.CircuitKey)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		attempt    int
+		multiplier float64
+		expected   time.Duration
+	}{
+		{"first retry", errors.New("unexpected EOF"), 0, 2, time.Second},
+		{"doubling", errors.New("unexpected EOF"), 2, 2, 4 * time.Second},
+		{"custom multiplier", errors.New("unexpected EOF"), 2, 3, 9 * time.Second},
+		{"unset multiplier doubles", errors.New("unexpected EOF"), 3, 0, 8 * time.Second},
+		{"capped at two minutes", errors.New("unexpected EOF"), 10, 2, 2 * time.Minute},
+		{"rate limits ignore the multiplier", errors.New("slack rate limit exceeded"), 2, 3, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if delay := GetRetryDelay(tt.err, tt.attempt, time.Second, tt.multiplier); delay != tt.expected {
+				t.Errorf("GetRetryDelay() = %v, want %v", delay, tt.expected)
+			}
+		})
+	}
+}
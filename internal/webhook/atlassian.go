@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// jiraPayload is a Jira issue or comment webhook
+type jiraPayload struct {
+	WebhookEvent string `json:"webhookEvent"` // e.g. jira:issue_updated or comment_created
+	Issue        struct {
+		Key string `json:"key"`
+	} `json:"issue"`
+}
+
+// confluencePayload is a Confluence page or blog post webhook
+type confluencePayload struct {
+	Event     string          `json:"event"`     // e.g. page_updated
+	EventType string          `json:"eventType"` // name of the event in some Confluence versions
+	Page      json.RawMessage `json:"page"`
+	Blog      json.RawMessage `json:"blog"`
+	BlogPost  json.RawMessage `json:"blogpost"`
+}
+
+// jiraHandler queues the issue of a Jira event
+func (r *Receiver) jiraHandler(w http.ResponseWriter, req *http.Request) {
+	body, ok := readVerified(w, req, "Jira", func(header http.Header, body []byte) error {
+		return verifyHubSignature(r.config.JiraSecret, header, body)
+	})
+	if !ok {
+		return
+	}
+
+	var payload jiraPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		decodeError(w, "Jira", err)
+		return
+	}
+	r.queueItems(w, jiraItems(payload))
+}
+
+// jiraItems returns the issue of an event. Deleted issues are removed by the next full sync.
+func jiraItems(payload jiraPayload) []Item {
+	if payload.Issue.Key == "" || payload.WebhookEvent == "jira:issue_deleted" {
+		return nil
+	}
+	return []Item{{Source: "jira", ID: payload.Issue.Key}}
+}
+
+// confluenceHandler queues the page of a Confluence event
+func (r *Receiver) confluenceHandler(w http.ResponseWriter, req *http.Request) {
+	body, ok := readVerified(w, req, "Confluence", func(header http.Header, body []byte) error {
+		return verifyHubSignature(r.config.ConfluenceSecret, header, body)
+	})
+	if !ok {
+		return
+	}
+
+	var payload confluencePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		decodeError(w, "Confluence", err)
+		return
+	}
+	r.queueItems(w, confluenceItems(payload))
+}
+
+// confluenceItems returns the page of an event. Blog posts can't be fetched on their own, so
+// they sync the whole adapter, and removed content is left to the next full sync.
+func confluenceItems(payload confluencePayload) []Item {
+	event := payload.Event + payload.EventType
+	if strings.Contains(event, "remove") || strings.Contains(event, "trash") || strings.Contains(event, "delete") {
+		return nil
+	}
+	if id := rawID(payload.Page); id != "" {
+		return []Item{{Source: "confluence", ID: id}}
+	}
+	if rawID(payload.Blog) != "" || rawID(payload.BlogPost) != "" {
+		return []Item{{Source: "confluence"}}
+	}
+	return nil
+}
+
+// verifyHubSignature checks the X-Hub-Signature header Jira and Confluence send for webhooks
+// with a secret: "sha256=" followed by the hex HMAC-SHA256 of the body
+func verifyHubSignature(secret string, header http.Header, body []byte) error {
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature"), "sha256=")
+	if !ok {
+		return errors.New("missing or unsupported X-Hub-Signature header")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(strings.ToLower(signature))) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
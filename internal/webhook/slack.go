@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// slackMaxAge is how far the timestamp of a Slack request may be off, so a captured request
+// can't be replayed later
+const slackMaxAge = 5 * time.Minute
+
+// slackPayload is a request of the Slack Events API
+type slackPayload struct {
+	Type      string `json:"type"`      // url_verification or event_callback
+	Challenge string `json:"challenge"` // echoed back to verify the request URL
+	Event     struct {
+		Type    string          `json:"type"`
+		Channel json.RawMessage `json:"channel"` // an ID, or an object for events such as channel_rename
+		Item    struct {
+			Channel string `json:"channel"`
+		} `json:"item"` // the message of reaction events
+	} `json:"event"`
+}
+
+// slackHandler queues the channel of a Slack event and answers URL verification requests
+func (r *Receiver) slackHandler(w http.ResponseWriter, req *http.Request) {
+	body, ok := readVerified(w, req, "Slack", func(header http.Header, body []byte) error {
+		return verifySlack(r.config.SlackSigningSecret, r.now(), header, body)
+	})
+	if !ok {
+		return
+	}
+
+	var payload slackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		decodeError(w, "Slack", err)
+		return
+	}
+	if payload.Type == "url_verification" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(payload.Challenge))
+		return
+	}
+	r.queueItems(w, slackItems(payload))
+}
+
+// slackItems returns the channel an event happened in
+func slackItems(payload slackPayload) []Item {
+	if payload.Type != "event_callback" {
+		return nil
+	}
+	channel := rawID(payload.Event.Channel)
+	if channel == "" {
+		channel = payload.Event.Item.Channel
+	}
+	if channel == "" {
+		return nil
+	}
+	return []Item{{Source: "slack", ID: channel}}
+}
+
+// verifySlack checks the signature Slack computes from the signing secret, the request
+// timestamp and the body
+func verifySlack(secret string, now time.Time, header http.Header, body []byte) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid X-Slack-Request-Timestamp header")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > slackMaxAge || age < -slackMaxAge {
+		return fmt.Errorf("request timestamp is more than %v off", slackMaxAge)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// queueSize bounds the items waiting to be synced. Events arriving while it is full are
+// rejected, so their source delivers them again later.
+const queueSize = 100
+
+// maxBodyBytes bounds the size of a webhook payload
+const maxBodyBytes = 1 << 20
+
+// Item is an item of a source that changed, such as a Slack channel, Confluence page or Jira issue
+type Item struct {
+	Source string // adapter name: slack, confluence or jira
+	ID     string // channel ID, page ID or issue key; empty to sync the whole adapter
+}
+
+// SyncFunc syncs a changed item
+type SyncFunc func(ctx context.Context, item Item) error
+
+// Response is the JSON response to a webhook request
+type Response struct {
+	Status string `json:"status"` // queued, ignored or queue_full
+}
+
+// Receiver verifies webhook requests of Slack, Confluence and Jira and queues the items they
+// name, which Run syncs one at a time
+type Receiver struct {
+	config   config.WebhookConfig
+	syncItem SyncFunc
+	queue    chan Item
+	mu       sync.Mutex
+	pending  map[Item]bool    // items in the queue, so a burst of events syncs an item once
+	now      func() time.Time // current time, replaced by tests to check the Slack timestamp window
+}
+
+// NewReceiver creates a receiver that syncs the items of verified events with syncItem
+func NewReceiver(cfg config.WebhookConfig, syncItem SyncFunc) *Receiver {
+	return &Receiver{
+		config:   cfg,
+		syncItem: syncItem,
+		queue:    make(chan Item, queueSize),
+		pending:  make(map[Item]bool),
+		now:      time.Now,
+	}
+}
+
+// Handler returns the handler of the endpoints below /webhooks/ whose secret is set
+func (r *Receiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	if r.config.SlackSigningSecret != "" {
+		mux.HandleFunc("/webhooks/slack", r.slackHandler)
+	}
+	if r.config.ConfluenceSecret != "" {
+		mux.HandleFunc("/webhooks/confluence", r.confluenceHandler)
+	}
+	if r.config.JiraSecret != "" {
+		mux.HandleFunc("/webhooks/jira", r.jiraHandler)
+	}
+	return mux
+}
+
+// Run syncs queued items one at a time until ctx is cancelled
+func (r *Receiver) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-r.queue:
+			// An event arriving during the sync queues the item again
+			r.mu.Lock()
+			delete(r.pending, item)
+			r.mu.Unlock()
+
+			logrus.Infof("Syncing %s item %q reported by webhook", item.Source, item.ID)
+			if err := r.syncItem(ctx, item); err != nil {
+				logrus.Errorf("Failed to sync %s item %q reported by webhook: %v", item.Source, item.ID, err)
+			}
+		}
+	}
+}
+
+// enqueue queues an item unless it is already waiting. It returns false if the queue is full.
+func (r *Receiver) enqueue(item Item) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending[item] {
+		return true
+	}
+	select {
+	case r.queue <- item:
+		r.pending[item] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// readVerified reads the body of a POST request and checks it with verify. It writes an error
+// response and returns false if the request is rejected.
+func readVerified(w http.ResponseWriter, req *http.Request, source string, verify func(header http.Header, body []byte) error) ([]byte, bool) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := verify(req.Header, body); err != nil {
+		logrus.Warnf("Rejected %s webhook from %s: %v", source, req.RemoteAddr, err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+// queueItems queues the items of an event and writes the response
+func (r *Receiver) queueItems(w http.ResponseWriter, items []Item) {
+	if len(items) == 0 {
+		writeResponse(w, http.StatusOK, "ignored")
+		return
+	}
+	for _, item := range items {
+		if !r.enqueue(item) {
+			logrus.Warnf("Webhook queue is full, rejecting %s item %q", item.Source, item.ID)
+			writeResponse(w, http.StatusServiceUnavailable, "queue_full")
+			return
+		}
+	}
+	writeResponse(w, http.StatusOK, "queued")
+}
+
+// writeResponse writes a JSON webhook response with the given status code
+func writeResponse(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(Response{Status: status})
+}
+
+// rawID returns an ID given as a JSON string, a number or an object with an "id" field
+func rawID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String()
+	}
+	var object struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && object.ID != nil {
+		return rawID(object.ID)
+	}
+	return ""
+}
+
+// decodeError writes the response to a payload that isn't valid JSON
+func decodeError(w http.ResponseWriter, source string, err error) {
+	http.Error(w, fmt.Sprintf("invalid %s payload: %v", source, err), http.StatusBadRequest)
+}
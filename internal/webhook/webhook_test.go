@@ -0,0 +1,300 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwebui-content-sync/internal/config"
+)
+
+// testConfig enables every endpoint
+var testConfig = config.WebhookConfig{
+	Enabled:            true,
+	SlackSigningSecret: "slack-secret",
+	ConfluenceSecret:   "confluence-secret",
+	JiraSecret:         "jira-secret",
+}
+
+// slackRequest returns a Slack request signed with secret at the given time
+func slackRequest(secret string, at time.Time, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/slack", strings.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// hubRequest returns a Jira or Confluence request signed with secret
+func hubRequest(path, secret, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// queued drains the receiver's queue
+func queued(r *Receiver) []Item {
+	var items []Item
+	for {
+		select {
+		case item := <-r.queue:
+			items = append(items, item)
+		default:
+			return items
+		}
+	}
+}
+
+func TestVerifySlack(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := `{"type":"event_callback"}`
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		wantErr string
+	}{
+		{name: "valid signature", request: slackRequest("slack-secret", now, body)},
+		{name: "other secret", request: slackRequest("other-secret", now, body), wantErr: "signature does not match"},
+		{name: "replayed request", request: slackRequest("slack-secret", now.Add(-10*time.Minute), body), wantErr: "more than 5m0s off"},
+		{name: "missing timestamp", request: httptest.NewRequest(http.MethodPost, "/webhooks/slack", nil), wantErr: "X-Slack-Request-Timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySlack("slack-secret", now, tt.request.Header, []byte(body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifySlack() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifySlack() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyHubSignature(t *testing.T) {
+	body := `{"webhookEvent":"jira:issue_updated"}`
+	tampered := hubRequest("/webhooks/jira", "jira-secret", body)
+	unsupported := hubRequest("/webhooks/jira", "jira-secret", body)
+	unsupported.Header.Set("X-Hub-Signature", strings.Replace(unsupported.Header.Get("X-Hub-Signature"), "sha256=", "sha1=", 1))
+
+	tests := []struct {
+		name    string
+		request *http.Request
+		body    string
+		wantErr string
+	}{
+		{name: "valid signature", request: hubRequest("/webhooks/jira", "jira-secret", body), body: body},
+		{name: "other secret", request: hubRequest("/webhooks/jira", "other-secret", body), body: body, wantErr: "signature does not match"},
+		{name: "tampered body", request: tampered, body: `{"webhookEvent":"jira:issue_deleted"}`, wantErr: "signature does not match"},
+		{name: "unsupported algorithm", request: unsupported, body: body, wantErr: "X-Hub-Signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyHubSignature("jira-secret", tt.request.Header, []byte(tt.body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyHubSignature() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyHubSignature() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReceiver_Handler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name       string
+		config     config.WebhookConfig
+		requests   []*http.Request
+		wantCode   int
+		wantStatus string
+		wantItems  []Item
+	}{
+		{
+			name:       "slack message",
+			config:     testConfig,
+			requests:   []*http.Request{slackRequest("slack-secret", now, `{"type":"event_callback","event":{"type":"message","channel":"C123"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "slack", ID: "C123"}},
+		},
+		{
+			name:       "slack reaction",
+			config:     testConfig,
+			requests:   []*http.Request{slackRequest("slack-secret", now, `{"type":"event_callback","event":{"type":"reaction_added","item":{"type":"message","channel":"C456"}}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "slack", ID: "C456"}},
+		},
+		{
+			name:   "burst of slack messages",
+			config: testConfig,
+			requests: []*http.Request{
+				slackRequest("slack-secret", now, `{"type":"event_callback","event":{"type":"message","channel":"C123"}}`),
+				slackRequest("slack-secret", now, `{"type":"event_callback","event":{"type":"channel_rename","channel":{"id":"C123","name":"general"}}}`),
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "slack", ID: "C123"}},
+		},
+		{
+			name:     "slack with an invalid signature",
+			config:   testConfig,
+			requests: []*http.Request{slackRequest("other-secret", now, `{"type":"event_callback","event":{"type":"message","channel":"C123"}}`)},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "jira issue",
+			config:     testConfig,
+			requests:   []*http.Request{hubRequest("/webhooks/jira", "jira-secret", `{"webhookEvent":"jira:issue_updated","issue":{"id":"10001","key":"PROJ-1"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "jira", ID: "PROJ-1"}},
+		},
+		{
+			name:       "deleted jira issue",
+			config:     testConfig,
+			requests:   []*http.Request{hubRequest("/webhooks/jira", "jira-secret", `{"webhookEvent":"jira:issue_deleted","issue":{"key":"PROJ-1"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "ignored",
+		},
+		{
+			name:     "jira signed with the confluence secret",
+			config:   testConfig,
+			requests: []*http.Request{hubRequest("/webhooks/jira", "confluence-secret", `{"webhookEvent":"jira:issue_updated","issue":{"key":"PROJ-1"}}`)},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "confluence page with a numeric ID",
+			config:     testConfig,
+			requests:   []*http.Request{hubRequest("/webhooks/confluence", "confluence-secret", `{"event":"page_updated","page":{"id":98765,"title":"Runbook"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "confluence", ID: "98765"}},
+		},
+		{
+			name:       "confluence blog post",
+			config:     testConfig,
+			requests:   []*http.Request{hubRequest("/webhooks/confluence", "confluence-secret", `{"event":"blog_created","blog":{"id":"555"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "queued",
+			wantItems:  []Item{{Source: "confluence"}},
+		},
+		{
+			name:       "trashed confluence page",
+			config:     testConfig,
+			requests:   []*http.Request{hubRequest("/webhooks/confluence", "confluence-secret", `{"event":"page_trashed","page":{"id":"98765"}}`)},
+			wantCode:   http.StatusOK,
+			wantStatus: "ignored",
+		},
+		{
+			name:     "endpoint without a secret",
+			config:   config.WebhookConfig{Enabled: true, SlackSigningSecret: "slack-secret"},
+			requests: []*http.Request{hubRequest("/webhooks/jira", "", `{"webhookEvent":"jira:issue_updated","issue":{"key":"PROJ-1"}}`)},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := NewReceiver(tt.config, nil)
+			receiver.now = func() time.Time { return now }
+			handler := receiver.Handler()
+
+			var rec *httptest.ResponseRecorder
+			for _, req := range tt.requests {
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != "" && !strings.Contains(rec.Body.String(), `"status":"`+tt.wantStatus+`"`) {
+				t.Errorf("Expected status %q, got %s", tt.wantStatus, rec.Body.String())
+			}
+			items := queued(receiver)
+			if fmt.Sprint(items) != fmt.Sprint(tt.wantItems) {
+				t.Errorf("Expected queued items %v, got %v", tt.wantItems, items)
+			}
+		})
+	}
+}
+
+func TestReceiver_SlackURLVerification(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	receiver := NewReceiver(testConfig, nil)
+	receiver.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	receiver.Handler().ServeHTTP(rec, slackRequest("slack-secret", now, `{"type":"url_verification","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"}`))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P" {
+		t.Errorf("Expected the challenge to be echoed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if items := queued(receiver); len(items) != 0 {
+		t.Errorf("Expected nothing to be queued, got %v", items)
+	}
+}
+
+func TestReceiver_QueueFull(t *testing.T) {
+	receiver := NewReceiver(testConfig, nil)
+	for i := 0; i < queueSize; i++ {
+		if !receiver.enqueue(Item{Source: "jira", ID: fmt.Sprintf("PROJ-%d", i)}) {
+			t.Fatalf("Expected item %d to be queued", i)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	receiver.Handler().ServeHTTP(rec, hubRequest("/webhooks/jira", "jira-secret", `{"webhookEvent":"jira:issue_updated","issue":{"key":"OTHER-1"}}`))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d while the queue is full, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestReceiver_Run(t *testing.T) {
+	synced := make(chan Item)
+	receiver := NewReceiver(testConfig, func(ctx context.Context, item Item) error {
+		synced <- item
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go receiver.Run(ctx)
+
+	receiver.enqueue(Item{Source: "jira", ID: "PROJ-1"})
+	if item := <-synced; item != (Item{Source: "jira", ID: "PROJ-1"}) {
+		t.Errorf("Expected PROJ-1 to be synced, got %v", item)
+	}
+
+	// Once its sync started, an item can be queued again
+	receiver.enqueue(Item{Source: "jira", ID: "PROJ-1"})
+	select {
+	case <-synced:
+	case <-time.After(time.Second):
+		t.Error("Expected PROJ-1 to be synced again")
+	}
+}
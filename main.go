@@ -18,22 +18,23 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
-	"github.com/openwebui-content-sync/internal/adapter"
 	"github.com/openwebui-content-sync/internal/config"
-	"github.com/openwebui-content-sync/internal/health"
-	"github.com/openwebui-content-sync/internal/scheduler"
-	"github.com/openwebui-content-sync/internal/sync"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
 	var configPath = flag.String("config", "config.yaml", "Path to configuration file")
+	var dryRun = flag.Bool("dry-run", false, "Report planned changes without modifying OpenWebUI")
+	var purgeSource = flag.String("purge-source", "", "Remove every file synced by the named source (e.g. jira) from OpenWebUI and exit")
+	var repairIndex = flag.Bool("repair-index", false, "Drop file index entries whose file no longer exists in OpenWebUI and duplicate entries, then exit")
+	var validateConfig = flag.Bool("validate-config", false, "Check the connection to OpenWebUI and every enabled source, print the results and exit")
 	flag.Parse()
 
 	// Load configuration
@@ -41,6 +42,12 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("Failed to load configuration: %v", err)
 	}
+	// Purging and repairing only talk to OpenWebUI, and the purged source is usually disabled already
+	if *purgeSource == "" && !*repairIndex {
+		if err := cfg.Validate(); err != nil {
+			logrus.Fatalf("Invalid configuration:\n%v", err)
+		}
+	}
 
 	// Set log level
 	level, err := logrus.ParseLevel(cfg.LogLevel)
@@ -49,127 +56,76 @@ func main() {
 	}
 	logrus.SetLevel(level)
 
-	logrus.Info("Starting OpenWebUI Content Sync")
-
-	// Initialize adapters
-	adapters := make([]adapter.Adapter, 0)
-
-	// Add GitHub adapter if configured
-	if cfg.GitHub.Enabled {
-		githubAdapter, err := adapter.NewGitHubAdapter(cfg.GitHub)
-		if err != nil {
-			logrus.Fatalf("Failed to create GitHub adapter: %v", err)
+	// In validate mode, check URLs and credentials without syncing and exit non-zero on failure
+	if *validateConfig {
+		if !printConnectionChecks(os.Stdout, checkConnections(context.Background(), cfg)) {
+			os.Exit(1)
 		}
-		adapters = append(adapters, githubAdapter)
+		return
 	}
 
-	// Add Confluence adapter if configured
-	if cfg.Confluence.Enabled {
-		confluenceAdapter, err := adapter.NewConfluenceAdapter(cfg.Confluence)
-		if err != nil {
-			logrus.Fatalf("Failed to create Confluence adapter: %v", err)
-		}
-		adapters = append(adapters, confluenceAdapter)
-	}
+	logrus.Info("Starting OpenWebUI Content Sync")
 
-	// Add Local Folders adapter if configured
-	if cfg.LocalFolders.Enabled {
-		localAdapter, err := adapter.NewLocalFolderAdapter(cfg.LocalFolders)
-		if err != nil {
-			logrus.Fatalf("Failed to create Local Folders adapter: %v", err)
-		}
-		adapters = append(adapters, localAdapter)
+	app, err := New(cfg, Options{DryRun: *dryRun, PurgeSource: *purgeSource, RepairIndex: *repairIndex})
+	if err != nil {
+		logrus.Fatalf("Failed to start: %v", err)
 	}
 
-	// Add Slack adapter if configured
-	if cfg.Slack.Enabled {
-		slackAdapter, err := adapter.NewSlackAdapter(cfg.Slack, cfg.Storage.Path)
+	// In purge mode, remove everything synced by the given source and exit
+	if *purgeSource != "" {
+		purged, err := app.Purge(context.Background(), *purgeSource)
 		if err != nil {
-			logrus.Fatalf("Failed to create Slack adapter: %v", err)
+			logrus.Fatalf("Failed to purge source %s: %v", *purgeSource, err)
 		}
-		adapters = append(adapters, slackAdapter)
+		logrus.Infof("Purged %d files from source %s", purged, *purgeSource)
+		return
 	}
-	// Add Jira adapter if configured
-	if cfg.Jira.Enabled {
-		jiraAdapter, err := adapter.NewJiraAdapter(cfg.Jira)
+
+	// In repair mode, clean up the file index and exit
+	if *repairIndex {
+		report, err := app.RepairIndex(context.Background())
 		if err != nil {
-			logrus.Fatalf("Failed to create Jira adapter: %v", err)
+			logrus.Fatalf("Failed to repair file index: %v", err)
 		}
-		adapters = append(adapters, jiraAdapter)
+		logrus.Infof("Repaired file index: checked %d entries, dropped %d missing and %d duplicate entries, deleted %d files of duplicates", report.Checked, report.Missing, report.Duplicates, report.Deleted)
+		return
 	}
 
-	// Initialize sync manager
-	syncManager, err := sync.NewManager(cfg.OpenWebUI, cfg.Storage)
-	if err != nil {
-		logrus.Fatalf("Failed to create sync manager: %v", err)
+	// In dry-run mode, run a single sync pass, print the summary and exit
+	if *dryRun {
+		runDryRun(app)
+		return
 	}
 
-	// Note: With the mapping system, individual files will have their own knowledge IDs
-	logrus.Infof("Using mapping-based knowledge ID assignment - files will use their individual knowledge IDs from mappings")
-
-	// Initialize scheduler
-	sched := scheduler.New(cfg.Schedule.Interval, adapters, syncManager)
-
-	// Start health check server
-	healthServer := health.NewServer(8080)
+	// Cancel on the first interrupt; a second one exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	go func() {
-		if err := healthServer.Start(); err != nil {
-			logrus.Errorf("Health server error: %v", err)
-		}
-	}()
-
-	// Create context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+		<-ctx.Done()
+		stop()
+		logrus.Info("Shutting down... (press CTRL+C again to force)")
 
-	// Start scheduler
-	go sched.Start(ctx)
-
-	// Wait for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		forceChan := make(chan os.Signal, 1)
+		signal.Notify(forceChan, syscall.SIGINT, syscall.SIGTERM)
+		<-forceChan
+		logrus.Warn("Force shutdown requested, exiting immediately")
+		os.Exit(1)
+	}()
 
-	// Initialize file index from OpenWebUI
-	logrus.Info("Initializing file index from OpenWebUI...")
-	if err := syncManager.InitializeFileIndex(ctx, adapters); err != nil {
-		logrus.Errorf("Failed to initialize file index: %v", err)
-		// Continue even if initialization fails
+	if err := app.Run(ctx); err != nil {
+		logrus.Fatalf("%v", err)
 	}
+}
 
-	// Run initial sync
-	logrus.Info("Running initial sync...")
-	if err := sched.RunSyncWithContext(ctx); err != nil {
-		logrus.Errorf("Initial sync failed: %v", err)
+// runDryRun performs a single sync pass without modifying OpenWebUI and prints a JSON summary
+func runDryRun(app *App) {
+	if err := app.RunOnce(context.Background()); err != nil {
+		logrus.Errorf("Dry run finished with errors: %v", err)
 	}
 
-	// Wait for shutdown signal
-	<-sigChan
-	logrus.Info("Shutting down gracefully... (press CTRL+C again to force)")
-	cancel()
-
-	// Create a channel for forced shutdown
-	forceChan := make(chan os.Signal, 1)
-	signal.Notify(forceChan, syscall.SIGINT, syscall.SIGTERM)
-
-	// Stop health server with timeout
-	healthCtx, healthCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer healthCancel()
-
-	// Run shutdown in a goroutine so we can detect double CTRL+C
-	shutdownDone := make(chan bool, 1)
-	go func() {
-		healthServer.Stop(healthCtx)
-		// Give some time for graceful shutdown
-		time.Sleep(5 * time.Second)
-		shutdownDone <- true
-	}()
-
-	// Wait for either shutdown completion or forced termination
-	select {
-	case <-shutdownDone:
-		logrus.Info("Graceful shutdown completed")
-	case <-forceChan:
-		logrus.Warn("Force shutdown requested, exiting immediately")
-		os.Exit(1)
+	summary, err := json.Marshal(app.Manager().Summary())
+	if err != nil {
+		logrus.Fatalf("Failed to encode dry run summary: %v", err)
 	}
+	fmt.Println(string(summary))
 }